@@ -5,6 +5,7 @@ package builder
 
 import (
 	"context"
+	"time"
 
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -19,4 +20,5 @@ type VM interface {
 	Logger() logging.Logger
 	Mempool() chain.Mempool
 	Rules(int64) chain.Rules
+	Now() time.Time
 }
@@ -0,0 +1,52 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package builder
+
+import (
+	"time"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+const (
+	// estimatedActionComputeUnits is the compute units of an average action
+	// (most simple actions, like transfers, use 1).
+	estimatedActionComputeUnits = 1
+
+	// estimatedAuthComputeUnits is the compute units of an average [chain.Auth]
+	// (like an ed25519 signature).
+	estimatedAuthComputeUnits = 5
+
+	// estimatedComputeUnitTime is roughly how long building takes per
+	// compute unit (including verifying auth, reading state, and executing
+	// actions).
+	estimatedComputeUnitTime = 10 * time.Microsecond
+
+	// estimatedBuildOverhead is roughly how long building takes regardless of
+	// the transactions included (fetching the parent state and computing the
+	// root).
+	estimatedBuildOverhead = 5 * time.Millisecond
+)
+
+// EstimateBuildTime returns a rough estimate of how long building a block
+// from a mempool of [mempoolSize] transactions takes with [r] (which can be
+// used to pick the GetTargetBuildDuration of a node).
+//
+// Each transaction is assumed to contain a single average action and be
+// authorized by an average [chain.Auth], so it consumes
+// [chain.Rules.GetBaseComputeUnits] plus a few compute units. At most as many
+// transactions as fit in [chain.Rules.GetMaxBlockUnits] (of compute) are
+// included and each compute unit is assumed to take a fixed amount of time.
+// The estimate does not account for the actual actions in the mempool, the
+// number of cores used for execution, or contention between transactions, so
+// it should only be used as a starting point.
+func EstimateBuildTime(mempoolSize int, r chain.Rules) time.Duration {
+	txUnits := r.GetBaseComputeUnits() + estimatedActionComputeUnits + estimatedAuthComputeUnits
+	txs := uint64(max(mempoolSize, 0))
+	if maxTxs := r.GetMaxBlockUnits()[fees.Compute] / txUnits; txs > maxTxs {
+		txs = maxTxs
+	}
+	return estimatedBuildOverhead + time.Duration(txs*txUnits)*estimatedComputeUnitTime
+}
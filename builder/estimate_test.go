@@ -0,0 +1,38 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+func TestEstimateBuildTime(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	r := chain.NewMockRules(ctrl)
+	r.EXPECT().GetBaseComputeUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetMaxBlockUnits().Return(fees.Dimensions{1_800_000, 7_000, 2_000, 2_000, 2_000}).AnyTimes()
+
+	// An empty block only takes the overhead
+	require.Equal(estimatedBuildOverhead, EstimateBuildTime(0, r))
+	require.Equal(estimatedBuildOverhead, EstimateBuildTime(-1, r))
+
+	// The estimate grows with the mempool until a block is full
+	full := 7_000 / (1 + estimatedActionComputeUnits + estimatedAuthComputeUnits)
+	prev := EstimateBuildTime(0, r)
+	for size := 1; size <= full; size++ {
+		estimate := EstimateBuildTime(size, r)
+		require.Greater(estimate, prev, "size=%d", size)
+		prev = estimate
+	}
+	for _, size := range []int{full + 1, 2 * full, 100_000} {
+		require.Equal(prev, EstimateBuildTime(size, r), "size=%d", size)
+	}
+}
@@ -75,7 +75,7 @@ func (b *Time) Queue(ctx context.Context) {
 		b.vm.Logger().Warn("unable to load preferred block", zap.Error(err))
 		return
 	}
-	now := time.Now().UnixMilli()
+	now := b.vm.Now().UnixMilli()
 	next := b.nextTime(now, preferredBlk.Tmstmp)
 	if next < 0 {
 		if err := b.Force(ctx); err != nil {
@@ -96,7 +96,7 @@ func (b *Time) Queue(ctx context.Context) {
 func (b *Time) Force(context.Context) error {
 	select {
 	case b.vm.EngineChan() <- common.PendingTxs:
-		b.lastQueue = time.Now().UnixMilli()
+		b.lastQueue = b.vm.Now().UnixMilli()
 	default:
 		b.vm.Logger().Debug("dropping message to consensus engine")
 	}
@@ -0,0 +1,130 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+// acceptSyncTestVM reports that state sync is ongoing to the first [syncing]
+// calls to [UpdateSyncTarget] and records the blocks committed and accepted.
+type acceptSyncTestVM struct {
+	*offlineTestVM
+
+	syncing  int
+	updates  int
+	commits  int
+	accepted []ids.ID
+}
+
+func (*acceptSyncTestVM) RecordBlockAccept(time.Duration) {}
+
+func (vm *acceptSyncTestVM) UpdateSyncTarget(*StatelessBlock) (bool, error) {
+	vm.updates++
+	return vm.updates <= vm.syncing, nil
+}
+
+func (vm *acceptSyncTestVM) CommitState(ctx context.Context, blk *StatelessBlock, view merkledb.View) error {
+	vm.commits++
+	return vm.offlineTestVM.CommitState(ctx, blk, view)
+}
+
+func (vm *acceptSyncTestVM) Accepted(_ context.Context, b *StatelessBlock) {
+	vm.accepted = append(vm.accepted, b.ID())
+}
+
+func TestAcceptUnprocessedDuringSync(t *testing.T) {
+	tests := []struct {
+		name    string
+		syncing int  // calls to [UpdateSyncTarget] made while syncing
+		verify  bool // verify before accepting (the state was ready)
+
+		updates   int
+		committed bool
+	}{
+		{
+			name:    "syncing",
+			syncing: 1,
+			updates: 1,
+		},
+		{
+			name:      "sync finished",
+			updates:   1,
+			committed: true,
+		},
+		{
+			name:      "synced before verify",
+			verify:    true,
+			committed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+
+			db, parentRoot := newOfflineTestState(ctx, require)
+			var (
+				chainID                      = ids.GenerateTestID()
+				r                            = newOfflineTestRules(gomock.NewController(t), chainID)
+				actionRegistry, authRegistry = (&testParser{}).Registry()
+				factory                      = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+			)
+			tx, err := NewTx(
+				&Base{Timestamp: 2_000, ChainID: chainID, MaxFee: 1_000_000},
+				[]Action{&testAction{}},
+			).Sign(factory, actionRegistry, authRegistry)
+			require.NoError(err)
+			vm := &acceptSyncTestVM{
+				offlineTestVM: &offlineTestVM{
+					r:            r,
+					lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+					parentState:  db,
+				},
+				syncing: tt.syncing,
+			}
+			blk, err := ParseStatefulBlock(ctx, &StatefulBlock{
+				Prnt:      ids.GenerateTestID(),
+				Tmstmp:    1_000,
+				Hght:      1,
+				Txs:       []*Transaction{tx},
+				StateRoot: parentRoot,
+			}, nil, choices.Processing, vm)
+			require.NoError(err)
+			if tt.verify {
+				require.NoError(blk.innerVerify(ctx, &offlineTestVerifyContext{db}))
+			}
+
+			require.NoError(blk.Accept(ctx))
+			require.Equal(tt.updates, vm.updates)
+			root, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+			if !tt.committed {
+				// The block is accepted once the sync finishes
+				require.False(blk.Processed())
+				require.Zero(vm.commits)
+				require.Empty(vm.accepted)
+				require.Equal(parentRoot, root)
+				return
+			}
+
+			// The block is executed and committed exactly once
+			require.True(blk.Processed())
+			require.Equal(1, vm.commits)
+			require.Equal([]ids.ID{blk.ID()}, vm.accepted)
+			require.NotEqual(parentRoot, root)
+			require.Equal(choices.Accepted, blk.Status())
+		})
+	}
+}
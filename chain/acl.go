@@ -0,0 +1,106 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// aclGranted is stored at the [ACLKey] of each address granted access to a
+// restricted action.
+var aclGranted = []byte{0x1}
+
+// HasAccess returns true if [addr] may submit actions with [actionTypeID]
+// (when restricted by [Rules.IsActionRestricted]).
+func HasAccess(
+	ctx context.Context,
+	im state.Immutable,
+	sm StateManager,
+	actionTypeID uint8,
+	addr codec.Address,
+) (bool, error) {
+	_, err := im.GetValue(ctx, ACLKey(sm.ACLKey(actionTypeID, addr)))
+	if errors.Is(err, database.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GrantAccess allows [addr] to submit actions with [actionTypeID].
+//
+// Callers are responsible for ensuring only [Rules.GetACLAdmin] can grant
+// access.
+func GrantAccess(
+	ctx context.Context,
+	mu state.Mutable,
+	sm StateManager,
+	actionTypeID uint8,
+	addr codec.Address,
+) error {
+	return mu.Insert(ctx, ACLKey(sm.ACLKey(actionTypeID, addr)), aclGranted)
+}
+
+// RevokeAccess prevents [addr] from submitting actions with [actionTypeID].
+//
+// Callers are responsible for ensuring only [Rules.GetACLAdmin] can revoke
+// access.
+func RevokeAccess(
+	ctx context.Context,
+	mu state.Mutable,
+	sm StateManager,
+	actionTypeID uint8,
+	addr codec.Address,
+) error {
+	return mu.Remove(ctx, ACLKey(sm.ACLKey(actionTypeID, addr)))
+}
+
+// aclKeys returns the [ACLKey] of the actor for each restricted action in [t].
+func (t *Transaction) aclKeys(sm StateManager, r Rules) []string {
+	var aclKeys []string
+	for _, action := range t.Actions {
+		typeID := action.GetTypeID()
+		if !r.IsActionRestricted(typeID) {
+			continue
+		}
+		aclKeys = append(aclKeys, string(ACLKey(sm.ACLKey(typeID, t.Auth.Actor()))))
+	}
+	return aclKeys
+}
+
+// verifyAccess ensures the actor of [t] has been granted access to every
+// restricted action in [t].
+func (t *Transaction) verifyAccess(ctx context.Context, sm StateManager, r Rules, im state.Immutable) error {
+	stateKeys, err := t.StateKeys(sm, r)
+	if err != nil {
+		return err
+	}
+	for i, action := range t.Actions {
+		typeID := action.GetTypeID()
+		if !r.IsActionRestricted(typeID) {
+			continue
+		}
+
+		// [stateKeys] may have been cached before [typeID] was restricted (if
+		// the rules changed while [t] was pending), in which case the access
+		// list can't be read.
+		if _, ok := stateKeys[string(ACLKey(sm.ACLKey(typeID, t.Auth.Actor())))]; !ok {
+			return fmt.Errorf("%w: action type %d at index %d", ErrActionNotPermitted, typeID, i)
+		}
+		ok, err := HasAccess(ctx, im, sm, typeID, t.Auth.Actor())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: action type %d at index %d", ErrActionNotPermitted, typeID, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// aclTestRules restricts [testAction] on top of the rules returned by
+// [newOfflineTestRules].
+type aclTestRules struct {
+	Rules
+
+	restricted bool
+}
+
+func (r *aclTestRules) IsActionRestricted(typeID uint8) bool {
+	return r.restricted && typeID == (&testAction{}).GetTypeID()
+}
+
+func newACLTestTx(require *require.Assertions, chainID ids.ID, factory *testAuthFactory) *Transaction {
+	actionRegistry, authRegistry := (&testParser{}).Registry()
+	tx := NewTx(
+		&Base{Timestamp: 2_000, ChainID: chainID, MaxFee: 1_000_000},
+		[]Action{&testAction{payload: []byte{0}}},
+	)
+	tx, err := tx.Sign(factory, actionRegistry, authRegistry)
+	require.NoError(err)
+	return tx
+}
+
+func TestActionAccessControl(t *testing.T) {
+	var (
+		sm       = &testStateManager{}
+		chainID  = ids.GenerateTestID()
+		typeID   = (&testAction{}).GetTypeID()
+		granted  = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		revoked  = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		unlisted = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+	)
+	tests := []struct {
+		name       string
+		factory    *testAuthFactory
+		restricted bool
+		err        error
+	}{
+		{
+			name:       "granted",
+			factory:    granted,
+			restricted: true,
+		},
+		{
+			name:       "revoked",
+			factory:    revoked,
+			restricted: true,
+			err:        ErrActionNotPermitted,
+		},
+		{
+			name:       "never granted",
+			factory:    unlisted,
+			restricted: true,
+			err:        ErrActionNotPermitted,
+		},
+		{
+			name:    "never granted unrestricted",
+			factory: unlisted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			r := &aclTestRules{newOfflineTestRules(gomock.NewController(t), chainID), tt.restricted}
+
+			s := make(taskTestState)
+			require.NoError(GrantAccess(ctx, s, sm, typeID, granted.actor))
+			require.NoError(GrantAccess(ctx, s, sm, typeID, revoked.actor))
+			require.NoError(RevokeAccess(ctx, s, sm, typeID, revoked.actor))
+
+			// The access list of the actor is only read if the action is
+			// restricted
+			tx := newACLTestTx(require, chainID, tt.factory)
+			stateKeys, err := tx.StateKeys(sm, r)
+			require.NoError(err)
+			aclKey := string(ACLKey(sm.ACLKey(typeID, tt.factory.actor)))
+			if tt.restricted {
+				require.Contains(stateKeys, aclKey)
+			} else {
+				require.NotContains(stateKeys, aclKey)
+			}
+
+			feeManager := fees.NewManager(nil)
+			err = tx.PreExecute(ctx, feeManager, sm, r, s, 1_000)
+			require.ErrorIs(err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// adversarialBuilder produces blocks on top of the state returned by
+// [newOfflineTestState] that are valid except for a single targeted defect
+// (like a malicious proposer would).
+//
+// The defects every VM must reject are checked by
+// [chaintest.RunAdversarialBlocks].
+type adversarialBuilder struct {
+	chainID    ids.ID
+	parentRoot ids.ID
+	factory    *testAuthFactory
+}
+
+// tx returns a signed transaction that expires at [timestamp].
+func (b *adversarialBuilder) tx(require *require.Assertions, timestamp int64, chainID ids.ID, payload byte) *Transaction {
+	actionRegistry, authRegistry := (&testParser{}).Registry()
+	tx := NewTx(
+		&Base{Timestamp: timestamp, ChainID: chainID, MaxFee: 1_000_000},
+		[]Action{&testAction{payload: []byte{payload}}},
+	)
+	tx, err := tx.Sign(b.factory, actionRegistry, authRegistry)
+	require.NoError(err)
+	return tx
+}
+
+// block returns a valid child of genesis with 3 transactions.
+func (b *adversarialBuilder) block(require *require.Assertions) *StatefulBlock {
+	blk := &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    1_000,
+		Hght:      1,
+		Txs:       []*Transaction{},
+		StateRoot: b.parentRoot,
+	}
+	for i := 0; i < 3; i++ {
+		blk.Txs = append(blk.Txs, b.tx(require, 2_000, b.chainID, byte(i)))
+	}
+	return blk
+}
+
+// parseAndVerify parses [raw] and verifies it on top of [vctx] (as a node
+// receiving the block from a proposer would).
+func parseAndVerify(ctx context.Context, vm VM, vctx VerifyContext, raw []byte) error {
+	blk, err := UnmarshalBlock(raw, &testParser{})
+	if err != nil {
+		return err
+	}
+	sblk, err := ParseStatefulBlock(ctx, blk, raw, choices.Processing, vm)
+	if err != nil {
+		return err
+	}
+	return sblk.innerVerify(ctx, vctx)
+}
+
+// unitsTestRules overrides the max block units of the rules returned by
+// [newOfflineTestRules].
+type unitsTestRules struct {
+	Rules
+
+	maxUnits fees.Dimensions
+}
+
+func (r *unitsTestRules) GetMaxBlockUnits() fees.Dimensions { return r.maxUnits }
+
+// viewCountingVerifyContext records how many times the parent state is loaded.
+type viewCountingVerifyContext struct {
+	offlineTestVerifyContext
+
+	views int
+}
+
+func (c *viewCountingVerifyContext) View(ctx context.Context, verify bool) (state.View, error) {
+	c.views++
+	return c.offlineTestVerifyContext.View(ctx, verify)
+}
+
+func TestBlockUnitsExceeded(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		txs   uint64 // max block units fit this many txs
+		err   error
+		views int
+	}{
+		{
+			name:  "within bounds",
+			txs:   3,
+			views: 1,
+		},
+		{
+			name: "over max",
+			txs:  2,
+			err:  ErrBlockUnitsExceeded,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			db, parentRoot := newOfflineTestState(ctx, require)
+			b := &adversarialBuilder{
+				chainID:    ids.GenerateTestID(),
+				parentRoot: parentRoot,
+				factory:    &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())},
+			}
+			r := newOfflineTestRules(gomock.NewController(t), b.chainID)
+			blk := b.block(require)
+
+			// All txs in the block require the same units
+			units, err := blk.Txs[0].Units(&testStateManager{}, r, blk.Tmstmp)
+			require.NoError(err)
+			maxUnits := fees.Dimensions{}
+			for i := range maxUnits {
+				maxUnits[i] = units[i] * tt.txs
+			}
+			vm := &offlineTestVM{
+				r:            &unitsTestRules{r, maxUnits},
+				lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+			}
+			raw, err := blk.Marshal(vm)
+			require.NoError(err)
+
+			// Over-budget blocks are rejected before loading state
+			vctx := &viewCountingVerifyContext{offlineTestVerifyContext: offlineTestVerifyContext{db}}
+			require.ErrorIs(parseAndVerify(ctx, vm, vctx, raw), tt.err)
+			require.Equal(tt.views, vctx.views)
+		})
+	}
+}
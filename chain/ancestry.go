@@ -0,0 +1,125 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+)
+
+const (
+	// ancestryFilterBits is the size of each [ancestryFilter] (128 KiB).
+	ancestryFilterBits   = 1 << 20
+	ancestryFilterHashes = 4
+
+	// MaxAncestryFilterTxs is the maximum number of transactions tracked by
+	// an [ancestryFilter] (~1% false positives at capacity). Blocks with more
+	// transactions in their processing ancestry fall back to checking every
+	// processing ancestor.
+	MaxAncestryFilterTxs = 100_000
+	// MaxAncestryFilterDepth is the maximum number of processing blocks
+	// tracked by an [ancestryFilter].
+	MaxAncestryFilterDepth = 1_024
+)
+
+// ancestryFilter is a bloom filter of the IDs of all transactions included in
+// a processing block and its processing ancestors. It allows [IsRepeat] to
+// only compare the transactions that may be repeats against each processing
+// ancestor.
+//
+// Transactions in ancestors that have since been accepted remain in the filter
+// (and are resolved by the exact check).
+type ancestryFilter struct {
+	bits  []uint64
+	txs   int
+	depth int
+}
+
+func newAncestryFilter() *ancestryFilter {
+	return &ancestryFilter{bits: make([]uint64, ancestryFilterBits/64)}
+}
+
+// child returns a copy of [f] that also includes [txs] (or nil if the copy
+// would exceed [MaxAncestryFilterTxs] or [MaxAncestryFilterDepth]).
+func (f *ancestryFilter) child(txs []*Transaction) *ancestryFilter {
+	if f.txs+len(txs) > MaxAncestryFilterTxs || f.depth+1 > MaxAncestryFilterDepth {
+		return nil
+	}
+	c := &ancestryFilter{
+		bits:  make([]uint64, len(f.bits)),
+		txs:   f.txs + len(txs),
+		depth: f.depth + 1,
+	}
+	copy(c.bits, f.bits)
+	for _, tx := range txs {
+		c.add(tx.ID())
+	}
+	return c
+}
+
+// ancestryHash returns the [i]th bit index of [id] (transaction IDs are
+// already uniformly distributed, so no additional hashing is required).
+func ancestryHash(id ids.ID, i int) uint64 {
+	return binary.BigEndian.Uint64(id[i*8:]) % ancestryFilterBits
+}
+
+func (f *ancestryFilter) add(id ids.ID) {
+	for i := 0; i < ancestryFilterHashes; i++ {
+		h := ancestryHash(id, i)
+		f.bits[h/64] |= 1 << (h % 64)
+	}
+}
+
+func (f *ancestryFilter) contains(id ids.ID) bool {
+	for i := 0; i < ancestryFilterHashes; i++ {
+		h := ancestryHash(id, i)
+		if f.bits[h/64]&(1<<(h%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// getAncestryFilter returns the [ancestryFilter] of [b] (which must be
+// processing), populating it from its parent if this is the first call.
+//
+// If nil is returned, all processing ancestors of [b] must be checked.
+func (b *StatelessBlock) getAncestryFilter(ctx context.Context) (*ancestryFilter, error) {
+	b.ancestryL.Lock()
+	defer b.ancestryL.Unlock()
+
+	if b.ancestryPopulated {
+		return b.ancestry, nil
+	}
+	var parentFilter *ancestryFilter
+	parent, err := b.vm.GetStatelessBlock(ctx, b.Prnt)
+	if err != nil {
+		return nil, err
+	}
+	if parent.st == choices.Accepted || parent.Hght == 0 /* genesis */ {
+		parentFilter = newAncestryFilter()
+	} else {
+		parentFilter, err = parent.getAncestryFilter(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if parentFilter != nil {
+		b.ancestry = parentFilter.child(b.Txs)
+	}
+	b.ancestryPopulated = true
+	return b.ancestry, nil
+}
+
+// freeAncestryFilter releases the [ancestryFilter] of [b] once it is decided.
+func (b *StatelessBlock) freeAncestryFilter() {
+	b.ancestryL.Lock()
+	defer b.ancestryL.Unlock()
+
+	b.ancestry = nil
+	b.ancestryPopulated = true
+}
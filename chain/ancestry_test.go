@@ -0,0 +1,313 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/stretchr/testify/require"
+)
+
+// ancestryTestVM serves processing blocks from memory and replays the
+// transactions of accepted blocks (as the emap would).
+type ancestryTestVM struct {
+	VM
+
+	blocks     map[ids.ID]*StatelessBlock
+	accepted   set.Set[ids.ID]
+	timestamps map[uint64]int64 // of accepted blocks
+
+	maxDepth int // see [Rules.GetMaxRepeatCheckDepth]
+}
+
+type ancestryTestRules struct {
+	Rules
+
+	maxDepth int
+}
+
+func (r *ancestryTestRules) GetMaxRepeatCheckDepth() int { return r.maxDepth }
+
+func (*ancestryTestVM) Tracer() trace.Tracer { return trace.Noop }
+
+func (vm *ancestryTestVM) Rules(int64) Rules { return &ancestryTestRules{maxDepth: vm.maxDepth} }
+
+func (vm *ancestryTestVM) GetStatelessBlock(_ context.Context, blkID ids.ID) (*StatelessBlock, error) {
+	blk, ok := vm.blocks[blkID]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return blk, nil
+}
+
+func (vm *ancestryTestVM) GetBlockHeightTimestamp(height uint64) (int64, error) {
+	timestamp, ok := vm.timestamps[height]
+	if !ok {
+		return 0, database.ErrNotFound
+	}
+	return timestamp, nil
+}
+
+func (vm *ancestryTestVM) IsRepeat(_ context.Context, txs []*Transaction, marker set.Bits, stop bool) set.Bits {
+	for i, tx := range txs {
+		if marker.Contains(i) {
+			continue
+		}
+		if vm.accepted.Contains(tx.ID()) {
+			marker.Add(i)
+			if stop {
+				return marker
+			}
+		}
+	}
+	return marker
+}
+
+func (vm *ancestryTestVM) addBlock(parent *StatelessBlock, tmstmp int64, txs []*Transaction, st choices.Status) *StatelessBlock {
+	blk := &StatelessBlock{
+		StatefulBlock: &StatefulBlock{
+			Tmstmp: tmstmp,
+			Txs:    txs,
+		},
+		id:     ids.GenerateTestID(),
+		st:     st,
+		txsSet: set.NewSet[ids.ID](len(txs)),
+		vm:     vm,
+	}
+	if parent != nil {
+		blk.Prnt = parent.id
+		blk.Hght = parent.Hght + 1
+	}
+	for _, tx := range txs {
+		blk.txsSet.Add(tx.ID())
+		if st == choices.Accepted {
+			vm.accepted.Add(tx.ID())
+		}
+	}
+	if st == choices.Accepted {
+		vm.timestamps[blk.Hght] = tmstmp
+	}
+	vm.blocks[blk.id] = blk
+	return blk
+}
+
+// exactIsRepeat compares [txs] against every processing ancestor of [b].
+func exactIsRepeat(
+	ctx context.Context,
+	b *StatelessBlock,
+	oldestAllowed int64,
+	txs []*Transaction,
+	marker set.Bits,
+	stop bool,
+) (set.Bits, error) {
+	if b.Tmstmp < oldestAllowed {
+		return marker, nil
+	}
+	if b.st == choices.Accepted || b.Hght == 0 {
+		return b.vm.IsRepeat(ctx, txs, marker, stop), nil
+	}
+	for i, tx := range txs {
+		if marker.Contains(i) {
+			continue
+		}
+		if b.txsSet.Contains(tx.ID()) {
+			marker.Add(i)
+			if stop {
+				return marker, nil
+			}
+		}
+	}
+	prnt, err := b.vm.GetStatelessBlock(ctx, b.Prnt)
+	if err != nil {
+		return marker, err
+	}
+	return exactIsRepeat(ctx, prnt, oldestAllowed, txs, marker, stop)
+}
+
+func newAncestryTestTxs(n int) []*Transaction {
+	txs := make([]*Transaction, n)
+	for i := range txs {
+		txs[i] = &Transaction{id: ids.GenerateTestID()}
+	}
+	return txs
+}
+
+func TestIsRepeatMatchesExactWalk(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	rng := rand.New(rand.NewSource(0)) //nolint:gosec
+
+	for round := 0; round < 50; round++ {
+		vm := &ancestryTestVM{
+			blocks:     map[ids.ID]*StatelessBlock{},
+			accepted:   set.Set[ids.ID]{},
+			timestamps: map[uint64]int64{},
+		}
+
+		// Build an accepted chain followed by a processing chain (with forks)
+		var (
+			all       []*Transaction
+			tmstmp    int64
+			blk       = vm.addBlock(nil, 0, nil, choices.Accepted)
+			accepted  = 1 + rng.Intn(5)
+			processed = 1 + rng.Intn(30)
+			tips      []*StatelessBlock
+		)
+		for i := 0; i < accepted+processed; i++ {
+			st := choices.Processing
+			if i < accepted {
+				st = choices.Accepted
+			}
+			tmstmp += int64(rng.Intn(3))
+			txs := newAncestryTestTxs(rng.Intn(20))
+			all = append(all, txs...)
+			blk = vm.addBlock(blk, tmstmp, txs, st)
+			if st == choices.Processing && rng.Intn(4) == 0 {
+				// Forks are never checked against the main chain
+				tips = append(tips, vm.addBlock(blk, tmstmp, newAncestryTestTxs(5), choices.Processing))
+			}
+		}
+		tips = append(tips, blk)
+
+		for _, tip := range tips {
+			for _, stop := range []bool{true, false} {
+				// Check a mix of new transactions and transactions from across
+				// the ancestry (including some outside of the validity window)
+				txs := newAncestryTestTxs(10)
+				for i := 0; i < 10; i++ {
+					txs = append(txs, all[rng.Intn(len(all))])
+				}
+				rng.Shuffle(len(txs), func(i, j int) { txs[i], txs[j] = txs[j], txs[i] })
+				oldestAllowed := rng.Int63n(tmstmp + 1)
+
+				expected, err := exactIsRepeat(ctx, tip, oldestAllowed, txs, set.NewBits(), stop)
+				require.NoError(err)
+				actual, err := tip.IsRepeat(ctx, oldestAllowed, txs, set.NewBits(), stop)
+				require.NoError(err)
+				if stop {
+					require.Equal(expected.Len() > 0, actual.Len() > 0)
+				} else {
+					require.Equal(expected, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestAncestryFilterBounds(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	vm := &ancestryTestVM{
+		blocks:     map[ids.ID]*StatelessBlock{},
+		accepted:   set.Set[ids.ID]{},
+		timestamps: map[uint64]int64{},
+	}
+	genesis := vm.addBlock(nil, 0, nil, choices.Accepted)
+	parent := vm.addBlock(genesis, 1, newAncestryTestTxs(10), choices.Processing)
+	child := vm.addBlock(parent, 2, newAncestryTestTxs(10), choices.Processing)
+
+	// The filter of a block includes the transactions of its processing
+	// ancestors
+	filter, err := child.getAncestryFilter(ctx)
+	require.NoError(err)
+	require.Equal(20, filter.txs)
+	require.Equal(2, filter.depth)
+	for _, tx := range append(parent.Txs, child.Txs...) {
+		require.True(filter.contains(tx.ID()))
+	}
+
+	// Filters are not extended beyond the max number of transactions or depth
+	filter.txs = MaxAncestryFilterTxs
+	require.Nil(filter.child(newAncestryTestTxs(1)))
+	filter.txs, filter.depth = 0, MaxAncestryFilterDepth
+	require.Nil(filter.child(nil))
+
+	// Filters are freed once blocks are decided
+	parent.freeAncestryFilter()
+	filter, err = parent.getAncestryFilter(ctx)
+	require.NoError(err)
+	require.Nil(filter)
+
+	// Blocks without a filter check all processing ancestors
+	grandchild := vm.addBlock(child, 3, nil, choices.Processing)
+	child.freeAncestryFilter()
+	repeats, err := grandchild.IsRepeat(ctx, 0, parent.Txs, set.NewBits(), false)
+	require.NoError(err)
+	require.Equal(len(parent.Txs), repeats.Len())
+}
+
+func TestIsRepeatMaxDepth(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	vm := &ancestryTestVM{
+		blocks:     map[ids.ID]*StatelessBlock{},
+		accepted:   set.Set[ids.ID]{},
+		timestamps: map[uint64]int64{},
+		maxDepth:   10,
+	}
+	blk := vm.addBlock(nil, 0, nil, choices.Accepted)
+	oldest := vm.addBlock(blk, 1, newAncestryTestTxs(1), choices.Processing)
+	blk = oldest
+	for i := 2; i <= 10; i++ {
+		blk = vm.addBlock(blk, int64(i), newAncestryTestTxs(1), choices.Processing)
+	}
+
+	// Windows that span at most [maxDepth] heights succeed
+	repeats, err := blk.IsRepeat(ctx, 1, oldest.Txs, set.NewBits(), true)
+	require.NoError(err)
+	require.Equal(1, repeats.Len())
+
+	// Windows that span more heights fail (even if a repeat would be found
+	// first)
+	_, err = blk.IsRepeat(ctx, 0, oldest.Txs, set.NewBits(), true)
+	require.ErrorIs(err, ErrReplayCheckTooDeep)
+	_, err = blk.IsRepeat(ctx, 0, blk.Txs, set.NewBits(), true)
+	require.ErrorIs(err, ErrReplayCheckTooDeep)
+	blk = vm.addBlock(blk, 11, newAncestryTestTxs(1), choices.Processing)
+	repeats, err = blk.IsRepeat(ctx, 2, oldest.Txs, set.NewBits(), true)
+	require.NoError(err)
+	require.Zero(repeats.Len())
+
+	// 0 is unlimited
+	vm.maxDepth = 0
+	repeats, err = blk.IsRepeat(ctx, 0, oldest.Txs, set.NewBits(), true)
+	require.NoError(err)
+	require.Equal(1, repeats.Len())
+}
+
+func TestIsRepeatMaxDepthAccepted(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	// The result only depends on the chain (and not on which of its blocks
+	// are accepted)
+	for accepted := 0; accepted <= 20; accepted++ {
+		vm := &ancestryTestVM{
+			blocks:     map[ids.ID]*StatelessBlock{},
+			accepted:   set.Set[ids.ID]{},
+			timestamps: map[uint64]int64{},
+			maxDepth:   10,
+		}
+		blk := vm.addBlock(nil, 0, nil, choices.Accepted)
+		for i := 1; i <= 20; i++ {
+			st := choices.Processing
+			if i <= accepted {
+				st = choices.Accepted
+			}
+			blk = vm.addBlock(blk, int64(i), newAncestryTestTxs(1), st)
+		}
+		child := vm.addBlock(blk, 21, newAncestryTestTxs(1), choices.Processing)
+		_, err := child.IsRepeat(ctx, 11, child.Txs, set.NewBits(), true)
+		require.ErrorIs(err, ErrReplayCheckTooDeep)
+		_, err = child.IsRepeat(ctx, 12, child.Txs, set.NewBits(), true)
+		require.NoError(err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
 
 	"github.com/ava-labs/hypersdk/workers"
 )
@@ -22,20 +23,42 @@ type AuthVM interface {
 // may perform complex cryptographic operations. We should
 // not block the caller when this happens and we should
 // not require each batch package to re-implement this logic.
+//
+// Once [ctx] is done, any signatures that have not been verified yet are
+// skipped (and the job fails with the error of [ctx]).
+//
+// If [vm] implements [AuthOffloadVM], signatures of the auth types supported
+// by its [AuthOffloader] (when the batch is created) are offloaded instead.
 type AuthBatch struct {
+	ctx context.Context
 	vm  AuthVM
 	job workers.Job
 	bvs map[uint8]*authBatchWorker
+
+	offloader AuthOffloader
+	offloaded map[uint8][]*authBatchObject
 }
 
-func NewAuthBatch(vm AuthVM, job workers.Job, authTypes map[uint8]int) *AuthBatch {
-	bvs := map[uint8]*authBatchWorker{}
+func NewAuthBatch(ctx context.Context, vm AuthVM, job workers.Job, authTypes map[uint8]int) *AuthBatch {
+	var (
+		bvs       = map[uint8]*authBatchWorker{}
+		offloader AuthOffloader
+		offloaded = map[uint8][]*authBatchObject{}
+	)
+	if ovm, ok := vm.(AuthOffloadVM); ok {
+		offloader = ovm.GetAuthOffloader()
+	}
 	for t, count := range authTypes {
+		if offloader != nil && offloader.Supports(t) {
+			offloaded[t] = make([]*authBatchObject, 0, min(count, offloader.BatchSize()))
+			continue
+		}
 		bv, ok := vm.GetAuthBatchVerifier(t, job.Workers(), count)
 		if !ok {
 			continue
 		}
 		bw := &authBatchWorker{
+			ctx,
 			vm,
 			job,
 			bv,
@@ -45,39 +68,98 @@ func NewAuthBatch(vm AuthVM, job workers.Job, authTypes map[uint8]int) *AuthBatc
 		go bw.start()
 		bvs[t] = bw
 	}
-	return &AuthBatch{vm, job, bvs}
+	return &AuthBatch{ctx, vm, job, bvs, offloader, offloaded}
 }
 
 func (a *AuthBatch) Add(digest []byte, auth Auth) {
+	t := auth.GetTypeID()
+	if items, ok := a.offloaded[t]; ok {
+		if _, ok := auth.(OffloadableAuth); ok {
+			items = append(items, &authBatchObject{digest, auth})
+			if len(items) < a.offloader.BatchSize() {
+				a.offloaded[t] = items
+				return
+			}
+			a.offload(t, items)
+			a.offloaded[t] = make([]*authBatchObject, 0, len(items))
+			return
+		}
+	}
+
 	// If batch doesn't exist for auth, just add verify right to job and start
 	// processing.
-	bv, ok := a.bvs[auth.GetTypeID()]
+	bv, ok := a.bvs[t]
 	if !ok {
-		a.job.Go(func() error { return auth.Verify(context.TODO(), digest) })
+		goAuthTask(a.ctx, a.job, func() error { return auth.Verify(a.ctx, digest) })
 		return
 	}
 	bv.items <- &authBatchObject{digest, auth}
 }
 
 func (a *AuthBatch) Done(f func()) {
+	for t, items := range a.offloaded {
+		if len(items) > 0 {
+			a.offload(t, items)
+		}
+	}
 	for _, bw := range a.bvs {
 		close(bw.items)
 		<-bw.done
 
 		for _, item := range bw.bv.Done() {
-			a.job.Go(item)
+			goAuthTask(a.ctx, a.job, item)
 			a.vm.Logger().Debug("enqueued batch for processing during done")
 		}
 	}
 	a.job.Done(f)
 }
 
+// offload adds a job that verifies [items] with [a.offloader] and falls back
+// to verifying them locally if it does not confirm they are all valid.
+func (a *AuthBatch) offload(authTypeID uint8, items []*authBatchObject) {
+	goAuthTask(a.ctx, a.job, func() error {
+		ctx := a.ctx
+		sigs := make([]*OffloadedSignature, len(items))
+		for i, item := range items {
+			publicKey, signature := item.auth.(OffloadableAuth).SignatureData()
+			sigs[i] = &OffloadedSignature{item.digest, publicKey, signature}
+		}
+		err := a.offloader.Verify(ctx, authTypeID, sigs)
+		if err == nil {
+			return nil
+		}
+		a.vm.Logger().Debug("verifying offloaded batch locally",
+			zap.Uint8("authTypeID", authTypeID),
+			zap.Int("size", len(items)),
+			zap.Error(err),
+		)
+		for _, item := range items {
+			if err := item.auth.Verify(ctx, item.digest); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// goAuthTask adds [f] to [job] unless [ctx] is done by the time a worker
+// picks it up.
+func goAuthTask(ctx context.Context, job workers.Job, f func() error) {
+	job.Go(func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return f()
+	})
+}
+
 type authBatchObject struct {
 	digest []byte
 	auth   Auth
 }
 
 type authBatchWorker struct {
+	ctx   context.Context
 	vm    AuthVM
 	job   workers.Job
 	bv    AuthBatchVerifier
@@ -91,7 +173,7 @@ func (b *authBatchWorker) start() {
 	for object := range b.items {
 		if j := b.bv.Add(object.digest, object.auth); j != nil {
 			// May finish parts of batch early, let's start computing them as soon as possible
-			b.job.Go(j)
+			goAuthTask(b.ctx, b.job, j)
 			b.vm.Logger().Debug("enqueued batch for processing during add")
 		}
 	}
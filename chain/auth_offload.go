@@ -0,0 +1,49 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import "context"
+
+// OffloadableAuth is an [Auth] whose signature can be verified by an
+// [AuthOffloader] (outside of this process).
+type OffloadableAuth interface {
+	Auth
+
+	// SignatureData returns the public key and signature that [Verify]
+	// checks against the tx digest.
+	SignatureData() (publicKey []byte, signature []byte)
+}
+
+// OffloadedSignature is a signature verified by an [AuthOffloader].
+type OffloadedSignature struct {
+	Digest    []byte
+	PublicKey []byte
+	Signature []byte
+}
+
+// AuthOffloader verifies batches of signatures remotely (like on a sidecar
+// process) to free up cores for execution.
+//
+// Only a nil error from [Verify] is trusted. Any error (including an invalid
+// signature) results in the batch being verified locally, so an unavailable
+// or misbehaving offloader can slow verification but never changes its
+// result.
+type AuthOffloader interface {
+	// Supports returns true if signatures of [authTypeID] can currently be
+	// offloaded.
+	Supports(authTypeID uint8) bool
+
+	// BatchSize is the number of signatures sent in each call to [Verify].
+	BatchSize() int
+
+	// Verify returns nil if all [sigs] are valid signatures of [authTypeID].
+	Verify(ctx context.Context, authTypeID uint8, sigs []*OffloadedSignature) error
+}
+
+// AuthOffloadVM is optionally implemented by an [AuthVM] to offload the
+// signature verification of blocks it did not build.
+type AuthOffloadVM interface {
+	// GetAuthOffloader returns nil if offloading is disabled.
+	GetAuthOffloader() AuthOffloader
+}
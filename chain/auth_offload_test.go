@@ -0,0 +1,171 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+var errOffloadFailed = errors.New("offload failed")
+
+// offloadTestAuth is valid if its signature is the digest it is verified
+// against (and counts how many times it is verified locally).
+type offloadTestAuth struct {
+	testAuth
+
+	typeID    uint8
+	signature []byte
+	local     *atomic.Int64
+}
+
+func (a *offloadTestAuth) GetTypeID() uint8 { return a.typeID }
+
+func (a *offloadTestAuth) Verify(_ context.Context, digest []byte) error {
+	a.local.Add(1)
+	if !bytes.Equal(a.signature, digest) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (a *offloadTestAuth) SignatureData() ([]byte, []byte) {
+	return nil, a.signature
+}
+
+// offloadTestOffloader answers each batch of the auth types in [supported]
+// with [answer].
+type offloadTestOffloader struct {
+	supported set.Set[uint8]
+	batchSize int
+	answer    func([]*OffloadedSignature) error
+
+	lock    sync.Mutex
+	batches []int
+}
+
+func (o *offloadTestOffloader) Supports(authTypeID uint8) bool {
+	return o.supported.Contains(authTypeID)
+}
+func (o *offloadTestOffloader) BatchSize() int { return o.batchSize }
+
+func (o *offloadTestOffloader) Verify(_ context.Context, _ uint8, sigs []*OffloadedSignature) error {
+	o.lock.Lock()
+	o.batches = append(o.batches, len(sigs))
+	o.lock.Unlock()
+	return o.answer(sigs)
+}
+
+type offloadTestVM struct {
+	offloader AuthOffloader
+}
+
+func (*offloadTestVM) Logger() logging.Logger { return logging.NoLog{} }
+
+func (*offloadTestVM) GetAuthBatchVerifier(uint8, int, int) (AuthBatchVerifier, bool) {
+	return nil, false
+}
+
+func (vm *offloadTestVM) GetAuthOffloader() AuthOffloader { return vm.offloader }
+
+// verifyOffloadTestBatch verifies [count] auths of [typeID] (with the
+// signature of [invalid] not matching its digest, if non-negative) and
+// returns how many auths were verified locally and the result of the job.
+func verifyOffloadTestBatch(require *require.Assertions, offloader *offloadTestOffloader, typeID uint8, count int, invalid int) (int64, error) {
+	job, err := workers.NewSerial().NewJob(count)
+	require.NoError(err)
+
+	var local atomic.Int64
+	batch := NewAuthBatch(context.TODO(), &offloadTestVM{offloader}, job, map[uint8]int{typeID: count})
+	for i := 0; i < count; i++ {
+		digest := []byte{byte(i)}
+		signature := digest
+		if i == invalid {
+			signature = []byte{byte(i) + 1}
+		}
+		batch.Add(digest, &offloadTestAuth{typeID: typeID, signature: signature, local: &local})
+	}
+	batch.Done(nil)
+	err = job.Wait()
+	return local.Load(), err
+}
+
+func TestAuthBatchOffload(t *testing.T) {
+	valid := func([]*OffloadedSignature) error { return nil }
+	checked := func(sigs []*OffloadedSignature) error {
+		for _, sig := range sigs {
+			if !bytes.Equal(sig.Digest, sig.Signature) {
+				return ErrInvalidSignature
+			}
+		}
+		return nil
+	}
+	failed := func([]*OffloadedSignature) error { return errOffloadFailed }
+
+	tests := []struct {
+		name    string
+		typeID  uint8
+		answer  func([]*OffloadedSignature) error
+		invalid int
+
+		expectedErr     error
+		expectedBatches []int
+		expectedLocal   int64
+	}{
+		{
+			name:            "offloaded",
+			answer:          checked,
+			invalid:         -1,
+			expectedBatches: []int{2, 2, 1},
+		},
+		{
+			name:            "invalid batch verified locally",
+			answer:          checked,
+			invalid:         4,
+			expectedErr:     ErrInvalidSignature,
+			expectedBatches: []int{2, 2, 1},
+			expectedLocal:   1,
+		},
+		{
+			name:            "failed batches verified locally",
+			answer:          failed,
+			invalid:         -1,
+			expectedBatches: []int{2, 2, 1},
+			expectedLocal:   5,
+		},
+		{
+			name:          "unsupported type not offloaded",
+			typeID:        1,
+			answer:        valid, // would accept [invalid]
+			invalid:       4,
+			expectedErr:   ErrInvalidSignature,
+			expectedLocal: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			offloader := &offloadTestOffloader{
+				supported: set.Of[uint8](0),
+				batchSize: 2,
+				answer:    tt.answer,
+			}
+			local, err := verifyOffloadTestBatch(require, offloader, tt.typeID, 5, tt.invalid)
+			require.ErrorIs(err, tt.expectedErr)
+			require.Equal(tt.expectedLocal, local)
+			require.ElementsMatch(tt.expectedBatches, offloader.batches)
+		})
+	}
+}
@@ -57,12 +57,21 @@ func (b *Base) Marshal(p *codec.Packer) {
 
 func UnmarshalBase(p *codec.Packer) (*Base, error) {
 	var base Base
+	if err := unmarshalBase(p, &base); err != nil {
+		return nil, err
+	}
+	return &base, nil
+}
+
+// unmarshalBase unpacks a [Base] into [base] (which may be allocated with the
+// [Transaction] it belongs to).
+func unmarshalBase(p *codec.Packer, base *Base) error {
 	base.Timestamp = p.UnpackInt64(true)
 	if base.Timestamp%consts.MillisecondsPerSecond != 0 {
 		// TODO: make this modulus configurable
-		return nil, fmt.Errorf("%w: timestamp=%d", ErrMisalignedTime, base.Timestamp)
+		return fmt.Errorf("%w: timestamp=%d", ErrMisalignedTime, base.Timestamp)
 	}
 	p.UnpackID(true, &base.ChainID)
 	base.MaxFee = p.UnpackUint64(true)
-	return &base, p.Err()
+	return p.Err()
 }
@@ -7,12 +7,16 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/maybe"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 	"go.opentelemetry.io/otel/attribute"
@@ -23,14 +27,16 @@ import (
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
 	"github.com/ava-labs/hypersdk/utils"
 	"github.com/ava-labs/hypersdk/window"
 	"github.com/ava-labs/hypersdk/workers"
 )
 
 var (
-	_ snowman.Block      = &StatelessBlock{}
-	_ block.StateSummary = &SyncableBlock{}
+	_ snowman.Block           = &StatelessBlock{}
+	_ block.WithVerifyContext = &StatelessBlock{}
+	_ block.StateSummary      = &SyncableBlock{}
 )
 
 type StatefulBlock struct {
@@ -38,6 +44,17 @@ type StatefulBlock struct {
 	Tmstmp int64  `json:"timestamp"`
 	Hght   uint64 `json:"height"`
 
+	// Builder is the address of the node that produced this block. It is
+	// only populated when [Rules.GetRestrictBuilders] is enabled and is
+	// included in the block ID.
+	Builder codec.Address `json:"builder"`
+
+	// BuilderSignature is the BLS signature of [Builder] over the digest of
+	// this block (all of its bytes except the signature). It binds the block
+	// to the validator that produced it, so a block can't claim to be built
+	// by any other validator. It is only populated when [Builder] is.
+	BuilderSignature []byte `json:"builderSignature"`
+
 	Txs []*Transaction `json:"txs"`
 
 	// StateRoot is the root of the post-execution state
@@ -50,6 +67,21 @@ type StatefulBlock struct {
 	// starting the verification of another block, etc.
 	StateRoot ids.ID `json:"stateRoot"`
 
+	// ResultsRoot is the [merkle.Root] of the [Result] of each transaction
+	// in [Txs] (in order). Unlike [StateRoot], it commits to the execution of
+	// this block (not its parent) so that light clients can verify the result
+	// of a transaction with [VerifyResult].
+	//
+	// It is only populated (and encoded) when [Rules.GetIncludeResultsRoot]
+	// is enabled.
+	ResultsRoot ids.ID `json:"resultsRoot"`
+
+	// EpochPChainHeight is the P-Chain height of the validator set recorded
+	// by this block (see [EpochSnapshot]). It is only populated (and encoded)
+	// for the first block of each epoch (when [Rules.GetEpochLength] is
+	// non-zero).
+	EpochPChainHeight uint64 `json:"epochPChainHeight"`
+
 	size int
 
 	// authCounts can be used by batch signature verification
@@ -61,12 +93,14 @@ func (b *StatefulBlock) Size() int {
 	return b.size
 }
 
-func (b *StatefulBlock) ID() (ids.ID, error) {
-	blk, err := b.Marshal()
-	if err != nil {
+func (b *StatefulBlock) ID(parser Parser) (ids.ID, error) {
+	p := codec.GetWriter(b.marshalSize(), consts.NetworkSizeLimit)
+	defer codec.PutWriter(p)
+
+	if err := b.marshal(p, parser); err != nil {
 		return ids.ID{}, err
 	}
-	return utils.ToID(blk), nil
+	return utils.ToID(p.Bytes()), nil
 }
 
 func NewGenesisBlock(root ids.ID) *StatefulBlock {
@@ -98,13 +132,55 @@ type StatelessBlock struct {
 	bytes  []byte
 	txsSet set.Set[ids.ID]
 
+	// [ancestry] is populated (and cached) by the first call to [IsRepeat]
+	// while the block is processing.
+	ancestryL         sync.Mutex
+	ancestry          *ancestryFilter
+	ancestryPopulated bool
+
 	results    []*Result
 	feeManager *fees.Manager
 
+	// usageDiff is the change in [StateUsage] of each registered state prefix
+	// caused by executing this block (or nil if state usage is not tracked or
+	// the block was not executed by this node).
+	usageDiff []StateUsage
+
+	// epoch is the [EpochSnapshot] recorded by this block (or nil if it
+	// doesn't start an epoch).
+	epoch *EpochSnapshot
+
+	// When [Rules.GetDelayedExecution] is enabled, the transactions included in
+	// a block are executed by its child. [pendingTxs] are the transactions of
+	// the parent executed by this block (at [pendingTimestamp]) and
+	// [executedTxs] are all transactions executed by this block (in order).
+	delayed          bool
+	pendingTxs       []*Transaction
+	pendingTimestamp int64
+	executedTxs      []*Transaction
+
 	vm   VM
 	view merkledb.View
 
+	// committed is true if the changes of [b] were committed to the accepted
+	// state during verification (see [VM.CatchUpIncrementalRoots]).
+	committed bool
+
+	// verifiedContext is the P-Chain context [b] was last verified with (see
+	// [VerifyWithContext]).
+	verifiedContext *verifiedContext
+
 	sigJob workers.Job
+	// sigCancel stops the verification of any signatures in [sigJob] that
+	// have not been verified yet (once [b] is rejected).
+	sigCancel context.CancelFunc
+
+	// sigDone is closed once [sigJob] has completed (with result [sigErr]).
+	// This allows verification to stop waiting on [sigJob] (and be retried)
+	// if it is canceled.
+	sigOnce sync.Once
+	sigDone chan struct{}
+	sigErr  error
 }
 
 func NewBlock(vm VM, parent snowman.Block, tmstp int64) *StatelessBlock {
@@ -143,12 +219,17 @@ func (b *StatelessBlock) populateTxs(ctx context.Context) error {
 
 	// Setup signature verification job
 	_, sigVerifySpan := b.vm.Tracer().Start(ctx, "StatelessBlock.verifySignatures") //nolint:spancheck
+	//
+	// Signatures are verified with a context that outlives [ctx] (which is
+	// only valid while parsing) until [b] is rejected.
+	sigCtx, sigCancel := context.WithCancel(context.Background())
 	job, err := b.vm.AuthVerifiers().NewJob(len(b.Txs))
 	if err != nil {
+		sigCancel()
 		return err //nolint:spancheck
 	}
-	b.sigJob = job
-	batchVerifier := NewAuthBatch(b.vm, b.sigJob, b.authCounts)
+	b.sigJob, b.sigCancel = job, sigCancel
+	batchVerifier := NewAuthBatch(sigCtx, b.vm, b.sigJob, b.authCounts)
 
 	// Make sure to always call [Done], otherwise we will block all future [Workers]
 	defer func() {
@@ -190,25 +271,18 @@ func ParseStatefulBlock(
 	defer span.End()
 
 	// Perform basic correctness checks before doing any expensive work
-	if blk.Tmstmp > time.Now().Add(FutureBound).UnixMilli() {
+	if blk.Tmstmp > vm.Now().Add(FutureBound).UnixMilli() {
 		return nil, ErrTimestampTooLate
 	}
 
 	if len(source) == 0 {
-		nsource, err := blk.Marshal()
+		nsource, err := blk.Marshal(vm)
 		if err != nil {
 			return nil, err
 		}
 		source = nsource
 	}
-	b := &StatelessBlock{
-		StatefulBlock: blk,
-		t:             time.UnixMilli(blk.Tmstmp),
-		bytes:         source,
-		st:            status,
-		vm:            vm,
-		id:            utils.ToID(source),
-	}
+	b := newStatelessBlock(blk, source, status, vm)
 
 	// If we are parsing an older block, it will not be re-executed and should
 	// not be tracked as a parsed block
@@ -221,6 +295,19 @@ func ParseStatefulBlock(
 	return b, b.populateTxs(ctx)
 }
 
+// newStatelessBlock wraps [blk] (serialized as [source]) without performing
+// any verification.
+func newStatelessBlock(blk *StatefulBlock, source []byte, status choices.Status, vm VM) *StatelessBlock {
+	return &StatelessBlock{
+		StatefulBlock: blk,
+		t:             time.UnixMilli(blk.Tmstmp),
+		bytes:         source,
+		st:            status,
+		vm:            vm,
+		id:            utils.ToID(source),
+	}
+}
+
 // [initializeBuilt] is invoked after a block is built
 func (b *StatelessBlock) initializeBuilt(
 	ctx context.Context,
@@ -231,10 +318,24 @@ func (b *StatelessBlock) initializeBuilt(
 	_, span := b.vm.Tracer().Start(ctx, "StatelessBlock.initializeBuilt")
 	defer span.End()
 
-	blk, err := b.StatefulBlock.Marshal()
+	blk, err := b.StatefulBlock.Marshal(b.vm)
 	if err != nil {
 		return err
 	}
+	if b.Builder != codec.EmptyAddress {
+		// The signature is packed last (see [StatefulBlock.Marshal]), so it
+		// can be filled in without marshaling the block again
+		digest := builderDigest(blk)
+		signature, err := b.vm.SignBuilder(digest)
+		if err != nil {
+			return fmt.Errorf("%w: unable to sign block", err)
+		}
+		if len(signature) != bls.SignatureLen {
+			return ErrInvalidBuilderSignature
+		}
+		copy(blk[len(blk)-bls.SignatureLen:], signature)
+		b.BuilderSignature = signature
+	}
 	b.bytes = blk
 	b.id = utils.ToID(b.bytes)
 	b.view = view
@@ -253,6 +354,13 @@ func (b *StatelessBlock) ID() ids.ID { return b.id }
 
 // implements "snowman.Block"
 func (b *StatelessBlock) Verify(ctx context.Context) error {
+	return b.verify(ctx, nil)
+}
+
+// verify verifies [b] (with [bctx], if not nil) and notifies the [VM] if it
+// is valid.
+func (b *StatelessBlock) verify(ctx context.Context, bctx *block.Context) (err error) {
+	defer b.vm.BeginVerify()()
 	start := time.Now()
 	defer func() {
 		b.vm.RecordBlockVerify(time.Since(start))
@@ -270,7 +378,31 @@ func (b *StatelessBlock) Verify(ctx context.Context) error {
 	)
 	defer span.End()
 
-	log := b.vm.Logger()
+	log := b.vm.VerifyLogger()
+	if stateReady && bctx != nil && b.Processed() && b.contextChanged(ctx, bctx) {
+		// The artifacts of the previous verification are only reused with
+		// the same context
+		log.Info(
+			"discarding verification, context changed",
+			zap.Uint64("height", b.Hght),
+			zap.Stringer("blkID", b.ID()),
+			zap.Uint64("pChainHeight", bctx.PChainHeight),
+		)
+		b.discardContext()
+	}
+
+	// Ensure block was produced by a validator at the P-Chain height it is
+	// verified with (if required). This is checked even if we built [b]
+	// because the P-Chain height is only known once [b] is proposed.
+	if err := verifyBuilder(ctx, b.vm, b.vm.Rules(b.Tmstmp), b, bctx); err != nil {
+		log.Warn("verification failed",
+			zap.Uint64("height", b.Hght),
+			zap.Stringer("blkID", b.ID()),
+			zap.Error(err),
+		)
+		return err
+	}
+
 	switch {
 	case !stateReady:
 		// If the state of the accepted tip has not been fully fetched, it is not safe to
@@ -290,6 +422,16 @@ func (b *StatelessBlock) Verify(ctx context.Context) error {
 			zap.Stringer("blkID", b.ID()),
 		)
 	default:
+		// Profile the verification (if requested)
+		if profiler := b.vm.BlockProfiler(); profiler != nil {
+			if profile := profiler.Start(b); profile != nil {
+				ctx = withBlockProfile(ctx, profile)
+				defer func() {
+					profiler.Finish(profile, err)
+				}()
+			}
+		}
+
 		// Get the [VerifyContext] needed to process this block.
 		//
 		// If the parent block's height is less than or equal to the last accepted height (and
@@ -297,7 +439,7 @@ func (b *StatelessBlock) Verify(ctx context.Context) error {
 		// context. Otherwise, the parent block will be used as the execution context.
 		vctx, err := b.vm.GetVerifyContext(ctx, b.Hght, b.Prnt)
 		if err != nil {
-			b.vm.Logger().Warn("unable to get verify context",
+			b.vm.VerifyLogger().Warn("unable to get verify context",
 				zap.Uint64("height", b.Hght),
 				zap.Stringer("blkID", b.ID()),
 				zap.Error(err),
@@ -308,13 +450,18 @@ func (b *StatelessBlock) Verify(ctx context.Context) error {
 		// Parent block may not be processed when we verify this block, so [innerVerify] may
 		// recursively verify ancestry.
 		if err := b.innerVerify(ctx, vctx); err != nil {
-			b.vm.Logger().Warn("verification failed",
+			b.vm.VerifyLogger().Warn("verification failed",
 				zap.Uint64("height", b.Hght),
 				zap.Stringer("blkID", b.ID()),
 				zap.Error(err),
 			)
 			return err
 		}
+		if bctx != nil {
+			if err := b.cacheContext(ctx, vctx, bctx); err != nil {
+				return err
+			}
+		}
 	}
 
 	// At any point after this, we may attempt to verify the block. We should be
@@ -338,27 +485,68 @@ func (b *StatelessBlock) Verify(ctx context.Context) error {
 //  3. If the view of a block we are accepting is missing (finishing dynamic
 //     state sync)
 func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) error {
+	return b.verifyWithContext(ctx, vctx, nil)
+}
+
+// verifyWithContext executes the block on top of the provided [VerifyContext]
+// with [ectx] (or the [ExecutionContext] returned by [VM.GetExecutionContext]
+// if [ectx] is nil).
+//
+// A provided [ectx] is not regenerated, which allows blocks to be
+// deterministically re-verified (or fuzzed) with a fixed context. It must
+// still have been derived from the fee state and timestamp of the parent of
+// [b] for the timestamp of [b].
+func (b *StatelessBlock) verifyWithContext(ctx context.Context, vctx VerifyContext, ectx *ExecutionContext) error {
 	var (
-		log = b.vm.Logger()
+		log = b.vm.VerifyLogger()
 		r   = b.vm.Rules(b.Tmstmp)
 	)
 
+	// Only the phases of the profiled block are recorded (and not those of
+	// any ancestors it verifies)
+	profile := blockProfile(ctx)
+	if profile != nil && profile.ID() != b.ID() {
+		profile = nil
+		ctx = withBlockProfile(ctx, nil)
+	}
+
 	// Perform basic correctness checks before doing any expensive work
-	if b.Timestamp().UnixMilli() > time.Now().Add(FutureBound).UnixMilli() {
+	if b.Timestamp().UnixMilli() > b.vm.Now().Add(FutureBound).UnixMilli() {
 		return ErrTimestampTooLate
 	}
+	if err := verifyPaused(r, b.Tmstmp, b.Txs); err != nil {
+		return err
+	}
+	if err := verifySigners(r, b.Txs); err != nil {
+		return err
+	}
+	if err := b.verifyUnits(r); err != nil {
+		return err
+	}
 
 	// Fetch view where we will apply block state transitions
 	//
 	// This call may result in our ancestry being verified.
-	parentView, err := vctx.View(ctx, true)
+	//
+	// State may transiently be unavailable (i.e. during sync), so we retry
+	// fetches that fail with a [TransientError].
+	retries := r.GetStateFetchRetries()
+	parentView, err := retryStateFetch(ctx, retries, func() (state.View, error) {
+		return vctx.View(ctx, true)
+	})
 	if err != nil {
 		return fmt.Errorf("%w: unable to load parent view", err)
 	}
+	getParentValue := func(key []byte) ([]byte, error) {
+		return retryStateFetch(ctx, retries, func() ([]byte, error) {
+			return parentView.GetValue(ctx, key)
+		})
+	}
+	profile.mark(ProfilePhaseParentView)
 
 	// Fetch parent height key and ensure block height is valid
 	heightKey := HeightKey(b.vm.StateManager().HeightKey())
-	parentHeightRaw, err := parentView.GetValue(ctx, heightKey)
+	parentHeightRaw, err := getParentValue(heightKey)
 	if err != nil {
 		return err
 	}
@@ -372,7 +560,7 @@ func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) er
 	// Parent may not be available (if we preformed state sync), so we
 	// can't rely on being able to fetch it during verification.
 	timestampKey := TimestampKey(b.vm.StateManager().TimestampKey())
-	parentTimestampRaw, err := parentView.GetValue(ctx, timestampKey)
+	parentTimestampRaw, err := getParentValue(timestampKey)
 	if err != nil {
 		return err
 	}
@@ -380,10 +568,27 @@ func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) er
 	if b.Tmstmp < parentTimestamp+r.GetMinBlockGap() {
 		return ErrTimestampTooEarly
 	}
-	if len(b.Txs) == 0 && b.Tmstmp < parentTimestamp+r.GetMinEmptyBlockGap() {
+	if err := b.setExecution(ctx, parentTimestamp); err != nil {
+		return err
+	}
+	if len(b.Txs) == 0 && len(b.pendingTxs) == 0 && b.Tmstmp < parentTimestamp+r.GetMinEmptyBlockGap() {
 		return ErrTimestampTooEarly
 	}
 
+	// Ensure the validator set is recorded at the start of each epoch (if
+	// enabled)
+	b.epoch, err = loadEpochSnapshot(ctx, b.vm, r, b.vm.StateManager(), parentView, b.Hght, b.EpochPChainHeight)
+	if err != nil {
+		return err
+	}
+
+	// Ensure all txs are valid at the block timestamp (the replay check below
+	// only considers blocks in [ValidityWindow], so it is only sound for txs
+	// that have not expired)
+	if err := b.verifyTxTimestamps(r); err != nil {
+		return err
+	}
+
 	// Ensure tx cannot be replayed
 	//
 	// Before node is considered ready (emap is fully populated), this may return
@@ -406,17 +611,32 @@ func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) er
 		}
 	}
 
+	// Ensure included transactions can be executed by our child (if in delayed
+	// execution mode)
+	if b.delayed {
+		if err := b.verifyIncluded(r); err != nil {
+			return err
+		}
+	}
+
 	// Compute next unit prices to use
 	feeKey := FeeKey(b.vm.StateManager().FeeKey())
-	feeRaw, err := parentView.GetValue(ctx, feeKey)
+	feeRaw, err := getParentValue(feeKey)
 	if err != nil {
 		return err
 	}
 	parentFeeManager := fees.NewManager(feeRaw)
-	feeManager, err := parentFeeManager.ComputeNext(parentTimestamp, b.Tmstmp, r)
-	if err != nil {
+	if ectx == nil {
+		ectx, err = b.vm.GetExecutionContext(b.Prnt, feeRaw, parentTimestamp, b.Tmstmp)
+		if err != nil {
+			return err
+		}
+	}
+	if err := ectx.verifyParent(feeRaw, parentTimestamp, b.Tmstmp); err != nil {
 		return err
 	}
+	feeManager := ectx.FeeManager()
+	profile.mark(ProfilePhaseChecks)
 
 	// Process transactions
 	results, ts, err := b.Execute(ctx, b.vm.Tracer(), parentView, feeManager, r)
@@ -424,8 +644,17 @@ func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) er
 		log.Error("failed to execute block", zap.Error(err))
 		return err
 	}
+	if err := applyRefunds(ctx, b.vm, parentView, ts, feeManager, r, b.executedTxs, results); err != nil {
+		log.Error("failed to apply refunds", zap.Error(err))
+		return err
+	}
+	if err := ectx.VerifyBlockCost(results); err != nil {
+		return err
+	}
+	setBuilder(results, b.Builder)
 	b.results = results
 	b.feeManager = feeManager
+	profile.mark(ProfilePhaseExecute)
 
 	// Update chain metadata
 	heightKeyStr := string(heightKey)
@@ -458,12 +687,13 @@ func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) er
 	// to verify all fee calculations were correct.
 	_, rspan := b.vm.Tracer().Start(ctx, "StatelessBlock.Verify.WaitRoot")
 	start := time.Now()
-	computedRoot, err := parentView.GetMerkleRoot(ctx)
+	computedRoot, err := getMerkleRoot(ctx, parentView)
 	rspan.End()
 	if err != nil {
 		return err
 	}
 	b.vm.RecordWaitRoot(time.Since(start))
+	profile.mark(ProfilePhaseWaitRoot)
 	if b.StateRoot != computedRoot {
 		return fmt.Errorf(
 			"%w: expected=%s found=%s",
@@ -476,21 +706,53 @@ func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) er
 	// Ensure signatures are verified
 	_, sspan := b.vm.Tracer().Start(ctx, "StatelessBlock.Verify.WaitSignatures")
 	start = time.Now()
-	err = b.sigJob.Wait()
+	err = b.waitSignatures(ctx)
 	sspan.End()
 	if err != nil {
 		return err
 	}
 	b.vm.RecordWaitSignatures(time.Since(start))
+	profile.mark(ProfilePhaseWaitSignatures)
+
+	// Compare results root
+	//
+	// We wait until signatures are verified to compute the root to avoid
+	// contending with signature verification for [AuthVerifiers].
+	if err := b.verifyResultsRoot(ctx, r, results); err != nil {
+		return err
+	}
 
 	// Get view from [tstate] after processing all state transitions
+	//
+	// If shadow root verification is enabled, we copy the changes before
+	// exporting them (as they are consumed by the view).
 	b.vm.RecordStateChanges(ts.PendingChanges())
 	b.vm.RecordStateOperations(ts.OpIndex())
+	if err := b.setStateUsageDiff(ctx, parentView, ts); err != nil {
+		return err
+	}
+	var (
+		shadow        = b.vm.ShadowRootComputer()
+		shadowChanges map[string]maybe.Maybe[[]byte]
+	)
+	if shadow != nil {
+		shadowChanges = ts.ChangedKeys()
+	} else if b.vm.CatchUpIncrementalRoots() {
+		committed, err := b.commitIncremental(ctx, ts, parentView)
+		if err != nil {
+			return err
+		}
+		if committed {
+			profile.mark(ProfilePhaseExport)
+			return nil
+		}
+	}
 	view, err := ts.ExportMerkleDBView(ctx, b.vm.Tracer(), parentView)
 	if err != nil {
 		return err
 	}
 	b.view = view
+	profile.mark(ProfilePhaseExport)
 
 	// Kickoff root generation
 	go func() {
@@ -506,12 +768,188 @@ func (b *StatelessBlock) innerVerify(ctx context.Context, vctx VerifyContext) er
 			zap.Stringer("root", root),
 		)
 		b.vm.RecordRootCalculated(time.Since(start))
+
+		// Compare root with secondary implementation (if enabled)
+		if shadow != nil {
+			b.verifyShadowRoot(ctx, shadow, parentView, shadowChanges, root)
+		}
 	}()
 	return nil
 }
 
+// commitIncremental commits the changes of [b] directly to the accepted state
+// if [parentView] is the accepted state (see [VM.CatchUpIncrementalRoots])
+// and returns whether they were committed.
+//
+// Rather than creating a view of the changes on top of the accepted state
+// (and computing its root asynchronously), merkledb rehashes only the nodes
+// modified by [b] while committing. [b.view] is then an empty view of the
+// accepted state, so [Accept] commits nothing and [b] can't be rejected.
+func (b *StatelessBlock) commitIncremental(ctx context.Context, ts *tstate.TState, parentView state.View) (bool, error) {
+	acceptedState, err := b.vm.State()
+	if err != nil {
+		return false, err
+	}
+	if parentView != state.View(acceptedState) {
+		// The parent of [b] is still processing
+		return false, nil
+	}
+	view, err := ts.ExportMerkleDBView(ctx, b.vm.Tracer(), parentView)
+	if err != nil {
+		return false, err
+	}
+	start := time.Now()
+	if err := b.vm.CommitState(ctx, b, view); err != nil {
+		return false, err
+	}
+	b.vm.RecordRootCalculated(time.Since(start))
+	b.committed = true
+	b.view, err = acceptedState.NewView(ctx, merkledb.ViewChanges{})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// waitSignatures waits for all signatures in [b] to be verified or for [ctx]
+// to be done, whichever happens first.
+func (b *StatelessBlock) waitSignatures(ctx context.Context) error {
+	b.sigOnce.Do(func() {
+		b.sigDone = make(chan struct{})
+		go func() {
+			b.sigErr = b.sigJob.Wait()
+			close(b.sigDone)
+		}()
+	})
+	select {
+	case <-b.sigDone:
+		return b.sigErr
+	case <-ctx.Done():
+		return checkContext(ctx)
+	}
+}
+
+// verifyUnits ensures the units required by [b.Txs] fit in a single block.
+//
+// This only depends on [b.Txs] (not on state), so it is performed before
+// fetching the parent state or executing anything. Units are charged when
+// [b.Txs] are executed (by [b] or, in delayed execution mode, by our child).
+func (b *StatelessBlock) verifyUnits(r Rules) error {
+	var (
+		sm       = b.vm.StateManager()
+		maxUnits = r.GetMaxBlockUnits()
+		included = fees.Dimensions{}
+	)
+	for _, tx := range b.Txs {
+		units, err := tx.Units(sm, r, b.Tmstmp)
+		if err != nil {
+			return err
+		}
+		if !included.CanAdd(units, maxUnits) {
+			return fmt.Errorf("%w: included txs exceed max block units", ErrBlockUnitsExceeded)
+		}
+		included, err = fees.Add(included, units)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyIncluded performs all checks on [b.Txs] that don't require them to be
+// executed (other than [verifyUnits]).
+func (b *StatelessBlock) verifyIncluded(r Rules) error {
+	for _, tx := range b.Txs {
+		if err := tx.verifyInclusion(r, b.Tmstmp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyTxTimestamps ensures the timestamp (expiry) of each tx in [b.Txs] is
+// in [b.Tmstmp, b.Tmstmp+ValidityWindow].
+//
+// A tx that expired before [b.Tmstmp] could have been included by a block
+// older than [ValidityWindow] (which [IsRepeat] no longer considers) and a tx
+// that expires after [b.Tmstmp+ValidityWindow] could be included again by a
+// block that no longer considers [b].
+func (b *StatelessBlock) verifyTxTimestamps(r Rules) error {
+	oldest, newest := b.Tmstmp, b.Tmstmp+r.GetValidityWindow()
+	for _, tx := range b.Txs {
+		expiry := tx.Expiry()
+		switch {
+		case expiry < oldest:
+			return fmt.Errorf("%w: %w: tx=%s timestamp=%d oldest=%d", ErrTxTimestampOutOfWindow, ErrTimestampTooLate, tx.ID(), expiry, oldest)
+		case expiry > newest:
+			return fmt.Errorf("%w: %w: tx=%s timestamp=%d newest=%d", ErrTxTimestampOutOfWindow, ErrTimestampTooEarly, tx.ID(), expiry, newest)
+		}
+	}
+	return nil
+}
+
+// verifyResultsRoot ensures [b.ResultsRoot] commits to [results] (or is empty
+// if [Rules.GetIncludeResultsRoot] is disabled).
+func (b *StatelessBlock) verifyResultsRoot(ctx context.Context, r Rules, results []*Result) error {
+	_, span := b.vm.Tracer().Start(ctx, "StatelessBlock.Verify.ResultsRoot")
+	defer span.End()
+
+	computedRoot := ids.Empty
+	if r.GetIncludeResultsRoot() {
+		root, err := ResultsRoot(b.vm.AuthVerifiers(), results)
+		if err != nil {
+			return err
+		}
+		computedRoot = root
+	}
+	if b.ResultsRoot != computedRoot {
+		return fmt.Errorf(
+			"%w: expected=%s found=%s",
+			ErrResultsRootMismatch,
+			computedRoot,
+			b.ResultsRoot,
+		)
+	}
+	return nil
+}
+
+// verifyShadowRoot computes the root of [changes] applied to [parentView]
+// with [shadow] and logs if it does not match [root].
+//
+// The root computed by [shadow] never affects consensus.
+func (b *StatelessBlock) verifyShadowRoot(
+	ctx context.Context,
+	shadow RootComputer,
+	parentView state.View,
+	changes map[string]maybe.Maybe[[]byte],
+	root ids.ID,
+) {
+	log := b.vm.Logger()
+	start := time.Now()
+	shadowRoot, err := shadow.ComputeRoot(ctx, parentView, changes)
+	if err != nil {
+		log.Warn("shadow merkle root generation failed",
+			zap.Uint64("height", b.Hght),
+			zap.Stringer("blkID", b.ID()),
+			zap.Error(err),
+		)
+		return
+	}
+	b.vm.RecordShadowRootCalculated(time.Since(start))
+	if shadowRoot != root {
+		b.vm.RecordShadowRootMismatch()
+		log.Error("shadow merkle root mismatch",
+			zap.Uint64("height", b.Hght),
+			zap.Stringer("blkID", b.ID()),
+			zap.Stringer("root", root),
+			zap.Stringer("shadowRoot", shadowRoot),
+		)
+	}
+}
+
 // implements "snowman.Block.choices.Decidable"
 func (b *StatelessBlock) Accept(ctx context.Context) error {
+	defer b.vm.BeginAccept()()
 	start := time.Now()
 	defer func() {
 		b.vm.RecordBlockAccept(time.Since(start))
@@ -532,7 +970,7 @@ func (b *StatelessBlock) Accept(ctx context.Context) error {
 		}
 		if updated {
 			b.vm.Logger().Info("updated state sync target",
-				zap.Stringer("id", b.ID()),
+				zap.Stringer("blkID", b.ID()),
 				zap.Stringer("root", b.StateRoot),
 			)
 			return nil // the sync is still ongoing
@@ -543,9 +981,12 @@ func (b *StatelessBlock) Accept(ctx context.Context) error {
 		// processing block).
 		//
 		// If state sync completes before accept is called
-		// then we need to process it here.
+		// then we need to process it here. If the sync reached
+		// its target while this block was being accepted,
+		// [UpdateSyncTarget] waits until the target is accepted
+		// (so the state is the state of the parent).
 		b.vm.Logger().Info("verifying unprocessed block in accept",
-			zap.Stringer("id", b.ID()),
+			zap.Stringer("blkID", b.ID()),
 			zap.Stringer("root", b.StateRoot),
 		)
 		vctx, err := b.vm.GetVerifyContext(ctx, b.Hght, b.Prnt)
@@ -559,7 +1000,12 @@ func (b *StatelessBlock) Accept(ctx context.Context) error {
 
 	// Commit view if we don't return before here (would happen if we are still
 	// syncing)
-	if err := b.view.CommitToDB(ctx); err != nil {
+	//
+	// [b.view] only contains the exact set of key changes recorded by [tstate]
+	// during execution, so committing it does not traverse any unmodified
+	// portion of the trie. Any nodes hashed during the async root generation
+	// kicked off in [innerVerify] are reused here.
+	if err := b.vm.CommitState(ctx, b, b.view); err != nil {
 		return fmt.Errorf("%w: unable to commit block", err)
 	}
 
@@ -572,6 +1018,8 @@ func (b *StatelessBlock) MarkAccepted(ctx context.Context) {
 	// Accept block and free unnecessary memory
 	b.st = choices.Accepted
 	b.txsSet = nil // only used for replay protection when processing
+	b.verifiedContext = nil
+	b.freeAncestryFilter()
 
 	// [Accepted] will persist the block to disk and set in-memory variables
 	// needed to ensure we don't resync all blocks when state sync finishes.
@@ -585,7 +1033,18 @@ func (b *StatelessBlock) Reject(ctx context.Context) error {
 	ctx, span := b.vm.Tracer().Start(ctx, "StatelessBlock.Reject")
 	defer span.End()
 
+	if b.committed {
+		return fmt.Errorf("%w: blkID=%s", ErrRejectedCommittedBlock, b.ID())
+	}
 	b.st = choices.Rejected
+	b.verifiedContext = nil
+
+	// There is no need to finish verifying the signatures of a rejected block
+	// (which may still be in progress if a competing block was accepted first)
+	if b.sigCancel != nil {
+		b.sigCancel()
+	}
+	b.freeAncestryFilter()
 	b.vm.Rejected(ctx, b)
 	return nil
 }
@@ -599,6 +1058,24 @@ func (b *StatelessBlock) Parent() ids.ID { return b.StatefulBlock.Prnt }
 // implements "snowman.Block"
 func (b *StatelessBlock) Bytes() []byte { return b.bytes }
 
+// Size returns the length of [Bytes]. If [b] has not been marshaled yet (like
+// while it is being built), it is marshaled to compute its size.
+//
+// Blocks larger than [consts.NetworkSizeLimit] can't be marshaled, so an
+// error is returned for them instead of a size.
+func (b *StatelessBlock) Size() (int, error) {
+	if len(b.bytes) > 0 {
+		return len(b.bytes), nil
+	}
+	p := codec.GetWriter(b.StatefulBlock.marshalSize(), consts.NetworkSizeLimit)
+	defer codec.PutWriter(p)
+
+	if err := b.StatefulBlock.marshal(p, b.vm); err != nil {
+		return 0, err
+	}
+	return len(p.Bytes()), nil
+}
+
 // implements "snowman.Block"
 func (b *StatelessBlock) Height() uint64 { return b.StatefulBlock.Hght }
 
@@ -726,14 +1203,60 @@ func (b *StatelessBlock) View(ctx context.Context, verify bool) (state.View, err
 	//
 	// It is not possible to reach this function if this block
 	// is not the child of the block whose post-execution state
-	// is currently stored on disk, so it is safe to call [CommitToDB].
-	if err := b.view.CommitToDB(ctx); err != nil {
+	// is currently stored on disk, so it is safe to call [CommitState].
+	if err := b.vm.CommitState(ctx, b, b.view); err != nil {
 		b.vm.Logger().Error("unable to commit to DB", zap.Error(err))
 		return nil, err
 	}
 	return b.vm.State()
 }
 
+// checkRepeatDepth returns [ErrReplayCheckTooDeep] if the blocks after
+// [oldestAllowed] (the ones [IsRepeat] may need to check) span more than
+// [Rules.GetMaxRepeatCheckDepth] heights.
+//
+// Because timestamps never decrease with height, this is the case if and only
+// if the ancestor of [b] [GetMaxRepeatCheckDepth] heights below it is not
+// older than [oldestAllowed]. This only depends on the chain [b] extends (and
+// not on which of its ancestors are accepted by this node), so all nodes
+// agree on the result.
+func (b *StatelessBlock) checkRepeatDepth(ctx context.Context, oldestAllowed int64) error {
+	maxDepth := b.vm.Rules(b.Tmstmp).GetMaxRepeatCheckDepth()
+	if maxDepth <= 0 || b.Hght < uint64(maxDepth) {
+		return nil
+	}
+	height := b.Hght - uint64(maxDepth)
+
+	// Walk back to the ancestor at [height] (or to the last accepted block)
+	blk := b
+	for blk.Hght > height && blk.st != choices.Accepted {
+		if blk.Tmstmp < oldestAllowed {
+			return nil
+		}
+		if err := checkContext(ctx); err != nil {
+			return err
+		}
+		prnt, err := b.vm.GetStatelessBlock(ctx, blk.Prnt)
+		if err != nil {
+			return err
+		}
+		blk = prnt
+	}
+	timestamp := blk.Tmstmp
+	if blk.Hght != height {
+		// The ancestor at [height] is accepted
+		v, err := b.vm.GetBlockHeightTimestamp(height)
+		if err != nil {
+			return fmt.Errorf("%w: unable to load timestamp at height %d", err, height)
+		}
+		timestamp = v
+	}
+	if timestamp >= oldestAllowed {
+		return fmt.Errorf("%w: max=%d", ErrReplayCheckTooDeep, maxDepth)
+	}
+	return nil
+}
+
 // IsRepeat returns a bitset of all transactions that are considered repeats in
 // the range that spans back to [oldestAllowed].
 //
@@ -763,23 +1286,56 @@ func (b *StatelessBlock) IsRepeat(
 		return b.vm.IsRepeat(ctx, txs, marker, stop), nil
 	}
 
-	// Check if block contains any overlapping txs
+	if err := b.checkRepeatDepth(ctx, oldestAllowed); err != nil {
+		return marker, err
+	}
+
+	// Only transactions that may be included in a processing ancestor need to
+	// be compared against each processing ancestor
+	filter, err := b.getAncestryFilter(ctx)
+	if err != nil {
+		return marker, err
+	}
+	candidates := make([]int, 0, len(txs))
 	for i, tx := range txs {
 		if marker.Contains(i) {
 			continue
 		}
-		if b.txsSet.Contains(tx.ID()) {
-			marker.Add(i)
-			if stop {
-				return marker, nil
-			}
+		if filter == nil || filter.contains(tx.ID()) {
+			candidates = append(candidates, i)
 		}
 	}
-	prnt, err := b.vm.GetStatelessBlock(ctx, b.Prnt)
-	if err != nil {
-		return marker, err
+
+	// Walk back to the last accepted block (or until we are back at least
+	// [ValidityWindow])
+	blk := b
+	for {
+		// Check if block contains any overlapping txs
+		for _, i := range candidates {
+			if marker.Contains(i) {
+				continue
+			}
+			if blk.txsSet.Contains(txs[i].ID()) {
+				marker.Add(i)
+				if stop {
+					return marker, nil
+				}
+			}
+		}
+		if err := checkContext(ctx); err != nil {
+			return marker, err
+		}
+		blk, err = b.vm.GetStatelessBlock(ctx, blk.Prnt)
+		if err != nil {
+			return marker, err
+		}
+		if blk.Tmstmp < oldestAllowed {
+			return marker, nil
+		}
+		if blk.st == choices.Accepted || blk.Hght == 0 /* genesis */ {
+			return b.vm.IsRepeat(ctx, txs, marker, stop), nil
+		}
 	}
-	return prnt.IsRepeat(ctx, oldestAllowed, txs, marker, stop)
 }
 
 func (b *StatelessBlock) GetTxs() []*Transaction {
@@ -790,76 +1346,455 @@ func (b *StatelessBlock) GetTimestamp() int64 {
 	return b.Tmstmp
 }
 
+// Results returns the results of [ExecutedTxs] (which may differ from
+// [GetTxs] if [Rules.GetDelayedExecution] is enabled).
 func (b *StatelessBlock) Results() []*Result {
 	return b.results
 }
 
+// ExecutedTxs returns the transactions executed by this block. This is only
+// populated once the block has been built or verified.
+func (b *StatelessBlock) ExecutedTxs() []*Transaction {
+	return b.executedTxs
+}
+
+// PendingTxs returns the transactions included by the parent that are executed
+// by this block (the prefix of [ExecutedTxs]). Once the block has been built
+// or verified, this excludes any transactions dropped during execution (see
+// [ExecutionOrder]).
+func (b *StatelessBlock) PendingTxs() []*Transaction {
+	if b.delayed {
+		return b.executedTxs
+	}
+	return b.executedTxs[:len(b.executedTxs)-len(b.Txs)]
+}
+
+// StateUsageDiff returns the change in [StateUsage] of each prefix registered
+// with [VM.StatePrefixes] (indexed like [StatePrefixRegistry.Index]) caused by
+// executing this block.
+//
+// This is nil if state usage is not tracked or if the block was not executed
+// by this node (i.e. it was accepted during state sync or its changes were
+// already committed before a restart).
+func (b *StatelessBlock) StateUsageDiff() []StateUsage {
+	return b.usageDiff
+}
+
+// setStateUsageDiff computes [usageDiff] from the changes in [ts] (which must
+// not have been exported yet).
+func (b *StatelessBlock) setStateUsageDiff(ctx context.Context, parent state.Immutable, ts *tstate.TState) error {
+	registry := b.vm.StatePrefixes()
+	if registry == nil {
+		return nil
+	}
+	diff, err := registry.Diff(ctx, parent, ts.ChangedKeys())
+	if err != nil {
+		return fmt.Errorf("%w: unable to compute state usage", err)
+	}
+	b.usageDiff = diff
+	return nil
+}
+
+// DelayedExecution returns true if the transactions included in this block
+// will be executed by its child.
+func (b *StatelessBlock) DelayedExecution() bool {
+	return b.delayed
+}
+
+// setExecution populates the transactions to execute in this block. If the
+// parent was built in delayed execution mode, its transactions are executed
+// first (at [parentTimestamp]).
+func (b *StatelessBlock) setExecution(ctx context.Context, parentTimestamp int64) error {
+	b.delayed = b.vm.Rules(b.Tmstmp).GetDelayedExecution()
+	b.pendingTxs = nil
+	b.pendingTimestamp = parentTimestamp
+	if b.vm.Rules(parentTimestamp).GetDelayedExecution() {
+		parent, err := b.vm.GetStatelessBlock(ctx, b.Prnt)
+		if err != nil {
+			return fmt.Errorf("%w: unable to load parent txs", err)
+		}
+		b.pendingTxs = parent.Txs
+	}
+	b.executedTxs = executedTxs(b.pendingTxs, b.Txs, b.delayed)
+	return nil
+}
+
+// executedTxs returns the transactions executed by a block that includes
+// [txs] when its parent included [pendingTxs] (which must be empty if the
+// parent was not in delayed execution mode).
+func executedTxs(pendingTxs []*Transaction, txs []*Transaction, delayed bool) []*Transaction {
+	if delayed {
+		return pendingTxs
+	}
+	if len(pendingTxs) == 0 {
+		return txs
+	}
+	return append(slices.Clone(pendingTxs), txs...)
+}
+
 func (b *StatelessBlock) FeeManager() *fees.Manager {
 	return b.feeManager
 }
 
-func (b *StatefulBlock) Marshal() ([]byte, error) {
-	size := ids.IDLen + consts.Uint64Len + consts.Uint64Len +
+// minBlockHeaderSize is the size of the header of a block without a builder
+// section (before [RestrictBuildersFork]), results root, or epoch.
+const minBlockHeaderSize = ids.IDLen + consts.Int64Len + consts.Uint64Len +
+	ids.IDLen + consts.IntLen
+
+// Marshal packs [b] with the encoding of the [Fork]s active (under the
+// [Rules] of [parser]) at [b.Tmstmp].
+//
+// The bytes of a block are retained (as [StatelessBlock.Bytes]), so they are
+// packed into a new buffer. Callers that only need the bytes briefly (like
+// [StatefulBlock.ID]) pack into a pooled writer with [marshal] instead.
+func (b *StatefulBlock) Marshal(parser Parser) ([]byte, error) {
+	p := codec.NewWriter(b.marshalSize(), consts.NetworkSizeLimit)
+	if err := b.marshal(p, parser); err != nil {
+		return nil, err
+	}
+	return p.Bytes(), nil
+}
+
+// marshalSize is the initial capacity of the writer [b] is marshaled with.
+func (b *StatefulBlock) marshalSize() int {
+	return ids.IDLen + consts.Uint64Len + consts.Uint64Len +
+		consts.BoolLen + codec.AddressLen + bls.SignatureLen +
 		consts.Uint64Len + window.WindowSliceSize +
 		consts.IntLen + codec.CummSize(b.Txs) +
-		ids.IDLen + consts.Uint64Len + consts.Uint64Len
-
-	p := codec.NewWriter(size, consts.NetworkSizeLimit)
+		ids.IDLen + ids.IDLen + consts.Uint64Len +
+		consts.Uint64Len + consts.Uint64Len
+}
 
+// marshal packs [b] into [p] (see [Marshal]).
+func (b *StatefulBlock) marshal(p *codec.Packer, parser Parser) error {
 	p.PackID(b.Prnt)
 	p.PackInt64(b.Tmstmp)
 	p.PackUint64(b.Hght)
 
+	r := parser.Rules(b.Tmstmp)
+	hasBuilder := b.Builder != codec.EmptyAddress
+	switch {
+	case IsActive(r, RestrictBuildersFork, b.Tmstmp):
+		p.PackBool(hasBuilder)
+		if hasBuilder {
+			p.PackAddress(b.Builder)
+		}
+	case hasBuilder:
+		return ErrBuilderNotActive
+	}
+
+	// Once [HeaderRootsFork] is active, roots are packed before transactions
+	// so that the header can be parsed without parsing any transactions (see
+	// [UnmarshalBlockHeader]).
+	headerRoots := IsActive(r, HeaderRootsFork, b.Tmstmp)
+	if headerRoots {
+		if err := b.packRoots(p, r); err != nil {
+			return err
+		}
+	}
+	if err := b.packTxs(p); err != nil {
+		return err
+	}
+	if !headerRoots {
+		if err := b.packRoots(p, r); err != nil {
+			return err
+		}
+	}
+
+	// The builder signature is packed last so that it covers all other
+	// bytes of the block (see [builderDigest])
+	if hasBuilder {
+		if len(b.BuilderSignature) != bls.SignatureLen {
+			return ErrInvalidBuilderSignature
+		}
+		p.PackFixedBytes(b.BuilderSignature)
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	b.size = len(p.Bytes())
+	return nil
+}
+
+// packRoots packs the commitments of [b] to the result of its execution.
+//
+// [b.ResultsRoot] is only packed if [Rules.GetIncludeResultsRoot] is enabled
+// and [b.EpochPChainHeight] is only packed if [b] starts an epoch (see
+// [IsEpochStart]), so neither is packed when the feature is disabled.
+func (b *StatefulBlock) packRoots(p *codec.Packer, r Rules) error {
+	p.PackID(b.StateRoot)
+
+	switch {
+	case r.GetIncludeResultsRoot():
+		p.PackID(b.ResultsRoot)
+	case b.ResultsRoot != ids.Empty:
+		return fmt.Errorf("%w: results root is disabled", ErrResultsRootMismatch)
+	}
+
+	switch {
+	case IsEpochStart(r, b.Hght):
+		p.PackUint64(b.EpochPChainHeight)
+	case b.EpochPChainHeight != 0:
+		return fmt.Errorf("%w: unexpected P-Chain height %d", ErrInvalidEpoch, b.EpochPChainHeight)
+	}
+	return nil
+}
+
+// packTxs packs the count of [b.Txs] followed by each transaction.
+func (b *StatefulBlock) packTxs(p *codec.Packer) error {
 	p.PackInt(len(b.Txs))
 	b.authCounts = map[uint8]int{}
 	for _, tx := range b.Txs {
 		if err := tx.Marshal(p); err != nil {
-			return nil, err
+			return err
 		}
 		b.authCounts[tx.Auth.GetTypeID()]++
 	}
+	return nil
+}
 
-	p.PackID(b.StateRoot)
-	bytes := p.Bytes()
+// MarshalTxs packs only the transactions of [b], using the same format as
+// [Marshal]. This is useful when only the transaction set of a block is
+// needed (like for mempool snapshots or diffs of transaction sets).
+//
+// The result can be parsed with [UnmarshalBlockTxs].
+func (b *StatefulBlock) MarshalTxs() ([]byte, error) {
+	p := codec.NewWriter(consts.IntLen+codec.CummSize(b.Txs), consts.NetworkSizeLimit)
+	if err := b.packTxs(p); err != nil {
+		return nil, err
+	}
+	return p.Bytes(), p.Err()
+}
+
+// BlockHeader contains the fields of a [StatefulBlock] other than its
+// transactions.
+type BlockHeader struct {
+	Prnt        ids.ID        `json:"parent"`
+	Tmstmp      int64         `json:"timestamp"`
+	Hght        uint64        `json:"height"`
+	Builder     codec.Address `json:"builder"`
+	StateRoot   ids.ID        `json:"stateRoot"`
+	ResultsRoot ids.ID        `json:"resultsRoot"`
+
+	EpochPChainHeight uint64 `json:"epochPChainHeight"`
+
+	TxCount int `json:"txCount"`
+}
+
+// unmarshalBlockHeader parses the header of a block from [p] and returns
+// whether the header includes the roots of the block (see [HeaderRootsFork]).
+// If it does not, the roots must be parsed with [unpackRoots] after the
+// transactions of the block.
+func unmarshalBlockHeader(p *codec.Packer, h *BlockHeader, parser Parser) bool {
+	p.UnpackID(false, &h.Prnt)
+	h.Tmstmp = p.UnpackInt64(false)
+	h.Hght = p.UnpackUint64(false)
+	if p.Err() != nil {
+		return false
+	}
+	r := parser.Rules(h.Tmstmp)
+	if IsActive(r, RestrictBuildersFork, h.Tmstmp) && p.UnpackBool() {
+		p.UnpackAddress(&h.Builder)
+	}
+	headerRoots := IsActive(r, HeaderRootsFork, h.Tmstmp)
+	if headerRoots {
+		unpackRoots(p, h, r)
+	}
+	h.TxCount = p.UnpackInt(false) // can produce empty blocks
+	return headerRoots
+}
+
+// unpackRoots parses the roots packed by [StatefulBlock.packRoots].
+func unpackRoots(p *codec.Packer, h *BlockHeader, r Rules) {
+	p.UnpackID(false, &h.StateRoot)
+	if r.GetIncludeResultsRoot() {
+		p.UnpackID(false, &h.ResultsRoot)
+	}
+	if IsEpochStart(r, h.Hght) {
+		h.EpochPChainHeight = p.UnpackUint64(true)
+	}
+}
+
+// UnmarshalBlockHeader parses the header of the block in [raw]. This is
+// useful when only the block metadata is needed (like in an indexer).
+//
+// Once [HeaderRootsFork] is active, no transactions are parsed and the
+// validity of [raw] past the header is not checked. Before, the roots of the
+// block are packed after its transactions, so the entire block is parsed.
+func UnmarshalBlockHeader(raw []byte, parser Parser) (*BlockHeader, error) {
+	var (
+		p = codec.GetReader(raw, consts.NetworkSizeLimit)
+		h BlockHeader
+	)
+	defer codec.PutReader(p)
+
+	headerRoots := unmarshalBlockHeader(p, &h, parser)
 	if err := p.Err(); err != nil {
 		return nil, err
 	}
-	b.size = len(bytes)
-	return bytes, nil
+	if !headerRoots {
+		blk, err := UnmarshalBlock(raw, parser)
+		if err != nil {
+			return nil, err
+		}
+		h.StateRoot = blk.StateRoot
+		h.ResultsRoot = blk.ResultsRoot
+		h.EpochPChainHeight = blk.EpochPChainHeight
+	}
+	return &h, nil
+}
+
+// UnmarshalBlockTimestamp returns the timestamp of the block in [raw]. Unlike
+// the rest of the header, the timestamp is packed at the same offset by every
+// encoding (regardless of which [Fork]s are active), so it can be read
+// without any [Rules].
+func UnmarshalBlockTimestamp(raw []byte) (int64, error) {
+	p := codec.GetReader(raw, consts.NetworkSizeLimit)
+	defer codec.PutReader(p)
+
+	var prnt ids.ID
+	p.UnpackID(false, &prnt)
+	timestamp := p.UnpackInt64(false)
+	return timestamp, p.Err()
 }
 
 func UnmarshalBlock(raw []byte, parser Parser) (*StatefulBlock, error) {
+	if len(raw) < minBlockHeaderSize {
+		return nil, fmt.Errorf("%w: size=%d min=%d", ErrBlockTooSmall, len(raw), minBlockHeaderSize)
+	}
+
 	var (
-		p = codec.NewReader(raw, consts.NetworkSizeLimit)
-		b StatefulBlock
+		p = codec.GetReader(raw, consts.NetworkSizeLimit)
+		h BlockHeader
 	)
-	b.size = len(raw)
+	defer codec.PutReader(p)
 
-	p.UnpackID(false, &b.Prnt)
-	b.Tmstmp = p.UnpackInt64(false)
-	b.Hght = p.UnpackUint64(false)
+	headerRoots := unmarshalBlockHeader(p, &h, parser)
 
 	// Parse transactions
-	txCount := p.UnpackInt(false) // can produce empty blocks
+	txs, authCounts, err := unpackTxs(p, h.TxCount, parser)
+	if err != nil {
+		return nil, err
+	}
+	if !headerRoots {
+		unpackRoots(p, &h, parser.Rules(h.Tmstmp))
+	}
+	b := StatefulBlock{
+		Prnt:        h.Prnt,
+		Tmstmp:      h.Tmstmp,
+		Hght:        h.Hght,
+		Builder:     h.Builder,
+		Txs:         txs,
+		StateRoot:   h.StateRoot,
+		ResultsRoot: h.ResultsRoot,
+		size:        len(raw),
+
+		EpochPChainHeight: h.EpochPChainHeight,
+		authCounts:        authCounts,
+	}
+	if b.Builder != codec.EmptyAddress {
+		b.BuilderSignature = make([]byte, bls.SignatureLen)
+		p.UnpackFixedBytes(bls.SignatureLen, &b.BuilderSignature)
+	}
+
+	// Ensure no leftover bytes
+	if !p.Empty() {
+		return nil, fmt.Errorf("%w: remaining=%d", ErrInvalidObject, len(raw)-p.Offset())
+	}
+	return &b, p.Err()
+}
+
+// UnmarshalBlockTxs parses transactions packed with
+// [StatefulBlock.MarshalTxs].
+func UnmarshalBlockTxs(raw []byte, parser Parser) ([]*Transaction, error) {
+	p := codec.GetReader(raw, consts.NetworkSizeLimit)
+	defer codec.PutReader(p)
+
+	count := p.UnpackInt(false) // can be empty
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	txs, _, err := unpackTxs(p, count, parser)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure no leftover bytes
+	if !p.Empty() {
+		return nil, fmt.Errorf("%w: remaining=%d", ErrInvalidObject, len(raw)-p.Offset())
+	}
+	return txs, p.Err()
+}
+
+// unpackTxs parses [count] transactions from [p].
+//
+// Because every transaction packs at least a [Base], [count] is rejected
+// if the unread bytes of [p] could not possibly contain that many
+// transactions.
+func unpackTxs(p *codec.Packer, count int, parser Parser) ([]*Transaction, map[uint8]int, error) {
+	if remaining := len(p.Bytes()) - p.Offset(); count > remaining/BaseSize {
+		return nil, nil, fmt.Errorf("%w: count=%d remaining=%d", ErrTooManyTxs, count, remaining)
+	}
 	actionRegistry, authRegistry := parser.Registry()
-	b.Txs = []*Transaction{} // don't preallocate all to avoid DoS
-	b.authCounts = map[uint8]int{}
-	for i := 0; i < txCount; i++ {
+	txs := make([]*Transaction, 0, count) // [count] is bounded by the size of [p]
+	authCounts := map[uint8]int{}
+	for i := 0; i < count; i++ {
 		tx, err := UnmarshalTx(p, actionRegistry, authRegistry)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		b.Txs = append(b.Txs, tx)
-		b.authCounts[tx.Auth.GetTypeID()]++
+		txs = append(txs, tx)
+		authCounts[tx.Auth.GetTypeID()]++
 	}
+	return txs, authCounts, nil
+}
 
-	p.UnpackID(false, &b.StateRoot)
+type builderVerifier interface {
+	VerifyBuilder(context.Context, codec.Address, uint64, ids.ID, []byte) (bool, error)
+}
 
-	// Ensure no leftover bytes
-	if !p.Empty() {
-		return nil, fmt.Errorf("%w: remaining=%d", ErrInvalidObject, len(raw)-p.Offset())
+// restrictBuilders returns true if blocks at [timestamp] must be built by a
+// validator.
+func restrictBuilders(r Rules, timestamp int64) bool {
+	return r.GetRestrictBuilders() && IsActive(r, RestrictBuildersFork, timestamp)
+}
+
+// builderDigest returns the digest of the block [raw] signed by its builder
+// (all of its bytes except the trailing [StatefulBlock.BuilderSignature]).
+func builderDigest(raw []byte) ids.ID {
+	return utils.ToID(raw[:len(raw)-bls.SignatureLen])
+}
+
+// verifyBuilder ensures the builder of [b] signed it and was a validator at
+// the P-Chain height of [bctx] when builders are restricted.
+//
+// Because the validator set is read at the P-Chain height [b] is proposed
+// with (instead of the current validator set), all nodes reach the same
+// result.
+func verifyBuilder(
+	ctx context.Context,
+	vm builderVerifier,
+	r Rules,
+	b *StatelessBlock,
+	bctx *block.Context,
+) error {
+	if !restrictBuilders(r, b.Tmstmp) {
+		return nil
 	}
-	return &b, p.Err()
+	if b.Builder == codec.EmptyAddress {
+		return fmt.Errorf("%w: missing builder", ErrUnauthorizedBuilder)
+	}
+	if bctx == nil {
+		return fmt.Errorf("%w: missing P-Chain height", ErrUnauthorizedBuilder)
+	}
+	ok, err := vm.VerifyBuilder(ctx, b.Builder, bctx.PChainHeight, builderDigest(b.bytes), b.BuilderSignature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: pChainHeight=%d", ErrUnauthorizedBuilder, bctx.PChainHeight)
+	}
+	return nil
 }
 
 type SyncableBlock struct {
@@ -874,6 +1809,25 @@ func NewSyncableBlock(sb *StatelessBlock) *SyncableBlock {
 	return &SyncableBlock{sb}
 }
 
+// ParseSyncableBlock parses [source] as an accepted block and wraps it in a
+// [SyncableBlock].
+//
+// Summaries are trusted (they are only accepted if a majority of stake
+// considers them valid), so transaction signatures are not verified.
+func ParseSyncableBlock(ctx context.Context, source []byte, vm VM) (*SyncableBlock, error) {
+	_, span := vm.Tracer().Start(ctx, "chain.ParseSyncableBlock")
+	defer span.End()
+
+	blk, err := UnmarshalBlock(source, vm)
+	if err != nil {
+		return nil, err
+	}
+	if blk.StateRoot == ids.Empty {
+		return nil, ErrStateRootEmpty
+	}
+	return NewSyncableBlock(newStatelessBlock(blk, source, choices.Accepted, vm)), nil
+}
+
 func (sb *SyncableBlock) String() string {
 	return fmt.Sprintf("%d:%s root=%s", sb.Height(), sb.ID(), sb.StateRoot)
 }
@@ -0,0 +1,191 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// Phases of a block verification recorded by a [BlockProfile] (in the order
+// they occur).
+const (
+	// ProfilePhaseParentView includes the basic checks of the block and
+	// fetching the parent view (which may verify the ancestry of the block).
+	ProfilePhaseParentView = "parentView"
+	// ProfilePhaseChecks includes the height, timestamp, builder, epoch, and
+	// replay checks performed before execution.
+	ProfilePhaseChecks = "checks"
+	// ProfilePhaseExecute includes executing transactions and applying
+	// refunds.
+	ProfilePhaseExecute = "execute"
+	// ProfilePhaseWaitRoot includes updating chain metadata and waiting for
+	// the parent root.
+	ProfilePhaseWaitRoot       = "waitRoot"
+	ProfilePhaseWaitSignatures = "waitSignatures"
+	// ProfilePhaseExport includes verifying the results root and exporting
+	// the state changes of the block.
+	ProfilePhaseExport = "export"
+)
+
+// MaxProfiledActions is the number of action types included in a
+// [BlockProfileSummary].
+const MaxProfiledActions = 10
+
+// BlockProfiler profiles the verification of selected blocks (see
+// [VM.BlockProfiler]).
+type BlockProfiler interface {
+	// Start returns the [BlockProfile] to record the verification of [b] in
+	// (or nil if [b] should not be profiled).
+	Start(b *StatelessBlock) *BlockProfile
+	// Finish is called with the result of the verification of each block
+	// [Start] returned a [BlockProfile] for.
+	Finish(p *BlockProfile, err error)
+}
+
+// BlockProfile records the duration of each phase of a block verification and
+// the cumulative execution time of each action type.
+//
+// All methods are no-ops on a nil [BlockProfile], so verification doesn't
+// need to check whether it is being profiled.
+type BlockProfile struct {
+	blkID  ids.ID
+	height uint64
+	txs    int
+
+	start time.Time
+	last  time.Time
+
+	phases []*PhaseProfile // only modified by the verifying goroutine
+
+	l       sync.Mutex
+	actions map[uint8]*ActionProfile
+}
+
+// PhaseProfile is the duration of a phase of a block verification.
+type PhaseProfile struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ActionProfile is the cumulative execution time of the actions of a type in
+// a block.
+type ActionProfile struct {
+	TypeID   uint8         `json:"typeID"`
+	Count    int           `json:"count"`
+	Duration time.Duration `json:"duration"`
+}
+
+// BlockProfileSummary summarizes a [BlockProfile].
+type BlockProfileSummary struct {
+	BlockID  ids.ID        `json:"blockID"`
+	Height   uint64        `json:"height"`
+	Txs      int           `json:"txs"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+
+	Phases []*PhaseProfile `json:"phases"`
+	// HottestActions includes the (at most [MaxProfiledActions]) action types
+	// with the largest cumulative execution time (in descending order).
+	HottestActions []*ActionProfile `json:"hottestActions"`
+}
+
+// NewBlockProfile returns a [BlockProfile] for the verification of [b] that
+// starts now.
+func NewBlockProfile(b *StatelessBlock) *BlockProfile {
+	now := time.Now()
+	return &BlockProfile{
+		blkID:   b.ID(),
+		height:  b.Hght,
+		txs:     len(b.Txs),
+		start:   now,
+		last:    now,
+		actions: map[uint8]*ActionProfile{},
+	}
+}
+
+func (p *BlockProfile) ID() ids.ID {
+	return p.blkID
+}
+
+// mark records the time since the previous phase ended (or the profile
+// started) as the duration of [phase].
+func (p *BlockProfile) mark(phase string) {
+	if p == nil {
+		return
+	}
+	now := time.Now()
+	p.phases = append(p.phases, &PhaseProfile{Name: phase, Duration: now.Sub(p.last)})
+	p.last = now
+}
+
+// recordAction adds [d] to the cumulative execution time of [typeID]. It may
+// be called concurrently.
+func (p *BlockProfile) recordAction(typeID uint8, d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	a, ok := p.actions[typeID]
+	if !ok {
+		a = &ActionProfile{TypeID: typeID}
+		p.actions[typeID] = a
+	}
+	a.Count++
+	a.Duration += d
+}
+
+// Summary returns a [BlockProfileSummary] of a verification that finished
+// now with [err].
+func (p *BlockProfile) Summary(err error) *BlockProfileSummary {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	actions := make([]*ActionProfile, 0, len(p.actions))
+	for _, a := range p.actions {
+		actions = append(actions, a)
+	}
+	slices.SortFunc(actions, func(a, b *ActionProfile) int {
+		if c := cmp.Compare(b.Duration, a.Duration); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.TypeID, b.TypeID)
+	})
+	if len(actions) > MaxProfiledActions {
+		actions = actions[:MaxProfiledActions]
+	}
+	s := &BlockProfileSummary{
+		BlockID:        p.blkID,
+		Height:         p.height,
+		Txs:            p.txs,
+		Duration:       time.Since(p.start),
+		Phases:         p.phases,
+		HottestActions: actions,
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	return s
+}
+
+type blockProfileKey struct{}
+
+// withBlockProfile returns a copy of [ctx] carrying [p] (which hides any
+// [BlockProfile] [ctx] already carries if [p] is nil).
+func withBlockProfile(ctx context.Context, p *BlockProfile) context.Context {
+	return context.WithValue(ctx, blockProfileKey{}, p)
+}
+
+// blockProfile returns the [BlockProfile] [ctx] carries (or nil).
+func blockProfile(ctx context.Context) *BlockProfile {
+	p, _ := ctx.Value(blockProfileKey{}).(*BlockProfile)
+	return p
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+// profileTestProfiler profiles every block and records the summaries of the
+// profiled verifications.
+type profileTestProfiler struct {
+	summaries []*BlockProfileSummary
+}
+
+func (*profileTestProfiler) Start(b *StatelessBlock) *BlockProfile {
+	return NewBlockProfile(b)
+}
+
+func (p *profileTestProfiler) Finish(profile *BlockProfile, err error) {
+	p.summaries = append(p.summaries, profile.Summary(err))
+}
+
+type profileTestVM struct {
+	*contextTestVM
+
+	profiler BlockProfiler
+}
+
+func (vm *profileTestVM) BlockProfiler() BlockProfiler {
+	return vm.profiler
+}
+
+func TestVerifyProfiled(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	blk, cvm, _ := newContextTestBlock(t, require)
+	profiler := &profileTestProfiler{}
+	blk.vm = &profileTestVM{contextTestVM: cvm, profiler: profiler}
+	require.NoError(blk.Verify(ctx))
+	require.Len(profiler.summaries, 1)
+
+	// Each phase of the verification is recorded in order
+	summary := profiler.summaries[0]
+	require.Equal(blk.ID(), summary.BlockID)
+	require.Equal(blk.Hght, summary.Height)
+	require.Equal(1, summary.Txs)
+	require.Empty(summary.Error)
+	phases := []string{}
+	for _, phase := range summary.Phases {
+		phases = append(phases, phase.Name)
+	}
+	require.Equal([]string{
+		ProfilePhaseParentView,
+		ProfilePhaseChecks,
+		ProfilePhaseExecute,
+		ProfilePhaseWaitRoot,
+		ProfilePhaseWaitSignatures,
+		ProfilePhaseExport,
+	}, phases)
+	require.Len(summary.HottestActions, 1)
+	require.Equal(uint8(0), summary.HottestActions[0].TypeID)
+	require.Equal(1, summary.HottestActions[0].Count)
+
+	// Profiling doesn't change the result of the verification
+	require.Len(blk.results, 1)
+	require.True(blk.results[0].Success)
+}
+
+func TestVerifyProfiledAncestor(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	// Only the phases of the profiled block are recorded
+	blk, _, _ := newContextTestBlock(t, require)
+	profile := NewBlockProfile(&StatelessBlock{StatefulBlock: &StatefulBlock{}, id: ids.GenerateTestID()})
+	require.NoError(blk.verifyWithContext(withBlockProfile(ctx, profile), blk.vm.(*contextTestVM).vctx, nil))
+	summary := profile.Summary(nil)
+	require.Empty(summary.Phases)
+	require.Empty(summary.HottestActions)
+}
+
+func TestBlockProfileHottestActions(t *testing.T) {
+	require := require.New(t)
+
+	profile := NewBlockProfile(&StatelessBlock{StatefulBlock: &StatefulBlock{}})
+	for i := 0; i < MaxProfiledActions+2; i++ {
+		profile.recordAction(uint8(i), time.Duration(i)*time.Millisecond)
+	}
+	profile.recordAction(0, 20*time.Millisecond)
+
+	// The action types with the largest cumulative execution time are
+	// included (in descending order)
+	actions := profile.Summary(nil).HottestActions
+	require.Len(actions, MaxProfiledActions)
+	require.Equal(&ActionProfile{TypeID: 0, Count: 2, Duration: 20 * time.Millisecond}, actions[0])
+	require.Equal(uint8(MaxProfiledActions+1), actions[1].TypeID)
+	require.Equal(uint8(3), actions[MaxProfiledActions-1].TypeID)
+
+	// A nil profile records nothing
+	var nilProfile *BlockProfile
+	nilProfile.mark(ProfilePhaseExecute)
+	nilProfile.recordAction(0, time.Second)
+}
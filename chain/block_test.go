@@ -0,0 +1,931 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+// testParser returns [Rules] that only activate [forks], enable epochs of
+// [epochLength], and include a results root if [resultsRoot] (which is all
+// that is needed to parse blocks).
+type testParser struct {
+	forks       ForkActivations
+	epochLength uint64
+	resultsRoot bool
+}
+
+func (p *testParser) Rules(int64) Rules {
+	return &parserTestRules{forkTestRules: forkTestRules{activations: p.forks}, parser: p}
+}
+
+type parserTestRules struct {
+	forkTestRules
+
+	parser *testParser
+}
+
+func (r *parserTestRules) GetEpochLength() uint64      { return r.parser.epochLength }
+func (r *parserTestRules) GetIncludeResultsRoot() bool { return r.parser.resultsRoot }
+
+func (*testParser) Registry() (ActionRegistry, AuthRegistry) {
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	_ = actionRegistry.Register(0, unmarshalTestAction, false)
+	authRegistry := codec.NewTypeParser[Auth, bool]()
+	_ = authRegistry.Register(0, unmarshalTestAuth, false)
+	return actionRegistry, authRegistry
+}
+
+// builderTestParser activates [RestrictBuildersFork] from genesis.
+var builderTestParser = &testParser{forks: ForkActivations{RestrictBuildersFork: 0}}
+
+// headerRootsTestParser activates [RestrictBuildersFork] and [HeaderRootsFork]
+// from genesis.
+var headerRootsTestParser = &testParser{forks: ForkActivations{RestrictBuildersFork: 0, HeaderRootsFork: 0}}
+
+type testAction struct {
+	payload []byte
+}
+
+func (*testAction) GetTypeID() uint8                           { return 0 }
+func (*testAction) ValidRange(Rules) (int64, int64)            { return -1, -1 }
+func (a *testAction) Marshal(p *codec.Packer)                  { p.PackBytes(a.payload) }
+func (a *testAction) Size() int                                { return codec.BytesLen(a.payload) }
+func (*testAction) ComputeUnits(Rules) uint64                  { return 1 }
+func (*testAction) StateKeysMaxChunks() []uint16               { return nil }
+func (*testAction) StateKeys(codec.Address, ids.ID) state.Keys { return state.Keys{} }
+func (*testAction) Execute(context.Context, Rules, state.Mutable, int64, codec.Address, ids.ID) ([][]byte, error) {
+	return nil, nil
+}
+
+func unmarshalTestAction(p *codec.Packer) (Action, error) {
+	var a testAction
+	p.UnpackBytes(64, false, &a.payload)
+	return &a, p.Err()
+}
+
+type testAuth struct {
+	actor codec.Address
+}
+
+func (*testAuth) GetTypeID() uint8                     { return 0 }
+func (*testAuth) ValidRange(Rules) (int64, int64)      { return -1, -1 }
+func (a *testAuth) Marshal(p *codec.Packer)            { p.PackAddress(a.actor) }
+func (*testAuth) Size() int                            { return codec.AddressLen }
+func (*testAuth) ComputeUnits(Rules) uint64            { return 1 }
+func (*testAuth) Verify(context.Context, []byte) error { return nil }
+func (a *testAuth) Actor() codec.Address               { return a.actor }
+func (a *testAuth) Sponsor() codec.Address             { return a.actor }
+
+func unmarshalTestAuth(p *codec.Packer) (Auth, error) {
+	var a testAuth
+	p.UnpackAddress(&a.actor)
+	return &a, p.Err()
+}
+
+type testAuthFactory struct {
+	actor codec.Address
+}
+
+func (f *testAuthFactory) Sign([]byte) (Auth, error) {
+	return &testAuth{actor: f.actor}, nil
+}
+
+func (*testAuthFactory) MaxUnits() (uint64, uint64) {
+	return codec.AddressLen, 1
+}
+
+// newTestBlock returns a block at [height] with [txs] transactions.
+func newTestBlock(tb testing.TB, height uint64, txs int) *StatefulBlock {
+	var (
+		parser                       = &testParser{}
+		actionRegistry, authRegistry = parser.Registry()
+		factory                      = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		chainID                      = ids.GenerateTestID()
+	)
+	blk := &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    int64(height) * 1_000,
+		Hght:      height,
+		Txs:       make([]*Transaction, 0, txs),
+		StateRoot: ids.GenerateTestID(),
+	}
+	for i := 0; i < txs; i++ {
+		tx := NewTx(
+			&Base{Timestamp: blk.Tmstmp + 1_000, ChainID: chainID, MaxFee: uint64(i) + 1},
+			[]Action{&testAction{payload: binary.BigEndian.AppendUint64(nil, uint64(i))}},
+		)
+		tx, err := tx.Sign(factory, actionRegistry, authRegistry)
+		require.NoError(tb, err)
+		blk.Txs = append(blk.Txs, tx)
+	}
+	return blk
+}
+
+// testBuilderVerifier accepts signatures produced by [testBuilderSignature]
+// from each builder in [validators] (once the P-Chain height it joined the
+// validator set at is reached).
+type testBuilderVerifier struct {
+	validators map[codec.Address]uint64
+}
+
+func (v *testBuilderVerifier) VerifyBuilder(
+	_ context.Context,
+	builder codec.Address,
+	pChainHeight uint64,
+	digest ids.ID,
+	signature []byte,
+) (bool, error) {
+	joined, ok := v.validators[builder]
+	if !ok || pChainHeight < joined {
+		return false, nil
+	}
+	return bytes.Equal(signature, testBuilderSignature(digest)), nil
+}
+
+func testBuilderSignature(digest ids.ID) []byte {
+	return bytes.Repeat(digest[:], bls.SignatureLen/ids.IDLen)
+}
+
+// newBuilderTestBlock returns an empty block built (and signed with
+// [testBuilderSignature]) by [builder].
+func newBuilderTestBlock(require *require.Assertions, builder codec.Address) *StatelessBlock {
+	blk := &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    1,
+		Hght:      1,
+		Builder:   builder,
+		Txs:       []*Transaction{},
+		StateRoot: ids.GenerateTestID(),
+	}
+	if builder != codec.EmptyAddress {
+		blk.BuilderSignature = make([]byte, bls.SignatureLen)
+	}
+	raw, err := blk.Marshal(builderTestParser)
+	require.NoError(err)
+	if builder != codec.EmptyAddress {
+		blk.BuilderSignature = testBuilderSignature(builderDigest(raw))
+		copy(raw[len(raw)-bls.SignatureLen:], blk.BuilderSignature)
+	}
+	return &StatelessBlock{StatefulBlock: blk, bytes: raw}
+}
+
+func TestBlockBuilderMarshal(t *testing.T) {
+	tests := []struct {
+		name        string
+		builder     codec.Address
+		resultsRoot ids.ID
+	}{
+		{
+			name: "no builder",
+		},
+		{
+			name:    "builder",
+			builder: codec.CreateAddress(1, ids.GenerateTestID()),
+		},
+		{
+			name:        "results root",
+			resultsRoot: ids.GenerateTestID(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			blk := &StatefulBlock{
+				Prnt:        ids.GenerateTestID(),
+				Tmstmp:      1,
+				Hght:        1,
+				Builder:     tt.builder,
+				Txs:         []*Transaction{},
+				StateRoot:   ids.GenerateTestID(),
+				ResultsRoot: tt.resultsRoot,
+			}
+			if tt.builder != codec.EmptyAddress {
+				blk.BuilderSignature = testBuilderSignature(ids.GenerateTestID())
+			}
+			parser := &testParser{forks: builderTestParser.forks, resultsRoot: tt.resultsRoot != ids.Empty}
+			raw, err := blk.Marshal(parser)
+			require.NoError(err)
+
+			parsed, err := UnmarshalBlock(raw, parser)
+			require.NoError(err)
+			require.Equal(tt.builder, parsed.Builder)
+			require.Equal(blk.BuilderSignature, parsed.BuilderSignature)
+			require.Equal(tt.resultsRoot, parsed.ResultsRoot)
+
+			id, err := parsed.ID(parser)
+			require.NoError(err)
+			expected, err := blk.ID(parser)
+			require.NoError(err)
+			require.Equal(expected, id)
+		})
+	}
+}
+
+func TestBlockBuilderBeforeFork(t *testing.T) {
+	require := require.New(t)
+
+	// Before [RestrictBuildersFork], blocks don't encode a builder
+	blk := newTestBlock(t, 1, 1)
+	raw, err := blk.Marshal(&testParser{})
+	require.NoError(err)
+	forkRaw, err := blk.Marshal(builderTestParser)
+	require.NoError(err)
+	require.Len(forkRaw, len(raw)+consts.BoolLen)
+
+	// ... so a block with a builder can't be marshaled
+	blk.Builder = codec.CreateAddress(1, ids.GenerateTestID())
+	blk.BuilderSignature = testBuilderSignature(ids.GenerateTestID())
+	_, err = blk.Marshal(&testParser{})
+	require.ErrorIs(err, ErrBuilderNotActive)
+
+	// A builder must sign the block
+	blk.BuilderSignature = nil
+	_, err = blk.Marshal(builderTestParser)
+	require.ErrorIs(err, ErrInvalidBuilderSignature)
+}
+
+func TestUnmarshalBlockTooSmall(t *testing.T) {
+	require := require.New(t)
+
+	// An empty block (without a builder, results root, or epoch) is the
+	// smallest valid block
+	blk := &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    1,
+		Hght:      1,
+		Txs:       []*Transaction{},
+		StateRoot: ids.GenerateTestID(),
+	}
+	raw, err := blk.Marshal(&testParser{})
+	require.NoError(err)
+	require.Len(raw, minBlockHeaderSize)
+	_, err = UnmarshalBlock(raw, &testParser{})
+	require.NoError(err)
+
+	_, err = UnmarshalBlock([]byte{}, &testParser{})
+	require.ErrorIs(err, ErrBlockTooSmall)
+	_, err = UnmarshalBlock(raw[:minBlockHeaderSize-1], &testParser{})
+	require.ErrorIs(err, ErrBlockTooSmall)
+}
+
+func TestUnmarshalFieldLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload int
+		err     error
+	}{
+		{
+			name:    "at limit",
+			payload: 64,
+		},
+		{
+			name:    "over limit",
+			payload: 65,
+			err:     codec.ErrFieldTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			parser := &testParser{}
+			actionRegistry, authRegistry := parser.Registry()
+			factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+
+			// [testAction] only parses payloads of up to 64 bytes, so the
+			// tx is signed with a registry that parses any payload
+			unlimitedRegistry := codec.NewTypeParser[Action, bool]()
+			require.NoError(unlimitedRegistry.Register(0, func(p *codec.Packer) (Action, error) {
+				var a testAction
+				p.UnpackBytes(-1, false, &a.payload)
+				return &a, p.Err()
+			}, false))
+			blk := newTestBlock(t, 1, 1)
+			tx, err := NewTx(
+				&Base{Timestamp: blk.Tmstmp + 1_000, ChainID: ids.GenerateTestID(), MaxFee: 1},
+				[]Action{&testAction{payload: make([]byte, tt.payload)}},
+			).Sign(factory, unlimitedRegistry, authRegistry)
+			require.NoError(err)
+			_, err = UnmarshalTx(codec.NewReader(tx.Bytes(), consts.NetworkSizeLimit), actionRegistry, authRegistry)
+			require.ErrorIs(err, tt.err)
+
+			// The limit is enforced when parsing the txs of a block
+			blk.Txs = append(blk.Txs, tx)
+			raw, err := blk.Marshal(parser)
+			require.NoError(err)
+			_, err = UnmarshalBlock(raw, parser)
+			require.ErrorIs(err, tt.err)
+			rawTxs, err := blk.MarshalTxs()
+			require.NoError(err)
+			_, err = UnmarshalBlockTxs(rawTxs, parser)
+			require.ErrorIs(err, tt.err)
+		})
+	}
+}
+
+func TestUnmarshalBlockHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		txs         int
+		builder     codec.Address
+		resultsRoot ids.ID
+		epoch       uint64
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "txs",
+			txs:  16,
+		},
+		{
+			name:        "builder and results root",
+			txs:         16,
+			builder:     codec.CreateAddress(1, ids.GenerateTestID()),
+			resultsRoot: ids.GenerateTestID(),
+		},
+		{
+			name:  "epoch",
+			txs:   16,
+			epoch: 10,
+		},
+	}
+	layouts := map[string]ForkActivations{
+		"roots after txs":  builderTestParser.forks,
+		"roots before txs": headerRootsTestParser.forks,
+	}
+	for _, tt := range tests {
+		for layout, forks := range layouts {
+			t.Run(tt.name+"/"+layout, func(t *testing.T) {
+				require := require.New(t)
+
+				parser := &testParser{forks: forks, resultsRoot: tt.resultsRoot != ids.Empty}
+				if tt.epoch != 0 {
+					parser.epochLength = 2 // block 1 starts the first epoch
+				}
+
+				blk := newTestBlock(t, 1, tt.txs)
+				blk.Builder = tt.builder
+				if tt.builder != codec.EmptyAddress {
+					blk.BuilderSignature = testBuilderSignature(ids.GenerateTestID())
+				}
+				blk.ResultsRoot = tt.resultsRoot
+				blk.EpochPChainHeight = tt.epoch
+				raw, err := blk.Marshal(parser)
+				require.NoError(err)
+
+				header, err := UnmarshalBlockHeader(raw, parser)
+				require.NoError(err)
+				parsed, err := UnmarshalBlock(raw, parser)
+				require.NoError(err)
+				require.Equal(parsed.Prnt, header.Prnt)
+				require.Equal(parsed.Tmstmp, header.Tmstmp)
+				require.Equal(parsed.Hght, header.Hght)
+				require.Equal(parsed.Builder, header.Builder)
+				require.Equal(blk.StateRoot, header.StateRoot)
+				require.Equal(parsed.StateRoot, header.StateRoot)
+				require.Equal(parsed.ResultsRoot, header.ResultsRoot)
+				require.Equal(parsed.EpochPChainHeight, header.EpochPChainHeight)
+				require.Len(parsed.Txs, header.TxCount)
+				timestamp, err := UnmarshalBlockTimestamp(raw)
+				require.NoError(err)
+				require.Equal(parsed.Tmstmp, timestamp)
+
+				// Header can't be parsed from a truncated block
+				_, err = UnmarshalBlockHeader(raw[:ids.IDLen], parser)
+				require.ErrorIs(err, wrappers.ErrInsufficientLength)
+			})
+		}
+	}
+}
+
+func TestBlockHeaderRootsFork(t *testing.T) {
+	require := require.New(t)
+
+	// [HeaderRootsFork] only moves the roots of a block...
+	blk := newTestBlock(t, 1, 16)
+	raw, err := blk.Marshal(builderTestParser)
+	require.NoError(err)
+	forkRaw, err := blk.Marshal(headerRootsTestParser)
+	require.NoError(err)
+	require.Len(forkRaw, len(raw))
+	require.NotEqual(raw, forkRaw)
+
+	// ...so each layout can only be parsed by the rules that produced it
+	_, err = UnmarshalBlock(forkRaw, builderTestParser)
+	require.Error(err)
+	_, err = UnmarshalBlock(raw, headerRootsTestParser)
+	require.Error(err)
+}
+
+func TestBlockEpochEncoding(t *testing.T) {
+	require := require.New(t)
+
+	// Blocks don't encode an epoch when epochs are disabled...
+	blk := newTestBlock(t, 10, 1)
+	raw, err := blk.Marshal(&testParser{})
+	require.NoError(err)
+	epochParser := &testParser{epochLength: 10}
+	blk.EpochPChainHeight = 5
+	_, err = blk.Marshal(&testParser{})
+	require.ErrorIs(err, ErrInvalidEpoch)
+
+	// ...or when they don't start an epoch
+	blk.Hght = 11
+	_, err = blk.Marshal(epochParser)
+	require.ErrorIs(err, ErrInvalidEpoch)
+	blk.EpochPChainHeight = 0
+	epochRaw, err := blk.Marshal(epochParser)
+	require.NoError(err)
+	require.Len(epochRaw, len(raw))
+
+	// The first block of an epoch must encode its P-Chain height
+	blk.Hght = 10
+	blk.EpochPChainHeight = 5
+	epochRaw, err = blk.Marshal(epochParser)
+	require.NoError(err)
+	require.Len(epochRaw, len(raw)+consts.Uint64Len)
+	parsed, err := UnmarshalBlock(epochRaw, epochParser)
+	require.NoError(err)
+	require.Equal(blk.EpochPChainHeight, parsed.EpochPChainHeight)
+}
+
+func TestBlockResultsRootEncoding(t *testing.T) {
+	require := require.New(t)
+
+	// Blocks only encode a results root when it is included
+	blk := newTestBlock(t, 1, 1)
+	raw, err := blk.Marshal(&testParser{})
+	require.NoError(err)
+	resultsParser := &testParser{resultsRoot: true}
+	blk.ResultsRoot = ids.GenerateTestID()
+	_, err = blk.Marshal(&testParser{})
+	require.ErrorIs(err, ErrResultsRootMismatch)
+
+	resultsRaw, err := blk.Marshal(resultsParser)
+	require.NoError(err)
+	require.Len(resultsRaw, len(raw)+ids.IDLen)
+	parsed, err := UnmarshalBlock(resultsRaw, resultsParser)
+	require.NoError(err)
+	require.Equal(blk.ResultsRoot, parsed.ResultsRoot)
+}
+
+func TestMarshalBlockTxs(t *testing.T) {
+	for _, txs := range []int{0, 1, 16} {
+		t.Run(fmt.Sprintf("%d txs", txs), func(t *testing.T) {
+			require := require.New(t)
+
+			blk := newTestBlock(t, 1, txs)
+			raw, err := blk.MarshalTxs()
+			require.NoError(err)
+
+			parsed, err := UnmarshalBlockTxs(raw, &testParser{})
+			require.NoError(err)
+			require.Len(parsed, txs)
+			for i, tx := range parsed {
+				require.Equal(blk.Txs[i].ID(), tx.ID())
+			}
+
+			// Transactions are packed in the same format as the block (where
+			// they are packed last once [HeaderRootsFork] is active)
+			blkRaw, err := blk.Marshal(headerRootsTestParser)
+			require.NoError(err)
+			require.True(bytes.HasSuffix(blkRaw, raw))
+
+			// Leftover bytes are rejected
+			_, err = UnmarshalBlockTxs(append(raw, 0), &testParser{})
+			require.ErrorIs(err, ErrInvalidObject)
+		})
+	}
+}
+
+func TestUnmarshalBlockTxsOversizedCount(t *testing.T) {
+	require := require.New(t)
+
+	raw, err := newTestBlock(t, 1, 2).MarshalTxs()
+	require.NoError(err)
+
+	// Count can't exceed the number of transactions that fit in the
+	// remaining bytes
+	binary.BigEndian.PutUint32(raw, math.MaxInt32)
+	_, err = UnmarshalBlockTxs(raw, &testParser{})
+	require.ErrorIs(err, ErrTooManyTxs)
+
+	// Count that fits but has no matching transactions
+	binary.BigEndian.PutUint32(raw, 3)
+	_, err = UnmarshalBlockTxs(raw, &testParser{})
+	require.ErrorIs(err, wrappers.ErrInsufficientLength)
+}
+
+func BenchmarkUnmarshalBlock(b *testing.B) {
+	parser := headerRootsTestParser
+	raw, err := newTestBlock(b, 1, 1_024).Marshal(parser)
+	require.NoError(b, err)
+
+	b.Run("header", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := UnmarshalBlockHeader(raw, parser); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := UnmarshalBlock(raw, parser); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestVerifyBuilder(t *testing.T) {
+	validator := codec.CreateAddress(1, ids.GenerateTestID())
+	nonValidator := codec.CreateAddress(1, ids.GenerateTestID())
+	vs := &testBuilderVerifier{
+		validators: map[codec.Address]uint64{validator: 10, nonValidator: 20},
+	}
+
+	tests := []struct {
+		name     string
+		restrict bool
+		forks    ForkActivations
+		builder  codec.Address
+		resign   bool
+		bctx     *block.Context
+		err      error
+	}{
+		{
+			name:    "unrestricted",
+			forks:   builderTestParser.forks,
+			builder: nonValidator,
+			bctx:    &block.Context{PChainHeight: 10},
+		},
+		{
+			name:     "before fork",
+			restrict: true,
+			bctx:     &block.Context{PChainHeight: 10},
+		},
+		{
+			name:     "validator",
+			restrict: true,
+			forks:    builderTestParser.forks,
+			builder:  validator,
+			bctx:     &block.Context{PChainHeight: 10},
+		},
+		{
+			name:     "not a validator at P-Chain height",
+			restrict: true,
+			forks:    builderTestParser.forks,
+			builder:  nonValidator,
+			bctx:     &block.Context{PChainHeight: 10},
+			err:      ErrUnauthorizedBuilder,
+		},
+		{
+			name:     "signed by another builder",
+			restrict: true,
+			forks:    builderTestParser.forks,
+			builder:  validator,
+			resign:   true,
+			bctx:     &block.Context{PChainHeight: 10},
+			err:      ErrUnauthorizedBuilder,
+		},
+		{
+			name:     "missing builder",
+			restrict: true,
+			forks:    builderTestParser.forks,
+			bctx:     &block.Context{PChainHeight: 10},
+			err:      ErrUnauthorizedBuilder,
+		},
+		{
+			name:     "missing context",
+			restrict: true,
+			forks:    builderTestParser.forks,
+			builder:  validator,
+			err:      ErrUnauthorizedBuilder,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			r := NewMockRules(ctrl)
+			r.EXPECT().GetRestrictBuilders().Return(tt.restrict).AnyTimes()
+			r.EXPECT().GetForkActivations().Return(tt.forks).AnyTimes()
+
+			b := newBuilderTestBlock(require, tt.builder)
+			if tt.resign {
+				// A signature of another block can't be reused
+				b.BuilderSignature = testBuilderSignature(ids.GenerateTestID())
+			}
+			err := verifyBuilder(context.Background(), vs, r, b, tt.bctx)
+			require.ErrorIs(err, tt.err)
+		})
+	}
+}
+
+func FuzzUnmarshalBlock(f *testing.F) {
+	// Seed with genesis, single-tx, and large blocks
+	for _, blk := range []*StatefulBlock{
+		NewGenesisBlock(ids.GenerateTestID()),
+		newTestBlock(f, 1, 1),
+		newTestBlock(f, 2, 1_024),
+	} {
+		raw, err := blk.Marshal(builderTestParser)
+		require.NoError(f, err)
+		f.Add(raw)
+	}
+
+	parser := builderTestParser
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		require := require.New(t)
+
+		// Parsing must never panic
+		blk, err := UnmarshalBlock(raw, parser)
+		if err != nil {
+			return
+		}
+
+		// Any parsed block must round-trip
+		remarshaled, err := blk.Marshal(parser)
+		require.NoError(err)
+		reparsed, err := UnmarshalBlock(remarshaled, parser)
+		require.NoError(err)
+		reremarshaled, err := reparsed.Marshal(parser)
+		require.NoError(err)
+		require.Equal(remarshaled, reremarshaled)
+		require.Equal(blk.Prnt, reparsed.Prnt)
+		require.Equal(blk.Tmstmp, reparsed.Tmstmp)
+		require.Equal(blk.Hght, reparsed.Hght)
+		require.Equal(blk.Builder, reparsed.Builder)
+		require.Equal(blk.BuilderSignature, reparsed.BuilderSignature)
+		require.Equal(blk.StateRoot, reparsed.StateRoot)
+		require.Equal(blk.ResultsRoot, reparsed.ResultsRoot)
+		require.Len(reparsed.Txs, len(blk.Txs))
+		for i, tx := range blk.Txs {
+			require.Equal(tx.ID(), reparsed.Txs[i].ID())
+		}
+	})
+}
+
+func TestDelayedExecutionActivation(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	// Delayed execution is active for blocks in [3, 6)
+	immediate := NewMockRules(ctrl)
+	immediate.EXPECT().GetDelayedExecution().Return(false).AnyTimes()
+	delayed := NewMockRules(ctrl)
+	delayed.EXPECT().GetDelayedExecution().Return(true).AnyTimes()
+	rules := func(height uint64) Rules {
+		if height >= 3 && height < 6 {
+			return delayed
+		}
+		return immediate
+	}
+
+	var (
+		pendingTxs []*Transaction
+		included   []*Transaction
+		executed   []*Transaction
+		executedAt = map[ids.ID]uint64{}
+	)
+	for height := uint64(1); height <= 8; height++ {
+		blk := newTestBlock(t, height, 2)
+		isDelayed := rules(height).GetDelayedExecution()
+		txs := executedTxs(pendingTxs, blk.Txs, isDelayed)
+		for _, tx := range txs {
+			executedAt[tx.ID()] = height
+		}
+		included = append(included, blk.Txs...)
+		executed = append(executed, txs...)
+
+		switch height {
+		case 3:
+			// First delayed block executes nothing
+			require.Empty(txs)
+		case 4, 5:
+			// Delayed blocks execute their parent's txs
+			require.Equal(pendingTxs, txs)
+		case 6:
+			// First immediate block executes its parent's txs and its own
+			require.Len(txs, 4)
+			require.Equal(pendingTxs, txs[:2])
+			require.Equal(blk.Txs, txs[2:])
+		default:
+			require.Equal(blk.Txs, txs)
+		}
+
+		pendingTxs = nil
+		if isDelayed {
+			pendingTxs = blk.Txs
+		}
+	}
+
+	// All txs are executed exactly once (in the order they were included)
+	require.Equal(included, executed)
+	for i, tx := range included {
+		height := uint64(i/2) + 1
+		if height >= 3 && height < 6 {
+			height++
+		}
+		require.Equal(height, executedAt[tx.ID()])
+	}
+}
+
+func TestParseSyncableBlock(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	vm := &offlineTestVM{r: newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())}
+
+	blk := newTestBlock(t, 1, 16)
+	raw, err := blk.Marshal(vm)
+	require.NoError(err)
+	parsed, err := ParseStatefulBlock(ctx, blk, raw, choices.Accepted, vm)
+	require.NoError(err)
+	expected := NewSyncableBlock(parsed)
+
+	sb, err := ParseSyncableBlock(ctx, raw, vm)
+	require.NoError(err)
+	require.Equal(expected.String(), sb.String())
+	require.Equal(expected.ID(), sb.ID())
+	require.Equal(raw, sb.Bytes())
+	require.Equal(choices.Accepted, sb.Status())
+
+	// Summaries must commit to a state root
+	blk.StateRoot = ids.Empty
+	raw, err = blk.Marshal(vm)
+	require.NoError(err)
+	_, err = ParseSyncableBlock(ctx, raw, vm)
+	require.ErrorIs(err, ErrStateRootEmpty)
+
+	// Leftover bytes are rejected
+	_, err = ParseSyncableBlock(ctx, append(raw, 0), vm)
+	require.ErrorIs(err, ErrInvalidObject)
+}
+
+func TestWaitSignaturesCanceled(t *testing.T) {
+	require := require.New(t)
+
+	w := workers.NewParallel(1, 1)
+	defer w.Stop()
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	job, err := w.NewJob(1)
+	require.NoError(err)
+	release := make(chan struct{})
+	job.Go(func() error {
+		<-release
+		return ErrInvalidSignature
+	})
+	job.Done(nil)
+	blk := &StatelessBlock{sigJob: job}
+
+	// Waiting returns once [ctx] is canceled, even if signatures are still
+	// being verified
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = blk.waitSignatures(ctx)
+	require.ErrorIs(err, context.Canceled)
+	require.True(IsTransient(err))
+
+	// The result of the job is still returned when retried
+	close(release)
+	require.ErrorIs(blk.waitSignatures(context.Background()), ErrInvalidSignature)
+	require.ErrorIs(blk.waitSignatures(context.Background()), ErrInvalidSignature)
+}
+
+// slowTestAuth takes [delay] to verify and counts the verifications that
+// completed.
+type slowTestAuth struct {
+	testAuth
+
+	delay    time.Duration
+	verified *atomic.Int64
+}
+
+func (a *slowTestAuth) Verify(context.Context, []byte) error {
+	time.Sleep(a.delay)
+	a.verified.Add(1)
+	return nil
+}
+
+type rejectTestVM struct {
+	*offlineTestVM
+
+	workers workers.Workers
+}
+
+func (vm *rejectTestVM) AuthVerifiers() workers.Workers         { return vm.workers }
+func (*rejectTestVM) Rejected(context.Context, *StatelessBlock) {}
+
+func TestRejectCancelsSignatureVerification(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	var (
+		chainID = ids.GenerateTestID()
+		factory = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		w       = workers.NewParallel(2, 1)
+		vm      = &rejectTestVM{&offlineTestVM{}, w}
+
+		count    = 100
+		verified atomic.Int64
+		txs      = make([]*Transaction, count)
+	)
+	defer w.Stop()
+	actionRegistry, authRegistry := (&testParser{}).Registry()
+	for i := range txs {
+		tx, err := NewTx(
+			&Base{Timestamp: 2_000, ChainID: chainID, MaxFee: uint64(i) + 1},
+			[]Action{&testAction{payload: []byte{0}}},
+		).Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		tx.Auth = &slowTestAuth{delay: 10 * time.Millisecond, verified: &verified}
+		txs[i] = tx
+	}
+	blk := &StatelessBlock{StatefulBlock: &StatefulBlock{Txs: txs}, vm: vm, st: choices.Processing}
+	require.NoError(blk.populateTxs(ctx))
+
+	// Reject the block once its signatures are being verified
+	require.Eventually(func() bool { return verified.Load() > 0 }, time.Second, time.Millisecond)
+	require.NoError(blk.Reject(ctx))
+
+	// The signatures that were not being verified when the block was rejected
+	// are skipped
+	require.ErrorIs(blk.waitSignatures(ctx), context.Canceled)
+	completed := verified.Load()
+	require.Less(completed, int64(count/2))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(completed, verified.Load())
+
+	// The workers can still verify the signatures of other blocks
+	job, err := w.NewJob(1)
+	require.NoError(err)
+	job.Go(func() error { return nil })
+	job.Done(nil)
+	require.NoError(job.Wait())
+}
+
+func TestStatelessBlockSize(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	var (
+		chainID = ids.GenerateTestID()
+		factory = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		vm      = &offlineTestVM{
+			r:            newOfflineTestRules(gomock.NewController(t), chainID),
+			lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{Hght: 2}, st: choices.Accepted},
+		}
+		parent = &StatelessBlock{StatefulBlock: &StatefulBlock{Hght: 1}, st: choices.Accepted}
+	)
+
+	// A block that is being built is marshaled to compute its size
+	built := NewBlock(vm, parent, 1_000)
+	built.Txs = []*Transaction{newACLTestTx(require, chainID, factory)}
+	require.Empty(built.Bytes())
+	raw, err := built.StatefulBlock.Marshal(vm)
+	require.NoError(err)
+	size, err := built.Size()
+	require.NoError(err)
+	require.Equal(len(raw), size)
+
+	// The size of a parsed block is the length of its bytes
+	parsed, err := ParseBlock(ctx, raw, choices.Accepted, vm)
+	require.NoError(err)
+	parsedSize, err := parsed.Size()
+	require.NoError(err)
+	require.Equal(len(parsed.Bytes()), parsedSize)
+	require.Equal(size, parsedSize)
+
+	// A block larger than [consts.NetworkSizeLimit] has no size
+	oversized := NewBlock(vm, parent, 1_000)
+	for len(oversized.Txs)*len(built.Txs[0].Bytes()) <= consts.NetworkSizeLimit {
+		oversized.Txs = append(oversized.Txs, built.Txs[0])
+	}
+	_, err = oversized.Size()
+	require.ErrorIs(err, wrappers.ErrInsufficientLength)
+}
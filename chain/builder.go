@@ -13,9 +13,11 @@ import (
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/ava-labs/hypersdk/executor"
@@ -59,6 +61,16 @@ func HandlePreExecute(log logging.Logger, err error) bool {
 	}
 }
 
+// builderMempool is the subset of [Mempool] used to select the transactions
+// included in a block.
+type builderMempool interface {
+	Len(context.Context) int
+	StartStreaming(context.Context)
+	PrepareStream(context.Context, int)
+	Stream(context.Context, int) []*Transaction
+	FinishStreaming(context.Context, []*Transaction) int
+}
+
 // TODO: This code is terrible and will be removed during the Vryx integration.
 func BuildBlock(
 	ctx context.Context,
@@ -67,25 +79,48 @@ func BuildBlock(
 ) (*StatelessBlock, error) {
 	ctx, span := vm.Tracer().Start(ctx, "chain.BuildBlock")
 	defer span.End()
-	log := vm.Logger()
 
 	// We don't need to fetch the [VerifyContext] because
 	// we will always have a block to build on.
 
 	// Select next timestamp
-	nextTime := time.Now().UnixMilli()
+	nextTime := vm.Now().UnixMilli()
 	r := vm.Rules(nextTime)
 	if nextTime < parent.Tmstmp+r.GetMinBlockGap() {
-		log.Debug("block building failed", zap.Error(ErrTimestampTooEarly))
+		vm.Logger().Debug("block building failed", zap.Error(ErrTimestampTooEarly))
 		return nil, ErrTimestampTooEarly
 	}
+	return buildBlock(ctx, vm, parent, nextTime, vm.Mempool(), nil)
+}
+
+// buildBlock builds a child of [parent] at [nextTime] with transactions
+// streamed from [mempool].
+//
+// If [preview] is not nil, buildBlock performs a dry run: it fills [preview]
+// instead of returning a block and does not record any build metrics (or
+// require the block to contain transactions).
+func buildBlock(
+	ctx context.Context,
+	vm VM,
+	parent *StatelessBlock,
+	nextTime int64,
+	mempool builderMempool,
+	preview *BlockPreview,
+) (*StatelessBlock, error) {
+	span := trace.SpanFromContext(ctx)
+	log := vm.Logger()
+	r := vm.Rules(nextTime)
 	b := NewBlock(vm, parent, nextTime)
+	if restrictBuilders(r, nextTime) {
+		b.Builder = vm.BuilderAddress()
+		b.BuilderSignature = make([]byte, bls.SignatureLen) // signed in [initializeBuilt]
+	}
 
 	// Fetch view where we will apply block state transitions
 	//
 	// If the parent block is not yet verified, we will attempt to
 	// execute it.
-	mempoolSize := vm.Mempool().Len(ctx)
+	mempoolSize := mempool.Len(ctx)
 	changesEstimate := min(mempoolSize, maxViewPreallocation)
 	parentView, err := parent.View(ctx, true)
 	if err != nil {
@@ -93,6 +128,18 @@ func BuildBlock(
 		return nil, err
 	}
 
+	// Record the validator set if we are starting a new epoch
+	b.EpochPChainHeight, err = epochPChainHeight(ctx, vm, r, vm.StateManager(), parentView, b.Hght)
+	if err != nil {
+		log.Warn("block building failed: couldn't select epoch P-Chain height", zap.Error(err))
+		return nil, err
+	}
+	b.epoch, err = loadEpochSnapshot(ctx, vm, r, vm.StateManager(), parentView, b.Hght, b.EpochPChainHeight)
+	if err != nil {
+		log.Warn("block building failed: couldn't load epoch validator set", zap.Error(err))
+		return nil, err
+	}
+
 	// Compute next unit prices to use
 	feeKey := FeeKey(vm.StateManager().FeeKey())
 	feeRaw, err := parentView.GetValue(ctx, feeKey)
@@ -100,18 +147,70 @@ func BuildBlock(
 		return nil, err
 	}
 	parentFeeManager := fees.NewManager(feeRaw)
-	feeManager, err := parentFeeManager.ComputeNext(parent.Tmstmp, nextTime, r)
+	ectx, err := vm.GetExecutionContext(parent.ID(), feeRaw, parent.Tmstmp, nextTime)
 	if err != nil {
 		return nil, err
 	}
+	feeManager := ectx.FeeManager()
 	maxUnits := r.GetMaxBlockUnits()
 	targetUnits := r.GetWindowTargetUnits()
 
+	// Transactions in the [PriorityLane] are streamed from the mempool first and
+	// may consume up to [laneReserved] units before we fill the rest of the block.
+	lane := vm.PriorityLane()
+	laneReserved := priorityLaneUnits(maxUnits, vm.GetPriorityLaneUnitsPercent())
+	laneUnits := fees.Dimensions{}
+
+	// Execute any due [Task]s and the transactions included by [parent] (if it
+	// was built in delayed execution mode) before adding any of our own.
+	//
+	// If we are in delayed execution mode, the transactions we include will only
+	// be executed by our child, so we track the units they will consume
+	// separately from those consumed during execution.
+	if err := b.setExecution(ctx, parent.Tmstmp); err != nil {
+		log.Warn("block building failed: couldn't get parent txs", zap.Error(err))
+		return nil, err
+	}
 	var (
-		ts            = tstate.New(changesEstimate)
-		oldestAllowed = nextTime - r.GetValidityWindow()
+		ts         = tstate.New(changesEstimate)
+		results    = []*Result{}
+		delayed    = b.delayed
+		blockUnits = feeManager
+
+		// executedPending are the transactions of [parent] that were not
+		// dropped during execution
+		executedPending []*Transaction
+	)
+	if len(b.pendingTxs) > 0 {
+		// The order of the parent's transactions is derived from the parent
+		// state root (which verifiers read from [StatefulBlock.StateRoot])
+		if r.GetPermutePendingTxs() {
+			b.StateRoot, err = getMerkleRoot(ctx, parentView)
+			if err != nil {
+				log.Warn("block building failed: couldn't get parent root", zap.Error(err))
+				return nil, err
+			}
+		}
+		results, ts, err = b.Execute(ctx, vm.Tracer(), parentView, feeManager, r)
+		if err != nil {
+			log.Warn("block building failed: couldn't execute parent txs", zap.Error(err))
+			return nil, err
+		}
+		executedPending = b.executedTxs
+	} else if err := writeEpochSnapshot(ctx, vm.StateManager(), parentView, ts, feeManager, r, b.epoch); err != nil {
+		log.Warn("block building failed: couldn't record epoch validator set", zap.Error(err))
+		return nil, err
+	} else if _, err := executeTasks(ctx, vm, parentView, ts, feeManager, r, nextTime); err != nil {
+		log.Warn("block building failed: couldn't execute tasks", zap.Error(err))
+		return nil, err
+	}
+	if delayed {
+		blockUnits = fees.NewManager(nil)
+	}
 
-		mempool = vm.Mempool()
+	var (
+		oldestAllowed = nextTime - r.GetValidityWindow()
+		paused        = r.IsPaused(nextTime)
 
 		// restorable txs after block attempt finishes
 		restorableLock sync.Mutex
@@ -125,7 +224,10 @@ func BuildBlock(
 		blockLock    sync.RWMutex
 		start        = time.Now()
 		txsAttempted = 0
-		results      = []*Result{}
+
+		// included are the results of the transactions added to the block
+		// (only tracked for previews)
+		included = []*Result{}
 
 		sm = vm.StateManager()
 
@@ -137,15 +239,74 @@ func BuildBlock(
 		stop bool
 	)
 
+	// fetch returns the values of [stateKeys] in [parentView] (using and
+	// updating [cache])
+	fetch := func(ctx context.Context, stateKeys state.Keys) (map[string][]byte, error) {
+		// Fetch keys from cache
+		var (
+			storage  = make(map[string][]byte, len(stateKeys))
+			toLookup = make([]string, 0, len(stateKeys))
+		)
+		cacheLock.RLock()
+		for k := range stateKeys {
+			if v, ok := cache[k]; ok {
+				if v.exists {
+					storage[k] = v.v
+				}
+				continue
+			}
+			toLookup = append(toLookup, k)
+		}
+		cacheLock.RUnlock()
+		if len(toLookup) == 0 {
+			return storage, nil
+		}
+
+		// Fetch keys from disk
+		toCache := make(map[string]*fetchData, len(toLookup))
+		defer func() {
+			// Update key cache regardless of whether exit is graceful
+			cacheLock.Lock()
+			for k := range toCache {
+				cache[k] = toCache[k]
+			}
+			cacheLock.Unlock()
+		}()
+		for _, k := range toLookup {
+			v, err := parentView.GetValue(ctx, []byte(k))
+			if errors.Is(err, database.ErrNotFound) {
+				toCache[k] = &fetchData{nil, false, 0}
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			// We verify that the [NumChunks] is already less than the number
+			// added on the write path, so we don't need to do so again here.
+			numChunks, ok := keys.NumChunks(v)
+			if !ok {
+				return nil, ErrInvalidKeyValue
+			}
+			toCache[k] = &fetchData{v, true, numChunks}
+			storage[k] = v
+		}
+		return storage, nil
+	}
+
 	// Batch fetch items from mempool to unblock incoming RPC/Gossip traffic
 	mempool.StartStreaming(ctx)
 	b.Txs = []*Transaction{}
 	for time.Since(start) < vm.GetTargetBuildDuration() && !stop {
+		// Stop building if the engine no longer needs this block
+		if ctx.Err() != nil {
+			break
+		}
 		prepareStreamLock.Lock()
 		txs := mempool.Stream(ctx, streamBatch)
 		prepareStreamLock.Unlock()
 		if len(txs) == 0 {
-			b.vm.RecordClearedMempool()
+			if preview == nil {
+				b.vm.RecordClearedMempool()
+			}
 			break
 		}
 		ctx, executeSpan := vm.Tracer().Start(ctx, "chain.BuildBlock.Execute") //nolint:spancheck
@@ -157,20 +318,233 @@ func BuildBlock(
 			break
 		}
 
-		e := executor.New(streamBatch, vm.GetTransactionExecutionCores(), MaxKeyDependencies, vm.GetExecutorBuildRecorder())
+		var recorder executor.Metrics
+		if preview == nil {
+			recorder = vm.GetExecutorBuildRecorder()
+		}
+		e := executor.New(streamBatch, executionCores(vm.GetTransactionExecutionCores(), r), MaxKeyDependencies, recorder)
 		pending := make(map[ids.ID]*Transaction, streamBatch)
 		var pendingLock sync.Mutex
-		for li, ltx := range txs {
-			txsAttempted++
+
+		// addBundle adds all of [members] (the transactions of a bundle) to
+		// the block (consecutively and in order) or none of them.
+		addBundle := func(members []*Transaction) {
+			// While the chain is paused, only admin transactions can be
+			// included (the rest are kept until it is unpaused)
+			if paused {
+				for _, tx := range members {
+					if !tx.isAdmin(r) {
+						restorableLock.Lock()
+						restorable = append(restorable, members...)
+						restorableLock.Unlock()
+						return
+					}
+				}
+			}
+
+			// The bundle is executed by a single job that accesses the keys
+			// of all of its transactions
+			var (
+				scope       = make(state.Keys)
+				stateKeys   = make([]state.Keys, len(members))
+				commutative = make([][]string, len(members))
+			)
+			for j, tx := range members {
+				var err error
+				stateKeys[j], err = tx.StateKeys(sm, r)
+				if err != nil {
+					// Drop bad bundle and continue
+					return
+				}
+				commutative[j], err = tx.CommutativeKeys(sm, r)
+				if err != nil {
+					return
+				}
+				for k, permissions := range stateKeys[j] {
+					scope.Add(k, permissions)
+				}
+			}
+
+			pendingLock.Lock()
+			for _, tx := range members {
+				pending[tx.ID()] = tx
+			}
+			pendingLock.Unlock()
+			e.Run(scope, func() error {
+				var restore bool
+				defer func() {
+					pendingLock.Lock()
+					for _, tx := range members {
+						delete(pending, tx.ID())
+					}
+					pendingLock.Unlock()
+
+					if !restore {
+						return
+					}
+					restorableLock.Lock()
+					restorable = append(restorable, members...)
+					restorableLock.Unlock()
+				}()
+
+				// Skip all remaining transactions if the engine no longer
+				// needs this block
+				if err := checkContext(ctx); err != nil {
+					restore = true
+					return err
+				}
+				storage, err := fetch(ctx, scope)
+				if err != nil {
+					return err
+				}
+
+				// Skip the bundle unless all of its transactions succeed
+				simulated, retry, err := simulateBundle(ctx, log, ts, storage, members, stateKeys, commutative, feeManager, sm, r, nextTime)
+				if err != nil {
+					log.Debug("skipping bundle",
+						zap.Stringer("bundleID", members[0].Bundle().ID()),
+						zap.Error(err),
+					)
+					restore = retry
+					return nil
+				}
+				var (
+					units       fees.Dimensions
+					laneDelta   fees.Dimensions
+					memberUnits = make([]fees.Dimensions, len(members))
+				)
+				for j, tx := range members {
+					memberUnits[j] = simulated[j].Units
+					if delayed {
+						// We only include the transactions (they will be
+						// executed by our child)
+						memberUnits[j], err = tx.Units(sm, r, nextTime)
+						if err != nil {
+							return nil
+						}
+					}
+					if units, err = fees.Add(units, memberUnits[j]); err != nil {
+						return nil
+					}
+					if lane != nil && lane.Matches(tx) {
+						if laneDelta, err = fees.Add(laneDelta, memberUnits[j]); err != nil {
+							return nil
+						}
+					}
+				}
+
+				blockLock.Lock()
+				defer blockLock.Unlock()
+
+				// Ensure lane transactions don't exceed their reservation
+				if !laneUnits.CanAdd(laneDelta, laneReserved) {
+					log.Debug("skipping bundle: priority lane reservation exhausted")
+					restore = true
+					return nil
+				}
+
+				// Ensure block isn't too big
+				if ok, dimension := blockUnits.Consume(units, maxUnits); !ok {
+					log.Debug(
+						"skipping bundle: too many units",
+						zap.Int("dimension", int(dimension)),
+						zap.Uint64("bundle", units[dimension]),
+						zap.Uint64("block units", blockUnits.LastConsumed(dimension)),
+						zap.Uint64("max block units", maxUnits[dimension]),
+					)
+					restore = true
+					if blockUnits.LastConsumed(dimension) >= targetUnits[dimension] {
+						stop = true
+						return errBlockFull
+					}
+					return nil
+				}
+
+				// Update block with the transactions of the bundle
+				//
+				// The transactions are applied while holding [blockLock] so
+				// that no other transaction is added between them.
+				for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+					laneUnits[i] += laneDelta[i]
+				}
+				applied := make([]*Result, len(members))
+				if delayed {
+					for j := range members {
+						applied[j] = &Result{Units: memberUnits[j]}
+					}
+				} else {
+					applied, err = applyBundle(ctx, ts, storage, members, stateKeys, commutative, feeManager, sm, r, nextTime)
+					if err != nil {
+						log.Warn("unexpected bundle execution error", zap.Error(err))
+						restore = true
+						return err
+					}
+					for _, result := range applied {
+						if result.panicked {
+							vm.RecordActionPanic()
+						}
+					}
+					results = append(results, applied...)
+				}
+				b.Txs = append(b.Txs, members...)
+				if preview != nil {
+					included = append(included, applied...)
+				}
+				return nil
+			})
+		}
+		for li := 0; li < len(txs); {
 			i := li
-			tx := ltx
+			tx := txs[li]
+
+			// The transactions of a bundle are streamed together and are
+			// executed by a single job
+			if tx.Bundle() != nil {
+				end, ok := bundleEnd(txs, i)
+				li = end
+				txsAttempted += end - i
+				if !ok {
+					// Drop incomplete bundle
+					//
+					// This should not happen because bundles are streamed
+					// as a unit.
+					continue
+				}
+				if i <= streamPrefetchThreshold && streamPrefetchThreshold < end {
+					prepareStreamLock.Lock()
+					go func() {
+						mempool.PrepareStream(ctx, streamBatch)
+						prepareStreamLock.Unlock()
+					}()
+				}
+				// Skip the bundle if any of its transactions is a duplicate
+				repeat := false
+				for j := i; j < end; j++ {
+					repeat = repeat || dup.Contains(j)
+				}
+				if !repeat {
+					addBundle(txs[i:end])
+				}
+				continue
+			}
+			li++
+			txsAttempted++
 
 			// Skip any duplicates before going async
 			if dup.Contains(i) {
 				continue
 			}
 
-			stateKeys, err := tx.StateKeys(sm)
+			// While the chain is paused, only admin transactions can be
+			// included (the rest are kept until it is unpaused)
+			if paused && !tx.isAdmin(r) {
+				restorableLock.Lock()
+				restorable = append(restorable, tx)
+				restorableLock.Unlock()
+				continue
+			}
+
+			stateKeys, err := tx.StateKeys(sm, r)
 			if err != nil {
 				// Drop bad transaction and continue
 				//
@@ -178,6 +552,10 @@ func BuildBlock(
 				// adding a transaction to the mempool.
 				continue
 			}
+			commutative, err := tx.CommutativeKeys(sm, r)
+			if err != nil {
+				continue
+			}
 
 			// Once we get part way through a prefetching job, we start
 			// to prepare for the next stream.
@@ -211,57 +589,21 @@ func BuildBlock(
 					restorableLock.Unlock()
 				}()
 
-				// Fetch keys from cache
-				var (
-					storage  = make(map[string][]byte, len(stateKeys))
-					toLookup = make([]string, 0, len(stateKeys))
-				)
-				cacheLock.RLock()
-				for k := range stateKeys {
-					if v, ok := cache[k]; ok {
-						if v.exists {
-							storage[k] = v.v
-						}
-						continue
-					}
-					toLookup = append(toLookup, k)
+				// Skip all remaining transactions if the engine no longer
+				// needs this block
+				if err := checkContext(ctx); err != nil {
+					restore = true
+					return err
 				}
-				cacheLock.RUnlock()
-
-				// Fetch keys from disk
-				var toCache map[string]*fetchData
-				if len(toLookup) > 0 {
-					toCache = make(map[string]*fetchData, len(toLookup))
-					for _, k := range toLookup {
-						v, err := parentView.GetValue(ctx, []byte(k))
-						if errors.Is(err, database.ErrNotFound) {
-							toCache[k] = &fetchData{nil, false, 0}
-							continue
-						} else if err != nil {
-							return err
-						}
-						// We verify that the [NumChunks] is already less than the number
-						// added on the write path, so we don't need to do so again here.
-						numChunks, ok := keys.NumChunks(v)
-						if !ok {
-							return ErrInvalidKeyValue
-						}
-						toCache[k] = &fetchData{v, true, numChunks}
-						storage[k] = v
-					}
 
-					// Update key cache regardless of whether exit is graceful
-					defer func() {
-						cacheLock.Lock()
-						for k := range toCache {
-							cache[k] = toCache[k]
-						}
-						cacheLock.Unlock()
-					}()
+				storage, err := fetch(ctx, stateKeys)
+				if err != nil {
+					return err
 				}
 
 				// Execute block
 				tsv := ts.NewView(stateKeys, storage)
+				tsv.SetAccumulators(commutative, false)
 				if err := tx.PreExecute(ctx, feeManager, sm, r, tsv, nextTime); err != nil {
 					// We don't need to rollback [tsv] here because it will never
 					// be committed.
@@ -270,32 +612,53 @@ func BuildBlock(
 					}
 					return nil
 				}
-				result, err := tx.Execute(
-					ctx,
-					feeManager,
-					sm,
-					r,
-					tsv,
-					nextTime,
-				)
-				if err != nil {
-					// Returning an error here should be avoided at all costs (can be a DoS). Rather,
-					// all units for the transaction should be consumed and a fee should be charged.
-					log.Warn("unexpected post-execution error", zap.Error(err))
-					restore = true
-					return err
+				var result *Result
+				if delayed {
+					// We only include the transaction (it will be executed by our child)
+					units, err := tx.Units(sm, r, nextTime)
+					if err != nil {
+						return nil
+					}
+					result = &Result{Units: units}
+				} else {
+					result, err = tx.Execute(
+						ctx,
+						feeManager,
+						sm,
+						r,
+						tsv,
+						nextTime,
+					)
+					if err != nil {
+						// Returning an error here should be avoided at all costs (can be a DoS). Rather,
+						// all units for the transaction should be consumed and a fee should be charged.
+						log.Warn("unexpected post-execution error", zap.Error(err))
+						restore = true
+						return err
+					}
+					if result.panicked {
+						vm.RecordActionPanic()
+					}
 				}
 
 				blockLock.Lock()
 				defer blockLock.Unlock()
 
+				// Ensure lane transactions don't exceed their reservation
+				inLane := lane != nil && lane.Matches(tx)
+				if inLane && !laneUnits.CanAdd(result.Units, laneReserved) {
+					log.Debug("skipping tx: priority lane reservation exhausted")
+					restore = true
+					return nil
+				}
+
 				// Ensure block isn't too big
-				if ok, dimension := feeManager.Consume(result.Units, maxUnits); !ok {
+				if ok, dimension := blockUnits.Consume(result.Units, maxUnits); !ok {
 					log.Debug(
 						"skipping tx: too many units",
 						zap.Int("dimension", int(dimension)),
 						zap.Uint64("tx", result.Units[dimension]),
-						zap.Uint64("block units", feeManager.LastConsumed(dimension)),
+						zap.Uint64("block units", blockUnits.LastConsumed(dimension)),
 						zap.Uint64("max block units", maxUnits[dimension]),
 					)
 					restore = true
@@ -303,15 +666,26 @@ func BuildBlock(
 					// If we are above the target for the dimension we can't consume, we will
 					// stop building. This prevents a full mempool iteration looking for the
 					// "perfect fit".
-					if feeManager.LastConsumed(dimension) >= targetUnits[dimension] {
+					if blockUnits.LastConsumed(dimension) >= targetUnits[dimension] {
 						stop = true
 						return errBlockFull
 					}
 				}
 
 				// Update block with new transaction
-				tsv.Commit()
+				if inLane {
+					for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+						laneUnits[i] += result.Units[i]
+					}
+				}
 				b.Txs = append(b.Txs, tx)
+				if preview != nil {
+					included = append(included, result)
+				}
+				if delayed {
+					return nil
+				}
+				tsv.Commit()
 				results = append(results, result)
 				return nil
 			})
@@ -340,6 +714,18 @@ func BuildBlock(
 		}
 	}
 
+	// If the engine no longer needs this block (or it would be rejected for
+	// having too few distinct signers), return all included transactions to
+	// the mempool.
+	ctxErr := checkContext(ctx)
+	var signersErr error
+	if ctxErr == nil && preview == nil {
+		signersErr = verifySigners(r, b.Txs)
+	}
+	if ctxErr != nil || signersErr != nil {
+		restorable = append(restorable, b.Txs...)
+	}
+
 	// Wait for stream preparation to finish to make
 	// sure all transactions are returned to the mempool.
 	go func() {
@@ -347,22 +733,49 @@ func BuildBlock(
 		restored := mempool.FinishStreaming(ctx, restorable)
 		b.vm.Logger().Debug("transactions restored to mempool", zap.Int("count", restored))
 	}()
+	if ctxErr != nil {
+		log.Debug("block building canceled", zap.Error(ctxErr))
+		return nil, ctxErr
+	}
+	if signersErr != nil {
+		log.Warn("block building failed: too few distinct signers", zap.Error(signersErr))
+		return nil, signersErr
+	}
 
 	// Update tracking metrics
 	span.SetAttributes(
 		attribute.Int("attempted", txsAttempted),
 		attribute.Int("added", len(b.Txs)),
 	)
-	if time.Since(start) > b.vm.GetTargetBuildDuration() {
-		b.vm.RecordBuildCapped()
-	}
+	if preview == nil {
+		if time.Since(start) > b.vm.GetTargetBuildDuration() {
+			b.vm.RecordBuildCapped()
+		}
+		if lane != nil {
+			b.vm.RecordPriorityLaneUtilization(priorityLaneUtilization(laneUnits, laneReserved))
+		}
 
-	// Perform basic validity checks to make sure the block is well-formatted
-	if len(b.Txs) == 0 {
-		if nextTime < parent.Tmstmp+r.GetMinEmptyBlockGap() {
-			return nil, fmt.Errorf("%w: allowed in %d ms", ErrNoTxs, parent.Tmstmp+r.GetMinEmptyBlockGap()-nextTime) //nolint:spancheck
+		// Perform basic validity checks to make sure the block is well-formatted
+		if len(b.Txs) == 0 && len(b.pendingTxs) == 0 {
+			if nextTime < parent.Tmstmp+r.GetMinEmptyBlockGap() {
+				return nil, fmt.Errorf("%w: allowed in %d ms", ErrNoTxs, parent.Tmstmp+r.GetMinEmptyBlockGap()-nextTime)
+			}
+			vm.RecordEmptyBlockBuilt()
 		}
-		vm.RecordEmptyBlockBuilt()
+	}
+
+	// Apply refunds of executed transactions before recording fees
+	if err := applyRefunds(ctx, vm, parentView, ts, feeManager, r, executedTxs(executedPending, b.Txs, delayed), results); err != nil {
+		return nil, err
+	}
+	if preview != nil {
+		// The block is never emitted, so we don't need to finish it (or
+		// require its fees to cover its cost)
+		return nil, preview.fill(b, included, results, feeManager, ectx.NextBlockCost, delayed, txsAttempted)
+	}
+	if err := ectx.VerifyBlockCost(results); err != nil {
+		log.Warn("block building failed: fees do not cover block cost", zap.Error(err))
+		return nil, err
 	}
 
 	// Update chain metadata
@@ -394,19 +807,36 @@ func BuildBlock(
 
 	// Fetch [parentView] root as late as possible to allow
 	// for async processing to complete
-	root, err := parentView.GetMerkleRoot(ctx)
+	root, err := getMerkleRoot(ctx, parentView)
 	if err != nil {
 		return nil, err
 	}
 	b.StateRoot = root
 
+	// Credit this node with the block in the results of all included
+	// transactions (which isn't committed to by the results root)
+	setBuilder(results, vm.BuilderAddress())
+
+	// Commit to the results of all included transactions (if required)
+	if r.GetIncludeResultsRoot() {
+		resultsRoot, err := ResultsRoot(vm.AuthVerifiers(), results)
+		if err != nil {
+			return nil, err
+		}
+		b.ResultsRoot = resultsRoot
+	}
+
 	// Get view from [tstate] after writing all changed keys
+	if err := b.setStateUsageDiff(ctx, parentView, ts); err != nil {
+		return nil, err
+	}
 	view, err := ts.ExportMerkleDBView(ctx, vm.Tracer(), parentView)
 	if err != nil {
 		return nil, err
 	}
 
 	// Compute block hash and marshaled representation
+	b.executedTxs = executedTxs(executedPending, b.Txs, delayed)
 	if err := b.initializeBuilt(ctx, view, results, feeManager); err != nil {
 		log.Warn("block failed", zap.Int("txs", len(b.Txs)), zap.Any("consumed", feeManager.UnitsConsumed()))
 		return nil, err
@@ -430,7 +860,7 @@ func BuildBlock(
 
 	log.Info(
 		"built block",
-		zap.Uint64("hght", b.Hght),
+		zap.Uint64("height", b.Hght),
 		zap.Int("attempted", txsAttempted),
 		zap.Int("added", len(b.Txs)),
 		zap.Int("state changes", ts.PendingChanges()),
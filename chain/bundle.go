@@ -0,0 +1,286 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/emap"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+	"github.com/ava-labs/hypersdk/utils"
+)
+
+// MaxBundleTxs is the maximum number of transactions in a [Bundle].
+const MaxBundleTxs = 16
+
+var _ emap.Item = (*Bundle)(nil)
+
+// Bundle is an ordered list of transactions (possibly paid for by different
+// sponsors) that must be included in the same block, consecutively and in
+// order, or not at all.
+//
+// Bundles are builder policy: a block is just a list of transactions, so
+// verifiers never see bundles. A builder only includes a bundle if all of its
+// transactions execute successfully (and fit in the block). Bundles are
+// admitted to the mempool and gossiped as a unit, and a bundle expires when
+// any of its transactions does.
+type Bundle struct {
+	Txs []*Transaction
+
+	id     ids.ID
+	size   int
+	expiry int64
+}
+
+// NewBundle creates a [Bundle] of [txs] (which must be distinct and not in
+// another bundle).
+func NewBundle(txs []*Transaction) (*Bundle, error) {
+	if len(txs) == 0 || len(txs) > MaxBundleTxs {
+		return nil, fmt.Errorf("%w: %d txs (max=%d)", ErrInvalidBundle, len(txs), MaxBundleTxs)
+	}
+	var (
+		seen   = set.NewSet[ids.ID](len(txs))
+		digest = make([]byte, 0, len(txs)*ids.IDLen)
+		b      = &Bundle{Txs: txs, size: consts.ByteLen, expiry: txs[0].Expiry()}
+	)
+	for _, tx := range txs {
+		if tx.bundle != nil {
+			return nil, fmt.Errorf("%w: tx %s is already bundled", ErrInvalidBundle, tx.ID())
+		}
+		txID := tx.ID()
+		if seen.Contains(txID) {
+			return nil, fmt.Errorf("%w: tx %s", ErrDuplicateTx, txID)
+		}
+		seen.Add(txID)
+		digest = append(digest, txID[:]...)
+		b.size += tx.Size()
+		b.expiry = min(b.expiry, tx.Expiry())
+	}
+	b.id = utils.ToID(digest)
+	for _, tx := range txs {
+		tx.bundle = b
+	}
+	return b, nil
+}
+
+// ID is derived from the IDs of the transactions in the bundle (in order).
+func (b *Bundle) ID() ids.ID { return b.id }
+
+// Expiry is the earliest expiry of the transactions in the bundle.
+func (b *Bundle) Expiry() int64 { return b.expiry }
+
+func (b *Bundle) Size() int { return b.size }
+
+// Bundle returns the [Bundle] the transaction was submitted in (or nil if it
+// wasn't).
+func (t *Transaction) Bundle() *Bundle { return t.bundle }
+
+func (b *Bundle) Marshal(p *codec.Packer) error {
+	p.PackByte(uint8(len(b.Txs)))
+	for _, tx := range b.Txs {
+		if err := tx.Marshal(p); err != nil {
+			return err
+		}
+	}
+	return p.Err()
+}
+
+func UnmarshalBundle(
+	p *codec.Packer,
+	actionRegistry ActionRegistry,
+	authRegistry AuthRegistry,
+) (*Bundle, error) {
+	count := int(p.UnpackByte())
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if count == 0 || count > MaxBundleTxs {
+		return nil, fmt.Errorf("%w: %d txs (max=%d)", ErrInvalidBundle, count, MaxBundleTxs)
+	}
+	txs := make([]*Transaction, 0, count)
+	for i := 0; i < count; i++ {
+		tx, err := UnmarshalTx(p, actionRegistry, authRegistry)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return NewBundle(txs)
+}
+
+func MarshalBundles(bundles []*Bundle) ([]byte, error) {
+	if len(bundles) == 0 {
+		return nil, ErrNoTxs
+	}
+	size := consts.IntLen + codec.CummSize(bundles)
+	p := codec.NewWriter(size, consts.NetworkSizeLimit)
+	p.PackInt(len(bundles))
+	for _, bundle := range bundles {
+		if err := bundle.Marshal(p); err != nil {
+			return nil, err
+		}
+	}
+	return p.Bytes(), p.Err()
+}
+
+func UnmarshalBundles(
+	raw []byte,
+	initialCapacity int,
+	actionRegistry ActionRegistry,
+	authRegistry AuthRegistry,
+) ([]*Bundle, error) {
+	p := codec.GetReader(raw, consts.NetworkSizeLimit)
+	defer codec.PutReader(p)
+
+	bundleCount := p.UnpackInt(true)
+	bundles := make([]*Bundle, 0, min(bundleCount, initialCapacity)) // DoS to set size to bundleCount
+	for i := 0; i < bundleCount; i++ {
+		bundle, err := UnmarshalBundle(p, actionRegistry, authRegistry)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+	if !p.Empty() {
+		// Ensure no leftover bytes
+		return nil, ErrInvalidObject
+	}
+	return bundles, p.Err()
+}
+
+// bundleEnd returns the index after the last transaction of the bundle that
+// starts at txs[i] and whether all of its transactions are in [txs]
+// (consecutively and in order).
+func bundleEnd(txs []*Transaction, i int) (int, bool) {
+	bundle := txs[i].bundle
+	end := i + 1
+	for end < len(txs) && txs[end].bundle == bundle {
+		end++
+	}
+	if end-i != len(bundle.Txs) {
+		return end, false
+	}
+	for j, tx := range bundle.Txs {
+		if txs[i+j] != tx {
+			return end, false
+		}
+	}
+	return end, true
+}
+
+// simulateBundle executes [txs] (the transactions of a bundle) in order on a
+// copy of the values of [stateKeys] in [ts] (falling back to [storage]) and
+// returns their results, without modifying [ts].
+//
+// If any transaction can't be executed or does not succeed, the bundle can't
+// be included and an error is returned. If the error was returned by
+// [Transaction.PreExecute], restore is true if the bundle may be included in
+// a later block (see [HandlePreExecute]).
+func simulateBundle(
+	ctx context.Context,
+	log logging.Logger,
+	ts *tstate.TState,
+	storage map[string][]byte,
+	txs []*Transaction,
+	stateKeys []state.Keys,
+	commutative [][]string,
+	feeManager *fees.Manager,
+	sm StateManager,
+	r Rules,
+	timestamp int64,
+) ([]*Result, bool, error) {
+	// Read the current values of all keys accessed by the bundle
+	scope := make(state.Keys)
+	for _, keys := range stateKeys {
+		for k, permissions := range keys {
+			scope.Add(k, permissions)
+		}
+	}
+	var (
+		view   = ts.NewView(scope, storage)
+		values = make(map[string][]byte, len(scope))
+	)
+	for k := range scope {
+		v, err := view.GetValue(ctx, []byte(k))
+		if errors.Is(err, database.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		values[k] = v
+	}
+
+	// Execute each transaction on the changes of the transactions before it
+	var (
+		sim     = tstate.New(len(scope))
+		results = make([]*Result, len(txs))
+	)
+	for i, tx := range txs {
+		tsv := sim.NewView(stateKeys[i], values)
+		tsv.SetAccumulators(commutative[i], false)
+		if err := tx.PreExecute(ctx, feeManager, sm, r, tsv, timestamp); err != nil {
+			return nil, HandlePreExecute(log, err), err
+		}
+		result, err := tx.Execute(ctx, feeManager, sm, r, tsv, timestamp)
+		if err != nil {
+			return nil, false, err
+		}
+		if !result.Success {
+			return nil, false, fmt.Errorf("%w: tx %s: %s", ErrBundleTxFailed, tx.ID(), result.Error)
+		}
+		tsv.Commit()
+		results[i] = result
+	}
+	return results, false, nil
+}
+
+// applyBundle executes [txs] (the transactions of a bundle that were
+// simulated by [simulateBundle]) in order on [ts] and returns their results.
+//
+// Because [ts] is not modified between simulating and applying a bundle (the
+// bundle is executed by a single job), execution should never differ. If it
+// does, an error is returned and some of [txs] may have been applied to [ts].
+func applyBundle(
+	ctx context.Context,
+	ts *tstate.TState,
+	storage map[string][]byte,
+	txs []*Transaction,
+	stateKeys []state.Keys,
+	commutative [][]string,
+	feeManager *fees.Manager,
+	sm StateManager,
+	r Rules,
+	timestamp int64,
+) ([]*Result, error) {
+	results := make([]*Result, len(txs))
+	for i, tx := range txs {
+		tsv := ts.NewView(stateKeys[i], storage)
+		tsv.SetAccumulators(commutative[i], false)
+		if err := tx.PreExecute(ctx, feeManager, sm, r, tsv, timestamp); err != nil {
+			return nil, err
+		}
+		result, err := tx.Execute(ctx, feeManager, sm, r, tsv, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Success {
+			return nil, fmt.Errorf("%w: tx %s: %s", ErrBundleTxFailed, tx.ID(), result.Error)
+		}
+		tsv.Commit()
+		results[i] = result
+	}
+	return results, nil
+}
@@ -0,0 +1,164 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"maps"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// newBundleTestTxs returns a transfer for each idempotency key in [keys]
+// (each signed by a different funded actor in [s]).
+func newBundleTestTxs(require *require.Assertions, chainID ids.ID, s taskTestState, keys ...string) []*Transaction {
+	actionRegistry, authRegistry := (&parallelTestConfig{}).Registry()
+	recipient := codec.CreateAddress(0, ids.GenerateTestID())
+	txs := make([]*Transaction, len(keys))
+	for i, key := range keys {
+		factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		s[string(refundTestBalanceKey(factory.actor))] = binary.BigEndian.AppendUint64(nil, parallelTestBalance)
+		action := &idempotentTestAction{parallelTestAction: parallelTestAction{to: recipient, amount: 1}, key: []byte(key)}
+		tx, err := NewTx(&Base{Timestamp: 1_000 * int64(i+1), ChainID: chainID, MaxFee: 1_000_000}, []Action{action}).Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		txs[i] = tx
+	}
+	return txs
+}
+
+func TestBundleMarshal(t *testing.T) {
+	require := require.New(t)
+	chainID := ids.GenerateTestID()
+	actionRegistry, authRegistry := (&parallelTestConfig{}).Registry()
+	txs := newBundleTestTxs(require, chainID, make(taskTestState), "a", "b", "c")
+
+	// Bundles must contain distinct (unbundled) txs
+	_, err := NewBundle(nil)
+	require.ErrorIs(err, ErrInvalidBundle)
+	_, err = NewBundle([]*Transaction{txs[0], txs[0]})
+	require.ErrorIs(err, ErrDuplicateTx)
+	bundle, err := NewBundle(txs[:2])
+	require.NoError(err)
+	require.Equal(bundle, txs[0].Bundle())
+	require.Equal(int64(1_000), bundle.Expiry())
+	_, err = NewBundle(txs[1:])
+	require.ErrorIs(err, ErrInvalidBundle)
+	require.Nil(txs[2].Bundle())
+
+	// Bundles are parsed in order
+	other, err := NewBundle(txs[2:])
+	require.NoError(err)
+	raw, err := MarshalBundles([]*Bundle{bundle, other})
+	require.NoError(err)
+	parsed, err := UnmarshalBundles(raw, 16, actionRegistry, authRegistry)
+	require.NoError(err)
+	require.Len(parsed, 2)
+	require.Equal(bundle.ID(), parsed[0].ID())
+	require.Equal(txs[1].ID(), parsed[0].Txs[1].ID())
+	require.Equal(bundle.Size(), parsed[0].Size())
+	require.Equal(other.ID(), parsed[1].ID())
+	require.Equal(parsed[1], parsed[1].Txs[0].Bundle())
+}
+
+func TestBundleEnd(t *testing.T) {
+	require := require.New(t)
+	chainID := ids.GenerateTestID()
+	txs := newBundleTestTxs(require, chainID, make(taskTestState), "a", "b", "c")
+	_, err := NewBundle(txs[:2])
+	require.NoError(err)
+
+	end, ok := bundleEnd(txs, 0)
+	require.True(ok)
+	require.Equal(2, end)
+
+	// Incomplete (or reordered) bundles are detected
+	end, ok = bundleEnd(txs[1:], 0)
+	require.False(ok)
+	require.Equal(1, end)
+	end, ok = bundleEnd([]*Transaction{txs[1], txs[0]}, 0)
+	require.False(ok)
+	require.Equal(2, end)
+}
+
+func TestBundleExecution(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	c := &parallelTestConfig{cores: 4}
+	r := &idempotencyTestRules{&parallelTestRules{newOfflineTestRules(ctrl, chainID), true}, true}
+	sm := &refundTestStateManager{}
+	feeManager := fees.NewManager(nil)
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		feeManager.SetUnitPrice(i, 1)
+	}
+
+	// The second bundle fails because its last tx repeats an operation
+	// applied by the first bundle
+	s := make(taskTestState)
+	first := newBundleTestTxs(require, chainID, s, "invoice-1", "invoice-2")
+	second := newBundleTestTxs(require, chainID, s, "invoice-3", "invoice-1")
+	prepare := func(txs []*Transaction) ([]state.Keys, [][]string, map[string][]byte) {
+		var (
+			stateKeys   = make([]state.Keys, len(txs))
+			commutative = make([][]string, len(txs))
+			storage     = map[string][]byte{}
+		)
+		for i, tx := range txs {
+			var err error
+			stateKeys[i], err = tx.StateKeys(sm, r)
+			require.NoError(err)
+			commutative[i], err = tx.CommutativeKeys(sm, r)
+			require.NoError(err)
+			for k := range stateKeys[i] {
+				if v, ok := s[k]; ok {
+					storage[k] = v
+				}
+			}
+		}
+		return stateKeys, commutative, storage
+	}
+
+	// Simulating a bundle doesn't modify state
+	ts := tstate.New(0)
+	stateKeys, commutative, storage := prepare(first)
+	simulated, _, err := simulateBundle(context.TODO(), logging.NoLog{}, ts, storage, first, stateKeys, commutative, feeManager, sm, r, 1_000)
+	require.NoError(err)
+	require.Zero(ts.PendingChanges())
+
+	// Applying a bundle produces the same results and state as executing its
+	// txs in a block
+	applied, err := applyBundle(context.TODO(), ts, storage, first, stateKeys, commutative, feeManager, sm, r, 1_000)
+	require.NoError(err)
+	require.Equal(simulated, applied)
+	results, expected := executeParallelTestBlock(require, c, r, s, first)
+	require.Equal(results, applied)
+	post := maps.Clone(s)
+	post.apply(ts)
+	require.Equal(expected, post)
+
+	// A bundle is skipped if any of its txs fails (on top of the changes
+	// applied before it)
+	changes := ts.PendingChanges()
+	stateKeys, commutative, storage = prepare(second)
+	_, retry, err := simulateBundle(context.TODO(), logging.NoLog{}, ts, storage, second, stateKeys, commutative, feeManager, sm, r, 1_000)
+	require.ErrorIs(err, ErrBundleTxFailed)
+	require.ErrorContains(err, ErrDuplicateIdempotencyKey.Error())
+	require.False(retry)
+	require.Equal(changes, ts.PendingChanges())
+
+	// The failing tx would be included without the bundle
+	results, _ = executeParallelTestBlock(require, c, r, s, second)
+	require.True(results[0].Success)
+	require.True(results[1].Success)
+}
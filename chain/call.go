@@ -0,0 +1,97 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// MaxCallDepth is the maximum number of nested [CallAction]s. Because an
+// [Action] can only call other actions by value, this also bounds any cycle
+// of calls.
+const MaxCallDepth = 4
+
+type callDepthKey struct{}
+
+// callDepth returns the number of [CallAction]s [ctx] is nested in.
+func callDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(callDepthKey{}).(int)
+	return depth
+}
+
+// CallAction executes [callee] from within the [Action.Execute] of a caller,
+// against the same [state.Mutable] and as [actor] (which does not need to be
+// the actor of the caller, allowing the caller to act on behalf of accounts it
+// controls).
+//
+// Every key returned by the [Action.StateKeys] of [callee] must be included
+// in [declared] (the [Action.StateKeys] of the caller) with at least the same
+// permissions. Callers should use [CallStateKeys], [CallStateKeysMaxChunks],
+// and [CallComputeUnits] to declare the keys and units of [callee] as their
+// own.
+//
+// The outputs of [callee] are returned to the caller instead of being
+// included in the [Result] of the transaction. If [callee] is a
+// [MeteredAction], the units it leaves unconsumed are not refunded.
+func CallAction(
+	ctx context.Context,
+	r Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+	declared state.Keys,
+	callee Action,
+) ([][]byte, error) {
+	depth := callDepth(ctx)
+	if depth >= MaxCallDepth {
+		return nil, fmt.Errorf("%w: depth=%d", ErrCallDepthExceeded, depth+1)
+	}
+	typeID := callee.GetTypeID()
+	if r.IsActionRestricted(typeID) {
+		// Access is only verified for the actions included in a
+		// transaction, so restricted actions can't be called
+		return nil, fmt.Errorf("%w: action type %d called internally", ErrActionNotPermitted, typeID)
+	}
+	for k, p := range callee.StateKeys(actor, actionID) {
+		if !declared[k].Has(p) {
+			return nil, fmt.Errorf("%w: action type %d requires key %x", ErrUndeclaredCallKey, typeID, k)
+		}
+	}
+	outputs, _, _, err := execute(context.WithValue(ctx, callDepthKey{}, depth+1), callee, r, mu, timestamp, actor, actionID)
+	return outputs, err
+}
+
+// CallStateKeys adds the [Action.StateKeys] of [callee] (executed as [actor])
+// to [keys].
+func CallStateKeys(keys state.Keys, callee Action, actor codec.Address, actionID ids.ID) state.Keys {
+	for k, p := range callee.StateKeys(actor, actionID) {
+		keys.Add(k, p)
+	}
+	return keys
+}
+
+// CallStateKeysMaxChunks returns [chunks] followed by the
+// [Action.StateKeysMaxChunks] of [callees].
+func CallStateKeysMaxChunks(chunks []uint16, callees ...Action) []uint16 {
+	for _, callee := range callees {
+		chunks = append(chunks, callee.StateKeysMaxChunks()...)
+	}
+	return chunks
+}
+
+// CallComputeUnits returns [units] plus the [Action.ComputeUnits] of
+// [callees].
+func CallComputeUnits(r Rules, units uint64, callees ...Action) uint64 {
+	for _, callee := range callees {
+		units += callee.ComputeUnits(r)
+	}
+	return units
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// callTestAction writes its call depth to [key] and then calls [callee] (if
+// any), declaring the keys of [callee] unless [undeclared] is set.
+type callTestAction struct {
+	key        []byte
+	callee     Action
+	undeclared bool
+}
+
+func (*callTestAction) GetTypeID() uint8                { return 0 }
+func (*callTestAction) ValidRange(Rules) (int64, int64) { return -1, -1 }
+func (*callTestAction) Size() int                       { return 0 }
+func (*callTestAction) Marshal(*codec.Packer)           {}
+func (a *callTestAction) ComputeUnits(r Rules) uint64 {
+	if a.callee == nil {
+		return 1
+	}
+	return CallComputeUnits(r, 1, a.callee)
+}
+
+func (a *callTestAction) StateKeysMaxChunks() []uint16 {
+	if a.callee == nil {
+		return []uint16{1}
+	}
+	return CallStateKeysMaxChunks([]uint16{1}, a.callee)
+}
+
+func (a *callTestAction) StateKeys(actor codec.Address, actionID ids.ID) state.Keys {
+	stateKeys := state.Keys{string(a.key): state.Allocate | state.Write}
+	if a.callee == nil || a.undeclared {
+		return stateKeys
+	}
+	return CallStateKeys(stateKeys, a.callee, actor, actionID)
+}
+
+func (a *callTestAction) Execute(
+	ctx context.Context,
+	r Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, error) {
+	depth := byte(callDepth(ctx))
+	if err := mu.Insert(ctx, a.key, []byte{depth}); err != nil {
+		return nil, err
+	}
+	if a.callee == nil {
+		return [][]byte{{depth}}, nil
+	}
+	return CallAction(ctx, r, mu, timestamp, actor, actionID, a.StateKeys(actor, actionID), a.callee)
+}
+
+// newCallTestAction returns a [callTestAction] that makes [calls] nested
+// calls (each to a different key).
+func newCallTestAction(calls int) *callTestAction {
+	var callee Action
+	for i := calls; i > 0; i-- {
+		callee = &callTestAction{key: keys.EncodeChunks([]byte{0x9, byte(i)}, 1), callee: callee}
+	}
+	return &callTestAction{key: keys.EncodeChunks([]byte{0x9, 0}, 1), callee: callee}
+}
+
+func TestCallAction(t *testing.T) {
+	var (
+		actor    = codec.CreateAddress(0, ids.GenerateTestID())
+		actionID = ids.GenerateTestID()
+	)
+	tests := []struct {
+		name       string
+		action     *callTestAction
+		restricted bool
+		err        error
+	}{
+		{
+			name:   "no calls",
+			action: newCallTestAction(0),
+		},
+		{
+			name:   "single call",
+			action: newCallTestAction(1),
+		},
+		{
+			name:   "max depth",
+			action: newCallTestAction(MaxCallDepth),
+		},
+		{
+			name:   "depth exceeded",
+			action: newCallTestAction(MaxCallDepth + 1),
+			err:    ErrCallDepthExceeded,
+		},
+		{
+			name: "undeclared key",
+			action: &callTestAction{
+				key:        keys.EncodeChunks([]byte{0x9, 0xff}, 1),
+				callee:     newCallTestAction(0),
+				undeclared: true,
+			},
+			err: ErrUndeclaredCallKey,
+		},
+		{
+			name:       "restricted callee",
+			action:     newCallTestAction(1),
+			restricted: true,
+			err:        ErrActionNotPermitted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+
+			r := NewMockRules(gomock.NewController(t))
+			r.EXPECT().IsActionRestricted(gomock.Any()).Return(tt.restricted).AnyTimes()
+			stateKeys := tt.action.StateKeys(actor, actionID)
+			tsv := tstate.New(len(stateKeys)).NewView(stateKeys, map[string][]byte{})
+			outputs, err := tt.action.Execute(ctx, r, tsv, 0, actor, actionID)
+			require.ErrorIs(err, tt.err)
+			if tt.err != nil {
+				return
+			}
+
+			// The outputs of the innermost callee are returned to the
+			// caller and each callee writes to the same view
+			calls := 0
+			for a := Action(tt.action); a != nil; a = a.(*callTestAction).callee {
+				v, err := tsv.GetValue(ctx, a.(*callTestAction).key)
+				require.NoError(err)
+				require.Equal([]byte{byte(calls)}, v)
+				calls++
+			}
+			require.Equal([][]byte{{byte(calls - 1)}}, outputs)
+			require.Len(stateKeys, calls)
+			require.Len(tt.action.StateKeysMaxChunks(), calls)
+			require.Equal(uint64(calls), tt.action.ComputeUnits(r))
+		})
+	}
+}
+
+func TestCallActionInsufficientPermissions(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	r := NewMockRules(gomock.NewController(t))
+	r.EXPECT().IsActionRestricted(gomock.Any()).Return(false).AnyTimes()
+	callee := newCallTestAction(0)
+	declared := state.Keys{string(callee.key): state.Read}
+	tsv := tstate.New(1).NewView(declared, map[string][]byte{})
+	_, err := CallAction(ctx, r, tsv, 0, codec.EmptyAddress, ids.Empty, declared, callee)
+	require.ErrorIs(err, ErrUndeclaredCallKey)
+
+	// The view is not modified
+	_, err = tsv.GetValue(ctx, callee.key)
+	require.Error(err)
+}
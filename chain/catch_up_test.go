@@ -0,0 +1,190 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// newCatchUpTestBlocks returns [count] sequential blocks on top of [db]
+// (whose root is [root]) and the post-execution root of each block, computed
+// by verifying each block on a view of its parent.
+func newCatchUpTestBlocks(
+	ctx context.Context,
+	require *require.Assertions,
+	r Rules,
+	db merkledb.MerkleDB,
+	root ids.ID,
+	count int,
+) ([]*StatefulBlock, []ids.ID) {
+	var (
+		b = &adversarialBuilder{
+			chainID: r.ChainID(),
+			factory: &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())},
+		}
+		vm = &offlineTestVM{
+			r:            r,
+			lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+		}
+		parentView state.View = db
+		parentID              = ids.GenerateTestID()
+		blks                  = make([]*StatefulBlock, count)
+		roots                 = make([]ids.ID, count)
+	)
+	for i := range blks {
+		blk := &StatefulBlock{
+			Prnt:      parentID,
+			Tmstmp:    int64(i+1) * 1_000,
+			Hght:      uint64(i + 1),
+			StateRoot: root,
+		}
+		for j := 0; j < 4; j++ {
+			blk.Txs = append(blk.Txs, b.tx(require, int64(i+2)*1_000, b.chainID, byte(j)))
+		}
+		sblk, err := ParseStatefulBlock(ctx, blk, nil, choices.Processing, vm)
+		require.NoError(err)
+		require.NoError(sblk.innerVerify(ctx, &offlineTestVerifyContext{parentView}))
+		root, err = sblk.view.GetMerkleRoot(ctx)
+		require.NoError(err)
+
+		blks[i], roots[i] = blk, root
+		parentView, parentID = sblk.view, sblk.ID()
+	}
+	return blks, roots
+}
+
+// verifyCatchUpBlocks verifies [blks] in sequence on top of [db] like a
+// bootstrapping node would (accepting each block before verifying its child)
+// and returns the root of [db] after each block is accepted.
+func verifyCatchUpBlocks(
+	ctx context.Context,
+	require *require.Assertions,
+	r Rules,
+	db merkledb.MerkleDB,
+	blks []*StatefulBlock,
+	incremental bool,
+) []ids.ID {
+	vm := &offlineTestVM{
+		r:            r,
+		lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+	}
+	if incremental {
+		vm.acceptedState = db
+	}
+	roots := make([]ids.ID, len(blks))
+	for i, blk := range blks {
+		sblk, err := ParseStatefulBlock(ctx, blk, nil, choices.Processing, vm)
+		require.NoError(err)
+		require.NoError(sblk.innerVerify(ctx, &offlineTestVerifyContext{db}))
+		require.Equal(incremental, sblk.committed)
+		require.NoError(vm.CommitState(ctx, sblk, sblk.view))
+		roots[i], err = db.GetMerkleRoot(ctx)
+		require.NoError(err)
+	}
+	return roots
+}
+
+func TestCatchUpIncrementalRoots(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	r := newOfflineTestRules(ctrl, ids.GenerateTestID())
+
+	db, root := newOfflineTestState(ctx, require)
+	blks, roots := newCatchUpTestBlocks(ctx, require, r, db, root, 10)
+
+	// Committing each block during verification produces the same roots as
+	// verifying each block on a view of its parent
+	for _, incremental := range []bool{false, true} {
+		db, _ := newOfflineTestState(ctx, require)
+		require.Equal(roots, verifyCatchUpBlocks(ctx, require, r, db, blks, incremental))
+	}
+
+	// Blocks are not committed if their parent is not accepted...
+	db, _ = newOfflineTestState(ctx, require)
+	vm := &offlineTestVM{
+		r:             r,
+		lastAccepted:  &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+		acceptedState: db,
+	}
+	parent, err := ParseStatefulBlock(ctx, blks[0], nil, choices.Processing, vm)
+	require.NoError(err)
+	parentView, err := db.NewView(ctx, merkledb.ViewChanges{})
+	require.NoError(err)
+	require.NoError(parent.innerVerify(ctx, &offlineTestVerifyContext{parentView}))
+	require.False(parent.committed)
+
+	// ...and committed blocks can't be rejected
+	blk, err := ParseStatefulBlock(ctx, blks[0], nil, choices.Processing, vm)
+	require.NoError(err)
+	require.NoError(blk.innerVerify(ctx, &offlineTestVerifyContext{db}))
+	require.True(blk.committed)
+	require.ErrorIs(blk.Reject(ctx), ErrRejectedCommittedBlock)
+	dbRoot, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(roots[0], dbRoot)
+}
+
+func TestVerifyCommittedHeight(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	r := newOfflineTestRules(ctrl, ids.GenerateTestID())
+
+	db, root := newOfflineTestState(ctx, require)
+	blks, _ := newCatchUpTestBlocks(ctx, require, r, db, root, 2)
+
+	// The node stops after committing [blks[1]] but before it is recorded as
+	// accepted
+	db, _ = newOfflineTestState(ctx, require)
+	verifyCatchUpBlocks(ctx, require, r, db, blks[:2], true)
+	vm := &offlineTestVM{r: r, acceptedState: db}
+	lastAccepted, err := ParseStatefulBlock(ctx, blks[0], nil, choices.Accepted, vm)
+	require.NoError(err)
+	vm.lastAccepted = lastAccepted
+
+	// Blocks at the height of the accepted state are never verified (the
+	// committed block is recorded as accepted by the [VM] on restart instead),
+	// whether or not they match it
+	conflicting := *blks[1]
+	conflicting.Tmstmp++
+	for _, sblk := range []*StatefulBlock{&conflicting, blks[1]} {
+		blk, err := ParseStatefulBlock(ctx, sblk, nil, choices.Processing, vm)
+		require.NoError(err)
+		require.ErrorIs(blk.innerVerify(ctx, &offlineTestVerifyContext{db}), ErrInvalidBlockHeight)
+		require.False(blk.Processed())
+		require.False(blk.committed)
+	}
+}
+
+func BenchmarkCatchUpRoots(b *testing.B) {
+	ctx := context.TODO()
+	for _, incremental := range []bool{false, true} {
+		b.Run(fmt.Sprintf("incremental=%t", incremental), func(b *testing.B) {
+			require := require.New(b)
+			r := newOfflineTestRules(gomock.NewController(b), ids.GenerateTestID())
+			db, root := newOfflineTestState(ctx, require)
+			blks, _ := newCatchUpTestBlocks(ctx, require, r, db, root, 100)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db, _ := newOfflineTestState(ctx, require)
+				b.StartTimer()
+				verifyCatchUpBlocks(ctx, require, r, db, blks, incremental)
+			}
+		})
+	}
+}
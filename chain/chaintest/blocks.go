@@ -0,0 +1,338 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chaintest
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/executor"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+// BlockConfig describes the VM whose block verification is checked by
+// [RunAdversarialBlocks].
+type BlockConfig struct {
+	// Rules must not enable delayed execution, restricted builders, the
+	// results root, or epochs (blocks are built without a builder, results
+	// root, or validator set) and must have a non-zero min block gap.
+	Rules          chain.Rules
+	ActionRegistry chain.ActionRegistry
+	AuthRegistry   chain.AuthRegistry
+	StateManager   chain.StateManager
+
+	// Genesis initializes the state blocks are built on (i.e. funds the
+	// sponsor of the transactions signed by [Factory]).
+	Genesis func(context.Context, state.Mutable) error
+
+	// Factory signs every transaction and Action returns the only action of
+	// the [i]th transaction (actions must differ so transactions do too).
+	Factory chain.AuthFactory
+	Action  func(i int) chain.Action
+}
+
+// adversarialBuilder produces blocks on top of the genesis state of a
+// [BlockConfig] that are valid except for a single targeted defect (like a
+// malicious proposer would).
+type adversarialBuilder struct {
+	c          *BlockConfig
+	parentRoot ids.ID
+	txs        int
+}
+
+// tx returns a signed transaction that expires at [timestamp].
+func (b *adversarialBuilder) tx(require *require.Assertions, timestamp int64, chainID ids.ID) *chain.Transaction {
+	tx := chain.NewTx(
+		&chain.Base{Timestamp: timestamp, ChainID: chainID, MaxFee: math.MaxUint64},
+		[]chain.Action{b.c.Action(b.txs)},
+	)
+	b.txs++
+	tx, err := tx.Sign(b.c.Factory, b.c.ActionRegistry, b.c.AuthRegistry)
+	require.NoError(err)
+	return tx
+}
+
+// block returns a valid child of genesis with 3 transactions that expire at
+// the first second after its timestamp.
+func (b *adversarialBuilder) block(require *require.Assertions) *chain.StatefulBlock {
+	blk := &chain.StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    b.c.Rules.GetMinBlockGap(),
+		Hght:      1,
+		Txs:       []*chain.Transaction{},
+		StateRoot: b.parentRoot,
+	}
+	expiry := b.expiry(blk.Tmstmp)
+	for i := 0; i < 3; i++ {
+		blk.Txs = append(blk.Txs, b.tx(require, expiry, b.c.Rules.ChainID()))
+	}
+	return blk
+}
+
+// expiry returns the first valid transaction timestamp after [timestamp].
+func (*adversarialBuilder) expiry(timestamp int64) int64 {
+	return (timestamp/consts.MillisecondsPerSecond + 1) * consts.MillisecondsPerSecond
+}
+
+// txCountOffset returns the offset of the transaction count in the encoding
+// of [blk] (the first byte that differs once a transaction is added to a
+// block without any).
+func txCountOffset(require *require.Assertions, vm chain.VM, blk *chain.StatefulBlock) int {
+	empty := *blk
+	empty.Txs = nil
+	emptyRaw, err := empty.Marshal(vm)
+	require.NoError(err)
+	one := *blk
+	one.Txs = blk.Txs[:1]
+	oneRaw, err := one.Marshal(vm)
+	require.NoError(err)
+	for i := range emptyRaw {
+		if emptyRaw[i] != oneRaw[i] {
+			return i - (consts.IntLen - 1)
+		}
+	}
+	require.FailNow("transaction count not found")
+	return 0
+}
+
+// RunAdversarialBlocks ensures the VM described by [c] rejects every
+// targeted defect a proposer can introduce into an otherwise valid block.
+// Each block is parsed and verified by a fresh VM (as a node receiving the
+// block from a proposer would).
+//
+// Fees (unit prices, windows, and units consumed) are not included in blocks
+// but are committed to by the state root of the child, so they are covered by
+// the "wrong state root" defect. Any new consensus field added to
+// [chain.StatefulBlock] must add a defect to this table.
+func RunAdversarialBlocks(t *testing.T, c *BlockConfig) {
+	window := c.Rules.GetValidityWindow()
+	tests := []struct {
+		name    string
+		mutate  func(*require.Assertions, *adversarialBuilder, *chain.StatefulBlock)
+		corrupt func(*require.Assertions, chain.VM, *chain.StatefulBlock, []byte) []byte
+		err     error
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "wrong state root",
+			mutate: func(_ *require.Assertions, _ *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.StateRoot = ids.GenerateTestID()
+			},
+			err: chain.ErrStateRootMismatch,
+		},
+		{
+			name: "wrong height",
+			mutate: func(_ *require.Assertions, _ *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.Hght = 2
+			},
+			err: chain.ErrInvalidBlockHeight,
+		},
+		{
+			name: "timestamp in the future",
+			mutate: func(_ *require.Assertions, _ *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.Tmstmp = time.Now().Add(2 * chain.FutureBound).UnixMilli()
+			},
+			err: chain.ErrTimestampTooLate,
+		},
+		{
+			name: "timestamp too close to parent",
+			mutate: func(_ *require.Assertions, _ *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.Tmstmp--
+			},
+			err: chain.ErrTimestampTooEarly,
+		},
+		{
+			name: "duplicated tx",
+			mutate: func(_ *require.Assertions, _ *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.Txs = append(blk.Txs, blk.Txs[0])
+			},
+			err: chain.ErrDuplicateTx,
+		},
+		{
+			name: "txs at the edges of the validity window",
+			mutate: func(require *require.Assertions, b *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.Tmstmp = blk.Txs[0].Base.Timestamp
+				blk.Txs = append(blk.Txs, b.tx(require, blk.Tmstmp+window, b.c.Rules.ChainID()))
+			},
+		},
+		{
+			name: "tx past expiry",
+			mutate: func(_ *require.Assertions, _ *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.Tmstmp = blk.Txs[0].Base.Timestamp + 1
+			},
+			err: chain.ErrTxTimestampOutOfWindow,
+		},
+		{
+			name: "tx past validity window",
+			mutate: func(require *require.Assertions, b *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.Txs = append(blk.Txs, b.tx(require, b.expiry(blk.Tmstmp+window), b.c.Rules.ChainID()))
+			},
+			err: chain.ErrTxTimestampOutOfWindow,
+		},
+		{
+			name: "tx for another chain",
+			mutate: func(require *require.Assertions, b *adversarialBuilder, blk *chain.StatefulBlock) {
+				blk.Txs = append(blk.Txs, b.tx(require, blk.Txs[0].Base.Timestamp, ids.GenerateTestID()))
+			},
+			err: chain.ErrInvalidChainID,
+		},
+		{
+			name: "oversize tx list",
+			corrupt: func(require *require.Assertions, vm chain.VM, blk *chain.StatefulBlock, raw []byte) []byte {
+				binary.BigEndian.PutUint32(raw[txCountOffset(require, vm, blk):], math.MaxInt32)
+				return raw
+			},
+			err: chain.ErrTooManyTxs,
+		},
+		{
+			name: "leftover trailing bytes",
+			corrupt: func(_ *require.Assertions, _ chain.VM, _ *chain.StatefulBlock, raw []byte) []byte {
+				return append(raw, 0)
+			},
+			err: chain.ErrInvalidObject,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+
+			vm, parentRoot := newBlockVM(ctx, require, c)
+			b := &adversarialBuilder{c: c, parentRoot: parentRoot}
+			blk := b.block(require)
+			if tt.mutate != nil {
+				tt.mutate(require, b, blk)
+			}
+			raw, err := blk.Marshal(vm)
+			require.NoError(err)
+			if tt.corrupt != nil {
+				raw = tt.corrupt(require, vm, blk, raw)
+			}
+			sblk, err := chain.ParseBlock(ctx, raw, choices.Processing, vm)
+			if err == nil {
+				err = sblk.Verify(ctx)
+			}
+			require.ErrorIs(err, tt.err)
+		})
+	}
+}
+
+var _ chain.VM = (*blockVM)(nil)
+
+// blockVM implements the subset of [chain.VM] used to parse and verify a
+// child of genesis.
+type blockVM struct {
+	chain.VM
+
+	c            *BlockConfig
+	state        merkledb.MerkleDB
+	lastAccepted *chain.StatelessBlock
+}
+
+// newBlockVM returns a [blockVM] whose accepted state is the genesis state of
+// [c] and the root of that state.
+func newBlockVM(ctx context.Context, require *require.Assertions, c *BlockConfig) (*blockVM, ids.ID) {
+	db, err := merkledb.New(ctx, memdb.New(), merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor16,
+		RootGenConcurrency:          1,
+		HistoryLength:               100,
+		ValueNodeCacheSize:          units.MiB,
+		IntermediateNodeCacheSize:   units.MiB,
+		IntermediateWriteBufferSize: units.KiB,
+		IntermediateWriteBatchSize:  units.KiB,
+		Tracer:                      trace.Noop,
+	})
+	require.NoError(err)
+	sps := state.NewSimpleMutable(db)
+	require.NoError(c.Genesis(ctx, sps))
+	sm := c.StateManager
+	require.NoError(sps.Insert(ctx, chain.HeightKey(sm.HeightKey()), binary.BigEndian.AppendUint64(nil, 0)))
+	require.NoError(sps.Insert(ctx, chain.TimestampKey(sm.TimestampKey()), binary.BigEndian.AppendUint64(nil, 0)))
+	require.NoError(sps.Insert(ctx, chain.FeeKey(sm.FeeKey()), fees.NewManager(nil).Bytes()))
+	require.NoError(sps.Commit(ctx))
+	root, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	vm := &blockVM{c: c, state: db}
+	genesis, err := chain.ParseStatefulBlock(ctx, chain.NewGenesisBlock(root), nil, choices.Accepted, vm)
+	require.NoError(err)
+	vm.lastAccepted = genesis
+	return vm, root
+}
+
+func (*blockVM) Logger() logging.Logger                          { return logging.NoLog{} }
+func (*blockVM) VerifyLogger() logging.Logger                    { return logging.NoLog{} }
+func (*blockVM) Tracer() trace.Tracer                            { return trace.Noop }
+func (vm *blockVM) Rules(int64) chain.Rules                      { return vm.c.Rules }
+func (vm *blockVM) StateManager() chain.StateManager             { return vm.c.StateManager }
+func (*blockVM) AuthVerifiers() workers.Workers                  { return workers.NewSerial() }
+func (*blockVM) GetVerifyAuth() bool                             { return true }
+func (*blockVM) StateReady() bool                                { return true }
+func (*blockVM) CatchUpIncrementalRoots() bool                   { return false }
+func (vm *blockVM) LastAcceptedBlock() *chain.StatelessBlock     { return vm.lastAccepted }
+func (*blockVM) ShadowRootComputer() chain.RootComputer          { return nil }
+func (*blockVM) BlockProfiler() chain.BlockProfiler              { return nil }
+func (*blockVM) StatePrefixes() *chain.StatePrefixRegistry       { return nil }
+func (*blockVM) GetTransactionExecutionCores() int               { return 1 }
+func (*blockVM) GetStateFetchConcurrency() int                   { return 1 }
+func (*blockVM) GetExecutorVerifyRecorder() executor.Metrics     { return nil }
+func (*blockVM) GetKeyAccessRecorder() chain.KeyAccessRecorder   { return nil }
+func (*blockVM) RecordBlockVerify(time.Duration)                 {}
+func (*blockVM) RecordWaitRoot(time.Duration)                    {}
+func (*blockVM) RecordWaitSignatures(time.Duration)              {}
+func (*blockVM) RecordRootCalculated(time.Duration)              {}
+func (*blockVM) RecordStateChanges(int)                          {}
+func (*blockVM) RecordStateOperations(int)                       {}
+func (*blockVM) RecordActionPanic()                              {}
+func (*blockVM) Now() time.Time                                  { return time.Now() }
+func (*blockVM) BeginVerify() func()                             { return func() {} }
+func (*blockVM) Verified(context.Context, *chain.StatelessBlock) {}
+func (*blockVM) GetAuthBatchVerifier(uint8, int, int) (chain.AuthBatchVerifier, bool) {
+	return nil, false
+}
+
+func (vm *blockVM) Registry() (chain.ActionRegistry, chain.AuthRegistry) {
+	return vm.c.ActionRegistry, vm.c.AuthRegistry
+}
+
+func (vm *blockVM) GetExecutionContext(_ ids.ID, parentFees []byte, parentTimestamp int64, timestamp int64) (*chain.ExecutionContext, error) {
+	return chain.GenerateExecutionContext(parentFees, parentTimestamp, timestamp, vm.c.Rules)
+}
+
+func (vm *blockVM) GetVerifyContext(context.Context, uint64, ids.ID) (chain.VerifyContext, error) {
+	return &blockVerifyContext{vm.state}, nil
+}
+
+// blockVerifyContext verifies a child of genesis (which has no ancestors to
+// check for repeats).
+type blockVerifyContext struct {
+	view state.View
+}
+
+func (c *blockVerifyContext) View(context.Context, bool) (state.View, error) {
+	return c.view, nil
+}
+
+func (*blockVerifyContext) IsRepeat(_ context.Context, _ int64, _ []*chain.Transaction, marker set.Bits, _ bool) (set.Bits, error) {
+	return marker, nil
+}
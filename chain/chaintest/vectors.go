@@ -0,0 +1,136 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package chaintest provides helpers for testing the actions and blocks of a
+// VM built on the [chain] package.
+package chaintest
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// RunActionVectors checks every action registered in [actions] against its
+// [chain.ActionVectors] in [vectors]:
+//
+//   - Codec vectors must marshal to their bytes (and back)
+//   - Invalid vectors must fail to unmarshal with their error
+//   - Execute vectors must produce their outputs (or error) and post-state
+//     when executed against in-memory state (with [rules], which may be nil
+//     if no action reads them)
+//
+// Actions registered without any vectors fail the test unless their type ID
+// is [pending] (so new actions can't be added without vectors by accident).
+func RunActionVectors(
+	t *testing.T,
+	actions *codec.TypeParser[chain.Action, bool],
+	vectors *chain.ActionVectorRegistry,
+	rules chain.Rules,
+	pending ...uint8,
+) {
+	for _, id := range actions.Indices() {
+		unmarshal, _ := actions.LookupIndex(id)
+		v, ok := vectors.Get(id)
+		hasVectors := ok && len(v.Codec)+len(v.Invalid)+len(v.Execute) > 0
+		switch {
+		case slices.Contains(pending, id):
+			if hasVectors {
+				t.Errorf("action %d has vectors but is pending", id)
+			}
+			continue
+		case !hasVectors:
+			t.Errorf("action %d has no vectors", id)
+			continue
+		}
+
+		for _, cv := range v.Codec {
+			t.Run(cv.Name, func(t *testing.T) {
+				runCodecVector(t, id, unmarshal, cv)
+			})
+		}
+		for _, iv := range v.Invalid {
+			t.Run(iv.Name, func(t *testing.T) {
+				require := require.New(t)
+
+				_, err := unmarshal(codec.NewReader(iv.Bytes, len(iv.Bytes)))
+				require.ErrorIs(err, iv.Err)
+			})
+		}
+		for _, ev := range v.Execute {
+			t.Run(ev.Name, func(t *testing.T) {
+				runExecuteVector(t, id, rules, ev)
+			})
+		}
+	}
+}
+
+func runCodecVector(
+	t *testing.T,
+	id uint8,
+	unmarshal func(*codec.Packer) (chain.Action, error),
+	v chain.ActionCodecVector,
+) {
+	require := require.New(t)
+	require.Equal(id, v.Action.GetTypeID())
+
+	// Marshal
+	require.Equal(len(v.Bytes), v.Action.Size())
+	p := codec.NewWriter(v.Action.Size(), consts.NetworkSizeLimit)
+	v.Action.Marshal(p)
+	require.NoError(p.Err())
+	require.Equal(v.Bytes, p.Bytes())
+
+	// Unmarshal
+	p = codec.NewReader(v.Bytes, len(v.Bytes))
+	action, err := unmarshal(p)
+	require.NoError(err)
+	require.True(p.Empty())
+	require.Equal(v.Action, action)
+}
+
+func runExecuteVector(
+	t *testing.T,
+	id uint8,
+	rules chain.Rules,
+	v chain.ActionExecuteVector,
+) {
+	require := require.New(t)
+	require.Equal(id, v.Action.GetTypeID())
+
+	ts := tstate.New(0)
+	tsv := ts.NewView(v.Action.StateKeys(v.Actor, ids.Empty), v.PreState)
+	outputs, err := v.Action.Execute(context.TODO(), rules, tsv, v.Timestamp, v.Actor, ids.Empty)
+	require.ErrorIs(err, v.Err)
+	if v.Err != nil {
+		return
+	}
+	require.Equal(v.Outputs, outputs)
+
+	tsv.Commit()
+	post := maps.Clone(v.PreState)
+	if post == nil {
+		post = map[string][]byte{}
+	}
+	for k, change := range ts.ChangedKeys() {
+		if change.IsNothing() {
+			delete(post, k)
+			continue
+		}
+		post[k] = change.Value()
+	}
+	if len(v.PostState) == 0 {
+		require.Empty(post)
+		return
+	}
+	require.Equal(v.PostState, post)
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+type accumulator interface {
+	Accumulate(ctx context.Context, key []byte, delta uint64) error
+}
+
+// Accumulate adds [delta] to the accumulator at [key] (a big-endian uint64
+// that is created if it doesn't exist). It is the only way a
+// [CommutativeAction] may modify its commutative keys.
+//
+// Accumulate fails with [tstate.ErrInvalidAccumulator] if [key] holds a value
+// that isn't 8 bytes and with [tstate.ErrAccumulatorOverflow] if the result
+// would overflow. These failures are identical whether or not the transaction
+// is executed concurrently with others that accumulate to [key].
+func Accumulate(ctx context.Context, mu state.Mutable, key []byte, delta uint64) error {
+	if a, ok := mu.(accumulator); ok {
+		return a.Accumulate(ctx, key, delta)
+	}
+	if delta == 0 {
+		return nil
+	}
+	v, err := mu.GetValue(ctx, key)
+	var current uint64
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+	case err != nil:
+		return err
+	case len(v) != consts.Uint64Len:
+		return tstate.ErrInvalidAccumulator
+	default:
+		current = binary.BigEndian.Uint64(v)
+	}
+	if delta > math.MaxUint64-current {
+		return tstate.ErrAccumulatorOverflow
+	}
+	return mu.Insert(ctx, key, binary.BigEndian.AppendUint64(nil, current+delta))
+}
+
+// CommutativeKeys returns the keys that the actions of [t] only modify with
+// [Accumulate] (see [CommutativeAction.Commutative]), in sorted order.
+func (t *Transaction) CommutativeKeys(sm StateManager, r Rules) ([]string, error) {
+	var (
+		actor       = t.Auth.Actor()
+		commutative = set.Set[string]{}
+		declared    = make([]set.Set[string], len(t.Actions))
+	)
+	for i, action := range t.Actions {
+		if c, ok := action.(CommutativeAction); ok {
+			declared[i] = set.Of(c.Commutative(actor, CreateActionID(t.ID(), uint8(i)))...)
+			commutative.Union(declared[i])
+		}
+	}
+	if commutative.Len() == 0 {
+		return nil, nil
+	}
+
+	// Keys declared by other actions, the sponsor, the memo, or the ACL of
+	// [t] (or without the required permissions) are not commutative
+	stateKeys, err := t.StateKeys(sm, r)
+	if err != nil {
+		return nil, err
+	}
+	for k := range commutative {
+		if !stateKeys[k].Has(state.Allocate | state.Write) {
+			commutative.Remove(k)
+		}
+	}
+	for i, action := range t.Actions {
+		for k := range action.StateKeys(actor, CreateActionID(t.ID(), uint8(i))) {
+			if !declared[i].Contains(k) {
+				commutative.Remove(k)
+			}
+		}
+	}
+	for k := range sm.SponsorStateKeys(t.Auth.Sponsor()) {
+		commutative.Remove(k)
+	}
+	if len(t.Memo) > 0 {
+		commutative.Remove(string(t.memoKey(sm)))
+	}
+	commutative.Remove(t.aclKeys(sm, r)...)
+	if commutative.Len() == 0 {
+		return nil, nil
+	}
+	keys := commutative.List()
+	slices.Sort(keys)
+	return keys, nil
+}
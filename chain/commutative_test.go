@@ -0,0 +1,239 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// commutativeTestAction credits [to] with [amount] using [Accumulate] or,
+// if [read] is set, reads the balance of [to] (which it isn't allowed to).
+type commutativeTestAction struct {
+	to     codec.Address
+	amount uint64
+	read   bool
+}
+
+func (*commutativeTestAction) GetTypeID() uint8                { return 2 }
+func (*commutativeTestAction) ValidRange(Rules) (int64, int64) { return -1, -1 }
+func (*commutativeTestAction) Size() int                       { return codec.AddressLen + consts.Uint64Len + consts.BoolLen }
+func (*commutativeTestAction) ComputeUnits(Rules) uint64       { return 1 }
+func (*commutativeTestAction) StateKeysMaxChunks() []uint16    { return []uint16{1} }
+
+func (a *commutativeTestAction) Marshal(p *codec.Packer) {
+	p.PackAddress(a.to)
+	p.PackUint64(a.amount)
+	p.PackBool(a.read)
+}
+
+func (a *commutativeTestAction) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{string(refundTestBalanceKey(a.to)): state.All}
+}
+
+func (a *commutativeTestAction) Commutative(codec.Address, ids.ID) []string {
+	return []string{string(refundTestBalanceKey(a.to))}
+}
+
+func (a *commutativeTestAction) Execute(ctx context.Context, _ Rules, mu state.Mutable, _ int64, _ codec.Address, _ ids.ID) ([][]byte, error) {
+	if a.read {
+		_, err := mu.GetValue(ctx, refundTestBalanceKey(a.to))
+		return nil, err
+	}
+	return nil, Accumulate(ctx, mu, refundTestBalanceKey(a.to), a.amount)
+}
+
+func TestCommutativeKeys(t *testing.T) {
+	require := require.New(t)
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+	sm := &refundTestStateManager{}
+
+	var (
+		actor     = codec.CreateAddress(0, ids.GenerateTestID())
+		recipient = codec.CreateAddress(0, ids.GenerateTestID())
+		other     = codec.CreateAddress(0, ids.GenerateTestID())
+	)
+	tests := []struct {
+		name     string
+		actions  []Action
+		expected []string
+	}{
+		{
+			name:    "not commutative",
+			actions: []Action{&balanceTestAction{account: recipient, credit: 1}},
+		},
+		{
+			name:     "commutative",
+			actions:  []Action{&commutativeTestAction{to: recipient, amount: 1}},
+			expected: []string{string(refundTestBalanceKey(recipient))},
+		},
+		{
+			name: "declared by other actions",
+			actions: []Action{
+				&commutativeTestAction{to: recipient, amount: 1},
+				&commutativeTestAction{to: other, amount: 1},
+				&balanceTestAction{account: recipient},
+			},
+			expected: []string{string(refundTestBalanceKey(other))},
+		},
+		{
+			name:    "declared by sponsor",
+			actions: []Action{&commutativeTestAction{to: actor, amount: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := NewTx(&Base{}, tt.actions)
+			tx.Auth = &testAuth{actor: actor}
+			keys, err := tx.CommutativeKeys(sm, r)
+			require.NoError(err)
+			require.Equal(tt.expected, keys)
+		})
+	}
+}
+
+func TestAccumulate(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	key := refundTestBalanceKey(codec.EmptyAddress)
+	keyStr := string(key)
+
+	// Accumulators can only be modified with [Accumulate] (whether or not
+	// it is deferred)
+	for _, deferred := range []bool{false, true} {
+		ts := tstate.New(1)
+		tsv := ts.NewView(state.Keys{keyStr: state.All}, map[string][]byte{keyStr: binary.BigEndian.AppendUint64(nil, 1)})
+		tsv.SetAccumulators([]string{keyStr}, deferred)
+		_, err := tsv.GetValue(ctx, key)
+		require.ErrorIs(err, tstate.ErrAccumulatorKey)
+		require.ErrorIs(tsv.Insert(ctx, key, []byte{1}), tstate.ErrAccumulatorKey)
+		require.ErrorIs(tsv.Remove(ctx, key), tstate.ErrAccumulatorKey)
+
+		// Rolled back accumulations are not applied
+		require.NoError(Accumulate(ctx, tsv, key, 2))
+		start := tsv.OpIndex()
+		require.NoError(Accumulate(ctx, tsv, key, 4))
+		tsv.Rollback(ctx, start)
+		tsv.Commit()
+		require.False(ts.DeferFailed())
+		v, ok := ts.ChangedKeys()[keyStr]
+		require.True(ok)
+		require.Equal(binary.BigEndian.AppendUint64(nil, 3), v.Value())
+	}
+
+	// An overflow fails unless it is deferred
+	s := taskTestState{keyStr: binary.BigEndian.AppendUint64(nil, math.MaxUint64)}
+	require.ErrorIs(Accumulate(ctx, s, key, 1), tstate.ErrAccumulatorOverflow)
+	ts := tstate.New(1)
+	tsv := ts.NewView(state.Keys{keyStr: state.All}, s)
+	tsv.SetAccumulators([]string{keyStr}, false)
+	require.ErrorIs(Accumulate(ctx, tsv, key, 1), tstate.ErrAccumulatorOverflow)
+	tsv = ts.NewView(state.Keys{keyStr: state.All}, s)
+	tsv.SetAccumulators([]string{keyStr}, true)
+	require.NoError(Accumulate(ctx, tsv, key, 1))
+	tsv.Commit()
+	require.True(ts.DeferFailed())
+	require.Empty(ts.ChangedKeys())
+
+	// Invalid accumulators fail
+	s = taskTestState{keyStr: []byte{1}}
+	require.ErrorIs(Accumulate(ctx, s, key, 1), tstate.ErrInvalidAccumulator)
+}
+
+// newCommutativeTestBlock returns a random block of credits (commutative or
+// not), reads, and transfers between a few accounts (some of which hold
+// balances that can overflow if [overflow] is set).
+func newCommutativeTestBlock(require *require.Assertions, rng *rand.Rand, c *parallelTestConfig, chainID ids.ID, numTxs int, overflow bool) ([]*Transaction, taskTestState) {
+	var (
+		actionRegistry, authRegistry = c.Registry()
+
+		s        = make(taskTestState)
+		accounts = make([]codec.Address, 4)
+		txs      = make([]*Transaction, numTxs)
+	)
+	for i := range accounts {
+		accounts[i] = codec.CreateAddress(0, ids.GenerateTestID())
+		if overflow && rng.Intn(2) == 0 {
+			s[string(refundTestBalanceKey(accounts[i]))] = binary.BigEndian.AppendUint64(nil, math.MaxUint64-uint64(rng.Intn(1_000)))
+		}
+	}
+	for i := range txs {
+		factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		s[string(refundTestBalanceKey(factory.actor))] = binary.BigEndian.AppendUint64(nil, parallelTestBalance)
+		account := accounts[rng.Intn(len(accounts))]
+		amount := uint64(rng.Intn(100))
+		var action Action
+		switch rng.Intn(8) {
+		case 0:
+			action = &balanceTestAction{account: account}
+		case 1:
+			action = &balanceTestAction{account: account, credit: amount}
+		case 2:
+			action = &parallelTestAction{to: account, amount: amount + 1}
+		case 3:
+			action = &commutativeTestAction{to: account, read: true}
+		default:
+			action = &commutativeTestAction{to: account, amount: amount}
+		}
+		tx, err := NewTx(&Base{Timestamp: 1_000, ChainID: chainID, MaxFee: 1_000_000}, []Action{action}).Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		txs[i] = tx
+	}
+	return txs, s
+}
+
+func TestCommutativeExecution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	c := &parallelTestConfig{cores: 8}
+	serial := &parallelTestRules{newOfflineTestRules(ctrl, chainID), false}
+	parallel := &parallelTestRules{newOfflineTestRules(ctrl, chainID), true}
+
+	// Executing random workloads in parallel (deferring accumulations) must
+	// produce the same results and state as executing them serially,
+	// including when accumulators overflow
+	var overflowed, succeeded int
+	for seed := int64(0); seed < 64; seed++ {
+		require := require.New(t)
+		rng := rand.New(rand.NewSource(seed)) //nolint:gosec
+		txs, s := newCommutativeTestBlock(require, rng, c, chainID, 128, seed%2 == 0)
+		serialResults, serialState := executeParallelTestBlock(require, c, serial, s, txs)
+		parallelResults, parallelState := executeParallelTestBlock(require, c, parallel, s, txs)
+		require.Equal(serialResults, parallelResults, "seed=%d", seed)
+		require.Equal(serialState, parallelState, "seed=%d", seed)
+
+		for i, result := range serialResults {
+			action, ok := txs[i].Actions[0].(*commutativeTestAction)
+			if !ok {
+				continue
+			}
+			switch {
+			case action.read:
+				require.False(result.Success)
+				require.Contains(string(result.Error), tstate.ErrAccumulatorKey.Error())
+			case result.Success:
+				succeeded++
+			default:
+				require.Contains(string(result.Error), tstate.ErrAccumulatorOverflow.Error())
+				overflowed++
+			}
+		}
+	}
+
+	// The workloads exercise both outcomes
+	require.Positive(t, overflowed)
+	require.Positive(t, succeeded)
+}
@@ -4,8 +4,10 @@
 package chain
 
 import (
+	"slices"
 	"time"
 
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/keys"
 )
 
@@ -19,7 +21,59 @@ const (
 	FutureBound        = 1 * time.Second
 	HeightKeyChunks    = 1
 	TimestampKeyChunks = 1
-	FeeKeyChunks       = 8 // 96 (per dimension) * 5 (num dimensions)
+	FeeKeyChunks       = 8   // 96 (per dimension) * 5 (num dimensions) + 8 (block cost) + 4 (per dimension utilization) * 5
+	MemoKeyChunks      = 5   // [MaxTxMemoSize] / 64 (chunk size) + 1
+	TaskQueueKeyChunks = 161 // [MaxQueuedTasks] * 40 (execution time + task ID) / 64 (chunk size) + 1
+	TaskCountKeyChunks = 1
+	TaskKeyChunks      = 17 // [MaxTaskSize] / 64 (chunk size) + 1
+	ACLKeyChunks       = 1
+	EpochKeyChunks     = 161 // ([MaxEpochValidators] * 80 + 20) / 64 (chunk size) + 1
+
+	IdempotencyKeyChunks = 1
+
+	// MaxTxMemoSize is the maximum size of the [Transaction.Memo].
+	MaxTxMemoSize = 256
+
+	// MaxTxActions is the maximum number of actions any [Transaction] can
+	// encode (regardless of [Rules.GetMaxActionsPerTx]). The high bit of the
+	// action count is reserved for [txMemoFlag].
+	MaxTxActions = 0x7f
+
+	// txMemoFlag is set in the action count of a [Transaction] that has a
+	// [Transaction.Memo].
+	txMemoFlag = 0x80
+
+	// MaxQueuedTasks is the maximum number of [Task]s that can be waiting to
+	// be executed at any time.
+	MaxQueuedTasks = 256
+
+	// MaxQueuedTasksPerActor is the maximum number of [Task]s of a single
+	// [Task.Actor] that can be waiting to be executed at any time.
+	MaxQueuedTasksPerActor = 8
+
+	// MaxTaskDelay is the furthest ahead of the block timestamp a [Task] can
+	// be scheduled to execute.
+	MaxTaskDelay = 7 * 24 * time.Hour
+
+	// MaxTasksPerBlock is the maximum number of due [Task]s executed by a
+	// single block. Any remaining due [Task]s are executed by later blocks.
+	MaxTasksPerBlock = 32
+
+	// MaxTaskSize is the maximum size of an encoded [Task].
+	MaxTaskSize = 1_024
+
+	// MaxEpochValidators is the maximum number of validators recorded in an
+	// [EpochSnapshot]. If the validator set is larger, only the validators
+	// with the most weight are recorded.
+	MaxEpochValidators = 128
+
+	// MaxResultErrorSize is the maximum size of the error included in a [Result]
+	// for a failed transaction. Longer errors are truncated.
+	MaxResultErrorSize = 1_024
+
+	// MaxForkDepth is the maximum number of blocks [ForkDepth] walks back from
+	// either block to find their common ancestor.
+	MaxForkDepth = 1_024
 
 	// MaxKeyDependencies must be greater than the maximum number of key dependencies
 	// any single task could have when executing a task.
@@ -37,3 +91,33 @@ func TimestampKey(prefix []byte) []byte {
 func FeeKey(prefix []byte) []byte {
 	return keys.EncodeChunks(prefix, FeeKeyChunks)
 }
+
+func MemoKey(prefix []byte) []byte {
+	return keys.EncodeChunks(prefix, MemoKeyChunks)
+}
+
+func TaskQueueKey(prefix []byte) []byte {
+	return keys.EncodeChunks(prefix, TaskQueueKeyChunks)
+}
+
+// TaskCountKey is the key the number of queued [Task]s of [actor] (in the
+// task queue with [prefix]) is stored at.
+func TaskCountKey(prefix []byte, actor codec.Address) []byte {
+	return keys.EncodeChunks(append(slices.Clone(prefix), actor[:]...), TaskCountKeyChunks)
+}
+
+func TaskKey(prefix []byte) []byte {
+	return keys.EncodeChunks(prefix, TaskKeyChunks)
+}
+
+func ACLKey(prefix []byte) []byte {
+	return keys.EncodeChunks(prefix, ACLKeyChunks)
+}
+
+func EpochKey(prefix []byte) []byte {
+	return keys.EncodeChunks(prefix, EpochKeyChunks)
+}
+
+func IdempotencyKey(prefix []byte) []byte {
+	return keys.EncodeChunks(prefix, IdempotencyKeyChunks)
+}
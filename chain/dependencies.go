@@ -33,9 +33,11 @@ type Parser interface {
 }
 
 type Metrics interface {
-	RecordRootCalculated(time.Duration) // only called in Verify
-	RecordWaitRoot(time.Duration)       // only called in Verify
-	RecordWaitSignatures(time.Duration) // only called in Verify
+	RecordRootCalculated(time.Duration)       // only called in Verify
+	RecordWaitRoot(time.Duration)             // only called in Verify
+	RecordWaitSignatures(time.Duration)       // only called in Verify
+	RecordShadowRootCalculated(time.Duration) // only called in Verify
+	RecordShadowRootMismatch()                // only called in Verify
 
 	RecordBlockVerify(time.Duration)
 	RecordBlockAccept(time.Duration)
@@ -44,8 +46,14 @@ type Metrics interface {
 	RecordBuildCapped()
 	RecordEmptyBlockBuilt()
 	RecordClearedMempool()
+	RecordPriorityLaneUtilization(float64)
+	RecordActionPanic()
 	GetExecutorBuildRecorder() executor.Metrics
 	GetExecutorVerifyRecorder() executor.Metrics
+
+	// GetKeyAccessRecorder returns the [KeyAccessRecorder] of executed
+	// transactions (or nil if key accesses are not recorded).
+	GetKeyAccessRecorder() KeyAccessRecorder
 }
 
 type Monitoring interface {
@@ -58,6 +66,10 @@ type VM interface {
 	Monitoring
 	Parser
 
+	// VerifyLogger is used to log block verification (which may be configured
+	// at a different level than [Monitoring.Logger]).
+	VerifyLogger() logging.Logger
+
 	// We don't include this in registry because it would never be used
 	// by any client of the hypersdk.
 	AuthVerifiers() workers.Workers
@@ -65,23 +77,86 @@ type VM interface {
 	GetVerifyAuth() bool
 
 	IsBootstrapped() bool
+
+	// Now returns the local time corrected by the offset measured from the
+	// clocks of peers (bounded by a configured max correction). It is used to
+	// select the timestamp of built blocks and to reject blocks too far in the
+	// future.
+	Now() time.Time
+
+	// CatchUpIncrementalRoots returns true if each verified block is accepted
+	// before any other block is verified (i.e. when bootstrapping) and blocks
+	// built on the accepted state should commit their changes to it during
+	// verification. This computes the root of each block incrementally on the
+	// accepted state instead of on a new view.
+	CatchUpIncrementalRoots() bool
 	LastAcceptedBlock() *StatelessBlock
 	GetStatelessBlock(context.Context, ids.ID) (*StatelessBlock, error)
+	// GetBlockHeightTimestamp returns the timestamp of the accepted block at
+	// [height].
+	GetBlockHeightTimestamp(height uint64) (int64, error)
 
 	GetVerifyContext(ctx context.Context, blockHeight uint64, parent ids.ID) (VerifyContext, error)
 
 	State() (merkledb.MerkleDB, error)
+	// CommitState commits [view] (the post-execution state of [blk]) to the
+	// accepted state. [blk] must be persisted before [view] is committed, so
+	// that it can be recorded as accepted on restart if the node stops before
+	// [Accepted] is called for it.
+	CommitState(ctx context.Context, blk *StatelessBlock, view merkledb.View) error
+	// ShadowRootComputer returns the [RootComputer] used to check the root of
+	// each verified block against a secondary implementation (or nil if
+	// shadow root verification is disabled).
+	ShadowRootComputer() RootComputer
 	StateManager() StateManager
 	ValidatorState() validators.State
+	SubnetID() ids.ID
+
+	// BuilderAddress is the [codec.Address] this node includes in blocks it builds
+	// when [Rules.GetRestrictBuilders] is enabled.
+	BuilderAddress() codec.Address
+	// SignBuilder returns the BLS signature of this node over [digest] (see
+	// [StatefulBlock.BuilderSignature]).
+	SignBuilder(digest ids.ID) ([]byte, error)
+	// VerifyBuilder returns true if [builder] belongs to a validator in the
+	// validator set at [pChainHeight] and [signature] is the BLS signature of
+	// that validator over [digest].
+	VerifyBuilder(ctx context.Context, builder codec.Address, pChainHeight uint64, digest ids.ID, signature []byte) (bool, error)
+
+	// BlockProfiler returns the [BlockProfiler] used to profile block
+	// verification (or nil if no blocks are being profiled).
+	BlockProfiler() BlockProfiler
+
+	// StatePrefixes returns the state prefixes usage is tracked for (or nil if
+	// state usage is not tracked).
+	StatePrefixes() *StatePrefixRegistry
 
 	Mempool() Mempool
+
+	// PriorityLane returns the [PriorityLane] registered by the controller (or
+	// nil if there is none). Up to [GetPriorityLaneUnitsPercent] of each block
+	// dimension is reserved for transactions in the lane.
+	PriorityLane() *PriorityLane
+	GetPriorityLaneUnitsPercent() uint64
 	IsRepeat(context.Context, []*Transaction, set.Bits, bool) set.Bits
 	GetTargetBuildDuration() time.Duration
 	GetTransactionExecutionCores() int
 	GetStateFetchConcurrency() int
 
+	// GetExecutionContext returns the [ExecutionContext] of a child of [parent]
+	// at [timestamp]. Contexts may be cached because they are fully determined
+	// by [parent] and [timestamp].
+	GetExecutionContext(parent ids.ID, parentFees []byte, parentTimestamp int64, timestamp int64) (*ExecutionContext, error)
+
+	// BeginVerify is called when a block starts being verified and the
+	// returned function is called once it is done (so the VM can
+	// deprioritize background work in the meantime).
+	BeginVerify() func()
 	Verified(context.Context, *StatelessBlock)
 	Rejected(context.Context, *StatelessBlock)
+	// BeginAccept is called when a block starts being accepted and the
+	// returned function is called once it is done (like [BeginVerify]).
+	BeginAccept() func()
 	Accepted(context.Context, *StatelessBlock)
 	AcceptedSyncableBlock(context.Context, *SyncableBlock) (block.StateSyncMode, error)
 
@@ -125,16 +200,119 @@ type Rules interface {
 	GetMinEmptyBlockGap() int64 // in milliseconds
 	GetValidityWindow() int64   // in milliseconds
 
+	// GetMaxRepeatCheckDepth is the maximum number of heights the validity
+	// window of a block may span (or 0 if there is no maximum). Blocks with a
+	// longer window fail verification with [ErrReplayCheckTooDeep], which
+	// bounds the number of processing blocks checked for repeats (see
+	// [StatelessBlock.IsRepeat]).
+	//
+	// Nodes must retain the timestamps of at least this many accepted blocks
+	// (see [VM.GetBlockHeightTimestamp]).
+	GetMaxRepeatCheckDepth() int
+
 	GetMaxActionsPerTx() uint8
 	GetMaxOutputsPerAction() uint8
 
+	// GetMaxActionOutputBytes is the maximum combined size of the outputs
+	// returned by a single [Action]. Transactions with an action that exceeds
+	// it fail (and the outputs are not included in the results of the block).
+	GetMaxActionOutputBytes() uint64
+
 	GetMinUnitPrice() fees.Dimensions
 	GetUnitPriceChangeDenominator() fees.Dimensions
 	GetWindowTargetUnits() fees.Dimensions
 	GetMaxBlockUnits() fees.Dimensions
 
+	// GetPricingMode determines how unit prices are computed from the units
+	// consumed by previous blocks (see [fees.PricingMode]). The mode can be
+	// changed at a fork (the fee window is maintained under every mode).
+	GetPricingMode() fees.PricingMode
+
+	// GetUtilizationEMADenominator determines how quickly the utilization
+	// tracked by [fees.EMAPricing] follows the utilization of each block (it
+	// moves 1/denominator of the way each block).
+	GetUtilizationEMADenominator() uint64
+
+	// GetTargetBlockRate is the desired number of seconds between blocks. When
+	// greater than 0, the transactions in each block must pay at least
+	// [ExecutionContext.NextBlockCost] in fees, which increases when blocks are
+	// produced faster than the target and decreases when they are produced
+	// slower (bounded by [GetMinBlockCost] and [GetMaxBlockCost]).
+	GetTargetBlockRate() int64
+	GetBlockCostChangeDenominator() uint64
+	GetMinBlockCost() uint64
+	GetMaxBlockCost() uint64
+
 	GetBaseComputeUnits() uint64
 
+	// GetRestrictBuilders returns true if blocks must be built (and signed)
+	// by a validator in the validator set at the P-Chain height they are
+	// verified with (as reported by [VM.VerifyBuilder]). It is only enforced
+	// once [RestrictBuildersFork] is active.
+	GetRestrictBuilders() bool
+
+	// GetIncludeResultsRoot returns true if blocks must commit to the [Result]
+	// of each transaction with a [StatefulBlock.ResultsRoot].
+	GetIncludeResultsRoot() bool
+
+	// GetDelayedExecution returns true if the transactions included in a block
+	// are executed by its child (instead of by the block itself).
+	GetDelayedExecution() bool
+
+	// GetPermutePendingTxs returns true if the transactions included by the
+	// parent (in delayed execution mode) are executed in a fixed permutation of
+	// the order they were included in, derived from the parent's post-execution
+	// state root. The builder of the parent knows this root when it includes
+	// the transactions, so it still chooses the order they are executed in
+	// (see [ExecutionOrder]).
+	GetPermutePendingTxs() bool
+
+	// GetStateFetchRetries returns the number of times a transient failure to
+	// fetch state during verification is retried (see [TransientError]).
+	GetStateFetchRetries() uint8
+
+	// GetParallelExecution returns true if transactions that don't share any
+	// state keys may be executed concurrently. The results of execution are
+	// identical to executing transactions serially.
+	GetParallelExecution() bool
+
+	// GetEpochLength returns the number of blocks in each epoch (or 0 if
+	// epochs are disabled). The first block of each epoch records a snapshot
+	// of the validator set in state (see [GetEpochSnapshot]).
+	GetEpochLength() uint64
+
+	// GetMinDistinctSigners returns the minimum number of distinct actors
+	// that must sign the transactions included in a block (or 0 if there is
+	// no minimum). Blocks with fewer than [GetMinDistinctSignersTxs]
+	// transactions are exempt.
+	GetMinDistinctSigners() int
+	GetMinDistinctSignersTxs() int
+
+	// GetEnforceIdempotencyKeys returns true if an [IdempotentAction] can't
+	// be applied more than once with the same [IdempotentAction.IdempotencyKey].
+	GetEnforceIdempotencyKeys() bool
+
+	// GetRefundPolicy returns how the compute units refunded by a
+	// [RefundingAction] are handled (see [RefundPolicy]).
+	GetRefundPolicy() RefundPolicy
+
+	// IsActionRestricted returns true if actions with [typeID] can only be
+	// submitted by actors granted access with [GrantAccess].
+	IsActionRestricted(typeID uint8) bool
+
+	// GetACLAdmin returns the only address that may grant or revoke access
+	// to restricted actions.
+	GetACLAdmin() codec.Address
+
+	// IsPaused returns true if the chain is paused at [timestamp]. Blocks
+	// built while paused may only include transactions from [GetACLAdmin]
+	// with [AdminAction]s (all others are rejected with [ErrChainPaused]).
+	IsPaused(timestamp int64) bool
+
+	// GetForkActivations returns when each [Fork] activates (see [IsActive]).
+	// The same activations must be returned at every timestamp.
+	GetForkActivations() ForkActivations
+
 	// Invariants:
 	// * Controllers must manage the max key length and max value length (max network
 	//   limit is ~2MB)
@@ -156,6 +334,30 @@ type MetadataManager interface {
 	HeightKey() []byte
 	TimestampKey() []byte
 	FeeKey() []byte
+
+	// MemoKey is the key the [Transaction.Memo] of [txID] is stored at (if
+	// present).
+	MemoKey(txID ids.ID) []byte
+
+	// TaskQueueKey is the prefix of the keys the queue of pending [Task]s is
+	// stored at (see [TaskQueueKey] and [TaskCountKey]).
+	TaskQueueKey() []byte
+
+	// TaskKey is the key the [Task] with [taskID] is stored at (until it is
+	// executed).
+	TaskKey(taskID ids.ID) []byte
+
+	// ACLKey is the key that records whether [addr] may submit actions with
+	// [actionTypeID] (if restricted by [Rules.IsActionRestricted]).
+	ACLKey(actionTypeID uint8, addr codec.Address) []byte
+
+	// EpochKey is the key the validator set snapshot of [epoch] is stored at
+	// (see [GetEpochSnapshot]).
+	EpochKey(epoch uint64) []byte
+
+	// IdempotencyKey is the key that records the transaction that applied
+	// the [IdempotentAction] identified by [id] (see [IdempotencyID]).
+	IdempotencyKey(id ids.ID) []byte
 }
 
 type FeeHandler interface {
@@ -172,6 +374,11 @@ type FeeHandler interface {
 
 	// Deduct removes [amount] from [addr] during transaction execution to pay fees.
 	Deduct(ctx context.Context, addr codec.Address, mu state.Mutable, amount uint64) error
+
+	// Refund returns [amount] of previously deducted fees to [addr] (see
+	// [RefundPolicy]). Only keys returned by [SponsorStateKeys] may be
+	// accessed.
+	Refund(ctx context.Context, addr codec.Address, mu state.Mutable, amount uint64) error
 }
 
 // StateManager allows [Chain] to safely store certain types of items in state
@@ -223,6 +430,14 @@ type Action interface {
 	// key (formatted as a big-endian uint16). This is used to automatically calculate storage usage.
 	//
 	// If any key is removed and then re-created, this will count as a creation instead of a modification.
+	//
+	// Keys that could be created must be declared with [state.Allocate] and keys that could be
+	// modified (or removed) must be declared with [state.Write]. Once [AllocatePermissionsFork] is
+	// active, keys are only charged for the permissions they are declared with.
+	//
+	// If multiple actions in a transaction declare the same key, the transaction is granted the union
+	// of their permissions (see [Transaction.StateKeys]), so every action may use the permissions
+	// declared by any other action for that key.
 	StateKeys(actor codec.Address, actionID ids.ID) state.Keys
 
 	// Execute actually runs the [Action]. Any state changes that the [Action] performs should
@@ -242,6 +457,116 @@ type Action interface {
 	) (outputs [][]byte, err error)
 }
 
+// RefundingAction is an [Action] that may use fewer compute units during
+// execution than it reserved with [ComputeUnits].
+type RefundingAction interface {
+	Action
+
+	// ExecuteRefunding is called instead of [Execute] and returns the compute
+	// units reserved by [ComputeUnits] that were not used. Refunds are only
+	// applied if all actions of the transaction succeed and refunds larger
+	// than [ComputeUnits] are capped.
+	//
+	// The same [Action] may be executed concurrently (i.e. when verifying
+	// sibling blocks), so the refund must not be stored on the [Action].
+	//
+	// [Execute] is not called by the processor, so it may be implemented by
+	// calling [ExecuteRefunding] and dropping the refund. If an [Action] is
+	// also a [MeteredAction], only [MeteredAction.ExecuteMetered] is called.
+	ExecuteRefunding(
+		ctx context.Context,
+		r Rules,
+		mu state.Mutable,
+		timestamp int64,
+		actor codec.Address,
+		actionID ids.ID,
+	) (outputs [][]byte, refund uint64, err error)
+}
+
+// MeteredAction is an [Action] whose compute depends on its inputs. Its
+// [ComputeUnits] are the maximum it may consume. Regardless of the
+// [RefundPolicy], the sponsor of the transaction is only charged for the
+// units it consumes (the fee of the rest is returned once all actions of the
+// transaction succeed).
+type MeteredAction interface {
+	Action
+
+	// ExecuteMetered is called instead of [Execute] and must consume gas from
+	// [meter] as it works. If [GasMeter.Consume] fails, [ExecuteMetered]
+	// should return its error (which reverts the transaction).
+	//
+	// [Execute] is not called by the processor, so it may be implemented by
+	// calling [ExecuteMetered] with a [GasMeter] limited to [ComputeUnits].
+	ExecuteMetered(
+		ctx context.Context,
+		r Rules,
+		mu state.Mutable,
+		timestamp int64,
+		actor codec.Address,
+		actionID ids.ID,
+		meter *GasMeter,
+	) (outputs [][]byte, err error)
+}
+
+// CommutativeAction is an [Action] that only adds to some of the keys it
+// writes (i.e. credits a balance), which allows transactions that add to the
+// same key to be executed concurrently.
+type CommutativeAction interface {
+	Action
+
+	// Commutative returns the keys that [Execute] (including any actions it
+	// calls) only modifies with [Accumulate]. A key is only treated as
+	// commutative if it is declared by [StateKeys] with [state.Allocate] and
+	// [state.Write] and no other action of the transaction (or its sponsor)
+	// declares it without also returning it here.
+	//
+	// Commutative keys can't be read, inserted, or removed during [Execute].
+	Commutative(actor codec.Address, actionID ids.ID) []string
+}
+
+// IdempotentAction is an [Action] that performs a logical operation (like
+// paying an invoice) that must not be applied more than once, even by
+// different transactions.
+//
+// If [Rules.GetEnforceIdempotencyKeys] is enabled, the [IdempotencyKey] of
+// each action is recorded in state (at [MetadataManager.IdempotencyKey]) when
+// it is executed and any later action of the same type with the same key
+// fails with [ErrDuplicateIdempotencyKey].
+type IdempotentAction interface {
+	Action
+
+	// IdempotencyKey identifies the operation performed by the action (or
+	// is empty if it may be applied more than once).
+	IdempotencyKey() []byte
+}
+
+// ValueSpender is an [Action] that can estimate the value it spends from the
+// actor's balance (in addition to any fee).
+//
+// This is only a hint used by the mempool to avoid admitting transactions
+// that can't all be paid for. It is never used during verification.
+type ValueSpender interface {
+	Action
+
+	// ValueSpent returns the maximum value spent from the actor's balance by
+	// [Execute] (in the same units as the fee).
+	ValueSpent() uint64
+}
+
+// AdminAction is an [Action] used to administer the chain (like granting or
+// revoking access to restricted actions).
+//
+// While the chain is paused (see [Rules.IsPaused]), blocks may only include
+// transactions from [Rules.GetACLAdmin] that exclusively contain admin
+// actions.
+type AdminAction interface {
+	Action
+
+	// AdminAction is never called. It only marks the action as an admin
+	// action.
+	AdminAction()
+}
+
 type Auth interface {
 	Object
 
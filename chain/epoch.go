@@ -0,0 +1,352 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+const epochValidatorSize = ids.NodeIDLen + consts.Uint64Len + consts.IntLen + bls.PublicKeyLen
+
+// EpochValidator is a member of the validator set recorded by an
+// [EpochSnapshot].
+type EpochValidator struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	Weight uint64     `json:"weight"`
+
+	// PublicKey is the compressed BLS public key of the validator (or nil if
+	// it has not registered one).
+	PublicKey []byte `json:"publicKey"`
+}
+
+// EpochSnapshot is the validator set of the P-Chain at [PChainHeight],
+// recorded in state by the first block of [Epoch].
+//
+// Because the snapshot is written during block execution, it is part of the
+// state root and can be read by any [Action] (with [EpochStateKeys]) or from
+// any view of state (with [GetEpochSnapshot]).
+type EpochSnapshot struct {
+	Epoch        uint64 `json:"epoch"`
+	PChainHeight uint64 `json:"pChainHeight"`
+
+	// Validators are sorted by [ids.NodeID].
+	Validators []*EpochValidator `json:"validators"`
+}
+
+// Epoch returns the epoch the block at [height] belongs to. Epochs are
+// [Rules.GetEpochLength] blocks long, so epoch 0 contains the genesis block.
+//
+// Epoch must only be called when epochs are enabled.
+func Epoch(r Rules, height uint64) uint64 {
+	return height / r.GetEpochLength()
+}
+
+// IsEpochStart returns true if the block at [height] must record the
+// [EpochSnapshot] of its epoch.
+//
+// The genesis block is never executed, so the snapshot of epoch 0 is recorded
+// by the block at height 1.
+func IsEpochStart(r Rules, height uint64) bool {
+	length := r.GetEpochLength()
+	if length == 0 || height == 0 {
+		return false
+	}
+	return height == 1 || height%length == 0
+}
+
+// EpochStateKeys returns the keys an [Action] must include in
+// [Action.StateKeys] to read the [EpochSnapshot] of [epoch].
+func EpochStateKeys(sm MetadataManager, epoch uint64) state.Keys {
+	return state.Keys{
+		string(EpochKey(sm.EpochKey(epoch))): state.Read,
+	}
+}
+
+// EpochStateKeysMaxChunks returns the max chunks of the keys returned by
+// [EpochStateKeys].
+func EpochStateKeysMaxChunks() []uint16 {
+	return []uint16{EpochKeyChunks}
+}
+
+// GetEpochSnapshot returns the [EpochSnapshot] of [epoch] in [im] (or
+// [ErrEpochNotFound] if it has not been recorded).
+func GetEpochSnapshot(
+	ctx context.Context,
+	im state.Immutable,
+	sm MetadataManager,
+	epoch uint64,
+) (*EpochSnapshot, error) {
+	raw, err := im.GetValue(ctx, EpochKey(sm.EpochKey(epoch)))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrEpochNotFound, epoch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalEpochSnapshot(raw)
+}
+
+// NewEpochSnapshot creates the [EpochSnapshot] of [epoch] from [vdrs] (the
+// validator set at [pChainHeight]).
+//
+// If there are more than [MaxEpochValidators] validators, only those with the
+// most weight are recorded (ties are broken by [ids.NodeID]).
+func NewEpochSnapshot(
+	epoch uint64,
+	pChainHeight uint64,
+	vdrs map[ids.NodeID]*validators.GetValidatorOutput,
+) *EpochSnapshot {
+	snapshot := &EpochSnapshot{
+		Epoch:        epoch,
+		PChainHeight: pChainHeight,
+		Validators:   make([]*EpochValidator, 0, len(vdrs)),
+	}
+	for nodeID, vdr := range vdrs {
+		v := &EpochValidator{NodeID: nodeID, Weight: vdr.Weight}
+		if vdr.PublicKey != nil {
+			v.PublicKey = bls.PublicKeyToCompressedBytes(vdr.PublicKey)
+		}
+		snapshot.Validators = append(snapshot.Validators, v)
+	}
+	if len(snapshot.Validators) > MaxEpochValidators {
+		slices.SortFunc(snapshot.Validators, func(a, b *EpochValidator) int {
+			switch {
+			case a.Weight > b.Weight:
+				return -1
+			case a.Weight < b.Weight:
+				return 1
+			default:
+				return bytes.Compare(a.NodeID[:], b.NodeID[:])
+			}
+		})
+		snapshot.Validators = snapshot.Validators[:MaxEpochValidators]
+	}
+	slices.SortFunc(snapshot.Validators, func(a, b *EpochValidator) int {
+		return bytes.Compare(a.NodeID[:], b.NodeID[:])
+	})
+	return snapshot
+}
+
+func (s *EpochSnapshot) Marshal() ([]byte, error) {
+	p := codec.NewWriter(
+		consts.Uint64Len*2+consts.IntLen+len(s.Validators)*epochValidatorSize,
+		consts.NetworkSizeLimit,
+	)
+	p.PackUint64(s.Epoch)
+	p.PackUint64(s.PChainHeight)
+	p.PackInt(len(s.Validators))
+	for _, v := range s.Validators {
+		p.PackFixedBytes(v.NodeID[:])
+		p.PackUint64(v.Weight)
+		p.PackBytes(v.PublicKey)
+	}
+	return p.Bytes(), p.Err()
+}
+
+func UnmarshalEpochSnapshot(raw []byte) (*EpochSnapshot, error) {
+	p := codec.NewReader(raw, consts.NetworkSizeLimit)
+	s := &EpochSnapshot{
+		Epoch:        p.UnpackUint64(false),
+		PChainHeight: p.UnpackUint64(true),
+	}
+	count := p.UnpackInt(false)
+	if count > MaxEpochValidators {
+		return nil, fmt.Errorf("%w: %d validators", ErrInvalidObject, count)
+	}
+	s.Validators = make([]*EpochValidator, count)
+	for i := range s.Validators {
+		v := &EpochValidator{}
+		nodeID := v.NodeID[:]
+		p.UnpackFixedBytes(ids.NodeIDLen, &nodeID)
+		v.Weight = p.UnpackUint64(true)
+		p.UnpackBytes(bls.PublicKeyLen, false, &v.PublicKey)
+		if len(v.PublicKey) == 0 {
+			v.PublicKey = nil
+		}
+		s.Validators[i] = v
+	}
+	if !p.Empty() {
+		return nil, fmt.Errorf("%w: remaining=%d", ErrInvalidObject, len(raw)-p.Offset())
+	}
+	return s, p.Err()
+}
+
+type epochValidatorState interface {
+	ValidatorState() validators.State
+	SubnetID() ids.ID
+}
+
+// epochPChainHeight returns the P-Chain height a block at [height] built on
+// [im] should record the validator set of (or 0 if it doesn't start an
+// epoch).
+//
+// The height never decreases between epochs, so a validator set is never
+// recorded twice out of order.
+func epochPChainHeight(
+	ctx context.Context,
+	vm epochValidatorState,
+	r Rules,
+	sm MetadataManager,
+	im state.Immutable,
+	height uint64,
+) (uint64, error) {
+	if !IsEpochStart(r, height) {
+		return 0, nil
+	}
+	pHeight, err := vm.ValidatorState().GetMinimumHeight(ctx)
+	if err != nil {
+		return 0, err
+	}
+	prev, err := previousEpochSnapshot(ctx, r, sm, im, height)
+	if err != nil {
+		return 0, err
+	}
+	if prev != nil {
+		pHeight = max(pHeight, prev.PChainHeight)
+	}
+	// A height of 0 is used to indicate the block does not start an epoch
+	return max(pHeight, 1), nil
+}
+
+// loadEpochSnapshot returns the [EpochSnapshot] that the block at [height]
+// built on [im] must record at [pChainHeight] (or nil if it doesn't start an
+// epoch).
+func loadEpochSnapshot(
+	ctx context.Context,
+	vm epochValidatorState,
+	r Rules,
+	sm MetadataManager,
+	im state.Immutable,
+	height uint64,
+	pChainHeight uint64,
+) (*EpochSnapshot, error) {
+	if !IsEpochStart(r, height) {
+		if pChainHeight != 0 {
+			return nil, fmt.Errorf("%w: unexpected P-Chain height %d", ErrInvalidEpoch, pChainHeight)
+		}
+		return nil, nil
+	}
+	if pChainHeight == 0 {
+		return nil, fmt.Errorf("%w: missing P-Chain height", ErrInvalidEpoch)
+	}
+	prev, err := previousEpochSnapshot(ctx, r, sm, im, height)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && pChainHeight < prev.PChainHeight {
+		return nil, fmt.Errorf("%w: P-Chain height %d is before %d", ErrInvalidEpoch, pChainHeight, prev.PChainHeight)
+	}
+	vs := vm.ValidatorState()
+	current, err := vs.GetCurrentHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pChainHeight > current {
+		return nil, fmt.Errorf("%w: P-Chain height %d is after %d", ErrInvalidEpoch, pChainHeight, current)
+	}
+	vdrs, err := vs.GetValidatorSet(ctx, pChainHeight, vm.SubnetID())
+	if err != nil {
+		return nil, err
+	}
+	return NewEpochSnapshot(Epoch(r, height), pChainHeight, vdrs), nil
+}
+
+// previousEpochSnapshot returns the [EpochSnapshot] of the epoch before the
+// block at [height] (or nil if there is none, like when [height] is in the
+// first epoch or epochs were just enabled).
+func previousEpochSnapshot(
+	ctx context.Context,
+	r Rules,
+	sm MetadataManager,
+	im state.Immutable,
+	height uint64,
+) (*EpochSnapshot, error) {
+	epoch := Epoch(r, height)
+	if epoch == 0 {
+		return nil, nil
+	}
+	prev, err := GetEpochSnapshot(ctx, im, sm, epoch-1)
+	if errors.Is(err, ErrEpochNotFound) {
+		return nil, nil
+	}
+	return prev, err
+}
+
+// epochUnits returns the units consumed by writing an [EpochSnapshot] at
+// [key].
+func epochUnits(r Rules, key []byte) (fees.Dimensions, error) {
+	// The key is declared with every permission, so it is charged the same
+	// units whether or not [AllocatePermissionsFork] is active
+	reads, allocates, writes, err := storageUnits(r, state.Keys{string(key): state.Allocate | state.Write}, true, func(k string) (uint16, bool) {
+		return keys.MaxChunks([]byte(k))
+	})
+	if err != nil {
+		return fees.Dimensions{}, err
+	}
+	return fees.Dimensions{0, 0, reads, allocates, writes}, nil
+}
+
+// writeEpochSnapshot records [snapshot] (if not nil) in [ts] and consumes the
+// units of the write from [feeManager].
+//
+// The snapshot is written before any [Task]s or transactions are executed so
+// that they can read it in the same block.
+func writeEpochSnapshot(
+	ctx context.Context,
+	sm MetadataManager,
+	im state.Immutable,
+	ts *tstate.TState,
+	feeManager *fees.Manager,
+	r Rules,
+	snapshot *EpochSnapshot,
+) error {
+	if snapshot == nil {
+		return nil
+	}
+	key := EpochKey(sm.EpochKey(snapshot.Epoch))
+	units, err := epochUnits(r, key)
+	if err != nil {
+		return err
+	}
+	if ok, d := feeManager.Consume(units, r.GetMaxBlockUnits()); !ok {
+		return fmt.Errorf("%w: %d too large", ErrInvalidUnitsConsumed, d)
+	}
+	raw, err := snapshot.Marshal()
+	if err != nil {
+		return err
+	}
+
+	// Fetch the existing value (if any) so that overwriting it is not
+	// considered an allocation
+	storage := map[string][]byte{}
+	v, err := im.GetValue(ctx, key)
+	switch {
+	case err == nil:
+		storage[string(key)] = v
+	case !errors.Is(err, database.ErrNotFound):
+		return err
+	}
+	tsv := ts.NewView(state.Keys{string(key): state.Allocate | state.Write}, storage)
+	if err := tsv.Insert(ctx, key, raw); err != nil {
+		return err
+	}
+	tsv.Commit()
+	return nil
+}
@@ -0,0 +1,299 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// epochTestValidatorState is a [validators.State] that returns [vdrs] at
+// every height up to [current].
+type epochTestValidatorState struct {
+	validators.State
+
+	minimum uint64
+	current uint64
+	vdrs    map[ids.NodeID]*validators.GetValidatorOutput
+}
+
+func (s *epochTestValidatorState) GetMinimumHeight(context.Context) (uint64, error) {
+	return s.minimum, nil
+}
+
+func (s *epochTestValidatorState) GetCurrentHeight(context.Context) (uint64, error) {
+	return s.current, nil
+}
+
+func (s *epochTestValidatorState) GetValidatorSet(
+	context.Context,
+	uint64,
+	ids.ID,
+) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	return s.vdrs, nil
+}
+
+type epochTestVM struct {
+	vs *epochTestValidatorState
+}
+
+func (vm *epochTestVM) ValidatorState() validators.State { return vm.vs }
+func (*epochTestVM) SubnetID() ids.ID                    { return ids.Empty }
+
+func newEpochTestRules(ctrl *gomock.Controller, length uint64) *MockRules {
+	r := NewMockRules(ctrl)
+	r.EXPECT().GetEpochLength().Return(length).AnyTimes()
+	r.EXPECT().GetMaxBlockUnits().Return(fees.Dimensions{1_000, 1_000, 1_000, 1_000, 1_000}).AnyTimes()
+	r.EXPECT().GetStorageKeyReadUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageKeyAllocateUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageKeyWriteUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueReadUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueAllocateUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueWriteUnits().Return(uint64(1)).AnyTimes()
+	return r
+}
+
+func newEpochTestValidators(count int) map[ids.NodeID]*validators.GetValidatorOutput {
+	vdrs := make(map[ids.NodeID]*validators.GetValidatorOutput, count)
+	for i := 0; i < count; i++ {
+		sk, err := bls.NewSecretKey()
+		if err != nil {
+			panic(err)
+		}
+		nodeID := ids.GenerateTestNodeID()
+		vdrs[nodeID] = &validators.GetValidatorOutput{
+			NodeID:    nodeID,
+			PublicKey: bls.PublicFromSecretKey(sk),
+			Weight:    uint64(i + 1),
+		}
+	}
+	return vdrs
+}
+
+func TestIsEpochStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		length uint64
+		height uint64
+		start  bool
+		epoch  uint64
+	}{
+		{
+			name:   "disabled",
+			height: 1,
+		},
+		{
+			name:   "genesis",
+			length: 10,
+			height: 0,
+		},
+		{
+			name:   "first block after genesis",
+			length: 10,
+			height: 1,
+			start:  true,
+		},
+		{
+			name:   "middle of first epoch",
+			length: 10,
+			height: 9,
+		},
+		{
+			name:   "second epoch",
+			length: 10,
+			height: 10,
+			start:  true,
+			epoch:  1,
+		},
+		{
+			name:   "single block epochs",
+			length: 1,
+			height: 2,
+			start:  true,
+			epoch:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			r := newEpochTestRules(gomock.NewController(t), tt.length)
+			require.Equal(tt.start, IsEpochStart(r, tt.height))
+			if tt.length > 0 {
+				require.Equal(tt.epoch, Epoch(r, tt.height))
+			}
+		})
+	}
+}
+
+func TestEpochSnapshotMarshal(t *testing.T) {
+	require := require.New(t)
+
+	vdrs := newEpochTestValidators(MaxEpochValidators + 2)
+	vdrs[ids.GenerateTestNodeID()] = &validators.GetValidatorOutput{Weight: 1_000}
+	snapshot := NewEpochSnapshot(3, 100, vdrs)
+	require.Len(snapshot.Validators, MaxEpochValidators)
+
+	// Only the lightest validators are dropped
+	for _, v := range snapshot.Validators {
+		require.Greater(v.Weight, uint64(2))
+	}
+
+	raw, err := snapshot.Marshal()
+	require.NoError(err)
+	require.LessOrEqual(len(raw), int(EpochKeyChunks)*64)
+	parsed, err := UnmarshalEpochSnapshot(raw)
+	require.NoError(err)
+	require.Equal(snapshot, parsed)
+
+	// Trailing bytes are rejected
+	_, err = UnmarshalEpochSnapshot(append(raw, 0))
+	require.ErrorIs(err, ErrInvalidObject)
+}
+
+func TestWriteEpochSnapshot(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	var (
+		sm = &testStateManager{}
+		r  = newEpochTestRules(gomock.NewController(t), 10)
+		vm = &epochTestVM{&epochTestValidatorState{minimum: 5, current: 10, vdrs: newEpochTestValidators(4)}}
+		s  = taskTestState{}
+		ts = tstate.New(1)
+		fm = fees.NewManager(nil)
+	)
+
+	// The first block after genesis records epoch 0
+	pHeight, err := epochPChainHeight(ctx, vm, r, sm, s, 1)
+	require.NoError(err)
+	require.Equal(uint64(5), pHeight)
+	snapshot, err := loadEpochSnapshot(ctx, vm, r, sm, s, 1, pHeight)
+	require.NoError(err)
+	require.NoError(writeEpochSnapshot(ctx, sm, s, ts, fm, r, snapshot))
+	s.apply(ts)
+
+	// The write is accounted for in the block units
+	units, err := epochUnits(r, EpochKey(sm.EpochKey(0)))
+	require.NoError(err)
+	require.Equal(units, fm.UnitsConsumed())
+	require.Equal(fees.Dimensions{0, 0, 1 + EpochKeyChunks, 1 + EpochKeyChunks, 1 + EpochKeyChunks}, units)
+
+	stored, err := GetEpochSnapshot(ctx, s, sm, 0)
+	require.NoError(err)
+	require.Equal(snapshot, stored)
+	_, err = GetEpochSnapshot(ctx, s, sm, 1)
+	require.ErrorIs(err, ErrEpochNotFound)
+}
+
+func TestLoadEpochSnapshot(t *testing.T) {
+	ctx := context.Background()
+	sm := &testStateManager{}
+
+	// [s] is the state of a node that synced to the middle of epoch 1 (only
+	// the snapshots recorded in state are available)
+	s := taskTestState{}
+	raw, err := NewEpochSnapshot(1, 20, newEpochTestValidators(2)).Marshal()
+	require.NoError(t, err)
+	s[string(EpochKey(sm.EpochKey(1)))] = raw
+
+	tests := []struct {
+		name    string
+		height  uint64
+		pHeight uint64
+		epoch   bool
+		err     error
+	}{
+		{
+			name:   "middle of synced epoch",
+			height: 15,
+		},
+		{
+			name:    "unexpected P-Chain height",
+			height:  15,
+			pHeight: 20,
+			err:     ErrInvalidEpoch,
+		},
+		{
+			name:   "missing P-Chain height",
+			height: 20,
+			err:    ErrInvalidEpoch,
+		},
+		{
+			name:    "P-Chain height before synced epoch",
+			height:  20,
+			pHeight: 19,
+			err:     ErrInvalidEpoch,
+		},
+		{
+			name:    "P-Chain height after current",
+			height:  20,
+			pHeight: 31,
+			err:     ErrInvalidEpoch,
+		},
+		{
+			name:    "same P-Chain height as synced epoch",
+			height:  20,
+			pHeight: 20,
+			epoch:   true,
+		},
+		{
+			name:    "next epoch",
+			height:  20,
+			pHeight: 30,
+			epoch:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			r := newEpochTestRules(gomock.NewController(t), 10)
+			vm := &epochTestVM{&epochTestValidatorState{current: 30, vdrs: newEpochTestValidators(3)}}
+			snapshot, err := loadEpochSnapshot(ctx, vm, r, sm, s, tt.height, tt.pHeight)
+			require.ErrorIs(err, tt.err)
+			if !tt.epoch {
+				require.Nil(snapshot)
+				return
+			}
+			require.Equal(uint64(2), snapshot.Epoch)
+			require.Equal(tt.pHeight, snapshot.PChainHeight)
+			require.Len(snapshot.Validators, 3)
+		})
+	}
+}
+
+func TestEpochPChainHeight(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	var (
+		sm = &testStateManager{}
+		r  = newEpochTestRules(gomock.NewController(t), 10)
+		vm = &epochTestVM{&epochTestValidatorState{minimum: 5, current: 30}}
+		s  = taskTestState{}
+	)
+	raw, err := NewEpochSnapshot(1, 20, nil).Marshal()
+	require.NoError(err)
+	s[string(EpochKey(sm.EpochKey(1)))] = raw
+
+	// Blocks that don't start an epoch don't record a P-Chain height
+	pHeight, err := epochPChainHeight(ctx, vm, r, sm, s, 15)
+	require.NoError(err)
+	require.Zero(pHeight)
+
+	// The P-Chain height never decreases between epochs
+	pHeight, err = epochPChainHeight(ctx, vm, r, sm, s, 20)
+	require.NoError(err)
+	require.Equal(uint64(20), pHeight)
+}
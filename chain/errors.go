@@ -10,24 +10,46 @@ var (
 	ErrInvalidObject = errors.New("invalid object")
 
 	// Genesis Correctness
-	ErrInvalidChainID   = errors.New("invalid chain ID")
-	ErrInvalidBlockRate = errors.New("invalid block rate")
+	ErrInvalidChainID      = errors.New("invalid chain ID")
+	ErrInvalidBlockRate    = errors.New("invalid block rate")
+	ErrEmptySchedule       = errors.New("empty rule schedule")
+	ErrDuplicateActivation = errors.New("duplicate activation time")
+	ErrDuplicateFork       = errors.New("duplicate fork")
+	ErrUnknownFork         = errors.New("unknown fork")
+	ErrInvalidActivation   = errors.New("invalid fork activation")
+
+	// State Usage
+	ErrInvalidStatePrefix   = errors.New("invalid state prefix")
+	ErrDuplicateStatePrefix = errors.New("duplicate state prefix")
 
 	// Block Correctness
-	ErrTimestampTooEarly    = errors.New("timestamp too early")
-	ErrTimestampTooLate     = errors.New("timestamp too late")
-	ErrStateRootEmpty       = errors.New("state root empty")
-	ErrNoTxs                = errors.New("no transactions")
-	ErrInvalidFee           = errors.New("invalid fee")
-	ErrInvalidUnitWindow    = errors.New("invalid unit window")
-	ErrInvalidBlockCost     = errors.New("invalid block cost")
-	ErrInvalidBlockWindow   = errors.New("invalid block window")
-	ErrInvalidUnitsConsumed = errors.New("invalid units consumed")
-	ErrInsufficientSurplus  = errors.New("insufficient surplus fee")
-	ErrInvalidSurplus       = errors.New("invalid surplus fee")
-	ErrStateRootMismatch    = errors.New("state root mismatch")
-	ErrInvalidResult        = errors.New("invalid result")
-	ErrInvalidBlockHeight   = errors.New("invalid block height")
+	ErrTimestampTooEarly       = errors.New("timestamp too early")
+	ErrTimestampTooLate        = errors.New("timestamp too late")
+	ErrStateRootEmpty          = errors.New("state root empty")
+	ErrNoTxs                   = errors.New("no transactions")
+	ErrInvalidFee              = errors.New("invalid fee")
+	ErrInvalidUnitWindow       = errors.New("invalid unit window")
+	ErrInvalidBlockCost        = errors.New("invalid block cost")
+	ErrInvalidBlockWindow      = errors.New("invalid block window")
+	ErrInvalidUnitsConsumed    = errors.New("invalid units consumed")
+	ErrBlockUnitsExceeded      = errors.New("block units exceeded")
+	ErrInsufficientSurplus     = errors.New("insufficient surplus fee")
+	ErrInvalidSurplus          = errors.New("invalid surplus fee")
+	ErrStateRootMismatch       = errors.New("state root mismatch")
+	ErrResultsRootMismatch     = errors.New("results root mismatch")
+	ErrInvalidResult           = errors.New("invalid result")
+	ErrInvalidBlockHeight      = errors.New("invalid block height")
+	ErrUnauthorizedBuilder     = errors.New("unauthorized builder")
+	ErrInvalidBuilderSignature = errors.New("invalid builder signature")
+	ErrBuilderNotActive        = errors.New("builder not active")
+	ErrParentMismatch          = errors.New("parent mismatch")
+	ErrInvalidEpoch            = errors.New("invalid epoch")
+	ErrChainPaused             = errors.New("chain paused")
+	ErrTooFewSigners           = errors.New("too few distinct signers")
+	ErrBlockTooSmall           = errors.New("block too small")
+
+	// Block contains a tx that is not valid at its timestamp
+	ErrTxTimestampOutOfWindow = errors.New("tx timestamp out of window")
 
 	// Tx Correctness
 	ErrInvalidSignature     = errors.New("invalid signature")
@@ -45,22 +67,48 @@ var (
 	ErrServicerMissing      = errors.New("servicer missing")
 	ErrTooManyTxs           = errors.New("too many transactions")
 	ErrActionNotActivated   = errors.New("action not activated")
+	ErrActionNotPermitted   = errors.New("action not permitted")
 	ErrAuthNotActivated     = errors.New("auth not activated")
+	ErrMemoNotActivated     = errors.New("memo not activated")
 	ErrAuthFailed           = errors.New("auth failed")
 	ErrMisalignedTime       = errors.New("misaligned time")
 	ErrInvalidActor         = errors.New("invalid actor")
 	ErrInvalidSponsor       = errors.New("invalid sponsor")
 	ErrTooManyActions       = errors.New("too many actions")
 	ErrTooManyOutputs       = errors.New("too many outputs")
+	ErrActionOutputTooLarge = errors.New("action output too large")
+	ErrActionPanicked       = errors.New("action panicked")
+	ErrCallDepthExceeded    = errors.New("call depth exceeded")
+	ErrUndeclaredCallKey    = errors.New("undeclared call key")
+	ErrOutOfGas             = errors.New("out of gas")
+
+	ErrDuplicateIdempotencyKey = errors.New("duplicate idempotency key")
+
+	// Bundle Correctness
+	ErrInvalidBundle  = errors.New("invalid bundle")
+	ErrBundleTxFailed = errors.New("bundle tx failed")
 
 	// Execution Correctness
 	ErrInvalidBalance  = errors.New("invalid balance")
 	ErrBlockTooBig     = errors.New("block too big")
 	ErrKeyNotSpecified = errors.New("key not specified")
+	ErrTaskQueueFull   = errors.New("task queue full")
+	ErrTaskQuotaFull   = errors.New("task quota full")
+	ErrTaskTooLarge    = errors.New("task too large")
+	ErrTaskTooLate     = errors.New("task too late")
+	ErrDuplicateTask   = errors.New("duplicate task")
 
 	// Misc
 	ErrNotImplemented         = errors.New("not implemented")
 	ErrBlockNotProcessed      = errors.New("block is not processed")
 	ErrInvalidKeyValue        = errors.New("invalid key or value")
 	ErrModificationNotAllowed = errors.New("modification not allowed")
+	ErrUnsupportedExecution   = errors.New("unsupported execution mode")
+	ErrForkTooDeep            = errors.New("fork too deep")
+	ErrReplayCheckTooDeep     = errors.New("replay check too deep")
+	ErrNoCommonAncestor       = errors.New("no common ancestor")
+	ErrStateNotReady          = errors.New("state not ready")
+	ErrEpochNotFound          = errors.New("epoch not found")
+	ErrRejectedCommittedBlock = errors.New("rejected block committed to state")
+	ErrBlockCommitted         = errors.New("block committed to state")
 )
@@ -0,0 +1,37 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+// TxEvictionScore returns the priority of [tx] for remaining in a full mempool
+// at [now] (in milliseconds). Transactions with the lowest score should be
+// evicted first.
+//
+// The score is the fee density of [tx] (max fee per byte), discounted by its
+// age: a transaction loses up to half of its priority over its validity
+// window. Because the issuance time of a transaction is not recorded, it is
+// assumed to be issued one validity window before its expiry. Thus, older and
+// cheaper transactions are evicted first.
+//
+// The score only depends on [tx], [now], and [r], so all nodes (and repeated
+// calls) compute the same score.
+func TxEvictionScore(tx *Transaction, now int64, r Rules) float64 {
+	size := tx.Size()
+	if size <= 0 {
+		size = 1
+	}
+	density := float64(tx.MaxFee()) / float64(size)
+
+	window := r.GetValidityWindow()
+	if window <= 0 {
+		return density
+	}
+	age := now - (tx.Expiry() - window)
+	switch {
+	case age < 0:
+		age = 0
+	case age > window:
+		age = window
+	}
+	return density / (1 + float64(age)/float64(window))
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+func TestTxEvictionScore(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	r := newOfflineTestRules(ctrl, chainID) // 60s validity window
+	actionRegistry, authRegistry := (&testParser{}).Registry()
+	factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+
+	newTx := func(expiry int64, maxFee uint64, payload int) *Transaction {
+		tx := NewTx(
+			&Base{Timestamp: expiry, ChainID: chainID, MaxFee: maxFee},
+			[]Action{&testAction{payload: make([]byte, payload)}},
+		)
+		tx, err := tx.Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		return tx
+	}
+
+	const now = 100_000
+	var (
+		// Issued at [now]
+		newExpensive = newTx(now+60_000, 10_000, 10)
+		newCheap     = newTx(now+60_000, 1_000, 10)
+		newLarge     = newTx(now+60_000, 10_000, 64)
+
+		// Issued 30s before [now]
+		midExpensive = newTx(now+30_000, 10_000, 10)
+		midCheap     = newTx(now+30_000, 1_000, 10)
+
+		// Issued 60s before [now]
+		oldExpensive = newTx(now, 10_000, 10)
+		oldCheap     = newTx(now, 1_000, 10)
+	)
+
+	// Txs are listed from last to first to be evicted
+	ordered := []*Transaction{
+		newExpensive,
+		midExpensive,
+		oldExpensive,
+		newCheap,
+		midCheap,
+		oldCheap,
+	}
+	for i := 1; i < len(ordered); i++ {
+		require.Greater(TxEvictionScore(ordered[i-1], now, r), TxEvictionScore(ordered[i], now, r), "index %d", i)
+	}
+
+	// Larger transactions with the same fee are evicted first
+	require.Greater(TxEvictionScore(newExpensive, now, r), TxEvictionScore(newLarge, now, r))
+
+	// A transaction loses half of its priority over its validity window (and
+	// no more once it is older)
+	require.InEpsilon(TxEvictionScore(newExpensive, now, r)/2, TxEvictionScore(oldExpensive, now, r), 1e-9)
+	require.Equal(TxEvictionScore(oldExpensive, now, r), TxEvictionScore(oldExpensive, now+10_000, r))
+
+	// Scores decrease as transactions age
+	require.Greater(TxEvictionScore(midCheap, now, r), TxEvictionScore(midCheap, now+1, r))
+
+	// Scores are deterministic
+	require.Equal(TxEvictionScore(midCheap, now, r), TxEvictionScore(midCheap, now, r))
+}
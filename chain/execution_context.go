@@ -0,0 +1,146 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/math"
+
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// ExecutionContext contains everything derived from a parent block that is
+// required to build or verify a child at [Timestamp].
+type ExecutionContext struct {
+	ParentTimestamp int64
+	Timestamp       int64
+	Rules           Rules
+
+	// NextBlockCost is the minimum sum of fees that must be paid by the
+	// transactions executed by the child (0 if [Rules.GetTargetBlockRate] is
+	// 0).
+	NextBlockCost uint64
+
+	// parentFees are the unit prices and windows of the parent the context
+	// was derived from.
+	parentFees []byte
+
+	// fees are the unit prices and windows of the child before any of its
+	// transactions are executed.
+	fees []byte
+}
+
+// GenerateExecutionContext derives the [ExecutionContext] of a child at
+// [timestamp] from the fee state ([parentFees]) and timestamp of its parent.
+func GenerateExecutionContext(
+	parentFees []byte,
+	parentTimestamp int64,
+	timestamp int64,
+	r Rules,
+) (*ExecutionContext, error) {
+	feeManager, err := fees.NewManager(parentFees).ComputeNext(parentTimestamp, timestamp, r)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecutionContext{
+		ParentTimestamp: parentTimestamp,
+		Timestamp:       timestamp,
+		Rules:           r,
+		NextBlockCost:   feeManager.BlockCost(),
+		parentFees:      parentFees,
+		fees:            feeManager.Bytes(),
+	}, nil
+}
+
+// ReconstructContext returns the [ExecutionContext] that [b] should have been
+// built against, regenerated from the fee state and timestamp of its parent.
+//
+// This is meant for tooling that wants to explain the unit prices of a block
+// (the inverse of the window checks performed in verify). The parent must
+// have been processed by this node or be the last accepted block.
+func (b *StatelessBlock) ReconstructContext(ctx context.Context) (*ExecutionContext, error) {
+	if b.Hght == 0 {
+		return nil, errors.New("cannot reconstruct context of genesis block")
+	}
+	parent, err := b.vm.GetStatelessBlock(ctx, b.Prnt)
+	if err != nil {
+		return nil, err
+	}
+	parentFees, err := parent.postExecutionFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateExecutionContext(parentFees, parent.Tmstmp, b.Tmstmp, b.vm.Rules(b.Tmstmp))
+}
+
+// postExecutionFees returns the unit prices and windows of [b] after its
+// transactions were executed.
+func (b *StatelessBlock) postExecutionFees(ctx context.Context) ([]byte, error) {
+	if b.feeManager != nil {
+		return b.feeManager.Bytes(), nil
+	}
+
+	// If the block was not processed by this node, its fees are only
+	// available if it is the last accepted block.
+	if b.st != choices.Accepted || b.ID() != b.vm.LastAcceptedBlock().ID() {
+		return nil, fmt.Errorf("%w: %s", ErrBlockNotProcessed, b.ID())
+	}
+	acceptedState, err := b.vm.State()
+	if err != nil {
+		return nil, err
+	}
+	return acceptedState.GetValue(ctx, FeeKey(b.vm.StateManager().FeeKey()))
+}
+
+// verifyParent ensures [c] was derived from a parent with [parentFees] at
+// [parentTimestamp] for a child at [timestamp].
+func (c *ExecutionContext) verifyParent(parentFees []byte, parentTimestamp int64, timestamp int64) error {
+	if c.ParentTimestamp != parentTimestamp || c.Timestamp != timestamp {
+		return fmt.Errorf(
+			"%w: context=(%d, %d) block=(%d, %d)",
+			ErrInvalidBlockWindow,
+			c.ParentTimestamp,
+			c.Timestamp,
+			parentTimestamp,
+			timestamp,
+		)
+	}
+	if !bytes.Equal(c.parentFees, parentFees) {
+		return fmt.Errorf("%w: context derived from different parent fees", ErrInvalidUnitWindow)
+	}
+	return nil
+}
+
+// FeeManager returns a new [fees.Manager] initialized with the unit prices and
+// windows of the child. Because the manager is modified as transactions are
+// executed, a new one is returned on each call.
+func (c *ExecutionContext) FeeManager() *fees.Manager {
+	return fees.NewManager(slices.Clone(c.fees))
+}
+
+// VerifyBlockCost ensures that the fees paid by the transactions that produced
+// [results] (after refunds) cover [NextBlockCost].
+func (c *ExecutionContext) VerifyBlockCost(results []*Result) error {
+	if c.NextBlockCost == 0 {
+		return nil
+	}
+	paid := uint64(0)
+	for _, result := range results {
+		n, err := math.Add64(paid, result.Fee)
+		if err != nil {
+			return err
+		}
+		paid = n
+	}
+	if paid < c.NextBlockCost {
+		return fmt.Errorf("%w: paid=%d required=%d", ErrInsufficientSurplus, paid, c.NextBlockCost)
+	}
+	return nil
+}
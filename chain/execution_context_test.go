@@ -0,0 +1,249 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// pacingTestRules enables block pacing with a 2s target.
+type pacingTestRules struct {
+	Rules
+}
+
+func (*pacingTestRules) GetTargetBlockRate() int64             { return 2 }
+func (*pacingTestRules) GetBlockCostChangeDenominator() uint64 { return 8 }
+func (*pacingTestRules) GetMinBlockCost() uint64               { return 1_000 }
+func (*pacingTestRules) GetMaxBlockCost() uint64               { return 100_000 }
+
+// produceBlocks generates [n] execution contexts, each [gap] ms after the
+// last, and returns the final fee state, timestamp, and the cost of each
+// block.
+func produceBlocks(t *testing.T, r Rules, feeRaw []byte, tmstmp int64, gap int64, n int) ([]byte, int64, []uint64) {
+	costs := make([]uint64, n)
+	for i := 0; i < n; i++ {
+		ectx, err := GenerateExecutionContext(feeRaw, tmstmp, tmstmp+gap, r)
+		require.NoError(t, err)
+		feeRaw = ectx.FeeManager().Bytes()
+		tmstmp += gap
+		costs[i] = ectx.NextBlockCost
+	}
+	return feeRaw, tmstmp, costs
+}
+
+func TestExecutionContextBlockPacing(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	r := &pacingTestRules{newOfflineTestRules(ctrl, ids.Empty)}
+
+	// Blocks produced faster than the target increase the cost (until the max)
+	feeRaw, tmstmp, costs := produceBlocks(t, r, nil, 0, 500, 200)
+	require.Equal(r.GetMinBlockCost()+r.GetMinBlockCost()*1_500/2_000/8, costs[0])
+	for i := 1; i < len(costs); i++ {
+		require.GreaterOrEqual(costs[i], costs[i-1])
+		require.LessOrEqual(costs[i], r.GetMaxBlockCost())
+	}
+	require.Equal(r.GetMaxBlockCost(), costs[len(costs)-1])
+
+	// Blocks produced at the target do not change the cost
+	feeRaw, tmstmp, costs = produceBlocks(t, r, feeRaw, tmstmp, 2_000, 5)
+	for _, cost := range costs {
+		require.Equal(r.GetMaxBlockCost(), cost)
+	}
+
+	// Blocks produced slower than the target decrease the cost (until the min)
+	feeRaw, tmstmp, costs = produceBlocks(t, r, feeRaw, tmstmp, 4_000, 200)
+	require.Less(costs[0], r.GetMaxBlockCost())
+	for i := 1; i < len(costs); i++ {
+		require.LessOrEqual(costs[i], costs[i-1])
+		require.GreaterOrEqual(costs[i], r.GetMinBlockCost())
+	}
+	require.Equal(r.GetMinBlockCost(), costs[len(costs)-1])
+
+	// Long gaps decrease the cost more than short ones
+	feeRaw, _, costs = produceBlocks(t, r, feeRaw, tmstmp, 500, 50)
+	short, err := GenerateExecutionContext(feeRaw, tmstmp, tmstmp+3_000, r)
+	require.NoError(err)
+	long, err := GenerateExecutionContext(feeRaw, tmstmp, tmstmp+20_000, r)
+	require.NoError(err)
+	require.Less(short.NextBlockCost, costs[len(costs)-1])
+	require.Less(long.NextBlockCost, short.NextBlockCost)
+}
+
+func TestExecutionContextBlockPacingDisabled(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	r := newOfflineTestRules(ctrl, ids.Empty)
+
+	_, _, costs := produceBlocks(t, r, nil, 0, 100, 10)
+	for _, cost := range costs {
+		require.Zero(cost)
+	}
+	ectx, err := GenerateExecutionContext(nil, 0, 100, r)
+	require.NoError(err)
+	require.NoError(ectx.VerifyBlockCost(nil))
+}
+
+func TestVerifyBlockCost(t *testing.T) {
+	require := require.New(t)
+	ectx := &ExecutionContext{NextBlockCost: 100}
+
+	require.ErrorIs(ectx.VerifyBlockCost(nil), ErrInsufficientSurplus)
+	require.ErrorIs(ectx.VerifyBlockCost([]*Result{{Fee: 60}, {Fee: 39}}), ErrInsufficientSurplus)
+	require.NoError(ectx.VerifyBlockCost([]*Result{{Fee: 60}, {Fee: 40}}))
+}
+
+func TestVerifyWithContext(t *testing.T) {
+	ctx := context.TODO()
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+	genesisFees := fees.NewManager(nil).Bytes()
+	otherFees := fees.NewManager(nil)
+	otherFees.SetUnitPrice(fees.Bandwidth, 100)
+
+	tests := []struct {
+		name            string
+		parentFees      []byte
+		parentTimestamp int64
+		timestamp       int64
+		err             error
+	}{
+		{
+			name:       "matching context",
+			parentFees: genesisFees,
+			timestamp:  1_000,
+		},
+		{
+			name:       "wrong timestamp",
+			parentFees: genesisFees,
+			timestamp:  2_000,
+			err:        ErrInvalidBlockWindow,
+		},
+		{
+			name:            "wrong parent timestamp",
+			parentFees:      genesisFees,
+			parentTimestamp: 500,
+			timestamp:       1_000,
+			err:             ErrInvalidBlockWindow,
+		},
+		{
+			name:       "wrong parent fees",
+			parentFees: otherFees.Bytes(),
+			timestamp:  1_000,
+			err:        ErrInvalidUnitWindow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db, parentRoot := newOfflineTestState(ctx, require)
+			vm := &offlineTestVM{
+				r:            r,
+				lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+			}
+			blk, err := ParseStatefulBlock(ctx, &StatefulBlock{
+				Prnt:      ids.GenerateTestID(),
+				Tmstmp:    1_000,
+				Hght:      1,
+				Txs:       []*Transaction{},
+				StateRoot: parentRoot,
+			}, nil, choices.Processing, vm)
+			require.NoError(err)
+
+			ectx, err := GenerateExecutionContext(tt.parentFees, tt.parentTimestamp, tt.timestamp, r)
+			require.NoError(err)
+			err = blk.verifyWithContext(ctx, &offlineTestVerifyContext{db}, ectx)
+			require.ErrorIs(err, tt.err)
+			if tt.err != nil {
+				return
+			}
+
+			// The provided context produces the same result as the one
+			// generated by the VM
+			regenerated, err := ParseStatefulBlock(ctx, blk.StatefulBlock, nil, choices.Processing, vm)
+			require.NoError(err)
+			require.NoError(regenerated.innerVerify(ctx, &offlineTestVerifyContext{db}))
+			root, err := blk.view.GetMerkleRoot(ctx)
+			require.NoError(err)
+			expected, err := regenerated.view.GetMerkleRoot(ctx)
+			require.NoError(err)
+			require.Equal(expected, root)
+		})
+	}
+}
+
+func TestReconstructContext(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	// Start from prices above the minimum with consumption above the target,
+	// so the prices of each block differ from those of its parent
+	db, _ := newOfflineTestState(ctx, require)
+	genesisFees := fees.NewManager(nil)
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		genesisFees.SetUnitPrice(i, 100)
+		genesisFees.SetLastConsumed(i, 10_000)
+	}
+	require.NoError(db.Put(FeeKey((&testStateManager{}).FeeKey()), genesisFees.Bytes()))
+	parentRoot, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	vm := &forkTestVM{
+		offlineTestVM: offlineTestVM{
+			r:            newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID()),
+			lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+		},
+		blocks: map[ids.ID]*StatelessBlock{},
+	}
+	parent, err := ParseStatefulBlock(ctx, &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    1_000,
+		Hght:      1,
+		Txs:       []*Transaction{},
+		StateRoot: parentRoot,
+	}, nil, choices.Processing, vm)
+	require.NoError(err)
+	require.NoError(parent.innerVerify(ctx, &offlineTestVerifyContext{db}))
+	parentRoot, err = parent.view.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	blk, err := ParseStatefulBlock(ctx, &StatefulBlock{
+		Prnt:      parent.ID(),
+		Tmstmp:    2_000,
+		Hght:      2,
+		Txs:       []*Transaction{},
+		StateRoot: parentRoot,
+	}, nil, choices.Processing, vm)
+	require.NoError(err)
+	require.NoError(blk.innerVerify(ctx, &offlineTestVerifyContext{parent.view}))
+
+	// The parent must be available
+	_, err = blk.ReconstructContext(ctx)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// The reconstructed context has the unit prices used by the block
+	vm.blocks[parent.ID()] = parent
+	ectx, err := blk.ReconstructContext(ctx)
+	require.NoError(err)
+	require.Equal(parent.Tmstmp, ectx.ParentTimestamp)
+	require.Equal(blk.Tmstmp, ectx.Timestamp)
+	require.Equal(blk.FeeManager().UnitPrices(), ectx.FeeManager().UnitPrices())
+	require.NotEqual(parent.FeeManager().UnitPrices(), ectx.FeeManager().UnitPrices())
+
+	// The parent must have been processed
+	unprocessed, err := ParseStatefulBlock(ctx, parent.StatefulBlock, nil, choices.Processing, vm)
+	require.NoError(err)
+	vm.blocks[parent.ID()] = unprocessed
+	_, err = blk.ReconstructContext(ctx)
+	require.ErrorIs(err, ErrBlockNotProcessed)
+}
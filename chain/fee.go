@@ -0,0 +1,49 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// MinFee returns the minimum fee [tx] must pay to be included in the next
+// block built on top of the last accepted block.
+//
+// The unit prices of the next block are computed by applying the pricing
+// windows of the last accepted block to the earliest time the next block
+// could be produced. These prices are then combined with the units [tx]
+// will consume.
+func MinFee(ctx context.Context, vm VM, tx *Transaction) (uint64, error) {
+	parent := vm.LastAcceptedBlock()
+	nextTime := vm.Now().UnixMilli()
+	r := vm.Rules(nextTime)
+	if minTime := parent.Tmstmp + r.GetMinBlockGap(); nextTime < minTime {
+		nextTime = minTime
+		r = vm.Rules(nextTime)
+	}
+
+	// Compute next unit prices to use
+	state, err := vm.State()
+	if err != nil {
+		return 0, err
+	}
+	feeRaw, err := state.GetValue(ctx, FeeKey(vm.StateManager().FeeKey()))
+	if err != nil {
+		return 0, err
+	}
+	parentFeeManager := fees.NewManager(feeRaw)
+	feeManager, err := parentFeeManager.ComputeNext(parent.Tmstmp, nextTime, r)
+	if err != nil {
+		return 0, err
+	}
+
+	// Compute units that will be consumed by [tx]
+	units, err := tx.Units(vm.StateManager(), r, nextTime)
+	if err != nil {
+		return 0, err
+	}
+	return feeManager.Fee(units)
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// ForkDepth returns the common ancestor of [a] and [b] and the number of
+// blocks between it and the furthest of [a] and [b] (so siblings have a depth
+// of 1 and a block has a depth of 0 with itself).
+//
+// If the common ancestor is more than [MaxForkDepth] blocks away from either
+// block, [ErrForkTooDeep] is returned. If [a] and [b] have no common ancestor
+// (i.e. they descend from different genesis blocks), [ErrNoCommonAncestor] is
+// returned.
+func ForkDepth(ctx context.Context, vm VM, a, b *StatelessBlock) (int, ids.ID, error) {
+	var (
+		depthA int
+		depthB int
+		err    error
+	)
+	for a.ID() != b.ID() {
+		if max(depthA, depthB) >= MaxForkDepth {
+			return 0, ids.Empty, fmt.Errorf("%w: max=%d", ErrForkTooDeep, MaxForkDepth)
+		}
+
+		// Walk back the higher block until both are at the same height (and
+		// then walk back both)
+		stepA, stepB := a.Hght >= b.Hght, b.Hght >= a.Hght
+		if stepA && stepB && a.Hght == 0 {
+			return 0, ids.Empty, fmt.Errorf("%w: %s and %s", ErrNoCommonAncestor, a.ID(), b.ID())
+		}
+		if stepA {
+			a, err = vm.GetStatelessBlock(ctx, a.Prnt)
+			if err != nil {
+				return 0, ids.Empty, err
+			}
+			depthA++
+		}
+		if stepB {
+			b, err = vm.GetStatelessBlock(ctx, b.Prnt)
+			if err != nil {
+				return 0, ids.Empty, err
+			}
+			depthB++
+		}
+	}
+	return max(depthA, depthB), a.ID(), nil
+}
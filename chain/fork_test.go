@@ -0,0 +1,110 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// forkTestVM is an [offlineTestVM] that stores the blocks it parses.
+type forkTestVM struct {
+	offlineTestVM
+
+	blocks map[ids.ID]*StatelessBlock
+}
+
+func (vm *forkTestVM) GetStatelessBlock(_ context.Context, blkID ids.ID) (*StatelessBlock, error) {
+	blk, ok := vm.blocks[blkID]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return blk, nil
+}
+
+// extend adds [n] blocks on top of [parent] (or a new genesis block if nil)
+// and returns the last one.
+func (vm *forkTestVM) extend(require *require.Assertions, parent *StatelessBlock, n int) *StatelessBlock {
+	if parent == nil {
+		genesis := NewGenesisBlock(ids.GenerateTestID())
+		blk, err := ParseStatefulBlock(context.TODO(), genesis, nil, choices.Accepted, vm)
+		require.NoError(err)
+		vm.blocks[blk.ID()] = blk
+		parent = blk
+	}
+	for i := 0; i < n; i++ {
+		blk, err := ParseStatefulBlock(context.TODO(), &StatefulBlock{
+			Prnt:      parent.ID(),
+			Tmstmp:    parent.Tmstmp + 1_000,
+			Hght:      parent.Hght + 1,
+			Txs:       []*Transaction{},
+			StateRoot: ids.GenerateTestID(),
+		}, nil, choices.Accepted, vm)
+		require.NoError(err)
+		vm.blocks[blk.ID()] = blk
+		parent = blk
+	}
+	return parent
+}
+
+func TestForkDepth(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	vm := &forkTestVM{
+		offlineTestVM: offlineTestVM{r: newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())},
+		blocks:        map[ids.ID]*StatelessBlock{},
+	}
+
+	root := vm.extend(require, nil, 3)
+
+	// Same block
+	depth, ancestor, err := ForkDepth(ctx, vm, root, root)
+	require.NoError(err)
+	require.Zero(depth)
+	require.Equal(root.ID(), ancestor)
+
+	// Siblings
+	a := vm.extend(require, root, 1)
+	b := vm.extend(require, root, 1)
+	depth, ancestor, err = ForkDepth(ctx, vm, a, b)
+	require.NoError(err)
+	require.Equal(1, depth)
+	require.Equal(root.ID(), ancestor)
+
+	// Common ancestor 5 deep (on both sides and on one side)
+	a = vm.extend(require, root, 5)
+	b = vm.extend(require, root, 5)
+	depth, ancestor, err = ForkDepth(ctx, vm, a, b)
+	require.NoError(err)
+	require.Equal(5, depth)
+	require.Equal(root.ID(), ancestor)
+
+	b = vm.extend(require, root, 2)
+	depth, ancestor, err = ForkDepth(ctx, vm, b, a)
+	require.NoError(err)
+	require.Equal(5, depth)
+	require.Equal(root.ID(), ancestor)
+
+	// Ancestor of a block
+	depth, ancestor, err = ForkDepth(ctx, vm, root, a)
+	require.NoError(err)
+	require.Equal(5, depth)
+	require.Equal(root.ID(), ancestor)
+
+	// Unrelated chains
+	other := vm.extend(require, nil, 3)
+	_, _, err = ForkDepth(ctx, vm, a, other)
+	require.ErrorIs(err, ErrNoCommonAncestor)
+
+	// Walk is bounded
+	deep := vm.extend(require, root, MaxForkDepth+1)
+	_, _, err = ForkDepth(ctx, vm, root, deep)
+	require.ErrorIs(err, ErrForkTooDeep)
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// GasMeter tracks the compute units consumed by a [MeteredAction] (up to
+// the limit it declared with [Action.ComputeUnits]).
+type GasMeter struct {
+	limit    uint64
+	consumed uint64
+}
+
+func NewGasMeter(limit uint64) *GasMeter {
+	return &GasMeter{limit: limit}
+}
+
+// Consume consumes [units] or returns [ErrOutOfGas] (consuming all remaining
+// units) if fewer than [units] remain.
+func (m *GasMeter) Consume(units uint64) error {
+	if remaining := m.Remaining(); units > remaining {
+		m.consumed = m.limit
+		return fmt.Errorf("%w: requested=%d remaining=%d", ErrOutOfGas, units, remaining)
+	}
+	m.consumed += units
+	return nil
+}
+
+func (m *GasMeter) Limit() uint64 {
+	return m.limit
+}
+
+func (m *GasMeter) Consumed() uint64 {
+	return m.consumed
+}
+
+func (m *GasMeter) Remaining() uint64 {
+	return m.limit - m.consumed
+}
+
+// execute executes [action] (with a [GasMeter] limited to its
+// [Action.ComputeUnits] if it is a [MeteredAction]) and returns the compute
+// units it left unconsumed (0 if it is not metered) and the compute units it
+// refunded (0 if it is not a [RefundingAction]).
+func execute(
+	ctx context.Context,
+	action Action,
+	r Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, uint64, uint64, error) {
+	switch a := action.(type) {
+	case MeteredAction:
+		meter := NewGasMeter(action.ComputeUnits(r))
+		outputs, err := a.ExecuteMetered(ctx, r, mu, timestamp, actor, actionID, meter)
+		return outputs, meter.Remaining(), 0, err
+	case RefundingAction:
+		outputs, refund, err := a.ExecuteRefunding(ctx, r, mu, timestamp, actor, actionID)
+		return outputs, 0, min(refund, action.ComputeUnits(r)), err
+	default:
+		outputs, err := action.Execute(ctx, r, mu, timestamp, actor, actionID)
+		return outputs, 0, 0, err
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// meteredTestAction declares 10 compute units and consumes [consume] of them.
+type meteredTestAction struct {
+	consume uint64
+}
+
+func (*meteredTestAction) GetTypeID() uint8                           { return 0 }
+func (*meteredTestAction) ValidRange(Rules) (int64, int64)            { return -1, -1 }
+func (*meteredTestAction) Size() int                                  { return 8 }
+func (a *meteredTestAction) Marshal(p *codec.Packer)                  { p.PackUint64(a.consume) }
+func (*meteredTestAction) ComputeUnits(Rules) uint64                  { return 10 }
+func (*meteredTestAction) StateKeysMaxChunks() []uint16               { return nil }
+func (*meteredTestAction) StateKeys(codec.Address, ids.ID) state.Keys { return state.Keys{} }
+func (a *meteredTestAction) Execute(ctx context.Context, r Rules, mu state.Mutable, timestamp int64, actor codec.Address, actionID ids.ID) ([][]byte, error) {
+	return a.ExecuteMetered(ctx, r, mu, timestamp, actor, actionID, NewGasMeter(a.ComputeUnits(r)))
+}
+
+func (a *meteredTestAction) ExecuteMetered(_ context.Context, _ Rules, _ state.Mutable, _ int64, _ codec.Address, _ ids.ID, meter *GasMeter) ([][]byte, error) {
+	for i := uint64(0); i < a.consume; i++ {
+		if err := meter.Consume(1); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func TestGasMeter(t *testing.T) {
+	require := require.New(t)
+
+	meter := NewGasMeter(10)
+	require.NoError(meter.Consume(4))
+	require.NoError(meter.Consume(6))
+	require.Equal(uint64(10), meter.Consumed())
+	require.Zero(meter.Remaining())
+
+	// Consuming more than remains exhausts the meter
+	meter = NewGasMeter(10)
+	require.NoError(meter.Consume(4))
+	require.ErrorIs(meter.Consume(7), ErrOutOfGas)
+	require.Equal(uint64(10), meter.Consumed())
+}
+
+func TestMeteredAction(t *testing.T) {
+	const balance = 1_000
+	tests := []struct {
+		name     string
+		consume  uint64
+		success  bool
+		refunded uint64
+	}{
+		{
+			name:     "none",
+			consume:  0,
+			success:  true,
+			refunded: 10,
+		},
+		{
+			name:     "partial",
+			consume:  4,
+			success:  true,
+			refunded: 6,
+		},
+		{
+			name:    "all",
+			consume: 10,
+			success: true,
+		},
+		{
+			// Out of gas reverts the transaction (which pays the max fee)
+			name:    "exceeded",
+			consume: 11,
+		},
+	}
+	for _, tt := range tests {
+		for _, policy := range []RefundPolicy{RefundBurn, RefundCredit, RefundPool} {
+			t.Run(fmt.Sprintf("%s/policy=%d", tt.name, policy), func(t *testing.T) {
+				require := require.New(t)
+				ctx := context.TODO()
+				r := &refundTestRules{newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID()), policy}
+				sm := (&refundTestConfig{}).StateManager()
+				_, authRegistry := (&testParser{}).Registry()
+				actionRegistry := codec.NewTypeParser[Action, bool]()
+				require.NoError(actionRegistry.Register(0, func(p *codec.Packer) (Action, error) {
+					return &meteredTestAction{consume: p.UnpackUint64(false)}, p.Err()
+				}, false))
+				feeManager := fees.NewManager(nil)
+				for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+					feeManager.SetUnitPrice(i, 1)
+				}
+
+				factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+				s := taskTestState{string(refundTestBalanceKey(factory.actor)): binary.BigEndian.AppendUint64(nil, balance)}
+				tx, err := NewTx(
+					&Base{Timestamp: 1_000, ChainID: r.ChainID(), MaxFee: balance},
+					[]Action{&meteredTestAction{consume: tt.consume}},
+				).Sign(factory, actionRegistry, authRegistry)
+				require.NoError(err)
+				units, err := tx.Units(sm, r, 1_000)
+				require.NoError(err)
+				stateKeys, err := tx.StateKeys(sm, r)
+				require.NoError(err)
+				storage := map[string][]byte{}
+				for k := range stateKeys {
+					if v, ok := s[k]; ok {
+						storage[k] = v
+					}
+				}
+				tsv := tstate.New(0).NewView(stateKeys, storage)
+				result, err := tx.Execute(ctx, feeManager, sm, r, tsv, 1_000)
+				require.NoError(err)
+				require.Equal(tt.success, result.Success)
+				if !tt.success {
+					require.Contains(string(result.Error), ErrOutOfGas.Error())
+				}
+
+				// The sponsor only pays for the units consumed (regardless
+				// of the refund policy)
+				fee, err := feeManager.Fee(units)
+				require.NoError(err)
+				require.Equal(fee-tt.refunded, result.Fee)
+				require.Equal(tt.refunded, result.refund[fees.Compute])
+				require.Zero(result.refundFee)
+				remaining, err := (&refundTestStateManager{}).balance(ctx, tsv, factory.actor)
+				require.NoError(err)
+				require.Equal(balance-result.Fee, remaining)
+			})
+		}
+	}
+}
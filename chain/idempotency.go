@@ -0,0 +1,63 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// IdempotencyID returns the identifier of the operation performed by an
+// [IdempotentAction] with [typeID] and [key] (so that actions of different
+// types may use the same keys).
+func IdempotencyID(typeID uint8, key []byte) ids.ID {
+	b := make([]byte, 0, 1+len(key))
+	b = append(b, typeID)
+	b = append(b, key...)
+	return hashing.ComputeHash256Array(b)
+}
+
+// idempotencyKeys returns the state key recording each action of [t] that is
+// an [IdempotentAction] (or nil for actions that aren't or if
+// [Rules.GetEnforceIdempotencyKeys] is disabled).
+func (t *Transaction) idempotencyKeys(sm StateManager, r Rules) [][]byte {
+	if !r.GetEnforceIdempotencyKeys() {
+		return nil
+	}
+	var keys [][]byte
+	for i, action := range t.Actions {
+		a, ok := action.(IdempotentAction)
+		if !ok {
+			continue
+		}
+		key := a.IdempotencyKey()
+		if len(key) == 0 {
+			continue
+		}
+		if keys == nil {
+			keys = make([][]byte, len(t.Actions))
+		}
+		keys[i] = IdempotencyKey(sm.IdempotencyKey(IdempotencyID(action.GetTypeID(), key)))
+	}
+	return keys
+}
+
+// recordIdempotencyKey records that [txID] applied the operation at [key],
+// failing with [ErrDuplicateIdempotencyKey] if it was already applied.
+func recordIdempotencyKey(ctx context.Context, mu state.Mutable, key []byte, txID ids.ID) error {
+	_, err := mu.GetValue(ctx, key)
+	switch {
+	case err == nil:
+		return ErrDuplicateIdempotencyKey
+	case !errors.Is(err, database.ErrNotFound):
+		return err
+	}
+	return mu.Insert(ctx, key, txID[:])
+}
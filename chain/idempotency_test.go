@@ -0,0 +1,155 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// idempotentTestAction is a [parallelTestAction] that is only applied once
+// for each non-empty [key].
+type idempotentTestAction struct {
+	parallelTestAction
+
+	key []byte
+}
+
+func (*idempotentTestAction) GetTypeID() uint8 { return 3 }
+
+func (a *idempotentTestAction) Size() int {
+	return a.parallelTestAction.Size() + codec.BytesLen(a.key)
+}
+
+func (a *idempotentTestAction) Marshal(p *codec.Packer) {
+	a.parallelTestAction.Marshal(p)
+	p.PackBytes(a.key)
+}
+
+func (a *idempotentTestAction) IdempotencyKey() []byte { return a.key }
+
+// idempotencyTestRules overrides whether the rules enforce idempotency keys.
+type idempotencyTestRules struct {
+	Rules
+
+	enforce bool
+}
+
+func (r *idempotencyTestRules) GetEnforceIdempotencyKeys() bool { return r.enforce }
+
+func TestIdempotencyKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	c := &parallelTestConfig{cores: 4}
+	actionRegistry, authRegistry := c.Registry()
+	sm := &refundTestStateManager{}
+	recipient := codec.CreateAddress(0, ids.GenerateTestID())
+
+	newAction := func(key string) *idempotentTestAction {
+		return &idempotentTestAction{
+			parallelTestAction: parallelTestAction{to: recipient, amount: 1},
+			key:                []byte(key),
+		}
+	}
+	newTxs := func(require *require.Assertions) ([]*Transaction, taskTestState) {
+		s := make(taskTestState)
+		actions := [][]Action{
+			{newAction("invoice-1")},
+			{newAction("invoice-1")},                         // signed by a different actor
+			{newAction("invoice-2"), newAction("invoice-2")}, // fails (so isn't recorded)
+			{newAction("invoice-2")},
+			{newAction("")},
+			{newAction("")},
+		}
+		txs := make([]*Transaction, len(actions))
+		for i, a := range actions {
+			factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+			s[string(refundTestBalanceKey(factory.actor))] = binary.BigEndian.AppendUint64(nil, parallelTestBalance)
+			tx, err := NewTx(&Base{Timestamp: 1_000, ChainID: chainID, MaxFee: 1_000_000}, a).Sign(factory, actionRegistry, authRegistry)
+			require.NoError(err)
+			txs[i] = tx
+		}
+		return txs, s
+	}
+
+	tests := []struct {
+		name     string
+		enforce  bool
+		expected []error
+	}{
+		{
+			name:     "enforced",
+			enforce:  true,
+			expected: []error{nil, ErrDuplicateIdempotencyKey, ErrDuplicateIdempotencyKey, nil, nil, nil},
+		},
+		{
+			name:     "not enforced",
+			expected: []error{nil, nil, nil, nil, nil, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			r := &idempotencyTestRules{&multiActionTestRules{&parallelTestRules{newOfflineTestRules(ctrl, chainID), true}}, tt.enforce}
+			txs, s := newTxs(require)
+
+			results, post := executeParallelTestBlock(require, c, r, s, txs)
+			for i, err := range tt.expected {
+				if err == nil {
+					require.True(results[i].Success, "tx=%d", i)
+					continue
+				}
+				require.False(results[i].Success, "tx=%d", i)
+				require.Contains(string(results[i].Error), err.Error(), "tx=%d", i)
+			}
+
+			// The transaction that applied each operation is recorded
+			for key, tx := range map[string]int{"invoice-1": 0, "invoice-2": 3} {
+				v, ok := post[string(IdempotencyKey(sm.IdempotencyKey(IdempotencyID(3, []byte(key)))))]
+				require.Equal(tt.enforce, ok)
+				if ok {
+					require.Equal(txs[tx].ID(), ids.ID(v))
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyUnits(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	sm := &refundTestStateManager{}
+	factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+	actionRegistry, authRegistry := (&parallelTestConfig{}).Registry()
+	action := &idempotentTestAction{parallelTestAction: parallelTestAction{to: factory.actor, amount: 1}, key: []byte("invoice-1")}
+	key := string(IdempotencyKey(sm.IdempotencyKey(IdempotencyID(action.GetTypeID(), action.key))))
+
+	// Recording the key is declared (and charged) only if enforced
+	var units, estimated [2]fees.Dimensions
+	for i, enforce := range []bool{false, true} {
+		r := &idempotencyTestRules{newOfflineTestRules(ctrl, chainID), enforce}
+		tx, err := NewTx(&Base{Timestamp: 1_000, ChainID: chainID, MaxFee: 1_000_000}, []Action{action}).Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		stateKeys, err := tx.StateKeys(sm, r)
+		require.NoError(err)
+		_, ok := stateKeys[key]
+		require.Equal(enforce, ok)
+
+		units[i], err = tx.Units(sm, r, 0)
+		require.NoError(err)
+		estimated[i], err = EstimateUnits(r, tx.Actions, factory)
+		require.NoError(err)
+	}
+	require.Greater(units[1][fees.StorageAllocate], units[0][fees.StorageAllocate])
+	require.Greater(units[1][fees.StorageWrite], units[0][fees.StorageWrite])
+	require.Greater(estimated[1][fees.StorageWrite], estimated[0][fees.StorageWrite])
+}
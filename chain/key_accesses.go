@@ -0,0 +1,92 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// KeyAccessRecorder is given the [KeyAccesses] of each set of transactions
+// executed (if key access recording is enabled).
+type KeyAccessRecorder interface {
+	RecordKeyAccesses(*KeyAccesses)
+}
+
+// KeyAccess is the number of transactions that declared access to [Key].
+//
+// [Writes] counts transactions that may modify [Key] ([state.Write] or
+// [state.Allocate]) and [Reads] counts transactions that may only read it.
+type KeyAccess struct {
+	Key    string
+	Reads  int
+	Writes int
+}
+
+// KeyAccesses aggregates the [KeyAccess] of each state key declared by the
+// transactions executed together (i.e. in a block).
+//
+// Keys are counted by the permissions transactions declare (which is what the
+// executor serializes transactions on), so contended keys (like a shared fee
+// recipient) stand out even if execution fails.
+type KeyAccesses struct {
+	txs      int
+	accesses map[string]*KeyAccess
+}
+
+func NewKeyAccesses() *KeyAccesses {
+	return &KeyAccesses{accesses: map[string]*KeyAccess{}}
+}
+
+// Add records the accesses of a transaction that declared [stateKeys].
+func (k *KeyAccesses) Add(stateKeys state.Keys) {
+	k.txs++
+	for key, perm := range stateKeys {
+		access, ok := k.accesses[key]
+		if !ok {
+			access = &KeyAccess{Key: key}
+			k.accesses[key] = access
+		}
+		if perm == state.Read {
+			access.Reads++
+		} else {
+			access.Writes++
+		}
+	}
+}
+
+// Txs returns the number of transactions recorded.
+func (k *KeyAccesses) Txs() int {
+	return k.txs
+}
+
+// Get returns the [KeyAccess] of [key] (with no accesses if it was never
+// declared).
+func (k *KeyAccesses) Get(key []byte) KeyAccess {
+	if access, ok := k.accesses[string(key)]; ok {
+		return *access
+	}
+	return KeyAccess{Key: string(key)}
+}
+
+// Hottest returns (at most) the [n] most accessed keys, ordered by writes
+// and then reads (ties are broken by key).
+func (k *KeyAccesses) Hottest(n int) []KeyAccess {
+	hottest := make([]KeyAccess, 0, len(k.accesses))
+	for _, access := range k.accesses {
+		hottest = append(hottest, *access)
+	}
+	slices.SortFunc(hottest, func(a, b KeyAccess) int {
+		if c := cmp.Compare(b.Writes, a.Writes); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Reads, a.Reads); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return hottest[:min(n, len(hottest))]
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// keyAccessTestConfig records the [KeyAccesses] of each execution.
+type keyAccessTestConfig struct {
+	parallelTestConfig
+
+	recorded []*KeyAccesses
+}
+
+func (c *keyAccessTestConfig) GetKeyAccessRecorder() KeyAccessRecorder { return c }
+
+func (c *keyAccessTestConfig) RecordKeyAccesses(accesses *KeyAccesses) {
+	c.recorded = append(c.recorded, accesses)
+}
+
+func TestKeyAccesses(t *testing.T) {
+	const (
+		disjoint    = 8
+		conflicting = 16
+	)
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	c := &keyAccessTestConfig{parallelTestConfig: parallelTestConfig{cores: 4}}
+	r := &parallelTestRules{newOfflineTestRules(ctrl, chainID), true}
+	txs, s := newParallelTestBlock(require, &c.parallelTestConfig, chainID, disjoint, conflicting)
+
+	// Key accesses are not recorded by default...
+	executeParallelTestBlock(require, &c.parallelTestConfig, r, s, txs)
+	require.Empty(c.recorded)
+
+	// ...but when they are, each tx that writes the shared recipient is
+	// counted
+	feeManager := fees.NewManager(nil)
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		feeManager.SetUnitPrice(i, 1)
+	}
+	results, _, err := executeTxs(context.TODO(), trace.Noop, c, s, feeManager, r, txs, 0, 1_000, 0, nil, ids.Empty)
+	require.NoError(err)
+	for _, result := range results {
+		require.True(result.Success)
+	}
+	require.Len(c.recorded, 1)
+	accesses := c.recorded[0]
+	require.Equal(disjoint+conflicting, accesses.Txs())
+
+	shared := refundTestBalanceKey(txs[len(txs)-1].Actions[0].(*parallelTestAction).to)
+	require.Equal(conflicting, accesses.Get(shared).Writes)
+	require.Zero(accesses.Get(shared).Reads)
+	hottest := accesses.Hottest(2)
+	require.Equal(string(shared), hottest[0].Key)
+	require.Equal(1, hottest[1].Writes)
+
+	// Each sender is only written by its own tx
+	sender := refundTestBalanceKey(txs[0].Auth.Actor())
+	require.Equal(1, accesses.Get(sender).Writes)
+}
+
+func TestKeyAccessesHottest(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		hot  = string(refundTestBalanceKey(codec.CreateAddress(0, ids.GenerateTestID())))
+		warm = string(refundTestBalanceKey(codec.CreateAddress(0, ids.GenerateTestID())))
+		read = string(refundTestBalanceKey(codec.CreateAddress(0, ids.GenerateTestID())))
+	)
+	accesses := NewKeyAccesses()
+	for i := 0; i < 3; i++ {
+		accesses.Add(state.Keys{hot: state.Write, read: state.Read})
+	}
+	accesses.Add(state.Keys{warm: state.Allocate, read: state.Read})
+	require.Equal(4, accesses.Txs())
+	require.Equal(KeyAccess{Key: hot, Writes: 3}, accesses.Get([]byte(hot)))
+	require.Equal(KeyAccess{Key: read, Reads: 4}, accesses.Get([]byte(read)))
+	require.Equal(KeyAccess{Key: "missing"}, accesses.Get([]byte("missing")))
+
+	// Keys are ordered by writes and then reads
+	require.Equal([]KeyAccess{
+		{Key: hot, Writes: 3},
+		{Key: warm, Writes: 1},
+	}, accesses.Hottest(2))
+	require.Len(accesses.Hottest(10), 3)
+}
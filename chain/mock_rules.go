@@ -16,6 +16,7 @@ import (
 	reflect "reflect"
 
 	ids "github.com/ava-labs/avalanchego/ids"
+	codec "github.com/ava-labs/hypersdk/codec"
 	fees "github.com/ava-labs/hypersdk/fees"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -72,6 +73,20 @@ func (mr *MockRulesMockRecorder) FetchCustom(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchCustom", reflect.TypeOf((*MockRules)(nil).FetchCustom), arg0)
 }
 
+// GetACLAdmin mocks base method.
+func (m *MockRules) GetACLAdmin() codec.Address {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetACLAdmin")
+	ret0, _ := ret[0].(codec.Address)
+	return ret0
+}
+
+// GetACLAdmin indicates an expected call of GetACLAdmin.
+func (mr *MockRulesMockRecorder) GetACLAdmin() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetACLAdmin", reflect.TypeOf((*MockRules)(nil).GetACLAdmin))
+}
+
 // GetBaseComputeUnits mocks base method.
 func (m *MockRules) GetBaseComputeUnits() uint64 {
 	m.ctrl.T.Helper()
@@ -86,6 +101,104 @@ func (mr *MockRulesMockRecorder) GetBaseComputeUnits() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBaseComputeUnits", reflect.TypeOf((*MockRules)(nil).GetBaseComputeUnits))
 }
 
+// GetBlockCostChangeDenominator mocks base method.
+func (m *MockRules) GetBlockCostChangeDenominator() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockCostChangeDenominator")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetBlockCostChangeDenominator indicates an expected call of GetBlockCostChangeDenominator.
+func (mr *MockRulesMockRecorder) GetBlockCostChangeDenominator() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockCostChangeDenominator", reflect.TypeOf((*MockRules)(nil).GetBlockCostChangeDenominator))
+}
+
+// GetDelayedExecution mocks base method.
+func (m *MockRules) GetDelayedExecution() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelayedExecution")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetDelayedExecution indicates an expected call of GetDelayedExecution.
+func (mr *MockRulesMockRecorder) GetDelayedExecution() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelayedExecution", reflect.TypeOf((*MockRules)(nil).GetDelayedExecution))
+}
+
+// GetEnforceIdempotencyKeys mocks base method.
+func (m *MockRules) GetEnforceIdempotencyKeys() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnforceIdempotencyKeys")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetEnforceIdempotencyKeys indicates an expected call of GetEnforceIdempotencyKeys.
+func (mr *MockRulesMockRecorder) GetEnforceIdempotencyKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnforceIdempotencyKeys", reflect.TypeOf((*MockRules)(nil).GetEnforceIdempotencyKeys))
+}
+
+// GetEpochLength mocks base method.
+func (m *MockRules) GetEpochLength() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEpochLength")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetEpochLength indicates an expected call of GetEpochLength.
+func (mr *MockRulesMockRecorder) GetEpochLength() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEpochLength", reflect.TypeOf((*MockRules)(nil).GetEpochLength))
+}
+
+// GetForkActivations mocks base method.
+func (m *MockRules) GetForkActivations() ForkActivations {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForkActivations")
+	ret0, _ := ret[0].(ForkActivations)
+	return ret0
+}
+
+// GetForkActivations indicates an expected call of GetForkActivations.
+func (mr *MockRulesMockRecorder) GetForkActivations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForkActivations", reflect.TypeOf((*MockRules)(nil).GetForkActivations))
+}
+
+// GetIncludeResultsRoot mocks base method.
+func (m *MockRules) GetIncludeResultsRoot() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncludeResultsRoot")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetIncludeResultsRoot indicates an expected call of GetIncludeResultsRoot.
+func (mr *MockRulesMockRecorder) GetIncludeResultsRoot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncludeResultsRoot", reflect.TypeOf((*MockRules)(nil).GetIncludeResultsRoot))
+}
+
+// GetMaxActionOutputBytes mocks base method.
+func (m *MockRules) GetMaxActionOutputBytes() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxActionOutputBytes")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetMaxActionOutputBytes indicates an expected call of GetMaxActionOutputBytes.
+func (mr *MockRulesMockRecorder) GetMaxActionOutputBytes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxActionOutputBytes", reflect.TypeOf((*MockRules)(nil).GetMaxActionOutputBytes))
+}
+
 // GetMaxActionsPerTx mocks base method.
 func (m *MockRules) GetMaxActionsPerTx() byte {
 	m.ctrl.T.Helper()
@@ -100,6 +213,20 @@ func (mr *MockRulesMockRecorder) GetMaxActionsPerTx() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxActionsPerTx", reflect.TypeOf((*MockRules)(nil).GetMaxActionsPerTx))
 }
 
+// GetMaxBlockCost mocks base method.
+func (m *MockRules) GetMaxBlockCost() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxBlockCost")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetMaxBlockCost indicates an expected call of GetMaxBlockCost.
+func (mr *MockRulesMockRecorder) GetMaxBlockCost() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxBlockCost", reflect.TypeOf((*MockRules)(nil).GetMaxBlockCost))
+}
+
 // GetMaxBlockUnits mocks base method.
 func (m *MockRules) GetMaxBlockUnits() fees.Dimensions {
 	m.ctrl.T.Helper()
@@ -128,6 +255,34 @@ func (mr *MockRulesMockRecorder) GetMaxOutputsPerAction() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxOutputsPerAction", reflect.TypeOf((*MockRules)(nil).GetMaxOutputsPerAction))
 }
 
+// GetMaxRepeatCheckDepth mocks base method.
+func (m *MockRules) GetMaxRepeatCheckDepth() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxRepeatCheckDepth")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetMaxRepeatCheckDepth indicates an expected call of GetMaxRepeatCheckDepth.
+func (mr *MockRulesMockRecorder) GetMaxRepeatCheckDepth() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxRepeatCheckDepth", reflect.TypeOf((*MockRules)(nil).GetMaxRepeatCheckDepth))
+}
+
+// GetMinBlockCost mocks base method.
+func (m *MockRules) GetMinBlockCost() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMinBlockCost")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetMinBlockCost indicates an expected call of GetMinBlockCost.
+func (mr *MockRulesMockRecorder) GetMinBlockCost() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinBlockCost", reflect.TypeOf((*MockRules)(nil).GetMinBlockCost))
+}
+
 // GetMinBlockGap mocks base method.
 func (m *MockRules) GetMinBlockGap() int64 {
 	m.ctrl.T.Helper()
@@ -142,6 +297,34 @@ func (mr *MockRulesMockRecorder) GetMinBlockGap() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinBlockGap", reflect.TypeOf((*MockRules)(nil).GetMinBlockGap))
 }
 
+// GetMinDistinctSigners mocks base method.
+func (m *MockRules) GetMinDistinctSigners() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMinDistinctSigners")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetMinDistinctSigners indicates an expected call of GetMinDistinctSigners.
+func (mr *MockRulesMockRecorder) GetMinDistinctSigners() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinDistinctSigners", reflect.TypeOf((*MockRules)(nil).GetMinDistinctSigners))
+}
+
+// GetMinDistinctSignersTxs mocks base method.
+func (m *MockRules) GetMinDistinctSignersTxs() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMinDistinctSignersTxs")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetMinDistinctSignersTxs indicates an expected call of GetMinDistinctSignersTxs.
+func (mr *MockRulesMockRecorder) GetMinDistinctSignersTxs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinDistinctSignersTxs", reflect.TypeOf((*MockRules)(nil).GetMinDistinctSignersTxs))
+}
+
 // GetMinEmptyBlockGap mocks base method.
 func (m *MockRules) GetMinEmptyBlockGap() int64 {
 	m.ctrl.T.Helper()
@@ -170,6 +353,76 @@ func (mr *MockRulesMockRecorder) GetMinUnitPrice() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinUnitPrice", reflect.TypeOf((*MockRules)(nil).GetMinUnitPrice))
 }
 
+// GetParallelExecution mocks base method.
+func (m *MockRules) GetParallelExecution() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParallelExecution")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetParallelExecution indicates an expected call of GetParallelExecution.
+func (mr *MockRulesMockRecorder) GetParallelExecution() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParallelExecution", reflect.TypeOf((*MockRules)(nil).GetParallelExecution))
+}
+
+// GetPermutePendingTxs mocks base method.
+func (m *MockRules) GetPermutePendingTxs() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermutePendingTxs")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetPermutePendingTxs indicates an expected call of GetPermutePendingTxs.
+func (mr *MockRulesMockRecorder) GetPermutePendingTxs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermutePendingTxs", reflect.TypeOf((*MockRules)(nil).GetPermutePendingTxs))
+}
+
+// GetPricingMode mocks base method.
+func (m *MockRules) GetPricingMode() fees.PricingMode {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPricingMode")
+	ret0, _ := ret[0].(fees.PricingMode)
+	return ret0
+}
+
+// GetPricingMode indicates an expected call of GetPricingMode.
+func (mr *MockRulesMockRecorder) GetPricingMode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPricingMode", reflect.TypeOf((*MockRules)(nil).GetPricingMode))
+}
+
+// GetRefundPolicy mocks base method.
+func (m *MockRules) GetRefundPolicy() RefundPolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundPolicy")
+	ret0, _ := ret[0].(RefundPolicy)
+	return ret0
+}
+
+// GetRefundPolicy indicates an expected call of GetRefundPolicy.
+func (mr *MockRulesMockRecorder) GetRefundPolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundPolicy", reflect.TypeOf((*MockRules)(nil).GetRefundPolicy))
+}
+
+// GetRestrictBuilders mocks base method.
+func (m *MockRules) GetRestrictBuilders() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRestrictBuilders")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetRestrictBuilders indicates an expected call of GetRestrictBuilders.
+func (mr *MockRulesMockRecorder) GetRestrictBuilders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestrictBuilders", reflect.TypeOf((*MockRules)(nil).GetRestrictBuilders))
+}
+
 // GetSponsorStateKeysMaxChunks mocks base method.
 func (m *MockRules) GetSponsorStateKeysMaxChunks() []uint16 {
 	m.ctrl.T.Helper()
@@ -184,6 +437,20 @@ func (mr *MockRulesMockRecorder) GetSponsorStateKeysMaxChunks() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSponsorStateKeysMaxChunks", reflect.TypeOf((*MockRules)(nil).GetSponsorStateKeysMaxChunks))
 }
 
+// GetStateFetchRetries mocks base method.
+func (m *MockRules) GetStateFetchRetries() uint8 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStateFetchRetries")
+	ret0, _ := ret[0].(uint8)
+	return ret0
+}
+
+// GetStateFetchRetries indicates an expected call of GetStateFetchRetries.
+func (mr *MockRulesMockRecorder) GetStateFetchRetries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateFetchRetries", reflect.TypeOf((*MockRules)(nil).GetStateFetchRetries))
+}
+
 // GetStorageKeyAllocateUnits mocks base method.
 func (m *MockRules) GetStorageKeyAllocateUnits() uint64 {
 	m.ctrl.T.Helper()
@@ -268,6 +535,20 @@ func (mr *MockRulesMockRecorder) GetStorageValueWriteUnits() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorageValueWriteUnits", reflect.TypeOf((*MockRules)(nil).GetStorageValueWriteUnits))
 }
 
+// GetTargetBlockRate mocks base method.
+func (m *MockRules) GetTargetBlockRate() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTargetBlockRate")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// GetTargetBlockRate indicates an expected call of GetTargetBlockRate.
+func (mr *MockRulesMockRecorder) GetTargetBlockRate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTargetBlockRate", reflect.TypeOf((*MockRules)(nil).GetTargetBlockRate))
+}
+
 // GetUnitPriceChangeDenominator mocks base method.
 func (m *MockRules) GetUnitPriceChangeDenominator() fees.Dimensions {
 	m.ctrl.T.Helper()
@@ -282,6 +563,20 @@ func (mr *MockRulesMockRecorder) GetUnitPriceChangeDenominator() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnitPriceChangeDenominator", reflect.TypeOf((*MockRules)(nil).GetUnitPriceChangeDenominator))
 }
 
+// GetUtilizationEMADenominator mocks base method.
+func (m *MockRules) GetUtilizationEMADenominator() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUtilizationEMADenominator")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetUtilizationEMADenominator indicates an expected call of GetUtilizationEMADenominator.
+func (mr *MockRulesMockRecorder) GetUtilizationEMADenominator() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUtilizationEMADenominator", reflect.TypeOf((*MockRules)(nil).GetUtilizationEMADenominator))
+}
+
 // GetValidityWindow mocks base method.
 func (m *MockRules) GetValidityWindow() int64 {
 	m.ctrl.T.Helper()
@@ -310,6 +605,34 @@ func (mr *MockRulesMockRecorder) GetWindowTargetUnits() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWindowTargetUnits", reflect.TypeOf((*MockRules)(nil).GetWindowTargetUnits))
 }
 
+// IsActionRestricted mocks base method.
+func (m *MockRules) IsActionRestricted(arg0 uint8) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsActionRestricted", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsActionRestricted indicates an expected call of IsActionRestricted.
+func (mr *MockRulesMockRecorder) IsActionRestricted(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsActionRestricted", reflect.TypeOf((*MockRules)(nil).IsActionRestricted), arg0)
+}
+
+// IsPaused mocks base method.
+func (m *MockRules) IsPaused(arg0 int64) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPaused", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsPaused indicates an expected call of IsPaused.
+func (mr *MockRulesMockRecorder) IsPaused(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPaused", reflect.TypeOf((*MockRules)(nil).IsPaused), arg0)
+}
+
 // NetworkID mocks base method.
 func (m *MockRules) NetworkID() uint32 {
 	m.ctrl.T.Helper()
@@ -0,0 +1,213 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+
+	"github.com/ava-labs/hypersdk/executor"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+var _ executionConfig = (*offlineConfig)(nil)
+
+// offlineConfig executes transactions without a [VM].
+type offlineConfig struct {
+	sm             StateManager
+	actionRegistry ActionRegistry
+}
+
+func (c *offlineConfig) StateManager() StateManager {
+	return c.sm
+}
+
+func (c *offlineConfig) Registry() (ActionRegistry, AuthRegistry) {
+	return c.actionRegistry, nil
+}
+
+func (*offlineConfig) GetStateFetchConcurrency() int {
+	return runtime.NumCPU()
+}
+
+func (*offlineConfig) GetTransactionExecutionCores() int {
+	return runtime.NumCPU()
+}
+
+func (*offlineConfig) GetExecutorVerifyRecorder() executor.Metrics {
+	return nil
+}
+
+func (*offlineConfig) GetKeyAccessRecorder() KeyAccessRecorder {
+	return nil
+}
+
+func (*offlineConfig) RecordActionPanic() {}
+
+// VerifyOffline verifies [blk] on top of [parentState] (whose root must be
+// [parentRoot]) without a [VM] and returns the root of the post-execution
+// state of [blk]. This allows tools to audit a block given only its parent's
+// state.
+//
+// Because the ancestry of [blk] is not available, transactions are not checked
+// for replays outside of [blk]. Blocks that execute (or include) transactions
+// in delayed execution mode can't be verified offline because the transactions
+// of the parent are not available. Blocks that start an epoch can't be
+// verified offline because the validator set is not available.
+//
+// [actionRegistry] is used to parse the callbacks of any [Task]s that are due.
+func VerifyOffline(
+	ctx context.Context,
+	blk *StatefulBlock,
+	parentRoot ids.ID,
+	parentState merkledb.Trie,
+	sm StateManager,
+	actionRegistry ActionRegistry,
+	r Rules,
+) (ids.ID, error) {
+	if r.GetDelayedExecution() {
+		return ids.Empty, fmt.Errorf("%w: delayed execution", ErrUnsupportedExecution)
+	}
+	if IsEpochStart(r, blk.Hght) {
+		return ids.Empty, fmt.Errorf("%w: epoch start", ErrUnsupportedExecution)
+	}
+
+	// Ensure [parentState] is the state [blk] was built on
+	root, err := parentState.GetMerkleRoot(ctx)
+	if err != nil {
+		return ids.Empty, err
+	}
+	if root != parentRoot {
+		return ids.Empty, fmt.Errorf("%w: expected=%s found=%s", ErrStateRootMismatch, parentRoot, root)
+	}
+	if blk.StateRoot != parentRoot {
+		return ids.Empty, fmt.Errorf("%w: expected=%s found=%s", ErrStateRootMismatch, parentRoot, blk.StateRoot)
+	}
+
+	// Ensure block height and timestamp are valid
+	heightKey := HeightKey(sm.HeightKey())
+	parentHeightRaw, err := parentState.GetValue(ctx, heightKey)
+	if err != nil {
+		return ids.Empty, err
+	}
+	if blk.Hght != binary.BigEndian.Uint64(parentHeightRaw)+1 {
+		return ids.Empty, ErrInvalidBlockHeight
+	}
+	timestampKey := TimestampKey(sm.TimestampKey())
+	parentTimestampRaw, err := parentState.GetValue(ctx, timestampKey)
+	if err != nil {
+		return ids.Empty, err
+	}
+	parentTimestamp := int64(binary.BigEndian.Uint64(parentTimestampRaw))
+	if blk.Tmstmp < parentTimestamp+r.GetMinBlockGap() {
+		return ids.Empty, ErrTimestampTooEarly
+	}
+	if len(blk.Txs) == 0 && blk.Tmstmp < parentTimestamp+r.GetMinEmptyBlockGap() {
+		return ids.Empty, ErrTimestampTooEarly
+	}
+	if err := verifyPaused(r, blk.Tmstmp, blk.Txs); err != nil {
+		return ids.Empty, err
+	}
+	if err := verifySigners(r, blk.Txs); err != nil {
+		return ids.Empty, err
+	}
+
+	// Ensure there are no duplicate transactions and all signatures are valid
+	txsSet := set.NewSet[ids.ID](len(blk.Txs))
+	for _, tx := range blk.Txs {
+		if txsSet.Contains(tx.ID()) {
+			return ids.Empty, ErrDuplicateTx
+		}
+		txsSet.Add(tx.ID())
+		digest, err := tx.Digest()
+		if err != nil {
+			return ids.Empty, err
+		}
+		if err := tx.Auth.Verify(ctx, digest); err != nil {
+			return ids.Empty, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+		}
+	}
+
+	// Compute next unit prices to use
+	feeKey := FeeKey(sm.FeeKey())
+	feeRaw, err := parentState.GetValue(ctx, feeKey)
+	if err != nil {
+		return ids.Empty, err
+	}
+	parentFeeManager := fees.NewManager(feeRaw)
+	feeManager, err := parentFeeManager.ComputeNext(parentTimestamp, blk.Tmstmp, r)
+	if err != nil {
+		return ids.Empty, err
+	}
+
+	// Process transactions
+	results, ts, err := executeTxs(
+		ctx,
+		trace.Noop,
+		&offlineConfig{sm, actionRegistry},
+		parentState,
+		feeManager,
+		r,
+		blk.Txs,
+		0,
+		blk.Tmstmp,
+		blk.Tmstmp,
+		nil,
+		parentRoot,
+	)
+	if err != nil {
+		return ids.Empty, err
+	}
+	resultsRoot := ids.Empty
+	if r.GetIncludeResultsRoot() {
+		resultsRoot, err = ResultsRoot(workers.NewSerial(), results)
+		if err != nil {
+			return ids.Empty, err
+		}
+	}
+	if blk.ResultsRoot != resultsRoot {
+		return ids.Empty, fmt.Errorf("%w: expected=%s found=%s", ErrResultsRootMismatch, resultsRoot, blk.ResultsRoot)
+	}
+
+	// Update chain metadata
+	heightKeyStr := string(heightKey)
+	timestampKeyStr := string(timestampKey)
+	feeKeyStr := string(feeKey)
+
+	keys := make(state.Keys)
+	keys.Add(heightKeyStr, state.Write)
+	keys.Add(timestampKeyStr, state.Write)
+	keys.Add(feeKeyStr, state.Write)
+	tsv := ts.NewView(keys, map[string][]byte{
+		heightKeyStr:    parentHeightRaw,
+		timestampKeyStr: parentTimestampRaw,
+		feeKeyStr:       parentFeeManager.Bytes(),
+	})
+	if err := tsv.Insert(ctx, heightKey, binary.BigEndian.AppendUint64(nil, blk.Hght)); err != nil {
+		return ids.Empty, err
+	}
+	if err := tsv.Insert(ctx, timestampKey, binary.BigEndian.AppendUint64(nil, uint64(blk.Tmstmp))); err != nil {
+		return ids.Empty, err
+	}
+	if err := tsv.Insert(ctx, feeKey, feeManager.Bytes()); err != nil {
+		return ids.Empty, err
+	}
+	tsv.Commit()
+
+	// Compute the post-execution root of [blk]
+	view, err := ts.ExportMerkleDBView(ctx, trace.Noop, parentState)
+	if err != nil {
+		return ids.Empty, err
+	}
+	return view.GetMerkleRoot(ctx)
+}
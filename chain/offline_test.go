@@ -0,0 +1,236 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/executor"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+// offlineTestVM implements the subset of [VM] used to parse and verify a block.
+type offlineTestVM struct {
+	VM
+
+	r            Rules
+	lastAccepted *StatelessBlock
+
+	// acceptedState is the accepted state blocks commit to when catching up
+	// (if nil, [CatchUpIncrementalRoots] is disabled).
+	acceptedState merkledb.MerkleDB
+
+	// parentState is the view returned by the context of [GetVerifyContext].
+	parentState state.View
+}
+
+func (*offlineTestVM) Logger() logging.Logger                      { return logging.NoLog{} }
+func (*offlineTestVM) VerifyLogger() logging.Logger                { return logging.NoLog{} }
+func (*offlineTestVM) Tracer() trace.Tracer                        { return trace.Noop }
+func (vm *offlineTestVM) Rules(int64) Rules                        { return vm.r }
+func (*offlineTestVM) StateManager() StateManager                  { return &testStateManager{} }
+func (*offlineTestVM) Registry() (ActionRegistry, AuthRegistry)    { return (&testParser{}).Registry() }
+func (*offlineTestVM) AuthVerifiers() workers.Workers              { return workers.NewSerial() }
+func (*offlineTestVM) GetVerifyAuth() bool                         { return true }
+func (vm *offlineTestVM) LastAcceptedBlock() *StatelessBlock       { return vm.lastAccepted }
+func (*offlineTestVM) ShadowRootComputer() RootComputer            { return nil }
+func (*offlineTestVM) BlockProfiler() BlockProfiler                { return nil }
+func (*offlineTestVM) StatePrefixes() *StatePrefixRegistry         { return nil }
+func (*offlineTestVM) GetTransactionExecutionCores() int           { return 1 }
+func (*offlineTestVM) GetStateFetchConcurrency() int               { return 1 }
+func (*offlineTestVM) GetExecutorVerifyRecorder() executor.Metrics { return nil }
+func (*offlineTestVM) GetKeyAccessRecorder() KeyAccessRecorder     { return nil }
+func (*offlineTestVM) RecordWaitRoot(time.Duration)                {}
+func (*offlineTestVM) RecordWaitSignatures(time.Duration)          {}
+func (*offlineTestVM) RecordRootCalculated(time.Duration)          {}
+func (*offlineTestVM) RecordStateChanges(int)                      {}
+func (*offlineTestVM) RecordStateOperations(int)                   {}
+func (*offlineTestVM) RecordActionPanic()                          {}
+func (*offlineTestVM) Now() time.Time                              { return time.Now() }
+func (*offlineTestVM) BeginAccept() func()                         { return func() {} }
+
+func (vm *offlineTestVM) CatchUpIncrementalRoots() bool {
+	return vm.acceptedState != nil
+}
+
+func (vm *offlineTestVM) State() (merkledb.MerkleDB, error) {
+	return vm.acceptedState, nil
+}
+
+func (*offlineTestVM) CommitState(ctx context.Context, _ *StatelessBlock, view merkledb.View) error {
+	return view.CommitToDB(ctx)
+}
+
+func (vm *offlineTestVM) GetExecutionContext(_ ids.ID, parentFees []byte, parentTimestamp int64, timestamp int64) (*ExecutionContext, error) {
+	return GenerateExecutionContext(parentFees, parentTimestamp, timestamp, vm.r)
+}
+
+func (vm *offlineTestVM) GetVerifyContext(context.Context, uint64, ids.ID) (VerifyContext, error) {
+	return &offlineTestVerifyContext{vm.parentState}, nil
+}
+
+func (*offlineTestVM) GetAuthBatchVerifier(uint8, int, int) (AuthBatchVerifier, bool) {
+	return nil, false
+}
+
+type offlineTestVerifyContext struct {
+	view state.View
+}
+
+func (c *offlineTestVerifyContext) View(context.Context, bool) (state.View, error) {
+	return c.view, nil
+}
+
+func (*offlineTestVerifyContext) IsRepeat(_ context.Context, _ int64, _ []*Transaction, marker set.Bits, _ bool) (set.Bits, error) {
+	return marker, nil
+}
+
+func newOfflineTestRules(ctrl *gomock.Controller, chainID ids.ID) *MockRules {
+	r := NewMockRules(ctrl)
+	r.EXPECT().ChainID().Return(chainID).AnyTimes()
+	r.EXPECT().GetMinBlockGap().Return(int64(100)).AnyTimes()
+	r.EXPECT().GetMinEmptyBlockGap().Return(int64(100)).AnyTimes()
+	r.EXPECT().GetValidityWindow().Return(int64(60_000)).AnyTimes()
+	r.EXPECT().GetMaxRepeatCheckDepth().Return(0).AnyTimes()
+	r.EXPECT().GetMaxActionsPerTx().Return(uint8(1)).AnyTimes()
+	r.EXPECT().GetMaxOutputsPerAction().Return(uint8(1)).AnyTimes()
+	r.EXPECT().GetMaxActionOutputBytes().Return(uint64(testMaxActionOutputBytes)).AnyTimes()
+	r.EXPECT().GetMaxBlockUnits().Return(fees.Dimensions{1_000_000, 1_000_000, 1_000_000, 1_000_000, 1_000_000}).AnyTimes()
+	r.EXPECT().GetWindowTargetUnits().Return(fees.Dimensions{1_000, 1_000, 1_000, 1_000, 1_000}).AnyTimes()
+	r.EXPECT().GetUnitPriceChangeDenominator().Return(fees.Dimensions{48, 48, 48, 48, 48}).AnyTimes()
+	r.EXPECT().GetMinUnitPrice().Return(fees.Dimensions{1, 1, 1, 1, 1}).AnyTimes()
+	r.EXPECT().GetPricingMode().Return(fees.WindowPricing).AnyTimes()
+	r.EXPECT().GetUtilizationEMADenominator().Return(uint64(8)).AnyTimes()
+	r.EXPECT().GetTargetBlockRate().Return(int64(0)).AnyTimes()
+	r.EXPECT().GetBlockCostChangeDenominator().Return(uint64(48)).AnyTimes()
+	r.EXPECT().GetMinBlockCost().Return(uint64(0)).AnyTimes()
+	r.EXPECT().GetMaxBlockCost().Return(uint64(0)).AnyTimes()
+	r.EXPECT().GetBaseComputeUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageKeyReadUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageKeyAllocateUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageKeyWriteUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueReadUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueAllocateUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueWriteUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetSponsorStateKeysMaxChunks().Return([]uint16{}).AnyTimes()
+	r.EXPECT().GetRestrictBuilders().Return(false).AnyTimes()
+	r.EXPECT().GetIncludeResultsRoot().Return(false).AnyTimes()
+	r.EXPECT().GetDelayedExecution().Return(false).AnyTimes()
+	r.EXPECT().GetPermutePendingTxs().Return(false).AnyTimes()
+	r.EXPECT().GetStateFetchRetries().Return(uint8(0)).AnyTimes()
+	r.EXPECT().GetRefundPolicy().Return(RefundBurn).AnyTimes()
+	r.EXPECT().GetEnforceIdempotencyKeys().Return(false).AnyTimes()
+	r.EXPECT().GetParallelExecution().Return(true).AnyTimes()
+	r.EXPECT().GetEpochLength().Return(uint64(0)).AnyTimes()
+	r.EXPECT().GetMinDistinctSigners().Return(0).AnyTimes()
+	r.EXPECT().GetMinDistinctSignersTxs().Return(0).AnyTimes()
+	r.EXPECT().IsActionRestricted(gomock.Any()).Return(false).AnyTimes()
+	r.EXPECT().IsPaused(gomock.Any()).Return(false).AnyTimes()
+	r.EXPECT().GetForkActivations().Return(nil).AnyTimes()
+	return r
+}
+
+// newOfflineTestState initializes a [merkledb.MerkleDB] with the metadata of
+// a genesis block and returns it with its root.
+func newOfflineTestState(ctx context.Context, require *require.Assertions) (merkledb.MerkleDB, ids.ID) {
+	db, err := merkledb.New(ctx, memdb.New(), merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor16,
+		RootGenConcurrency:          1,
+		HistoryLength:               100,
+		ValueNodeCacheSize:          units.MiB,
+		IntermediateNodeCacheSize:   units.MiB,
+		IntermediateWriteBufferSize: units.KiB,
+		IntermediateWriteBatchSize:  units.KiB,
+		Tracer:                      trace.Noop,
+	})
+	require.NoError(err)
+	sm := &testStateManager{}
+	genesisView, err := db.NewView(ctx, merkledb.ViewChanges{MapOps: map[string]maybe.Maybe[[]byte]{
+		string(HeightKey(sm.HeightKey())):       maybe.Some(binary.BigEndian.AppendUint64(nil, 0)),
+		string(TimestampKey(sm.TimestampKey())): maybe.Some(binary.BigEndian.AppendUint64(nil, 0)),
+		string(FeeKey(sm.FeeKey())):             maybe.Some(fees.NewManager(nil).Bytes()),
+	}})
+	require.NoError(err)
+	require.NoError(genesisView.CommitToDB(ctx))
+	root, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	return db, root
+}
+
+func TestVerifyOffline(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+
+	// Initialize parent state
+	db, parentRoot := newOfflineTestState(ctx, require)
+	sm := &testStateManager{}
+
+	// Create block
+	var (
+		chainID                      = ids.GenerateTestID()
+		r                            = newOfflineTestRules(ctrl, chainID)
+		actionRegistry, authRegistry = (&testParser{}).Registry()
+		factory                      = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+	)
+	blk := &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    1_000,
+		Hght:      1,
+		Txs:       []*Transaction{},
+		StateRoot: parentRoot,
+	}
+	for i := 0; i < 3; i++ {
+		tx := NewTx(
+			&Base{Timestamp: 2_000, ChainID: chainID, MaxFee: 1_000_000},
+			[]Action{&testAction{payload: []byte{byte(i)}}},
+		)
+		tx, err := tx.Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		blk.Txs = append(blk.Txs, tx)
+	}
+
+	// Verify offline
+	offlineRoot, err := VerifyOffline(ctx, blk, parentRoot, db, sm, actionRegistry, r)
+	require.NoError(err)
+
+	// Verify in the VM
+	vm := &offlineTestVM{
+		r:            r,
+		lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+	}
+	sblk, err := ParseStatefulBlock(ctx, blk, nil, choices.Processing, vm)
+	require.NoError(err)
+	require.NoError(sblk.innerVerify(ctx, &offlineTestVerifyContext{db}))
+	vmRoot, err := sblk.view.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(vmRoot, offlineRoot)
+
+	// Offline verification fails with the wrong parent state root
+	_, err = VerifyOffline(ctx, blk, ids.GenerateTestID(), db, sm, actionRegistry, r)
+	require.ErrorIs(err, ErrStateRootMismatch)
+
+	// Offline verification fails with an invalid height
+	blk.Hght = 2
+	_, err = VerifyOffline(ctx, blk, parentRoot, db, sm, actionRegistry, r)
+	require.ErrorIs(err, ErrInvalidBlockHeight)
+}
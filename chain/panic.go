@@ -0,0 +1,62 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// FrameworkPanic is panicked by framework code that can't continue (as
+// opposed to a bug in an [Action]). It is never contained by
+// [ContainPanicsFork], so it always crashes the node.
+type FrameworkPanic struct {
+	Err error
+}
+
+func (p *FrameworkPanic) Error() string {
+	return "framework panic: " + p.Err.Error()
+}
+
+func (p *FrameworkPanic) Unwrap() error {
+	return p.Err
+}
+
+// executeAction executes [action] and returns the compute units it left
+// unconsumed (see [MeteredAction]) and refunded (see [RefundingAction]). If
+// [contain] is set, a panic raised while executing it is returned as
+// [ErrActionPanicked] (and the caller must roll back [ts]).
+//
+// A [FrameworkPanic], or a panic that interrupted an update of [ts] (which
+// can't be rolled back), is re-panicked. The panic value is not included in
+// the error because it may differ between nodes (i.e. if it formats a
+// pointer).
+func executeAction(
+	ctx context.Context,
+	action Action,
+	r Rules,
+	ts *tstate.TStateView,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+	contain bool,
+) (outputs [][]byte, unused uint64, refunded uint64, err error) {
+	if contain {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if _, ok := v.(*FrameworkPanic); ok || ts.Interrupted() {
+				panic(v)
+			}
+			outputs, unused, refunded, err = nil, 0, 0, ErrActionPanicked
+		}()
+	}
+	return execute(ctx, action, r, ts, timestamp, actor, actionID)
+}
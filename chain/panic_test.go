@@ -0,0 +1,229 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"maps"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+var errPanicTestFramework = errors.New("framework failed")
+
+// panicTestAction credits [account] and then panics (with a [FrameworkPanic]
+// if [framework] is set).
+type panicTestAction struct {
+	account   codec.Address
+	framework bool
+}
+
+func (*panicTestAction) GetTypeID() uint8                { return 2 }
+func (*panicTestAction) ValidRange(Rules) (int64, int64) { return -1, -1 }
+func (*panicTestAction) Size() int                       { return codec.AddressLen + 1 }
+func (*panicTestAction) ComputeUnits(Rules) uint64       { return 1 }
+func (*panicTestAction) StateKeysMaxChunks() []uint16    { return []uint16{1} }
+
+func (a *panicTestAction) Marshal(p *codec.Packer) {
+	p.PackAddress(a.account)
+	p.PackBool(a.framework)
+}
+
+func (a *panicTestAction) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{string(refundTestBalanceKey(a.account)): state.All}
+}
+
+func (a *panicTestAction) Execute(ctx context.Context, _ Rules, mu state.Mutable, _ int64, _ codec.Address, _ ids.ID) ([][]byte, error) {
+	if err := (&refundTestStateManager{}).Refund(ctx, a.account, mu, 1); err != nil {
+		return nil, err
+	}
+	if a.framework {
+		panic(&FrameworkPanic{Err: errPanicTestFramework})
+	}
+	var outputs [][]byte
+	return [][]byte{outputs[1]}, nil // index out of range
+}
+
+// panicTestConfig registers [panicTestAction] and counts the actions that
+// panicked.
+type panicTestConfig struct {
+	parallelTestConfig
+
+	panics atomic.Int64
+}
+
+func (c *panicTestConfig) RecordActionPanic() { c.panics.Add(1) }
+
+func (*panicTestConfig) Registry() (ActionRegistry, AuthRegistry) {
+	_, authRegistry := (&testParser{}).Registry()
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	_ = actionRegistry.Register(2, func(p *codec.Packer) (Action, error) {
+		a := &panicTestAction{}
+		p.UnpackAddress(&a.account)
+		a.framework = p.UnpackBool()
+		return a, p.Err()
+	}, false)
+	return actionRegistry, authRegistry
+}
+
+// panicTestRules activates [ContainPanicsFork] (if [contain] is set).
+type panicTestRules struct {
+	*parallelTestRules
+
+	contain bool
+}
+
+func (r *panicTestRules) GetForkActivations() ForkActivations {
+	if !r.contain {
+		return nil
+	}
+	return ForkActivations{ContainPanicsFork: 0}
+}
+
+// newPanicTestTx returns a tx (from a funded actor) with a [panicTestAction]
+// crediting [account].
+func newPanicTestTx(
+	require *require.Assertions,
+	c *panicTestConfig,
+	chainID ids.ID,
+	s taskTestState,
+	account codec.Address,
+	framework bool,
+) *Transaction {
+	actionRegistry, authRegistry := c.Registry()
+	factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+	s[string(refundTestBalanceKey(factory.actor))] = binary.BigEndian.AppendUint64(nil, parallelTestBalance)
+	tx, err := NewTx(
+		&Base{Timestamp: 1_000, ChainID: chainID, MaxFee: 1_000_000},
+		[]Action{&panicTestAction{account: account, framework: framework}},
+	).Sign(factory, actionRegistry, authRegistry)
+	require.NoError(err)
+	return tx
+}
+
+// executePanicTestBlock executes [txs] on top of a copy of [s] and returns
+// the resulting state.
+func executePanicTestBlock(
+	require *require.Assertions,
+	c *panicTestConfig,
+	r Rules,
+	s taskTestState,
+	txs []*Transaction,
+) ([]*Result, taskTestState) {
+	feeManager := fees.NewManager(nil)
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		feeManager.SetUnitPrice(i, 1)
+	}
+	results, ts, err := executeTxs(context.TODO(), trace.Noop, c, s, feeManager, r, txs, 0, 1_000, 0, nil, ids.Empty)
+	require.NoError(err)
+	post := maps.Clone(s)
+	post.apply(ts)
+	return results, post
+}
+
+func TestContainActionPanics(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	c := &panicTestConfig{parallelTestConfig: parallelTestConfig{cores: 8}}
+
+	// Panicking txs are interleaved with transfers
+	txs, s := newParallelTestBlock(require, &c.parallelTestConfig, chainID, 32, 8)
+	account := codec.CreateAddress(0, ids.GenerateTestID())
+	s[string(refundTestBalanceKey(account))] = binary.BigEndian.AppendUint64(nil, 1)
+	panicking := map[int]bool{}
+	for _, i := range []int{0, 17, 35, len(txs)} {
+		txs = append(txs[:i], append([]*Transaction{newPanicTestTx(require, c, chainID, s, account, false)}, txs[i:]...)...)
+	}
+	for i, tx := range txs {
+		if _, ok := tx.Actions[0].(*panicTestAction); ok {
+			panicking[i] = true
+		}
+	}
+	require.Len(panicking, 4)
+
+	// The block is still valid and executes identically regardless of the
+	// number of cores
+	var (
+		r             = &panicTestRules{&parallelTestRules{newOfflineTestRules(ctrl, chainID), false}, true}
+		serialResults []*Result
+		serialState   taskTestState
+	)
+	for _, parallel := range []bool{false, true} {
+		r.parallel = parallel
+		results, post := executePanicTestBlock(require, c, r, s, txs)
+		if serialResults == nil {
+			serialResults, serialState = results, post
+			continue
+		}
+		require.Equal(serialResults, results)
+		require.Equal(serialState, post)
+	}
+	require.Equal(int64(2*len(panicking)), c.panics.Load())
+
+	// Each panicking tx fails (but is charged a fee) and its credit is
+	// rolled back
+	for i, result := range serialResults {
+		if !panicking[i] {
+			require.True(result.Success)
+			continue
+		}
+		require.False(result.Success)
+		require.Equal(resultError(ErrActionPanicked), result.Error)
+		require.Empty(result.Outputs)
+		require.Positive(result.Fee)
+		sponsor := txs[i].Auth.Sponsor()
+		require.Equal(binary.BigEndian.AppendUint64(nil, parallelTestBalance-result.Fee), serialState[string(refundTestBalanceKey(sponsor))])
+	}
+	require.Equal(binary.BigEndian.AppendUint64(nil, 1), serialState[string(refundTestBalanceKey(account))])
+}
+
+func TestUncontainedActionPanics(t *testing.T) {
+	tests := []struct {
+		name      string
+		contain   bool
+		framework bool
+	}{
+		{
+			name: "before fork",
+		},
+		{
+			name:      "framework panic",
+			contain:   true,
+			framework: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+			chainID := ids.GenerateTestID()
+			c := &panicTestConfig{}
+			s := make(taskTestState)
+			tx := newPanicTestTx(require, c, chainID, s, codec.CreateAddress(0, ids.GenerateTestID()), tt.framework)
+			r := &panicTestRules{&parallelTestRules{newOfflineTestRules(ctrl, chainID), false}, tt.contain}
+
+			// The panic is raised to the caller
+			sm := c.StateManager()
+			stateKeys, err := tx.StateKeys(sm, r)
+			require.NoError(err)
+			tsv := tstate.New(1).NewView(stateKeys, s)
+			require.Panics(func() {
+				_, _ = tx.Execute(context.TODO(), fees.NewManager(nil), sm, r, tsv, 1_000)
+			})
+		})
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+const parallelTestBalance = 1_000_000_000
+
+// parallelTestAction transfers [amount] from the actor to [to].
+type parallelTestAction struct {
+	to     codec.Address
+	amount uint64
+}
+
+func (*parallelTestAction) GetTypeID() uint8                { return 0 }
+func (*parallelTestAction) ValidRange(Rules) (int64, int64) { return -1, -1 }
+func (*parallelTestAction) Size() int                       { return codec.AddressLen + 8 }
+func (*parallelTestAction) ComputeUnits(Rules) uint64       { return 1 }
+func (*parallelTestAction) StateKeysMaxChunks() []uint16    { return []uint16{1, 1} }
+
+func (a *parallelTestAction) Marshal(p *codec.Packer) {
+	p.PackAddress(a.to)
+	p.PackUint64(a.amount)
+}
+
+func (a *parallelTestAction) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
+	return state.Keys{
+		string(refundTestBalanceKey(actor)): state.Read | state.Write,
+		string(refundTestBalanceKey(a.to)):  state.All,
+	}
+}
+
+func (a *parallelTestAction) Execute(ctx context.Context, _ Rules, mu state.Mutable, _ int64, actor codec.Address, _ ids.ID) ([][]byte, error) {
+	sm := &refundTestStateManager{}
+	if err := sm.Deduct(ctx, actor, mu, a.amount); err != nil {
+		return nil, err
+	}
+	return nil, sm.Refund(ctx, a.to, mu, a.amount)
+}
+
+// balanceTestAction reads the balance of [account] or, if [credit] is
+// non-zero, credits [account] with [credit]. It only declares the
+// permission it needs.
+type balanceTestAction struct {
+	account codec.Address
+	credit  uint64
+}
+
+func (*balanceTestAction) GetTypeID() uint8                { return 1 }
+func (*balanceTestAction) ValidRange(Rules) (int64, int64) { return -1, -1 }
+func (*balanceTestAction) Size() int                       { return codec.AddressLen + 8 }
+func (*balanceTestAction) ComputeUnits(Rules) uint64       { return 1 }
+func (*balanceTestAction) StateKeysMaxChunks() []uint16    { return []uint16{1} }
+
+func (a *balanceTestAction) Marshal(p *codec.Packer) {
+	p.PackAddress(a.account)
+	p.PackUint64(a.credit)
+}
+
+func (a *balanceTestAction) StateKeys(codec.Address, ids.ID) state.Keys {
+	if a.credit == 0 {
+		return state.Keys{string(refundTestBalanceKey(a.account)): state.Read}
+	}
+	return state.Keys{string(refundTestBalanceKey(a.account)): state.Write}
+}
+
+func (a *balanceTestAction) Execute(ctx context.Context, _ Rules, mu state.Mutable, _ int64, _ codec.Address, _ ids.ID) ([][]byte, error) {
+	sm := &refundTestStateManager{}
+	if a.credit == 0 {
+		bal, err := sm.balance(ctx, mu, a.account)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{binary.BigEndian.AppendUint64(nil, bal)}, nil
+	}
+	return nil, sm.Refund(ctx, a.account, mu, a.credit)
+}
+
+// parallelTestConfig executes [parallelTestAction]s on [cores] cores.
+type parallelTestConfig struct {
+	refundTestConfig
+
+	cores int
+}
+
+func (c *parallelTestConfig) GetTransactionExecutionCores() int { return c.cores }
+
+func (*parallelTestConfig) Registry() (ActionRegistry, AuthRegistry) {
+	_, authRegistry := (&testParser{}).Registry()
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	_ = actionRegistry.Register(0, func(p *codec.Packer) (Action, error) {
+		a := &parallelTestAction{}
+		p.UnpackAddress(&a.to)
+		a.amount = p.UnpackUint64(true)
+		return a, p.Err()
+	}, false)
+	_ = actionRegistry.Register(1, func(p *codec.Packer) (Action, error) {
+		a := &balanceTestAction{}
+		p.UnpackAddress(&a.account)
+		a.credit = p.UnpackUint64(false)
+		return a, p.Err()
+	}, false)
+	_ = actionRegistry.Register(2, func(p *codec.Packer) (Action, error) {
+		a := &commutativeTestAction{}
+		p.UnpackAddress(&a.to)
+		a.amount = p.UnpackUint64(false)
+		a.read = p.UnpackBool()
+		return a, p.Err()
+	}, false)
+	_ = actionRegistry.Register(3, func(p *codec.Packer) (Action, error) {
+		a := &idempotentTestAction{}
+		p.UnpackAddress(&a.to)
+		a.amount = p.UnpackUint64(true)
+		p.UnpackBytes(-1, false, &a.key)
+		return a, p.Err()
+	}, false)
+	return actionRegistry, authRegistry
+}
+
+// parallelTestRules overrides whether the rules returned by
+// [newOfflineTestRules] allow parallel execution.
+type parallelTestRules struct {
+	Rules
+
+	parallel bool
+}
+
+func (r *parallelTestRules) GetParallelExecution() bool { return r.parallel }
+
+// multiActionTestRules allows 2 actions per tx.
+type multiActionTestRules struct {
+	*parallelTestRules
+}
+
+func (*multiActionTestRules) GetMaxActionsPerTx() uint8 { return 2 }
+
+// newParallelTestBlock returns [disjoint] transfers between distinct accounts
+// followed by [conflicting] transfers to the same account (and the state
+// funding all senders).
+func newParallelTestBlock(require *require.Assertions, c *parallelTestConfig, chainID ids.ID, disjoint int, conflicting int) ([]*Transaction, taskTestState) {
+	var (
+		actionRegistry, authRegistry = c.Registry()
+
+		s      = make(taskTestState)
+		shared = codec.CreateAddress(0, ids.GenerateTestID())
+		txs    = make([]*Transaction, 0, disjoint+conflicting)
+	)
+	for i := 0; i < disjoint+conflicting; i++ {
+		factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		s[string(refundTestBalanceKey(factory.actor))] = binary.BigEndian.AppendUint64(nil, parallelTestBalance)
+		to := codec.CreateAddress(0, ids.GenerateTestID())
+		if i >= disjoint {
+			to = shared
+		}
+		tx := NewTx(
+			&Base{Timestamp: 1_000, ChainID: chainID, MaxFee: 1_000_000},
+			[]Action{&parallelTestAction{to: to, amount: uint64(i) + 1}},
+		)
+		tx, err := tx.Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		txs = append(txs, tx)
+	}
+	return txs, s
+}
+
+// executeParallelTestBlock executes [txs] on top of a copy of [s] and returns
+// the resulting state.
+func executeParallelTestBlock(
+	require *require.Assertions,
+	c *parallelTestConfig,
+	r Rules,
+	s taskTestState,
+	txs []*Transaction,
+) ([]*Result, taskTestState) {
+	feeManager := fees.NewManager(nil)
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		feeManager.SetUnitPrice(i, 1)
+	}
+	results, ts, err := executeTxs(context.TODO(), trace.Noop, c, s, feeManager, r, txs, 0, 1_000, 0, nil, ids.Empty)
+	require.NoError(err)
+	post := maps.Clone(s)
+	post.apply(ts)
+	return results, post
+}
+
+func TestParallelExecution(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	c := &parallelTestConfig{cores: 8}
+	txs, s := newParallelTestBlock(require, c, chainID, 256, 16)
+
+	// Executing transactions in parallel must produce the same results and
+	// state (and thus state root) as executing them serially
+	serialResults, serialState := executeParallelTestBlock(require, c, &parallelTestRules{newOfflineTestRules(ctrl, chainID), false}, s, txs)
+	parallelResults, parallelState := executeParallelTestBlock(require, c, &parallelTestRules{newOfflineTestRules(ctrl, chainID), true}, s, txs)
+	require.Equal(serialResults, parallelResults)
+	require.Equal(serialState, parallelState)
+
+	// Transfers were applied
+	for _, result := range parallelResults {
+		require.True(result.Success)
+	}
+	action := txs[len(txs)-1].Actions[0].(*parallelTestAction)
+	bal, err := (&refundTestStateManager{}).balance(context.TODO(), parallelState, action.to)
+	require.NoError(err)
+	require.Equal(uint64((257+272)*16/2), bal)
+}
+
+func BenchmarkParallelExecution(b *testing.B) {
+	for _, parallel := range []bool{false, true} {
+		b.Run(fmt.Sprintf("parallel=%t", parallel), func(b *testing.B) {
+			require := require.New(b)
+			chainID := ids.GenerateTestID()
+			c := &parallelTestConfig{cores: 8}
+			r := &parallelTestRules{newOfflineTestRules(gomock.NewController(b), chainID), parallel}
+			txs, s := newParallelTestBlock(require, c, chainID, 1_024, 0)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				executeParallelTestBlock(require, c, r, s, txs)
+			}
+		})
+	}
+}
+
+func TestDuplicateStateKeys(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	c := &parallelTestConfig{cores: 8}
+	actionRegistry, authRegistry := c.Registry()
+
+	// Each tx reads and credits the same account (in either order), declaring
+	// its balance as [state.Read] and [state.Write] respectively
+	var (
+		s      = make(taskTestState)
+		shared = codec.CreateAddress(0, ids.GenerateTestID())
+		txs    = make([]*Transaction, 16)
+	)
+	s[string(refundTestBalanceKey(shared))] = binary.BigEndian.AppendUint64(nil, parallelTestBalance)
+	for i := range txs {
+		factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		s[string(refundTestBalanceKey(factory.actor))] = binary.BigEndian.AppendUint64(nil, parallelTestBalance)
+		actions := []Action{&balanceTestAction{account: shared}, &balanceTestAction{account: shared, credit: 1}}
+		if i%2 == 1 {
+			actions[0], actions[1] = actions[1], actions[0]
+		}
+		tx, err := NewTx(&Base{Timestamp: 1_000, ChainID: chainID, MaxFee: 1_000_000}, actions).Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		txs[i] = tx
+	}
+
+	// The key is leased as [state.Write]
+	r := &multiActionTestRules{&parallelTestRules{newOfflineTestRules(ctrl, chainID), true}}
+	stateKeys, err := txs[0].StateKeys(c.StateManager(), r)
+	require.NoError(err)
+	require.Equal(state.Write, stateKeys[string(refundTestBalanceKey(shared))])
+
+	// Conflicting txs are executed one at a time, so every credit is applied
+	// and each read observes the credits of all previous txs
+	results, post := executeParallelTestBlock(require, c, r, s, txs)
+	for i, result := range results {
+		require.True(result.Success)
+		read := result.Outputs[0]
+		if i%2 == 1 {
+			read = result.Outputs[1]
+		}
+		expected := uint64(parallelTestBalance + i)
+		if i%2 == 1 {
+			expected++
+		}
+		require.Equal([][]byte{binary.BigEndian.AppendUint64(nil, expected)}, read)
+	}
+	bal, err := (&refundTestStateManager{}).balance(context.TODO(), post, shared)
+	require.NoError(err)
+	require.Equal(uint64(parallelTestBalance+len(txs)), bal)
+}
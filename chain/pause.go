@@ -0,0 +1,38 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import "fmt"
+
+// isAdmin returns true if [t] may be included while the chain is paused
+// (it is submitted by [Rules.GetACLAdmin] and only contains [AdminAction]s).
+func (t *Transaction) isAdmin(r Rules) bool {
+	if t.Auth.Actor() != r.GetACLAdmin() {
+		return false
+	}
+	for _, action := range t.Actions {
+		if _, ok := action.(AdminAction); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyPaused ensures that [txs] can be included at [timestamp]. If the
+// chain is paused (see [Rules.IsPaused]), every transaction must be an admin
+// transaction.
+//
+// Blocks without transactions are always allowed, so time continues to
+// progress while paused.
+func verifyPaused(r Rules, timestamp int64, txs []*Transaction) error {
+	if !r.IsPaused(timestamp) {
+		return nil
+	}
+	for i, tx := range txs {
+		if !tx.isAdmin(r) {
+			return fmt.Errorf("%w: tx %s at index %d is not an admin tx", ErrChainPaused, tx.ID(), i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+// pauseTestRules overrides whether the chain is paused (and the admin) of the
+// rules returned by [newOfflineTestRules].
+type pauseTestRules struct {
+	Rules
+
+	paused bool
+	admin  codec.Address
+}
+
+func (r *pauseTestRules) IsPaused(int64) bool        { return r.paused }
+func (r *pauseTestRules) GetACLAdmin() codec.Address { return r.admin }
+
+// adminTestAction is a [testAction] that is an [AdminAction].
+type adminTestAction struct {
+	testAction
+}
+
+func (*adminTestAction) GetTypeID() uint8 { return 1 }
+func (*adminTestAction) AdminAction()     {}
+
+func unmarshalAdminTestAction(p *codec.Packer) (Action, error) {
+	a, err := unmarshalTestAction(p)
+	if err != nil {
+		return nil, err
+	}
+	return &adminTestAction{testAction: *a.(*testAction)}, nil
+}
+
+func TestPausedChain(t *testing.T) {
+	var (
+		admin = codec.CreateAddress(0, ids.GenerateTestID())
+		user  = codec.CreateAddress(0, ids.GenerateTestID())
+	)
+	tests := []struct {
+		name   string
+		paused bool
+		actor  codec.Address
+		action Action // if nil, the block is empty
+		err    error
+	}{
+		{
+			name:   "unpaused",
+			actor:  user,
+			action: &testAction{},
+		},
+		{
+			name:   "unpaused admin",
+			actor:  admin,
+			action: &adminTestAction{},
+		},
+		{
+			name:   "paused",
+			paused: true,
+			actor:  user,
+			action: &testAction{},
+			err:    ErrChainPaused,
+		},
+		{
+			name:   "paused admin",
+			paused: true,
+			actor:  admin,
+			action: &adminTestAction{},
+		},
+		{
+			name:   "paused admin action from user",
+			paused: true,
+			actor:  user,
+			action: &adminTestAction{},
+			err:    ErrChainPaused,
+		},
+		{
+			name:   "paused non-admin action from admin",
+			paused: true,
+			actor:  admin,
+			action: &testAction{},
+			err:    ErrChainPaused,
+		},
+		{
+			name:   "paused empty",
+			paused: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+
+			db, parentRoot := newOfflineTestState(ctx, require)
+			var (
+				chainID                      = ids.GenerateTestID()
+				r                            = &pauseTestRules{newOfflineTestRules(gomock.NewController(t), chainID), tt.paused, admin}
+				actionRegistry, authRegistry = (&testParser{}).Registry()
+			)
+			require.NoError((*codec.TypeParser[Action, bool])(actionRegistry).Register(1, unmarshalAdminTestAction, false))
+			blk := &StatefulBlock{
+				Prnt:      ids.GenerateTestID(),
+				Tmstmp:    1_000,
+				Hght:      1,
+				Txs:       []*Transaction{},
+				StateRoot: parentRoot,
+			}
+			if tt.action != nil {
+				tx, err := NewTx(
+					&Base{Timestamp: 2_000, ChainID: chainID, MaxFee: 1_000_000},
+					[]Action{tt.action},
+				).Sign(&testAuthFactory{actor: tt.actor}, actionRegistry, authRegistry)
+				require.NoError(err)
+				blk.Txs = append(blk.Txs, tx)
+			}
+
+			// The block is rejected (or accepted) by the VM and offline
+			vm := &offlineTestVM{
+				r:            r,
+				lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+			}
+			sblk, err := ParseStatefulBlock(ctx, blk, nil, choices.Processing, vm)
+			require.NoError(err)
+			require.ErrorIs(sblk.innerVerify(ctx, &offlineTestVerifyContext{db}), tt.err)
+			_, err = VerifyOffline(ctx, blk, parentRoot, db, &testStateManager{}, actionRegistry, r)
+			require.ErrorIs(err, tt.err)
+		})
+	}
+}
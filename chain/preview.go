@@ -0,0 +1,147 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/math"
+
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// PreviewTx is a transaction that would be included in a [BlockPreview].
+type PreviewTx struct {
+	ID    ids.ID          `json:"id"`
+	Units fees.Dimensions `json:"units"`
+
+	// Fee is the fee paid by the transaction (after refunds). If the block
+	// would be built in delayed execution mode, the transaction is not
+	// executed and Fee is the fee it would pay at the unit prices of the
+	// block.
+	Fee uint64 `json:"fee"`
+}
+
+// BlockPreview describes the block that would be built on top of a parent
+// if the builder ran now (see [PreviewBlock]).
+type BlockPreview struct {
+	Height    uint64 `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+
+	Attempted int          `json:"attempted"` // txs streamed from the mempool
+	Txs       []*PreviewTx `json:"txs"`
+
+	Units fees.Dimensions `json:"units"` // consumed by [Txs]
+	Fees  uint64          `json:"fees"`  // paid by [Txs]
+
+	// BlockCost is the sum of fees that must be paid by the transactions
+	// executed by the block (see [ExecutionContext.VerifyBlockCost]) and Paid
+	// is the sum of fees they pay. The block would be invalid (and not be
+	// built) if Paid < BlockCost.
+	BlockCost uint64 `json:"blockCost"`
+	Paid      uint64 `json:"paid"`
+	Surplus   uint64 `json:"surplus"` // Paid - BlockCost (0 if the block is invalid)
+
+	// BuildTime is how long the preview took to build, which estimates how
+	// long building the block would take.
+	BuildTime time.Duration `json:"buildTime"`
+}
+
+// fill records the transactions added to [b] (which produced [included]) and
+// the fees paid by the transactions executed by [b] (which produced
+// [executed]).
+func (p *BlockPreview) fill(
+	b *StatelessBlock,
+	included []*Result,
+	executed []*Result,
+	feeManager *fees.Manager,
+	blockCost uint64,
+	delayed bool,
+	attempted int,
+) error {
+	p.Height = b.Hght
+	p.Timestamp = b.Tmstmp
+	p.Attempted = attempted
+	p.Txs = make([]*PreviewTx, len(b.Txs))
+	for i, tx := range b.Txs {
+		result := included[i]
+		fee := result.Fee
+		if delayed {
+			var err error
+			fee, err = feeManager.Fee(result.Units)
+			if err != nil {
+				return err
+			}
+		}
+		units, err := fees.Add(p.Units, result.Units)
+		if err != nil {
+			return err
+		}
+		p.Units = units
+		p.Fees, err = math.Add64(p.Fees, fee)
+		if err != nil {
+			return err
+		}
+		p.Txs[i] = &PreviewTx{ID: tx.ID(), Units: result.Units, Fee: fee}
+	}
+	p.BlockCost = blockCost
+	for _, result := range executed {
+		paid, err := math.Add64(p.Paid, result.Fee)
+		if err != nil {
+			return err
+		}
+		p.Paid = paid
+	}
+	if p.Paid >= p.BlockCost {
+		p.Surplus = p.Paid - p.BlockCost
+	}
+	return nil
+}
+
+// snapshotMempool streams a fixed list of transactions (taken from a
+// [Mempool]) to the builder without modifying the [Mempool].
+type snapshotMempool struct {
+	txs []*Transaction
+}
+
+func (m *snapshotMempool) Len(context.Context) int          { return len(m.txs) }
+func (*snapshotMempool) StartStreaming(context.Context)     {}
+func (*snapshotMempool) PrepareStream(context.Context, int) {}
+
+func (m *snapshotMempool) Stream(_ context.Context, count int) []*Transaction {
+	txs := m.txs[:min(count, len(m.txs))]
+	m.txs = m.txs[len(txs):]
+	return txs
+}
+
+func (*snapshotMempool) FinishStreaming(context.Context, []*Transaction) int { return 0 }
+
+// PreviewBlock performs a dry run of [BuildBlock] on top of [parent] with
+// [txs] (in the order they would be streamed from the [Mempool]).
+//
+// The block is built at the current time (or the earliest time allowed after
+// [parent], if later) in a throwaway view of [parent]'s state. PreviewBlock
+// does not modify the [Mempool], record any build metrics, or return a block
+// that could be proposed.
+func PreviewBlock(
+	ctx context.Context,
+	vm VM,
+	parent *StatelessBlock,
+	txs []*Transaction,
+) (*BlockPreview, error) {
+	ctx, span := vm.Tracer().Start(ctx, "chain.PreviewBlock")
+	defer span.End()
+
+	start := time.Now()
+	nextTime := vm.Now().UnixMilli()
+	nextTime = max(nextTime, parent.Tmstmp+vm.Rules(nextTime).GetMinBlockGap())
+	preview := &BlockPreview{}
+	if _, err := buildBlock(ctx, vm, parent, nextTime, &snapshotMempool{txs: txs}, preview); err != nil {
+		return nil, err
+	}
+	preview.BuildTime = time.Since(start)
+	return preview, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// PriorityLane identifies protocol-critical transactions (i.e. oracle updates)
+// that should be included ahead of all other transactions.
+//
+// Lane membership is builder policy and is never checked during verification.
+type PriorityLane struct {
+	// Actions are the [Action] type IDs a transaction may contain to be in the lane.
+	Actions set.Set[uint8]
+	// Signers are the actors authorized to submit transactions in the lane.
+	Signers set.Set[codec.Address]
+}
+
+// Matches returns true if [tx] is signed by an authorized signer and only
+// contains allowed actions.
+func (p *PriorityLane) Matches(tx *Transaction) bool {
+	if len(tx.Actions) == 0 || !p.Signers.Contains(tx.Auth.Actor()) {
+		return false
+	}
+	for _, action := range tx.Actions {
+		if !p.Actions.Contains(action.GetTypeID()) {
+			return false
+		}
+	}
+	return true
+}
+
+// priorityLaneUnits returns the units of a block with [maxUnits] reserved for
+// the [PriorityLane] when reserving [percent] of each dimension.
+func priorityLaneUnits(maxUnits fees.Dimensions, percent uint64) fees.Dimensions {
+	reserved := fees.Dimensions{}
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		reserved[i] = maxUnits[i] / 100 * percent
+	}
+	return reserved
+}
+
+// priorityLaneUtilization returns the largest fraction of [reserved] units
+// consumed by [used] across all dimensions.
+func priorityLaneUtilization(used fees.Dimensions, reserved fees.Dimensions) float64 {
+	var utilization float64
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		if reserved[i] == 0 {
+			continue
+		}
+		utilization = max(utilization, float64(used[i])/float64(reserved[i]))
+	}
+	return utilization
+}
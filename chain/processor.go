@@ -5,9 +5,13 @@ package chain
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"slices"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 
 	"github.com/ava-labs/hypersdk/executor"
 	"github.com/ava-labs/hypersdk/fees"
@@ -23,58 +27,282 @@ type fetchData struct {
 	chunks uint16
 }
 
+// executionConfig contains the dependencies required to execute transactions.
+type executionConfig interface {
+	StateManager() StateManager
+	Registry() (ActionRegistry, AuthRegistry)
+	GetStateFetchConcurrency() int
+	GetTransactionExecutionCores() int
+	GetExecutorVerifyRecorder() executor.Metrics
+	GetKeyAccessRecorder() KeyAccessRecorder
+	RecordActionPanic()
+}
+
+// executionCores returns the number of transactions that may be executed
+// concurrently (at most [cores]).
+//
+// The executor only runs transactions concurrently if they don't conflict on
+// any state keys (and commits their changes in order), so the resulting state
+// is identical regardless of the number of cores used.
+func executionCores(cores int, r Rules) int {
+	if !r.GetParallelExecution() {
+		return 1
+	}
+	return cores
+}
+
 func (b *StatelessBlock) Execute(
 	ctx context.Context,
 	tracer trace.Tracer, //nolint:interfacer
 	im state.Immutable,
 	feeManager *fees.Manager,
 	r Rules,
+) ([]*Result, *tstate.TState, error) {
+	results, ts, err := executeTxs(
+		ctx,
+		tracer,
+		b.vm,
+		im,
+		feeManager,
+		r,
+		b.executedTxs,
+		len(b.pendingTxs),
+		b.Tmstmp,
+		b.pendingTimestamp,
+		b.epoch,
+		b.StateRoot,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	b.executedTxs, results = dropUnexecuted(b.executedTxs, results)
+	return results, ts, nil
+}
+
+// dropUnexecuted removes the transactions of [txs] that were dropped by
+// [executeTxs] (and have no result).
+func dropUnexecuted(txs []*Transaction, results []*Result) ([]*Transaction, []*Result) {
+	if !slices.Contains(results, nil) {
+		return txs, results
+	}
+	executed := make([]*Transaction, 0, len(txs))
+	executedResults := make([]*Result, 0, len(results))
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		executed = append(executed, txs[i])
+		executedResults = append(executedResults, result)
+	}
+	return executed, executedResults
+}
+
+// ExecutionOrder returns the order in which [numTxs] transactions are
+// executed when the first [numPending] were included by the parent (in
+// delayed execution mode).
+//
+// If [Rules.GetPermutePendingTxs] is enabled, the pending transactions are
+// permuted with [seed] (the parent's post-execution state root). This is not
+// a source of randomness and does not prevent the builder of the parent from
+// ordering transactions: it knows [seed] when it includes them, so it can
+// include them in the inverse permutation of any order it wants executed.
+//
+// Pending transactions that can no longer pay fees are dropped instead of
+// invalidating the block, so any order is valid. The remaining transactions
+// are always executed in the order they were included.
+func ExecutionOrder(r Rules, numTxs int, numPending int, seed ids.ID) []int {
+	order := make([]int, numTxs)
+	for i := range order {
+		order[i] = i
+	}
+	if !r.GetPermutePendingTxs() {
+		return order
+	}
+
+	// Fisher-Yates shuffle where each swap is derived from [seed] (instead of
+	// a PRNG whose output could change between Go versions)
+	buf := make([]byte, ids.IDLen+8)
+	copy(buf, seed[:])
+	for i := numPending - 1; i > 0; i-- {
+		binary.BigEndian.PutUint64(buf[ids.IDLen:], uint64(i))
+		h := hashing.ComputeHash256(buf)
+		j := int(binary.BigEndian.Uint64(h) % uint64(i+1))
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
+
+// executeTxs executes [txs] on top of [im] at [timestamp]. The first
+// [numPending] transactions were included by the parent (in delayed execution
+// mode) and are executed at [pendingTimestamp] (in the order returned by
+// [ExecutionOrder] for [seed]). If [epoch] is not nil, it is recorded before
+// anything is executed.
+//
+// Results are returned in the order of [txs] (regardless of the order they
+// were executed in). Pending transactions that fail [Transaction.PreExecute]
+// (like those that can no longer pay fees) are dropped: they are not
+// executed, don't consume any units, and their result is nil.
+//
+// If transactions may be executed concurrently, transactions that only
+// accumulate to the same keys (see [CommutativeAction]) are executed
+// concurrently and their accumulations are applied as they are committed.
+// Because additions commute, the result is identical to executing them in
+// order unless an accumulation fails (i.e. would overflow), in which case
+// all transactions are re-executed without deferring accumulations.
+func executeTxs(
+	ctx context.Context,
+	tracer trace.Tracer, //nolint:interfacer
+	c executionConfig,
+	im state.Immutable,
+	feeManager *fees.Manager,
+	r Rules,
+	txs []*Transaction,
+	numPending int,
+	timestamp int64,
+	pendingTimestamp int64,
+	epoch *EpochSnapshot,
+	seed ids.ID,
 ) ([]*Result, *tstate.TState, error) {
 	ctx, span := tracer.Start(ctx, "Processor.Execute")
 	defer span.End()
 
+	if executionCores(c.GetTransactionExecutionCores(), r) == 1 {
+		return executeTxsWith(ctx, c, im, feeManager, r, txs, numPending, timestamp, pendingTimestamp, epoch, seed, false)
+	}
+	consumed := feeManager.UnitsConsumed()
+	results, ts, err := executeTxsWith(ctx, c, im, feeManager, r, txs, numPending, timestamp, pendingTimestamp, epoch, seed, true)
+	if err != nil || !ts.DeferFailed() {
+		return results, ts, err
+	}
+
+	// Release the units consumed by the discarded execution
+	var release fees.Dimensions
+	for i, units := range feeManager.UnitsConsumed() {
+		release[i] = units - consumed[i]
+	}
+	if ok, d := feeManager.Release(release); !ok {
+		return nil, nil, fmt.Errorf("%w: %d released more units than consumed", ErrInvalidUnitsConsumed, d)
+	}
+	return executeTxsWith(ctx, c, im, feeManager, r, txs, numPending, timestamp, pendingTimestamp, epoch, seed, false)
+}
+
+// executeTxsWith executes [txs] (see [executeTxs]). If [deferAccumulators]
+// is set, accumulations to commutative keys are deferred until each
+// transaction is committed.
+func executeTxsWith(
+	ctx context.Context,
+	c executionConfig,
+	im state.Immutable,
+	feeManager *fees.Manager,
+	r Rules,
+	txs []*Transaction,
+	numPending int,
+	timestamp int64,
+	pendingTimestamp int64,
+	epoch *EpochSnapshot,
+	seed ids.ID,
+	deferAccumulators bool,
+) ([]*Result, *tstate.TState, error) {
 	var (
-		sm     = b.vm.StateManager()
-		numTxs = len(b.Txs)
-		t      = b.GetTimestamp()
+		sm     = c.StateManager()
+		numTxs = len(txs)
 
-		f       = fetcher.New(im, numTxs, b.vm.GetStateFetchConcurrency())
-		e       = executor.New(numTxs, b.vm.GetTransactionExecutionCores(), MaxKeyDependencies, b.vm.GetExecutorVerifyRecorder())
+		f       = fetcher.New(im, numTxs, c.GetStateFetchConcurrency())
+		e       = executor.New(numTxs, executionCores(c.GetTransactionExecutionCores(), r), MaxKeyDependencies, c.GetExecutorVerifyRecorder())
 		ts      = tstate.New(numTxs * 2) // TODO: tune this heuristic
 		results = make([]*Result, numTxs)
+
+		// accesses is only populated if a [KeyAccessRecorder] is provided
+		// (to avoid overhead in production)
+		recorder = c.GetKeyAccessRecorder()
+		accesses *KeyAccesses
+
+		// profile is only populated when verifying a profiled block
+		profile = blockProfile(ctx)
+
+		// dropped are the units of each pending transaction that was dropped
+		// (only written by the job of that transaction)
+		dropped = make([]fees.Dimensions, numPending)
 	)
+	if recorder != nil {
+		accesses = NewKeyAccesses()
+	}
+
+	// abort stops all fetching and execution and waits for any work in progress
+	// to exit, so that no goroutines outlive [executeTxs].
+	abort := func(err error) ([]*Result, *tstate.TState, error) {
+		f.Stop()
+		e.Stop()
+		_ = f.Wait()
+		_ = e.Wait()
+		return nil, nil, err
+	}
+
+	// Record the validator set of a new epoch and execute any [Task]s that are
+	// due before any transactions
+	if err := writeEpochSnapshot(ctx, sm, im, ts, feeManager, r, epoch); err != nil {
+		return abort(err)
+	}
+	if _, err := executeTasks(ctx, c, im, ts, feeManager, r, timestamp); err != nil {
+		return abort(err)
+	}
 
 	// Fetch required keys and execute transactions
-	for li, ltx := range b.Txs {
+	//
+	// Any transactions included by the parent (in delayed execution mode) are
+	// executed first at the parent's timestamp.
+	for _, li := range ExecutionOrder(r, numTxs, numPending, seed) {
 		i := li
-		tx := ltx
+		tx := txs[i]
+		t := timestamp
+		if i < numPending {
+			t = pendingTimestamp
+		}
 
-		stateKeys, err := tx.StateKeys(sm)
+		// Stop enqueuing work if the caller no longer needs the result
+		if err := checkContext(ctx); err != nil {
+			return abort(err)
+		}
+
+		// Keys declared by multiple actions are leased with the union of
+		// their permissions
+		stateKeys, err := tx.StateKeys(sm, r)
+		if err != nil {
+			return abort(err)
+		}
+		if accesses != nil {
+			accesses.Add(stateKeys)
+		}
+		commutative, err := tx.CommutativeKeys(sm, r)
 		if err != nil {
-			f.Stop()
-			e.Stop()
-			return nil, nil, err
+			return abort(err)
 		}
 
 		// Ensure we don't consume too many units
-		units, err := tx.Units(sm, r)
+		units, err := tx.Units(sm, r, t)
 		if err != nil {
-			f.Stop()
-			e.Stop()
-			return nil, nil, err
+			return abort(err)
 		}
 		if ok, d := feeManager.Consume(units, r.GetMaxBlockUnits()); !ok {
-			f.Stop()
-			e.Stop()
-			return nil, nil, fmt.Errorf("%w: %d too large", ErrInvalidUnitsConsumed, d)
+			return abort(fmt.Errorf("%w: %d too large", ErrInvalidUnitsConsumed, d))
 		}
 
 		// Prefetch state keys from disk
 		txID := tx.ID()
 		if err := f.Fetch(ctx, txID, stateKeys); err != nil {
-			return nil, nil, err
+			return abort(err)
 		}
-		e.Run(stateKeys, func() error {
+		var shared []string // transactions only share keys they defer accumulations to
+		if deferAccumulators {
+			shared = commutative
+		}
+		e.RunCommutative(stateKeys, shared, func() error {
+			// Skip all remaining transactions if the caller no longer needs
+			// the result
+			if err := checkContext(ctx); err != nil {
+				return err
+			}
+
 			// Wait for stateKeys to be read from disk
 			storage, err := f.Get(txID)
 			if err != nil {
@@ -86,17 +314,30 @@ func (b *StatelessBlock) Execute(
 			// It is critical we explicitly set the scope before each transaction is
 			// processed
 			tsv := ts.NewView(stateKeys, storage)
+			tsv.SetAccumulators(commutative, deferAccumulators)
 
 			// Ensure we have enough funds to pay fees
+			//
+			// Transactions included by the parent were only checked against the
+			// parent's state, so they may no longer be executable. Rather than
+			// invalidating the block (or executing them without paying any
+			// fees), we drop them.
 			if err := tx.PreExecute(ctx, feeManager, sm, r, tsv, t); err != nil {
-				return err
+				if i >= numPending {
+					return err
+				}
+				dropped[i] = units
+				return nil
 			}
 
-			result, err := tx.Execute(ctx, feeManager, sm, r, tsv, t)
+			result, err := tx.execute(ctx, feeManager, sm, r, tsv, t, profile)
 			if err != nil {
 				return err
 			}
 			results[i] = result
+			if result.panicked {
+				c.RecordActionPanic()
+			}
 
 			// Commit results to parent [TState]
 			tsv.Commit()
@@ -104,12 +345,24 @@ func (b *StatelessBlock) Execute(
 		})
 	}
 	if err := f.Wait(); err != nil {
-		return nil, nil, err
+		return abort(err)
 	}
 	if err := e.Wait(); err != nil {
 		return nil, nil, err
 	}
 
+	// Release the units reserved by dropped transactions (only once all
+	// transactions have been executed, so that the units available to each
+	// transaction don't depend on when others are dropped)
+	for _, units := range dropped {
+		if ok, d := feeManager.Release(units); !ok {
+			return nil, nil, fmt.Errorf("%w: %d released more units than consumed", ErrInvalidUnitsConsumed, d)
+		}
+	}
+	if recorder != nil {
+		recorder.RecordKeyAccesses(accesses)
+	}
+
 	// Return tstate that can be used to add block-level keys to state
 	return results, ts, nil
 }
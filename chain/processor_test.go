@@ -0,0 +1,323 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// cancelTestState cancels execution after [after] values are read from state.
+type cancelTestState struct {
+	taskTestState
+
+	after  int64
+	reads  atomic.Int64
+	cancel context.CancelFunc
+}
+
+func (s *cancelTestState) GetValue(ctx context.Context, key []byte) ([]byte, error) {
+	if s.reads.Add(1) == s.after {
+		s.cancel()
+	}
+	return s.taskTestState.GetValue(ctx, key)
+}
+
+func TestExecuteTxsCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chainID := ids.GenerateTestID()
+	c := &parallelTestConfig{cores: 8}
+	r := &parallelTestRules{newOfflineTestRules(ctrl, chainID), true}
+	txs, s := newParallelTestBlock(require.New(t), c, chainID, 10_000, 0)
+	feeManager := fees.NewManager(nil)
+
+	tests := []struct {
+		name  string
+		after int64
+	}{
+		{
+			name:  "before execution",
+			after: 0,
+		},
+		{
+			name:  "mid-execution",
+			after: 1_000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.after == 0 {
+				cancel()
+			}
+			im := &cancelTestState{taskTestState: s, after: tt.after, cancel: cancel}
+
+			start := time.Now()
+			results, ts, err := executeTxs(ctx, trace.Noop, c, im, feeManager, r, txs, 0, 1_000, 0, nil, ids.Empty)
+			require.Less(time.Since(start), 5*time.Second)
+			require.ErrorIs(err, context.Canceled)
+			require.True(IsTransient(err))
+			require.Nil(results)
+			require.Nil(ts)
+
+			// Execution stopped before all keys were read
+			require.Less(im.reads.Load(), int64(2*len(txs)))
+		})
+	}
+}
+
+var errDropTestBalance = errors.New("insufficient balance")
+
+// dropTestStateManager only allows a sponsor to pay fees it can afford.
+type dropTestStateManager struct {
+	refundTestStateManager
+}
+
+func (sm *dropTestStateManager) CanDeduct(ctx context.Context, addr codec.Address, im state.Immutable, amount uint64) error {
+	bal, err := sm.balance(ctx, im, addr)
+	if err != nil {
+		return err
+	}
+	if bal < amount {
+		return errDropTestBalance
+	}
+	return nil
+}
+
+// dropTestConfig is a [parallelTestConfig] that uses a
+// [dropTestStateManager].
+type dropTestConfig struct {
+	parallelTestConfig
+}
+
+func (*dropTestConfig) StateManager() StateManager { return &dropTestStateManager{} }
+
+func TestExecuteTxsDropsPending(t *testing.T) {
+	for _, cores := range []int{1, 8} {
+		t.Run(fmt.Sprintf("%d cores", cores), func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+			chainID := ids.GenerateTestID()
+			c := &dropTestConfig{parallelTestConfig{cores: cores}}
+			r := &parallelTestRules{newOfflineTestRules(ctrl, chainID), true}
+			txs, s := newParallelTestBlock(require, &c.parallelTestConfig, chainID, 4, 0)
+
+			// The sponsor of the second transaction can no longer pay fees
+			delete(s, string(refundTestBalanceKey(txs[1].Auth.Sponsor())))
+
+			newFeeManager := func() *fees.Manager {
+				feeManager := fees.NewManager(nil)
+				for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+					feeManager.SetUnitPrice(i, 1)
+				}
+				return feeManager
+			}
+
+			// If the parent included the transactions (in delayed execution
+			// mode), the transaction is dropped...
+			feeManager := newFeeManager()
+			results, ts, err := executeTxs(context.TODO(), trace.Noop, c, s, feeManager, r, txs, len(txs), 2_000, 1_000, nil, ids.Empty)
+			require.NoError(err)
+			require.Nil(results[1])
+			executed, executedResults := dropUnexecuted(txs, results)
+			require.Equal([]*Transaction{txs[0], txs[2], txs[3]}, executed)
+			require.NotContains(executedResults, nil)
+
+			// ...without consuming any units or modifying state
+			var expected fees.Dimensions
+			for _, tx := range executed {
+				units, err := tx.Units(c.StateManager(), r, 1_000)
+				require.NoError(err)
+				expected, err = fees.Add(expected, units)
+				require.NoError(err)
+			}
+			require.Equal(expected, feeManager.UnitsConsumed())
+			post := maps.Clone(s)
+			post.apply(ts)
+			_, ok := post[string(refundTestBalanceKey(txs[1].Auth.Sponsor()))]
+			require.False(ok)
+
+			// Transactions included by the block itself must be executable
+			_, _, err = executeTxs(context.TODO(), trace.Noop, c, s, newFeeManager(), r, txs, 0, 1_000, 0, nil, ids.Empty)
+			require.ErrorIs(err, errDropTestBalance)
+		})
+	}
+}
+
+// permuteTestRules executes the transactions of each block in its child
+// (in a permuted order).
+type permuteTestRules struct {
+	Rules
+}
+
+func (*permuteTestRules) GetDelayedExecution() bool  { return true }
+func (*permuteTestRules) GetPermutePendingTxs() bool { return true }
+
+var permuteTestKey = keys.EncodeChunks([]byte{0xb}, 1)
+
+// permuteTestAction appends its payload to [permuteTestKey], so that the
+// value records the order actions were executed in.
+type permuteTestAction struct {
+	testAction
+}
+
+func (*permuteTestAction) GetTypeID() uint8             { return 3 }
+func (*permuteTestAction) StateKeysMaxChunks() []uint16 { return []uint16{1} }
+func (*permuteTestAction) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{string(permuteTestKey): state.Read | state.Allocate | state.Write}
+}
+
+func (a *permuteTestAction) Execute(ctx context.Context, _ Rules, mu state.Mutable, _ int64, _ codec.Address, _ ids.ID) ([][]byte, error) {
+	v, err := mu.GetValue(ctx, permuteTestKey)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+	return nil, mu.Insert(ctx, permuteTestKey, append(slices.Clone(v), a.payload...))
+}
+
+func unmarshalPermuteTestAction(p *codec.Packer) (Action, error) {
+	a, err := unmarshalTestAction(p)
+	if err != nil {
+		return nil, err
+	}
+	return &permuteTestAction{testAction: *a.(*testAction)}, nil
+}
+
+func TestExecutionOrder(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	r := newOfflineTestRules(ctrl, ids.Empty)
+	permuted := &permuteTestRules{r}
+	identity := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	// Transactions are executed in the order they were included unless
+	// permuting is enabled
+	require.Equal(identity, ExecutionOrder(r, 10, 8, ids.ID{1}))
+
+	// The same seed produces the same order
+	order := ExecutionOrder(permuted, 10, 8, ids.ID{1})
+	require.Equal(order, ExecutionOrder(permuted, 10, 8, ids.ID{1}))
+	require.NotEqual(identity, order)
+	require.NotEqual(order, ExecutionOrder(permuted, 10, 8, ids.ID{2}))
+
+	// Only the pending transactions are permuted
+	require.ElementsMatch(identity[:8], order[:8])
+	require.Equal(identity[8:], order[8:])
+	require.Empty(ExecutionOrder(permuted, 0, 0, ids.ID{1}))
+	require.Equal([]int{0}, ExecutionOrder(permuted, 1, 1, ids.ID{1}))
+
+	// The builder knows the seed when it includes transactions, so it can
+	// include them in an order that is executed in any order it wants
+	wanted := []int{7, 6, 5, 4, 3, 2, 1, 0, 8, 9}
+	included := make([]int, len(order))
+	for i, j := range order {
+		included[j] = wanted[i]
+	}
+	executed := make([]int, len(order))
+	for i, j := range order {
+		executed[i] = included[j]
+	}
+	require.Equal(wanted, executed)
+}
+
+func TestPermutedExecution(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	db, parentRoot := newOfflineTestState(ctx, require)
+	var (
+		chainID                      = ids.GenerateTestID()
+		r                            = &permuteTestRules{newOfflineTestRules(gomock.NewController(t), chainID)}
+		actionRegistry, authRegistry = (&testParser{}).Registry()
+		factory                      = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+		txs                          = make([]*Transaction, 8)
+	)
+	require.NoError((*codec.TypeParser[Action, bool])(actionRegistry).Register(3, unmarshalPermuteTestAction, false))
+	for i := range txs {
+		tx, err := NewTx(
+			&Base{Timestamp: 2_000, ChainID: chainID, MaxFee: 1_000_000},
+			[]Action{&permuteTestAction{testAction{payload: []byte{byte(i)}}}},
+		).Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		txs[i] = tx
+	}
+
+	// The parent included [txs] (in delayed execution mode), so they are
+	// executed by the child in an order derived from the parent state root
+	order := ExecutionOrder(r, len(txs), len(txs), parentRoot)
+	expected := make([]byte, len(order))
+	for i, j := range order {
+		expected[i] = byte(j)
+	}
+	require.NotEqual([]byte{0, 1, 2, 3, 4, 5, 6, 7}, expected)
+
+	// Execute as the builder does
+	ectx, err := GenerateExecutionContext(fees.NewManager(nil).Bytes(), 0, 1_000, r)
+	require.NoError(err)
+	vm := &forkTestVM{
+		offlineTestVM: offlineTestVM{
+			r:            r,
+			lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+		},
+		blocks: map[ids.ID]*StatelessBlock{},
+	}
+	results, ts, err := executeTxs(ctx, trace.Noop, vm, db, ectx.FeeManager(), r, txs, len(txs), 1_000, 0, nil, parentRoot)
+	require.NoError(err)
+	built, err := ts.ExportMerkleDBView(ctx, trace.Noop, db)
+	require.NoError(err)
+	v, err := built.GetValue(ctx, permuteTestKey)
+	require.NoError(err)
+	require.Equal(expected, v)
+
+	// Verify reproduces the same order (and results) from the state root
+	// declared by the block
+	parent, err := ParseStatefulBlock(ctx, &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Txs:       txs,
+		StateRoot: ids.GenerateTestID(),
+	}, nil, choices.Accepted, vm)
+	require.NoError(err)
+	vm.blocks[parent.ID()] = parent
+	blk, err := ParseStatefulBlock(ctx, &StatefulBlock{
+		Prnt:      parent.ID(),
+		Tmstmp:    1_000,
+		Hght:      1,
+		Txs:       []*Transaction{},
+		StateRoot: parentRoot,
+	}, nil, choices.Processing, vm)
+	require.NoError(err)
+	require.NoError(blk.innerVerify(ctx, &offlineTestVerifyContext{db}))
+	v, err = blk.view.GetValue(ctx, permuteTestKey)
+	require.NoError(err)
+	require.Equal(expected, v)
+	require.Len(blk.Results(), len(results))
+	for i, result := range blk.Results() {
+		require.True(result.Success)
+		require.Equal(results[i].Units, result.Units)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/math"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// RefundPolicy determines how the compute units refunded by a
+// [RefundingAction] are handled.
+//
+// The units left unconsumed by a [MeteredAction] are not subject to the
+// [RefundPolicy]: their fee is always returned to the sponsor of the
+// transaction (so it only pays for the units actually consumed).
+//
+// Refunds are always applied after all transactions in a block are executed,
+// so they never make room for more transactions in the same block.
+type RefundPolicy uint8
+
+const (
+	// RefundBurn ignores refunds. The sponsor pays the full fee and the block
+	// consumes all reserved units.
+	RefundBurn RefundPolicy = iota
+
+	// RefundCredit returns the fee of the refunded units to the sponsor of the
+	// transaction and releases the refunded units from the block.
+	RefundCredit
+
+	// RefundPool releases the refunded units from the block and splits the
+	// fees of all refunded units in the block evenly between the sponsors of
+	// all successful transactions in the block (any remainder is burned).
+	RefundPool
+)
+
+// refund records the units refunded by the successful execution of [t] in
+// [result] and credits the fee of the [metered] units left unconsumed by its
+// [MeteredAction]s (and, if [RefundCredit] is active, of the [refunded] units
+// returned by its [RefundingAction]s) to the sponsor of [t].
+func (t *Transaction) refund(
+	ctx context.Context,
+	feeManager *fees.Manager,
+	s StateManager,
+	r Rules,
+	mu state.Mutable,
+	metered uint64,
+	refunded uint64,
+	result *Result,
+) error {
+	var (
+		policy  = r.GetRefundPolicy()
+		compute uint64
+	)
+	if policy != RefundBurn {
+		compute = refunded
+	}
+	if compute == 0 && metered == 0 {
+		return nil
+	}
+	units := fees.Dimensions{}
+	units[fees.Compute] = compute
+	fee, err := feeManager.Fee(units)
+	if err != nil {
+		return err
+	}
+	units[fees.Compute] = metered
+	meteredFee, err := feeManager.Fee(units)
+	if err != nil {
+		return err
+	}
+	units[fees.Compute] = compute + metered
+	result.refund = units
+	credit := meteredFee
+	if policy == RefundPool {
+		result.refundFee = fee
+	} else {
+		credit += fee
+	}
+	if credit == 0 {
+		return nil
+	}
+	if err := s.Refund(ctx, t.Auth.Sponsor(), mu, credit); err != nil {
+		return err
+	}
+	result.Fee -= credit
+	return nil
+}
+
+// applyRefunds releases the units refunded by [results] (the results of
+// executing [txs]) from [feeManager]. If [RefundPool] is active, the pooled
+// fees are distributed to the sponsors of all successful transactions in
+// [ts].
+func applyRefunds(
+	ctx context.Context,
+	c executionConfig,
+	im state.Immutable,
+	ts *tstate.TState,
+	feeManager *fees.Manager,
+	r Rules,
+	txs []*Transaction,
+	results []*Result,
+) error {
+	var (
+		units    = fees.Dimensions{}
+		pool     = math.NewUint64Operator(0)
+		sponsors = []codec.Address{}
+	)
+	for i, result := range results {
+		if !result.Success {
+			continue
+		}
+		sponsors = append(sponsors, txs[i].Auth.Sponsor())
+		if result.refund == (fees.Dimensions{}) {
+			continue
+		}
+		nunits, err := fees.Add(units, result.refund)
+		if err != nil {
+			return err
+		}
+		units = nunits
+		pool.Add(result.refundFee)
+	}
+	if units == (fees.Dimensions{}) {
+		return nil
+	}
+	if ok, d := feeManager.Release(units); !ok {
+		return fmt.Errorf("%w: %d refunded more units than consumed", ErrInvalidUnitsConsumed, d)
+	}
+	if r.GetRefundPolicy() != RefundPool {
+		return nil
+	}
+	pooled, err := pool.Value()
+	if err != nil {
+		return err
+	}
+	share := pooled / uint64(len(sponsors))
+	if share == 0 {
+		return nil
+	}
+
+	// Fetch sponsor keys from the parent state ([ts] includes any changes made
+	// by executed transactions)
+	sm := c.StateManager()
+	stateKeys := make(state.Keys)
+	for _, sponsor := range sponsors {
+		for k, v := range sm.SponsorStateKeys(sponsor) {
+			stateKeys.Add(k, v)
+		}
+	}
+	storage := make(map[string][]byte, len(stateKeys))
+	for k := range stateKeys {
+		v, err := im.GetValue(ctx, []byte(k))
+		if errors.Is(err, database.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		storage[k] = v
+	}
+	tsv := ts.NewView(stateKeys, storage)
+	for _, sponsor := range sponsors {
+		if err := sm.Refund(ctx, sponsor, tsv, share); err != nil {
+			return err
+		}
+	}
+	tsv.Commit()
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+// refundTestAction reserves 10 compute units and refunds [refund] of them.
+type refundTestAction struct {
+	refund uint64
+}
+
+func (*refundTestAction) GetTypeID() uint8                           { return 0 }
+func (*refundTestAction) ValidRange(Rules) (int64, int64)            { return -1, -1 }
+func (*refundTestAction) Size() int                                  { return 8 }
+func (a *refundTestAction) Marshal(p *codec.Packer)                  { p.PackUint64(a.refund) }
+func (*refundTestAction) ComputeUnits(Rules) uint64                  { return 10 }
+func (*refundTestAction) StateKeysMaxChunks() []uint16               { return nil }
+func (*refundTestAction) StateKeys(codec.Address, ids.ID) state.Keys { return state.Keys{} }
+func (a *refundTestAction) Execute(ctx context.Context, r Rules, mu state.Mutable, timestamp int64, actor codec.Address, actionID ids.ID) ([][]byte, error) {
+	outputs, _, err := a.ExecuteRefunding(ctx, r, mu, timestamp, actor, actionID)
+	return outputs, err
+}
+
+func (a *refundTestAction) ExecuteRefunding(context.Context, Rules, state.Mutable, int64, codec.Address, ids.ID) ([][]byte, uint64, error) {
+	return nil, a.refund, nil
+}
+
+// refundTestStateManager tracks the balance of each sponsor in state.
+type refundTestStateManager struct {
+	testStateManager
+}
+
+func refundTestBalanceKey(addr codec.Address) []byte {
+	return keys.EncodeChunks(append([]byte{0x7}, addr[:]...), 1)
+}
+
+func (*refundTestStateManager) SponsorStateKeys(addr codec.Address) state.Keys {
+	return state.Keys{string(refundTestBalanceKey(addr)): state.Read | state.Write}
+}
+
+func (*refundTestStateManager) balance(ctx context.Context, im state.Immutable, addr codec.Address) (uint64, error) {
+	v, err := im.GetValue(ctx, refundTestBalanceKey(addr))
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+func (sm *refundTestStateManager) Deduct(ctx context.Context, addr codec.Address, mu state.Mutable, amount uint64) error {
+	bal, err := sm.balance(ctx, mu, addr)
+	if err != nil {
+		return err
+	}
+	return mu.Insert(ctx, refundTestBalanceKey(addr), binary.BigEndian.AppendUint64(nil, bal-amount))
+}
+
+func (sm *refundTestStateManager) Refund(ctx context.Context, addr codec.Address, mu state.Mutable, amount uint64) error {
+	bal, err := sm.balance(ctx, mu, addr)
+	if err != nil {
+		return err
+	}
+	return mu.Insert(ctx, refundTestBalanceKey(addr), binary.BigEndian.AppendUint64(nil, bal+amount))
+}
+
+// refundTestConfig is a [taskTestConfig] that uses a [refundTestStateManager].
+type refundTestConfig struct {
+	taskTestConfig
+}
+
+func (*refundTestConfig) StateManager() StateManager { return &refundTestStateManager{} }
+
+// refundTestRules overrides the refund policy of the rules returned by
+// [newOfflineTestRules].
+type refundTestRules struct {
+	Rules
+
+	policy RefundPolicy
+}
+
+func (r *refundTestRules) GetRefundPolicy() RefundPolicy { return r.policy }
+
+func TestRefundPolicy(t *testing.T) {
+	const (
+		balance = 1_000
+		refund  = 6
+	)
+	tests := []struct {
+		name     string
+		policy   RefundPolicy
+		released uint64
+		credited uint64 // to the sponsor of the refunding tx
+		pooled   uint64 // to each sponsor
+	}{
+		{
+			name:   "burn",
+			policy: RefundBurn,
+		},
+		{
+			name:     "credit",
+			policy:   RefundCredit,
+			released: refund,
+			credited: refund,
+		},
+		{
+			name:     "pool",
+			policy:   RefundPool,
+			released: refund,
+			pooled:   refund / 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			r := &refundTestRules{newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID()), tt.policy}
+			c := &refundTestConfig{}
+			sm := c.StateManager()
+			_, authRegistry := (&testParser{}).Registry()
+			actionRegistry := codec.NewTypeParser[Action, bool]()
+			require.NoError(actionRegistry.Register(0, func(p *codec.Packer) (Action, error) {
+				return &refundTestAction{refund: p.UnpackUint64(false)}, p.Err()
+			}, false))
+			feeManager := fees.NewManager(nil)
+			for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+				feeManager.SetUnitPrice(i, 1)
+			}
+
+			// Execute a tx that refunds units and one that doesn't
+			s := make(taskTestState)
+			ts := tstate.New(0)
+			txs := []*Transaction{}
+			results := []*Result{}
+			for _, actionRefund := range []uint64{refund, 0} {
+				factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+				s[string(refundTestBalanceKey(factory.actor))] = binary.BigEndian.AppendUint64(nil, balance)
+				tx := NewTx(
+					&Base{Timestamp: 1_000, ChainID: r.ChainID(), MaxFee: balance},
+					[]Action{&refundTestAction{refund: actionRefund}},
+				)
+				tx, err := tx.Sign(factory, actionRegistry, authRegistry)
+				require.NoError(err)
+				units, err := tx.Units(sm, r, 1_000)
+				require.NoError(err)
+				ok, _ := feeManager.Consume(units, r.GetMaxBlockUnits())
+				require.True(ok)
+				stateKeys, err := tx.StateKeys(sm, r)
+				require.NoError(err)
+				storage := map[string][]byte{}
+				for k := range stateKeys {
+					if v, ok := s[k]; ok {
+						storage[k] = v
+					}
+				}
+				tsv := ts.NewView(stateKeys, storage)
+				result, err := tx.Execute(ctx, feeManager, sm, r, tsv, 1_000)
+				require.NoError(err)
+				require.True(result.Success)
+				tsv.Commit()
+				txs = append(txs, tx)
+				results = append(results, result)
+			}
+			consumed := feeManager.UnitsConsumed()
+			require.NoError(applyRefunds(ctx, c, s, ts, feeManager, r, txs, results))
+			s.apply(ts)
+
+			// Refunded units are released from the block
+			require.Equal(consumed[fees.Compute]-tt.released, feeManager.UnitsConsumed()[fees.Compute])
+
+			// Sponsor balances reflect the policy
+			for i, tx := range txs {
+				fee, err := feeManager.Fee(results[i].Units)
+				require.NoError(err)
+				expected := balance - fee + tt.pooled
+				if i == 0 {
+					expected += tt.credited
+					require.Equal(fee-tt.credited, results[i].Fee)
+				} else {
+					require.Equal(fee, results[i].Fee)
+				}
+				bal, err := (&refundTestStateManager{}).balance(ctx, s, tx.Auth.Sponsor())
+				require.NoError(err)
+				require.Equal(expected, bal)
+			}
+		})
+	}
+}
+
+func TestExecuteRefundCapped(t *testing.T) {
+	require := require.New(t)
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+
+	// Refunds can't exceed the compute units reserved by the action
+	_, unused, refund, err := execute(context.TODO(), &refundTestAction{refund: 100}, r, nil, 0, codec.EmptyAddress, ids.Empty)
+	require.NoError(err)
+	require.Zero(unused)
+	require.Equal(uint64(10), refund)
+}
@@ -19,6 +19,26 @@ type Result struct {
 	// to make life easier for indexers.
 	Units fees.Dimensions
 	Fee   uint64
+
+	// Builder is the builder credited with the block that included the
+	// transaction. It is this node's [VM.BuilderAddress] if it built the block
+	// and [StatefulBlock.Builder] otherwise (which is empty unless
+	// [Rules.GetRestrictBuilders] is enabled).
+	//
+	// Because the header of the block already commits to the builder, it is
+	// not committed to by the [StatefulBlock.ResultsRoot].
+	Builder codec.Address
+
+	// [refund] are the units refunded by a [RefundingAction] or left
+	// unconsumed by a [MeteredAction] that are released after the block is
+	// executed and [refundFee] is the fee added to the pool (see
+	// [RefundPolicy]). They are not serialized.
+	refund    fees.Dimensions
+	refundFee uint64
+
+	// [panicked] is set if an action panicked (see [ContainPanicsFork]). It
+	// is not serialized.
+	panicked bool
 }
 
 func (r *Result) Size() int {
@@ -29,12 +49,30 @@ func (r *Result) Size() int {
 			outputSize += codec.BytesLen(output)
 		}
 	}
-	return consts.BoolLen + codec.BytesLen(r.Error) + outputSize + fees.DimensionsLen + consts.Uint64Len
+	size := consts.BoolLen + codec.BytesLen(r.Error) + outputSize + fees.DimensionsLen + consts.Uint64Len + consts.BoolLen
+	if r.Builder != codec.EmptyAddress {
+		size += codec.AddressLen
+	}
+	return size
 }
 
 func (r *Result) Marshal(p *codec.Packer) error {
+	if err := r.marshalCommitted(p); err != nil {
+		return err
+	}
+	hasBuilder := r.Builder != codec.EmptyAddress
+	p.PackBool(hasBuilder)
+	if hasBuilder {
+		p.PackAddress(r.Builder)
+	}
+	return nil
+}
+
+// marshalCommitted packs the fields of [r] committed to by the
+// [StatefulBlock.ResultsRoot] (all but [Builder]).
+func (r *Result) marshalCommitted(p *codec.Packer) error {
 	p.PackBool(r.Success)
-	p.PackBytes(r.Error)
+	p.PackLimitedBytes(r.Error, MaxResultErrorSize)
 	p.PackByte(uint8(len(r.Outputs)))
 	for _, outputs := range r.Outputs {
 		p.PackByte(uint8(len(outputs)))
@@ -63,7 +101,7 @@ func UnmarshalResult(p *codec.Packer) (*Result, error) {
 	result := &Result{
 		Success: p.UnpackBool(),
 	}
-	p.UnpackBytes(consts.MaxInt, false, &result.Error)
+	p.UnpackBytes(MaxResultErrorSize, false, &result.Error)
 	outputs := [][][]byte{}
 	numActions := p.UnpackByte()
 	for i := uint8(0); i < numActions; i++ {
@@ -85,10 +123,20 @@ func UnmarshalResult(p *codec.Packer) (*Result, error) {
 	}
 	result.Units = units
 	result.Fee = p.UnpackUint64(false)
+	if p.UnpackBool() {
+		p.UnpackAddress(&result.Builder)
+	}
 	// Wait to check if empty until after all results are unpacked.
 	return result, p.Err()
 }
 
+// setBuilder records [builder] as the [Result.Builder] of each of [results].
+func setBuilder(results []*Result, builder codec.Address) {
+	for _, result := range results {
+		result.Builder = builder
+	}
+}
+
 func UnmarshalResults(src []byte) ([]*Result, error) {
 	p := codec.NewReader(src, consts.MaxInt) // could be much larger than [NetworkSizeLimit]
 	items := p.UnpackInt(false)
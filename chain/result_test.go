@@ -0,0 +1,101 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+func TestResultErrorLimit(t *testing.T) {
+	require := require.New(t)
+
+	// Errors at the limit are accepted
+	result := &Result{
+		Error:   make([]byte, MaxResultErrorSize),
+		Outputs: [][][]byte{},
+		Units:   fees.Dimensions{},
+	}
+	raw, err := MarshalResults([]*Result{result})
+	require.NoError(err)
+	results, err := UnmarshalResults(raw)
+	require.NoError(err)
+	require.Equal(result.Error, results[0].Error)
+
+	// Errors one byte over the limit are rejected
+	result.Error = make([]byte, MaxResultErrorSize+1)
+	_, err = MarshalResults([]*Result{result})
+	require.ErrorIs(err, codec.ErrFieldTooLarge)
+
+	// Errors one byte over the limit are rejected when unpacking
+	p := codec.NewWriter(0, consts.MaxInt)
+	p.PackInt(1)
+	p.PackBool(false)
+	p.PackBytes(result.Error)
+	p.PackByte(0)
+	p.PackFixedBytes(fees.Dimensions{}.Bytes())
+	p.PackUint64(0)
+	require.NoError(p.Err())
+	_, err = UnmarshalResults(p.Bytes())
+	require.Error(err)
+
+	// The same bytes are accepted as an output (which has a larger limit)
+	result.Error = []byte{}
+	result.Outputs = [][][]byte{{make([]byte, MaxResultErrorSize+1)}}
+	raw, err = MarshalResults([]*Result{result})
+	require.NoError(err)
+	results, err = UnmarshalResults(raw)
+	require.NoError(err)
+	require.Equal(result.Outputs, results[0].Outputs)
+}
+
+func TestResultErrorTruncated(t *testing.T) {
+	require := require.New(t)
+
+	err := codec.ErrFieldTooLarge
+	require.Equal([]byte(err.Error()), resultError(err))
+
+	long := errors.New(strings.Repeat("a", MaxResultErrorSize+1))
+	require.Len(resultError(long), MaxResultErrorSize)
+}
+
+func TestResultBuilder(t *testing.T) {
+	require := require.New(t)
+
+	results := generateResults(3)
+	root, err := ResultsRoot(workers.NewSerial(), results)
+	require.NoError(err)
+
+	// The builder is marshaled...
+	builder := codec.CreateAddress(0, ids.GenerateTestID())
+	setBuilder(results, builder)
+	raw, err := MarshalResults(results)
+	require.NoError(err)
+	parsed, err := UnmarshalResults(raw)
+	require.NoError(err)
+	require.Equal(results, parsed)
+
+	// ...but not committed to by the results root
+	builderRoot, err := ResultsRoot(workers.NewSerial(), results)
+	require.NoError(err)
+	require.Equal(root, builderRoot)
+
+	// An empty builder is omitted
+	setBuilder(results, codec.EmptyAddress)
+	emptyRaw, err := MarshalResults(results)
+	require.NoError(err)
+	require.Len(emptyRaw, len(raw)-len(results)*codec.AddressLen)
+	parsed, err = UnmarshalResults(emptyRaw)
+	require.NoError(err)
+	require.Equal(codec.EmptyAddress, parsed[0].Builder)
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/merkle"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+// resultLeaf returns the [merkle.Leaf] of [result] (which doesn't include
+// [Result.Builder]).
+//
+// [Result.Marshal] is canonical (fields are always packed in the same order
+// and nil/empty values are encoded identically), so all nodes compute the
+// same leaf for the same result.
+func resultLeaf(result *Result) (ids.ID, error) {
+	p := codec.GetWriter(result.Size(), consts.MaxInt)
+	defer codec.PutWriter(p)
+
+	if err := result.marshalCommitted(p); err != nil {
+		return ids.Empty, err
+	}
+	if err := p.Err(); err != nil {
+		return ids.Empty, err
+	}
+	return merkle.Leaf(p.Bytes()), nil
+}
+
+// resultLeaves hashes each of [results] in parallel on [w].
+func resultLeaves(w workers.Workers, results []*Result) ([]ids.ID, error) {
+	leaves := make([]ids.ID, len(results))
+	if len(results) == 0 {
+		return leaves, nil
+	}
+	job, err := w.NewJob(len(results))
+	if err != nil {
+		return nil, err
+	}
+	batchSize := max(len(results)/job.Workers(), 1)
+	for start := 0; start < len(results); start += batchSize {
+		start, end := start, min(start+batchSize, len(results))
+		job.Go(func() error {
+			for i := start; i < end; i++ {
+				leaf, err := resultLeaf(results[i])
+				if err != nil {
+					return err
+				}
+				leaves[i] = leaf
+			}
+			return nil
+		})
+	}
+	job.Done(nil)
+	if err := job.Wait(); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// ResultsRoot returns the [merkle.Root] of [results] (in transaction order).
+// Results are hashed in parallel on [w].
+func ResultsRoot(w workers.Workers, results []*Result) (ids.ID, error) {
+	leaves, err := resultLeaves(w, results)
+	if err != nil {
+		return ids.Empty, err
+	}
+	return merkle.Root(leaves), nil
+}
+
+// ResultProof returns the leaf of the result at [index] in [results] and the
+// [merkle.Proof] that it is included in [ResultsRoot].
+func ResultProof(w workers.Workers, results []*Result, index int) (ids.ID, []ids.ID, error) {
+	leaves, err := resultLeaves(w, results)
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	proof, err := merkle.Proof(leaves, index)
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	return leaves[index], proof, nil
+}
+
+// VerifyResult returns true if [proof] shows that [result] was produced by the
+// transaction at [index] in a block with [count] transactions and [root]
+// (the [StatefulBlock.ResultsRoot]).
+func VerifyResult(root ids.ID, result *Result, index int, count int, proof []ids.ID) (bool, error) {
+	leaf, err := resultLeaf(result)
+	if err != nil {
+		return false, err
+	}
+	return merkle.Verify(root, leaf, index, count, proof), nil
+}
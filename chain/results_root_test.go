@@ -0,0 +1,88 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+func generateResults(count int) []*Result {
+	results := make([]*Result, count)
+	for i := range results {
+		results[i] = &Result{
+			Success: i%2 == 0,
+			Error:   []byte{},
+			Outputs: [][][]byte{{[]byte(fmt.Sprintf("output-%d", i))}},
+			Units:   fees.Dimensions{uint64(i), 1, 2, 3, 4},
+			Fee:     uint64(i),
+		}
+	}
+	return results
+}
+
+func TestResultsRoot(t *testing.T) {
+	require := require.New(t)
+
+	results := generateResults(11)
+	parallel := workers.NewParallel(4, 10)
+	defer parallel.Stop()
+
+	// Parallel hashing must produce the same root
+	root, err := ResultsRoot(workers.NewSerial(), results)
+	require.NoError(err)
+	require.NotEqual(ids.Empty, root)
+	parallelRoot, err := ResultsRoot(parallel, results)
+	require.NoError(err)
+	require.Equal(root, parallelRoot)
+
+	// Nil and empty fields are encoded identically
+	results[0].Error = nil
+	nilRoot, err := ResultsRoot(parallel, results)
+	require.NoError(err)
+	require.Equal(root, nilRoot)
+
+	// Each result can be proven against the root
+	for i, result := range results {
+		_, proof, err := ResultProof(parallel, results, i)
+		require.NoError(err)
+		ok, err := VerifyResult(root, result, i, len(results), proof)
+		require.NoError(err)
+		require.True(ok)
+
+		// A modified result does not verify
+		modified := *result
+		modified.Success = !modified.Success
+		ok, err = VerifyResult(root, &modified, i, len(results), proof)
+		require.NoError(err)
+		require.False(ok)
+	}
+
+	// No results have an empty root
+	emptyRoot, err := ResultsRoot(parallel, nil)
+	require.NoError(err)
+	require.Equal(ids.Empty, emptyRoot)
+}
+
+func BenchmarkResultsRoot(b *testing.B) {
+	results := generateResults(50_000)
+	for _, cores := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("cores=%d", cores), func(b *testing.B) {
+			w := workers.NewParallel(cores, 10)
+			defer w.Stop()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := ResultsRoot(w, results); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// RootComputer computes the state root that results from applying [changes]
+// to [parent].
+//
+// This is used to compare the roots produced by different implementations of
+// state commitment (i.e. when upgrading merkledb) without affecting
+// consensus. Implementations must not share the merkledb version the hypersdk
+// is built with (i.e. they should be backed by the previous version),
+// otherwise they can't detect any divergence.
+type RootComputer interface {
+	ComputeRoot(
+		ctx context.Context,
+		parent state.View,
+		changes map[string]maybe.Maybe[[]byte],
+	) (ids.ID, error)
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/state"
+)
+
+var errShadowRootFailed = errors.New("shadow root failed")
+
+// shadowTestRootComputer returns [root] (or [err]) for any changes.
+type shadowTestRootComputer struct {
+	root ids.ID
+	err  error
+}
+
+func (c *shadowTestRootComputer) ComputeRoot(context.Context, state.View, map[string]maybe.Maybe[[]byte]) (ids.ID, error) {
+	return c.root, c.err
+}
+
+// shadowTestVM records the shadow roots it is notified of.
+type shadowTestVM struct {
+	*offlineTestVM
+
+	calculated int
+	mismatches int
+}
+
+func (vm *shadowTestVM) RecordShadowRootCalculated(time.Duration) { vm.calculated++ }
+func (vm *shadowTestVM) RecordShadowRootMismatch()                { vm.mismatches++ }
+
+func TestVerifyShadowRoot(t *testing.T) {
+	root := ids.GenerateTestID()
+	tests := []struct {
+		name       string
+		shadow     *shadowTestRootComputer
+		calculated int
+		mismatches int
+	}{
+		{
+			name:       "match",
+			shadow:     &shadowTestRootComputer{root: root},
+			calculated: 1,
+		},
+		{
+			name:       "mismatch",
+			shadow:     &shadowTestRootComputer{root: ids.GenerateTestID()},
+			calculated: 1,
+			mismatches: 1,
+		},
+		{
+			// Failures are only logged
+			name:   "failure",
+			shadow: &shadowTestRootComputer{err: errShadowRootFailed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			vm := &shadowTestVM{offlineTestVM: &offlineTestVM{}}
+			b := &StatelessBlock{StatefulBlock: &StatefulBlock{Hght: 1}, vm: vm}
+			b.verifyShadowRoot(context.TODO(), tt.shadow, nil, nil, root)
+			require.Equal(tt.calculated, vm.calculated)
+			require.Equal(tt.mismatches, vm.mismatches)
+		})
+	}
+}
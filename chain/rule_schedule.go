@@ -0,0 +1,56 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ScheduledRules are the [Rules] that apply to all blocks with a timestamp
+// greater than or equal to [ActivateAt] (until the next [ScheduledRules]
+// activates).
+type ScheduledRules struct {
+	ActivateAt int64 // ms
+	Rules      Rules
+}
+
+// RuleSchedule is used by VMs to implement upgrades by scheduling changes to
+// [Rules] at specific timestamps.
+type RuleSchedule struct {
+	schedule []*ScheduledRules
+}
+
+// NewRuleSchedule returns a [RuleSchedule] for [schedule]. [schedule] does not
+// need to be sorted, however, no two [ScheduledRules] may share the same
+// activation time.
+func NewRuleSchedule(schedule ...*ScheduledRules) (*RuleSchedule, error) {
+	if len(schedule) == 0 {
+		return nil, ErrEmptySchedule
+	}
+	sorted := slices.Clone(schedule)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActivateAt < sorted[j].ActivateAt
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].ActivateAt == sorted[i-1].ActivateAt {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateActivation, sorted[i].ActivateAt)
+		}
+	}
+	return &RuleSchedule{sorted}, nil
+}
+
+// RulesAt returns the latest [Rules] activated at or before [t]. If no [Rules]
+// are activated at [t], the earliest scheduled [Rules] are returned.
+func (s *RuleSchedule) RulesAt(t int64) Rules {
+	// Find the first scheduled rules that activate after [t]
+	i := sort.Search(len(s.schedule), func(i int) bool {
+		return s.schedule[i].ActivateAt > t
+	})
+	if i == 0 {
+		return s.schedule[0].Rules
+	}
+	return s.schedule[i-1].Rules
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRuleSchedule(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	var (
+		genesis = NewMockRules(ctrl)
+		fork1   = NewMockRules(ctrl)
+		fork2   = NewMockRules(ctrl)
+	)
+
+	// Provide schedule out of order to ensure it is sorted
+	schedule, err := NewRuleSchedule(
+		&ScheduledRules{ActivateAt: 2_000, Rules: fork2},
+		&ScheduledRules{ActivateAt: 0, Rules: genesis},
+		&ScheduledRules{ActivateAt: 1_000, Rules: fork1},
+	)
+	require.NoError(err)
+
+	tests := []struct {
+		t     int64
+		rules Rules
+	}{
+		{t: -1, rules: genesis},
+		{t: 0, rules: genesis},
+		{t: 999, rules: genesis},
+		{t: 1_000, rules: fork1},
+		{t: 1_001, rules: fork1},
+		{t: 1_999, rules: fork1},
+		{t: 2_000, rules: fork2},
+		{t: 10_000, rules: fork2},
+	}
+	for _, tt := range tests {
+		require.Same(tt.rules, schedule.RulesAt(tt.t), "t=%d", tt.t)
+	}
+}
+
+func TestRuleScheduleInvalid(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	_, err := NewRuleSchedule()
+	require.ErrorIs(err, ErrEmptySchedule)
+
+	_, err = NewRuleSchedule(
+		&ScheduledRules{ActivateAt: 0, Rules: NewMockRules(ctrl)},
+		&ScheduledRules{ActivateAt: 0, Rules: NewMockRules(ctrl)},
+	)
+	require.ErrorIs(err, ErrDuplicateActivation)
+}
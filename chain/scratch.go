@@ -0,0 +1,30 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import "context"
+
+type scratchKey struct{}
+
+// WithScratch returns a copy of [ctx] with an empty scratch space (see
+// [Scratch]).
+func WithScratch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scratchKey{}, map[string][]byte{})
+}
+
+// Scratch returns the scratch space of the transaction being executed, which
+// [Action]s can use to pass intermediate values to the actions executed after
+// them in the same transaction (including the actions they call with
+// [CallAction]) without writing to state.
+//
+// Every transaction (and task callback) starts with an empty scratch space,
+// which is discarded once it is executed. Because the scratch space is not
+// persisted or included in any [Result], actions should only stash values
+// that they could have computed themselves.
+//
+// Scratch returns nil if [ctx] was not created with [WithScratch].
+func Scratch(ctx context.Context) map[string][]byte {
+	scratch, _ := ctx.Value(scratchKey{}).(map[string][]byte)
+	return scratch
+}
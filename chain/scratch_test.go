@@ -0,0 +1,105 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+var errTestScratchMissing = errors.New("scratch value missing")
+
+// scratchTestAction stashes [value] under [key] in the scratch space (if
+// [value] is set) or returns the value stashed under [key].
+type scratchTestAction struct {
+	key   string
+	value []byte
+}
+
+func (*scratchTestAction) GetTypeID() uint8                           { return 0 }
+func (*scratchTestAction) ValidRange(Rules) (int64, int64)            { return -1, -1 }
+func (*scratchTestAction) ComputeUnits(Rules) uint64                  { return 1 }
+func (*scratchTestAction) StateKeysMaxChunks() []uint16               { return nil }
+func (*scratchTestAction) StateKeys(codec.Address, ids.ID) state.Keys { return state.Keys{} }
+func (a *scratchTestAction) Size() int {
+	return codec.StringLen(a.key) + codec.BytesLen(a.value)
+}
+
+func (a *scratchTestAction) Marshal(p *codec.Packer) {
+	p.PackString(a.key)
+	p.PackBytes(a.value)
+}
+
+func unmarshalScratchTestAction(p *codec.Packer) (Action, error) {
+	var a scratchTestAction
+	a.key = p.UnpackString(true)
+	p.UnpackBytes(-1, false, &a.value)
+	return &a, p.Err()
+}
+
+func (a *scratchTestAction) Execute(ctx context.Context, _ Rules, _ state.Mutable, _ int64, _ codec.Address, _ ids.ID) ([][]byte, error) {
+	scratch := Scratch(ctx)
+	if len(a.value) > 0 {
+		scratch[a.key] = a.value
+		return nil, nil
+	}
+	v, ok := scratch[a.key]
+	if !ok {
+		return nil, errTestScratchMissing
+	}
+	return [][]byte{v}, nil
+}
+
+func TestScratch(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+	sm := &refundTestStateManager{}
+	_, authRegistry := (&testParser{}).Registry()
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	require.NoError(actionRegistry.Register(0, unmarshalScratchTestAction, false))
+	feeManager := fees.NewManager(nil)
+	ts := tstate.New(0)
+	factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+	execute := func(actions ...Action) *Result {
+		tx, err := NewTx(&Base{Timestamp: 1_000, ChainID: r.ChainID(), MaxFee: 1_000}, actions).Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		stateKeys, err := tx.StateKeys(sm, r)
+		require.NoError(err)
+		tsv := ts.NewView(stateKeys, map[string][]byte{
+			string(refundTestBalanceKey(factory.actor)): binary.BigEndian.AppendUint64(nil, 1_000),
+		})
+		result, err := tx.Execute(ctx, feeManager, sm, r, tsv, 1_000)
+		require.NoError(err)
+		return result
+	}
+
+	// Values stashed by an action are visible to later actions in the same tx
+	result := execute(
+		&scratchTestAction{key: "a", value: []byte{1}},
+		&scratchTestAction{key: "a"},
+	)
+	require.True(result.Success)
+	require.Equal([][][]byte{{}, {{1}}}, result.Outputs)
+
+	// ...but not to actions in other txs
+	result = execute(&scratchTestAction{key: "a"})
+	require.False(result.Success)
+	require.Equal(errTestScratchMissing.Error(), string(result.Error))
+
+	// There is no scratch space outside of execution
+	require.Nil(Scratch(ctx))
+}
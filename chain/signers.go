@@ -0,0 +1,33 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+// verifySigners ensures that [txs] are signed by at least
+// [Rules.GetMinDistinctSigners] distinct actors.
+//
+// Blocks with fewer than [Rules.GetMinDistinctSignersTxs] transactions (and
+// blocks without transactions) are exempt, so a quiet chain can still make
+// progress.
+func verifySigners(r Rules, txs []*Transaction) error {
+	minSigners := r.GetMinDistinctSigners()
+	if minSigners <= 0 || len(txs) == 0 || len(txs) < r.GetMinDistinctSignersTxs() {
+		return nil
+	}
+	signers := set.NewSet[codec.Address](minSigners)
+	for _, tx := range txs {
+		signers.Add(tx.Auth.Actor())
+		if signers.Len() >= minSigners {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %d of %d required", ErrTooFewSigners, signers.Len(), minSigners)
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+// signersTestRules overrides the minimum number of distinct signers of the
+// rules returned by [newOfflineTestRules].
+type signersTestRules struct {
+	Rules
+
+	minSigners    int
+	minSignersTxs int
+}
+
+func (r *signersTestRules) GetMinDistinctSigners() int    { return r.minSigners }
+func (r *signersTestRules) GetMinDistinctSignersTxs() int { return r.minSignersTxs }
+
+func TestMinDistinctSigners(t *testing.T) {
+	var (
+		alice = codec.CreateAddress(0, ids.GenerateTestID())
+		bob   = codec.CreateAddress(0, ids.GenerateTestID())
+		carol = codec.CreateAddress(0, ids.GenerateTestID())
+	)
+	tests := []struct {
+		name          string
+		minSigners    int
+		minSignersTxs int
+		signers       []codec.Address // the actor of each tx
+		err           error
+	}{
+		{
+			name:          "disabled",
+			minSignersTxs: 1,
+			signers:       []codec.Address{alice, alice, alice, alice},
+		},
+		{
+			name:          "enough distinct signers",
+			minSigners:    3,
+			minSignersTxs: 4,
+			signers:       []codec.Address{alice, bob, alice, carol},
+		},
+		{
+			name:          "dominated by a single signer",
+			minSigners:    3,
+			minSignersTxs: 4,
+			signers:       []codec.Address{alice, alice, bob, alice},
+			err:           ErrTooFewSigners,
+		},
+		{
+			name:          "small block",
+			minSigners:    3,
+			minSignersTxs: 4,
+			signers:       []codec.Address{alice, alice, alice},
+		},
+		{
+			name:       "empty block",
+			minSigners: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+
+			db, parentRoot := newOfflineTestState(ctx, require)
+			var (
+				chainID           = ids.GenerateTestID()
+				r                 = &signersTestRules{newOfflineTestRules(gomock.NewController(t), chainID), tt.minSigners, tt.minSignersTxs}
+				actionRegistry, _ = (&testParser{}).Registry()
+				blk               = &StatefulBlock{
+					Prnt:      ids.GenerateTestID(),
+					Tmstmp:    1_000,
+					Hght:      1,
+					Txs:       []*Transaction{},
+					StateRoot: parentRoot,
+				}
+			)
+			for i, signer := range tt.signers {
+				b := &adversarialBuilder{factory: &testAuthFactory{actor: signer}}
+				blk.Txs = append(blk.Txs, b.tx(require, 2_000, chainID, byte(i)))
+			}
+
+			// The block is rejected (or accepted) by the VM and offline
+			vm := &offlineTestVM{
+				r:            r,
+				lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+			}
+			sblk, err := ParseStatefulBlock(ctx, blk, nil, choices.Processing, vm)
+			require.NoError(err)
+			require.ErrorIs(sblk.innerVerify(ctx, &offlineTestVerifyContext{db}), tt.err)
+			_, err = VerifyOffline(ctx, blk, parentRoot, db, &testStateManager{}, actionRegistry, r)
+			require.ErrorIs(err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/math"
+
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// RulesSimulation is the outcome of re-executing a block under hypothetical
+// [Rules] (see [StatelessBlock.SimulateUnderRules]).
+type RulesSimulation struct {
+	// Txs are the transactions executed by the block (in the order they were
+	// included) and the units and fees they would consume.
+	Txs []*PreviewTx `json:"txs"`
+
+	// Successes is the number of [Txs] that would succeed.
+	Successes int `json:"successes"`
+
+	UnitPrices fees.Dimensions `json:"unitPrices"`
+	Units      fees.Dimensions `json:"units"` // consumed by the block (after refunds)
+	Fees       uint64          `json:"fees"`  // paid by [Txs]
+}
+
+// SimulateUnderRules re-executes the transactions executed by [b] on top of
+// its parent's state under [r] (instead of the rules [b] was verified with)
+// and returns the units and fees they would consume. This allows governance
+// to model the impact of a rules change (i.e. different unit costs or prices)
+// before it activates.
+//
+// The simulation is executed in a throwaway view, so it does not modify state
+// or [b]. Because only the post-execution state of [b]'s parent can be read,
+// [b] must be verified and not yet committed to state.
+func (b *StatelessBlock) SimulateUnderRules(ctx context.Context, r Rules) (*RulesSimulation, error) {
+	ctx, span := b.vm.Tracer().Start(ctx, "StatelessBlock.SimulateUnderRules")
+	defer span.End()
+
+	if !b.Processed() {
+		return nil, ErrBlockNotProcessed
+	}
+	if b.st == choices.Accepted || b.committed {
+		return nil, ErrBlockCommitted
+	}
+	vctx, err := b.vm.GetVerifyContext(ctx, b.Hght, b.Prnt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get verify context", err)
+	}
+	parentView, err := vctx.View(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load parent view", err)
+	}
+
+	// Compute the unit prices of [b] under [r]
+	sm := b.vm.StateManager()
+	parentTimestampRaw, err := parentView.GetValue(ctx, TimestampKey(sm.TimestampKey()))
+	if err != nil {
+		return nil, err
+	}
+	feeRaw, err := parentView.GetValue(ctx, FeeKey(sm.FeeKey()))
+	if err != nil {
+		return nil, err
+	}
+	ectx, err := GenerateExecutionContext(feeRaw, int64(binary.BigEndian.Uint64(parentTimestampRaw)), b.Tmstmp, r)
+	if err != nil {
+		return nil, err
+	}
+	feeManager := ectx.FeeManager()
+
+	// Execute transactions (the resulting [tstate.TState] is discarded)
+	results, ts, err := b.Execute(ctx, b.vm.Tracer(), parentView, feeManager, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyRefunds(ctx, b.vm, parentView, ts, feeManager, r, b.executedTxs, results); err != nil {
+		return nil, err
+	}
+	return newRulesSimulation(b.executedTxs, results, feeManager)
+}
+
+// newRulesSimulation summarizes the [results] of executing [txs] with
+// [feeManager].
+func newRulesSimulation(txs []*Transaction, results []*Result, feeManager *fees.Manager) (*RulesSimulation, error) {
+	s := &RulesSimulation{
+		Txs:        make([]*PreviewTx, len(txs)),
+		UnitPrices: feeManager.UnitPrices(),
+		Units:      feeManager.UnitsConsumed(),
+	}
+	for i, tx := range txs {
+		result := results[i]
+		if result.Success {
+			s.Successes++
+		}
+		fee, err := math.Add64(s.Fees, result.Fee)
+		if err != nil {
+			return nil, err
+		}
+		s.Fees = fee
+		s.Txs[i] = &PreviewTx{ID: tx.ID(), Units: result.Units, Fee: result.Fee}
+	}
+	return s, nil
+}
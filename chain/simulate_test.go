@@ -0,0 +1,113 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+// simulateTestRules scales the base compute units of the rules returned by
+// [newOfflineTestRules] by [multiplier].
+type simulateTestRules struct {
+	Rules
+
+	multiplier uint64
+}
+
+func (r *simulateTestRules) GetBaseComputeUnits() uint64 {
+	return r.Rules.GetBaseComputeUnits() * r.multiplier
+}
+
+func TestSimulateUnderRules(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	chainID := ids.GenerateTestID()
+	r := newOfflineTestRules(gomock.NewController(t), chainID)
+
+	db, root := newOfflineTestState(ctx, require)
+	var (
+		b = &adversarialBuilder{
+			chainID: chainID,
+			factory: &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())},
+		}
+		vm = &offlineTestVM{
+			r:            r,
+			lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+			parentState:  db,
+		}
+		blk = &StatefulBlock{
+			Prnt:      ids.GenerateTestID(),
+			Tmstmp:    1_000,
+			Hght:      1,
+			StateRoot: root,
+		}
+	)
+	for i := 0; i < 4; i++ {
+		blk.Txs = append(blk.Txs, b.tx(require, 2_000, chainID, byte(i)))
+	}
+	sblk, err := ParseStatefulBlock(ctx, blk, nil, choices.Processing, vm)
+	require.NoError(err)
+
+	// Blocks must be verified before they can be simulated
+	_, err = sblk.SimulateUnderRules(ctx, r)
+	require.ErrorIs(err, ErrBlockNotProcessed)
+	require.NoError(sblk.innerVerify(ctx, &offlineTestVerifyContext{db}))
+	viewRoot, err := sblk.view.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	// Simulating the block under its own rules reproduces its results...
+	baseline, err := sblk.SimulateUnderRules(ctx, r)
+	require.NoError(err)
+	require.Len(baseline.Txs, len(blk.Txs))
+	require.Equal(len(blk.Txs), baseline.Successes)
+	var (
+		units = fees.Dimensions{}
+		paid  uint64
+	)
+	for i, result := range sblk.Results() {
+		require.Equal(blk.Txs[i].ID(), baseline.Txs[i].ID)
+		require.Equal(result.Units, baseline.Txs[i].Units)
+		require.Equal(result.Fee, baseline.Txs[i].Fee)
+		units, err = fees.Add(units, result.Units)
+		require.NoError(err)
+		paid += result.Fee
+	}
+	require.Equal(units, baseline.Units)
+	require.Equal(paid, baseline.Fees)
+
+	// ...while doubling the base compute units charges each tx for the
+	// additional units
+	doubled, err := sblk.SimulateUnderRules(ctx, &simulateTestRules{r, 2})
+	require.NoError(err)
+	require.Equal(baseline.UnitPrices, doubled.UnitPrices)
+	extra := r.GetBaseComputeUnits() * uint64(len(blk.Txs))
+	require.Equal(baseline.Units[fees.Compute]+extra, doubled.Units[fees.Compute])
+	require.Greater(doubled.Fees, baseline.Fees)
+	for i, tx := range doubled.Txs {
+		require.Equal(baseline.Txs[i].Units[fees.Compute]+r.GetBaseComputeUnits(), tx.Units[fees.Compute])
+	}
+
+	// Simulations don't modify state or the block
+	dbRoot, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(root, dbRoot)
+	simulatedRoot, err := sblk.view.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(viewRoot, simulatedRoot)
+	require.Equal(units, sblk.FeeManager().UnitsConsumed())
+
+	// Accepted blocks can't be simulated (their parent's state is gone)
+	sblk.st = choices.Accepted
+	_, err = sblk.SimulateUnderRules(ctx, r)
+	require.ErrorIs(err, ErrBlockCommitted)
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// stateFetchBackoff is the delay before the first retry of a transient state
+// fetch failure. The delay doubles after each subsequent failure.
+const stateFetchBackoff = 10 * time.Millisecond
+
+// TransientError is implemented by errors that may not recur if the operation
+// that produced them is retried (i.e. state that is not yet available because
+// the node is still syncing).
+type TransientError interface {
+	error
+	Transient() bool
+}
+
+var _ TransientError = (*transientError)(nil)
+
+type transientError struct {
+	err error
+}
+
+// NewTransientError marks [err] as a [TransientError].
+func NewTransientError(err error) error {
+	return &transientError{err}
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+
+func (e *transientError) Unwrap() error { return e.err }
+
+func (*transientError) Transient() bool { return true }
+
+// IsTransient returns true if [err] (or any error it wraps) is a
+// [TransientError] that should be retried.
+func IsTransient(err error) bool {
+	var terr TransientError
+	return errors.As(err, &terr) && terr.Transient()
+}
+
+// checkContext returns a [TransientError] wrapping [ctx.Err] if [ctx] is done.
+//
+// Verification and building check this regularly so that they return promptly
+// once the engine no longer needs their result. The operation may be retried
+// with a new [context.Context].
+func checkContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return NewTransientError(err)
+	}
+	return nil
+}
+
+// getMerkleRoot returns the root of [view] or returns once [ctx] is done,
+// whichever happens first.
+//
+// If [ctx] is done first, the root continues to be computed in the background
+// (and is cached by [view] for the next call).
+func getMerkleRoot(ctx context.Context, view state.View) (ids.ID, error) {
+	if err := checkContext(ctx); err != nil {
+		return ids.Empty, err
+	}
+	type rootResult struct {
+		root ids.ID
+		err  error
+	}
+	done := make(chan rootResult, 1)
+	go func() {
+		// Finish computing the root even if [ctx] is canceled, so that it
+		// is cached for the next caller.
+		root, err := view.GetMerkleRoot(context.WithoutCancel(ctx))
+		done <- rootResult{root, err}
+	}()
+	select {
+	case r := <-done:
+		return r.root, r.err
+	case <-ctx.Done():
+		return ids.Empty, checkContext(ctx)
+	}
+}
+
+// retryStateFetch calls [f] until it succeeds, it returns a permanent error, or
+// it has been retried [retries] times.
+func retryStateFetch[T any](ctx context.Context, retries uint8, f func() (T, error)) (T, error) {
+	backoff := stateFetchBackoff
+	for attempt := 0; ; attempt++ {
+		v, err := f()
+		if err == nil || !IsTransient(err) || attempt >= int(retries) {
+			return v, err
+		}
+		select {
+		case <-ctx.Done():
+			return v, NewTransientError(ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
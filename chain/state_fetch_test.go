@@ -0,0 +1,169 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/state"
+)
+
+var errStateUnavailable = errors.New("state unavailable")
+
+// flakyVerifyContext fails to return a view [failures] times before
+// returning the view of [offlineTestVerifyContext].
+type flakyVerifyContext struct {
+	offlineTestVerifyContext
+
+	failures int
+	err      error
+	calls    int
+}
+
+func (c *flakyVerifyContext) View(ctx context.Context, verify bool) (state.View, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, c.err
+	}
+	return c.offlineTestVerifyContext.View(ctx, verify)
+}
+
+// blockingRootView returns its root once [release] is closed.
+type blockingRootView struct {
+	state.View
+
+	root    ids.ID
+	release chan struct{}
+}
+
+func (v *blockingRootView) GetMerkleRoot(context.Context) (ids.ID, error) {
+	<-v.release
+	return v.root, nil
+}
+
+// stateFetchTestRules overrides the number of state fetch retries of the rules
+// returned by [newOfflineTestRules].
+type stateFetchTestRules struct {
+	Rules
+
+	retries uint8
+}
+
+func (r *stateFetchTestRules) GetStateFetchRetries() uint8 { return r.retries }
+
+func TestIsTransient(t *testing.T) {
+	require := require.New(t)
+	require.False(IsTransient(errStateUnavailable))
+	require.False(IsTransient(nil))
+
+	err := NewTransientError(errStateUnavailable)
+	require.True(IsTransient(err))
+	require.ErrorIs(err, errStateUnavailable)
+	require.True(IsTransient(fmt.Errorf("%w: wrapped", err)))
+}
+
+func TestVerifyStateFetchRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		retries  uint8
+		err      error
+		calls    int
+		expected error
+	}{
+		{
+			name:  "no failures",
+			calls: 1,
+		},
+		{
+			name:     "failures within retries",
+			failures: 2,
+			retries:  2,
+			err:      NewTransientError(errStateUnavailable),
+			calls:    3,
+		},
+		{
+			name:     "failures exceed retries",
+			failures: 3,
+			retries:  2,
+			err:      NewTransientError(errStateUnavailable),
+			calls:    3,
+			expected: errStateUnavailable,
+		},
+		{
+			name:     "permanent failure",
+			failures: 1,
+			retries:  2,
+			err:      errStateUnavailable,
+			calls:    1,
+			expected: errStateUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+			ctx := context.TODO()
+
+			db, parentRoot := newOfflineTestState(ctx, require)
+			vm := &offlineTestVM{
+				r:            &stateFetchTestRules{newOfflineTestRules(ctrl, ids.GenerateTestID()), tt.retries},
+				lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+			}
+			sblk, err := ParseStatefulBlock(ctx, &StatefulBlock{
+				Prnt:      ids.GenerateTestID(),
+				Tmstmp:    1_000,
+				Hght:      1,
+				Txs:       []*Transaction{},
+				StateRoot: parentRoot,
+			}, nil, choices.Processing, vm)
+			require.NoError(err)
+
+			vctx := &flakyVerifyContext{
+				offlineTestVerifyContext: offlineTestVerifyContext{db},
+				failures:                 tt.failures,
+				err:                      tt.err,
+			}
+			err = sblk.innerVerify(ctx, vctx)
+			require.ErrorIs(err, tt.expected)
+			require.Equal(tt.calls, vctx.calls)
+		})
+	}
+}
+
+func TestGetMerkleRootCanceled(t *testing.T) {
+	require := require.New(t)
+
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	view := &blockingRootView{root: ids.GenerateTestID(), release: make(chan struct{})}
+
+	// Waiting for the root returns once [ctx] is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err := getMerkleRoot(ctx, view)
+	require.ErrorIs(err, context.Canceled)
+	require.True(IsTransient(err))
+
+	// A canceled [ctx] never waits for the root
+	_, err = getMerkleRoot(ctx, view)
+	require.ErrorIs(err, context.Canceled)
+
+	close(view.release)
+	root, err := getMerkleRoot(context.Background(), view)
+	require.NoError(err)
+	require.Equal(view.root, root)
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// StateUsage is the number of keys (and the total size of those keys and
+// their values) stored under a [StatePrefix].
+//
+// When used as a diff, either field may be negative.
+type StateUsage struct {
+	Keys  int64 `json:"keys"`
+	Bytes int64 `json:"bytes"`
+}
+
+// Add adds [o] to [u].
+func (u *StateUsage) Add(o StateUsage) {
+	u.Keys += o.Keys
+	u.Bytes += o.Bytes
+}
+
+// StatePrefix is a named keyspace of the state (all keys starting with
+// [Prefix]).
+type StatePrefix struct {
+	Name   string
+	Prefix []byte
+}
+
+// StatePrefixRegistry is the list of [StatePrefix]es the VM reports usage for.
+// Keys that do not match any registered prefix are accounted together (after
+// all registered prefixes).
+type StatePrefixRegistry struct {
+	prefixes []StatePrefix
+}
+
+func NewStatePrefixRegistry() *StatePrefixRegistry {
+	return &StatePrefixRegistry{}
+}
+
+// Register adds a prefix named [name]. [prefix] may not be empty and may not
+// overlap with (be a prefix of, or start with) a registered prefix, so each
+// key is accounted to at most one prefix.
+func (r *StatePrefixRegistry) Register(name string, prefix []byte) error {
+	if len(name) == 0 {
+		return fmt.Errorf("%w: empty name", ErrInvalidStatePrefix)
+	}
+	if len(prefix) == 0 {
+		return fmt.Errorf("%w: empty prefix for %s", ErrInvalidStatePrefix, name)
+	}
+	for _, p := range r.prefixes {
+		if p.Name == name {
+			return fmt.Errorf("%w: %s", ErrDuplicateStatePrefix, name)
+		}
+		if bytes.HasPrefix(p.Prefix, prefix) || bytes.HasPrefix(prefix, p.Prefix) {
+			return fmt.Errorf("%w: %s overlaps with %s", ErrDuplicateStatePrefix, name, p.Name)
+		}
+	}
+	r.prefixes = append(r.prefixes, StatePrefix{Name: name, Prefix: slices.Clone(prefix)})
+	return nil
+}
+
+// Prefixes returns the registered prefixes (in registration order).
+func (r *StatePrefixRegistry) Prefixes() []StatePrefix {
+	return slices.Clone(r.prefixes)
+}
+
+// Index returns the index of the prefix [key] is accounted to (or the number
+// of registered prefixes if [key] does not match any of them).
+func (r *StatePrefixRegistry) Index(key []byte) int {
+	for i, p := range r.prefixes {
+		if bytes.HasPrefix(key, p.Prefix) {
+			return i
+		}
+	}
+	return len(r.prefixes)
+}
+
+// KeyUsage returns the [StateUsage] of a single key/value pair.
+//
+// Only the raw size of the key and value is counted (not the overhead of
+// storing them in the state), so usage is a lower bound of the disk used.
+func KeyUsage(key []byte, value []byte) StateUsage {
+	return StateUsage{Keys: 1, Bytes: int64(len(key) + len(value))}
+}
+
+// Diff returns the change in [StateUsage] of each prefix (indexed like
+// [Index]) caused by applying [changes] to [parent].
+//
+// The previous value of each changed key is read from [parent].
+func (r *StatePrefixRegistry) Diff(
+	ctx context.Context,
+	parent state.Immutable,
+	changes map[string]maybe.Maybe[[]byte],
+) ([]StateUsage, error) {
+	diff := make([]StateUsage, len(r.prefixes)+1)
+	for k, v := range changes {
+		key := []byte(k)
+		d := &diff[r.Index(key)]
+		prev, err := parent.GetValue(ctx, key)
+		switch {
+		case err == nil:
+			u := KeyUsage(key, prev)
+			d.Keys -= u.Keys
+			d.Bytes -= u.Bytes
+		case !errors.Is(err, database.ErrNotFound):
+			return nil, err
+		}
+		if v.HasValue() {
+			d.Add(KeyUsage(key, v.Value()))
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/trace"
+)
+
+func TestStatePrefixRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix []byte
+		err    error
+	}{
+		{name: "", prefix: []byte{0x2}, err: ErrInvalidStatePrefix},
+		{name: "empty", prefix: nil, err: ErrInvalidStatePrefix},
+		{name: "balance", prefix: []byte{0x2}, err: ErrDuplicateStatePrefix},
+		{name: "longer", prefix: []byte{0x0, 0x1}, err: ErrDuplicateStatePrefix},
+		{name: "shorter", prefix: []byte{0x1}, err: ErrDuplicateStatePrefix},
+		{name: "sibling", prefix: []byte{0x1, 0x3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			r := NewStatePrefixRegistry()
+			require.NoError(r.Register("balance", []byte{0x0}))
+			require.NoError(r.Register("height", []byte{0x1, 0x2}))
+			require.ErrorIs(r.Register(tt.name, tt.prefix), tt.err)
+		})
+	}
+}
+
+func TestStatePrefixRegistryDiff(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	r := NewStatePrefixRegistry()
+	require.NoError(r.Register("a", []byte{0xa}))
+	require.NoError(r.Register("b", []byte{0xb}))
+	require.Equal(0, r.Index([]byte{0xa, 0x1}))
+	require.Equal(1, r.Index([]byte{0xb}))
+	require.Equal(2, r.Index([]byte{0xc, 0x1}))
+
+	db, err := merkledb.New(ctx, memdb.New(), merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor16,
+		RootGenConcurrency:          1,
+		HistoryLength:               100,
+		ValueNodeCacheSize:          units.MiB,
+		IntermediateNodeCacheSize:   units.MiB,
+		IntermediateWriteBufferSize: units.KiB,
+		IntermediateWriteBatchSize:  units.KiB,
+		Tracer:                      trace.Noop,
+	})
+	require.NoError(err)
+	require.NoError(db.Put([]byte{0xa, 0x1}, []byte("old")))
+	require.NoError(db.Put([]byte{0xb, 0x1}, []byte("deleted")))
+
+	diff, err := r.Diff(ctx, db, map[string]maybe.Maybe[[]byte]{
+		string([]byte{0xa, 0x1}): maybe.Some([]byte("longer")), // update
+		string([]byte{0xa, 0x2}): maybe.Some([]byte("new")),    // insert
+		string([]byte{0xb, 0x1}): maybe.Nothing[[]byte](),      // delete
+		string([]byte{0xb, 0x2}): maybe.Nothing[[]byte](),      // delete missing
+		string([]byte{0xc}):      maybe.Some([]byte{}),         // unregistered
+	})
+	require.NoError(err)
+	require.Equal([]StateUsage{
+		{Keys: 1, Bytes: 3 + 2 + 3},
+		{Keys: -1, Bytes: -(2 + 7)},
+		{Keys: 1, Bytes: 1},
+	}, diff)
+
+	// Errors other than [database.ErrNotFound] are returned
+	require.NoError(db.Close())
+	_, err = r.Diff(ctx, db, map[string]maybe.Maybe[[]byte]{
+		string([]byte{0xa, 0x1}): maybe.Nothing[[]byte](),
+	})
+	require.ErrorIs(err, database.ErrClosed)
+}
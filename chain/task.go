@@ -0,0 +1,397 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/math"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+	"github.com/ava-labs/hypersdk/utils"
+)
+
+const taskEntrySize = consts.Int64Len + ids.IDLen
+
+// Task is work scheduled by an [Action] (with [EnqueueTask]) that is executed
+// by the first block with a timestamp of at least [ExecuteAt], without a user
+// transaction.
+//
+// When a [Task] is executed, [Callback] is executed on behalf of [Actor] with
+// the [ID] of the [Task] as its action ID. [Callback] may enqueue more tasks
+// but they will not be executed until the next block.
+type Task struct {
+	ID        ids.ID
+	ExecuteAt int64
+	Actor     codec.Address
+	Callback  Action
+}
+
+// TaskID returns the ID of the [index]th [Task] enqueued by the action with
+// [actionID].
+func TaskID(actionID ids.ID, index uint16) ids.ID {
+	return utils.ToID(binary.BigEndian.AppendUint16(actionID[:], index))
+}
+
+// TaskStateKeys returns the keys an [Action] must include in
+// [Action.StateKeys] to enqueue the [Task] with [taskID] on behalf of
+// [actor].
+func TaskStateKeys(sm MetadataManager, actor codec.Address, taskID ids.ID) state.Keys {
+	prefix := sm.TaskQueueKey()
+	return state.Keys{
+		string(TaskQueueKey(prefix)):        state.All,
+		string(TaskCountKey(prefix, actor)): state.All,
+		string(TaskKey(sm.TaskKey(taskID))): state.Allocate | state.Write,
+	}
+}
+
+// TaskStateKeysMaxChunks returns the max chunks of the keys returned by
+// [TaskStateKeys].
+func TaskStateKeysMaxChunks() []uint16 {
+	return []uint16{TaskQueueKeyChunks, TaskCountKeyChunks, TaskKeyChunks}
+}
+
+// EnqueueTask schedules [task] to be executed by the first block with a
+// timestamp of at least [task.ExecuteAt].
+//
+// [task.ExecuteAt] may be at most [MaxTaskDelay] after [timestamp] (the
+// timestamp of the block enqueuing it) and [task.Actor] may have at most
+// [MaxQueuedTasksPerActor] queued [Task]s, so no actor can hold a large part
+// of the queue for long. If the queue already holds [MaxQueuedTasks] [Task]s,
+// [ErrTaskQueueFull] is returned.
+func EnqueueTask(ctx context.Context, mu state.Mutable, sm MetadataManager, timestamp int64, task *Task) error {
+	if task.ExecuteAt > timestamp+MaxTaskDelay.Milliseconds() {
+		return fmt.Errorf("%w: executeAt=%d max=%d", ErrTaskTooLate, task.ExecuteAt, timestamp+MaxTaskDelay.Milliseconds())
+	}
+	raw, err := marshalTask(task)
+	if err != nil {
+		return err
+	}
+	if len(raw) > MaxTaskSize {
+		return fmt.Errorf("%w: size=%d max=%d", ErrTaskTooLarge, len(raw), MaxTaskSize)
+	}
+	taskKey := TaskKey(sm.TaskKey(task.ID))
+	_, err = mu.GetValue(ctx, taskKey)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: %s", ErrDuplicateTask, task.ID)
+	case !errors.Is(err, database.ErrNotFound):
+		return err
+	}
+	prefix := sm.TaskQueueKey()
+	countKey := TaskCountKey(prefix, task.Actor)
+	count, err := getTaskCount(ctx, mu, countKey)
+	if err != nil {
+		return err
+	}
+	if count >= MaxQueuedTasksPerActor {
+		return fmt.Errorf("%w: actor=%s", ErrTaskQuotaFull, task.Actor)
+	}
+	queueKey := TaskQueueKey(prefix)
+	queue, err := getTaskQueue(ctx, mu, queueKey)
+	if err != nil {
+		return err
+	}
+	if len(queue) >= MaxQueuedTasks {
+		return ErrTaskQueueFull
+	}
+	entry := &taskEntry{executeAt: task.ExecuteAt, id: task.ID}
+	i, _ := slices.BinarySearchFunc(queue, entry, compareTaskEntries)
+	queue = slices.Insert(queue, i, entry)
+	if err := putTaskQueue(ctx, mu, queueKey, queue); err != nil {
+		return err
+	}
+	if err := putTaskCount(ctx, mu, countKey, count+1); err != nil {
+		return err
+	}
+	return mu.Insert(ctx, taskKey, raw)
+}
+
+// getTaskCount returns the number of queued [Task]s stored at [countKey].
+func getTaskCount(ctx context.Context, im state.Immutable, countKey []byte) (uint16, error) {
+	raw, err := im.GetValue(ctx, countKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(raw) != consts.Uint16Len {
+		return 0, fmt.Errorf("%w: task count size=%d", ErrInvalidObject, len(raw))
+	}
+	return binary.BigEndian.Uint16(raw), nil
+}
+
+// putTaskCount stores [count] at [countKey] (or removes it if [count] is 0).
+func putTaskCount(ctx context.Context, mu state.Mutable, countKey []byte, count uint16) error {
+	if count == 0 {
+		return mu.Remove(ctx, countKey)
+	}
+	return mu.Insert(ctx, countKey, binary.BigEndian.AppendUint16(nil, count))
+}
+
+// taskEntry is the position of a [Task] in the task queue.
+type taskEntry struct {
+	executeAt int64
+	id        ids.ID
+}
+
+// compareTaskEntries orders entries by execution time and then by ID.
+func compareTaskEntries(a, b *taskEntry) int {
+	switch {
+	case a.executeAt < b.executeAt:
+		return -1
+	case a.executeAt > b.executeAt:
+		return 1
+	default:
+		return bytes.Compare(a.id[:], b.id[:])
+	}
+}
+
+// getTaskQueue returns the entries of the task queue stored at [queueKey]
+// (ordered by execution time and then by ID).
+func getTaskQueue(ctx context.Context, im state.Immutable, queueKey []byte) ([]*taskEntry, error) {
+	raw, err := im.GetValue(ctx, queueKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(raw)%taskEntrySize != 0 || len(raw)/taskEntrySize > MaxQueuedTasks {
+		return nil, fmt.Errorf("%w: task queue size=%d", ErrInvalidObject, len(raw))
+	}
+	p := codec.NewReader(raw, len(raw))
+	queue := make([]*taskEntry, len(raw)/taskEntrySize)
+	for i := range queue {
+		entry := &taskEntry{executeAt: p.UnpackInt64(false)}
+		p.UnpackID(false, &entry.id)
+		queue[i] = entry
+	}
+	return queue, p.Err()
+}
+
+// putTaskQueue stores [queue] at [queueKey] (or removes it if [queue] is
+// empty).
+func putTaskQueue(ctx context.Context, mu state.Mutable, queueKey []byte, queue []*taskEntry) error {
+	if len(queue) == 0 {
+		return mu.Remove(ctx, queueKey)
+	}
+	p := codec.NewWriter(len(queue)*taskEntrySize, len(queue)*taskEntrySize)
+	for _, entry := range queue {
+		p.PackInt64(entry.executeAt)
+		p.PackID(entry.id)
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return mu.Insert(ctx, queueKey, p.Bytes())
+}
+
+// removeTaskEntry removes [entry] from the task queue stored at [queueKey].
+func removeTaskEntry(ctx context.Context, mu state.Mutable, queueKey []byte, entry *taskEntry) error {
+	queue, err := getTaskQueue(ctx, mu, queueKey)
+	if err != nil {
+		return err
+	}
+	i, ok := slices.BinarySearchFunc(queue, entry, compareTaskEntries)
+	if !ok {
+		return fmt.Errorf("%w: task %s not queued", ErrInvalidObject, entry.id)
+	}
+	return putTaskQueue(ctx, mu, queueKey, slices.Delete(queue, i, i+1))
+}
+
+// taskActor returns the [Task.Actor] of the [Task] encoded as [raw] (even if
+// its callback can't be parsed).
+func taskActor(raw []byte) (codec.Address, error) {
+	if len(raw) < codec.AddressLen {
+		return codec.EmptyAddress, fmt.Errorf("%w: task size=%d", ErrInvalidObject, len(raw))
+	}
+	return codec.Address(raw[:codec.AddressLen]), nil
+}
+
+func marshalTask(task *Task) ([]byte, error) {
+	p := codec.NewWriter(codec.AddressLen+consts.ByteLen+task.Callback.Size(), consts.NetworkSizeLimit)
+	p.PackAddress(task.Actor)
+	p.PackByte(task.Callback.GetTypeID())
+	task.Callback.Marshal(p)
+	return p.Bytes(), p.Err()
+}
+
+func unmarshalTask(raw []byte, entry *taskEntry, actionRegistry *codec.TypeParser[Action, bool]) (*Task, error) {
+	p := codec.NewReader(raw, MaxTaskSize)
+	task := &Task{ID: entry.id, ExecuteAt: entry.executeAt}
+	p.UnpackAddress(&task.Actor)
+	callbackType := p.UnpackByte()
+	unmarshalCallback, ok := actionRegistry.LookupIndex(callbackType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d is unknown action type", ErrInvalidObject, callbackType)
+	}
+	callback, err := unmarshalCallback(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal callback", err)
+	}
+	task.Callback = callback
+	if !p.Empty() {
+		return nil, fmt.Errorf("%w: remaining=%d", ErrInvalidObject, len(raw)-p.Offset())
+	}
+	return task, p.Err()
+}
+
+// taskUnits returns the units consumed by executing the [Task] encoded as
+// [raw] (with [callback]) that accesses [stateKeys] at [timestamp].
+func taskUnits(r Rules, raw []byte, callback Action, stateKeys state.Keys, timestamp int64) (fees.Dimensions, error) {
+	computeOp := math.NewUint64Operator(r.GetBaseComputeUnits())
+	if callback != nil {
+		computeOp.Add(callback.ComputeUnits(r))
+	}
+	compute, err := computeOp.Value()
+	if err != nil {
+		return fees.Dimensions{}, err
+	}
+	reads, allocates, writes, err := storageUnits(r, stateKeys, IsActive(r, AllocatePermissionsFork, timestamp), func(k string) (uint16, bool) {
+		return keys.MaxChunks([]byte(k))
+	})
+	if err != nil {
+		return fees.Dimensions{}, err
+	}
+	return fees.Dimensions{uint64(len(raw)), compute, reads, allocates, writes}, nil
+}
+
+// executeTasks executes up to [MaxTasksPerBlock] [Task]s that are due at
+// [timestamp] in [im] (ordered by execution time and then by ID) and commits
+// their changes to [ts].
+//
+// The units used by each [Task] are consumed from [feeManager]. If a [Task]
+// does not fit in the block, it (and all [Task]s after it) remain in the
+// queue until the next block.
+//
+// If the callback of a [Task] can't be parsed or fails, the [Task] is
+// removed without applying any of its changes.
+func executeTasks(
+	ctx context.Context,
+	c executionConfig,
+	im state.Immutable,
+	ts *tstate.TState,
+	feeManager *fees.Manager,
+	r Rules,
+	timestamp int64,
+) (int, error) {
+	var (
+		sm       = c.StateManager()
+		prefix   = sm.TaskQueueKey()
+		queueKey = TaskQueueKey(prefix)
+	)
+	queue, err := getTaskQueue(ctx, im, queueKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(queue) == 0 {
+		return 0, nil
+	}
+	actionRegistry, _ := c.Registry()
+
+	// We only consider the tasks that were queued before this block, so any
+	// tasks enqueued during execution are executed by a later block.
+	due := 0
+	for due < len(queue) && due < MaxTasksPerBlock && queue[due].executeAt <= timestamp {
+		due++
+	}
+	for i, entry := range queue[:due] {
+		if err := checkContext(ctx); err != nil {
+			return i, err
+		}
+		taskKey := TaskKey(sm.TaskKey(entry.id))
+		raw, err := im.GetValue(ctx, taskKey)
+		if err != nil {
+			return i, fmt.Errorf("%w: unable to load task %s", err, entry.id)
+		}
+		actor, err := taskActor(raw)
+		if err != nil {
+			return i, err
+		}
+		stateKeys := make(state.Keys)
+		task, parseErr := unmarshalTask(raw, entry, actionRegistry)
+		var callback Action
+		if parseErr == nil {
+			callback = task.Callback
+			for k, v := range callback.StateKeys(task.Actor, task.ID) {
+				if !stateKeys.Add(k, v) {
+					return i, fmt.Errorf("%w: invalid state key in task %s", ErrInvalidKeyValue, entry.id)
+				}
+			}
+		}
+		countKey := TaskCountKey(prefix, actor)
+		stateKeys.Add(string(queueKey), state.Allocate|state.Write)
+		stateKeys.Add(string(countKey), state.Allocate|state.Write)
+		stateKeys.Add(string(taskKey), state.Allocate|state.Write)
+
+		// Ensure the task fits in the block
+		units, err := taskUnits(r, raw, callback, stateKeys, timestamp)
+		if err != nil {
+			return i, err
+		}
+		if ok, _ := feeManager.Consume(units, r.GetMaxBlockUnits()); !ok {
+			return i, nil
+		}
+
+		// Fetch state keys from the parent state ([ts] includes any changes
+		// made by previous tasks)
+		storage := make(map[string][]byte, len(stateKeys))
+		for k := range stateKeys {
+			v, err := im.GetValue(ctx, []byte(k))
+			if errors.Is(err, database.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return i, err
+			}
+			storage[k] = v
+		}
+		tsv := ts.NewView(stateKeys, storage)
+		if !IsActive(r, AllocatePermissionsFork, timestamp) {
+			tsv.AllowImplicitAllocate()
+		}
+
+		// Remove the task before executing it (it is never executed twice)
+		if err := removeTaskEntry(ctx, tsv, queueKey, entry); err != nil {
+			return i, err
+		}
+		count, err := getTaskCount(ctx, tsv, countKey)
+		if err != nil {
+			return i, err
+		}
+		if count == 0 {
+			return i, fmt.Errorf("%w: no tasks queued by %s", ErrInvalidObject, actor)
+		}
+		if err := putTaskCount(ctx, tsv, countKey, count-1); err != nil {
+			return i, err
+		}
+		if err := tsv.Remove(ctx, taskKey); err != nil {
+			return i, err
+		}
+
+		// Execute callback
+		if parseErr == nil {
+			start := tsv.OpIndex()
+			if _, _, _, err := execute(WithScratch(ctx), callback, r, tsv, timestamp, task.Actor, task.ID); err != nil {
+				tsv.Rollback(ctx, start)
+			}
+		}
+		tsv.Commit()
+	}
+	return due, nil
+}
@@ -0,0 +1,352 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/executor"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+var errTaskFailed = errors.New("task failed")
+
+// taskTestState is an in-memory [state.Mutable].
+type taskTestState map[string][]byte
+
+func (s taskTestState) GetValue(_ context.Context, key []byte) ([]byte, error) {
+	v, ok := s[string(key)]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return v, nil
+}
+
+func (s taskTestState) Insert(_ context.Context, key []byte, value []byte) error {
+	s[string(key)] = value
+	return nil
+}
+
+func (s taskTestState) Remove(_ context.Context, key []byte) error {
+	delete(s, string(key))
+	return nil
+}
+
+// apply writes the changes in [ts] to [s] (as if a block was accepted).
+func (s taskTestState) apply(ts *tstate.TState) {
+	for k, v := range ts.ChangedKeys() {
+		if v.IsNothing() {
+			delete(s, k)
+			continue
+		}
+		s[k] = v.Value()
+	}
+}
+
+func taskMarkerKey(taskID ids.ID) []byte {
+	return keys.EncodeChunks(append([]byte{0x6}, taskID[:]...), 1)
+}
+
+// taskTestAction records its execution in [executed] and in state (at
+// [taskMarkerKey]). If [enqueue] is set, it enqueues another task that is
+// immediately due.
+type taskTestAction struct {
+	executed *[]ids.ID
+
+	enqueue bool
+	fail    bool
+}
+
+func (*taskTestAction) GetTypeID() uint8                { return 1 }
+func (*taskTestAction) ValidRange(Rules) (int64, int64) { return -1, -1 }
+func (*taskTestAction) Size() int                       { return 2 }
+func (*taskTestAction) ComputeUnits(Rules) uint64       { return 1 }
+func (*taskTestAction) StateKeysMaxChunks() []uint16    { return nil }
+
+func (a *taskTestAction) Marshal(p *codec.Packer) {
+	p.PackBool(a.enqueue)
+	p.PackBool(a.fail)
+}
+
+func (a *taskTestAction) StateKeys(actor codec.Address, taskID ids.ID) state.Keys {
+	stateKeys := state.Keys{string(taskMarkerKey(taskID)): state.All}
+	if a.enqueue {
+		for k, v := range TaskStateKeys(&testStateManager{}, actor, TaskID(taskID, 0)) {
+			stateKeys.Add(k, v)
+		}
+	}
+	return stateKeys
+}
+
+func (a *taskTestAction) Execute(
+	ctx context.Context,
+	_ Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	taskID ids.ID,
+) ([][]byte, error) {
+	*a.executed = append(*a.executed, taskID)
+	if err := mu.Insert(ctx, taskMarkerKey(taskID), []byte{1}); err != nil {
+		return nil, err
+	}
+	if a.fail {
+		return nil, errTaskFailed
+	}
+	if a.enqueue {
+		return nil, EnqueueTask(ctx, mu, &testStateManager{}, timestamp, &Task{
+			ID:        TaskID(taskID, 0),
+			ExecuteAt: timestamp,
+			Actor:     actor,
+			Callback:  &taskTestAction{executed: a.executed},
+		})
+	}
+	return nil, nil
+}
+
+// taskTestConfig is an [executionConfig] that parses [taskTestAction]s
+// that record their execution in [executed].
+type taskTestConfig struct {
+	executed []ids.ID
+}
+
+func (*taskTestConfig) StateManager() StateManager                  { return &testStateManager{} }
+func (*taskTestConfig) GetStateFetchConcurrency() int               { return 1 }
+func (*taskTestConfig) GetTransactionExecutionCores() int           { return 1 }
+func (*taskTestConfig) GetExecutorVerifyRecorder() executor.Metrics { return nil }
+func (*taskTestConfig) GetKeyAccessRecorder() KeyAccessRecorder     { return nil }
+func (*taskTestConfig) RecordActionPanic()                          {}
+
+func (c *taskTestConfig) Registry() (ActionRegistry, AuthRegistry) {
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	_ = actionRegistry.Register(1, func(p *codec.Packer) (Action, error) {
+		a := &taskTestAction{executed: &c.executed}
+		a.enqueue = p.UnpackBool()
+		a.fail = p.UnpackBool()
+		return a, p.Err()
+	}, false)
+	return actionRegistry, nil
+}
+
+// enqueue adds a [taskTestAction] due at [executeAt] to [s] (enqueued at
+// time 0 by a new actor).
+func (c *taskTestConfig) enqueue(require *require.Assertions, s taskTestState, executeAt int64, action *taskTestAction) ids.ID {
+	id, err := c.enqueueAs(s, codec.CreateAddress(0, ids.GenerateTestID()), 0, executeAt, action)
+	require.NoError(err)
+	return id
+}
+
+// enqueueAs adds a [taskTestAction] of [actor] due at [executeAt] to [s]
+// (enqueued at [timestamp]).
+func (c *taskTestConfig) enqueueAs(s taskTestState, actor codec.Address, timestamp int64, executeAt int64, action *taskTestAction) (ids.ID, error) {
+	action.executed = &c.executed
+	id := ids.GenerateTestID()
+	return id, EnqueueTask(context.TODO(), s, &testStateManager{}, timestamp, &Task{
+		ID:        id,
+		ExecuteAt: executeAt,
+		Actor:     actor,
+		Callback:  action,
+	})
+}
+
+// executeBlock executes the tasks in [s] that are due at [timestamp] and
+// applies the changes to [s].
+func (c *taskTestConfig) executeBlock(require *require.Assertions, r Rules, s taskTestState, timestamp int64) int {
+	c.executed = nil
+	ts := tstate.New(0)
+	executed, err := executeTasks(context.TODO(), c, s, ts, fees.NewManager(nil), r, timestamp)
+	require.NoError(err)
+	require.Len(c.executed, executed)
+	s.apply(ts)
+	return executed
+}
+
+func (*taskTestConfig) queue(require *require.Assertions, s taskTestState) []*taskEntry {
+	queue, err := getTaskQueue(context.TODO(), s, TaskQueueKey((&testStateManager{}).TaskQueueKey()))
+	require.NoError(err)
+	return queue
+}
+
+func TestEnqueueTask(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	c := &taskTestConfig{}
+	s := make(taskTestState)
+	sm := &testStateManager{}
+
+	// Duplicate tasks are rejected
+	id := c.enqueue(require, s, 1_000, &taskTestAction{})
+	err := EnqueueTask(ctx, s, sm, 0, &Task{
+		ID:        id,
+		ExecuteAt: 2_000,
+		Actor:     codec.CreateAddress(0, ids.GenerateTestID()),
+		Callback:  &taskTestAction{},
+	})
+	require.ErrorIs(err, ErrDuplicateTask)
+
+	// Tasks can be enqueued until the queue is full (regardless of their
+	// IDs)
+	for i := 1; i < MaxQueuedTasks; i++ {
+		c.enqueue(require, s, int64(i), &taskTestAction{})
+	}
+	_, err = c.enqueueAs(s, codec.CreateAddress(0, ids.GenerateTestID()), 0, 2_000, &taskTestAction{})
+	require.ErrorIs(err, ErrTaskQueueFull)
+	queue := c.queue(require, s)
+	require.Len(queue, MaxQueuedTasks)
+	require.True(slices.IsSortedFunc(queue, compareTaskEntries))
+
+	// The queue, the count of each actor, and each task are stored
+	require.Len(s, 1+2*MaxQueuedTasks)
+}
+
+func TestEnqueueTaskLimits(t *testing.T) {
+	require := require.New(t)
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+	c := &taskTestConfig{}
+	s := make(taskTestState)
+	sm := &testStateManager{}
+	now := int64(1_000)
+	latest := now + MaxTaskDelay.Milliseconds()
+
+	// Tasks can't be scheduled more than [MaxTaskDelay] ahead
+	attacker := codec.CreateAddress(0, ids.GenerateTestID())
+	_, err := c.enqueueAs(s, attacker, now, latest+1, &taskTestAction{})
+	require.ErrorIs(err, ErrTaskTooLate)
+
+	// A single actor can only hold [MaxQueuedTasksPerActor] tasks
+	for i := 0; i < MaxQueuedTasksPerActor; i++ {
+		_, err := c.enqueueAs(s, attacker, now, latest, &taskTestAction{})
+		require.NoError(err)
+	}
+	_, err = c.enqueueAs(s, attacker, now, latest, &taskTestAction{})
+	require.ErrorIs(err, ErrTaskQuotaFull)
+	honest := codec.CreateAddress(0, ids.GenerateTestID())
+	_, err = c.enqueueAs(s, honest, now, now+1, &taskTestAction{})
+	require.NoError(err)
+
+	// An attacker with many actors can fill the queue
+	for len(c.queue(require, s)) < MaxQueuedTasks {
+		sybil := codec.CreateAddress(0, ids.GenerateTestID())
+		for i := 0; i < MaxQueuedTasksPerActor && len(c.queue(require, s)) < MaxQueuedTasks; i++ {
+			_, err := c.enqueueAs(s, sybil, now, latest, &taskTestAction{})
+			require.NoError(err)
+		}
+	}
+	_, err = c.enqueueAs(s, honest, now, now+1, &taskTestAction{})
+	require.ErrorIs(err, ErrTaskQueueFull)
+
+	// ...but only until [MaxTaskDelay] has passed
+	require.Equal(1, c.executeBlock(require, r, s, now+1))
+	_, err = c.enqueueAs(s, attacker, now+1, latest, &taskTestAction{})
+	require.ErrorIs(err, ErrTaskQuotaFull)
+	for executed := 0; executed < MaxQueuedTasks-1; {
+		executed += c.executeBlock(require, r, s, latest)
+	}
+	require.Empty(c.queue(require, s))
+	require.NotContains(s, string(TaskCountKey(sm.TaskQueueKey(), attacker)))
+	_, err = c.enqueueAs(s, attacker, latest, latest, &taskTestAction{})
+	require.NoError(err)
+}
+
+func TestExecuteTasksOverdue(t *testing.T) {
+	require := require.New(t)
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+	c := &taskTestConfig{}
+	s := make(taskTestState)
+
+	// Tasks that are overdue are executed in order of execution time (and
+	// tasks that aren't due are left in the queue)
+	third := c.enqueue(require, s, 3_000, &taskTestAction{})
+	first := c.enqueue(require, s, 1_000, &taskTestAction{})
+	later := c.enqueue(require, s, 20_000, &taskTestAction{})
+	second := c.enqueue(require, s, 2_000, &taskTestAction{})
+	require.Equal(3, c.executeBlock(require, r, s, 10_000))
+	require.Equal([]ids.ID{first, second, third}, c.executed)
+	for _, id := range []ids.ID{first, second, third} {
+		require.Contains(s, string(taskMarkerKey(id)))
+		require.NotContains(s, string(TaskKey((&testStateManager{}).TaskKey(id))))
+	}
+	queue := c.queue(require, s)
+	require.Len(queue, 1)
+	require.Equal(later, queue[0].id)
+
+	// Tasks due at the same time are executed in order of ID
+	a := c.enqueue(require, s, 15_000, &taskTestAction{})
+	b := c.enqueue(require, s, 15_000, &taskTestAction{})
+	require.Equal(2, c.executeBlock(require, r, s, 15_000))
+	expected := []ids.ID{a, b}
+	if b.Compare(a) < 0 {
+		expected = []ids.ID{b, a}
+	}
+	require.Equal(expected, c.executed)
+
+	// Remaining task is executed once due
+	require.Zero(c.executeBlock(require, r, s, 19_999))
+	require.Equal(1, c.executeBlock(require, r, s, 20_000))
+	require.Empty(c.queue(require, s))
+	require.Zero(c.executeBlock(require, r, s, 30_000))
+}
+
+func TestExecuteTasksPerBlockBound(t *testing.T) {
+	require := require.New(t)
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+	c := &taskTestConfig{}
+	s := make(taskTestState)
+
+	for i := 0; i < MaxTasksPerBlock+5; i++ {
+		c.enqueue(require, s, int64(i), &taskTestAction{})
+	}
+	require.Equal(MaxTasksPerBlock, c.executeBlock(require, r, s, 1_000))
+	require.Len(c.queue(require, s), 5)
+	require.Equal(5, c.executeBlock(require, r, s, 1_000))
+	require.Empty(c.queue(require, s))
+}
+
+func TestExecuteTasksReentrant(t *testing.T) {
+	require := require.New(t)
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+	c := &taskTestConfig{}
+	s := make(taskTestState)
+
+	// Tasks enqueued by a task are not executed in the same block (even if
+	// they are due)
+	parent := c.enqueue(require, s, 1_000, &taskTestAction{enqueue: true})
+	require.Equal(1, c.executeBlock(require, r, s, 1_000))
+	require.Equal([]ids.ID{parent}, c.executed)
+	queue := c.queue(require, s)
+	require.Len(queue, 1)
+	child := TaskID(parent, 0)
+	require.Equal(child, queue[0].id)
+
+	require.Equal(1, c.executeBlock(require, r, s, 1_100))
+	require.Equal([]ids.ID{child}, c.executed)
+	require.Empty(c.queue(require, s))
+}
+
+func TestExecuteTasksFailure(t *testing.T) {
+	require := require.New(t)
+	r := newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID())
+	c := &taskTestConfig{}
+	s := make(taskTestState)
+
+	// Changes of a failed task are reverted but the task is still removed
+	failed := c.enqueue(require, s, 1_000, &taskTestAction{fail: true})
+	require.Equal(1, c.executeBlock(require, r, s, 1_000))
+	require.NotContains(s, string(taskMarkerKey(failed)))
+	require.Empty(c.queue(require, s))
+	require.NotContains(s, string(TaskKey((&testStateManager{}).TaskKey(failed))))
+	require.Empty(s)
+}
@@ -5,7 +5,9 @@ package chain
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 
@@ -30,13 +32,23 @@ type Transaction struct {
 	Base *Base `json:"base"`
 
 	Actions []Action `json:"actions"`
-	Auth    Auth     `json:"auth"`
+
+	// Memo is an optional reference (i.e. a payment reference) that is stored
+	// in state at [MetadataManager.MemoKey] when the transaction is executed.
+	Memo []byte `json:"memo"`
+
+	Auth Auth `json:"auth"`
 
 	digest    []byte
 	bytes     []byte
 	size      int
 	id        ids.ID
 	stateKeys state.Keys
+
+	// bundle is the [Bundle] the transaction was submitted in (if any). It
+	// is not part of the transaction, so a transaction parsed from a block
+	// never has one.
+	bundle *Bundle
 }
 
 func NewTx(base *Base, actions []Action) *Transaction {
@@ -50,20 +62,53 @@ func (t *Transaction) Digest() ([]byte, error) {
 	if len(t.digest) > 0 {
 		return t.digest, nil
 	}
-	size := t.Base.Size() + consts.Uint8Len
+	size := t.Base.Size() + consts.Uint8Len + t.memoSize()
 	for _, action := range t.Actions {
 		size += consts.ByteLen + action.Size()
 	}
 	p := codec.NewWriter(size, consts.NetworkSizeLimit)
 	t.Base.Marshal(p)
-	p.PackByte(uint8(len(t.Actions)))
+	if err := t.packActionCount(p); err != nil {
+		return nil, err
+	}
 	for _, action := range t.Actions {
 		p.PackByte(action.GetTypeID())
 		action.Marshal(p)
 	}
+	t.packMemo(p)
 	return p.Bytes(), p.Err()
 }
 
+// packActionCount packs the number of [t.Actions] (with [txMemoFlag] set if
+// [t] has a [Transaction.Memo]).
+func (t *Transaction) packActionCount(p *codec.Packer) error {
+	if len(t.Actions) > MaxTxActions {
+		return ErrTooManyActions
+	}
+	count := uint8(len(t.Actions))
+	if len(t.Memo) > 0 {
+		count |= txMemoFlag
+	}
+	p.PackByte(count)
+	return nil
+}
+
+// packMemo packs [t.Memo] (if any). A [Transaction] without a memo packs
+// nothing, so it is encoded the same way before [TxMemoFork].
+func (t *Transaction) packMemo(p *codec.Packer) {
+	if len(t.Memo) > 0 {
+		p.PackLimitedBytes(t.Memo, MaxTxMemoSize)
+	}
+}
+
+// memoSize is the number of bytes [packMemo] packs.
+func (t *Transaction) memoSize() int {
+	if len(t.Memo) == 0 {
+		return 0
+	}
+	return codec.BytesLen(t.Memo)
+}
+
 func (t *Transaction) Sign(
 	factory AuthFactory,
 	actionRegistry ActionRegistry,
@@ -103,13 +148,23 @@ func (t *Transaction) Expiry() int64 { return t.Base.Timestamp }
 
 func (t *Transaction) MaxFee() uint64 { return t.Base.MaxFee }
 
-func (t *Transaction) StateKeys(sm StateManager) (state.Keys, error) {
+// StateKeys returns all keys that could be touched by [t] (including the
+// [ACLKey] of the actor for any action restricted by [r] and the
+// [IdempotencyKey] of each [IdempotentAction]).
+//
+// A key declared more than once (by different actions, the sponsor, or the
+// memo) is declared with the union of its permissions. Because [state.Write]
+// and [state.Allocate] include [state.Read], a key declared as [state.Read]
+// by one action and [state.Write] by another is leased exclusively for the
+// whole transaction.
+func (t *Transaction) StateKeys(sm StateManager, r Rules) (state.Keys, error) {
 	if t.stateKeys != nil {
 		return t.stateKeys, nil
 	}
 	stateKeys := make(state.Keys)
 
-	// Verify the formatting of state keys passed by the controller
+	// Verify the formatting of state keys passed by the controller (duplicate
+	// keys are merged by [state.Keys.Add])
 	for i, action := range t.Actions {
 		for k, v := range action.StateKeys(t.Auth.Actor(), CreateActionID(t.ID(), uint8(i))) {
 			if !stateKeys.Add(k, v) {
@@ -122,17 +177,43 @@ func (t *Transaction) StateKeys(sm StateManager) (state.Keys, error) {
 			return nil, ErrInvalidKeyValue
 		}
 	}
+	if len(t.Memo) > 0 {
+		if !stateKeys.Add(string(t.memoKey(sm)), state.Allocate|state.Write) {
+			return nil, ErrInvalidKeyValue
+		}
+	}
+	for _, k := range t.aclKeys(sm, r) {
+		if !stateKeys.Add(k, state.Read) {
+			return nil, ErrInvalidKeyValue
+		}
+	}
+	for _, k := range t.idempotencyKeys(sm, r) {
+		if k == nil {
+			continue
+		}
+		if !stateKeys.Add(string(k), state.Allocate|state.Write) {
+			return nil, ErrInvalidKeyValue
+		}
+	}
 
 	// Cache keys if called again
 	t.stateKeys = stateKeys
 	return stateKeys, nil
 }
 
+// memoKey is the state key [t.Memo] is stored at.
+func (t *Transaction) memoKey(sm StateManager) []byte {
+	return MemoKey(sm.MemoKey(t.ID()))
+}
+
 // Sponsor is the [codec.Address] that pays fees for this transaction.
 func (t *Transaction) Sponsor() codec.Address { return t.Auth.Sponsor() }
 
 // Units is charged whether or not a transaction is successful.
-func (t *Transaction) Units(sm StateManager, r Rules) (fees.Dimensions, error) {
+//
+// [timestamp] is the time [t] is executed at (which determines whether
+// [AllocatePermissionsFork] is active).
+func (t *Transaction) Units(sm StateManager, r Rules, timestamp int64) (fees.Dimensions, error) {
 	// Calculate compute usage
 	computeOp := math.NewUint64Operator(r.GetBaseComputeUnits())
 	for _, action := range t.Actions {
@@ -145,45 +226,79 @@ func (t *Transaction) Units(sm StateManager, r Rules) (fees.Dimensions, error) {
 	}
 
 	// Calculate storage usage
-	stateKeys, err := t.StateKeys(sm)
+	stateKeys, err := t.StateKeys(sm, r)
 	if err != nil {
 		return fees.Dimensions{}, err
 	}
+	var memoKey string
+	if len(t.Memo) > 0 {
+		memoKey = string(t.memoKey(sm))
+	}
+	reads, allocates, writes, err := storageUnits(r, stateKeys, IsActive(r, AllocatePermissionsFork, timestamp), func(k string) (uint16, bool) {
+		if k == memoKey {
+			// The memo key is always suffixed with [MemoKeyChunks] (so it can be
+			// looked up by txID), so we only charge for the chunks actually used.
+			return keys.NumChunks(t.Memo)
+		}
+		return keys.MaxChunks([]byte(k))
+	})
+	if err != nil {
+		return fees.Dimensions{}, err
+	}
+	return fees.Dimensions{uint64(t.Size()), maxComputeUnits, reads, allocates, writes}, nil
+}
+
+// storageUnits returns the units required to read, allocate, and write each
+// key in [stateKeys], where [chunks] returns the number of chunks charged for
+// the value of a key.
+//
+// If [byPermission] is set (see [AllocatePermissionsFork]), keys are only
+// charged to allocate (or write) if they have [state.Allocate] (or
+// [state.Write]). Otherwise, every key is charged for all operations.
+func storageUnits(
+	r Rules,
+	stateKeys state.Keys,
+	byPermission bool,
+	chunks func(string) (uint16, bool),
+) (uint64, uint64, uint64, error) {
 	readsOp := math.NewUint64Operator(0)
 	allocatesOp := math.NewUint64Operator(0)
 	writesOp := math.NewUint64Operator(0)
-	for k := range stateKeys {
-		// Compute key costs
-		readsOp.Add(r.GetStorageKeyReadUnits())
-		allocatesOp.Add(r.GetStorageKeyAllocateUnits())
-		writesOp.Add(r.GetStorageKeyWriteUnits())
-
-		// Compute value costs
-		maxChunks, ok := keys.MaxChunks([]byte(k))
+	for k, p := range stateKeys {
+		maxChunks, ok := chunks(k)
 		if !ok {
-			return fees.Dimensions{}, ErrInvalidKeyValue
+			return 0, 0, 0, ErrInvalidKeyValue
 		}
+
+		// Compute key and value costs (every permission includes [state.Read])
+		readsOp.Add(r.GetStorageKeyReadUnits())
 		readsOp.MulAdd(uint64(maxChunks), r.GetStorageValueReadUnits())
-		allocatesOp.MulAdd(uint64(maxChunks), r.GetStorageValueAllocateUnits())
-		writesOp.MulAdd(uint64(maxChunks), r.GetStorageValueWriteUnits())
+		if !byPermission || p.Has(state.Allocate) {
+			allocatesOp.Add(r.GetStorageKeyAllocateUnits())
+			allocatesOp.MulAdd(uint64(maxChunks), r.GetStorageValueAllocateUnits())
+		}
+		if !byPermission || p.Has(state.Write) {
+			writesOp.Add(r.GetStorageKeyWriteUnits())
+			writesOp.MulAdd(uint64(maxChunks), r.GetStorageValueWriteUnits())
+		}
 	}
 	reads, err := readsOp.Value()
 	if err != nil {
-		return fees.Dimensions{}, err
+		return 0, 0, 0, err
 	}
 	allocates, err := allocatesOp.Value()
 	if err != nil {
-		return fees.Dimensions{}, err
+		return 0, 0, 0, err
 	}
 	writes, err := writesOp.Value()
 	if err != nil {
-		return fees.Dimensions{}, err
+		return 0, 0, 0, err
 	}
-	return fees.Dimensions{uint64(t.Size()), maxComputeUnits, reads, allocates, writes}, nil
+	return reads, allocates, writes, nil
 }
 
 // EstimateUnits provides a pessimistic estimate (some key accesses may be duplicates) of the cost
-// to execute a transaction.
+// to execute a transaction (without a [Transaction.Memo]).
 //
 // This is typically used during transaction construction.
 func EstimateUnits(r Rules, actions []Action, authFactory AuthFactory) (fees.Dimensions, error) {
@@ -202,6 +317,9 @@ func EstimateUnits(r Rules, actions []Action, authFactory AuthFactory) (fees.Dim
 		bandwidth += consts.ByteLen + uint64(action.Size())
 		actionStateKeysMaxChunks := action.StateKeysMaxChunks()
 		stateKeysMaxChunks = append(stateKeysMaxChunks, actionStateKeysMaxChunks...)
+		if a, ok := action.(IdempotentAction); ok && r.GetEnforceIdempotencyKeys() && len(a.IdempotencyKey()) > 0 {
+			stateKeysMaxChunks = append(stateKeysMaxChunks, IdempotencyKeyChunks)
+		}
 		computeOp.Add(action.ComputeUnits(r))
 	}
 	authBandwidth, authCompute := authFactory.MaxUnits()
@@ -243,14 +361,12 @@ func EstimateUnits(r Rules, actions []Action, authFactory AuthFactory) (fees.Dim
 	return fees.Dimensions{bandwidth, compute, reads, allocates, writes}, nil
 }
 
-func (t *Transaction) PreExecute(
-	ctx context.Context,
-	feeManager *fees.Manager,
-	s StateManager,
-	r Rules,
-	im state.Immutable,
-	timestamp int64,
-) error {
+// verifyInclusion performs the checks of [PreExecute] that don't require
+// access to state.
+func (t *Transaction) verifyInclusion(r Rules, timestamp int64) error {
+	if len(t.Memo) > 0 && !IsActive(r, TxMemoFork, timestamp) {
+		return ErrMemoNotActivated
+	}
 	if err := t.Base.Execute(r.ChainID(), r, timestamp); err != nil {
 		return err
 	}
@@ -273,7 +389,24 @@ func (t *Transaction) PreExecute(
 	if end >= 0 && timestamp > end {
 		return ErrAuthNotActivated
 	}
-	units, err := t.Units(s, r)
+	return nil
+}
+
+func (t *Transaction) PreExecute(
+	ctx context.Context,
+	feeManager *fees.Manager,
+	s StateManager,
+	r Rules,
+	im state.Immutable,
+	timestamp int64,
+) error {
+	if err := t.verifyInclusion(r, timestamp); err != nil {
+		return err
+	}
+	if err := t.verifyAccess(ctx, s, r, im); err != nil {
+		return err
+	}
+	units, err := t.Units(s, r, timestamp)
 	if err != nil {
 		return err
 	}
@@ -296,8 +429,28 @@ func (t *Transaction) Execute(
 	ts *tstate.TStateView,
 	timestamp int64,
 ) (*Result, error) {
+	return t.execute(ctx, feeManager, s, r, ts, timestamp, nil)
+}
+
+// execute is [Execute] that records the execution time of each action in
+// [profile] (if not nil).
+func (t *Transaction) execute(
+	ctx context.Context,
+	feeManager *fees.Manager,
+	s StateManager,
+	r Rules,
+	ts *tstate.TStateView,
+	timestamp int64,
+	profile *BlockProfile,
+) (*Result, error) {
+	// Before [AllocatePermissionsFork], keys declared with [state.Write] could
+	// be created without [state.Allocate]
+	if !IsActive(r, AllocatePermissionsFork, timestamp) {
+		ts.AllowImplicitAllocate()
+	}
+
 	// Always charge fee first
-	units, err := t.Units(s, r)
+	units, err := t.Units(s, r, timestamp)
 	if err != nil {
 		// Should never happen
 		return nil, err
@@ -313,6 +466,14 @@ func (t *Transaction) Execute(
 		return nil, err
 	}
 
+	// Store memo regardless of whether actions succeed (it is paid for by the fee)
+	if len(t.Memo) > 0 {
+		if err := ts.Insert(ctx, t.memoKey(s), t.Memo); err != nil {
+			// Should never happen
+			return nil, err
+		}
+	}
+
 	// We create a temp state checkpoint to ensure we don't commit failed actions to state.
 	//
 	// We should favor reverting over returning an error because the caller won't be charged
@@ -320,12 +481,40 @@ func (t *Transaction) Execute(
 	var (
 		actionStart   = ts.OpIndex()
 		resultOutputs = [][][]byte{}
+		actionCtx     = WithScratch(ctx)
+		contain       = IsActive(r, ContainPanicsFork, timestamp)
+		metered       uint64 // compute units left unconsumed by [MeteredAction]s
+		refunded      uint64 // compute units refunded by [RefundingAction]s
+
+		idempotencyKeys = t.idempotencyKeys(s, r)
 	)
 	for i, action := range t.Actions {
-		outputs, err := action.Execute(ctx, r, ts, timestamp, t.Auth.Actor(), CreateActionID(t.ID(), uint8(i)))
+		// An operation that was already applied fails before it is executed
+		// (and is only recorded if the transaction succeeds)
+		if idempotencyKeys != nil && idempotencyKeys[i] != nil {
+			if err := recordIdempotencyKey(ctx, ts, idempotencyKeys[i], t.ID()); err != nil {
+				ts.Rollback(ctx, actionStart)
+				return &Result{Success: false, Error: resultError(err), Outputs: resultOutputs, Units: units, Fee: fee}, nil
+			}
+		}
+		var started time.Time
+		if profile != nil {
+			started = time.Now()
+		}
+		outputs, unused, refund, err := executeAction(actionCtx, action, r, ts, timestamp, t.Auth.Actor(), CreateActionID(t.ID(), uint8(i)), contain)
+		if profile != nil {
+			profile.recordAction(action.GetTypeID(), time.Since(started))
+		}
 		if err != nil {
 			ts.Rollback(ctx, actionStart)
-			return &Result{false, utils.ErrBytes(err), resultOutputs, units, fee}, nil
+			return &Result{
+				Success:  false,
+				Error:    resultError(err),
+				Outputs:  resultOutputs,
+				Units:    units,
+				Fee:      fee,
+				panicked: errors.Is(err, ErrActionPanicked),
+			}, nil
 		}
 		if outputs == nil {
 			// Ensure output standardization (match form we will
@@ -336,11 +525,21 @@ func (t *Transaction) Execute(
 		// Wait to append outputs until after we check that there aren't too many
 		if len(outputs) > int(r.GetMaxOutputsPerAction()) {
 			ts.Rollback(ctx, actionStart)
-			return &Result{false, utils.ErrBytes(ErrTooManyOutputs), resultOutputs, units, fee}, nil
+			return &Result{Success: false, Error: resultError(ErrTooManyOutputs), Outputs: resultOutputs, Units: units, Fee: fee}, nil
+		}
+		outputBytes := 0
+		for _, output := range outputs {
+			outputBytes += len(output)
+		}
+		if uint64(outputBytes) > r.GetMaxActionOutputBytes() {
+			ts.Rollback(ctx, actionStart)
+			return &Result{Success: false, Error: resultError(ErrActionOutputTooLarge), Outputs: resultOutputs, Units: units, Fee: fee}, nil
 		}
 		resultOutputs = append(resultOutputs, outputs)
+		metered += unused
+		refunded += refund
 	}
-	return &Result{
+	result := &Result{
 		Success: true,
 		Error:   []byte{},
 
@@ -348,7 +547,12 @@ func (t *Transaction) Execute(
 
 		Units: units,
 		Fee:   fee,
-	}, nil
+	}
+	if err := t.refund(ctx, feeManager, s, r, ts, metered, refunded, result); err != nil {
+		// Should never happen
+		return nil, err
+	}
+	return result, nil
 }
 
 func (t *Transaction) Marshal(p *codec.Packer) error {
@@ -362,12 +566,15 @@ func (t *Transaction) Marshal(p *codec.Packer) error {
 
 func (t *Transaction) marshalActions(p *codec.Packer) error {
 	t.Base.Marshal(p)
-	p.PackByte(uint8(len(t.Actions)))
+	if err := t.packActionCount(p); err != nil {
+		return err
+	}
 	for _, action := range t.Actions {
 		actionID := action.GetTypeID()
 		p.PackByte(actionID)
 		action.Marshal(p)
 	}
+	t.packMemo(p)
 	authID := t.Auth.GetTypeID()
 	p.PackByte(authID)
 	t.Auth.Marshal(p)
@@ -378,15 +585,39 @@ func MarshalTxs(txs []*Transaction) ([]byte, error) {
 	if len(txs) == 0 {
 		return nil, ErrNoTxs
 	}
-	size := consts.IntLen + codec.CummSize(txs)
-	p := codec.NewWriter(size, consts.NetworkSizeLimit)
+	p := codec.NewWriter(consts.IntLen+codec.CummSize(txs), consts.NetworkSizeLimit)
+	if err := packTxs(p, txs); err != nil {
+		return nil, err
+	}
+	return p.Bytes(), nil
+}
+
+// MarshalTxsPooled packs [txs] (like [MarshalTxs]) into a pooled writer.
+//
+// This should be used when the bytes are only needed briefly (like while
+// they are gossiped). The writer must be returned with [codec.PutWriter]
+// once [codec.Packer.Bytes] is no longer used.
+func MarshalTxsPooled(txs []*Transaction) (*codec.Packer, error) {
+	if len(txs) == 0 {
+		return nil, ErrNoTxs
+	}
+	p := codec.GetWriter(consts.IntLen+codec.CummSize(txs), consts.NetworkSizeLimit)
+	if err := packTxs(p, txs); err != nil {
+		codec.PutWriter(p)
+		return nil, err
+	}
+	return p, nil
+}
+
+// packTxs packs the count of [txs] followed by each transaction.
+func packTxs(p *codec.Packer, txs []*Transaction) error {
 	p.PackInt(len(txs))
 	for _, tx := range txs {
 		if err := tx.Marshal(p); err != nil {
-			return nil, err
+			return err
 		}
 	}
-	return p.Bytes(), p.Err()
+	return p.Err()
 }
 
 func UnmarshalTxs(
@@ -395,10 +626,12 @@ func UnmarshalTxs(
 	actionRegistry ActionRegistry,
 	authRegistry AuthRegistry,
 ) (map[uint8]int, []*Transaction, error) {
-	p := codec.NewReader(raw, consts.NetworkSizeLimit)
+	p := codec.GetReader(raw, consts.NetworkSizeLimit)
+	defer codec.PutReader(p)
+
 	txCount := p.UnpackInt(true)
 	authCounts := map[uint8]int{}
-	txs := make([]*Transaction, 0, initialCapacity) // DoS to set size to txCount
+	txs := make([]*Transaction, 0, min(txCount, initialCapacity)) // DoS to set size to txCount
 	for i := 0; i < txCount; i++ {
 		tx, err := UnmarshalTx(p, actionRegistry, authRegistry)
 		if err != nil {
@@ -419,15 +652,24 @@ func UnmarshalTx(
 	actionRegistry *codec.TypeParser[Action, bool],
 	authRegistry *codec.TypeParser[Auth, bool],
 ) (*Transaction, error) {
+	// The [Base] is allocated with the [Transaction] to avoid an extra
+	// allocation for each parsed transaction.
+	alloc := &struct {
+		tx   Transaction
+		base Base
+	}{}
 	start := p.Offset()
-	base, err := UnmarshalBase(p)
-	if err != nil {
+	if err := unmarshalBase(p, &alloc.base); err != nil {
 		return nil, fmt.Errorf("%w: could not unmarshal base", err)
 	}
-	actions, err := unmarshalActions(p, actionRegistry)
+	actions, hasMemo, err := unmarshalActions(p, actionRegistry)
 	if err != nil {
 		return nil, fmt.Errorf("%w: could not unmarshal actions", err)
 	}
+	var memo []byte
+	if hasMemo {
+		p.UnpackBytes(MaxTxMemoSize, true, &memo)
+	}
 	digest := p.Offset()
 	authType := p.UnpackByte()
 	unmarshalAuth, ok := authRegistry.LookupIndex(authType)
@@ -445,9 +687,12 @@ func UnmarshalTx(
 		return nil, fmt.Errorf("%w: sponsorType (%d) did not match authType (%d)", ErrInvalidSponsor, sponsorType, authType)
 	}
 
-	var tx Transaction
-	tx.Base = base
+	tx := &alloc.tx
+	tx.Base = &alloc.base
 	tx.Actions = actions
+	if len(memo) > 0 {
+		tx.Memo = memo
+	}
 	tx.Auth = auth
 	if err := p.Err(); err != nil {
 		return nil, p.Err()
@@ -457,29 +702,33 @@ func UnmarshalTx(
 	tx.bytes = codecBytes[start:p.Offset()] // ensure errors handled before grabbing memory
 	tx.size = len(tx.bytes)
 	tx.id = utils.ToID(tx.bytes)
-	return &tx, nil
+	return tx, nil
 }
 
+// unmarshalActions parses the actions of a [Transaction] and returns whether
+// it has a [Transaction.Memo] (flagged by [txMemoFlag] in the action count).
 func unmarshalActions(
 	p *codec.Packer,
 	actionRegistry *codec.TypeParser[Action, bool],
-) ([]Action, error) {
-	actionCount := p.UnpackByte()
+) ([]Action, bool, error) {
+	count := p.UnpackByte()
+	hasMemo := count&txMemoFlag != 0
+	actionCount := count &^ txMemoFlag
 	if actionCount == 0 {
-		return nil, fmt.Errorf("%w: no actions", ErrInvalidObject)
+		return nil, false, fmt.Errorf("%w: no actions", ErrInvalidObject)
 	}
-	actions := []Action{}
+	actions := make([]Action, 0, actionCount)
 	for i := uint8(0); i < actionCount; i++ {
 		actionType := p.UnpackByte()
 		unmarshalAction, ok := actionRegistry.LookupIndex(actionType)
 		if !ok {
-			return nil, fmt.Errorf("%w: %d is unknown action type", ErrInvalidObject, actionType)
+			return nil, false, fmt.Errorf("%w: %d is unknown action type", ErrInvalidObject, actionType)
 		}
 		action, err := unmarshalAction(p)
 		if err != nil {
-			return nil, fmt.Errorf("%w: could not unmarshal action", err)
+			return nil, false, fmt.Errorf("%w: could not unmarshal action", err)
 		}
 		actions = append(actions, action)
 	}
-	return actions, nil
+	return actions, hasMemo, nil
 }
@@ -0,0 +1,525 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+const testMaxActionOutputBytes = 64
+
+type testStateManager struct{}
+
+func (*testStateManager) HeightKey() []byte    { return []byte{0x0} }
+func (*testStateManager) TimestampKey() []byte { return []byte{0x1} }
+func (*testStateManager) FeeKey() []byte       { return []byte{0x2} }
+
+func (*testStateManager) MemoKey(txID ids.ID) []byte {
+	return append([]byte{0x3}, txID[:]...)
+}
+
+func (*testStateManager) TaskQueueKey() []byte { return []byte{0x4} }
+
+func (*testStateManager) TaskKey(taskID ids.ID) []byte {
+	return append([]byte{0x5}, taskID[:]...)
+}
+
+func (*testStateManager) ACLKey(actionTypeID uint8, addr codec.Address) []byte {
+	return append([]byte{0x7, actionTypeID}, addr[:]...)
+}
+
+func (*testStateManager) EpochKey(epoch uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{0x8}, epoch)
+}
+
+func (*testStateManager) IdempotencyKey(id ids.ID) []byte {
+	return append([]byte{0xd}, id[:]...)
+}
+
+func (*testStateManager) SponsorStateKeys(codec.Address) state.Keys {
+	return state.Keys{}
+}
+
+func (*testStateManager) CanDeduct(context.Context, codec.Address, state.Immutable, uint64) error {
+	return nil
+}
+
+func (*testStateManager) Deduct(context.Context, codec.Address, state.Mutable, uint64) error {
+	return nil
+}
+
+func (*testStateManager) Refund(context.Context, codec.Address, state.Mutable, uint64) error {
+	return nil
+}
+
+// newMemoTx returns a signed transaction with [memo] (re-parsed from bytes)
+// and the mocks it uses.
+func newMemoTx(t *testing.T, memo []byte) (*Transaction, *MockRules, error) {
+	return newOutputTx(t, memo, nil)
+}
+
+// newOutputTx returns a signed transaction with [memo] and an action that
+// returns [outputs] and the mocks it uses.
+func newOutputTx(t *testing.T, memo []byte, outputs [][]byte) (*Transaction, *MockRules, error) {
+	ctrl := gomock.NewController(t)
+
+	actor := codec.CreateAddress(0, ids.GenerateTestID())
+	action := NewMockAction(ctrl)
+	action.EXPECT().GetTypeID().Return(uint8(0)).AnyTimes()
+	action.EXPECT().Size().Return(0).AnyTimes()
+	action.EXPECT().Marshal(gomock.Any()).AnyTimes()
+	action.EXPECT().ComputeUnits(gomock.Any()).Return(uint64(1)).AnyTimes()
+	action.EXPECT().StateKeys(gomock.Any(), gomock.Any()).Return(state.Keys{}).AnyTimes()
+	action.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(outputs, nil).AnyTimes()
+	auth := NewMockAuth(ctrl)
+	auth.EXPECT().GetTypeID().Return(uint8(0)).AnyTimes()
+	auth.EXPECT().Size().Return(0).AnyTimes()
+	auth.EXPECT().Marshal(gomock.Any()).AnyTimes()
+	auth.EXPECT().ComputeUnits(gomock.Any()).Return(uint64(1)).AnyTimes()
+	auth.EXPECT().Actor().Return(actor).AnyTimes()
+	auth.EXPECT().Sponsor().Return(actor).AnyTimes()
+	factory := NewMockAuthFactory(ctrl)
+	factory.EXPECT().Sign(gomock.Any()).Return(auth, nil).AnyTimes()
+
+	r := NewMockRules(ctrl)
+	r.EXPECT().GetBaseComputeUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageKeyReadUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageKeyAllocateUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageKeyWriteUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueReadUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueAllocateUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetStorageValueWriteUnits().Return(uint64(1)).AnyTimes()
+	r.EXPECT().GetMaxOutputsPerAction().Return(uint8(1)).AnyTimes()
+	r.EXPECT().GetMaxActionOutputBytes().Return(uint64(testMaxActionOutputBytes)).AnyTimes()
+	r.EXPECT().GetRefundPolicy().Return(RefundBurn).AnyTimes()
+	r.EXPECT().GetEnforceIdempotencyKeys().Return(false).AnyTimes()
+	r.EXPECT().GetParallelExecution().Return(true).AnyTimes()
+	r.EXPECT().IsActionRestricted(gomock.Any()).Return(false).AnyTimes()
+	r.EXPECT().GetForkActivations().Return(nil).AnyTimes()
+
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	if err := actionRegistry.Register(0, func(*codec.Packer) (Action, error) { return action, nil }, false); err != nil {
+		return nil, nil, err
+	}
+	authRegistry := codec.NewTypeParser[Auth, bool]()
+	if err := authRegistry.Register(0, func(*codec.Packer) (Auth, error) { return auth, nil }, false); err != nil {
+		return nil, nil, err
+	}
+
+	tx := NewTx(&Base{Timestamp: 1_000, ChainID: ids.GenerateTestID(), MaxFee: 1}, []Action{action})
+	tx.Memo = memo
+	tx, err := tx.Sign(factory, actionRegistry, authRegistry)
+	return tx, r, err
+}
+
+func TestTransactionMemo(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	sm := &testStateManager{}
+
+	// Memo is preserved when marshaling
+	memo := []byte("invoice-42")
+	tx, r, err := newMemoTx(t, memo)
+	require.NoError(err)
+	require.Equal(memo, tx.Memo)
+
+	// Memo is stored by txID when executed
+	stateKeys, err := tx.StateKeys(sm, r)
+	require.NoError(err)
+	memoKey := MemoKey(sm.MemoKey(tx.ID()))
+	require.Contains(stateKeys, string(memoKey))
+	ts := tstate.New(1)
+	tsv := ts.NewView(stateKeys, map[string][]byte{})
+	result, err := tx.Execute(ctx, fees.NewManager(nil), sm, r, tsv, 0)
+	require.NoError(err)
+	require.True(result.Success)
+	stored, err := tsv.GetValue(ctx, memoKey)
+	require.NoError(err)
+	require.Equal(memo, stored)
+
+	// Larger memos are charged more units
+	noMemoTx, _, err := newMemoTx(t, nil)
+	require.NoError(err)
+	require.Nil(noMemoTx.Memo)
+	noMemoUnits, err := noMemoTx.Units(sm, r, 0)
+	require.NoError(err)
+	largeMemoTx, _, err := newMemoTx(t, make([]byte, MaxTxMemoSize))
+	require.NoError(err)
+	largeMemoUnits, err := largeMemoTx.Units(sm, r, 0)
+	require.NoError(err)
+	memoUnits, err := tx.Units(sm, r, 0)
+	require.NoError(err)
+	require.Greater(memoUnits[fees.StorageWrite], noMemoUnits[fees.StorageWrite])
+	require.Greater(largeMemoUnits[fees.StorageWrite], memoUnits[fees.StorageWrite])
+	require.Greater(largeMemoUnits[fees.Bandwidth], memoUnits[fees.Bandwidth])
+}
+
+func TestTransactionMemoTooLarge(t *testing.T) {
+	require := require.New(t)
+
+	_, _, err := newMemoTx(t, make([]byte, MaxTxMemoSize+1))
+	require.ErrorIs(err, codec.ErrFieldTooLarge)
+
+	// Oversized memos are rejected when parsing
+	tx, _, err := newMemoTx(t, nil)
+	require.NoError(err)
+	p := codec.NewWriter(0, consts.NetworkSizeLimit)
+	tx.Base.Marshal(p)
+	p.PackByte(1 | txMemoFlag)
+	p.PackByte(0)
+	p.PackBytes(make([]byte, MaxTxMemoSize+1))
+	p.PackByte(0)
+	require.NoError(p.Err())
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	require.NoError(actionRegistry.Register(0, func(*codec.Packer) (Action, error) { return tx.Actions[0], nil }, false))
+	authRegistry := codec.NewTypeParser[Auth, bool]()
+	require.NoError(authRegistry.Register(0, func(*codec.Packer) (Auth, error) { return tx.Auth, nil }, false))
+	_, err = UnmarshalTx(codec.NewReader(p.Bytes(), consts.MaxInt), actionRegistry, authRegistry)
+	require.ErrorIs(err, codec.ErrFieldTooLarge)
+}
+
+func TestTransactionMemoFork(t *testing.T) {
+	require := require.New(t)
+
+	// A tx without a memo doesn't encode one, so it is encoded the same way
+	// before [TxMemoFork]
+	noMemoTx, _, err := newMemoTx(t, nil)
+	require.NoError(err)
+	digest, err := noMemoTx.Digest()
+	require.NoError(err)
+	require.Len(digest, noMemoTx.Base.Size()+consts.Uint8Len+consts.ByteLen)
+	require.Equal(byte(1), digest[noMemoTx.Base.Size()])
+
+	// A memo is flagged in the action count...
+	memoTx, r, err := newMemoTx(t, []byte("invoice-42"))
+	require.NoError(err)
+	digest, err = memoTx.Digest()
+	require.NoError(err)
+	require.Equal(byte(1)|txMemoFlag, digest[memoTx.Base.Size()])
+
+	// ...and is invalid until [TxMemoFork] is active
+	require.ErrorIs(memoTx.verifyInclusion(r, memoTx.Base.Timestamp), ErrMemoNotActivated)
+
+	// A flagged memo can't be empty (so each tx has a single encoding)
+	p := codec.NewWriter(0, consts.NetworkSizeLimit)
+	memoTx.Base.Marshal(p)
+	p.PackByte(1 | txMemoFlag)
+	p.PackByte(0)
+	p.PackBytes(nil)
+	p.PackByte(0)
+	require.NoError(p.Err())
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	require.NoError(actionRegistry.Register(0, func(*codec.Packer) (Action, error) { return memoTx.Actions[0], nil }, false))
+	authRegistry := codec.NewTypeParser[Auth, bool]()
+	require.NoError(authRegistry.Register(0, func(*codec.Packer) (Auth, error) { return memoTx.Auth, nil }, false))
+	_, err = UnmarshalTx(codec.NewReader(p.Bytes(), consts.MaxInt), actionRegistry, authRegistry)
+	require.ErrorIs(err, codec.ErrFieldNotPopulated)
+}
+
+func TestActionOutputTooLarge(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []byte
+		err    error
+	}{
+		{
+			name: "no output",
+		},
+		{
+			name:   "at limit",
+			output: make([]byte, testMaxActionOutputBytes),
+		},
+		{
+			name:   "over limit",
+			output: make([]byte, testMaxActionOutputBytes+1),
+			err:    ErrActionOutputTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			sm := &testStateManager{}
+
+			var outputs [][]byte
+			if tt.output != nil {
+				outputs = [][]byte{tt.output}
+			}
+			tx, r, err := newOutputTx(t, []byte("memo"), outputs)
+			require.NoError(err)
+			stateKeys, err := tx.StateKeys(sm, r)
+			require.NoError(err)
+			tsv := tstate.New(1).NewView(stateKeys, map[string][]byte{})
+			result, err := tx.Execute(ctx, fees.NewManager(nil), sm, r, tsv, 0)
+			require.NoError(err)
+			if tt.err != nil {
+				// The tx is recorded as failed (but is still included, so
+				// its memo is stored) without the oversized output
+				require.False(result.Success)
+				require.Equal(resultError(tt.err), result.Error)
+				require.Empty(result.Outputs)
+				memo, err := tsv.GetValue(ctx, MemoKey(sm.MemoKey(tx.ID())))
+				require.NoError(err)
+				require.Equal([]byte("memo"), memo)
+				return
+			}
+			require.True(result.Success)
+			require.Len(result.Outputs, 1)
+			if outputs == nil {
+				outputs = [][]byte{}
+			}
+			require.Equal(outputs, result.Outputs[0])
+		})
+	}
+}
+
+func TestUnmarshalTxs(t *testing.T) {
+	require := require.New(t)
+
+	txs := newTestBlock(t, 1, 16).Txs
+	raw, err := MarshalTxs(txs)
+	require.NoError(err)
+
+	actionRegistry, authRegistry := (&testParser{}).Registry()
+	authCounts, parsed, err := UnmarshalTxs(raw, 4, actionRegistry, authRegistry)
+	require.NoError(err)
+	require.Len(parsed, len(txs))
+	require.Equal(len(txs), authCounts[(&testAuth{}).GetTypeID()])
+	for i, tx := range parsed {
+		require.Equal(txs[i].ID(), tx.ID())
+		require.Equal(txs[i].Bytes(), tx.Bytes())
+		require.Equal(txs[i].Base, tx.Base)
+		digest, err := tx.Digest()
+		require.NoError(err)
+		expected, err := txs[i].Digest()
+		require.NoError(err)
+		require.Equal(expected, digest)
+	}
+
+	// Leftover bytes are rejected
+	_, _, err = UnmarshalTxs(append(raw, 0), 4, actionRegistry, authRegistry)
+	require.ErrorIs(err, ErrInvalidObject)
+}
+
+// BenchmarkGossipTxs measures marshaling (to send) and parsing (once
+// received) a gossiped batch of transactions.
+//
+// Bench: go test -run=NONE -bench=BenchmarkGossipTxs -benchmem
+//
+// Before pooling packers (MarshalTxs and UnmarshalTxs with new packers):
+//
+// goos: linux
+// goarch: amd64
+// pkg: github.com/ava-labs/hypersdk/chain
+// cpu: AMD EPYC
+// BenchmarkGossipTxs/send         	   50917	     23014 ns/op	  106568 B/op	       3 allocs/op
+// BenchmarkGossipTxs/receive      	    3546	    338589 ns/op	  320456 B/op	    5005 allocs/op
+//
+// After (MarshalTxsPooled and UnmarshalTxs with pooled packers):
+//
+// goos: linux
+// goarch: amd64
+// pkg: github.com/ava-labs/hypersdk/chain
+// cpu: AMD EPYC
+// BenchmarkGossipTxs/send         	  188548	      6373 ns/op	       0 B/op	       0 allocs/op
+// BenchmarkGossipTxs/receive      	    4052	    295593 ns/op	  320388 B/op	    4003 allocs/op
+func BenchmarkGossipTxs(b *testing.B) {
+	txs := newTestBlock(b, 1, 1_000).Txs
+	raw, err := MarshalTxs(txs)
+	require.NoError(b, err)
+	actionRegistry, authRegistry := (&testParser{}).Registry()
+
+	b.Run("send", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p, err := MarshalTxsPooled(txs)
+			if err != nil {
+				b.Fatal(err)
+			}
+			codec.PutWriter(p)
+		}
+	})
+	b.Run("receive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := UnmarshalTxs(raw, 1_000, actionRegistry, authRegistry); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// allocateTestAction writes to [key] (declared with [permissions]).
+type allocateTestAction struct {
+	key         []byte
+	permissions state.Permissions
+}
+
+func (*allocateTestAction) GetTypeID() uint8                { return 0 }
+func (*allocateTestAction) ValidRange(Rules) (int64, int64) { return -1, -1 }
+func (*allocateTestAction) ComputeUnits(Rules) uint64       { return 1 }
+func (*allocateTestAction) StateKeysMaxChunks() []uint16    { return []uint16{1} }
+func (a *allocateTestAction) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{string(a.key): a.permissions}
+}
+
+func (a *allocateTestAction) Size() int {
+	return codec.BytesLen(a.key) + consts.ByteLen
+}
+
+func (a *allocateTestAction) Marshal(p *codec.Packer) {
+	p.PackBytes(a.key)
+	p.PackByte(byte(a.permissions))
+}
+
+func unmarshalAllocateTestAction(p *codec.Packer) (Action, error) {
+	var a allocateTestAction
+	p.UnpackBytes(-1, true, &a.key)
+	a.permissions = state.Permissions(p.UnpackByte())
+	return &a, p.Err()
+}
+
+func (a *allocateTestAction) Execute(ctx context.Context, _ Rules, mu state.Mutable, _ int64, _ codec.Address, _ ids.ID) ([][]byte, error) {
+	return nil, mu.Insert(ctx, a.key, []byte{1})
+}
+
+// allocateTestRules schedules [AllocatePermissionsFork] at [activation] on
+// top of the rules returned by [newOfflineTestRules].
+type allocateTestRules struct {
+	Rules
+	activation int64
+}
+
+func (r *allocateTestRules) GetForkActivations() ForkActivations {
+	return ForkActivations{AllocatePermissionsFork: r.activation}
+}
+
+func TestAllocatePermissionsFork(t *testing.T) {
+	ctx := context.TODO()
+	r := &allocateTestRules{newOfflineTestRules(gomock.NewController(t), ids.GenerateTestID()), 2_000}
+	sm := &refundTestStateManager{}
+	_, authRegistry := (&testParser{}).Registry()
+	actionRegistry := codec.NewTypeParser[Action, bool]()
+	require.NoError(t, actionRegistry.Register(0, unmarshalAllocateTestAction, false))
+	factory := &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+	existingKey := keys.EncodeChunks([]byte{0x8, 0x0}, 1)
+	newKey := keys.EncodeChunks([]byte{0x8, 0x1}, 1)
+
+	tests := []struct {
+		name        string
+		key         []byte
+		permissions state.Permissions
+		timestamp   int64
+
+		// units charged to allocate and write the declared keys (the sponsor
+		// balance is declared with [state.Read] | [state.Write])
+		allocates uint64
+		writes    uint64
+		err       error
+	}{
+		{
+			name:        "create with write before fork",
+			key:         newKey,
+			permissions: state.Write,
+			timestamp:   1_000,
+			allocates:   4,
+			writes:      4,
+		},
+		{
+			name:        "create with write after fork",
+			key:         newKey,
+			permissions: state.Write,
+			timestamp:   2_000,
+			allocates:   0,
+			writes:      4,
+			err:         tstate.ErrInvalidKeyOrPermission,
+		},
+		{
+			name:        "create with allocate after fork",
+			key:         newKey,
+			permissions: state.Allocate,
+			timestamp:   2_000,
+			allocates:   2,
+			writes:      2,
+		},
+		{
+			name:        "modify with allocate after fork",
+			key:         existingKey,
+			permissions: state.Allocate,
+			timestamp:   2_000,
+			allocates:   2,
+			writes:      2,
+			err:         tstate.ErrInvalidKeyOrPermission,
+		},
+		{
+			name:        "modify with all after fork",
+			key:         existingKey,
+			permissions: state.All,
+			timestamp:   2_000,
+			allocates:   2,
+			writes:      4,
+		},
+		{
+			name:        "read before fork",
+			key:         existingKey,
+			permissions: state.Read,
+			timestamp:   1_000,
+			allocates:   4,
+			writes:      4,
+			err:         tstate.ErrInvalidKeyOrPermission,
+		},
+		{
+			name:        "read after fork",
+			key:         existingKey,
+			permissions: state.Read,
+			timestamp:   2_000,
+			allocates:   0,
+			writes:      2,
+			err:         tstate.ErrInvalidKeyOrPermission,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			tx, err := NewTx(
+				&Base{Timestamp: tt.timestamp, ChainID: r.ChainID(), MaxFee: 1_000},
+				[]Action{&allocateTestAction{key: tt.key, permissions: tt.permissions}},
+			).Sign(factory, actionRegistry, authRegistry)
+			require.NoError(err)
+
+			units, err := tx.Units(sm, r, tt.timestamp)
+			require.NoError(err)
+			require.Equal(tt.allocates, units[fees.StorageAllocate])
+			require.Equal(tt.writes, units[fees.StorageWrite])
+
+			stateKeys, err := tx.StateKeys(sm, r)
+			require.NoError(err)
+			tsv := tstate.New(0).NewView(stateKeys, map[string][]byte{
+				string(refundTestBalanceKey(factory.actor)): binary.BigEndian.AppendUint64(nil, 1_000),
+				string(existingKey):                         {0},
+			})
+			result, err := tx.Execute(ctx, fees.NewManager(nil), sm, r, tsv, tt.timestamp)
+			require.NoError(err)
+			if tt.err != nil {
+				require.False(result.Success)
+				require.Equal(tt.err.Error(), string(result.Error))
+				return
+			}
+			require.True(result.Success)
+		})
+	}
+}
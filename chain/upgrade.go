@@ -0,0 +1,142 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Fork names a set of behaviors that activate together. Behavior that changes
+// the result of building or verifying a block should be gated on a [Fork]
+// with [IsActive] (instead of an ad-hoc [Rules] check), so that there is a
+// single place that records what activates when.
+type Fork string
+
+// AllocatePermissionsFork enforces the [state.Allocate] permission of the
+// keys declared by a [Transaction]. Once active:
+//   - Creating a key requires [state.Allocate] ([state.Write] is no longer
+//     sufficient) and modifying an existing key requires [state.Write].
+//   - Each declared key is only charged the storage units its permissions
+//     allow (a key without [state.Allocate] is not charged allocate units and
+//     a key without [state.Write] is not charged write units).
+//
+// It is registered by the VM before any [Fork] of the controller.
+const AllocatePermissionsFork Fork = "allocatePermissions"
+
+// ContainPanicsFork contains panics raised while executing an [Action]. Once
+// active, a panicking [Action] fails its [Transaction] with
+// [ErrActionPanicked] (and its changes are rolled back, like any other failed
+// action) instead of crashing the node. A [FrameworkPanic] still crashes the
+// node.
+//
+// It is registered by the VM after [AllocatePermissionsFork].
+const ContainPanicsFork Fork = "containPanics"
+
+// RestrictBuildersFork adds the builder of a block to its encoding. Once
+// active, each block records whether it has a [StatefulBlock.Builder] and a
+// block with a builder ends with its [StatefulBlock.BuilderSignature].
+// [Rules.GetRestrictBuilders] is only enforced once active.
+//
+// It is registered by the VM after [ContainPanicsFork].
+const RestrictBuildersFork Fork = "restrictBuilders"
+
+// TxMemoFork allows a [Transaction] to carry a [Transaction.Memo]. A memo is
+// flagged in the action count of a [Transaction] (see [MaxTxActions]), so a
+// [Transaction] without a memo is encoded the same way before and after
+// activation. A [Transaction] with a memo is invalid until it is active.
+//
+// It is registered by the VM after [RestrictBuildersFork].
+const TxMemoFork Fork = "txMemo"
+
+// HeaderRootsFork packs the roots of a block (its [StatefulBlock.StateRoot],
+// [StatefulBlock.ResultsRoot], and [StatefulBlock.EpochPChainHeight]) before
+// its transactions instead of after them, so that [UnmarshalBlockHeader] can
+// read them without parsing any transactions.
+//
+// It is registered by the VM after [TxMemoFork].
+const HeaderRootsFork Fork = "headerRoots"
+
+// ForkActivations are the timestamps (in ms) at which each [Fork] activates.
+// Forks that are not scheduled never activate.
+type ForkActivations map[Fork]int64
+
+// IsActive returns true if [fork] is active at [timestamp] under [r].
+func IsActive(r Rules, fork Fork, timestamp int64) bool {
+	return r.GetForkActivations().IsActive(fork, timestamp)
+}
+
+// IsActive returns true if [fork] is scheduled to activate at or before
+// [timestamp].
+func (a ForkActivations) IsActive(fork Fork, timestamp int64) bool {
+	activation, ok := a[fork]
+	return ok && timestamp >= activation
+}
+
+// ForkRegistry is the ordered list of [Fork]s known to the VM. Forks must
+// activate in the order they are registered (a later fork may assume all
+// earlier forks are active).
+type ForkRegistry struct {
+	forks []Fork
+}
+
+func NewForkRegistry() *ForkRegistry {
+	return &ForkRegistry{}
+}
+
+// Register adds [fork] after all previously registered forks.
+func (r *ForkRegistry) Register(fork Fork) error {
+	if len(fork) == 0 {
+		return fmt.Errorf("%w: empty name", ErrUnknownFork)
+	}
+	if slices.Contains(r.forks, fork) {
+		return fmt.Errorf("%w: %s", ErrDuplicateFork, fork)
+	}
+	r.forks = append(r.forks, fork)
+	return nil
+}
+
+// Forks returns the registered forks (in activation order).
+func (r *ForkRegistry) Forks() []Fork {
+	return slices.Clone(r.forks)
+}
+
+// Verify ensures that [activations] only schedules registered forks, that
+// forks activate in the order they were registered, and that no fork
+// activates before [genesisTimestamp].
+//
+// Every invalid activation is reported (instead of just the first).
+func (r *ForkRegistry) Verify(activations ForkActivations, genesisTimestamp int64) error {
+	errs := []error{}
+	for fork := range activations {
+		if !slices.Contains(r.forks, fork) {
+			errs = append(errs, fmt.Errorf("%w: %s is scheduled but not registered", ErrUnknownFork, fork))
+		}
+	}
+	var (
+		prev       Fork
+		prevActive = genesisTimestamp
+	)
+	for _, fork := range r.forks {
+		activation, ok := activations[fork]
+		if !ok {
+			// Once a fork is unscheduled, no later fork may be scheduled
+			prevActive = -1
+			prev = fork
+			continue
+		}
+		switch {
+		case activation < genesisTimestamp:
+			errs = append(errs, fmt.Errorf("%w: %s activates at %d (before genesis at %d)", ErrInvalidActivation, fork, activation, genesisTimestamp))
+		case prevActive < 0:
+			errs = append(errs, fmt.Errorf("%w: %s is scheduled but %s (registered before it) is not", ErrInvalidActivation, fork, prev))
+		case activation < prevActive:
+			errs = append(errs, fmt.Errorf("%w: %s activates at %d (before %s at %d)", ErrInvalidActivation, fork, activation, prev, prevActive))
+		default:
+			prev, prevActive = fork, activation
+		}
+	}
+	return errors.Join(errs...)
+}
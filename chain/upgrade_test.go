@@ -0,0 +1,224 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+var forkTestKey = keys.EncodeChunks([]byte{0xa}, 1)
+
+// forkTestRules overrides the fork activations of the rules returned by
+// [newOfflineTestRules].
+type forkTestRules struct {
+	Rules
+
+	activations ForkActivations
+}
+
+func (r *forkTestRules) GetForkActivations() ForkActivations { return r.activations }
+
+// forkTestAction writes the number of forks active when it is executed to
+// [forkTestKey].
+type forkTestAction struct {
+	testAction
+}
+
+func (*forkTestAction) GetTypeID() uint8             { return 2 }
+func (*forkTestAction) StateKeysMaxChunks() []uint16 { return []uint16{1} }
+func (*forkTestAction) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{string(forkTestKey): state.Allocate | state.Write}
+}
+
+func (*forkTestAction) Execute(ctx context.Context, r Rules, mu state.Mutable, timestamp int64, _ codec.Address, _ ids.ID) ([][]byte, error) {
+	active := 0
+	for fork := range r.GetForkActivations() {
+		if IsActive(r, fork, timestamp) {
+			active++
+		}
+	}
+	return nil, mu.Insert(ctx, forkTestKey, []byte{byte(active)})
+}
+
+func unmarshalForkTestAction(p *codec.Packer) (Action, error) {
+	a, err := unmarshalTestAction(p)
+	if err != nil {
+		return nil, err
+	}
+	return &forkTestAction{testAction: *a.(*testAction)}, nil
+}
+
+// testForkBoundaries calls [f] with the timestamps immediately before and at
+// the activation of each fork in [registry] (that is scheduled by
+// [activations]) and the forks that are active at each.
+func testForkBoundaries(
+	t *testing.T,
+	registry *ForkRegistry,
+	activations ForkActivations,
+	f func(t *testing.T, timestamp int64, active []Fork),
+) {
+	require.NoError(t, registry.Verify(activations, 0))
+	for _, fork := range registry.Forks() {
+		activation, ok := activations[fork]
+		if !ok {
+			continue
+		}
+		for _, timestamp := range []int64{activation - 1, activation} {
+			if timestamp < 0 {
+				continue
+			}
+			active := []Fork{}
+			for _, other := range registry.Forks() {
+				if activations.IsActive(other, timestamp) {
+					active = append(active, other)
+				}
+			}
+			t.Run(fmt.Sprintf("%s/%d", fork, timestamp), func(t *testing.T) {
+				f(t, timestamp, active)
+			})
+		}
+	}
+}
+
+// forkRoundTrip executes a block with a [forkTestAction] at [timestamp] in
+// the VM and offline and returns the value written by the action.
+func forkRoundTrip(t *testing.T, activations ForkActivations, timestamp int64) []byte {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	db, parentRoot := newOfflineTestState(ctx, require)
+	var (
+		chainID                      = ids.GenerateTestID()
+		r                            = &forkTestRules{newOfflineTestRules(gomock.NewController(t), chainID), activations}
+		actionRegistry, authRegistry = (&testParser{}).Registry()
+	)
+	require.NoError((*codec.TypeParser[Action, bool])(actionRegistry).Register(2, unmarshalForkTestAction, false))
+	tx, err := NewTx(
+		&Base{Timestamp: (timestamp/1_000 + 1) * 1_000, ChainID: chainID, MaxFee: 1_000_000},
+		[]Action{&forkTestAction{}},
+	).Sign(&testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}, actionRegistry, authRegistry)
+	require.NoError(err)
+	blk := &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    timestamp,
+		Hght:      1,
+		Txs:       []*Transaction{tx},
+		StateRoot: parentRoot,
+	}
+
+	offlineRoot, err := VerifyOffline(ctx, blk, parentRoot, db, &testStateManager{}, actionRegistry, r)
+	require.NoError(err)
+	vm := &offlineTestVM{
+		r:            r,
+		lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+	}
+	sblk, err := ParseStatefulBlock(ctx, blk, nil, choices.Processing, vm)
+	require.NoError(err)
+	require.NoError(sblk.innerVerify(ctx, &offlineTestVerifyContext{db}))
+	vmRoot, err := sblk.view.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(offlineRoot, vmRoot)
+
+	v, err := sblk.view.GetValue(ctx, forkTestKey)
+	require.NoError(err)
+	return v
+}
+
+func TestForkBoundaries(t *testing.T) {
+	registry := NewForkRegistry()
+	require.NoError(t, registry.Register("a"))
+	require.NoError(t, registry.Register("b"))
+	require.NoError(t, registry.Register("c"))
+	activations := ForkActivations{"a": 5_000, "b": 10_000}
+
+	testForkBoundaries(t, registry, activations, func(t *testing.T, timestamp int64, active []Fork) {
+		require.Equal(t, []byte{byte(len(active))}, forkRoundTrip(t, activations, timestamp))
+	})
+}
+
+func TestForkRegistry(t *testing.T) {
+	require := require.New(t)
+
+	registry := NewForkRegistry()
+	require.NoError(registry.Register("a"))
+	require.NoError(registry.Register("b"))
+	require.ErrorIs(registry.Register("a"), ErrDuplicateFork)
+	require.ErrorIs(registry.Register(""), ErrUnknownFork)
+	require.Equal([]Fork{"a", "b"}, registry.Forks())
+}
+
+func TestForkActivationsVerify(t *testing.T) {
+	registry := NewForkRegistry()
+	require.NoError(t, registry.Register("a"))
+	require.NoError(t, registry.Register("b"))
+	require.NoError(t, registry.Register("c"))
+
+	tests := []struct {
+		name        string
+		activations ForkActivations
+		err         error
+	}{
+		{
+			name: "none scheduled",
+		},
+		{
+			name:        "in order",
+			activations: ForkActivations{"a": 1_000, "b": 2_000, "c": 3_000},
+		},
+		{
+			name:        "same time",
+			activations: ForkActivations{"a": 1_000, "b": 1_000},
+		},
+		{
+			name:        "at genesis",
+			activations: ForkActivations{"a": 100},
+		},
+		{
+			name:        "out of order",
+			activations: ForkActivations{"a": 2_000, "b": 1_000},
+			err:         ErrInvalidActivation,
+		},
+		{
+			name:        "before genesis",
+			activations: ForkActivations{"a": 99},
+			err:         ErrInvalidActivation,
+		},
+		{
+			name:        "earlier fork unscheduled",
+			activations: ForkActivations{"a": 1_000, "c": 2_000},
+			err:         ErrInvalidActivation,
+		},
+		{
+			name:        "unknown fork",
+			activations: ForkActivations{"d": 1_000},
+			err:         ErrUnknownFork,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, registry.Verify(tt.activations, 100), tt.err)
+		})
+	}
+}
+
+func TestIsActive(t *testing.T) {
+	require := require.New(t)
+
+	r := &forkTestRules{activations: ForkActivations{"a": 1_000}}
+	require.False(IsActive(r, "a", 999))
+	require.True(IsActive(r, "a", 1_000))
+	require.True(IsActive(r, "a", 1_001))
+	require.False(IsActive(r, "b", 1_001))
+}
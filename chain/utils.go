@@ -16,3 +16,13 @@ func CreateActionID(txID ids.ID, i uint8) ids.ID {
 	actionBytes[ids.IDLen] = i
 	return utils.ToID(actionBytes)
 }
+
+// resultError returns the bytes of [err] to include in a [Result], truncated to
+// [MaxResultErrorSize].
+func resultError(err error) []byte {
+	b := utils.ErrBytes(err)
+	if len(b) > MaxResultErrorSize {
+		return b[:MaxResultErrorSize]
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+// ActionCodecVector is the canonical encoding of an [Action].
+type ActionCodecVector struct {
+	Name   string
+	Bytes  []byte // excludes the type ID
+	Action Action
+}
+
+// ActionInvalidVector is an encoding that must be rejected when an [Action]
+// is unmarshaled.
+type ActionInvalidVector struct {
+	Name  string
+	Bytes []byte // excludes the type ID
+	Err   error
+}
+
+// ActionExecuteVector is the expected result of executing an [Action] by
+// [Actor] on [PreState].
+//
+// [PostState] is the entire state after execution (keys in [PreState] that
+// are missing from [PostState] must be deleted). If [Err] is set, changes are
+// discarded (as they are by the processor), so [PostState] and [Outputs] are
+// not checked.
+type ActionExecuteVector struct {
+	Name      string
+	Action    Action
+	Actor     codec.Address
+	Timestamp int64
+	PreState  map[string][]byte
+	PostState map[string][]byte
+	Outputs   [][]byte
+	Err       error
+}
+
+// ActionVectors are the conformance vectors of a registered [Action].
+type ActionVectors struct {
+	Codec   []ActionCodecVector
+	Invalid []ActionInvalidVector
+	Execute []ActionExecuteVector
+}
+
+// ActionVectorRegistry holds the [ActionVectors] of each [Action] (by type
+// ID). Vectors are registered alongside the unmarshaler of each action in
+// the [ActionRegistry].
+type ActionVectorRegistry struct {
+	vectors map[uint8]*ActionVectors
+}
+
+func NewActionVectorRegistry() *ActionVectorRegistry {
+	return &ActionVectorRegistry{vectors: map[uint8]*ActionVectors{}}
+}
+
+// Register registers [vectors] for the action with type ID [id]. Returns an
+// error if [id] already has vectors.
+func (r *ActionVectorRegistry) Register(id uint8, vectors *ActionVectors) error {
+	if _, ok := r.vectors[id]; ok {
+		return codec.ErrDuplicateItem
+	}
+	r.vectors[id] = vectors
+	return nil
+}
+
+// Get returns the vectors registered for [id] (if any).
+func (r *ActionVectorRegistry) Get(id uint8) (*ActionVectors, bool) {
+	v, ok := r.vectors[id]
+	return v, ok
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// VerifyChain verifies [blocks] in height order, using each verified block as
+// the [VerifyContext] of its child. This avoids fetching a [VerifyContext] from
+// [vm] for every block when catching up on a contiguous range.
+//
+// [blocks] must form a contiguous chain (in any order). Verification stops at
+// the first failure and the returned error includes the height of the block
+// that failed.
+func VerifyChain(ctx context.Context, vm VM, blocks []*StatelessBlock) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	ctx, span := vm.Tracer().Start(ctx, "chain.VerifyChain",
+		trace.WithAttributes(
+			attribute.Int("blocks", len(blocks)),
+		),
+	)
+	defer span.End()
+
+	if !vm.StateReady() {
+		return ErrStateNotReady
+	}
+
+	// Sort a copy so we don't modify the caller's slice
+	sorted := slices.Clone(blocks)
+	slices.SortFunc(sorted, func(a, b *StatelessBlock) int {
+		switch {
+		case a.Hght < b.Hght:
+			return -1
+		case a.Hght > b.Hght:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	// Only the first block in the range needs its [VerifyContext] to be
+	// provided by the [VM]
+	first := sorted[0]
+	vctx, err := vm.GetVerifyContext(ctx, first.Hght, first.Prnt)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load verify context (height=%d)", err, first.Hght)
+	}
+
+	log := vm.VerifyLogger()
+	for i, blk := range sorted {
+		if i > 0 {
+			parent := sorted[i-1]
+			if blk.Hght != parent.Hght+1 {
+				return fmt.Errorf("%w: height=%d parent=%d", ErrInvalidBlockHeight, blk.Hght, parent.Hght)
+			}
+			if blk.Prnt != parent.ID() {
+				return fmt.Errorf("%w: height=%d expected=%s found=%s", ErrParentMismatch, blk.Hght, parent.ID(), blk.Prnt)
+			}
+			vctx = parent
+		}
+
+		// Blocks we built (or already verified) do not need to be executed again
+		if !blk.Processed() {
+			start := time.Now()
+			err := blk.innerVerify(ctx, vctx)
+			vm.RecordBlockVerify(time.Since(start))
+			if err != nil {
+				log.Warn("verification failed",
+					zap.Uint64("height", blk.Hght),
+					zap.Stringer("blkID", blk.ID()),
+					zap.Error(err),
+				)
+				return fmt.Errorf("%w: height=%d", err, blk.Hght)
+			}
+		}
+		vm.Verified(ctx, blk)
+	}
+	return nil
+}
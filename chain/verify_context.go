@@ -0,0 +1,98 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// verifiedContext records the P-Chain context (and parent state) that the
+// execution artifacts of a block ([StatelessBlock.view],
+// [StatelessBlock.results], and [StatelessBlock.feeManager]) were computed
+// with.
+//
+// Only the most recent context is kept, so at most one set of artifacts is
+// held by each block.
+type verifiedContext struct {
+	pChainHeight uint64
+	parent       state.View
+}
+
+// implements "block.WithVerifyContext"
+//
+// Blocks must be verified with a P-Chain context when [Rules.GetRestrictBuilders]
+// is enabled because the validity of [StatefulBlock.Builder] depends on the
+// validator set at the P-Chain height of the context.
+func (b *StatelessBlock) ShouldVerifyWithContext(context.Context) (bool, error) {
+	return restrictBuilders(b.vm.Rules(b.Tmstmp), b.Tmstmp), nil
+}
+
+// implements "block.WithVerifyContext"
+//
+// Unlike [Verify], [VerifyWithContext] may be called multiple times on the
+// same block. If [b] was already verified with the same P-Chain height on top
+// of the same parent state, the artifacts of that verification are reused
+// instead of re-executing [b].
+func (b *StatelessBlock) VerifyWithContext(ctx context.Context, bctx *block.Context) error {
+	return b.verify(ctx, bctx)
+}
+
+// contextChanged returns true if the artifacts of [b] were computed with a
+// context other than [bctx] (or on top of a different parent state).
+//
+// Blocks that were built locally (or verified without a context) are never
+// considered changed.
+func (b *StatelessBlock) contextChanged(ctx context.Context, bctx *block.Context) bool {
+	if b.verifiedContext == nil {
+		return false
+	}
+	if b.verifiedContext.pChainHeight != bctx.PChainHeight {
+		return true
+	}
+	parent, err := b.parentView(ctx)
+	if err != nil {
+		// If the parent state can't be loaded, we can't confirm it is
+		// unchanged
+		return true
+	}
+	return parent != b.verifiedContext.parent
+}
+
+// cacheContext records that the artifacts of [b] were computed with [bctx]
+// on top of the parent state of [vctx].
+func (b *StatelessBlock) cacheContext(ctx context.Context, vctx VerifyContext, bctx *block.Context) error {
+	parent, err := vctx.View(ctx, false)
+	if err != nil {
+		return err
+	}
+	b.verifiedContext = &verifiedContext{
+		pChainHeight: bctx.PChainHeight,
+		parent:       parent,
+	}
+	return nil
+}
+
+// discardContext frees the artifacts of [b] so that it is re-executed the
+// next time it is verified.
+func (b *StatelessBlock) discardContext() {
+	b.verifiedContext = nil
+	b.view = nil
+	b.results = nil
+	b.feeManager = nil
+	b.epoch = nil
+}
+
+// parentView returns the current parent state of [b] (without verifying any
+// ancestry).
+func (b *StatelessBlock) parentView(ctx context.Context) (state.View, error) {
+	vctx, err := b.vm.GetVerifyContext(ctx, b.Hght, b.Prnt)
+	if err != nil {
+		return nil, err
+	}
+	return vctx.View(ctx, false)
+}
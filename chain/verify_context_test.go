@@ -0,0 +1,164 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+// contextTestVM implements the subset of [VM] used to verify, accept, and
+// reject a block (and counts how many times a block is executed).
+type contextTestVM struct {
+	*offlineTestVM
+
+	vctx       VerifyContext
+	executions int
+}
+
+func (*contextTestVM) RecordBlockVerify(time.Duration)                                   {}
+func (*contextTestVM) RecordBlockAccept(time.Duration)                                   {}
+func (*contextTestVM) StateReady() bool                                                  { return true }
+func (*contextTestVM) BeginVerify() func()                                               { return func() {} }
+func (*contextTestVM) BeginAccept() func()                                               { return func() {} }
+func (*contextTestVM) Verified(context.Context, *StatelessBlock)                         {}
+func (*contextTestVM) Accepted(context.Context, *StatelessBlock)                         {}
+func (*contextTestVM) Rejected(context.Context, *StatelessBlock)                         {}
+func (*contextTestVM) CommitState(context.Context, *StatelessBlock, merkledb.View) error { return nil }
+func (vm *contextTestVM) RecordStateChanges(int)                                         { vm.executions++ }
+
+func (vm *contextTestVM) GetVerifyContext(context.Context, uint64, ids.ID) (VerifyContext, error) {
+	return vm.vctx, nil
+}
+
+func newContextTestBlock(t *testing.T, require *require.Assertions) (*StatelessBlock, *contextTestVM, merkledb.MerkleDB) {
+	ctx := context.TODO()
+	db, parentRoot := newOfflineTestState(ctx, require)
+
+	var (
+		chainID                      = ids.GenerateTestID()
+		r                            = newOfflineTestRules(gomock.NewController(t), chainID)
+		actionRegistry, authRegistry = (&testParser{}).Registry()
+		factory                      = &testAuthFactory{actor: codec.CreateAddress(0, ids.GenerateTestID())}
+	)
+	blk := &StatefulBlock{
+		Prnt:      ids.GenerateTestID(),
+		Tmstmp:    1_000,
+		Hght:      1,
+		StateRoot: parentRoot,
+	}
+	tx, err := NewTx(
+		&Base{Timestamp: 2_000, ChainID: chainID, MaxFee: 1_000_000},
+		[]Action{&testAction{payload: []byte{1}}},
+	).Sign(factory, actionRegistry, authRegistry)
+	require.NoError(err)
+	blk.Txs = []*Transaction{tx}
+
+	vm := &contextTestVM{
+		offlineTestVM: &offlineTestVM{
+			r:            r,
+			lastAccepted: &StatelessBlock{StatefulBlock: &StatefulBlock{}, st: choices.Accepted},
+		},
+		vctx: &offlineTestVerifyContext{db},
+	}
+	sblk, err := ParseStatefulBlock(ctx, blk, nil, choices.Processing, vm)
+	require.NoError(err)
+	return sblk, vm, db
+}
+
+func TestVerifyWithContextCache(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	blk, vm, db := newContextTestBlock(t, require)
+	shouldVerify, err := blk.ShouldVerifyWithContext(ctx)
+	require.NoError(err)
+	require.False(shouldVerify)
+
+	// The first verification executes the block
+	require.NoError(blk.VerifyWithContext(ctx, &block.Context{PChainHeight: 10}))
+	require.Equal(1, vm.executions)
+	view := blk.view
+	require.NotNil(view)
+
+	// Re-verifying with the same context reuses the cached view
+	require.NoError(blk.VerifyWithContext(ctx, &block.Context{PChainHeight: 10}))
+	require.Equal(1, vm.executions)
+	require.Same(view, blk.view)
+
+	// Re-verifying with a different context recomputes it
+	require.NoError(blk.VerifyWithContext(ctx, &block.Context{PChainHeight: 11}))
+	require.Equal(2, vm.executions)
+	require.NotSame(view, blk.view)
+	require.Equal(uint64(11), blk.verifiedContext.pChainHeight)
+
+	// Re-verifying after the parent state changed recomputes it
+	parent, err := db.NewView(ctx, merkledb.ViewChanges{})
+	require.NoError(err)
+	vm.vctx = &offlineTestVerifyContext{parent}
+	require.NoError(blk.VerifyWithContext(ctx, &block.Context{PChainHeight: 11}))
+	require.Equal(3, vm.executions)
+	root, err := blk.view.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	// Verifying without a context doesn't discard the cache
+	require.NoError(blk.Verify(ctx))
+	require.Equal(3, vm.executions)
+	require.NotNil(blk.verifiedContext)
+
+	// Accepting the block uses the cached view (and frees the cache)
+	require.NoError(blk.Accept(ctx))
+	require.Equal(3, vm.executions)
+	require.Nil(blk.verifiedContext)
+	acceptedRoot, err := blk.view.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(root, acceptedRoot)
+}
+
+func TestVerifyWithContextReject(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	blk, vm, _ := newContextTestBlock(t, require)
+	require.NoError(blk.VerifyWithContext(ctx, &block.Context{PChainHeight: 10}))
+	require.Equal(1, vm.executions)
+	require.NotNil(blk.verifiedContext)
+
+	require.NoError(blk.Reject(ctx))
+	require.Nil(blk.verifiedContext)
+}
+
+func TestVerifyResultBuilder(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	// Results are not credited to a builder the header doesn't carry...
+	blk, vm, _ := newContextTestBlock(t, require)
+	require.NoError(blk.Verify(ctx))
+	require.Len(blk.Results(), 1)
+	require.Equal(codec.EmptyAddress, blk.Results()[0].Builder)
+
+	// ...but are credited to the builder in the header (which is only
+	// carried once [RestrictBuildersFork] is active)
+	vm.r = &forkTestRules{Rules: vm.r, activations: builderTestParser.forks}
+	builder := codec.CreateAddress(0, ids.GenerateTestID())
+	withBuilder := *blk.StatefulBlock
+	withBuilder.Builder = builder
+	withBuilder.BuilderSignature = testBuilderSignature(ids.GenerateTestID())
+	blk, err := ParseStatefulBlock(ctx, &withBuilder, nil, choices.Processing, vm)
+	require.NoError(err)
+	require.NoError(blk.Verify(ctx))
+	require.Len(blk.Results(), 1)
+	require.Equal(builder, blk.Results()[0].Builder)
+}
@@ -191,6 +191,44 @@ func (h *Handler) PrintChainInfo() error {
 	return nil
 }
 
+// CompareChain compares the accepted chain of the selected chain's node with
+// that of the node serving [peerURI] (see [rpc.FindChainDivergence]).
+func (h *Handler) CompareChain(peerURI string) error {
+	_, uris, err := h.PromptChain("select chainID", nil)
+	if err != nil {
+		return err
+	}
+	d, err := rpc.FindChainDivergence(context.Background(), rpc.NewJSONRPCClient(uris[0]), rpc.NewJSONRPCClient(peerURI))
+	if err != nil {
+		return err
+	}
+	if !d.Diverged {
+		utils.Outf(
+			"{{green}}checkpoints match:{{/}} %d-%d\n",
+			d.From,
+			d.To,
+		)
+		return nil
+	}
+	if d.FirstDiffering == d.From {
+		utils.Outf(
+			"{{red}}chains diverge at or before first common checkpoint:{{/}} %d {{yellow}}compared:{{/}} %d-%d\n",
+			d.FirstDiffering,
+			d.From,
+			d.To,
+		)
+		return nil
+	}
+	utils.Outf(
+		"{{red}}first differing checkpoint:{{/}} %d {{yellow}}last matching checkpoint:{{/}} %d {{yellow}}compared:{{/}} %d-%d\n",
+		d.FirstDiffering,
+		d.LastMatching,
+		d.From,
+		d.To,
+	)
+	return nil
+}
+
 func (h *Handler) WatchChain(hideTxs bool, getParser func(string, uint32, ids.ID) (chain.Parser, error), handleTx func(*chain.Transaction, *chain.Result)) error {
 	ctx := context.Background()
 	chainID, uris, err := h.PromptChain("select chainID", nil)
@@ -226,7 +264,7 @@ func (h *Handler) WatchChain(hideTxs bool, getParser func(string, uint32, ids.ID
 		tpsWindow         = window.Window{}
 	)
 	for ctx.Err() == nil {
-		blk, results, prices, err := scli.ListenBlock(ctx, parser)
+		blk, txs, results, prices, err := scli.ListenBlock(ctx, parser)
 		if err != nil {
 			return err
 		}
@@ -281,7 +319,7 @@ func (h *Handler) WatchChain(hideTxs bool, getParser func(string, uint32, ids.ID
 		if hideTxs {
 			continue
 		}
-		for i, tx := range blk.Txs {
+		for i, tx := range txs {
 			handleTx(tx, results[i])
 		}
 	}
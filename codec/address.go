@@ -8,6 +8,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/btcsuite/btcd/btcutil/bech32"
 )
 
 const (
@@ -34,9 +35,9 @@ func CreateAddress(typeID uint8, id ids.ID) Address {
 	return Address(a)
 }
 
-// AddressBech32 returns a Bech32 address from [hrp] and [p].
-// This function uses avalanchego's FormatBech32 function.
-func AddressBech32(hrp string, p Address) (string, error) {
+// checkBech32Size ensures an address encoded with [hrp] does not exceed the
+// max size of a Bech32 string.
+func checkBech32Size(hrp string) error {
 	expanedNum := AddressLen * fromBits
 	expandedLen := expanedNum / toBits
 	if expanedNum%toBits != 0 {
@@ -44,7 +45,16 @@ func AddressBech32(hrp string, p Address) (string, error) {
 	}
 	addrLen := len(hrp) + separatorLen + expandedLen + checksumlen
 	if addrLen > maxBech32Size {
-		return "", fmt.Errorf("%w: max=%d, requested=%d", ErrInvalidSize, maxBech32Size, addrLen)
+		return fmt.Errorf("%w: max=%d, requested=%d", ErrInvalidSize, maxBech32Size, addrLen)
+	}
+	return nil
+}
+
+// AddressBech32 returns a Bech32 address from [hrp] and [p].
+// This function uses avalanchego's FormatBech32 function.
+func AddressBech32(hrp string, p Address) (string, error) {
+	if err := checkBech32Size(hrp); err != nil {
+		return "", err
 	}
 	return address.FormatBech32(hrp, p[:])
 }
@@ -67,7 +77,7 @@ func ParseAddressBech32(hrp, saddr string) (Address, error) {
 		return EmptyAddress, err
 	}
 	if phrp != hrp {
-		return EmptyAddress, ErrIncorrectHRP
+		return EmptyAddress, fmt.Errorf("%w: expected=%s found=%s", ErrIncorrectHRP, hrp, phrp)
 	}
 	// The parsed value may be greater than [minLength] because the
 	// underlying Bech32 implementation requires bytes to each encode 5 bits
@@ -78,3 +88,76 @@ func ParseAddressBech32(hrp, saddr string) (Address, error) {
 	}
 	return Address(p[:AddressLen]), nil
 }
+
+// AddressBech32m returns a Bech32m (BIP-350) address from [hrp] and [p].
+func AddressBech32m(hrp string, p Address) (string, error) {
+	if err := checkBech32Size(hrp); err != nil {
+		return "", err
+	}
+	conv, err := bech32.ConvertBits(p[:], fromBits, toBits, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.EncodeM(hrp, conv)
+}
+
+// MustAddressBech32m returns a Bech32m address from [hrp] and [p] or panics.
+func MustAddressBech32m(hrp string, p Address) string {
+	addr, err := AddressBech32m(hrp, p)
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
+
+// ParseAddressBech32m parses a Bech32m encoded address string and extracts
+// its [AddressBytes]. Addresses encoded with Bech32 (instead of Bech32m) or
+// with an hrp other than [hrp] are rejected.
+func ParseAddressBech32m(hrp, saddr string) (Address, error) {
+	phrp, decoded, version, err := bech32.DecodeGeneric(saddr)
+	if err != nil {
+		return EmptyAddress, err
+	}
+	if version != bech32.VersionM {
+		return EmptyAddress, ErrIncorrectEncoding
+	}
+	if phrp != hrp {
+		return EmptyAddress, fmt.Errorf("%w: expected=%s found=%s", ErrIncorrectHRP, hrp, phrp)
+	}
+	p, err := bech32.ConvertBits(decoded, toBits, fromBits, true)
+	if err != nil {
+		return EmptyAddress, err
+	}
+	// See [ParseAddressBech32] for why [p] may be longer than [AddressLen].
+	if len(p) < AddressLen {
+		return EmptyAddress, ErrInsufficientLength
+	}
+	return Address(p[:AddressLen]), nil
+}
+
+// AddressCodec formats and parses the addresses of a chain as Bech32m
+// strings with the hrp of that chain.
+type AddressCodec struct {
+	hrp string
+}
+
+func NewAddressCodec(hrp string) *AddressCodec {
+	return &AddressCodec{hrp}
+}
+
+// HRP returns the human-readable part of all addresses formatted by [c].
+func (c *AddressCodec) HRP() string {
+	return c.hrp
+}
+
+func (c *AddressCodec) FormatAddress(p Address) (string, error) {
+	return AddressBech32m(c.hrp, p)
+}
+
+func (c *AddressCodec) MustFormatAddress(p Address) string {
+	return MustAddressBech32m(c.hrp, p)
+}
+
+func (c *AddressCodec) ParseAddress(saddr string) (Address, error) {
+	return ParseAddressBech32m(c.hrp, saddr)
+}
@@ -46,3 +46,28 @@ func TestInvalidAddressChecksum(t *testing.T) {
 		Actual:    "8u2e7k",
 	})
 }
+
+func TestIDAddressBech32m(t *testing.T) {
+	require := require.New(t)
+
+	c := NewAddressCodec(hrp)
+	addrBytes := CreateAddress(0, ids.GenerateTestID())
+	addr, err := c.FormatAddress(addrBytes)
+	require.NoError(err)
+	require.Equal(c.MustFormatAddress(addrBytes), addr)
+
+	sb, err := c.ParseAddress(addr)
+	require.NoError(err)
+	require.Equal(addrBytes, sb)
+
+	// Addresses from another chain are rejected
+	_, err = NewAddressCodec("test").ParseAddress(addr)
+	require.ErrorIs(err, ErrIncorrectHRP)
+	require.ErrorContains(err, "expected=test")
+
+	// Bech32 addresses are rejected
+	legacy, err := AddressBech32(hrp, addrBytes)
+	require.NoError(err)
+	_, err = c.ParseAddress(legacy)
+	require.ErrorIs(err, ErrIncorrectEncoding)
+}
@@ -11,6 +11,8 @@ var (
 	ErrFieldNotPopulated  = errors.New("field is not populated")
 	ErrInvalidBitset      = errors.New("invalid bitset")
 	ErrIncorrectHRP       = errors.New("incorrect hrp")
+	ErrIncorrectEncoding  = errors.New("incorrect encoding")
 	ErrInsufficientLength = errors.New("insufficient length")
 	ErrInvalidSize        = errors.New("invalid size")
+	ErrFieldTooLarge      = errors.New("field is too large")
 )
@@ -9,9 +9,14 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 
+	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/window"
 )
 
+// MaxStringLen is the maximum length of a packed string (strings are prefixed
+// with a uint16 length).
+const MaxStringLen = int(consts.MaxUint16)
+
 // Packer is a wrapper struct for the Packer struct
 // from avalanchego/utils/wrappers/packing.go. A bool [required] parameter is
 // added to many unpacking methods, which signals the packer to add an error
@@ -84,25 +89,50 @@ func (p *Packer) PackBytes(b []byte) {
 	p.p.PackBytes(b)
 }
 
+// PackLimitedBytes packs [b] if it is no larger than [limit]. Otherwise,
+// PackLimitedBytes adds an ErrFieldTooLarge error to the Packer.
+func (p *Packer) PackLimitedBytes(b []byte, limit int) {
+	if len(b) > limit {
+		p.addErr(fmt.Errorf("%w: Bytes field has length %d (limit=%d)", ErrFieldTooLarge, len(b), limit))
+		return
+	}
+	p.p.PackBytes(b)
+}
+
 func (p *Packer) UnpackFixedBytes(size int, dest *[]byte) {
 	copy((*dest), p.p.UnpackFixedBytes(size))
 }
 
-// UnpackBytes unpacks [limit] bytes into [dest]. Otherwise
-// if [limit] >= 0, UnpackBytes unpacks a byte slice array into [dest]. If
-// [required] is set to true and the amount of bytes written to [dest] is 0,
-// UnpackBytes adds an err ErrFieldNotPopulated to the Packer.
+// UnpackBytes unpacks a byte slice of at most [limit] bytes (if [limit] >= 0)
+// into [dest]. If the byte slice is larger than [limit], UnpackBytes adds an
+// ErrFieldTooLarge error to the Packer. If [required] is set to true and the
+// amount of bytes written to [dest] is 0, UnpackBytes adds an err
+// ErrFieldNotPopulated to the Packer.
 func (p *Packer) UnpackBytes(limit int, required bool, dest *[]byte) {
-	if limit >= 0 {
-		*dest = p.p.UnpackLimitedBytes(uint32(limit))
-	} else {
-		*dest = p.p.UnpackBytes()
+	size := p.p.UnpackInt()
+	if limit >= 0 && uint64(size) > uint64(limit) {
+		p.addErr(fmt.Errorf("%w: Bytes field has length %d (limit=%d)", ErrFieldTooLarge, size, limit))
+		*dest = nil
+		return
 	}
+	*dest = p.p.UnpackFixedBytes(int(size))
 	if required && len(*dest) == 0 {
 		p.addErr(fmt.Errorf("%w: Bytes field is not populated", ErrFieldNotPopulated))
 	}
 }
 
+func (p *Packer) PackUint16(v uint16) {
+	p.p.PackShort(v)
+}
+
+func (p *Packer) UnpackUint16(required bool) uint16 {
+	v := p.p.UnpackShort()
+	if required && v == 0 {
+		p.addErr(fmt.Errorf("%w: Uint16 field is not populated", ErrFieldNotPopulated))
+	}
+	return v
+}
+
 func (p *Packer) PackUint64(v uint64) {
 	p.p.PackLong(v)
 }
@@ -147,12 +177,37 @@ func (p *Packer) UnpackWindow(w *window.Window) {
 	copy((*w)[:], p.p.UnpackFixedBytes(window.WindowSliceSize))
 }
 
+// PackString packs [s] using the default string limit ([MaxStringLen]).
 func (p *Packer) PackString(s string) {
+	p.PackLimitedString(s, MaxStringLen)
+}
+
+// PackLimitedString packs [s] if it is no larger than [limit]. Otherwise,
+// PackLimitedString adds an ErrFieldTooLarge error to the Packer.
+func (p *Packer) PackLimitedString(s string, limit int) {
+	if len(s) > min(limit, MaxStringLen) {
+		p.addErr(fmt.Errorf("%w: String field has length %d (limit=%d)", ErrFieldTooLarge, len(s), limit))
+		return
+	}
 	p.p.PackStr(s)
 }
 
+// UnpackString unpacks a string using the default string limit ([MaxStringLen]).
 func (p *Packer) UnpackString(required bool) string {
-	str := p.p.UnpackStr()
+	return p.UnpackLimitedString(MaxStringLen, required)
+}
+
+// UnpackLimitedString unpacks a string of at most [limit] bytes. If the string
+// is larger than [limit], UnpackLimitedString adds an ErrFieldTooLarge error to
+// the Packer. If [required] is set to true and the string is empty,
+// UnpackLimitedString adds an ErrFieldNotPopulated error to the Packer.
+func (p *Packer) UnpackLimitedString(limit int, required bool) string {
+	size := p.p.UnpackShort()
+	if int(size) > min(limit, MaxStringLen) {
+		p.addErr(fmt.Errorf("%w: String field has length %d (limit=%d)", ErrFieldTooLarge, size, limit))
+		return ""
+	}
+	str := string(p.p.UnpackFixedBytes(int(size)))
 	if required && len(str) == 0 {
 		p.addErr(fmt.Errorf("%w: String field is not populated", ErrFieldNotPopulated))
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/window"
 )
 
@@ -117,6 +118,22 @@ func TestPackerAddress(t *testing.T) {
 	})
 }
 
+func TestPackerUint16(t *testing.T) {
+	require := require.New(t)
+
+	wp := NewWriter(consts.Uint16Len*2, consts.Uint16Len*2)
+	wp.PackUint16(consts.MaxUint16)
+	wp.PackUint16(0)
+	require.NoError(wp.Err())
+	require.Len(wp.Bytes(), consts.Uint16Len*2)
+
+	rp := NewReader(wp.Bytes(), consts.Uint16Len*2)
+	require.Equal(consts.MaxUint16, rp.UnpackUint16(true))
+	require.NoError(rp.Err())
+	require.Zero(rp.UnpackUint16(true))
+	require.ErrorIs(rp.Err(), ErrFieldNotPopulated)
+}
+
 func TestNewReader(t *testing.T) {
 	require := require.New(t)
 	vInt := 900
@@ -135,3 +152,101 @@ func TestNewReader(t *testing.T) {
 	require.Zero(rp.UnpackUint64(true), "Reader unpacked correctly.")
 	require.ErrorIs(rp.Err(), wrappers.ErrInsufficientLength)
 }
+
+func TestPackerLimitedBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		limit int
+		err   error
+	}{
+		{
+			name:  "at limit",
+			size:  32,
+			limit: 32,
+		},
+		{
+			name:  "over limit",
+			size:  33,
+			limit: 32,
+			err:   ErrFieldTooLarge,
+		},
+		{
+			name:  "larger limit",
+			size:  33,
+			limit: 64,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			b := make([]byte, tt.size)
+			wp := NewWriter(0, consts.MaxInt)
+			wp.PackLimitedBytes(b, tt.limit)
+			require.ErrorIs(wp.Err(), tt.err)
+
+			// Ensure the same limit is enforced when unpacking
+			wp = NewWriter(0, consts.MaxInt)
+			wp.PackBytes(b)
+			require.NoError(wp.Err())
+			rp := NewReader(wp.Bytes(), consts.MaxInt)
+			var unpacked []byte
+			rp.UnpackBytes(tt.limit, true, &unpacked)
+			if tt.err != nil {
+				require.ErrorIs(rp.Err(), tt.err)
+				return
+			}
+			require.NoError(rp.Err())
+			require.Equal(b, unpacked)
+		})
+	}
+}
+
+func TestPackerLimitedString(t *testing.T) {
+	tests := []struct {
+		name  string
+		str   string
+		limit int
+		err   error
+	}{
+		{
+			name:  "at limit",
+			str:   TestString,
+			limit: len(TestString),
+		},
+		{
+			name:  "over limit",
+			str:   TestString,
+			limit: len(TestString) - 1,
+			err:   ErrFieldTooLarge,
+		},
+		{
+			name:  "larger limit",
+			str:   TestString,
+			limit: MaxStringLen,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			wp := NewWriter(0, consts.MaxInt)
+			wp.PackLimitedString(tt.str, tt.limit)
+			require.ErrorIs(wp.Err(), tt.err)
+
+			// Ensure the same limit is enforced when unpacking
+			wp = NewWriter(0, consts.MaxInt)
+			wp.PackString(tt.str)
+			require.NoError(wp.Err())
+			rp := NewReader(wp.Bytes(), consts.MaxInt)
+			unpacked := rp.UnpackLimitedString(tt.limit, true)
+			if tt.err != nil {
+				require.ErrorIs(rp.Err(), tt.err)
+				return
+			}
+			require.NoError(rp.Err())
+			require.Equal(tt.str, unpacked)
+		})
+	}
+}
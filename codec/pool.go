@@ -0,0 +1,72 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"sync"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+// maxPooledWriterSize is the largest buffer kept by the writer pool (large
+// enough for a gossiped batch of transactions or a block). Larger buffers are
+// only used by writers without a network limit and are released to the GC.
+const maxPooledWriterSize = consts.NetworkSizeLimit
+
+var (
+	readerPool = sync.Pool{
+		New: func() any { return &Packer{p: &wrappers.Packer{}} },
+	}
+	writerPool = sync.Pool{
+		New: func() any { return &Packer{p: &wrappers.Packer{}} },
+	}
+)
+
+// GetReader returns a pooled Packer that reads [src] with a MaxSize of
+// [limit] (like [NewReader]).
+//
+// The Packer must be returned with [PutReader] once it is no longer used.
+// Anything unpacked from [src] references [src] (not the Packer), so it may
+// be retained after the Packer is returned.
+func GetReader(src []byte, limit int) *Packer {
+	p := readerPool.Get().(*Packer)
+	p.p.Bytes = src
+	p.p.MaxSize = limit
+	return p
+}
+
+// PutReader returns [p] (from [GetReader]) to the pool. [p] must not be used
+// after it is returned.
+func PutReader(p *Packer) {
+	*p.p = wrappers.Packer{}
+	readerPool.Put(p)
+}
+
+// GetWriter returns a pooled Packer with an initial capacity of at least
+// [initial] and a MaxSize of [limit] (like [NewWriter]).
+//
+// The Packer must be returned with [PutWriter] once it is no longer used.
+// Because the buffer of the Packer is reused, [Packer.Bytes] must be copied
+// if it is needed after the Packer is returned.
+func GetWriter(initial, limit int) *Packer {
+	p := writerPool.Get().(*Packer)
+	if cap(p.p.Bytes) < initial {
+		p.p.Bytes = make([]byte, 0, initial)
+	}
+	p.p.MaxSize = limit
+	return p
+}
+
+// PutWriter returns [p] (from [GetWriter]) to the pool. [p] (and anything
+// returned by [Packer.Bytes]) must not be used after it is returned.
+func PutWriter(p *Packer) {
+	buf := p.p.Bytes[:0]
+	if cap(buf) > maxPooledWriterSize {
+		buf = nil
+	}
+	*p.p = wrappers.Packer{Bytes: buf}
+	writerPool.Put(p)
+}
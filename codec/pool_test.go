@@ -0,0 +1,68 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+func TestPooledPackerReset(t *testing.T) {
+	require := require.New(t)
+
+	// Errors and offsets of a returned reader are not observed by the next
+	// reader
+	rp := GetReader([]byte{1}, consts.NetworkSizeLimit)
+	rp.UnpackUint64(true)
+	require.ErrorIs(rp.Err(), wrappers.ErrInsufficientLength)
+	PutReader(rp)
+	rp = GetReader(binary.BigEndian.AppendUint64(nil, 5), consts.NetworkSizeLimit)
+	require.Equal(uint64(5), rp.UnpackUint64(true))
+	require.NoError(rp.Err())
+	require.True(rp.Empty())
+	PutReader(rp)
+
+	// A returned writer is empty when reused
+	wp := GetWriter(consts.Uint64Len, consts.Uint64Len)
+	wp.PackUint64(1)
+	wp.PackUint64(2)
+	require.ErrorIs(wp.Err(), wrappers.ErrInsufficientLength)
+	PutWriter(wp)
+	wp = GetWriter(consts.Uint64Len, consts.Uint64Len)
+	require.True(wp.Empty())
+	wp.PackUint64(3)
+	require.NoError(wp.Err())
+	require.Equal(binary.BigEndian.AppendUint64(nil, 3), wp.Bytes())
+	PutWriter(wp)
+}
+
+func TestPooledPackerConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1_000; j++ {
+				v := uint64(i*1_000+j) + 1 // UnpackUint64 requires a non-zero value
+				wp := GetWriter(consts.Uint64Len, consts.NetworkSizeLimit)
+				wp.PackUint64(v)
+				raw := make([]byte, len(wp.Bytes()))
+				copy(raw, wp.Bytes())
+				PutWriter(wp)
+
+				rp := GetReader(raw, consts.NetworkSizeLimit)
+				require.Equal(t, v, rp.UnpackUint64(true))
+				require.NoError(t, rp.Err())
+				PutReader(rp)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -4,6 +4,10 @@
 package codec
 
 import (
+	"slices"
+
+	"golang.org/x/exp/maps"
+
 	"github.com/ava-labs/hypersdk/consts"
 )
 
@@ -49,3 +53,10 @@ func (p *TypeParser[T, Y]) LookupIndex(index uint8) (func(*Packer) (T, error), b
 	}
 	return nil, false
 }
+
+// Indices returns the registered indices of Typeparser [p] in ascending order.
+func (p *TypeParser[T, Y]) Indices() []uint8 {
+	indices := maps.Keys(p.indexToDecoder)
+	slices.Sort(indices)
+	return indices
+}
@@ -41,6 +41,7 @@ func TestTypeParser(t *testing.T) {
 		f, ok := tp.LookupIndex(0)
 		require.Nil(f)
 		require.False(ok)
+		require.Empty(tp.Indices())
 	})
 
 	t.Run("populated parser", func(t *testing.T) {
@@ -76,6 +77,8 @@ func TestTypeParser(t *testing.T) {
 		res, err = f(nil)
 		require.Nil(res)
 		require.ErrorIs(err, errBlah2)
+
+		require.Equal([]uint8{blah1.GetTypeID(), blah2.GetTypeID()}, tp.Indices())
 	})
 
 	t.Run("duplicate item", func(t *testing.T) {
@@ -12,6 +12,8 @@ import (
 	"github.com/ava-labs/avalanchego/utils/units"
 
 	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/mempool"
+	"github.com/ava-labs/hypersdk/rpc"
 	"github.com/ava-labs/hypersdk/trace"
 )
 
@@ -25,6 +27,7 @@ func (c *Config) GetStateFetchConcurrency() int             { return 1 }
 func (c *Config) GetMempoolSize() int                       { return 2_048 }
 func (c *Config) GetMempoolSponsorSize() int                { return 32 }
 func (c *Config) GetMempoolExemptSponsors() []codec.Address { return nil }
+func (c *Config) GetMempoolMaxBytes() int                   { return 64 * units.MiB }
 func (c *Config) GetStreamingBacklogSize() int              { return 1024 }
 func (c *Config) GetIntermediateNodeCacheSize() int         { return 4 * units.GiB }
 func (c *Config) GetStateIntermediateWriteBufferSize() int  { return 32 * units.MiB }
@@ -33,6 +36,8 @@ func (c *Config) GetValueNodeCacheSize() int                { return 2 * units.G
 func (c *Config) GetTraceConfig() *trace.Config             { return &trace.Config{Enabled: false} }
 func (c *Config) GetStateSyncParallelism() int              { return 4 }
 func (c *Config) GetStateSyncServerDelay() time.Duration    { return 0 } // used for testing
+func (c *Config) GetStateSyncServerConcurrency() int        { return 4 }
+func (c *Config) GetStateSyncServerPeerQuota() int          { return 2 }
 
 func (c *Config) GetParsedBlockCacheSize() int     { return 128 }
 func (c *Config) GetStateHistoryLength() int       { return 256 }
@@ -41,11 +46,50 @@ func (c *Config) GetAcceptedBlockWindow() int      { return 50_000 } // ~3.5hr w
 func (c *Config) GetStateSyncMinBlocks() uint64    { return 768 }    // set to max int for archive nodes to ensure no skips
 func (c *Config) GetAcceptorSize() int             { return 64 }
 
+func (c *Config) GetStateSyncAcceptGracePeriod() time.Duration { return 0 } // wait indefinitely
+func (c *Config) GetStateSyncServerMaxDeferral() time.Duration { return time.Second }
+
 func (c *Config) GetContinuousProfilerConfig() *profiler.Config {
 	return &profiler.Config{Enabled: false}
 }
+func (c *Config) GetMempoolEviction() *mempool.EvictionConfig {
+	return &mempool.EvictionConfig{}
+}
 func (c *Config) GetVerifyAuth() bool                    { return true }
 func (c *Config) GetTargetBuildDuration() time.Duration  { return 100 * time.Millisecond }
 func (c *Config) GetProcessingBuildSkip() int            { return 16 }
 func (c *Config) GetTargetGossipDuration() time.Duration { return 20 * time.Millisecond }
 func (c *Config) GetBlockCompactionFrequency() int       { return 32 } // 64 MB of deletion if 2 MB blocks
+func (c *Config) GetStoreTxsByAddress() bool             { return false }
+func (c *Config) GetStoreTxReceipts() bool               { return false }
+func (c *Config) GetMempoolReservations() bool           { return true }
+func (c *Config) GetLogLevels() map[string]logging.Level { return nil }
+func (c *Config) GetLogLevelsFile() string               { return "" }
+func (c *Config) GetAdminAPI() bool                      { return false }
+func (c *Config) GetShadowRootVerification() bool        { return false }
+func (c *Config) GetCatchUpIncrementalRoots() bool       { return false }
+func (c *Config) GetRecordKeyAccesses() bool             { return false }
+func (c *Config) GetBlockProfileDir() string             { return "" }
+func (c *Config) GetBlockProfileMaxBytes() int64         { return 256 * units.MiB }
+func (c *Config) GetPriorityLaneSize() int               { return 256 }
+func (c *Config) GetPriorityLaneUnitsPercent() uint64    { return 10 }
+func (c *Config) GetParentFetchDepth() int               { return 4 }
+func (c *Config) GetParentFetchRetries() int             { return 3 }
+func (c *Config) GetParentFetchTimeout() time.Duration   { return 2 * time.Second }
+func (c *Config) GetDeferredVerificationSize() int       { return 0 }
+func (c *Config) GetDeferredVerificationCores() int      { return 1 }
+func (c *Config) GetMaxClockCorrection() time.Duration   { return 2 * time.Second }
+func (c *Config) GetClockSkewThreshold() time.Duration   { return time.Second }
+
+func (c *Config) GetRPCNamespaces() map[string]rpc.NamespaceConfig { return nil }
+
+func (c *Config) GetAuthOffloadEndpoints() []string           { return nil }
+func (c *Config) GetAuthOffloadTimeout() time.Duration        { return 250 * time.Millisecond }
+func (c *Config) GetAuthOffloadHealthInterval() time.Duration { return 5 * time.Second }
+func (c *Config) GetAuthOffloadBatchSize() int                { return 256 }
+
+func (c *Config) GetStoreStateUsage() bool   { return false }
+func (c *Config) GetRebuildStateUsage() bool { return false }
+
+func (c *Config) GetChainChecksumInterval() uint64  { return 1_000 }
+func (c *Config) GetChainChecksumRetention() uint64 { return 1_024 }
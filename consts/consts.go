@@ -21,6 +21,7 @@ const (
 
 	MaxUint8              = ^uint8(0)
 	MaxUint16             = ^uint16(0)
+	MaxUint32             = ^uint32(0)
 	MaxUint8Offset        = 7
 	MaxUint               = ^uint(0)
 	MaxInt                = int(MaxUint >> 1)
@@ -3,4 +3,45 @@
 
 package actions
 
-const TransferComputeUnits = 1
+const (
+	TransferComputeUnits = 1
+	ACLComputeUnits      = 1
+	MetadataComputeUnits = 1
+	EchoComputeUnits     = 1
+
+	// EscrowComputeUnits are charged in addition to the units of the
+	// [Transfer] called by [Escrow] and [ReleaseEscrow].
+	EscrowComputeUnits = 1
+
+	// DigestComputeUnits are charged by [EmitDigest] and (in addition to the
+	// units charged for its data) by [Digest].
+	DigestComputeUnits = 1
+
+	// IterateComputeUnits are consumed by [Iterate] before it starts
+	// iterating.
+	IterateComputeUnits = 1
+
+	// IterationsPerComputeUnit is the number of iterations performed by
+	// [Iterate] for each compute unit it consumes.
+	IterationsPerComputeUnit = 64
+
+	// MetadataBytesPerComputeUnit is the number of bytes of a metadata value
+	// charged as one additional compute unit.
+	MetadataBytesPerComputeUnit = 64
+
+	// MaxEchoSize is the maximum size of the message returned by [Echo]. It
+	// is larger than the default [chain.Rules.GetMaxActionOutputBytes] so that
+	// the limit can be exercised.
+	MaxEchoSize = 4_096
+
+	// MaxBurnBps is the [TransferWithBurn.BurnBps] that burns the entire
+	// value (in basis points).
+	MaxBurnBps = 10_000
+
+	// MaxDigestKeySize is the maximum size of the key a [Digest] is stashed
+	// under.
+	MaxDigestKeySize = 64
+
+	// MaxPayReferenceSize is the maximum size of [Pay.Reference].
+	MaxPayReferenceSize = 64
+)
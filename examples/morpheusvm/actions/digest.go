@@ -0,0 +1,155 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"crypto/sha256"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var (
+	_ chain.Action = (*Digest)(nil)
+	_ chain.Action = (*EmitDigest)(nil)
+)
+
+// digestScratchKey returns the key [Digest] stashes the digest named [key]
+// at in the [chain.Scratch] space of a transaction.
+func digestScratchKey(key []byte) string {
+	return "digest/" + string(key)
+}
+
+// Digest computes the SHA-256 digest of [Data] and stashes it under [Key] so
+// that it can be used by a later [EmitDigest] in the same transaction. It
+// doesn't modify state or produce any output.
+type Digest struct {
+	Key  []byte `json:"key"`
+	Data []byte `json:"data"`
+}
+
+func (*Digest) GetTypeID() uint8 {
+	return mconsts.DigestID
+}
+
+func (*Digest) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{}
+}
+
+func (*Digest) StateKeysMaxChunks() []uint16 {
+	return []uint16{}
+}
+
+func (d *Digest) Execute(
+	ctx context.Context,
+	_ chain.Rules,
+	_ state.Mutable,
+	_ int64,
+	_ codec.Address,
+	_ ids.ID,
+) ([][]byte, error) {
+	scratch := chain.Scratch(ctx)
+	if scratch == nil {
+		return nil, ErrNoScratch
+	}
+	digest := sha256.Sum256(d.Data)
+	scratch[digestScratchKey(d.Key)] = digest[:]
+	return nil, nil
+}
+
+// ComputeUnits charges [DigestComputeUnits] plus one unit for each
+// [MetadataBytesPerComputeUnit] bytes of [Data].
+func (d *Digest) ComputeUnits(chain.Rules) uint64 {
+	return DigestComputeUnits + uint64(len(d.Data))/MetadataBytesPerComputeUnit
+}
+
+func (d *Digest) Size() int {
+	return codec.BytesLen(d.Key) + codec.BytesLen(d.Data)
+}
+
+func (d *Digest) Marshal(p *codec.Packer) {
+	p.PackLimitedBytes(d.Key, MaxDigestKeySize)
+	p.PackLimitedBytes(d.Data, MaxEchoSize)
+}
+
+func UnmarshalDigest(p *codec.Packer) (chain.Action, error) {
+	var d Digest
+	p.UnpackBytes(MaxDigestKeySize, false, &d.Key)
+	p.UnpackBytes(MaxEchoSize, false, &d.Data)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (*Digest) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+// EmitDigest returns the digest stashed under [Key] by an earlier [Digest] in
+// the same transaction as its output. It fails with [ErrDigestNotFound] if
+// there is no such digest (digests are never shared between transactions).
+type EmitDigest struct {
+	Key []byte `json:"key"`
+}
+
+func (*EmitDigest) GetTypeID() uint8 {
+	return mconsts.EmitDigestID
+}
+
+func (*EmitDigest) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{}
+}
+
+func (*EmitDigest) StateKeysMaxChunks() []uint16 {
+	return []uint16{}
+}
+
+func (e *EmitDigest) Execute(
+	ctx context.Context,
+	_ chain.Rules,
+	_ state.Mutable,
+	_ int64,
+	_ codec.Address,
+	_ ids.ID,
+) ([][]byte, error) {
+	digest, ok := chain.Scratch(ctx)[digestScratchKey(e.Key)]
+	if !ok {
+		return nil, ErrDigestNotFound
+	}
+	return [][]byte{digest}, nil
+}
+
+func (*EmitDigest) ComputeUnits(chain.Rules) uint64 {
+	return DigestComputeUnits
+}
+
+func (e *EmitDigest) Size() int {
+	return codec.BytesLen(e.Key)
+}
+
+func (e *EmitDigest) Marshal(p *codec.Packer) {
+	p.PackLimitedBytes(e.Key, MaxDigestKeySize)
+}
+
+func UnmarshalEmitDigest(p *codec.Packer) (chain.Action, error) {
+	var e EmitDigest
+	p.UnpackBytes(MaxDigestKeySize, false, &e.Key)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (*EmitDigest) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+func TestDigest(t *testing.T) {
+	require := require.New(t)
+
+	// The actions of a tx share a scratch space
+	ctx := chain.WithScratch(context.TODO())
+	data := []byte("data")
+	digest := &Digest{Key: []byte("a"), Data: data}
+	outputs, err := digest.Execute(ctx, nil, nil, 0, codec.EmptyAddress, ids.Empty)
+	require.NoError(err)
+	require.Empty(outputs)
+
+	expected := sha256.Sum256(data)
+	outputs, err = (&EmitDigest{Key: []byte("a")}).Execute(ctx, nil, nil, 0, codec.EmptyAddress, ids.Empty)
+	require.NoError(err)
+	require.Equal([][]byte{expected[:]}, outputs)
+
+	// Digests are only emitted under the key they were stashed with
+	_, err = (&EmitDigest{Key: []byte("b")}).Execute(ctx, nil, nil, 0, codec.EmptyAddress, ids.Empty)
+	require.ErrorIs(err, ErrDigestNotFound)
+
+	// Another tx can't emit digests stashed by the previous one
+	ctx = chain.WithScratch(context.TODO())
+	_, err = (&EmitDigest{Key: []byte("a")}).Execute(ctx, nil, nil, 0, codec.EmptyAddress, ids.Empty)
+	require.ErrorIs(err, ErrDigestNotFound)
+
+	// Digests can't be computed outside of a tx
+	_, err = digest.Execute(context.TODO(), nil, nil, 0, codec.EmptyAddress, ids.Empty)
+	require.ErrorIs(err, ErrNoScratch)
+
+	p := codec.NewWriter(digest.Size(), consts.NetworkSizeLimit)
+	digest.Marshal(p)
+	require.NoError(p.Err())
+	parsed, err := UnmarshalDigest(codec.NewReader(p.Bytes(), consts.NetworkSizeLimit))
+	require.NoError(err)
+	require.Equal(digest, parsed)
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var _ chain.Action = (*Echo)(nil)
+
+// Echo returns [Message] as its output without modifying state. It is
+// useful for testing how outputs of different sizes are handled.
+type Echo struct {
+	Message []byte `json:"message"`
+}
+
+func (*Echo) GetTypeID() uint8 {
+	return mconsts.EchoID
+}
+
+func (*Echo) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{}
+}
+
+func (*Echo) StateKeysMaxChunks() []uint16 {
+	return []uint16{}
+}
+
+func (e *Echo) Execute(
+	context.Context,
+	chain.Rules,
+	state.Mutable,
+	int64,
+	codec.Address,
+	ids.ID,
+) ([][]byte, error) {
+	return [][]byte{e.Message}, nil
+}
+
+// ComputeUnits charges [EchoComputeUnits] plus one unit for each
+// [MetadataBytesPerComputeUnit] bytes of [Message].
+func (e *Echo) ComputeUnits(chain.Rules) uint64 {
+	return EchoComputeUnits + uint64(len(e.Message))/MetadataBytesPerComputeUnit
+}
+
+func (e *Echo) Size() int {
+	return codec.BytesLen(e.Message)
+}
+
+func (e *Echo) Marshal(p *codec.Packer) {
+	p.PackLimitedBytes(e.Message, MaxEchoSize)
+}
+
+func UnmarshalEcho(p *codec.Packer) (chain.Action, error) {
+	var e Echo
+	p.UnpackBytes(MaxEchoSize, false, &e.Message)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (*Echo) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
+)
+
+func TestEcho(t *testing.T) {
+	limit := genesis.Default().MaxActionOutputBytes
+	tests := []struct {
+		name string
+		size uint64
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "at limit",
+			size: limit,
+		},
+		{
+			name: "over limit",
+			size: limit + 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			// Echo returns its message regardless of size (outputs over
+			// the limit are rejected by the chain)
+			action := &Echo{Message: make([]byte, tt.size)}
+			outputs, err := action.Execute(context.TODO(), nil, nil, 0, codec.EmptyAddress, ids.Empty)
+			require.NoError(err)
+			require.Equal([][]byte{action.Message}, outputs)
+
+			p := codec.NewWriter(action.Size(), consts.NetworkSizeLimit)
+			action.Marshal(p)
+			require.NoError(p.Err())
+			parsed, err := UnmarshalEcho(codec.NewReader(p.Bytes(), consts.NetworkSizeLimit))
+			require.NoError(err)
+			require.Len(parsed.(*Echo).Message, int(tt.size))
+		})
+	}
+
+	// Messages larger than [MaxEchoSize] can't be packed or parsed
+	action := &Echo{Message: make([]byte, MaxEchoSize+1)}
+	p := codec.NewWriter(action.Size(), consts.NetworkSizeLimit)
+	action.Marshal(p)
+	require.ErrorIs(t, p.Err(), codec.ErrFieldTooLarge)
+	p = codec.NewWriter(action.Size(), consts.NetworkSizeLimit)
+	p.PackBytes(action.Message)
+	_, err := UnmarshalEcho(codec.NewReader(p.Bytes(), consts.NetworkSizeLimit))
+	require.ErrorIs(t, err, codec.ErrFieldTooLarge)
+}
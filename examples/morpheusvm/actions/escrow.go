@@ -0,0 +1,193 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var (
+	_ chain.ValueSpender = (*Escrow)(nil)
+	_ chain.Action       = (*ReleaseEscrow)(nil)
+)
+
+// Escrow locks [Value] of the actor until the actor releases it to
+// [Recipient] with [ReleaseEscrow]. The ID of the escrow is the ID of the
+// action that created it.
+//
+// The funds are moved to [storage.EscrowAddress] by calling [Transfer] with
+// [chain.CallAction].
+type Escrow struct {
+	// Recipient is the only address [Value] can be released to.
+	Recipient codec.Address `json:"recipient"`
+
+	// Value is locked until it is released to [Recipient].
+	Value uint64 `json:"value"`
+}
+
+func (*Escrow) GetTypeID() uint8 {
+	return mconsts.EscrowID
+}
+
+// transfer returns the [Transfer] that locks [Value] in escrow [escrowID].
+func (e *Escrow) transfer(escrowID ids.ID) *Transfer {
+	return &Transfer{To: storage.EscrowAddress(escrowID), Value: e.Value}
+}
+
+func (e *Escrow) StateKeys(actor codec.Address, actionID ids.ID) state.Keys {
+	return chain.CallStateKeys(state.Keys{
+		string(storage.EscrowKey(actionID)): state.Allocate | state.Write,
+	}, e.transfer(actionID), actor, actionID)
+}
+
+// ValueSpent implements [chain.ValueSpender].
+func (e *Escrow) ValueSpent() uint64 {
+	return e.Value
+}
+
+func (*Escrow) StateKeysMaxChunks() []uint16 {
+	return chain.CallStateKeysMaxChunks([]uint16{storage.EscrowChunks}, &Transfer{})
+}
+
+func (e *Escrow) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, error) {
+	if _, err := chain.CallAction(ctx, r, mu, timestamp, actor, actionID, e.StateKeys(actor, actionID), e.transfer(actionID)); err != nil {
+		return nil, err
+	}
+	if err := storage.SetEscrow(ctx, mu, actionID, actor, e.Recipient, e.Value); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (*Escrow) ComputeUnits(r chain.Rules) uint64 {
+	return chain.CallComputeUnits(r, EscrowComputeUnits, &Transfer{})
+}
+
+func (*Escrow) Size() int {
+	return codec.AddressLen + consts.Uint64Len
+}
+
+func (e *Escrow) Marshal(p *codec.Packer) {
+	p.PackAddress(e.Recipient)
+	p.PackUint64(e.Value)
+}
+
+func UnmarshalEscrow(p *codec.Packer) (chain.Action, error) {
+	var escrow Escrow
+	p.UnpackAddress(&escrow.Recipient)
+	escrow.Value = p.UnpackUint64(true)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &escrow, nil
+}
+
+func (*Escrow) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
+
+// ReleaseEscrow sends the funds of [EscrowID] to [Recipient] and removes the
+// escrow. Only the creator of the escrow can release it and [Recipient] must
+// match the recipient it was created with.
+type ReleaseEscrow struct {
+	EscrowID  ids.ID        `json:"escrowID"`
+	Recipient codec.Address `json:"recipient"`
+}
+
+func (*ReleaseEscrow) GetTypeID() uint8 {
+	return mconsts.ReleaseEscrowID
+}
+
+// transfer returns the [Transfer] that releases [value] to [Recipient].
+func (re *ReleaseEscrow) transfer(value uint64) *Transfer {
+	return &Transfer{To: re.Recipient, Value: value}
+}
+
+func (re *ReleaseEscrow) StateKeys(_ codec.Address, actionID ids.ID) state.Keys {
+	// The keys of a [Transfer] don't depend on its value
+	return chain.CallStateKeys(state.Keys{
+		string(storage.EscrowKey(re.EscrowID)): state.Read | state.Write,
+	}, re.transfer(0), storage.EscrowAddress(re.EscrowID), actionID)
+}
+
+func (*ReleaseEscrow) StateKeysMaxChunks() []uint16 {
+	return chain.CallStateKeysMaxChunks([]uint16{storage.EscrowChunks}, &Transfer{})
+}
+
+func (re *ReleaseEscrow) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, error) {
+	creator, recipient, value, exists, err := storage.GetEscrow(ctx, mu, re.EscrowID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrEscrowNotFound
+	}
+	if creator != actor {
+		return nil, ErrNotEscrowCreator
+	}
+	if recipient != re.Recipient {
+		return nil, ErrEscrowRecipientMismatch
+	}
+	escrow := storage.EscrowAddress(re.EscrowID)
+	if _, err := chain.CallAction(ctx, r, mu, timestamp, escrow, actionID, re.StateKeys(actor, actionID), re.transfer(value)); err != nil {
+		return nil, err
+	}
+	if err := storage.RemoveEscrow(ctx, mu, re.EscrowID); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (*ReleaseEscrow) ComputeUnits(r chain.Rules) uint64 {
+	return chain.CallComputeUnits(r, EscrowComputeUnits, &Transfer{})
+}
+
+func (*ReleaseEscrow) Size() int {
+	return ids.IDLen + codec.AddressLen
+}
+
+func (re *ReleaseEscrow) Marshal(p *codec.Packer) {
+	p.PackID(re.EscrowID)
+	p.PackAddress(re.Recipient)
+}
+
+func UnmarshalReleaseEscrow(p *codec.Packer) (chain.Action, error) {
+	var release ReleaseEscrow
+	p.UnpackID(true, &release.EscrowID)
+	p.UnpackAddress(&release.Recipient)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (*ReleaseEscrow) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/state"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+func TestEscrow(t *testing.T) {
+	var (
+		creator   = codec.CreateAddress(0, ids.GenerateTestID())
+		recipient = codec.CreateAddress(0, ids.GenerateTestID())
+		escrowID  = ids.GenerateTestID()
+	)
+	tests := []struct {
+		name    string
+		actor   codec.Address
+		release *ReleaseEscrow
+		err     error
+	}{
+		{
+			name:    "release",
+			actor:   creator,
+			release: &ReleaseEscrow{EscrowID: escrowID, Recipient: recipient},
+		},
+		{
+			name:    "not found",
+			actor:   creator,
+			release: &ReleaseEscrow{EscrowID: ids.GenerateTestID(), Recipient: recipient},
+			err:     ErrEscrowNotFound,
+		},
+		{
+			name:    "not creator",
+			actor:   recipient,
+			release: &ReleaseEscrow{EscrowID: escrowID, Recipient: recipient},
+			err:     ErrNotEscrowCreator,
+		},
+		{
+			name:    "recipient mismatch",
+			actor:   creator,
+			release: &ReleaseEscrow{EscrowID: escrowID, Recipient: creator},
+			err:     ErrEscrowRecipientMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			r := genesis.Default().Rules(0, 0, ids.Empty)
+			escrow := &Escrow{Recipient: recipient, Value: 40}
+			releaseID := ids.GenerateTestID()
+
+			stateKeys := escrow.StateKeys(creator, escrowID)
+			for k, p := range tt.release.StateKeys(tt.actor, releaseID) {
+				stateKeys.Add(k, p)
+			}
+			tsv := tstate.New(0).NewView(stateKeys, map[string][]byte{
+				string(storage.BalanceKey(creator)): binary.BigEndian.AppendUint64(nil, 100),
+			})
+
+			// Creating the escrow moves the funds to the escrow address
+			_, err := escrow.Execute(ctx, r, tsv, 0, creator, escrowID)
+			require.NoError(err)
+			balance, err := storage.GetBalance(ctx, tsv, creator)
+			require.NoError(err)
+			require.Equal(uint64(60), balance)
+			balance, err = storage.GetBalance(ctx, tsv, storage.EscrowAddress(escrowID))
+			require.NoError(err)
+			require.Equal(uint64(40), balance)
+
+			_, err = tt.release.Execute(ctx, r, tsv, 0, tt.actor, releaseID)
+			require.ErrorIs(err, tt.err)
+			_, _, _, exists, err := storage.GetEscrow(ctx, tsv, escrowID)
+			require.NoError(err)
+			if tt.err != nil {
+				require.True(exists)
+				return
+			}
+
+			// Releasing the escrow moves the funds to the recipient
+			require.False(exists)
+			balance, err = storage.GetBalance(ctx, tsv, recipient)
+			require.NoError(err)
+			require.Equal(uint64(40), balance)
+			balance, err = storage.GetBalance(ctx, tsv, storage.EscrowAddress(escrowID))
+			require.NoError(err)
+			require.Zero(balance)
+		})
+	}
+}
+
+func TestEscrowUndeclaredKey(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	r := genesis.Default().Rules(0, 0, ids.Empty)
+
+	var (
+		creator  = codec.CreateAddress(0, ids.GenerateTestID())
+		escrowID = ids.GenerateTestID()
+		escrow   = &Escrow{Recipient: codec.CreateAddress(0, ids.GenerateTestID()), Value: 40}
+	)
+	stateKeys := escrow.StateKeys(creator, escrowID)
+	tsv := tstate.New(0).NewView(stateKeys, map[string][]byte{
+		string(storage.BalanceKey(creator)): binary.BigEndian.AppendUint64(nil, 100),
+	})
+
+	// The [Transfer] called by [Escrow] can't touch keys that [Escrow] did
+	// not declare
+	declared := state.Keys{string(storage.EscrowKey(escrowID)): state.Allocate | state.Write}
+	_, err := chain.CallAction(ctx, r, tsv, 0, creator, escrowID, declared, escrow.transfer(escrowID))
+	require.ErrorIs(err, chain.ErrUndeclaredCallKey)
+	balance, err := storage.GetBalance(ctx, tsv, creator)
+	require.NoError(err)
+	require.Equal(uint64(100), balance)
+
+	// The keys of the [Transfer] are included in those of [Escrow]
+	_, err = chain.CallAction(ctx, r, tsv, 0, creator, escrowID, stateKeys, escrow.transfer(escrowID))
+	require.NoError(err)
+	require.Len(stateKeys, 3)
+	require.Len(escrow.StateKeysMaxChunks(), 3)
+	require.Equal(uint64(EscrowComputeUnits+TransferComputeUnits), escrow.ComputeUnits(r))
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"math"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var _ chain.MeteredAction = (*Iterate)(nil)
+
+// Iterate hashes [Seed] [Iterations] times and returns the result without
+// modifying state. It is metered, so it is only charged for the iterations it
+// performs (up to [MaxUnits]).
+type Iterate struct {
+	Seed       ids.ID `json:"seed"`
+	Iterations uint64 `json:"iterations"`
+
+	// MaxUnits is the maximum number of compute units (in addition to
+	// [IterateComputeUnits]) that may be consumed. If [Iterations] requires
+	// more, the transaction reverts.
+	MaxUnits uint64 `json:"maxUnits"`
+}
+
+func (*Iterate) GetTypeID() uint8 {
+	return mconsts.IterateID
+}
+
+func (*Iterate) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{}
+}
+
+func (*Iterate) StateKeysMaxChunks() []uint16 {
+	return []uint16{}
+}
+
+func (i *Iterate) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, error) {
+	return i.ExecuteMetered(ctx, r, mu, timestamp, actor, actionID, chain.NewGasMeter(i.ComputeUnits(r)))
+}
+
+// ExecuteMetered consumes [IterateComputeUnits] and then one unit for every
+// [IterationsPerComputeUnit] iterations (rounded up).
+func (i *Iterate) ExecuteMetered(
+	_ context.Context,
+	_ chain.Rules,
+	_ state.Mutable,
+	_ int64,
+	_ codec.Address,
+	_ ids.ID,
+	meter *chain.GasMeter,
+) ([][]byte, error) {
+	if err := meter.Consume(IterateComputeUnits); err != nil {
+		return nil, err
+	}
+	digest := i.Seed
+	for n := uint64(0); n < i.Iterations; n++ {
+		if n%IterationsPerComputeUnit == 0 {
+			if err := meter.Consume(1); err != nil {
+				return nil, err
+			}
+		}
+		digest = hashing.ComputeHash256Array(digest[:])
+	}
+	return [][]byte{digest[:]}, nil
+}
+
+// ComputeUnits is the maximum number of units [Iterate] may consume
+// (saturating, so that it exceeds any block limit instead of overflowing).
+func (i *Iterate) ComputeUnits(chain.Rules) uint64 {
+	if i.MaxUnits > math.MaxUint64-IterateComputeUnits {
+		return math.MaxUint64
+	}
+	return IterateComputeUnits + i.MaxUnits
+}
+
+func (*Iterate) Size() int {
+	return ids.IDLen + 2*consts.Uint64Len
+}
+
+func (i *Iterate) Marshal(p *codec.Packer) {
+	p.PackID(i.Seed)
+	p.PackUint64(i.Iterations)
+	p.PackUint64(i.MaxUnits)
+}
+
+func UnmarshalIterate(p *codec.Packer) (chain.Action, error) {
+	var i Iterate
+	p.UnpackID(false, &i.Seed)
+	i.Iterations = p.UnpackUint64(false)
+	i.MaxUnits = p.UnpackUint64(false)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (*Iterate) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
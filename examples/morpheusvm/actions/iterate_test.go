@@ -0,0 +1,84 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+func TestIterate(t *testing.T) {
+	tests := []struct {
+		name       string
+		iterations uint64
+		consumed   uint64
+	}{
+		{
+			name:     "none",
+			consumed: IterateComputeUnits,
+		},
+		{
+			name:       "one unit",
+			iterations: IterationsPerComputeUnit,
+			consumed:   IterateComputeUnits + 1,
+		},
+		{
+			name:       "partial unit",
+			iterations: IterationsPerComputeUnit + 1,
+			consumed:   IterateComputeUnits + 2,
+		},
+		{
+			name:       "many units",
+			iterations: 100 * IterationsPerComputeUnit,
+			consumed:   IterateComputeUnits + 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			// The units consumed scale with [Iterations] (not [MaxUnits])
+			action := &Iterate{Seed: ids.GenerateTestID(), Iterations: tt.iterations, MaxUnits: 1_000}
+			meter := chain.NewGasMeter(action.ComputeUnits(nil))
+			outputs, err := action.ExecuteMetered(context.TODO(), nil, nil, 0, codec.EmptyAddress, ids.Empty, meter)
+			require.NoError(err)
+			require.Len(outputs, 1)
+			require.Equal(tt.consumed, meter.Consumed())
+
+			// Execute returns the same output
+			executed, err := action.Execute(context.TODO(), nil, nil, 0, codec.EmptyAddress, ids.Empty)
+			require.NoError(err)
+			require.Equal(outputs, executed)
+
+			p := codec.NewWriter(action.Size(), consts.NetworkSizeLimit)
+			action.Marshal(p)
+			require.NoError(p.Err())
+			parsed, err := UnmarshalIterate(codec.NewReader(p.Bytes(), consts.NetworkSizeLimit))
+			require.NoError(err)
+			require.Equal(action, parsed)
+		})
+	}
+}
+
+func TestIterateOutOfGas(t *testing.T) {
+	require := require.New(t)
+
+	// Iterations that require more than [MaxUnits] revert
+	action := &Iterate{Iterations: 10 * IterationsPerComputeUnit, MaxUnits: 9}
+	meter := chain.NewGasMeter(action.ComputeUnits(nil))
+	_, err := action.ExecuteMetered(context.TODO(), nil, nil, 0, codec.EmptyAddress, ids.Empty, meter)
+	require.ErrorIs(err, chain.ErrOutOfGas)
+
+	// The declared units saturate instead of overflowing
+	action = &Iterate{MaxUnits: math.MaxUint64}
+	require.Equal(uint64(math.MaxUint64), action.ComputeUnits(nil))
+}
@@ -5,4 +5,21 @@ package actions
 
 import "errors"
 
-var ErrOutputValueZero = errors.New("value is zero")
+var (
+	ErrOutputValueZero = errors.New("value is zero")
+	ErrNotACLAdmin     = errors.New("not acl admin")
+
+	ErrMetadataKeyEmpty = errors.New("metadata key is empty")
+	ErrMetadataTooLarge = errors.New("metadata too large")
+
+	ErrEscrowNotFound          = errors.New("escrow not found")
+	ErrNotEscrowCreator        = errors.New("not escrow creator")
+	ErrEscrowRecipientMismatch = errors.New("escrow recipient mismatch")
+
+	ErrNoScratch      = errors.New("no scratch space")
+	ErrDigestNotFound = errors.New("digest not found")
+
+	ErrInvalidBurnBps = errors.New("burn bps exceeds 10000")
+
+	ErrUnsupportedResultVersion = errors.New("unsupported result version")
+)
@@ -0,0 +1,104 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var (
+	_ chain.IdempotentAction = (*Pay)(nil)
+	_ chain.ValueSpender     = (*Pay)(nil)
+)
+
+// Pay is a [Transfer] that settles the payment identified by [Reference]
+// (like an invoice number). If [chain.Rules.GetEnforceIdempotencyKeys] is
+// enabled, each [Reference] can only be paid once (by any actor), so a
+// payment that is re-signed and re-submitted isn't applied twice.
+type Pay struct {
+	// To is the recipient of the [Value].
+	To codec.Address `json:"to"`
+
+	// Value is transferred to [To].
+	Value uint64 `json:"value"`
+
+	// Reference identifies the payment (at most [MaxPayReferenceSize] bytes).
+	Reference []byte `json:"reference"`
+}
+
+func (*Pay) GetTypeID() uint8 {
+	return mconsts.PayID
+}
+
+func (p *Pay) transfer() *Transfer {
+	return &Transfer{To: p.To, Value: p.Value}
+}
+
+func (p *Pay) StateKeys(actor codec.Address, actionID ids.ID) state.Keys {
+	return p.transfer().StateKeys(actor, actionID)
+}
+
+// IdempotencyKey implements [chain.IdempotentAction].
+func (p *Pay) IdempotencyKey() []byte {
+	return p.Reference
+}
+
+// ValueSpent implements [chain.ValueSpender].
+func (p *Pay) ValueSpent() uint64 {
+	return p.Value
+}
+
+func (*Pay) StateKeysMaxChunks() []uint16 {
+	return (&Transfer{}).StateKeysMaxChunks()
+}
+
+func (p *Pay) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	mu state.Mutable,
+	timestamp int64,
+	actor codec.Address,
+	actionID ids.ID,
+) ([][]byte, error) {
+	return p.transfer().Execute(ctx, r, mu, timestamp, actor, actionID)
+}
+
+func (*Pay) ComputeUnits(chain.Rules) uint64 {
+	return TransferComputeUnits
+}
+
+func (p *Pay) Size() int {
+	return codec.AddressLen + consts.Uint64Len + codec.BytesLen(p.Reference)
+}
+
+func (p *Pay) Marshal(packer *codec.Packer) {
+	packer.PackAddress(p.To)
+	packer.PackUint64(p.Value)
+	packer.PackLimitedBytes(p.Reference, MaxPayReferenceSize)
+}
+
+func UnmarshalPay(p *codec.Packer) (chain.Action, error) {
+	var pay Pay
+	p.UnpackAddress(&pay.To)
+	pay.Value = p.UnpackUint64(true)
+	p.UnpackBytes(MaxPayReferenceSize, true, &pay.Reference)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &pay, nil
+}
+
+func (*Pay) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto/ed25519"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/auth"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+func TestPayIdempotency(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	chainID := ids.GenerateTestID()
+	sm := &storage.StateManager{}
+	r := genesis.Default().Rules(0, 0, chainID)
+	require.True(r.GetEnforceIdempotencyKeys())
+
+	actionRegistry := codec.NewTypeParser[chain.Action, bool]()
+	require.NoError(actionRegistry.Register((&Pay{}).GetTypeID(), UnmarshalPay, false))
+	authRegistry := codec.NewTypeParser[chain.Auth, bool]()
+	require.NoError(authRegistry.Register((&auth.ED25519{}).GetTypeID(), auth.UnmarshalED25519, false))
+
+	// The same payment is signed by two different actors
+	var (
+		recipient     = codec.CreateAddress(0, ids.GenerateTestID())
+		pay           = &Pay{To: recipient, Value: 10, Reference: []byte("invoice-42")}
+		storageValues = map[string][]byte{}
+		txs           = make([]*chain.Transaction, 2)
+	)
+	for i := range txs {
+		priv, err := ed25519.GeneratePrivateKey()
+		require.NoError(err)
+		factory := auth.NewED25519Factory(priv)
+		tx, err := chain.NewTx(&chain.Base{Timestamp: 1_000, ChainID: chainID, MaxFee: 1}, []chain.Action{pay}).Sign(factory, actionRegistry, authRegistry)
+		require.NoError(err)
+		storageValues[string(storage.BalanceKey(tx.Auth.Actor()))] = binary.BigEndian.AppendUint64(nil, 100)
+		txs[i] = tx
+	}
+
+	// Only the first payment is applied
+	ts := tstate.New(0)
+	for i, tx := range txs {
+		stateKeys, err := tx.StateKeys(sm, r)
+		require.NoError(err)
+		tsv := ts.NewView(stateKeys, storageValues)
+		result, err := tx.Execute(ctx, fees.NewManager(nil), sm, r, tsv, 0)
+		require.NoError(err)
+		tsv.Commit()
+		if i == 0 {
+			require.True(result.Success)
+			continue
+		}
+		require.False(result.Success)
+		require.Contains(string(result.Error), chain.ErrDuplicateIdempotencyKey.Error())
+	}
+	tsv := ts.NewView(pay.StateKeys(recipient, ids.Empty), storageValues)
+	balance, err := storage.GetBalance(ctx, tsv, recipient)
+	require.NoError(err)
+	require.Equal(pay.Value, balance)
+
+	p := codec.NewWriter(pay.Size(), consts.NetworkSizeLimit)
+	pay.Marshal(p)
+	require.NoError(p.Err())
+	parsed, err := UnmarshalPay(codec.NewReader(p.Bytes(), consts.NetworkSizeLimit))
+	require.NoError(err)
+	require.Equal(pay, parsed)
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var _ chain.AdminAction = (*RevokeAccess)(nil)
+
+// RevokeAccess prevents [Address] from submitting actions with type ID
+// [Action] (if restricted by [chain.Rules.IsActionRestricted]).
+// It can only be submitted by [chain.Rules.GetACLAdmin].
+type RevokeAccess struct {
+	// Action is the type ID of the restricted action.
+	Action uint8 `json:"action"`
+
+	// Address is the actor whose access is revoked.
+	Address codec.Address `json:"address"`
+}
+
+// AdminAction implements [chain.AdminAction], so access can be managed while
+// the chain is paused.
+func (*RevokeAccess) AdminAction() {}
+
+func (*RevokeAccess) GetTypeID() uint8 {
+	return mconsts.RevokeAccessID
+}
+
+func (a *RevokeAccess) StateKeys(codec.Address, ids.ID) state.Keys {
+	return state.Keys{
+		string(chain.ACLKey(storage.ACLKey(a.Action, a.Address))): state.Write,
+	}
+}
+
+func (*RevokeAccess) StateKeysMaxChunks() []uint16 {
+	return []uint16{chain.ACLKeyChunks}
+}
+
+func (a *RevokeAccess) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	mu state.Mutable,
+	_ int64,
+	actor codec.Address,
+	_ ids.ID,
+) ([][]byte, error) {
+	if actor != r.GetACLAdmin() {
+		return nil, ErrNotACLAdmin
+	}
+	if err := chain.RevokeAccess(ctx, mu, &storage.StateManager{}, a.Action, a.Address); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (*RevokeAccess) ComputeUnits(chain.Rules) uint64 {
+	return ACLComputeUnits
+}
+
+func (*RevokeAccess) Size() int {
+	return consts.Uint8Len + codec.AddressLen
+}
+
+func (a *RevokeAccess) Marshal(p *codec.Packer) {
+	p.PackByte(a.Action)
+	p.PackAddress(a.Address)
+}
+
+func UnmarshalRevokeAccess(p *codec.Packer) (chain.Action, error) {
+	var a RevokeAccess
+	a.Action = p.UnpackByte()
+	p.UnpackAddress(&a.Address)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (*RevokeAccess) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
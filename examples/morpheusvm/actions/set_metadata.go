@@ -0,0 +1,90 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var _ chain.Action = (*SetMetadata)(nil)
+
+// SetMetadata stores [Value] at [Key] in the metadata of the actor (like a
+// name or label). If [Value] is empty, [Key] is removed.
+type SetMetadata struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+func (*SetMetadata) GetTypeID() uint8 {
+	return mconsts.SetMetadataID
+}
+
+func (m *SetMetadata) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.MetadataKey(actor, m.Key)): state.Allocate | state.Write,
+	}
+}
+
+func (*SetMetadata) StateKeysMaxChunks() []uint16 {
+	return []uint16{storage.MetadataChunks}
+}
+
+func (m *SetMetadata) Execute(
+	ctx context.Context,
+	_ chain.Rules,
+	mu state.Mutable,
+	_ int64,
+	actor codec.Address,
+	_ ids.ID,
+) ([][]byte, error) {
+	if len(m.Key) == 0 {
+		return nil, ErrMetadataKeyEmpty
+	}
+	if len(m.Key) > storage.MaxMetadataKeySize || len(m.Value) > storage.MaxMetadataValueSize {
+		return nil, ErrMetadataTooLarge
+	}
+	if err := storage.SetMetadata(ctx, mu, actor, m.Key, m.Value); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// ComputeUnits charges [MetadataComputeUnits] plus one unit for each
+// [MetadataBytesPerComputeUnit] bytes of [Value].
+func (m *SetMetadata) ComputeUnits(chain.Rules) uint64 {
+	return MetadataComputeUnits + uint64(len(m.Value))/MetadataBytesPerComputeUnit
+}
+
+func (m *SetMetadata) Size() int {
+	return codec.BytesLen(m.Key) + codec.BytesLen(m.Value)
+}
+
+func (m *SetMetadata) Marshal(p *codec.Packer) {
+	p.PackLimitedBytes(m.Key, storage.MaxMetadataKeySize)
+	p.PackLimitedBytes(m.Value, storage.MaxMetadataValueSize)
+}
+
+func UnmarshalSetMetadata(p *codec.Packer) (chain.Action, error) {
+	var m SetMetadata
+	p.UnpackBytes(storage.MaxMetadataKeySize, true, &m.Key)
+	p.UnpackBytes(storage.MaxMetadataValueSize, false, &m.Value)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (*SetMetadata) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+func TestSetMetadata(t *testing.T) {
+	var (
+		actor = codec.CreateAddress(0, ids.GenerateTestID())
+		key   = []byte("name")
+	)
+	tests := []struct {
+		name     string
+		existing []byte
+		key      []byte
+		value    []byte
+		err      error
+	}{
+		{
+			name:  "set",
+			key:   key,
+			value: []byte("alice"),
+		},
+		{
+			name:     "overwrite",
+			existing: []byte("alice"),
+			key:      key,
+			value:    []byte("bob"),
+		},
+		{
+			name:     "delete",
+			existing: []byte("alice"),
+			key:      key,
+		},
+		{
+			name: "delete missing",
+			key:  key,
+		},
+		{
+			name:  "empty key",
+			value: []byte("alice"),
+			err:   ErrMetadataKeyEmpty,
+		},
+		{
+			name:  "key too large",
+			key:   bytes.Repeat([]byte{1}, storage.MaxMetadataKeySize+1),
+			value: []byte("alice"),
+			err:   ErrMetadataTooLarge,
+		},
+		{
+			name:     "value too large",
+			existing: []byte("alice"),
+			key:      key,
+			value:    bytes.Repeat([]byte{1}, storage.MaxMetadataValueSize+1),
+			err:      ErrMetadataTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			action := &SetMetadata{Key: tt.key, Value: tt.value}
+
+			storageValues := map[string][]byte{}
+			if tt.existing != nil {
+				storageValues[string(storage.MetadataKey(actor, tt.key))] = tt.existing
+			}
+			ts := tstate.New(0)
+			tsv := ts.NewView(action.StateKeys(actor, ids.Empty), storageValues)
+			_, err := action.Execute(ctx, nil, tsv, 0, actor, ids.Empty)
+			require.ErrorIs(err, tt.err)
+
+			value, err := storage.GetMetadata(ctx, tsv, actor, tt.key)
+			require.NoError(err)
+			if tt.err != nil {
+				// Nothing is modified if the action fails
+				require.Equal(tt.existing, value)
+				return
+			}
+			if len(tt.value) == 0 {
+				require.Nil(value)
+				return
+			}
+			require.Equal(tt.value, value)
+		})
+	}
+}
+
+func TestSetMetadataComputeUnits(t *testing.T) {
+	require := require.New(t)
+	small := &SetMetadata{Key: []byte("name"), Value: []byte("alice")}
+	large := &SetMetadata{Key: []byte("name"), Value: bytes.Repeat([]byte{1}, storage.MaxMetadataValueSize)}
+	require.Equal(uint64(MetadataComputeUnits), small.ComputeUnits(nil))
+	require.Equal(uint64(MetadataComputeUnits+storage.MaxMetadataValueSize/MetadataBytesPerComputeUnit), large.ComputeUnits(nil))
+}
+
+func TestUnmarshalSetMetadataTooLarge(t *testing.T) {
+	require := require.New(t)
+	for _, action := range []*SetMetadata{
+		{Key: bytes.Repeat([]byte{1}, storage.MaxMetadataKeySize+1), Value: []byte("alice")},
+		{Key: []byte("name"), Value: bytes.Repeat([]byte{1}, storage.MaxMetadataValueSize+1)},
+	} {
+		p := codec.NewWriter(action.Size(), consts.NetworkSizeLimit)
+		action.Marshal(p)
+		require.ErrorIs(p.Err(), codec.ErrFieldTooLarge)
+
+		p = codec.NewWriter(action.Size(), consts.NetworkSizeLimit)
+		p.PackBytes(action.Key)
+		p.PackBytes(action.Value)
+		_, err := UnmarshalSetMetadata(codec.NewReader(p.Bytes(), consts.NetworkSizeLimit))
+		require.ErrorIs(err, codec.ErrFieldTooLarge)
+	}
+}
@@ -5,6 +5,7 @@ package actions
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ava-labs/avalanchego/ids"
 
@@ -17,7 +18,7 @@ import (
 	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
 )
 
-var _ chain.Action = (*Transfer)(nil)
+var _ chain.ValueSpender = (*Transfer)(nil)
 
 type Transfer struct {
 	// To is the recipient of the [Value].
@@ -31,6 +32,8 @@ func (*Transfer) GetTypeID() uint8 {
 	return mconsts.TransferID
 }
 
+// StateKeys declares [state.Allocate] on the balance of [To] because it is
+// created if [To] has never held a balance.
 func (t *Transfer) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
 	return state.Keys{
 		string(storage.BalanceKey(actor)): state.Read | state.Write,
@@ -38,6 +41,11 @@ func (t *Transfer) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
 	}
 }
 
+// ValueSpent implements [chain.ValueSpender].
+func (t *Transfer) ValueSpent() uint64 {
+	return t.Value
+}
+
 func (*Transfer) StateKeysMaxChunks() []uint16 {
 	return []uint16{storage.BalanceChunks, storage.BalanceChunks}
 }
@@ -59,7 +67,23 @@ func (t *Transfer) Execute(
 	if err := storage.AddBalance(ctx, mu, t.To, t.Value, true); err != nil {
 		return nil, err
 	}
-	return nil, nil
+
+	// [actor] and [To] may be the same address, so balances are read after
+	// both are updated.
+	senderBalance, err := storage.GetBalance(ctx, mu, actor)
+	if err != nil {
+		return nil, err
+	}
+	receiverBalance, err := storage.GetBalance(ctx, mu, t.To)
+	if err != nil {
+		return nil, err
+	}
+	tr := &TransferResult{SenderBalance: senderBalance, ReceiverBalance: receiverBalance}
+	output, err := tr.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{output}, nil
 }
 
 func (*Transfer) ComputeUnits(chain.Rules) uint64 {
@@ -89,3 +113,38 @@ func (*Transfer) ValidRange(chain.Rules) (int64, int64) {
 	// Returning -1, -1 means that the action is always valid.
 	return -1, -1
 }
+
+// TransferResultVersion is the first byte of each encoded [TransferResult].
+const TransferResultVersion uint8 = 0
+
+// TransferResult is the output of a [Transfer]. It contains the balances of
+// the sender and the recipient after the transfer (so wallets don't need to
+// query them).
+type TransferResult struct {
+	SenderBalance   uint64 `json:"senderBalance"`
+	ReceiverBalance uint64 `json:"receiverBalance"`
+}
+
+func UnmarshalTransferResult(b []byte) (*TransferResult, error) {
+	p := codec.NewReader(b, consts.ByteLen+consts.Uint64Len*2)
+	version := p.UnpackByte()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if version != TransferResultVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedResultVersion, version)
+	}
+	var result TransferResult
+	result.SenderBalance = p.UnpackUint64(false)  // if 0, sender balance deleted
+	result.ReceiverBalance = p.UnpackUint64(true) // transfers of 0 are not allowed
+	return &result, p.Err()
+}
+
+func (t *TransferResult) Marshal() ([]byte, error) {
+	size := consts.ByteLen + consts.Uint64Len*2
+	p := codec.NewWriter(size, size)
+	p.PackByte(TransferResultVersion)
+	p.PackUint64(t.SenderBalance)
+	p.PackUint64(t.ReceiverBalance)
+	return p.Bytes(), p.Err()
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+func TestTransferResult(t *testing.T) {
+	var (
+		sender   = codec.CreateAddress(0, ids.GenerateTestID())
+		receiver = codec.CreateAddress(0, ids.GenerateTestID())
+	)
+	tests := []struct {
+		name            string
+		to              codec.Address
+		value           uint64
+		receiverBalance uint64
+	}{
+		{
+			name:            "existing receiver",
+			to:              receiver,
+			value:           10,
+			receiverBalance: 5,
+		},
+		{
+			name:  "new receiver",
+			to:    receiver,
+			value: 10,
+		},
+		{
+			name:  "full balance",
+			to:    receiver,
+			value: 100,
+		},
+		{
+			name:  "self",
+			to:    sender,
+			value: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			transfer := &Transfer{To: tt.to, Value: tt.value}
+
+			storageValues := map[string][]byte{
+				string(storage.BalanceKey(sender)): binary.BigEndian.AppendUint64(nil, 100),
+			}
+			if tt.receiverBalance > 0 {
+				storageValues[string(storage.BalanceKey(tt.to))] = binary.BigEndian.AppendUint64(nil, tt.receiverBalance)
+			}
+			ts := tstate.New(0)
+			tsv := ts.NewView(transfer.StateKeys(sender, ids.Empty), storageValues)
+			outputs, err := transfer.Execute(ctx, nil, tsv, 0, sender, ids.Empty)
+			require.NoError(err)
+			require.Len(outputs, 1)
+
+			// The output matches the balances in state after execution
+			result, err := UnmarshalTransferResult(outputs[0])
+			require.NoError(err)
+			senderBalance, err := storage.GetBalance(ctx, tsv, sender)
+			require.NoError(err)
+			receiverBalance, err := storage.GetBalance(ctx, tsv, tt.to)
+			require.NoError(err)
+			require.Equal(senderBalance, result.SenderBalance)
+			require.Equal(receiverBalance, result.ReceiverBalance)
+			if tt.to == sender {
+				require.Equal(uint64(100), result.SenderBalance)
+			} else {
+				require.Equal(100-tt.value, result.SenderBalance)
+				require.Equal(tt.receiverBalance+tt.value, result.ReceiverBalance)
+			}
+		})
+	}
+}
+
+func TestUnmarshalTransferResultVersion(t *testing.T) {
+	require := require.New(t)
+	output, err := (&TransferResult{SenderBalance: 1, ReceiverBalance: 2}).Marshal()
+	require.NoError(err)
+	output[0] = TransferResultVersion + 1
+	_, err = UnmarshalTransferResult(output)
+	require.ErrorIs(err, ErrUnsupportedResultVersion)
+}
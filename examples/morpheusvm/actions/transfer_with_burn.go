@@ -0,0 +1,128 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/state"
+
+	mconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var _ chain.ValueSpender = (*TransferWithBurn)(nil)
+
+// TransferWithBurn transfers [Value] to [To] but burns [BurnBps] basis points
+// of it (removing them from the total supply). [To] is credited the
+// remainder.
+type TransferWithBurn struct {
+	// To is the recipient of the unburned portion of [Value].
+	To codec.Address `json:"to"`
+
+	// Value is debited from the actor.
+	Value uint64 `json:"value"`
+
+	// BurnBps is the portion of [Value] burned (at most [MaxBurnBps]).
+	BurnBps uint16 `json:"burnBps"`
+}
+
+func (*TransferWithBurn) GetTypeID() uint8 {
+	return mconsts.TransferWithBurnID
+}
+
+// StateKeys declares [state.Allocate] on the balance of [To] because it is
+// created if [To] has never held a balance. Every [TransferWithBurn] writes
+// the supply key, so they can't be executed in parallel.
+func (t *TransferWithBurn) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
+	return state.Keys{
+		string(storage.BalanceKey(actor)): state.Read | state.Write,
+		string(storage.BalanceKey(t.To)):  state.All,
+		string(storage.SupplyKey()):       state.Read | state.Write,
+	}
+}
+
+// ValueSpent implements [chain.ValueSpender].
+func (t *TransferWithBurn) ValueSpent() uint64 {
+	return t.Value
+}
+
+func (*TransferWithBurn) StateKeysMaxChunks() []uint16 {
+	return []uint16{storage.BalanceChunks, storage.BalanceChunks, storage.SupplyChunks}
+}
+
+// Burned returns the portion of [Value] that is burned. It is rounded down,
+// so [To] is credited any fraction of a unit.
+func (t *TransferWithBurn) Burned() uint64 {
+	// Split [Value] so the product can't overflow
+	bps := uint64(t.BurnBps)
+	return t.Value/MaxBurnBps*bps + t.Value%MaxBurnBps*bps/MaxBurnBps
+}
+
+func (t *TransferWithBurn) Execute(
+	ctx context.Context,
+	_ chain.Rules,
+	mu state.Mutable,
+	_ int64,
+	actor codec.Address,
+	_ ids.ID,
+) ([][]byte, error) {
+	if t.Value == 0 {
+		return nil, ErrOutputValueZero
+	}
+	if t.BurnBps > MaxBurnBps {
+		return nil, ErrInvalidBurnBps
+	}
+	if err := storage.SubBalance(ctx, mu, actor, t.Value); err != nil {
+		return nil, err
+	}
+	burned := t.Burned()
+	if burned > 0 {
+		if err := storage.SubSupply(ctx, mu, burned); err != nil {
+			return nil, err
+		}
+	}
+	// Don't create a balance of 0 for [To] if everything is burned
+	if credited := t.Value - burned; credited > 0 {
+		if err := storage.AddBalance(ctx, mu, t.To, credited, true); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (*TransferWithBurn) ComputeUnits(chain.Rules) uint64 {
+	return TransferComputeUnits
+}
+
+func (*TransferWithBurn) Size() int {
+	return codec.AddressLen + consts.Uint64Len + consts.Uint16Len
+}
+
+func (t *TransferWithBurn) Marshal(p *codec.Packer) {
+	p.PackAddress(t.To)
+	p.PackUint64(t.Value)
+	p.PackUint16(t.BurnBps)
+}
+
+func UnmarshalTransferWithBurn(p *codec.Packer) (chain.Action, error) {
+	var transfer TransferWithBurn
+	p.UnpackAddress(&transfer.To)
+	transfer.Value = p.UnpackUint64(true)
+	transfer.BurnBps = p.UnpackUint16(false) // 0 is a plain transfer
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (*TransferWithBurn) ValidRange(chain.Rules) (int64, int64) {
+	// Returning -1, -1 means that the action is always valid.
+	return -1, -1
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/tstate"
+)
+
+func TestTransferWithBurn(t *testing.T) {
+	var (
+		sender   = codec.CreateAddress(0, ids.GenerateTestID())
+		receiver = codec.CreateAddress(0, ids.GenerateTestID())
+	)
+	tests := []struct {
+		name    string
+		value   uint64
+		balance uint64
+		bps     uint16
+		err     error
+
+		burned   uint64
+		credited uint64
+	}{
+		{
+			name:     "no burn",
+			value:    1_001,
+			balance:  2_000,
+			credited: 1_001,
+		},
+		{
+			name:    "full burn",
+			value:   1_001,
+			balance: 2_000,
+			bps:     MaxBurnBps,
+			burned:  1_001,
+		},
+		{
+			// 25% of 1_001 is 250.25, which is rounded down
+			name:     "fractional burn",
+			value:    1_001,
+			balance:  2_000,
+			bps:      2_500,
+			burned:   250,
+			credited: 751,
+		},
+		{
+			// 0.01% of 9_999 is 0.9999, which is rounded down
+			name:     "burn less than a unit",
+			value:    9_999,
+			balance:  9_999,
+			bps:      1,
+			credited: 9_999,
+		},
+		{
+			name:     "max value",
+			value:    consts.MaxUint64,
+			balance:  consts.MaxUint64,
+			bps:      MaxBurnBps - 1,
+			burned:   consts.MaxUint64 - consts.MaxUint64/MaxBurnBps - 1,
+			credited: consts.MaxUint64/MaxBurnBps + 1,
+		},
+		{
+			name:    "invalid bps",
+			value:   1_001,
+			balance: 2_000,
+			bps:     MaxBurnBps + 1,
+			err:     ErrInvalidBurnBps,
+		},
+		{
+			name:    "insufficient balance",
+			value:   1_001,
+			balance: 1_000,
+			bps:     2_500,
+			err:     storage.ErrInvalidBalance,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			transfer := &TransferWithBurn{To: receiver, Value: tt.value, BurnBps: tt.bps}
+
+			ts := tstate.New(0)
+			tsv := ts.NewView(transfer.StateKeys(sender, ids.Empty), map[string][]byte{
+				string(storage.BalanceKey(sender)): binary.BigEndian.AppendUint64(nil, tt.balance),
+				string(storage.SupplyKey()):        binary.BigEndian.AppendUint64(nil, tt.balance),
+			})
+			_, err := transfer.Execute(ctx, nil, tsv, 0, sender, ids.Empty)
+			require.ErrorIs(err, tt.err)
+			if err != nil {
+				return
+			}
+			require.Equal(tt.burned, transfer.Burned())
+
+			// The burned portion is removed from the supply
+			senderBalance, err := storage.GetBalance(ctx, tsv, sender)
+			require.NoError(err)
+			require.Equal(tt.balance-tt.value, senderBalance)
+			receiverBalance, err := storage.GetBalance(ctx, tsv, receiver)
+			require.NoError(err)
+			require.Equal(tt.credited, receiverBalance)
+			supply, err := storage.GetSupply(ctx, tsv)
+			require.NoError(err)
+			require.Equal(tt.balance-tt.burned, supply)
+			require.Equal(supply, senderBalance+receiverBalance)
+
+			// No balance is created for the receiver if everything is burned
+			_, err = tsv.GetValue(ctx, storage.BalanceKey(receiver))
+			require.Equal(tt.credited > 0, err == nil)
+		})
+	}
+}
+
+func TestTransferWithBurnMarshal(t *testing.T) {
+	require := require.New(t)
+
+	transfer := &TransferWithBurn{
+		To:      codec.CreateAddress(0, ids.GenerateTestID()),
+		Value:   1_001,
+		BurnBps: 2_500,
+	}
+	p := codec.NewWriter(transfer.Size(), transfer.Size())
+	transfer.Marshal(p)
+	require.NoError(p.Err())
+	require.Len(p.Bytes(), transfer.Size())
+
+	parsed, err := UnmarshalTransferWithBurn(codec.NewReader(p.Bytes(), transfer.Size()))
+	require.NoError(err)
+	require.Equal(transfer, parsed)
+}
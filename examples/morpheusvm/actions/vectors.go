@@ -0,0 +1,249 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"encoding/binary"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+)
+
+// Addresses used by the conformance vectors
+var (
+	vectorAlice = codec.Address{0, 0xa1}
+	vectorBob   = codec.Address{0, 0xb0}
+)
+
+// vectorUint64 returns the big-endian encoding of [v] (the encoding of both
+// packed values and balances in state).
+func vectorUint64(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
+
+// vectorBalances returns the state holding the balance of each address in
+// [balances].
+func vectorBalances(balances map[codec.Address]uint64) map[string][]byte {
+	state := make(map[string][]byte, len(balances))
+	for addr, balance := range balances {
+		state[string(storage.BalanceKey(addr))] = vectorUint64(balance)
+	}
+	return state
+}
+
+// vectorSupply returns the state holding the balance of each address in
+// [balances] and the total supply (their sum).
+func vectorSupply(balances map[codec.Address]uint64) map[string][]byte {
+	state := vectorBalances(balances)
+	var supply uint64
+	for _, balance := range balances {
+		supply += balance
+	}
+	state[string(storage.SupplyKey())] = vectorUint64(supply)
+	return state
+}
+
+// vectorTransferResult returns the encoding of a [TransferResult].
+func vectorTransferResult(sender uint64, receiver uint64) [][]byte {
+	output := []byte{TransferResultVersion}
+	output = append(output, vectorUint64(sender)...)
+	output = append(output, vectorUint64(receiver)...)
+	return [][]byte{output}
+}
+
+// BurnVectors returns the conformance vectors of [Burn].
+func BurnVectors() *chain.ActionVectors {
+	return &chain.ActionVectors{
+		Codec: []chain.ActionCodecVector{
+			{
+				Name:   "burn",
+				Bytes:  []byte{0, 0, 0, 0, 0, 0, 0x01, 0x02},
+				Action: &Burn{Value: 0x0102},
+			},
+		},
+		Invalid: []chain.ActionInvalidVector{
+			{
+				Name:  "burn truncated",
+				Bytes: []byte{0, 0, 0, 1},
+				Err:   wrappers.ErrInsufficientLength,
+			},
+			{
+				Name:  "burn zero value",
+				Bytes: vectorUint64(0),
+				Err:   codec.ErrFieldNotPopulated,
+			},
+		},
+		Execute: []chain.ActionExecuteVector{
+			{
+				Name:      "burn partial balance",
+				Action:    &Burn{Value: 30},
+				Actor:     vectorAlice,
+				PreState:  vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				PostState: vectorBalances(map[codec.Address]uint64{vectorAlice: 70}),
+			},
+			{
+				Name:     "burn entire balance",
+				Action:   &Burn{Value: 100},
+				Actor:    vectorAlice,
+				PreState: vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+			},
+			{
+				Name:     "burn insufficient balance",
+				Action:   &Burn{Value: 101},
+				Actor:    vectorAlice,
+				PreState: vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				Err:      storage.ErrInvalidBalance,
+			},
+			{
+				Name:     "burn zero value",
+				Action:   &Burn{},
+				Actor:    vectorAlice,
+				PreState: vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				Err:      ErrOutputValueZero,
+			},
+		},
+	}
+}
+
+// TransferVectors returns the conformance vectors of [Transfer].
+func TransferVectors() *chain.ActionVectors {
+	return &chain.ActionVectors{
+		Codec: []chain.ActionCodecVector{
+			{
+				Name:   "transfer",
+				Bytes:  append(vectorBob[:], 0, 0, 0, 0, 0, 0, 0, 0x0a),
+				Action: &Transfer{To: vectorBob, Value: 10},
+			},
+		},
+		Invalid: []chain.ActionInvalidVector{
+			{
+				Name:  "transfer truncated",
+				Bytes: append(vectorBob[:], 0, 0, 0, 0x0a),
+				Err:   wrappers.ErrInsufficientLength,
+			},
+			{
+				Name:  "transfer empty recipient",
+				Bytes: append(make([]byte, codec.AddressLen), vectorUint64(10)...),
+				Err:   codec.ErrFieldNotPopulated,
+			},
+			{
+				Name:  "transfer zero value",
+				Bytes: append(vectorBob[:], vectorUint64(0)...),
+				Err:   codec.ErrFieldNotPopulated,
+			},
+		},
+		Execute: []chain.ActionExecuteVector{
+			{
+				Name:      "transfer to new recipient",
+				Action:    &Transfer{To: vectorBob, Value: 10},
+				Actor:     vectorAlice,
+				PreState:  vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				PostState: vectorBalances(map[codec.Address]uint64{vectorAlice: 90, vectorBob: 10}),
+				Outputs:   vectorTransferResult(90, 10),
+			},
+			{
+				Name:      "transfer to existing recipient",
+				Action:    &Transfer{To: vectorBob, Value: 10},
+				Actor:     vectorAlice,
+				PreState:  vectorBalances(map[codec.Address]uint64{vectorAlice: 100, vectorBob: 5}),
+				PostState: vectorBalances(map[codec.Address]uint64{vectorAlice: 90, vectorBob: 15}),
+				Outputs:   vectorTransferResult(90, 15),
+			},
+			{
+				Name:      "transfer entire balance",
+				Action:    &Transfer{To: vectorBob, Value: 100},
+				Actor:     vectorAlice,
+				PreState:  vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				PostState: vectorBalances(map[codec.Address]uint64{vectorBob: 100}),
+				Outputs:   vectorTransferResult(0, 100),
+			},
+			{
+				Name:      "transfer to self",
+				Action:    &Transfer{To: vectorAlice, Value: 10},
+				Actor:     vectorAlice,
+				PreState:  vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				PostState: vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				Outputs:   vectorTransferResult(100, 100),
+			},
+			{
+				Name:     "transfer insufficient balance",
+				Action:   &Transfer{To: vectorBob, Value: 101},
+				Actor:    vectorAlice,
+				PreState: vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				Err:      storage.ErrInvalidBalance,
+			},
+			{
+				Name:     "transfer zero value",
+				Action:   &Transfer{To: vectorBob},
+				Actor:    vectorAlice,
+				PreState: vectorBalances(map[codec.Address]uint64{vectorAlice: 100}),
+				Err:      ErrOutputValueZero,
+			},
+		},
+	}
+}
+
+// TransferWithBurnVectors returns the conformance vectors of
+// [TransferWithBurn].
+func TransferWithBurnVectors() *chain.ActionVectors {
+	return &chain.ActionVectors{
+		Codec: []chain.ActionCodecVector{
+			{
+				Name:   "transfer with burn",
+				Bytes:  append(vectorBob[:], 0, 0, 0, 0, 0, 0, 0x03, 0xe9, 0x09, 0xc4),
+				Action: &TransferWithBurn{To: vectorBob, Value: 1_001, BurnBps: 2_500},
+			},
+			{
+				Name:   "transfer with no burn",
+				Bytes:  append(vectorBob[:], 0, 0, 0, 0, 0, 0, 0, 0x0a, 0, 0),
+				Action: &TransferWithBurn{To: vectorBob, Value: 10},
+			},
+		},
+		Invalid: []chain.ActionInvalidVector{
+			{
+				Name:  "transfer with burn truncated",
+				Bytes: append(vectorBob[:], vectorUint64(10)...),
+				Err:   wrappers.ErrInsufficientLength,
+			},
+			{
+				Name:  "transfer with burn zero value",
+				Bytes: append(vectorBob[:], 0, 0, 0, 0, 0, 0, 0, 0, 0x09, 0xc4),
+				Err:   codec.ErrFieldNotPopulated,
+			},
+		},
+		Execute: []chain.ActionExecuteVector{
+			{
+				Name:      "transfer with fractional burn",
+				Action:    &TransferWithBurn{To: vectorBob, Value: 1_001, BurnBps: 2_500},
+				Actor:     vectorAlice,
+				PreState:  vectorSupply(map[codec.Address]uint64{vectorAlice: 2_000}),
+				PostState: vectorSupply(map[codec.Address]uint64{vectorAlice: 999, vectorBob: 751}),
+			},
+			{
+				Name:      "transfer with no burn",
+				Action:    &TransferWithBurn{To: vectorBob, Value: 10},
+				Actor:     vectorAlice,
+				PreState:  vectorSupply(map[codec.Address]uint64{vectorAlice: 100}),
+				PostState: vectorSupply(map[codec.Address]uint64{vectorAlice: 90, vectorBob: 10}),
+			},
+			{
+				Name:      "transfer with full burn",
+				Action:    &TransferWithBurn{To: vectorBob, Value: 10, BurnBps: MaxBurnBps},
+				Actor:     vectorAlice,
+				PreState:  vectorSupply(map[codec.Address]uint64{vectorAlice: 100}),
+				PostState: vectorSupply(map[codec.Address]uint64{vectorAlice: 90}),
+			},
+			{
+				Name:     "transfer with invalid burn",
+				Action:   &TransferWithBurn{To: vectorBob, Value: 10, BurnBps: MaxBurnBps + 1},
+				Actor:    vectorAlice,
+				PreState: vectorSupply(map[codec.Address]uint64{vectorAlice: 100}),
+				Err:      ErrInvalidBurnBps,
+			},
+		},
+	}
+}
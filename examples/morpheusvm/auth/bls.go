@@ -14,7 +14,10 @@ import (
 	"github.com/ava-labs/hypersdk/utils"
 )
 
-var _ chain.Auth = (*BLS)(nil)
+var (
+	_ chain.Auth            = (*BLS)(nil)
+	_ chain.OffloadableAuth = (*BLS)(nil)
+)
 
 const (
 	BLSComputeUnits = 10
@@ -54,6 +57,10 @@ func (b *BLS) Verify(_ context.Context, msg []byte) error {
 	return nil
 }
 
+func (b *BLS) SignatureData() ([]byte, []byte) {
+	return bls.PublicKeyToBytes(b.Signer), bls.SignatureToBytes(b.Signature)
+}
+
 func (b *BLS) Actor() codec.Address {
 	return b.address()
 }
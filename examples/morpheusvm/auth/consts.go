@@ -4,7 +4,11 @@
 package auth
 
 import (
+	"github.com/ava-labs/hypersdk/crypto/bls"
+	"github.com/ava-labs/hypersdk/crypto/ed25519"
+	"github.com/ava-labs/hypersdk/crypto/secp256r1"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	"github.com/ava-labs/hypersdk/offload"
 	"github.com/ava-labs/hypersdk/vm"
 )
 
@@ -14,3 +18,33 @@ func Engines() map[uint8]vm.AuthEngine {
 		consts.ED25519ID: &ED25519AuthEngine{},
 	}
 }
+
+// OffloadVerifiers verify the [SignatureData] of each auth type on a
+// signature verification sidecar.
+func OffloadVerifiers() map[uint8]offload.Verifier {
+	return map[uint8]offload.Verifier{
+		consts.ED25519ID: func(digest []byte, publicKey []byte, signature []byte) bool {
+			if len(publicKey) != ed25519.PublicKeyLen || len(signature) != ed25519.SignatureLen {
+				return false
+			}
+			return ed25519.Verify(digest, ed25519.PublicKey(publicKey), ed25519.Signature(signature))
+		},
+		consts.SECP256R1ID: func(digest []byte, publicKey []byte, signature []byte) bool {
+			if len(publicKey) != secp256r1.PublicKeyLen || len(signature) != secp256r1.SignatureLen {
+				return false
+			}
+			return secp256r1.Verify(digest, secp256r1.PublicKey(publicKey), secp256r1.Signature(signature))
+		},
+		consts.BLSID: func(digest []byte, publicKey []byte, signature []byte) bool {
+			pk, err := bls.PublicKeyFromBytes(publicKey)
+			if err != nil {
+				return false
+			}
+			sig, err := bls.SignatureFromBytes(signature)
+			if err != nil {
+				return false
+			}
+			return bls.Verify(digest, pk, sig)
+		},
+	}
+}
@@ -14,7 +14,10 @@ import (
 	"github.com/ava-labs/hypersdk/utils"
 )
 
-var _ chain.Auth = (*ED25519)(nil)
+var (
+	_ chain.Auth            = (*ED25519)(nil)
+	_ chain.OffloadableAuth = (*ED25519)(nil)
+)
 
 const (
 	ED25519ComputeUnits = 5
@@ -54,6 +57,10 @@ func (d *ED25519) Verify(_ context.Context, msg []byte) error {
 	return nil
 }
 
+func (d *ED25519) SignatureData() ([]byte, []byte) {
+	return d.Signer[:], d.Signature[:]
+}
+
 func (d *ED25519) Actor() codec.Address {
 	return d.address()
 }
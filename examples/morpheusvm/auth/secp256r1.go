@@ -14,7 +14,10 @@ import (
 	"github.com/ava-labs/hypersdk/utils"
 )
 
-var _ chain.Auth = (*SECP256R1)(nil)
+var (
+	_ chain.Auth            = (*SECP256R1)(nil)
+	_ chain.OffloadableAuth = (*SECP256R1)(nil)
+)
 
 const (
 	SECP256R1ComputeUnits = 10 // can't be batched like ed25519
@@ -54,6 +57,10 @@ func (d *SECP256R1) Verify(_ context.Context, msg []byte) error {
 	return nil
 }
 
+func (d *SECP256R1) SignatureData() ([]byte, []byte) {
+	return d.Signer[:], d.Signature[:]
+}
+
 func (d *SECP256R1) Actor() codec.Address {
 	return d.address()
 }
@@ -0,0 +1,47 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package challenge
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchVerify(t *testing.T) {
+	require := require.New(t)
+
+	salt, err := New()
+	require.NoError(err)
+	solution, attempts := Search(salt, 8, 2)
+	require.Positive(attempts)
+	require.True(Verify(salt, solution, 8))
+
+	// Solutions are only valid for the salt they were found for
+	otherSalt, err := New()
+	require.NoError(err)
+	require.False(Verify(otherSalt, solution, 64))
+
+	// Malformed salts and solutions are never valid
+	require.False(Verify(salt[1:], solution, 0))
+	require.False(Verify(salt, make([]byte, maxSolutionSize+1), 0))
+}
+
+// Bench: go test -bench=. -benchtime=20x -benchmem
+func BenchmarkSearch(b *testing.B) {
+	salt, err := New()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, difficulty := range []uint16{22, 24, 26, 28} {
+		for _, cores := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("difficulty=%d cores=%d", difficulty, cores), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					Search(salt, difficulty, cores)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ava-labs/hypersdk/cli"
+	"github.com/ava-labs/hypersdk/crypto/bls"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/config"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
+	"github.com/ava-labs/hypersdk/utils"
+
+	smath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+const (
+	validatorProfile = "validator"
+	rpcProfile       = "rpc"
+	archiveProfile   = "archive"
+
+	maxDescriptorValidators = 128
+	fsModeDir               = 0o700
+
+	genesisFileName      = "genesis.json"
+	vmConfigFileName     = "config.json"
+	subnetConfigFileName = "subnet.json"
+	manifestFileName     = "manifest.env"
+	keysDirName          = "keys"
+	validatorsDirName    = "validators"
+	signerKeyFileName    = "signer.key"
+)
+
+var profiles = []string{validatorProfile, rpcProfile, archiveProfile}
+
+// ChainDescriptor describes a chain scaffolded by "chain create".
+type ChainDescriptor struct {
+	Name    string `yaml:"name"`
+	Profile string `yaml:"profile"` // validator, rpc, or archive
+	Output  string `yaml:"output"`  // defaults to ./[Name]
+
+	// Validators is the number of validator signer keys to generate.
+	Validators int `yaml:"validators"`
+
+	// MaxSupply is the maximum sum of all allocations (ignored if 0).
+	MaxSupply uint64 `yaml:"maxSupply"`
+
+	Keys        []*DescriptorKey        `yaml:"keys"`
+	Allocations []*DescriptorAllocation `yaml:"allocations"`
+
+	// Genesis overrides the fields of [genesis.Default] (using the JSON names
+	// of [genesis.Genesis]), including fee parameters and rule activations.
+	Genesis map[string]any `yaml:"genesis"`
+}
+
+// DescriptorKey is a key generated (and stored) by "chain create" and
+// allocated [Balance] in genesis.
+type DescriptorKey struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"` // ed25519, secp256r1, or bls
+	Balance uint64 `yaml:"balance"`
+}
+
+// DescriptorAllocation allocates [Balance] to an existing [Address] in
+// genesis.
+type DescriptorAllocation struct {
+	Address string `yaml:"address"`
+	Balance uint64 `yaml:"balance"`
+}
+
+// ParseChainDescriptor parses a YAML [ChainDescriptor] (rejecting unknown
+// fields so that typos aren't silently ignored).
+func ParseChainDescriptor(b []byte) (*ChainDescriptor, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	d := &ChainDescriptor{}
+	if err := dec.Decode(d); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDescriptor, err)
+	}
+	return d, nil
+}
+
+// Verify checks the fields of [d] that are not part of genesis.
+func (d *ChainDescriptor) Verify() error {
+	errs := []error{}
+	if len(d.Name) == 0 {
+		errs = append(errs, fmt.Errorf("%w: name is empty", ErrInvalidDescriptor))
+	}
+	if strings.ContainsAny(d.Name, `/\`) {
+		errs = append(errs, fmt.Errorf("%w: name %q must not contain path separators", ErrInvalidDescriptor, d.Name))
+	}
+	if err := checkProfile(d.Profile); err != nil {
+		errs = append(errs, err)
+	}
+	if d.Validators <= 0 || d.Validators > maxDescriptorValidators {
+		errs = append(errs, fmt.Errorf("%w: validators must be between 1 and %d (got %d)", ErrInvalidDescriptor, maxDescriptorValidators, d.Validators))
+	}
+	names := make(map[string]struct{}, len(d.Keys))
+	for i, k := range d.Keys {
+		if len(k.Name) == 0 || strings.ContainsAny(k.Name, `/\`) {
+			errs = append(errs, fmt.Errorf("%w: keys[%d] must have a name without path separators", ErrInvalidDescriptor, i))
+		}
+		if _, ok := names[k.Name]; ok {
+			errs = append(errs, fmt.Errorf("%w: keys[%d] reuses the name %q", ErrInvalidDescriptor, i, k.Name))
+		}
+		names[k.Name] = struct{}{}
+		if err := checkKeyType(k.Type); err != nil {
+			errs = append(errs, fmt.Errorf("%w: keys[%d] (use ed25519, secp256r1, or bls)", err, i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BuildGenesis creates the genesis of [d], allocating to the addresses of
+// [keys] (generated from [d.Keys]), and verifies it.
+func (d *ChainDescriptor) BuildGenesis(keys []*cli.PrivateKey) (*genesis.Genesis, error) {
+	g := genesis.Default()
+	if len(d.Genesis) > 0 {
+		b, err := json.Marshal(d.Genesis)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDescriptor, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(g); err != nil {
+			return nil, fmt.Errorf("%w: genesis: %w", ErrInvalidDescriptor, err)
+		}
+	}
+	g.CustomAllocation = make([]*genesis.CustomAllocation, 0, len(d.Keys)+len(d.Allocations))
+	for i, k := range d.Keys {
+		if k.Balance == 0 {
+			continue
+		}
+		g.CustomAllocation = append(g.CustomAllocation, &genesis.CustomAllocation{
+			Address: consts.FormatAddress(keys[i].Address),
+			Balance: k.Balance,
+		})
+	}
+	for _, a := range d.Allocations {
+		g.CustomAllocation = append(g.CustomAllocation, &genesis.CustomAllocation{
+			Address: a.Address,
+			Balance: a.Balance,
+		})
+	}
+
+	errs := []error{}
+	if err := g.Verify(); err != nil {
+		errs = append(errs, err)
+	}
+	if d.MaxSupply > 0 {
+		var supply uint64
+		for _, a := range g.CustomAllocation {
+			var err error
+			supply, err = smath.Add64(supply, a.Balance)
+			if err != nil {
+				supply = math.MaxUint64
+				break
+			}
+		}
+		if supply > d.MaxSupply {
+			errs = append(errs, fmt.Errorf(
+				"%w: allocations total %s %s but maxSupply is %s %s (lower balances or raise maxSupply)",
+				genesis.ErrInvalidAllocation,
+				utils.FormatBalance(supply, consts.Decimals),
+				consts.Symbol,
+				utils.FormatBalance(d.MaxSupply, consts.Decimals),
+				consts.Symbol,
+			))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func checkProfile(profile string) error {
+	for _, p := range profiles {
+		if profile == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q (use %s)", ErrInvalidProfile, profile, strings.Join(profiles, ", "))
+}
+
+// profileConfig returns the VM config of a node running [profile].
+func profileConfig(profile string) (*config.Config, error) {
+	if err := checkProfile(profile); err != nil {
+		return nil, err
+	}
+	c, err := config.New(ids.EmptyNodeID, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch profile {
+	case validatorProfile:
+		// Validators only need the state required to build and verify blocks
+		c.StoreTransactions = false
+	case rpcProfile:
+		c.StoreTransactions = true
+		c.StoreTxReceipts = true
+		c.StreamingBacklogSize = 10 * c.StreamingBacklogSize
+	case archiveProfile:
+		c.StoreTransactions = true
+		c.StoreTxsByAddress = true
+		c.StoreTxReceipts = true
+	}
+	return c, nil
+}
+
+func promptChainDescriptor() (*ChainDescriptor, error) {
+	name, err := handler.Root().PromptString("name", 1, 64)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range profiles {
+		utils.Outf("%d) {{cyan}}profile:{{/}} %s\n", i, p)
+	}
+	profile, err := handler.Root().PromptChoice("profile", len(profiles))
+	if err != nil {
+		return nil, err
+	}
+	validators, err := handler.Root().PromptInt("validators", maxDescriptorValidators)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := handler.Root().PromptAmount(
+		fmt.Sprintf("balance of new %s key (%s)", ed25519Key, consts.Symbol),
+		consts.Decimals,
+		math.MaxUint64,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainDescriptor{
+		Name:       name,
+		Profile:    profiles[profile],
+		Validators: validators,
+		Keys: []*DescriptorKey{
+			{Name: "admin", Type: ed25519Key, Balance: balance},
+		},
+	}, nil
+}
+
+// createChain writes the genesis, keys, VM config, subnet config, and
+// manifest of [d] to [d.Output].
+//
+// Nothing is written (or stored) unless [d] is valid.
+func createChain(d *ChainDescriptor) (string, error) {
+	if err := d.Verify(); err != nil {
+		return "", err
+	}
+	keys := make([]*cli.PrivateKey, len(d.Keys))
+	for i, k := range d.Keys {
+		priv, err := generatePrivateKey(k.Type)
+		if err != nil {
+			return "", err
+		}
+		keys[i] = priv
+	}
+	g, err := d.BuildGenesis(keys)
+	if err != nil {
+		return "", err
+	}
+	c, err := profileConfig(d.Profile)
+	if err != nil {
+		return "", err
+	}
+
+	output := d.Output
+	if len(output) == 0 {
+		output = d.Name
+	}
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(output); err == nil {
+		return "", fmt.Errorf("%w: %s (remove it or set output)", ErrOutputExists, output)
+	}
+	for _, dir := range []string{output, filepath.Join(output, keysDirName)} {
+		if err := os.MkdirAll(dir, fsModeDir); err != nil {
+			return "", err
+		}
+	}
+
+	// Genesis
+	genesisPath := filepath.Join(output, genesisFileName)
+	if err := writeJSON(genesisPath, g); err != nil {
+		return "", err
+	}
+
+	// Keys
+	var address string
+	for i, priv := range keys {
+		if err := handler.h.StoreKey(priv); err != nil {
+			return "", err
+		}
+		if err := utils.SaveBytes(filepath.Join(output, keysDirName, d.Keys[i].Name+".pk"), priv.Bytes); err != nil {
+			return "", err
+		}
+		if i == 0 {
+			address = consts.FormatAddress(priv.Address)
+		}
+	}
+	if len(keys) > 0 {
+		if err := handler.h.StoreDefaultKey(keys[0].Address); err != nil {
+			return "", err
+		}
+	}
+
+	// Validator signer keys (the staking certificate of each validator is
+	// generated by avalanchego on first start)
+	validatorsDir := filepath.Join(output, validatorsDirName)
+	for i := 0; i < d.Validators; i++ {
+		dir := filepath.Join(validatorsDir, fmt.Sprintf("%d", i))
+		if err := os.MkdirAll(dir, fsModeDir); err != nil {
+			return "", err
+		}
+		sk, err := bls.GeneratePrivateKey()
+		if err != nil {
+			return "", err
+		}
+		if err := utils.SaveBytes(filepath.Join(dir, signerKeyFileName), bls.PrivateKeyToBytes(sk)); err != nil {
+			return "", err
+		}
+	}
+
+	// Configs
+	vmConfigPath := filepath.Join(output, vmConfigFileName)
+	if err := writeJSON(vmConfigPath, c); err != nil {
+		return "", err
+	}
+	subnetConfigPath := filepath.Join(output, subnetConfigFileName)
+	if err := writeJSON(subnetConfigPath, map[string]any{
+		"proposerMinBlockDelay":       0,
+		"proposerNumHistoricalBlocks": 50_000,
+	}); err != nil {
+		return "", err
+	}
+
+	// Manifest (sourced by scripts/run.sh when MANIFEST is set)
+	manifest := []string{
+		"CHAIN_NAME=" + d.Name,
+		"PROFILE=" + d.Profile,
+		"GENESIS_PATH=" + genesisPath,
+		"VM_CONFIG_PATH=" + vmConfigPath,
+		"SUBNET_CONFIG_PATH=" + subnetConfigPath,
+		"VALIDATORS_DIR=" + validatorsDir,
+		fmt.Sprintf("NUM_VALIDATORS=%d", d.Validators),
+	}
+	if len(address) > 0 {
+		manifest = append(manifest, "ADDRESS="+address)
+	}
+	manifestPath := filepath.Join(output, manifestFileName)
+	if err := os.WriteFile(manifestPath, []byte(strings.Join(manifest, "\n")+"\n"), fsModeWrite); err != nil {
+		return "", err
+	}
+	return manifestPath, nil
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, fsModeWrite)
+}
+
+var createChainCmd = &cobra.Command{
+	Use:   "create [descriptor file]",
+	Short: "Scaffolds the genesis, keys, and configs of a new chain (prompts if no descriptor is provided)",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return ErrInvalidArgs
+		}
+		return nil
+	},
+	RunE: func(_ *cobra.Command, args []string) error {
+		var (
+			d   *ChainDescriptor
+			err error
+		)
+		if len(args) == 1 {
+			b, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			d, err = ParseChainDescriptor(b)
+			if err != nil {
+				return err
+			}
+		} else {
+			d, err = promptChainDescriptor()
+			if err != nil {
+				return err
+			}
+		}
+		manifestPath, err := createChain(d)
+		if err != nil {
+			return err
+		}
+		color.Green("created chain %s and saved manifest to %s", d.Name, manifestPath)
+		utils.Outf("{{yellow}}run:{{/}} MANIFEST=%s ./scripts/run.sh\n", manifestPath)
+		return nil
+	},
+}
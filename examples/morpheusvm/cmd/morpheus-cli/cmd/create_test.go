@@ -0,0 +1,109 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/cli"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+const testDescriptor = `
+name: testnet
+profile: rpc
+validators: 3
+maxSupply: 1000
+keys:
+  - name: admin
+    type: ed25519
+    balance: 600
+  - name: ops
+    type: secp256r1
+genesis:
+  minBlockGap: 250
+  epochLength: 100
+  minUnitPrice: [1, 2, 3, 4, 5]
+`
+
+func TestChainDescriptor(t *testing.T) {
+	require := require.New(t)
+
+	d, err := ParseChainDescriptor([]byte(testDescriptor))
+	require.NoError(err)
+	require.NoError(d.Verify())
+
+	keys := make([]*cli.PrivateKey, len(d.Keys))
+	for i, k := range d.Keys {
+		keys[i], err = generatePrivateKey(k.Type)
+		require.NoError(err)
+	}
+	g, err := d.BuildGenesis(keys)
+	require.NoError(err)
+	require.Equal(int64(250), g.MinBlockGap)
+	require.Equal(uint64(100), g.EpochLength)
+	require.Equal(fees.Dimensions{1, 2, 3, 4, 5}, g.MinUnitPrice)
+	require.Equal(genesis.Default().MaxBlockUnits, g.MaxBlockUnits)
+
+	// Keys without a balance are not allocated
+	require.Equal([]*genesis.CustomAllocation{
+		{Address: consts.FormatAddress(keys[0].Address), Balance: 600},
+	}, g.CustomAllocation)
+
+	// Allocations are checked against the max supply
+	d.Allocations = []*DescriptorAllocation{
+		{Address: consts.FormatAddress(keys[1].Address), Balance: 401},
+	}
+	_, err = d.BuildGenesis(keys)
+	require.ErrorIs(err, genesis.ErrInvalidAllocation)
+
+	// Unknown genesis fields are rejected
+	d.Allocations = nil
+	d.Genesis["minBlockGapMs"] = 100
+	_, err = d.BuildGenesis(keys)
+	require.ErrorIs(err, ErrInvalidDescriptor)
+}
+
+func TestChainDescriptorVerify(t *testing.T) {
+	require := require.New(t)
+
+	// Unknown fields are rejected
+	_, err := ParseChainDescriptor([]byte("name: testnet\nvalidator: 1\n"))
+	require.ErrorIs(err, ErrInvalidDescriptor)
+
+	// Every invalid field is reported
+	d := &ChainDescriptor{
+		Name:    "test/net",
+		Profile: "full",
+		Keys: []*DescriptorKey{
+			{Name: "admin", Type: ed25519Key},
+			{Name: "admin", Type: "rsa"},
+		},
+	}
+	err = d.Verify()
+	require.ErrorIs(err, ErrInvalidDescriptor)
+	require.ErrorIs(err, ErrInvalidProfile)
+	require.ErrorIs(err, ErrInvalidKeyType)
+}
+
+func TestProfileConfig(t *testing.T) {
+	require := require.New(t)
+
+	validator, err := profileConfig(validatorProfile)
+	require.NoError(err)
+	require.False(validator.StoreTransactions)
+
+	archive, err := profileConfig(archiveProfile)
+	require.NoError(err)
+	require.True(archive.StoreTransactions)
+	require.True(archive.StoreTxsByAddress)
+	require.True(archive.StoreTxReceipts)
+
+	_, err = profileConfig("full")
+	require.ErrorIs(err, ErrInvalidProfile)
+}
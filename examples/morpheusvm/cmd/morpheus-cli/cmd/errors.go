@@ -10,4 +10,7 @@ var (
 	ErrMissingSubcommand = errors.New("must specify a subcommand")
 	ErrInvalidAddress    = errors.New("invalid address")
 	ErrInvalidKeyType    = errors.New("invalid key type")
+	ErrInvalidDescriptor = errors.New("invalid chain descriptor")
+	ErrInvalidProfile    = errors.New("invalid profile")
+	ErrOutputExists      = errors.New("output already exists")
 )
@@ -0,0 +1,69 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/challenge"
+	"github.com/ava-labs/hypersdk/utils"
+
+	frpc "github.com/ava-labs/hypersdk/examples/morpheusvm/cmd/morpheus-faucet/rpc"
+	lconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+var faucetCmd = &cobra.Command{
+	Use: "faucet",
+	RunE: func(*cobra.Command, []string) error {
+		return ErrMissingSubcommand
+	},
+}
+
+var requestFaucetCmd = &cobra.Command{
+	Use: "request",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+
+		// Get private key
+		_, priv, _, _, _, _, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Get faucet
+		faucetURI, err := handler.Root().PromptString("faucet URI", 0, consts.MaxInt)
+		if err != nil {
+			return err
+		}
+		fcli := frpc.NewJSONRPCClient(faucetURI)
+		faucet, err := fcli.FaucetAddress(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Search for a solution (if required)
+		salt, difficulty, err := fcli.Challenge(ctx)
+		if err != nil {
+			return err
+		}
+		var solution []byte
+		if difficulty > 0 {
+			utils.Outf("{{yellow}}searching for faucet solutions (difficulty=%d, faucet=%s):{{/}} %x\n", difficulty, faucet, salt)
+			start := time.Now()
+			var attempts uint64
+			solution, attempts = challenge.Search(salt, difficulty, numCores)
+			utils.Outf("{{cyan}}found solution (attempts=%d, t=%s):{{/}} %x\n", attempts, time.Since(start), solution)
+		}
+		txID, amount, err := fcli.Request(ctx, lconsts.FormatAddress(priv.Address), salt, solution)
+		if err != nil {
+			return err
+		}
+		utils.Outf("{{green}}faucet funds incoming (%s %s):{{/}} %s\n", utils.FormatBalance(amount, lconsts.Decimals), lconsts.Symbol, txID)
+		return nil
+	},
+}
@@ -66,6 +66,9 @@ var genGenesisCmd = &cobra.Command{
 			return err
 		}
 		g.CustomAllocation = allocs
+		if err := g.Verify(); err != nil {
+			return err
+		}
 
 		b, err := json.Marshal(g)
 		if err != nil {
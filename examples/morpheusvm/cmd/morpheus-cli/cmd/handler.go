@@ -90,7 +90,7 @@ func (*Handler) GetBalance(
 	cli *brpc.JSONRPCClient,
 	addr codec.Address,
 ) (uint64, error) {
-	saddr, err := codec.AddressBech32(consts.HRP, addr)
+	saddr, err := consts.AddressCodec.FormatAddress(addr)
 	if err != nil {
 		return 0, err
 	}
@@ -133,9 +133,9 @@ func (*Controller) Decimals() uint8 {
 }
 
 func (*Controller) Address(addr codec.Address) string {
-	return codec.MustAddressBech32(consts.HRP, addr)
+	return consts.FormatAddress(addr)
 }
 
 func (*Controller) ParseAddress(addr string) (codec.Address, error) {
-	return codec.ParseAddressBech32(consts.HRP, addr)
+	return consts.ParseAddress(addr)
 }
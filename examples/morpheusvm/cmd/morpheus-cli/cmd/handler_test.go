@@ -0,0 +1,35 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+func TestControllerParseAddress(t *testing.T) {
+	require := require.New(t)
+	c := NewController("")
+
+	addr := codec.CreateAddress(0, ids.GenerateTestID())
+	parsed, err := c.ParseAddress(c.Address(addr))
+	require.NoError(err)
+	require.Equal(addr, parsed)
+
+	// Pasting an address from another chain fails with the expected HRP
+	other := codec.MustAddressBech32m("token", addr)
+	_, err = c.ParseAddress(other)
+	require.ErrorIs(err, codec.ErrIncorrectHRP)
+	require.ErrorContains(err, "expected="+consts.HRP)
+
+	// Pasting a Bech32 (not Bech32m) address fails
+	legacy := codec.MustAddressBech32(consts.HRP, addr)
+	_, err = c.ParseAddress(legacy)
+	require.ErrorIs(err, codec.ErrIncorrectEncoding)
+}
@@ -153,7 +153,7 @@ var genKeyCmd = &cobra.Command{
 		}
 		utils.Outf(
 			"{{green}}created address:{{/}} %s",
-			codec.MustAddressBech32(consts.HRP, priv.Address),
+			consts.FormatAddress(priv.Address),
 		)
 		return nil
 	},
@@ -180,7 +180,7 @@ var importKeyCmd = &cobra.Command{
 		}
 		utils.Outf(
 			"{{green}}imported address:{{/}} %s",
-			codec.MustAddressBech32(consts.HRP, priv.Address),
+			consts.FormatAddress(priv.Address),
 		)
 		return nil
 	},
@@ -193,7 +193,7 @@ func lookupSetKeyBalance(choice int, address string, uri string, networkID uint3
 	if err != nil {
 		return err
 	}
-	addr, err := codec.ParseAddressBech32(consts.HRP, address)
+	addr, err := consts.ParseAddress(address)
 	if err != nil {
 		return err
 	}
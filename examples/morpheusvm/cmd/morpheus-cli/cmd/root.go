@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/hypersdk/cli"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/version"
 	"github.com/ava-labs/hypersdk/utils"
 )
 
@@ -38,6 +39,8 @@ var (
 	prometheusData        string
 	startPrometheus       bool
 	maxFee                int64
+	numCores              int
+	specVersion           string
 
 	rootCmd = &cobra.Command{
 		Use:        "morpheus-cli",
@@ -55,6 +58,8 @@ func init() {
 		actionCmd,
 		spamCmd,
 		prometheusCmd,
+		faucetCmd,
+		specCmd,
 	)
 	rootCmd.PersistentFlags().StringVar(
 		&dbPath,
@@ -140,6 +145,8 @@ func init() {
 		setChainCmd,
 		chainInfoCmd,
 		watchChainCmd,
+		compareChainCmd,
+		createChainCmd,
 	)
 
 	// actions
@@ -170,6 +177,17 @@ func init() {
 		runSpamCmd,
 	)
 
+	// faucet
+	requestFaucetCmd.PersistentFlags().IntVar(
+		&numCores,
+		"num-cores",
+		4,
+		"number of cores to use when searching for faucet solutions",
+	)
+	faucetCmd.AddCommand(
+		requestFaucetCmd,
+	)
+
 	// prometheus
 	generatePrometheusCmd.PersistentFlags().StringVar(
 		&prometheusBaseURI,
@@ -204,6 +222,17 @@ func init() {
 	prometheusCmd.AddCommand(
 		generatePrometheusCmd,
 	)
+
+	// spec
+	openRPCSpecCmd.PersistentFlags().StringVar(
+		&specVersion,
+		"version",
+		version.Version.String(),
+		"version of the documents",
+	)
+	specCmd.AddCommand(
+		openRPCSpecCmd,
+	)
 }
 
 func Execute() error {
@@ -0,0 +1,58 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	"github.com/ava-labs/hypersdk/rpc"
+
+	brpc "github.com/ava-labs/hypersdk/examples/morpheusvm/rpc"
+)
+
+var specCmd = &cobra.Command{
+	Use: "spec",
+	RunE: func(*cobra.Command, []string) error {
+		return ErrMissingSubcommand
+	},
+}
+
+var openRPCSpecCmd = &cobra.Command{
+	Use:   "openrpc [output directory]",
+	Short: "Writes the OpenRPC documents of the JSON-RPC endpoints to a directory",
+	PreRunE: func(_ *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ErrInvalidArgs
+		}
+		return nil
+	},
+	RunE: func(_ *cobra.Command, args []string) error {
+		services := map[string]any{
+			rpc.Name:    &rpc.JSONRPCServer{},
+			consts.Name: &brpc.JSONRPCServer{},
+		}
+		for name, service := range services {
+			doc, err := rpc.NewOpenRPCDocument(name, specVersion, service)
+			if err != nil {
+				return err
+			}
+			b, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(args[0], name+".openrpc.json")
+			if err := os.WriteFile(path, append(b, '\n'), fsModeWrite); err != nil {
+				return err
+			}
+			color.Green("saved %s OpenRPC document to %s", name, path)
+		}
+		return nil
+	},
+}
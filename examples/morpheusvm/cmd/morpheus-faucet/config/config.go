@@ -0,0 +1,55 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/crypto/ed25519"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/auth"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+type Config struct {
+	HTTPHost string `json:"host"`
+	HTTPPort int    `json:"port"`
+
+	PrivateKeyBytes []byte `json:"privateKeyBytes"`
+
+	MorpheusRPC string `json:"morpheusRPC"`
+	Amount      uint64 `json:"amount"`
+
+	// Cooldowns are persisted in [StorePath] so that they survive restarts.
+	StorePath       string `json:"storePath"`
+	AddressCooldown int64  `json:"addressCooldown"` // seconds
+	IPCooldown      int64  `json:"ipCooldown"`      // seconds
+
+	// Requests must include a proof-of-work solution if [StartDifficulty] is
+	// greater than 0.
+	StartDifficulty       uint16 `json:"startDifficulty"`
+	SolutionsPerSalt      int    `json:"solutionsPerSalt"`
+	TargetDurationPerSalt int64  `json:"targetDurationPerSalt"` // seconds
+
+	// If a transfer is rejected, it is rebuilt with re-estimated fees (plus
+	// [FeeBumpPercent] for each attempt) up to [MaxSubmitAttempts] times.
+	MaxSubmitAttempts int    `json:"maxSubmitAttempts"`
+	FeeBumpPercent    uint64 `json:"feeBumpPercent"`
+}
+
+func (c *Config) PrivateKey() ed25519.PrivateKey {
+	return ed25519.PrivateKey(c.PrivateKeyBytes)
+}
+
+func (c *Config) Address() codec.Address {
+	return auth.NewED25519Address(c.PrivateKey().PublicKey())
+}
+
+func (c *Config) AddressBech32() string {
+	return consts.FormatAddress(c.Address())
+}
+
+// RequiresSolution returns true if requests must include a proof-of-work
+// solution.
+func (c *Config) RequiresSolution() bool {
+	return c.StartDifficulty > 0
+}
@@ -0,0 +1,16 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package manager
+
+import "errors"
+
+var (
+	ErrAddressCooldown     = errors.New("address requested funds too recently")
+	ErrIPCooldown          = errors.New("ip requested funds too recently")
+	ErrSaltExpired         = errors.New("salt expired")
+	ErrInvalidSolution     = errors.New("invalid solution")
+	ErrDuplicateSolution   = errors.New("duplicate solution")
+	ErrFeeTooHigh          = errors.New("network fee too high")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+)
@@ -0,0 +1,348 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/timer"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/actions"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/auth"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/challenge"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/cmd/morpheus-faucet/config"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/pebble"
+	"github.com/ava-labs/hypersdk/requester"
+	"github.com/ava-labs/hypersdk/rpc"
+	"github.com/ava-labs/hypersdk/utils"
+
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	lrpc "github.com/ava-labs/hypersdk/examples/morpheusvm/rpc"
+)
+
+// storeCacheSize is the cache size of the cooldown store (which only holds a
+// small record per address and IP).
+const storeCacheSize = 8 * 1024 * 1024
+
+type Manager struct {
+	log    logging.Logger
+	config *config.Config
+
+	cli  *rpc.JSONRPCClient
+	lcli *lrpc.JSONRPCClient
+
+	factory *auth.ED25519Factory
+
+	db       database.Database
+	store    *cooldownStore
+	registry *prometheus.Registry
+	metrics  *metrics
+
+	l            sync.Mutex
+	t            *timer.Timer
+	lastRotation int64
+	salt         []byte
+	difficulty   uint16
+	solutions    set.Set[ids.ID]
+}
+
+func New(logger logging.Logger, config *config.Config) (*Manager, error) {
+	ctx := context.TODO()
+	cli := rpc.NewJSONRPCClient(config.MorpheusRPC)
+	networkID, _, chainID, err := cli.Network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lcli := lrpc.NewJSONRPCClient(config.MorpheusRPC, networkID, chainID)
+	registry, metrics, err := newMetrics()
+	if err != nil {
+		return nil, err
+	}
+	storeConfig := pebble.NewDefaultConfig()
+	storeConfig.CacheSize = storeCacheSize
+	db, _, err := pebble.New(config.StorePath, storeConfig)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		log:      logger,
+		config:   config,
+		cli:      cli,
+		lcli:     lcli,
+		factory:  auth.NewED25519Factory(config.PrivateKey()),
+		db:       db,
+		store:    &cooldownStore{db},
+		registry: registry,
+		metrics:  metrics,
+	}
+	m.lastRotation = time.Now().Unix()
+	m.difficulty = m.config.StartDifficulty
+	m.metrics.difficulty.Set(float64(m.difficulty))
+	m.solutions = set.NewSet[ids.ID](m.config.SolutionsPerSalt)
+	m.salt, err = challenge.New()
+	if err != nil {
+		return nil, err
+	}
+	bal, err := lcli.Balance(ctx, m.config.AddressBech32())
+	if err != nil {
+		return nil, err
+	}
+	m.log.Info("faucet initialized",
+		zap.String("address", m.config.AddressBech32()),
+		zap.Uint16("difficulty", m.difficulty),
+		zap.String("balance", utils.FormatBalance(bal, consts.Decimals)),
+	)
+	m.t = timer.NewTimer(m.updateDifficulty)
+	return m, nil
+}
+
+// Registry returns the metrics of the faucet.
+func (m *Manager) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Run adjusts the proof-of-work difficulty (if required) until [ctx] is done.
+func (m *Manager) Run(ctx context.Context) error {
+	if m.config.RequiresSolution() {
+		m.t.SetTimeoutIn(time.Duration(m.config.TargetDurationPerSalt) * time.Second)
+		go m.t.Dispatch()
+	}
+	<-ctx.Done()
+	m.t.Stop()
+	return ctx.Err()
+}
+
+// Close closes the cooldown store.
+func (m *Manager) Close() error {
+	return m.db.Close()
+}
+
+func (m *Manager) updateDifficulty() {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	// If time since [lastRotation] is within half of the target duration,
+	// we attempted to update difficulty when we just reset during request processing.
+	now := time.Now().Unix()
+	if now-m.lastRotation < m.config.TargetDurationPerSalt/2 {
+		return
+	}
+
+	// Decrease difficulty if there are no solutions in this period
+	if m.difficulty > m.config.StartDifficulty && m.solutions.Len() == 0 {
+		m.difficulty--
+		m.metrics.difficulty.Set(float64(m.difficulty))
+		m.log.Info("decreasing faucet difficulty", zap.Uint16("new difficulty", m.difficulty))
+	}
+	if err := m.rotateSalt(); err != nil {
+		panic(err)
+	}
+}
+
+// rotateSalt must be called while holding [m.l].
+func (m *Manager) rotateSalt() error {
+	salt, err := challenge.New()
+	if err != nil {
+		return err
+	}
+	m.lastRotation = time.Now().Unix()
+	m.salt = salt
+	m.solutions.Clear()
+	m.t.Cancel()
+	m.t.SetTimeoutIn(time.Duration(m.config.TargetDurationPerSalt) * time.Second)
+	return nil
+}
+
+func (m *Manager) GetFaucetAddress(_ context.Context) (codec.Address, error) {
+	return m.config.Address(), nil
+}
+
+// GetChallenge returns the salt and difficulty that solutions must be found
+// for (a difficulty of 0 means no solution is required).
+func (m *Manager) GetChallenge(_ context.Context) ([]byte, uint16, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	return m.salt, m.difficulty, nil
+}
+
+func (m *Manager) checkCooldown(key []byte, cooldown int64, now int64, reason error) error {
+	last, err := m.store.last(key)
+	if err != nil {
+		return err
+	}
+	if last == 0 {
+		return nil
+	}
+	if remaining := last + cooldown*hconsts.MillisecondsPerSecond - now; remaining > 0 {
+		m.metrics.cooldowns.Inc()
+		return fmt.Errorf("%w: retry in %s", reason, time.Duration(remaining)*time.Millisecond)
+	}
+	return nil
+}
+
+func (m *Manager) checkSolution(salt []byte, solution []byte) (ids.ID, error) {
+	if !bytes.Equal(m.salt, salt) {
+		m.metrics.badSolutions.Inc()
+		return ids.Empty, ErrSaltExpired
+	}
+	if !challenge.Verify(salt, solution, m.difficulty) {
+		m.metrics.badSolutions.Inc()
+		return ids.Empty, ErrInvalidSolution
+	}
+	solutionID := utils.ToID(solution)
+	if m.solutions.Contains(solutionID) {
+		m.metrics.badSolutions.Inc()
+		return ids.Empty, ErrDuplicateSolution
+	}
+	return solutionID, nil
+}
+
+// estimateFee returns the max fee of [txActions] at the current unit prices,
+// increased by [config.FeeBumpPercent] for each previously rejected [attempt].
+func (m *Manager) estimateFee(ctx context.Context, parser chain.Parser, txActions []chain.Action, attempt int) (uint64, error) {
+	unitPrices, err := m.cli.UnitPrices(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	units, err := chain.EstimateUnits(parser.Rules(time.Now().UnixMilli()), txActions, m.factory)
+	if err != nil {
+		return 0, err
+	}
+	maxFee, err := fees.MulSum(unitPrices, units)
+	if err != nil {
+		return 0, err
+	}
+	bump, err := math.Mul64(maxFee, m.config.FeeBumpPercent*uint64(attempt))
+	if err != nil {
+		return 0, err
+	}
+	return math.Add64(maxFee, bump/100)
+}
+
+func (m *Manager) sendFunds(ctx context.Context, destination codec.Address, amount uint64) (ids.ID, uint64, error) {
+	parser, err := m.lcli.Parser(ctx)
+	if err != nil {
+		return ids.Empty, 0, err
+	}
+	transfer := []chain.Action{&actions.Transfer{
+		To:    destination,
+		Value: amount,
+	}}
+	for attempt := 0; ; attempt++ {
+		maxFee, err := m.estimateFee(ctx, parser, transfer, attempt)
+		if err != nil {
+			return ids.Empty, 0, err
+		}
+		if amount < maxFee {
+			m.log.Warn("abandoning airdrop because network fee is greater than amount", zap.String("maxFee", utils.FormatBalance(maxFee, consts.Decimals)))
+			return ids.Empty, 0, ErrFeeTooHigh
+		}
+		bal, err := m.lcli.Balance(ctx, m.config.AddressBech32())
+		if err != nil {
+			return ids.Empty, 0, err
+		}
+		if bal < maxFee+amount {
+			// This is a "best guess" heuristic for balance as there may be txs in-flight.
+			m.log.Warn("faucet has insufficient funds", zap.String("balance", utils.FormatBalance(bal, consts.Decimals)))
+			return ids.Empty, 0, ErrInsufficientBalance
+		}
+		submit, tx, err := m.cli.GenerateTransactionManual(parser, transfer, m.factory, maxFee)
+		if err != nil {
+			return ids.Empty, 0, err
+		}
+		err = submit(ctx)
+		if err == nil {
+			return tx.ID(), maxFee, nil
+		}
+
+		// Only retry if the transaction was rejected by the node (it may
+		// otherwise have been accepted)
+		if !requester.IsRejected(err) || attempt+1 >= m.config.MaxSubmitAttempts {
+			return ids.Empty, 0, err
+		}
+		m.metrics.submitRetries.Inc()
+		m.log.Warn("transfer rejected, re-estimating fee",
+			zap.Int("attempt", attempt+1),
+			zap.String("maxFee", utils.FormatBalance(maxFee, consts.Decimals)),
+			zap.Error(err),
+		)
+	}
+}
+
+// Request sends [config.Amount] to [destination] if neither it nor [ip] were
+// sent funds within their cooldowns and [solution] solves the current
+// challenge (if required).
+func (m *Manager) Request(ctx context.Context, ip string, destination codec.Address, salt []byte, solution []byte) (ids.ID, uint64, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	// Ensure request is allowed
+	now := time.Now().UnixMilli()
+	if err := m.checkCooldown(addressKey(destination), m.config.AddressCooldown, now, ErrAddressCooldown); err != nil {
+		return ids.Empty, 0, err
+	}
+	if err := m.checkCooldown(ipKey(ip), m.config.IPCooldown, now, ErrIPCooldown); err != nil {
+		return ids.Empty, 0, err
+	}
+	var solutionID ids.ID
+	if m.config.RequiresSolution() {
+		var err error
+		solutionID, err = m.checkSolution(salt, solution)
+		if err != nil {
+			return ids.Empty, 0, err
+		}
+	}
+
+	// Issue transaction
+	txID, maxFee, err := m.sendFunds(ctx, destination, m.config.Amount)
+	if err != nil {
+		m.metrics.failures.Inc()
+		return ids.Empty, 0, err
+	}
+	if err := m.store.record(destination, ip, now); err != nil {
+		// The funds have already been sent, so we only log the failure
+		m.log.Error("unable to record cooldown", zap.Error(err))
+	}
+	m.metrics.drips.Inc()
+	m.metrics.dripped.Add(float64(m.config.Amount))
+	m.log.Info("fauceted funds",
+		zap.Stringer("txID", txID),
+		zap.String("max fee", utils.FormatBalance(maxFee, consts.Decimals)),
+		zap.String("destination", consts.FormatAddress(destination)),
+		zap.String("ip", ip),
+		zap.String("amount", utils.FormatBalance(m.config.Amount, consts.Decimals)),
+	)
+	if !m.config.RequiresSolution() {
+		return txID, m.config.Amount, nil
+	}
+	m.solutions.Add(solutionID)
+
+	// Roll salt if hit expected solutions
+	if m.solutions.Len() >= m.config.SolutionsPerSalt {
+		m.difficulty++
+		m.metrics.difficulty.Set(float64(m.difficulty))
+		m.log.Info("increasing faucet difficulty", zap.Uint16("new difficulty", m.difficulty))
+		if err := m.rotateSalt(); err != nil {
+			// Should never happen
+			return ids.Empty, 0, err
+		}
+	}
+	return txID, m.config.Amount, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package manager
+
+import (
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	drips         prometheus.Counter
+	dripped       prometheus.Counter
+	cooldowns     prometheus.Counter
+	badSolutions  prometheus.Counter
+	failures      prometheus.Counter
+	submitRetries prometheus.Counter
+	difficulty    prometheus.Gauge
+}
+
+func newMetrics() (*prometheus.Registry, *metrics, error) {
+	r := prometheus.NewRegistry()
+	m := &metrics{
+		drips: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "faucet",
+			Name:      "drips",
+			Help:      "number of requests that were sent funds",
+		}),
+		dripped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "faucet",
+			Name:      "dripped",
+			Help:      "amount of funds sent",
+		}),
+		cooldowns: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "faucet",
+			Name:      "cooldowns",
+			Help:      "number of requests rejected because of an address or ip cooldown",
+		}),
+		badSolutions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "faucet",
+			Name:      "bad_solutions",
+			Help:      "number of requests rejected because of an expired, invalid, or duplicate solution",
+		}),
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "faucet",
+			Name:      "failures",
+			Help:      "number of requests that could not be sent funds",
+		}),
+		submitRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "faucet",
+			Name:      "submit_retries",
+			Help:      "number of transfers rebuilt with re-estimated fees after being rejected",
+		}),
+		difficulty: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "faucet",
+			Name:      "difficulty",
+			Help:      "current proof-of-work difficulty",
+		}),
+	}
+	errs := wrappers.Errs{}
+	errs.Add(
+		r.Register(m.drips),
+		r.Register(m.dripped),
+		r.Register(m.cooldowns),
+		r.Register(m.badSolutions),
+		r.Register(m.failures),
+		r.Register(m.submitRetries),
+		r.Register(m.difficulty),
+	)
+	return r, m, errs.Err
+}
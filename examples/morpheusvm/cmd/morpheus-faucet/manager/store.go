@@ -0,0 +1,62 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package manager
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ava-labs/avalanchego/database"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+const (
+	addressPrefix = 0x0
+	ipPrefix      = 0x1
+)
+
+// cooldownStore records the last time (in ms) each address and IP was sent
+// funds.
+type cooldownStore struct {
+	db database.Database
+}
+
+func addressKey(addr codec.Address) []byte {
+	k := make([]byte, 1+codec.AddressLen)
+	k[0] = addressPrefix
+	copy(k[1:], addr[:])
+	return k
+}
+
+func ipKey(ip string) []byte {
+	k := make([]byte, 1+len(ip))
+	k[0] = ipPrefix
+	copy(k[1:], ip)
+	return k
+}
+
+// last returns the last time [key] was sent funds (or 0 if it never was).
+func (s *cooldownStore) last(key []byte) (int64, error) {
+	v, err := s.db.Get(key)
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(v)), nil
+}
+
+func (s *cooldownStore) record(addr codec.Address, ip string, now int64) error {
+	v := binary.BigEndian.AppendUint64(nil, uint64(now))
+	batch := s.db.NewBatch()
+	if err := batch.Put(addressKey(addr), v); err != nil {
+		return err
+	}
+	if err := batch.Put(ipKey(ip), v); err != nil {
+		return err
+	}
+	return batch.Write()
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/requester"
+)
+
+const (
+	JSONRPCEndpoint = "/faucet"
+)
+
+type JSONRPCClient struct {
+	requester *requester.EndpointRequester
+}
+
+// New creates a new client object.
+func NewJSONRPCClient(uri string) *JSONRPCClient {
+	uri = strings.TrimSuffix(uri, "/")
+	uri += JSONRPCEndpoint
+	req := requester.New(uri, "faucet")
+	return &JSONRPCClient{
+		requester: req,
+	}
+}
+
+func (cli *JSONRPCClient) FaucetAddress(ctx context.Context) (string, error) {
+	resp := new(FaucetAddressReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"faucetAddress",
+		nil,
+		resp,
+		requester.Idempotent(),
+	)
+	return resp.Address, err
+}
+
+func (cli *JSONRPCClient) Challenge(ctx context.Context) ([]byte, uint16, error) {
+	resp := new(ChallengeReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"challenge",
+		nil,
+		resp,
+	)
+	return resp.Salt, resp.Difficulty, err
+}
+
+// Request asks the faucet to send funds to [addr]. If the faucet requires a
+// proof-of-work, [solution] must solve the [Challenge] for [salt] (otherwise
+// both may be nil).
+func (cli *JSONRPCClient) Request(ctx context.Context, addr string, salt []byte, solution []byte) (ids.ID, uint64, error) {
+	resp := new(RequestReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"request",
+		&RequestArgs{
+			Address:  addr,
+			Salt:     salt,
+			Solution: solution,
+		},
+		resp,
+	)
+	return resp.TxID, resp.Amount, err
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// sigsidecar verifies the signatures of morpheusvm transactions for nodes
+// that offload signature verification (see authOffloadEndpoints).
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/auth"
+	"github.com/ava-labs/hypersdk/offload"
+)
+
+var (
+	listenAddress string
+	maxBatchSize  int
+	cores         int
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "sigsidecar",
+	Short: "Signature verification sidecar for morpheusvm",
+	RunE:  runFunc,
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&listenAddress, "listen", "127.0.0.1:9652", "address to serve gRPC on")
+	rootCmd.Flags().IntVar(&maxBatchSize, "max-batch-size", 4_096, "largest batch of signatures to verify (0 is unbounded)")
+	rootCmd.Flags().IntVar(&cores, "cores", runtime.NumCPU(), "cores to verify each batch with")
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "sigsidecar failed %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func runFunc(*cobra.Command, []string) error {
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return err
+	}
+	server := offload.NewGRPCServer(offload.NewServer(auth.OffloadVerifiers(), maxBatchSize, cores))
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		server.GracefulStop()
+	}()
+	fmt.Printf("verifying signatures on %s\n", listener.Addr())
+	return server.Serve(listener)
+}
@@ -17,8 +17,11 @@ import (
 	"github.com/ava-labs/hypersdk/config"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/version"
+	"github.com/ava-labs/hypersdk/mempool"
 	"github.com/ava-labs/hypersdk/trace"
 	"github.com/ava-labs/hypersdk/vm"
+
+	hrpc "github.com/ava-labs/hypersdk/rpc"
 )
 
 var _ vm.Config = (*Config)(nil)
@@ -45,6 +48,10 @@ type Config struct {
 	// Profiling
 	ContinuousProfilerDir string `json:"continuousProfilerDir"` // "*" is replaced with rand int
 
+	// Block profiling (enabled with the profileBlocks admin RPC)
+	BlockProfileDir      string `json:"blockProfileDir"` // "*" is replaced with nodeID
+	BlockProfileMaxBytes int64  `json:"blockProfileMaxBytes"`
+
 	// Streaming settings
 	StreamingBacklogSize int `json:"streamingBacklogSize"`
 
@@ -52,16 +59,72 @@ type Config struct {
 	MempoolSize           int      `json:"mempoolSize"`
 	MempoolSponsorSize    int      `json:"mempoolSponsorSize"`
 	MempoolExemptSponsors []string `json:"mempoolExemptSponsors"`
+	MempoolReservations   bool     `json:"mempoolReservations"`
+	MempoolMaxBytes       int      `json:"mempoolMaxBytes"`
+
+	// Mempool eviction (new txs are dropped while the mempool is full if all
+	// weights are 0)
+	MempoolEviction mempool.EvictionConfig `json:"mempoolEviction"`
+
+	// Deferred verification (0 verifies the auth of submitted txs before
+	// responding)
+	DeferredVerificationSize  int `json:"deferredVerificationSize"`
+	DeferredVerificationCores int `json:"deferredVerificationCores"`
 
 	// Misc
 	VerifyAuth        bool          `json:"verifyAuth"`
 	StoreTransactions bool          `json:"storeTransactions"`
+	StoreTxsByAddress bool          `json:"storeTxsByAddress"`
+	StoreTxReceipts   bool          `json:"storeTxReceipts"`
 	TestMode          bool          `json:"testMode"` // makes gossip/building manual
 	LogLevel          logging.Level `json:"logLevel"`
 
+	// Logging
+	LogLevels     map[string]logging.Level `json:"logLevels"`     // per-component overrides of LogLevel
+	LogLevelsFile string                   `json:"logLevelsFile"` // reloaded "logLevels" (can be this file)
+
+	// Admin
+	AdminAPI bool `json:"adminAPI"`
+
+	// RPC namespaces (replaces the config each namespace is registered with)
+	RPCNamespaces map[string]hrpc.NamespaceConfig `json:"rpcNamespaces"`
+
 	// State Sync
 	StateSyncServerDelay time.Duration `json:"stateSyncServerDelay"` // for testing
 
+	// State sync serving (bounds the proofs generated for syncing peers)
+	StateSyncServerConcurrency int           `json:"stateSyncServerConcurrency"`
+	StateSyncServerPeerQuota   int           `json:"stateSyncServerPeerQuota"`
+	StateSyncServerMaxDeferral time.Duration `json:"stateSyncServerMaxDeferral"`
+
+	// Shadow root verification (used to safely upgrade merkledb on a canary node)
+	ShadowRootVerification bool `json:"shadowRootVerification"`
+
+	// Commit blocks to state during verification while bootstrapping
+	CatchUpIncrementalRoots bool `json:"catchUpIncrementalRoots"`
+
+	// Record how many txs access each state key (for finding contended keys)
+	RecordKeyAccesses bool `json:"recordKeyAccesses"`
+
+	// Clock correction (measured from the clocks of peers)
+	MaxClockCorrection time.Duration `json:"maxClockCorrection"`
+	ClockSkewThreshold time.Duration `json:"clockSkewThreshold"`
+
+	// Signature verification offloaded to sidecars (see cmd/sigsidecar)
+	AuthOffloadEndpoints      []string      `json:"authOffloadEndpoints"`
+	AuthOffloadTimeout        time.Duration `json:"authOffloadTimeout"`
+	AuthOffloadHealthInterval time.Duration `json:"authOffloadHealthInterval"`
+	AuthOffloadBatchSize      int           `json:"authOffloadBatchSize"`
+
+	// State usage accounting (see the getStateUsage RPC)
+	StoreStateUsage   bool `json:"storeStateUsage"`
+	RebuildStateUsage bool `json:"rebuildStateUsage"`
+
+	// Rolling checksum of the accepted chain (see the chainChecksum RPC and
+	// "chain compare"), disabled if the interval is 0
+	ChainChecksumInterval  uint64 `json:"chainChecksumInterval"`  // blocks
+	ChainChecksumRetention uint64 `json:"chainChecksumRetention"` // checkpoints
+
 	loaded               bool
 	nodeID               ids.NodeID
 	parsedExemptSponsors []codec.Address
@@ -81,7 +144,7 @@ func New(nodeID ids.NodeID, b []byte) (*Config, error) {
 	// broadcasting many txs at once)
 	c.parsedExemptSponsors = make([]codec.Address, len(c.MempoolExemptSponsors))
 	for i, sponsor := range c.MempoolExemptSponsors {
-		p, err := codec.ParseAddressBech32(consts.HRP, sponsor)
+		p, err := consts.ParseAddress(sponsor)
 		if err != nil {
 			return nil, err
 		}
@@ -98,10 +161,33 @@ func (c *Config) setDefault() {
 	c.StateFetchConcurrency = c.Config.GetStateFetchConcurrency()
 	c.MempoolSize = c.Config.GetMempoolSize()
 	c.MempoolSponsorSize = c.Config.GetMempoolSponsorSize()
+	c.MempoolReservations = c.Config.GetMempoolReservations()
+	c.MempoolMaxBytes = c.Config.GetMempoolMaxBytes()
+	c.MempoolEviction = *c.Config.GetMempoolEviction()
+	c.DeferredVerificationSize = c.Config.GetDeferredVerificationSize()
+	c.DeferredVerificationCores = c.Config.GetDeferredVerificationCores()
 	c.StateSyncServerDelay = c.Config.GetStateSyncServerDelay()
+	c.StateSyncServerConcurrency = c.Config.GetStateSyncServerConcurrency()
+	c.StateSyncServerPeerQuota = c.Config.GetStateSyncServerPeerQuota()
+	c.StateSyncServerMaxDeferral = c.Config.GetStateSyncServerMaxDeferral()
 	c.StreamingBacklogSize = c.Config.GetStreamingBacklogSize()
 	c.VerifyAuth = c.Config.GetVerifyAuth()
 	c.StoreTransactions = defaultStoreTransactions
+	c.StoreTxsByAddress = c.Config.GetStoreTxsByAddress()
+	c.StoreTxReceipts = c.Config.GetStoreTxReceipts()
+	c.LogLevels = c.Config.GetLogLevels()
+	c.LogLevelsFile = c.Config.GetLogLevelsFile()
+	c.AdminAPI = c.Config.GetAdminAPI()
+	c.MaxClockCorrection = c.Config.GetMaxClockCorrection()
+	c.ClockSkewThreshold = c.Config.GetClockSkewThreshold()
+	c.AuthOffloadTimeout = c.Config.GetAuthOffloadTimeout()
+	c.AuthOffloadHealthInterval = c.Config.GetAuthOffloadHealthInterval()
+	c.AuthOffloadBatchSize = c.Config.GetAuthOffloadBatchSize()
+	c.StoreStateUsage = c.Config.GetStoreStateUsage()
+	c.RebuildStateUsage = c.Config.GetRebuildStateUsage()
+	c.BlockProfileMaxBytes = c.Config.GetBlockProfileMaxBytes()
+	c.ChainChecksumInterval = c.Config.GetChainChecksumInterval()
+	c.ChainChecksumRetention = c.Config.GetChainChecksumRetention()
 }
 
 func (c *Config) GetLogLevel() logging.Level                { return c.LogLevel }
@@ -113,6 +199,13 @@ func (c *Config) GetStateFetchConcurrency() int             { return c.StateFetc
 func (c *Config) GetMempoolSize() int                       { return c.MempoolSize }
 func (c *Config) GetMempoolSponsorSize() int                { return c.MempoolSponsorSize }
 func (c *Config) GetMempoolExemptSponsors() []codec.Address { return c.parsedExemptSponsors }
+func (c *Config) GetMempoolReservations() bool              { return c.MempoolReservations }
+func (c *Config) GetMempoolMaxBytes() int                   { return c.MempoolMaxBytes }
+func (c *Config) GetDeferredVerificationSize() int          { return c.DeferredVerificationSize }
+func (c *Config) GetDeferredVerificationCores() int         { return c.DeferredVerificationCores }
+func (c *Config) GetMempoolEviction() *mempool.EvictionConfig {
+	return &c.MempoolEviction
+}
 func (c *Config) GetTraceConfig() *trace.Config {
 	return &trace.Config{
 		Enabled:         c.TraceEnabled,
@@ -122,8 +215,11 @@ func (c *Config) GetTraceConfig() *trace.Config {
 		Version:         version.Version.String(),
 	}
 }
-func (c *Config) GetStateSyncServerDelay() time.Duration { return c.StateSyncServerDelay }
-func (c *Config) GetStreamingBacklogSize() int           { return c.StreamingBacklogSize }
+func (c *Config) GetStateSyncServerDelay() time.Duration       { return c.StateSyncServerDelay }
+func (c *Config) GetStateSyncServerConcurrency() int           { return c.StateSyncServerConcurrency }
+func (c *Config) GetStateSyncServerPeerQuota() int             { return c.StateSyncServerPeerQuota }
+func (c *Config) GetStateSyncServerMaxDeferral() time.Duration { return c.StateSyncServerMaxDeferral }
+func (c *Config) GetStreamingBacklogSize() int                 { return c.StreamingBacklogSize }
 func (c *Config) GetContinuousProfilerConfig() *profiler.Config {
 	if len(c.ContinuousProfilerDir) == 0 {
 		return &profiler.Config{Enabled: false}
@@ -138,6 +234,33 @@ func (c *Config) GetContinuousProfilerConfig() *profiler.Config {
 		MaxNumFiles: defaultContinuousProfilerMaxFiles,
 	}
 }
-func (c *Config) GetVerifyAuth() bool        { return c.VerifyAuth }
-func (c *Config) GetStoreTransactions() bool { return c.StoreTransactions }
-func (c *Config) Loaded() bool               { return c.loaded }
+func (c *Config) GetBlockProfileDir() string {
+	return strings.ReplaceAll(c.BlockProfileDir, "*", c.nodeID.String())
+}
+func (c *Config) GetVerifyAuth() bool                    { return c.VerifyAuth }
+func (c *Config) GetStoreTransactions() bool             { return c.StoreTransactions }
+func (c *Config) GetStoreTxsByAddress() bool             { return c.StoreTxsByAddress }
+func (c *Config) GetStoreTxReceipts() bool               { return c.StoreTxReceipts }
+func (c *Config) GetShadowRootVerification() bool        { return c.ShadowRootVerification }
+func (c *Config) GetCatchUpIncrementalRoots() bool       { return c.CatchUpIncrementalRoots }
+func (c *Config) GetRecordKeyAccesses() bool             { return c.RecordKeyAccesses }
+func (c *Config) GetBlockProfileMaxBytes() int64         { return c.BlockProfileMaxBytes }
+func (c *Config) GetMaxClockCorrection() time.Duration   { return c.MaxClockCorrection }
+func (c *Config) GetClockSkewThreshold() time.Duration   { return c.ClockSkewThreshold }
+func (c *Config) GetLogLevels() map[string]logging.Level { return c.LogLevels }
+func (c *Config) GetLogLevelsFile() string               { return c.LogLevelsFile }
+func (c *Config) GetAdminAPI() bool                      { return c.AdminAPI }
+func (c *Config) Loaded() bool                           { return c.loaded }
+
+func (c *Config) GetRPCNamespaces() map[string]hrpc.NamespaceConfig { return c.RPCNamespaces }
+
+func (c *Config) GetAuthOffloadEndpoints() []string           { return c.AuthOffloadEndpoints }
+func (c *Config) GetAuthOffloadTimeout() time.Duration        { return c.AuthOffloadTimeout }
+func (c *Config) GetAuthOffloadHealthInterval() time.Duration { return c.AuthOffloadHealthInterval }
+func (c *Config) GetAuthOffloadBatchSize() int                { return c.AuthOffloadBatchSize }
+
+func (c *Config) GetStoreStateUsage() bool   { return c.StoreStateUsage }
+func (c *Config) GetRebuildStateUsage() bool { return c.RebuildStateUsage }
+
+func (c *Config) GetChainChecksumInterval() uint64  { return c.ChainChecksumInterval }
+func (c *Config) GetChainChecksumRetention() uint64 { return c.ChainChecksumRetention }
@@ -29,9 +29,29 @@ func init() {
 	ID = vmID
 }
 
+// AddressCodec formats and parses all addresses on the chain (using Bech32m
+// with [HRP]). It should be used everywhere an address is rendered or read
+// so that all tools agree on the encoding.
+var AddressCodec = codec.NewAddressCodec(HRP)
+
+// FormatAddress returns the human-readable encoding of [addr].
+func FormatAddress(addr codec.Address) string {
+	return AddressCodec.MustFormatAddress(addr)
+}
+
+// ParseAddress parses [addr], returning an error naming [HRP] if [addr] belongs
+// to another chain.
+func ParseAddress(addr string) (codec.Address, error) {
+	return AddressCodec.ParseAddress(addr)
+}
+
 // Instantiate registry here so it can be imported by any package. We set these
 // values in [controller/registry].
 var (
 	ActionRegistry *codec.TypeParser[chain.Action, bool]
 	AuthRegistry   *codec.TypeParser[chain.Auth, bool]
+
+	// ActionVectors are the conformance vectors of the actions in
+	// [ActionRegistry].
+	ActionVectors *chain.ActionVectorRegistry
 )
@@ -8,6 +8,24 @@ const (
 	TransferID uint8 = 0
 	BurnId     uint8 = 1
 
+	GrantAccessID  uint8 = 2
+	RevokeAccessID uint8 = 3
+
+	SetMetadataID uint8 = 4
+	EchoID        uint8 = 5
+
+	EscrowID        uint8 = 6
+	ReleaseEscrowID uint8 = 7
+
+	DigestID     uint8 = 8
+	EmitDigestID uint8 = 9
+
+	TransferWithBurnID uint8 = 10
+
+	IterateID uint8 = 11
+
+	PayID uint8 = 12
+
 	// Auth TypeIDs
 	ED25519ID   uint8 = 0
 	SECP256R1ID uint8 = 1
@@ -14,6 +14,7 @@ import (
 
 	"github.com/ava-labs/hypersdk/builder"
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/actions"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/auth"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/config"
@@ -30,7 +31,11 @@ import (
 	hstorage "github.com/ava-labs/hypersdk/storage"
 )
 
-var _ vm.Controller = (*Controller)(nil)
+var (
+	_ vm.Controller          = (*Controller)(nil)
+	_ vm.AddressIndexer      = (*Controller)(nil)
+	_ vm.StatePrefixProvider = (*Controller)(nil)
+)
 
 type Controller struct {
 	inner *vm.VM
@@ -152,7 +157,7 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 	defer batch.Reset()
 
 	results := blk.Results()
-	for i, tx := range blk.Txs {
+	for i, tx := range blk.ExecutedTxs() {
 		result := results[i]
 		if c.config.GetStoreTransactions() {
 			err := storage.StoreTransaction(
@@ -180,6 +185,24 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 	return batch.Write()
 }
 
+func (*Controller) ResultAddresses(tx *chain.Transaction, result *chain.Result) []codec.Address {
+	if !result.Success {
+		return nil
+	}
+	addrs := []codec.Address{}
+	for _, action := range tx.Actions {
+		switch act := action.(type) { //nolint:gocritic
+		case *actions.Transfer:
+			addrs = append(addrs, act.To)
+		}
+	}
+	return addrs
+}
+
+func (*Controller) RegisterStatePrefixes(r *chain.StatePrefixRegistry) error {
+	return storage.RegisterStatePrefixes(r)
+}
+
 func (*Controller) Rejected(context.Context, *chain.StatelessBlock) error {
 	return nil
 }
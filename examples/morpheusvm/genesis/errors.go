@@ -6,6 +6,11 @@ package genesis
 import "errors"
 
 var (
-	ErrInvalidHRP    = errors.New("invalid HRP")
-	ErrInvalidTarget = errors.New("invalid target")
+	ErrInvalidHRP             = errors.New("invalid HRP")
+	ErrInvalidTarget          = errors.New("invalid target")
+	ErrInvalidAllocation      = errors.New("invalid allocation")
+	ErrInvalidFeeParameters   = errors.New("invalid fee parameters")
+	ErrInvalidBlockParameters = errors.New("invalid block parameters")
+	ErrInvalidACLParameters   = errors.New("invalid access control parameters")
+	ErrInvalidPauseParameters = errors.New("invalid pause parameters")
 )
@@ -6,11 +6,13 @@ package genesis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
+	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
@@ -34,19 +36,63 @@ type Genesis struct {
 	StateBranchFactor merkledb.BranchFactor `json:"stateBranchFactor"`
 
 	// Chain Parameters
-	MinBlockGap      int64 `json:"minBlockGap"`      // ms
-	MinEmptyBlockGap int64 `json:"minEmptyBlockGap"` // ms
+	MinBlockGap              int64 `json:"minBlockGap"`      // ms
+	MinEmptyBlockGap         int64 `json:"minEmptyBlockGap"` // ms
+	RestrictBuilders         bool  `json:"restrictBuilders"` // requires the restrictBuilders fork
+	IncludeResultsRoot       bool  `json:"includeResultsRoot"`
+	DelayedExecution         bool  `json:"delayedExecution"`
+	PermutePendingTxs        bool  `json:"permutePendingTxs"` // requires delayedExecution
+	StateFetchRetries        uint8 `json:"stateFetchRetries"`
+	DisableParallelExecution bool  `json:"disableParallelExecution"` // parallel execution is enabled when omitted
+
+	MaxRepeatCheckDepth int `json:"maxRepeatCheckDepth"` // blocks (0 is unlimited)
+
+	// Epoch Parameters (disabled if EpochLength is 0)
+	EpochLength uint64 `json:"epochLength"` // blocks
+
+	// Signer Diversity Parameters (disabled if MinDistinctSigners is 0)
+	MinDistinctSigners    int `json:"minDistinctSigners"`
+	MinDistinctSignersTxs int `json:"minDistinctSignersTxs"` // blocks with fewer txs are exempt
+
+	// Access Control Parameters
+	RestrictedActions []uint8 `json:"restrictedActions"` // action type IDs
+	ACLAdmin          string  `json:"aclAdmin"`          // bech32 address
+
+	aclAdmin codec.Address
+
+	// Emergency Parameters (the chain is paused from PausedFrom until
+	// PausedUntil, or indefinitely if PausedUntil is 0)
+	PausedFrom  int64 `json:"pausedFrom"`  // ms (disabled if 0)
+	PausedUntil int64 `json:"pausedUntil"` // ms
+
+	// Upgrade Parameters (when each fork activates, see [chain.IsActive])
+	ForkActivations chain.ForkActivations `json:"forkActivations"` // ms
 
 	// Chain Fee Parameters
-	MinUnitPrice               fees.Dimensions `json:"minUnitPrice"`
-	UnitPriceChangeDenominator fees.Dimensions `json:"unitPriceChangeDenominator"`
-	WindowTargetUnits          fees.Dimensions `json:"windowTargetUnits"` // 10s
-	MaxBlockUnits              fees.Dimensions `json:"maxBlockUnits"`     // must be possible to reach before block too large
+	RefundPolicy               chain.RefundPolicy `json:"refundPolicy"`
+	MinUnitPrice               fees.Dimensions    `json:"minUnitPrice"`
+	UnitPriceChangeDenominator fees.Dimensions    `json:"unitPriceChangeDenominator"`
+	WindowTargetUnits          fees.Dimensions    `json:"windowTargetUnits"` // 10s
+	MaxBlockUnits              fees.Dimensions    `json:"maxBlockUnits"`     // must be possible to reach before block too large
+
+	// Pricing Parameters (PricingMode is used from PricingModeActivation,
+	// before which unit prices are computed with [fees.WindowPricing])
+	PricingMode               fees.PricingMode `json:"pricingMode"`
+	PricingModeActivation     int64            `json:"pricingModeActivation"` // ms
+	UtilizationEMADenominator uint64           `json:"utilizationEMADenominator"`
+
+	// Block Pacing Parameters (disabled if TargetBlockRate is 0)
+	TargetBlockRate            int64  `json:"targetBlockRate"` // s
+	BlockCostChangeDenominator uint64 `json:"blockCostChangeDenominator"`
+	MinBlockCost               uint64 `json:"minBlockCost"`
+	MaxBlockCost               uint64 `json:"maxBlockCost"`
 
 	// Tx Parameters
-	ValidityWindow      int64 `json:"validityWindow"` // ms
-	MaxActionsPerTx     uint8 `json:"maxActionsPerTx"`
-	MaxOutputsPerAction uint8 `json:"maxOutputsPerAction"`
+	ValidityWindow         int64  `json:"validityWindow"` // ms
+	MaxActionsPerTx        uint8  `json:"maxActionsPerTx"`
+	MaxOutputsPerAction    uint8  `json:"maxOutputsPerAction"`
+	MaxActionOutputBytes   uint64 `json:"maxActionOutputBytes"`
+	EnforceIdempotencyKeys bool   `json:"enforceIdempotencyKeys"`
 
 	// Tx Fee Parameters
 	BaseComputeUnits          uint64 `json:"baseUnits"`
@@ -67,8 +113,10 @@ func Default() *Genesis {
 		StateBranchFactor: merkledb.BranchFactor16,
 
 		// Chain Parameters
-		MinBlockGap:      100,
-		MinEmptyBlockGap: 2_500,
+		MinBlockGap:         100,
+		MinEmptyBlockGap:    2_500,
+		StateFetchRetries:   3,
+		MaxRepeatCheckDepth: 1_024,
 
 		// Chain Fee Parameters
 		MinUnitPrice:               fees.Dimensions{100, 100, 100, 100, 100},
@@ -76,10 +124,19 @@ func Default() *Genesis {
 		WindowTargetUnits:          fees.Dimensions{20_000_000, 1_000, 1_000, 1_000, 1_000},
 		MaxBlockUnits:              fees.Dimensions{1_800_000, 2_000, 2_000, 2_000, 2_000},
 
+		// Pricing Parameters
+		UtilizationEMADenominator: 8,
+
+		// Block Pacing Parameters
+		BlockCostChangeDenominator: 48,
+
 		// Tx Parameters
-		ValidityWindow:      60 * hconsts.MillisecondsPerSecond, // ms
-		MaxActionsPerTx:     16,
-		MaxOutputsPerAction: 1,
+		ValidityWindow:       60 * hconsts.MillisecondsPerSecond, // ms
+		MaxActionsPerTx:      16,
+		MaxOutputsPerAction:  1,
+		MaxActionOutputBytes: 1_024,
+
+		EnforceIdempotencyKeys: true,
 
 		// Tx Fee Compute Parameters
 		BaseComputeUnits: 1,
@@ -103,9 +160,172 @@ func New(b []byte, _ []byte /* upgradeBytes */) (*Genesis, error) {
 			return nil, fmt.Errorf("failed to unmarshal config %s: %w", string(b), err)
 		}
 	}
+	if errs := g.verifyBlockPacing(); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(g.ACLAdmin) > 0 {
+		admin, err := consts.ParseAddress(g.ACLAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, g.ACLAdmin)
+		}
+		g.aclAdmin = admin
+	}
 	return g, nil
 }
 
+// Verify cross-checks that the parameters of [g] are internally consistent.
+//
+// Every inconsistency is reported (instead of just the first) so that a
+// genesis can be fixed in one pass.
+func (g *Genesis) Verify() error {
+	errs := []error{}
+	if err := g.StateBranchFactor.Valid(); err != nil {
+		errs = append(errs, fmt.Errorf("%w: stateBranchFactor must be one of 2, 4, 16, or 256", err))
+	}
+
+	// Allocations
+	if len(g.CustomAllocation) == 0 {
+		errs = append(errs, fmt.Errorf("%w: no allocations (no account would be able to pay fees)", ErrInvalidAllocation))
+	}
+	var (
+		supply   uint64
+		overflow bool
+		seen     = make(map[codec.Address]int, len(g.CustomAllocation))
+	)
+	for i, alloc := range g.CustomAllocation {
+		addr, err := consts.ParseAddress(alloc.Address)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: customAllocation[%d] has invalid address %q (%w)", ErrInvalidAllocation, i, alloc.Address, err))
+			continue
+		}
+		if j, ok := seen[addr]; ok {
+			errs = append(errs, fmt.Errorf("%w: customAllocation[%d] duplicates customAllocation[%d] (merge their balances)", ErrInvalidAllocation, i, j))
+		}
+		seen[addr] = i
+		if alloc.Balance == 0 {
+			errs = append(errs, fmt.Errorf("%w: customAllocation[%d] has a balance of 0 (remove it)", ErrInvalidAllocation, i))
+		}
+		if !overflow {
+			supply, err = smath.Add64(supply, alloc.Balance)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: total supply overflows uint64 at customAllocation[%d]", ErrInvalidAllocation, i))
+				overflow = true
+			}
+		}
+	}
+
+	// Fees
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		if g.MinUnitPrice[i] == 0 {
+			errs = append(errs, fmt.Errorf("%w: minUnitPrice[%d] is 0 (the fee floor must be at least 1)", ErrInvalidFeeParameters, i))
+		}
+		if g.UnitPriceChangeDenominator[i] == 0 {
+			errs = append(errs, fmt.Errorf("%w: unitPriceChangeDenominator[%d] is 0", ErrInvalidFeeParameters, i))
+		}
+		if g.MaxBlockUnits[i] == 0 {
+			errs = append(errs, fmt.Errorf("%w: maxBlockUnits[%d] is 0 (no transaction could be included)", ErrInvalidFeeParameters, i))
+		}
+		if g.WindowTargetUnits[i] == 0 {
+			errs = append(errs, fmt.Errorf("%w: windowTargetUnits[%d] is 0 (unit prices would never decrease)", ErrInvalidFeeParameters, i))
+		}
+	}
+	if g.MaxBlockUnits[fees.Bandwidth] > hconsts.NetworkSizeLimit {
+		errs = append(errs, fmt.Errorf(
+			"%w: maxBlockUnits[%d] is %d but blocks larger than %d bytes are dropped (lower it)",
+			ErrInvalidFeeParameters,
+			fees.Bandwidth,
+			g.MaxBlockUnits[fees.Bandwidth],
+			hconsts.NetworkSizeLimit,
+		))
+	}
+	if g.BaseComputeUnits == 0 {
+		errs = append(errs, fmt.Errorf("%w: baseUnits is 0 (empty transactions would be free)", ErrInvalidFeeParameters))
+	}
+	switch g.PricingMode {
+	case fees.WindowPricing:
+	case fees.EMAPricing:
+		if g.UtilizationEMADenominator == 0 {
+			errs = append(errs, fmt.Errorf("%w: utilizationEMADenominator is 0 but pricingMode is EMA", ErrInvalidFeeParameters))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%w: unknown pricingMode %d", ErrInvalidFeeParameters, g.PricingMode))
+	}
+	if g.PricingModeActivation < 0 {
+		errs = append(errs, fmt.Errorf("%w: pricingModeActivation must be >= 0 ms", ErrInvalidFeeParameters))
+	}
+	errs = append(errs, g.verifyBlockPacing()...)
+
+	// Blocks and windows
+	if g.MinBlockGap <= 0 {
+		errs = append(errs, fmt.Errorf("%w: minBlockGap must be > 0 ms", ErrInvalidBlockParameters))
+	}
+	if g.MinEmptyBlockGap < g.MinBlockGap {
+		errs = append(errs, fmt.Errorf("%w: minEmptyBlockGap (%d ms) is less than minBlockGap (%d ms)", ErrInvalidBlockParameters, g.MinEmptyBlockGap, g.MinBlockGap))
+	}
+	if g.ValidityWindow <= g.MinBlockGap {
+		errs = append(errs, fmt.Errorf(
+			"%w: validityWindow (%d ms) must be greater than minBlockGap (%d ms) or transactions expire before they can be included",
+			ErrInvalidBlockParameters,
+			g.ValidityWindow,
+			g.MinBlockGap,
+		))
+	}
+	if g.MaxActionsPerTx == 0 {
+		errs = append(errs, fmt.Errorf("%w: maxActionsPerTx is 0", ErrInvalidBlockParameters))
+	}
+	if g.MaxActionsPerTx > chain.MaxTxActions {
+		errs = append(errs, fmt.Errorf("%w: maxActionsPerTx (%d) is greater than the %d actions a transaction can encode", ErrInvalidBlockParameters, g.MaxActionsPerTx, chain.MaxTxActions))
+	}
+	if g.MaxRepeatCheckDepth < 0 {
+		errs = append(errs, fmt.Errorf("%w: maxRepeatCheckDepth must be >= 0 blocks", ErrInvalidBlockParameters))
+	}
+	if g.PermutePendingTxs && !g.DelayedExecution {
+		errs = append(errs, fmt.Errorf("%w: permutePendingTxs is set but delayedExecution is not (no transactions would be permuted)", ErrInvalidBlockParameters))
+	}
+	if _, ok := g.ForkActivations[chain.RestrictBuildersFork]; g.RestrictBuilders && !ok {
+		errs = append(errs, fmt.Errorf("%w: restrictBuilders is set but the %s fork is not scheduled (builders would never be restricted)", ErrInvalidBlockParameters, chain.RestrictBuildersFork))
+	}
+
+	// Access control
+	if len(g.RestrictedActions) > 0 && len(g.ACLAdmin) == 0 {
+		errs = append(errs, fmt.Errorf("%w: restrictedActions is set but aclAdmin is empty (no account could be granted access)", ErrInvalidACLParameters))
+	}
+	if len(g.ACLAdmin) > 0 {
+		if _, err := consts.ParseAddress(g.ACLAdmin); err != nil {
+			errs = append(errs, fmt.Errorf("%w: aclAdmin has invalid address %q (%w)", ErrInvalidACLParameters, g.ACLAdmin, err))
+		}
+	}
+
+	// Pausing
+	if g.PausedFrom < 0 || g.PausedUntil < 0 {
+		errs = append(errs, fmt.Errorf("%w: pausedFrom and pausedUntil must be >= 0 ms", ErrInvalidPauseParameters))
+	}
+	if g.PausedUntil > 0 && g.PausedUntil <= g.PausedFrom {
+		errs = append(errs, fmt.Errorf("%w: pausedUntil (%d ms) must be greater than pausedFrom (%d ms)", ErrInvalidPauseParameters, g.PausedUntil, g.PausedFrom))
+	}
+	if g.PausedFrom > 0 && g.PausedUntil == 0 && len(g.ACLAdmin) == 0 {
+		errs = append(errs, fmt.Errorf("%w: the chain is paused indefinitely but aclAdmin is empty (only empty blocks could be built)", ErrInvalidPauseParameters))
+	}
+	return errors.Join(errs...)
+}
+
+// verifyBlockPacing returns the inconsistencies in the block pacing parameters
+// of [g]. Unlike the rest of [Verify], these are also checked by [New] because
+// computing the next block cost would panic.
+func (g *Genesis) verifyBlockPacing() []error {
+	if g.TargetBlockRate <= 0 {
+		return nil
+	}
+	errs := []error{}
+	if g.BlockCostChangeDenominator == 0 {
+		errs = append(errs, fmt.Errorf("%w: blockCostChangeDenominator is 0 but targetBlockRate is set", ErrInvalidFeeParameters))
+	}
+	if g.MinBlockCost > g.MaxBlockCost {
+		errs = append(errs, fmt.Errorf("%w: minBlockCost (%d) is greater than maxBlockCost (%d)", ErrInvalidFeeParameters, g.MinBlockCost, g.MaxBlockCost))
+	}
+	return errs
+}
+
 func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, mu state.Mutable) error {
 	ctx, span := tracer.Start(ctx, "Genesis.Load")
 	defer span.End()
@@ -116,7 +336,7 @@ func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, mu state.Mutabl
 
 	supply := uint64(0)
 	for _, alloc := range g.CustomAllocation {
-		addr, err := codec.ParseAddressBech32(consts.HRP, alloc.Address)
+		addr, err := consts.ParseAddress(alloc.Address)
 		if err != nil {
 			return fmt.Errorf("%w: %s", err, alloc.Address)
 		}
@@ -128,7 +348,7 @@ func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, mu state.Mutabl
 			return fmt.Errorf("%w: addr=%s, bal=%d", err, alloc.Address, alloc.Balance)
 		}
 	}
-	return nil
+	return storage.SetSupply(ctx, mu, supply)
 }
 
 func (g *Genesis) GetStateBranchFactor() merkledb.BranchFactor {
@@ -0,0 +1,273 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package genesis
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	"github.com/ava-labs/hypersdk/fees"
+
+	hconsts "github.com/ava-labs/hypersdk/consts"
+)
+
+func TestGenesisVerify(t *testing.T) {
+	addr := consts.FormatAddress(codec.CreateAddress(consts.ED25519ID, ids.GenerateTestID()))
+	tests := []struct {
+		name   string
+		modify func(*Genesis)
+		errs   []error
+	}{
+		{
+			name:   "valid",
+			modify: func(*Genesis) {},
+		},
+		{
+			name: "no allocations",
+			modify: func(g *Genesis) {
+				g.CustomAllocation = nil
+			},
+			errs: []error{ErrInvalidAllocation},
+		},
+		{
+			name: "invalid allocations",
+			modify: func(g *Genesis) {
+				g.CustomAllocation = []*CustomAllocation{
+					{Address: addr, Balance: 10},
+					{Address: addr, Balance: 0},
+					{Address: "morpheus1invalid", Balance: 10},
+				}
+			},
+			errs: []error{ErrInvalidAllocation},
+		},
+		{
+			name: "supply overflow",
+			modify: func(g *Genesis) {
+				g.CustomAllocation = append(g.CustomAllocation, &CustomAllocation{
+					Address: consts.FormatAddress(codec.CreateAddress(consts.ED25519ID, ids.GenerateTestID())),
+					Balance: ^uint64(0),
+				})
+			},
+			errs: []error{ErrInvalidAllocation},
+		},
+		{
+			name: "fee floor",
+			modify: func(g *Genesis) {
+				g.MinUnitPrice[fees.Compute] = 0
+			},
+			errs: []error{ErrInvalidFeeParameters},
+		},
+		{
+			name: "block too large",
+			modify: func(g *Genesis) {
+				g.MaxBlockUnits[fees.Bandwidth] = hconsts.NetworkSizeLimit + 1
+			},
+			errs: []error{ErrInvalidFeeParameters},
+		},
+		{
+			name: "block cost range",
+			modify: func(g *Genesis) {
+				g.TargetBlockRate = 1
+				g.MinBlockCost = 10
+				g.MaxBlockCost = 5
+			},
+			errs: []error{ErrInvalidFeeParameters},
+		},
+		{
+			name: "block cost without change denominator",
+			modify: func(g *Genesis) {
+				g.TargetBlockRate = 1
+				g.BlockCostChangeDenominator = 0
+			},
+			errs: []error{ErrInvalidFeeParameters},
+		},
+		{
+			name: "ema pricing",
+			modify: func(g *Genesis) {
+				g.PricingMode = fees.EMAPricing
+				g.PricingModeActivation = 1_000
+			},
+		},
+		{
+			name: "ema pricing without smoothing",
+			modify: func(g *Genesis) {
+				g.PricingMode = fees.EMAPricing
+				g.UtilizationEMADenominator = 0
+			},
+			errs: []error{ErrInvalidFeeParameters},
+		},
+		{
+			name: "unknown pricing mode",
+			modify: func(g *Genesis) {
+				g.PricingMode = 2
+			},
+			errs: []error{ErrInvalidFeeParameters},
+		},
+		{
+			name: "validity window shorter than block gap",
+			modify: func(g *Genesis) {
+				g.ValidityWindow = g.MinBlockGap
+			},
+			errs: []error{ErrInvalidBlockParameters},
+		},
+		{
+			name: "too many actions per tx",
+			modify: func(g *Genesis) {
+				g.MaxActionsPerTx = chain.MaxTxActions + 1
+			},
+			errs: []error{ErrInvalidBlockParameters},
+		},
+		{
+			name: "restricted builders without fork",
+			modify: func(g *Genesis) {
+				g.RestrictBuilders = true
+			},
+			errs: []error{ErrInvalidBlockParameters},
+		},
+		{
+			name: "restricted actions without admin",
+			modify: func(g *Genesis) {
+				g.RestrictedActions = []uint8{0}
+			},
+			errs: []error{ErrInvalidACLParameters},
+		},
+		{
+			name: "paused",
+			modify: func(g *Genesis) {
+				g.PausedFrom = 1_000
+				g.PausedUntil = 2_000
+			},
+		},
+		{
+			name: "pause ends before it starts",
+			modify: func(g *Genesis) {
+				g.PausedFrom = 2_000
+				g.PausedUntil = 1_000
+			},
+			errs: []error{ErrInvalidPauseParameters},
+		},
+		{
+			name: "paused indefinitely without admin",
+			modify: func(g *Genesis) {
+				g.PausedFrom = 1_000
+			},
+			errs: []error{ErrInvalidPauseParameters},
+		},
+		{
+			name: "multiple errors",
+			modify: func(g *Genesis) {
+				g.CustomAllocation = nil
+				g.MinEmptyBlockGap = 0
+				g.UnitPriceChangeDenominator[fees.StorageRead] = 0
+			},
+			errs: []error{ErrInvalidAllocation, ErrInvalidBlockParameters, ErrInvalidFeeParameters},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			g := Default()
+			g.CustomAllocation = []*CustomAllocation{{Address: addr, Balance: 10}}
+			tt.modify(g)
+			err := g.Verify()
+			if len(tt.errs) == 0 {
+				require.NoError(err)
+				return
+			}
+			for _, expected := range tt.errs {
+				require.ErrorIs(err, expected)
+			}
+		})
+	}
+}
+
+func TestRulesIsPaused(t *testing.T) {
+	tests := []struct {
+		name        string
+		pausedFrom  int64
+		pausedUntil int64
+		timestamp   int64
+		paused      bool
+	}{
+		{
+			name:      "disabled",
+			timestamp: 1_000,
+		},
+		{
+			name:       "before pause",
+			pausedFrom: 1_000,
+			timestamp:  999,
+		},
+		{
+			name:       "paused indefinitely",
+			pausedFrom: 1_000,
+			timestamp:  1_000_000,
+			paused:     true,
+		},
+		{
+			name:        "paused",
+			pausedFrom:  1_000,
+			pausedUntil: 2_000,
+			timestamp:   1_999,
+			paused:      true,
+		},
+		{
+			name:        "unpaused",
+			pausedFrom:  1_000,
+			pausedUntil: 2_000,
+			timestamp:   2_000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := Default()
+			g.PausedFrom = tt.pausedFrom
+			g.PausedUntil = tt.pausedUntil
+			require.Equal(t, tt.paused, g.Rules(tt.timestamp, 0, ids.Empty).IsPaused(tt.timestamp))
+		})
+	}
+}
+
+func TestRulesPricingMode(t *testing.T) {
+	require := require.New(t)
+
+	g := Default()
+	require.Equal(fees.WindowPricing, g.Rules(0, 0, ids.Empty).GetPricingMode())
+
+	// The pricing mode only changes at its activation
+	g.PricingMode = fees.EMAPricing
+	g.PricingModeActivation = 1_000
+	require.Equal(fees.WindowPricing, g.Rules(999, 0, ids.Empty).GetPricingMode())
+	require.Equal(fees.EMAPricing, g.Rules(1_000, 0, ids.Empty).GetPricingMode())
+}
+
+func TestNewBlockPacing(t *testing.T) {
+	require := require.New(t)
+
+	// A genesis that would panic when computing the next block cost is
+	// rejected even if [Genesis.Verify] is never called
+	_, err := New([]byte(`{"targetBlockRate":1,"blockCostChangeDenominator":0}`), nil)
+	require.ErrorIs(err, ErrInvalidFeeParameters)
+	g, err := New([]byte(`{"targetBlockRate":1}`), nil)
+	require.NoError(err)
+	require.Equal(uint64(48), g.BlockCostChangeDenominator)
+}
+
+func TestRulesParallelExecution(t *testing.T) {
+	require := require.New(t)
+
+	// Parallel execution is enabled unless explicitly disabled
+	g, err := New([]byte(`{}`), nil)
+	require.NoError(err)
+	require.True(g.Rules(0, 0, ids.Empty).GetParallelExecution())
+	g, err = New([]byte(`{"disableParallelExecution":true}`), nil)
+	require.NoError(err)
+	require.False(g.Rules(0, 0, ids.Empty).GetParallelExecution())
+}
@@ -17,12 +17,29 @@ import (
 func init() {
 	consts.ActionRegistry = codec.NewTypeParser[chain.Action]()
 	consts.AuthRegistry = codec.NewTypeParser[chain.Auth]()
+	consts.ActionVectors = chain.NewActionVectorRegistry()
 
 	errs := &wrappers.Errs{}
 	errs.Add(
 		// When registering new actions, ALWAYS make sure to append at the end.
 		consts.ActionRegistry.Register((&actions.Transfer{}).GetTypeID(), actions.UnmarshalTransfer, false),
 		consts.ActionRegistry.Register((&actions.Burn{}).GetTypeID(), actions.UnmarshalBurn, false),
+		consts.ActionRegistry.Register((&actions.GrantAccess{}).GetTypeID(), actions.UnmarshalGrantAccess, false),
+		consts.ActionRegistry.Register((&actions.RevokeAccess{}).GetTypeID(), actions.UnmarshalRevokeAccess, false),
+		consts.ActionRegistry.Register((&actions.SetMetadata{}).GetTypeID(), actions.UnmarshalSetMetadata, false),
+		consts.ActionRegistry.Register((&actions.Echo{}).GetTypeID(), actions.UnmarshalEcho, false),
+		consts.ActionRegistry.Register((&actions.Escrow{}).GetTypeID(), actions.UnmarshalEscrow, false),
+		consts.ActionRegistry.Register((&actions.ReleaseEscrow{}).GetTypeID(), actions.UnmarshalReleaseEscrow, false),
+		consts.ActionRegistry.Register((&actions.Digest{}).GetTypeID(), actions.UnmarshalDigest, false),
+		consts.ActionRegistry.Register((&actions.EmitDigest{}).GetTypeID(), actions.UnmarshalEmitDigest, false),
+		consts.ActionRegistry.Register((&actions.TransferWithBurn{}).GetTypeID(), actions.UnmarshalTransferWithBurn, false),
+		consts.ActionRegistry.Register((&actions.Iterate{}).GetTypeID(), actions.UnmarshalIterate, false),
+		consts.ActionRegistry.Register((&actions.Pay{}).GetTypeID(), actions.UnmarshalPay, false),
+
+		// Actions without vectors fail [chaintest.RunActionVectors].
+		consts.ActionVectors.Register((&actions.Transfer{}).GetTypeID(), actions.TransferVectors()),
+		consts.ActionVectors.Register((&actions.Burn{}).GetTypeID(), actions.BurnVectors()),
+		consts.ActionVectors.Register((&actions.TransferWithBurn{}).GetTypeID(), actions.TransferWithBurnVectors()),
 
 		// When registering new auth, ALWAYS make sure to append at the end.
 		consts.AuthRegistry.Register((&auth.ED25519{}).GetTypeID(), auth.UnmarshalED25519, false),
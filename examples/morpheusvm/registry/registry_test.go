@@ -0,0 +1,68 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/chain/chaintest"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/crypto/ed25519"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/actions"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/auth"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/storage"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+func TestActionVectors(t *testing.T) {
+	chaintest.RunActionVectors(
+		t,
+		consts.ActionRegistry,
+		consts.ActionVectors,
+		nil,
+		// TODO: add vectors for the remaining actions
+		consts.GrantAccessID,
+		consts.RevokeAccessID,
+		consts.SetMetadataID,
+		consts.EchoID,
+		consts.EscrowID,
+		consts.ReleaseEscrowID,
+		consts.DigestID,
+		consts.EmitDigestID,
+		consts.IterateID,
+		consts.PayID,
+	)
+}
+
+func TestAdversarialBlocks(t *testing.T) {
+	require := require.New(t)
+	priv, err := ed25519.GeneratePrivateKey()
+	require.NoError(err)
+	g := genesis.Default()
+	g.CustomAllocation = []*genesis.CustomAllocation{{
+		Address: consts.FormatAddress(auth.NewED25519Address(priv.PublicKey())),
+		Balance: 10_000_000_000_000,
+	}}
+	chaintest.RunAdversarialBlocks(t, &chaintest.BlockConfig{
+		Rules:          g.Rules(0, 0, ids.GenerateTestID()),
+		ActionRegistry: consts.ActionRegistry,
+		AuthRegistry:   consts.AuthRegistry,
+		StateManager:   &storage.StateManager{},
+		Genesis: func(ctx context.Context, mu state.Mutable) error {
+			return g.Load(ctx, trace.Noop, mu)
+		},
+		Factory: auth.NewED25519Factory(priv),
+		Action: func(i int) chain.Action {
+			return &actions.Transfer{To: codec.CreateAddress(0, ids.GenerateTestID()), Value: uint64(i + 1)}
+		},
+	})
+}
@@ -0,0 +1,31 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+// Address is a [codec.Address] that is encoded in JSON with
+// [consts.AddressCodec]. Decoding an address of another chain fails with an
+// error naming [consts.HRP].
+type Address codec.Address
+
+func (a Address) MarshalText() ([]byte, error) {
+	saddr, err := consts.AddressCodec.FormatAddress(codec.Address(a))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(saddr), nil
+}
+
+func (a *Address) UnmarshalText(b []byte) error {
+	addr, err := consts.ParseAddress(string(b))
+	if err != nil {
+		return err
+	}
+	*a = Address(addr)
+	return nil
+}
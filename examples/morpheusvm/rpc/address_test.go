@@ -0,0 +1,39 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+)
+
+func TestAddressJSON(t *testing.T) {
+	require := require.New(t)
+
+	addr := codec.CreateAddress(0, ids.GenerateTestID())
+	raw, err := json.Marshal(&BalanceArgs{Address: Address(addr)})
+	require.NoError(err)
+	require.JSONEq(fmt.Sprintf(`{"address":%q}`, consts.FormatAddress(addr)), string(raw))
+
+	var args BalanceArgs
+	require.NoError(json.Unmarshal(raw, &args))
+	require.Equal(addr, codec.Address(args.Address))
+}
+
+func TestAddressJSONWrongHRP(t *testing.T) {
+	require := require.New(t)
+
+	other := codec.MustAddressBech32m("token", codec.CreateAddress(0, ids.GenerateTestID()))
+	var args BalanceArgs
+	err := json.Unmarshal([]byte(fmt.Sprintf(`{"address":%q}`, other)), &args)
+	require.ErrorIs(err, codec.ErrIncorrectHRP)
+	require.ErrorContains(err, "expected="+consts.HRP)
+}
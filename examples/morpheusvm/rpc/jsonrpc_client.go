@@ -47,6 +47,7 @@ func (cli *JSONRPCClient) Genesis(ctx context.Context) (*genesis.Genesis, error)
 		"genesis",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	if err != nil {
 		return nil, err
@@ -62,6 +63,7 @@ func (cli *JSONRPCClient) Tx(ctx context.Context, id ids.ID) (bool, bool, int64,
 		"tx",
 		&TxArgs{TxID: id},
 		resp,
+		requester.Idempotent(),
 	)
 	switch {
 	// We use string parsing here because the JSON-RPC library we use may not
@@ -75,14 +77,19 @@ func (cli *JSONRPCClient) Tx(ctx context.Context, id ids.ID) (bool, bool, int64,
 }
 
 func (cli *JSONRPCClient) Balance(ctx context.Context, addr string) (uint64, error) {
+	paddr, err := consts.ParseAddress(addr)
+	if err != nil {
+		return 0, err
+	}
 	resp := new(BalanceReply)
-	err := cli.requester.SendRequest(
+	err = cli.requester.SendRequest(
 		ctx,
 		"balance",
 		&BalanceArgs{
-			Address: addr,
+			Address: Address(paddr),
 		},
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.Amount, err
 }
@@ -9,7 +9,6 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 
 	"github.com/ava-labs/hypersdk/codec"
-	"github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
 	"github.com/ava-labs/hypersdk/fees"
 )
@@ -61,7 +60,7 @@ func (j *JSONRPCServer) Tx(req *http.Request, args *TxArgs, reply *TxReply) erro
 }
 
 type BalanceArgs struct {
-	Address string `json:"address"`
+	Address Address `json:"address"`
 }
 
 type BalanceReply struct {
@@ -72,11 +71,7 @@ func (j *JSONRPCServer) Balance(req *http.Request, args *BalanceArgs, reply *Bal
 	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Balance")
 	defer span.End()
 
-	addr, err := codec.ParseAddressBech32(consts.HRP, args.Address)
-	if err != nil {
-		return err
-	}
-	balance, err := j.c.GetBalanceFromState(ctx, addr)
+	balance, err := j.c.GetBalanceFromState(ctx, codec.Address(args.Address))
 	if err != nil {
 		return err
 	}
@@ -41,3 +41,8 @@ CLI_PATH=$MORPHEUSVM_PATH/build/morpheus-cli
 echo "Building morpheus-cli in $CLI_PATH"
 mkdir -p "$(dirname "$CLI_PATH")"
 go build -o "$CLI_PATH" ./cmd/morpheus-cli
+
+FAUCET_PATH=$MORPHEUSVM_PATH/build/morpheus-faucet
+echo "Building morpheus-faucet in $FAUCET_PATH"
+mkdir -p "$(dirname "$FAUCET_PATH")"
+go build -o "$FAUCET_PATH" ./cmd/morpheus-faucet
@@ -17,6 +17,14 @@ if ! [[ "$0" =~ scripts/run.sh ]]; then
   exit 255
 fi
 
+# to run with a chain scaffolded by "morpheus-cli chain create"
+# MANIFEST=./mychain/manifest.env ./scripts/run.sh
+MANIFEST=${MANIFEST:-}
+if [[ -n "${MANIFEST}" ]]; then
+  # shellcheck disable=SC1090
+  source "${MANIFEST}"
+fi
+
 VERSION=v1.11.6
 MAX_UINT64=18446744073709551615
 MODE=${MODE:-run}
@@ -27,7 +35,7 @@ STATESYNC_DELAY=${STATESYNC_DELAY:-0}
 MIN_BLOCK_GAP=${MIN_BLOCK_GAP:-100}
 STORE_TXS=${STORE_TXS:-false}
 UNLIMITED_USAGE=${UNLIMITED_USAGE:-false}
-ADDRESS=${ADDRESS:-morpheus1qrzvk4zlwj9zsacqgtufx7zvapd3quufqpxk5rsdd4633m4wz2fdjk97rwu}
+ADDRESS=${ADDRESS:-morpheus1qrzvk4zlwj9zsacqgtufx7zvapd3quufqpxk5rsdd4633m4wz2fdjrew0t7}
 if [[ ${MODE} != "run" ]]; then
   LOG_LEVEL=DEBUG
   AGO_LOG_DISPLAY_LEVEL=INFO
@@ -124,7 +132,7 @@ cat <<EOF > "${TMPDIR}"/allocations.json
 ]
 EOF
 
-GENESIS_PATH=$2
+GENESIS_PATH=${GENESIS_PATH:-$2}
 if [[ -z "${GENESIS_PATH}" ]]; then
   echo "creating VM genesis file with allocations"
   rm -f "${TMPDIR}"/morpheusvm.genesis
@@ -143,9 +151,13 @@ fi
 
 ############################
 
-echo "creating vm config"
 rm -f "${TMPDIR}"/morpheusvm.config
 rm -rf "${TMPDIR}"/morpheusvm-e2e-profiles
+if [[ -n "${VM_CONFIG_PATH}" ]]; then
+  echo "copying custom vm config"
+  cp "${VM_CONFIG_PATH}" "${TMPDIR}"/morpheusvm.config
+else
+echo "creating vm config"
 cat <<EOF > "${TMPDIR}"/morpheusvm.config
 {
   "mempoolSize": 10000000,
@@ -162,20 +174,26 @@ cat <<EOF > "${TMPDIR}"/morpheusvm.config
   "stateSyncServerDelay": ${STATESYNC_DELAY}
 }
 EOF
+fi
 mkdir -p "${TMPDIR}"/morpheusvm-e2e-profiles
 
 ############################
 
 ############################
 
-echo "creating subnet config"
 rm -f "${TMPDIR}"/morpheusvm.subnet
+if [[ -n "${SUBNET_CONFIG_PATH}" ]]; then
+  echo "copying custom subnet config"
+  cp "${SUBNET_CONFIG_PATH}" "${TMPDIR}"/morpheusvm.subnet
+else
+echo "creating subnet config"
 cat <<EOF > "${TMPDIR}"/morpheusvm.subnet
 {
   "proposerMinBlockDelay": 0,
   "proposerNumHistoricalBlocks": 50000
 }
 EOF
+fi
 
 ############################
 
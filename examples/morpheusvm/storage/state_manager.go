@@ -6,6 +6,8 @@ package storage
 import (
 	"context"
 
+	"github.com/ava-labs/avalanchego/ids"
+
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/state"
@@ -27,6 +29,30 @@ func (*StateManager) FeeKey() []byte {
 	return FeeKey()
 }
 
+func (*StateManager) MemoKey(txID ids.ID) []byte {
+	return MemoKey(txID)
+}
+
+func (*StateManager) TaskQueueKey() []byte {
+	return TaskQueueKey()
+}
+
+func (*StateManager) TaskKey(taskID ids.ID) []byte {
+	return TaskKey(taskID)
+}
+
+func (*StateManager) ACLKey(actionTypeID uint8, addr codec.Address) []byte {
+	return ACLKey(actionTypeID, addr)
+}
+
+func (*StateManager) EpochKey(epoch uint64) []byte {
+	return EpochKey(epoch)
+}
+
+func (*StateManager) IdempotencyKey(id ids.ID) []byte {
+	return IdempotencyKey(id)
+}
+
 func (*StateManager) SponsorStateKeys(addr codec.Address) state.Keys {
 	return state.Keys{
 		string(BalanceKey(addr)): state.Read | state.Write,
@@ -57,3 +83,13 @@ func (*StateManager) Deduct(
 ) error {
 	return SubBalance(ctx, mu, addr, amount)
 }
+
+func (*StateManager) Refund(
+	ctx context.Context,
+	addr codec.Address,
+	mu state.Mutable,
+	amount uint64,
+) error {
+	// Don't create accounts that were deleted after paying fees
+	return AddBalance(ctx, mu, addr, amount, false)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 
+	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/fees"
@@ -35,6 +36,23 @@ type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 // 0x1/ (hypersdk-height)
 // 0x2/ (hypersdk-timestamp)
 // 0x3/ (hypersdk-fee)
+// 0x4/ (hypersdk-memo)
+//   -> [txID] => memo
+// 0x5/ (hypersdk-task-queue)
+// 0x6/ (hypersdk-task)
+//   -> [taskID] => task
+// 0x7/ (hypersdk-acl)
+//   -> [actionTypeID|address] => granted
+// 0x8/ (hypersdk-epoch)
+//   -> [epoch] => validator set
+// 0x9/ (metadata)
+//   -> [owner|key] => value
+// 0xA/ (escrow)
+//   -> [escrowID] => creator|recipient|value
+// 0xB/ (supply)
+//   -> total supply
+// 0xC/ (hypersdk-idempotency)
+//   -> [idempotencyID] => txID
 
 const (
 	// metaDB
@@ -45,9 +63,63 @@ const (
 	heightPrefix    = 0x1
 	timestampPrefix = 0x2
 	feePrefix       = 0x3
+	memoPrefix      = 0x4
+	taskQueuePrefix = 0x5
+	taskPrefix      = 0x6
+	aclPrefix       = 0x7
+	epochPrefix     = 0x8
+	metadataPrefix  = 0x9
+	escrowPrefix    = 0xA
+	supplyPrefix    = 0xB
+
+	idempotencyPrefix = 0xC
 )
 
-const BalanceChunks uint16 = 1
+const (
+	BalanceChunks uint16 = 1
+
+	// MaxMetadataKeySize is the maximum size of a metadata key.
+	MaxMetadataKeySize = 32
+
+	// MaxMetadataValueSize is the maximum size of a metadata value.
+	MaxMetadataValueSize = 256
+
+	MetadataChunks uint16 = MaxMetadataValueSize/64 + 1
+
+	EscrowChunks uint16 = 2
+
+	SupplyChunks uint16 = 1
+)
+
+// statePrefixes names each prefix of the state (for the getStateUsage RPC).
+var statePrefixes = []struct {
+	name   string
+	prefix byte
+}{
+	{"balance", balancePrefix},
+	{"hypersdk-height", heightPrefix},
+	{"hypersdk-timestamp", timestampPrefix},
+	{"hypersdk-fee", feePrefix},
+	{"hypersdk-memo", memoPrefix},
+	{"hypersdk-task-queue", taskQueuePrefix},
+	{"hypersdk-task", taskPrefix},
+	{"hypersdk-acl", aclPrefix},
+	{"hypersdk-epoch", epochPrefix},
+	{"metadata", metadataPrefix},
+	{"escrow", escrowPrefix},
+	{"supply", supplyPrefix},
+	{"hypersdk-idempotency", idempotencyPrefix},
+}
+
+// RegisterStatePrefixes registers each prefix of the state with [r].
+func RegisterStatePrefixes(r *chain.StatePrefixRegistry) error {
+	for _, p := range statePrefixes {
+		if err := r.Register(p.name, []byte{p.prefix}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 var (
 	failureByte  = byte(0x0)
@@ -55,6 +127,8 @@ var (
 	heightKey    = []byte{heightPrefix}
 	timestampKey = []byte{timestampPrefix}
 	feeKey       = []byte{feePrefix}
+	taskQueueKey = []byte{taskQueuePrefix}
+	supplyKey    = binary.BigEndian.AppendUint16([]byte{supplyPrefix}, SupplyChunks)
 )
 
 // [txPrefix] + [txID]
@@ -208,7 +282,7 @@ func AddBalance(
 			"%w: could not add balance (bal=%d, addr=%v, amount=%d)",
 			ErrInvalidBalance,
 			bal,
-			codec.MustAddressBech32(mconsts.HRP, addr),
+			mconsts.FormatAddress(addr),
 			amount,
 		)
 	}
@@ -231,7 +305,7 @@ func SubBalance(
 			"%w: could not subtract balance (bal=%d, addr=%v, amount=%d)",
 			ErrInvalidBalance,
 			bal,
-			codec.MustAddressBech32(mconsts.HRP, addr),
+			mconsts.FormatAddress(addr),
 			amount,
 		)
 	}
@@ -254,3 +328,213 @@ func TimestampKey() (k []byte) {
 func FeeKey() (k []byte) {
 	return feeKey
 }
+
+// [memoPrefix] + [txID]
+func MemoKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = memoPrefix
+	copy(k[1:], txID[:])
+	return
+}
+
+func TaskQueueKey() (k []byte) {
+	return taskQueueKey
+}
+
+// [taskPrefix] + [taskID]
+func TaskKey(taskID ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = taskPrefix
+	copy(k[1:], taskID[:])
+	return
+}
+
+// [aclPrefix] + [actionTypeID] + [address]
+func ACLKey(actionTypeID uint8, addr codec.Address) (k []byte) {
+	k = make([]byte, 2+codec.AddressLen)
+	k[0] = aclPrefix
+	k[1] = actionTypeID
+	copy(k[2:], addr[:])
+	return
+}
+
+// [epochPrefix] + [epoch]
+func EpochKey(epoch uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = epochPrefix
+	binary.BigEndian.PutUint64(k[1:], epoch)
+	return
+}
+
+// [idempotencyPrefix] + [id]
+func IdempotencyKey(id ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = idempotencyPrefix
+	copy(k[1:], id[:])
+	return
+}
+
+// [metadataPrefix] + [owner] + [key]
+func MetadataKey(addr codec.Address, key []byte) (k []byte) {
+	k = make([]byte, 1+codec.AddressLen+len(key)+consts.Uint16Len)
+	k[0] = metadataPrefix
+	copy(k[1:], addr[:])
+	copy(k[1+codec.AddressLen:], key)
+	binary.BigEndian.PutUint16(k[1+codec.AddressLen+len(key):], MetadataChunks)
+	return
+}
+
+// GetMetadata returns the value [addr] stored at [key] (or nil if there is
+// none).
+func GetMetadata(
+	ctx context.Context,
+	im state.Immutable,
+	addr codec.Address,
+	key []byte,
+) ([]byte, error) {
+	v, err := im.GetValue(ctx, MetadataKey(addr, key))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	return v, err
+}
+
+// SetMetadata stores [value] at [key] for [addr] (or removes [key] if [value]
+// is empty).
+func SetMetadata(
+	ctx context.Context,
+	mu state.Mutable,
+	addr codec.Address,
+	key []byte,
+	value []byte,
+) error {
+	k := MetadataKey(addr, key)
+	if len(value) == 0 {
+		return mu.Remove(ctx, k)
+	}
+	return mu.Insert(ctx, k, value)
+}
+
+// [escrowPrefix] + [escrowID]
+func EscrowKey(escrowID ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen+consts.Uint16Len)
+	k[0] = escrowPrefix
+	copy(k[1:], escrowID[:])
+	binary.BigEndian.PutUint16(k[1+ids.IDLen:], EscrowChunks)
+	return
+}
+
+// EscrowAddress returns the address that holds the funds of [escrowID]. No
+// key can sign for it, so funds can only leave it through a ReleaseEscrow
+// action.
+func EscrowAddress(escrowID ids.ID) codec.Address {
+	return codec.CreateAddress(mconsts.EscrowID, escrowID)
+}
+
+// GetEscrow returns the creator, recipient, and value of [escrowID] (and
+// false if it does not exist).
+func GetEscrow(
+	ctx context.Context,
+	im state.Immutable,
+	escrowID ids.ID,
+) (codec.Address, codec.Address, uint64, bool, error) {
+	v, err := im.GetValue(ctx, EscrowKey(escrowID))
+	if errors.Is(err, database.ErrNotFound) {
+		return codec.EmptyAddress, codec.EmptyAddress, 0, false, nil
+	}
+	if err != nil {
+		return codec.EmptyAddress, codec.EmptyAddress, 0, false, err
+	}
+	var creator, recipient codec.Address
+	copy(creator[:], v)
+	copy(recipient[:], v[codec.AddressLen:])
+	value := binary.BigEndian.Uint64(v[2*codec.AddressLen:])
+	return creator, recipient, value, true, nil
+}
+
+func SetEscrow(
+	ctx context.Context,
+	mu state.Mutable,
+	escrowID ids.ID,
+	creator codec.Address,
+	recipient codec.Address,
+	value uint64,
+) error {
+	v := make([]byte, 2*codec.AddressLen+consts.Uint64Len)
+	copy(v, creator[:])
+	copy(v[codec.AddressLen:], recipient[:])
+	binary.BigEndian.PutUint64(v[2*codec.AddressLen:], value)
+	return mu.Insert(ctx, EscrowKey(escrowID), v)
+}
+
+func RemoveEscrow(
+	ctx context.Context,
+	mu state.Mutable,
+	escrowID ids.ID,
+) error {
+	return mu.Remove(ctx, EscrowKey(escrowID))
+}
+
+// [supplyPrefix]
+func SupplyKey() (k []byte) {
+	return supplyKey
+}
+
+// GetSupply returns the total supply (the sum of all balances).
+func GetSupply(
+	ctx context.Context,
+	im state.Immutable,
+) (uint64, error) {
+	v, err := im.GetValue(ctx, supplyKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+func SetSupply(
+	ctx context.Context,
+	mu state.Mutable,
+	supply uint64,
+) error {
+	return mu.Insert(ctx, supplyKey, binary.BigEndian.AppendUint64(nil, supply))
+}
+
+// SubSupply removes [amount] from the total supply (when it is burned).
+func SubSupply(
+	ctx context.Context,
+	mu state.Mutable,
+	amount uint64,
+) error {
+	supply, err := GetSupply(ctx, mu)
+	if err != nil {
+		return err
+	}
+	nsupply, err := smath.Sub(supply, amount)
+	if err != nil {
+		return fmt.Errorf(
+			"%w: could not subtract supply (supply=%d, amount=%d)",
+			ErrInvalidSupply,
+			supply,
+			amount,
+		)
+	}
+	return SetSupply(ctx, mu, nsupply)
+}
+
+// GetTxMemo returns the memo stored by the transaction [txID] (or nil if it
+// had no memo).
+func GetTxMemo(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) ([]byte, error) {
+	memo, err := im.GetValue(ctx, chain.MemoKey(MemoKey(txID)))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	return memo, err
+}
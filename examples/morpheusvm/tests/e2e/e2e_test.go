@@ -208,7 +208,7 @@ var _ = ginkgo.BeforeSuite(func() {
 	priv = ed25519.PrivateKey(privBytes)
 	factory = auth.NewED25519Factory(priv)
 	rsender = auth.NewED25519Address(priv.PublicKey())
-	sender = codec.MustAddressBech32(consts.HRP, rsender)
+	sender = consts.FormatAddress(rsender)
 	utils.Outf("\n{{yellow}}$ loaded address:{{/}} %s\n\n", sender)
 
 	utils.Outf(
@@ -431,7 +431,7 @@ var _ = ginkgo.Describe("[Test]", func() {
 		other, err := ed25519.GeneratePrivateKey()
 		require.NoError(err)
 		aother := auth.NewED25519Address(other.PublicKey())
-		aotherStr := codec.MustAddressBech32(consts.HRP, aother)
+		aotherStr := consts.FormatAddress(aother)
 
 		ginkgo.By("issue Transfer to the first node", func() {
 			// Generate transaction
@@ -0,0 +1,340 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package faucet_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/api/metrics"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/crypto/ed25519"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/auth"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/challenge"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/cmd/morpheus-faucet/config"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/cmd/morpheus-faucet/manager"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/controller"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/requester"
+	"github.com/ava-labs/hypersdk/rpc"
+	"github.com/ava-labs/hypersdk/server"
+	"github.com/ava-labs/hypersdk/vm"
+
+	frpc "github.com/ava-labs/hypersdk/examples/morpheusvm/cmd/morpheus-faucet/rpc"
+	lconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	lrpc "github.com/ava-labs/hypersdk/examples/morpheusvm/rpc"
+)
+
+const (
+	faucetBalance = 10_000_000
+	dripAmount    = 1_000_000
+)
+
+type testVM struct {
+	vm       *vm.VM
+	toEngine chan common.Message
+	server   *httptest.Server
+	lcli     *lrpc.JSONRPCClient
+}
+
+// newTestVM starts an embedded MorpheusVM (serving both the core and
+// MorpheusVM APIs from a single URI) that allocates [faucetBalance] to [addr].
+func newTestVM(t *testing.T, addr codec.Address) *testVM {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	gen := genesis.Default()
+	gen.MinUnitPrice = fees.Dimensions{1, 1, 1, 1, 1}
+	gen.MinBlockGap = 0
+	gen.CustomAllocation = []*genesis.CustomAllocation{
+		{
+			Address: lconsts.FormatAddress(addr),
+			Balance: faucetBalance,
+		},
+	}
+	genesisBytes, err := json.Marshal(gen)
+	require.NoError(err)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	snowCtx := &snow.Context{
+		NetworkID:      1,
+		SubnetID:       ids.GenerateTestID(),
+		ChainID:        ids.GenerateTestID(),
+		NodeID:         ids.GenerateTestNodeID(),
+		Log:            logging.NoLog{},
+		ChainDataDir:   t.TempDir(),
+		Metrics:        metrics.NewOptionalGatherer(),
+		PublicKey:      bls.PublicFromSecretKey(sk),
+		ValidatorState: &validators.TestState{},
+	}
+	toEngine := make(chan common.Message, 1)
+	v := controller.New()
+	require.NoError(v.Initialize(
+		ctx,
+		snowCtx,
+		memdb.New(),
+		genesisBytes,
+		nil,
+		[]byte(`{"testMode":true}`),
+		toEngine,
+		nil,
+		&appSender{},
+	))
+	t.Cleanup(func() {
+		require.NoError(v.Shutdown(context.TODO()))
+	})
+	handlers, err := v.CreateHandlers(ctx)
+	require.NoError(err)
+	mux := http.NewServeMux()
+	mux.Handle(rpc.JSONRPCEndpoint, handlers[rpc.JSONRPCEndpoint])
+	mux.Handle(lrpc.JSONRPCEndpoint, handlers[lrpc.JSONRPCEndpoint])
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	v.ForceReady()
+
+	return &testVM{
+		vm:       v,
+		toEngine: toEngine,
+		server:   srv,
+		lcli:     lrpc.NewJSONRPCClient(srv.URL, snowCtx.NetworkID, snowCtx.ChainID),
+	}
+}
+
+// acceptBlock builds and accepts a block with all transactions in the mempool.
+func (tvm *testVM) acceptBlock(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	require.NoError(tvm.vm.Builder().Force(ctx))
+	<-tvm.toEngine
+	blk, err := tvm.vm.BuildBlock(ctx)
+	require.NoError(err)
+	require.NoError(blk.Verify(ctx))
+	require.NoError(tvm.vm.SetPreference(ctx, blk.ID()))
+	require.NoError(blk.Accept(ctx))
+	require.Equal(choices.Accepted, blk.Status())
+}
+
+func newTestFaucet(t *testing.T, c *config.Config) (*manager.Manager, *frpc.JSONRPCClient) {
+	require := require.New(t)
+
+	m, err := manager.New(logging.NoLog{}, c)
+	require.NoError(err)
+	handler, err := server.NewHandler(frpc.NewJSONRPCServer(m), "faucet")
+	require.NoError(err)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return m, frpc.NewJSONRPCClient(srv.URL)
+}
+
+func newRecipient(t *testing.T) codec.Address {
+	priv, err := ed25519.GeneratePrivateKey()
+	require.NoError(t, err)
+	return auth.NewED25519Address(priv.PublicKey())
+}
+
+// requireRPCError ensures [err] was returned by the faucet because of [expected].
+func requireRPCError(t *testing.T, err error, expected error) {
+	require.True(t, requester.IsRejected(err))
+	require.Contains(t, err.Error(), expected.Error())
+}
+
+func TestFaucet(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	priv, err := ed25519.GeneratePrivateKey()
+	require.NoError(err)
+	tvm := newTestVM(t, auth.NewED25519Address(priv.PublicKey()))
+	c := &config.Config{
+		PrivateKeyBytes:       priv[:],
+		MorpheusRPC:           tvm.server.URL,
+		Amount:                dripAmount,
+		StorePath:             t.TempDir(),
+		AddressCooldown:       3_600,
+		IPCooldown:            3_600,
+		StartDifficulty:       4,
+		SolutionsPerSalt:      2,
+		TargetDurationPerSalt: 300,
+		MaxSubmitAttempts:     3,
+		FeeBumpPercent:        10,
+	}
+	m, fcli := newTestFaucet(t, c)
+
+	faucetAddr, err := fcli.FaucetAddress(ctx)
+	require.NoError(err)
+	require.Equal(c.AddressBech32(), faucetAddr)
+
+	// Request funds with a valid solution
+	salt, difficulty, err := fcli.Challenge(ctx)
+	require.NoError(err)
+	require.Equal(c.StartDifficulty, difficulty)
+	solution, _ := challenge.Search(salt, difficulty, 1)
+	recipient := newRecipient(t)
+	txID, amount, err := fcli.Request(ctx, lconsts.FormatAddress(recipient), salt, solution)
+	require.NoError(err)
+	require.Equal(uint64(dripAmount), amount)
+	tvm.acceptBlock(t)
+	success, _, err := tvm.lcli.WaitForTransaction(ctx, txID)
+	require.NoError(err)
+	require.True(success)
+	balance, err := tvm.lcli.Balance(ctx, lconsts.FormatAddress(recipient))
+	require.NoError(err)
+	require.Equal(uint64(dripAmount), balance)
+
+	// The same address and IP must wait for their cooldowns
+	_, _, err = fcli.Request(ctx, lconsts.FormatAddress(recipient), salt, solution)
+	requireRPCError(t, err, manager.ErrAddressCooldown)
+	recipient2 := newRecipient(t)
+	_, _, err = fcli.Request(ctx, lconsts.FormatAddress(recipient2), salt, solution)
+	requireRPCError(t, err, manager.ErrIPCooldown)
+
+	// Solutions must be valid and can only be used once
+	_, _, err = m.Request(ctx, "10.0.0.1", recipient2, salt, solution)
+	require.ErrorIs(err, manager.ErrDuplicateSolution)
+	invalid := []byte{0}
+	for challenge.Verify(salt, invalid, difficulty) {
+		invalid[0]++
+	}
+	_, _, err = m.Request(ctx, "10.0.0.1", recipient2, salt, invalid)
+	require.ErrorIs(err, manager.ErrInvalidSolution)
+
+	// Reaching [SolutionsPerSalt] increases the difficulty and rotates the salt
+	solution2, _ := challenge.Search(salt, difficulty, 1)
+	_, _, err = m.Request(ctx, "10.0.0.1", recipient2, salt, solution2)
+	require.NoError(err)
+	tvm.acceptBlock(t)
+	newSalt, newDifficulty, err := fcli.Challenge(ctx)
+	require.NoError(err)
+	require.NotEqual(salt, newSalt)
+	require.Equal(difficulty+1, newDifficulty)
+	solution3, _ := challenge.Search(salt, difficulty, 1)
+	_, _, err = m.Request(ctx, "10.0.0.2", newRecipient(t), salt, solution3)
+	require.ErrorIs(err, manager.ErrSaltExpired)
+
+	// Drips are recorded in metrics
+	families, err := m.Registry().Gather()
+	require.NoError(err)
+	recorded := map[string]float64{}
+	for _, family := range families {
+		metric := family.GetMetric()[0]
+		if family.GetType().String() == "COUNTER" {
+			recorded[family.GetName()] = metric.GetCounter().GetValue()
+		} else {
+			recorded[family.GetName()] = metric.GetGauge().GetValue()
+		}
+	}
+	require.Equal(float64(2), recorded["faucet_drips"])
+	require.Equal(float64(2*dripAmount), recorded["faucet_dripped"])
+	require.Equal(float64(2), recorded["faucet_cooldowns"])
+	require.Equal(float64(3), recorded["faucet_bad_solutions"])
+	require.Equal(float64(newDifficulty), recorded["faucet_difficulty"])
+
+	// Cooldowns persist across restarts
+	require.NoError(m.Close())
+	m, _ = newTestFaucet(t, c)
+	defer func() {
+		require.NoError(m.Close())
+	}()
+	_, _, err = m.Request(ctx, "10.0.0.3", recipient, nil, nil)
+	require.ErrorIs(err, manager.ErrAddressCooldown)
+}
+
+func TestFaucetWithoutSolution(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	priv, err := ed25519.GeneratePrivateKey()
+	require.NoError(err)
+	tvm := newTestVM(t, auth.NewED25519Address(priv.PublicKey()))
+	m, fcli := newTestFaucet(t, &config.Config{
+		PrivateKeyBytes:   priv[:],
+		MorpheusRPC:       tvm.server.URL,
+		Amount:            dripAmount,
+		StorePath:         t.TempDir(),
+		MaxSubmitAttempts: 2,
+		FeeBumpPercent:    10,
+	})
+	defer func() {
+		require.NoError(m.Close())
+	}()
+
+	// No proof-of-work or cooldowns are required
+	_, difficulty, err := fcli.Challenge(ctx)
+	require.NoError(err)
+	require.Zero(difficulty)
+
+	// Repeat requests for the same address (which produce an identical
+	// transaction if issued within the same second) are rebuilt with a bumped
+	// fee when the node rejects them as duplicates
+	recipient := newRecipient(t)
+	txID, _, err := fcli.Request(ctx, lconsts.FormatAddress(recipient), nil, nil)
+	require.NoError(err)
+	tvm.acceptBlock(t)
+	txID2, _, err := fcli.Request(ctx, lconsts.FormatAddress(recipient), nil, nil)
+	require.NoError(err)
+	require.NotEqual(txID, txID2)
+	tvm.acceptBlock(t)
+	balance, err := tvm.lcli.Balance(ctx, lconsts.FormatAddress(recipient))
+	require.NoError(err)
+	require.Equal(uint64(2*dripAmount), balance)
+
+	// Requests fail once the faucet can't cover the transfer and fee
+	for {
+		_, _, err = m.Request(ctx, "10.0.0.1", newRecipient(t), nil, nil)
+		if err != nil {
+			break
+		}
+		tvm.acceptBlock(t)
+	}
+	require.ErrorIs(err, manager.ErrInsufficientBalance)
+}
+
+var _ common.AppSender = (*appSender)(nil)
+
+type appSender struct{}
+
+func (*appSender) SendAppGossip(context.Context, common.SendConfig, []byte) error {
+	return nil
+}
+
+func (*appSender) SendAppRequest(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendAppError(context.Context, ids.NodeID, uint32, int32, string) error {
+	return nil
+}
+
+func (*appSender) SendAppResponse(context.Context, ids.NodeID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppRequest(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppError(context.Context, ids.ID, uint32, int32, string) error {
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
@@ -41,6 +42,7 @@ import (
 	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/pubsub"
+	"github.com/ava-labs/hypersdk/replay"
 	"github.com/ava-labs/hypersdk/rpc"
 	"github.com/ava-labs/hypersdk/vm"
 
@@ -58,6 +60,11 @@ var (
 	requestTimeout time.Duration
 	vms            int
 
+	// record/replay of the events delivered to embedded VMs
+	recordPath string
+	replayPath string
+	sched      *replay.Scheduler
+
 	priv    ed25519.PrivateKey
 	pk      ed25519.PublicKey
 	factory *auth.ED25519Factory
@@ -113,9 +120,22 @@ func init() {
 		3,
 		"number of VMs to create",
 	)
+	flag.StringVar(
+		&recordPath,
+		"replay-record",
+		"",
+		"file to record the order of delivered events to",
+	)
+	flag.StringVar(
+		&replayPath,
+		"replay",
+		"",
+		"file of recorded events to deliver in the same order",
+	)
 }
 
 type instance struct {
+	name              string // stable across runs (used to record events)
 	chainID           ids.ID
 	nodeID            ids.NodeID
 	vm                *vm.VM
@@ -139,7 +159,7 @@ var _ = ginkgo.BeforeSuite(func() {
 	pk = priv.PublicKey()
 	factory = auth.NewED25519Factory(priv)
 	addr = auth.NewED25519Address(pk)
-	addrStr = codec.MustAddressBech32(lconsts.HRP, addr)
+	addrStr = lconsts.FormatAddress(addr)
 	log.Debug(
 		"generated key",
 		zap.String("addr", addrStr),
@@ -151,7 +171,7 @@ var _ = ginkgo.BeforeSuite(func() {
 	pk2 = priv2.PublicKey()
 	factory2 = auth.NewED25519Factory(priv2)
 	addr2 = auth.NewED25519Address(pk2)
-	addrStr2 = codec.MustAddressBech32(lconsts.HRP, addr2)
+	addrStr2 = lconsts.FormatAddress(addr2)
 	log.Debug(
 		"generated key",
 		zap.String("addr", addrStr2),
@@ -163,7 +183,7 @@ var _ = ginkgo.BeforeSuite(func() {
 	pk3 = priv3.PublicKey()
 	factory3 = auth.NewED25519Factory(priv3)
 	addr3 = auth.NewED25519Address(pk3)
-	addrStr3 = codec.MustAddressBech32(lconsts.HRP, addr3)
+	addrStr3 = lconsts.FormatAddress(addr3)
 	log.Debug(
 		"generated key",
 		zap.String("addr", addrStr3),
@@ -237,6 +257,7 @@ var _ = ginkgo.BeforeSuite(func() {
 		ljsonRPCServer := httptest.NewServer(hd[lrpc.JSONRPCEndpoint])
 		webSocketServer := httptest.NewServer(hd[rpc.WebSocketEndpoint])
 		instances[i] = instance{
+			name:              fmt.Sprintf("node-%d", i),
 			chainID:           snowCtx.ChainID,
 			nodeID:            snowCtx.NodeID,
 			vm:                v,
@@ -272,6 +293,18 @@ var _ = ginkgo.BeforeSuite(func() {
 
 	app.instances = instances
 	color.Blue("created %d VMs", vms)
+
+	switch {
+	case len(replayPath) > 0:
+		l, err := replay.Load(replayPath)
+		require.NoError(err)
+		sched = replay.NewReplayer(l)
+		color.Blue("replaying %d events from %s", len(l), replayPath)
+	case len(recordPath) > 0:
+		sched = replay.NewRecorder(0)
+	default:
+		sched = replay.NewScheduler()
+	}
 })
 
 var _ = ginkgo.AfterSuite(func() {
@@ -284,6 +317,14 @@ var _ = ginkgo.AfterSuite(func() {
 		err := iv.vm.Shutdown(context.TODO())
 		require.NoError(err)
 	}
+
+	switch sched.Mode() {
+	case replay.Record:
+		require.NoError(sched.Log().Save(recordPath))
+		color.Blue("recorded %d events to %s", len(sched.Log()), recordPath)
+	case replay.Replay:
+		require.True(sched.Done(), "recorded events were not delivered")
+	}
 })
 
 var _ = ginkgo.Describe("[Ping]", func() {
@@ -416,6 +457,14 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			<-instances[1].toEngine
 		})
 
+		ginkgo.By("preview block in the node 1", func() {
+			preview, err := instances[1].vm.BuildPreview(context.TODO())
+			require.NoError(err)
+			require.Len(preview.Txs, 1)
+			require.Equal(transferTxRoot.ID(), preview.Txs[0].ID)
+			require.Equal(1, instances[1].vm.Mempool().Len(context.TODO()))
+		})
+
 		ginkgo.By("build block in the node 1", func() {
 			ctx := context.TODO()
 			blk, err := instances[1].vm.BuildBlock(ctx)
@@ -438,9 +487,10 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			results := blk.(*chain.StatelessBlock).Results()
 			require.Len(results, 1)
 			require.True(results[0].Success)
-			require.Len(results[0].Outputs[0], 0)
+			require.Len(results[0].Outputs[0], 1)
 			require.Equal(results[0].Units, transferTxUnits)
 			require.Equal(results[0].Fee, transferTxFee)
+			require.Equal(vm.NodeAddress(instances[1].nodeID), results[0].Builder)
 		})
 
 		ginkgo.By("ensure balance is updated", func() {
@@ -450,6 +500,13 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			balance2, err := instances[1].lcli.Balance(context.Background(), addrStr2)
 			require.NoError(err)
 			require.Equal(balance2, uint64(100_000))
+
+			// The transfer output contains the balances after execution
+			results := blocks[len(blocks)-1].(*chain.StatelessBlock).Results()
+			tr, err := actions.UnmarshalTransferResult(results[0].Outputs[0][0])
+			require.NoError(err)
+			require.Equal(balance, tr.SenderBalance)
+			require.Equal(balance2, tr.ReceiverBalance)
 		})
 	})
 
@@ -457,7 +514,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 		ginkgo.By("transfer funds again", func() {
 			parser, err := instances[1].lcli.Parser(context.Background())
 			require.NoError(err)
-			submit, _, _, err := instances[1].cli.GenerateTransaction(
+			submit, tx, _, err := instances[1].cli.GenerateTransaction(
 				context.Background(),
 				parser,
 				[]chain.Action{&actions.Transfer{
@@ -467,6 +524,8 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 				factory,
 			)
 			require.NoError(err)
+			minFee, err := chain.MinFee(context.Background(), instances[1].vm, tx)
+			require.NoError(err)
 			require.NoError(submit(context.Background()))
 			accept := expectBlk(instances[1])
 			results := accept(true)
@@ -474,6 +533,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			require.True(results[0].Success)
 			require.Equal(results[0].Units, transferTxUnits)
 			require.Equal(results[0].Fee, transferTxFee)
+			require.Equal(results[0].Fee, minFee)
 
 			balance2, err := instances[1].lcli.Balance(context.Background(), addrStr2)
 			require.NoError(err)
@@ -634,6 +694,12 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			err = blk1.Verify(ctx)
 			require.NoError(err)
 
+			// The header doesn't carry the builder (builders aren't
+			// restricted), so it isn't credited in the results
+			results := blk1.(*chain.StatelessBlock).Results()
+			require.Len(results, 1)
+			require.Equal(codec.EmptyAddress, results[0].Builder)
+
 			// Parse tip
 			blk2, err := n.vm.ParseBlock(ctx, blocks[1].Bytes())
 			require.NoError(err)
@@ -708,7 +774,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 		require.True(results[0].Success)
 
 		// Read item from connection
-		blk, lresults, prices, err := cli.ListenBlock(context.TODO(), parser)
+		blk, _, lresults, prices, err := cli.ListenBlock(context.TODO(), parser)
 		require.NoError(err)
 		require.Len(blk.Txs, 1)
 		tx := blk.Txs[0].Actions[0].(*actions.Transfer)
@@ -803,7 +869,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			require.Len(results, 1)
 			require.True(results[0].Success)
 
-			balance, err := instances[0].lcli.Balance(context.TODO(), codec.MustAddressBech32(lconsts.HRP, r1addr))
+			balance, err := instances[0].lcli.Balance(context.TODO(), lconsts.FormatAddress(r1addr))
 			require.NoError(err)
 			require.Equal(balance, uint64(2000))
 		})
@@ -855,13 +921,13 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			require.Len(results, 1)
 			require.True(results[0].Success)
 
-			balance, err := instances[0].lcli.Balance(context.TODO(), codec.MustAddressBech32(lconsts.HRP, r1addr))
+			balance, err := instances[0].lcli.Balance(context.TODO(), lconsts.FormatAddress(r1addr))
 			require.NoError(err)
 			require.Equal(balance, uint64(2000))
 		})
 
 		ginkgo.By("send back to ed25519 (in separate actions)", func() {
-			bbalance, err := instances[0].lcli.Balance(context.TODO(), codec.MustAddressBech32(lconsts.HRP, addr))
+			bbalance, err := instances[0].lcli.Balance(context.TODO(), lconsts.FormatAddress(addr))
 			require.NoError(err)
 
 			parser, err := instances[0].lcli.Parser(context.Background())
@@ -888,7 +954,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			require.Len(results, 1)
 			require.True(results[0].Success)
 
-			balance, err := instances[0].lcli.Balance(context.TODO(), codec.MustAddressBech32(lconsts.HRP, addr))
+			balance, err := instances[0].lcli.Balance(context.TODO(), lconsts.FormatAddress(addr))
 			require.NoError(err)
 			require.Equal(balance, bbalance+100)
 		})
@@ -936,22 +1002,29 @@ func expectBlk(i instance) func(bool) []*chain.Result {
 	ctx := context.TODO()
 
 	// manually signal ready
-	require.NoError(i.vm.Builder().Force(ctx))
+	require.NoError(sched.Do(replay.Timer, i.name, "build", func() error {
+		return i.vm.Builder().Force(ctx)
+	}))
 	// manually ack ready sig as in engine
 	<-i.toEngine
 
 	blk, err := i.vm.BuildBlock(ctx)
 	require.NoError(err)
 	require.NotNil(blk)
+	height := strconv.FormatUint(blk.Height(), 10)
 
-	require.NoError(blk.Verify(ctx))
+	require.NoError(sched.Do(replay.Block, i.name, height, func() error {
+		return blk.Verify(ctx)
+	}))
 	require.Equal(blk.Status(), choices.Processing)
 
 	err = i.vm.SetPreference(ctx, blk.ID())
 	require.NoError(err)
 
 	return func(add bool) []*chain.Result {
-		require.NoError(blk.Accept(ctx))
+		require.NoError(sched.Do(replay.Accept, i.name, height, func() error {
+			return blk.Accept(ctx)
+		}))
 		require.Equal(blk.Status(), choices.Accepted)
 
 		if add {
@@ -969,6 +1042,7 @@ var _ common.AppSender = &appSender{}
 
 type appSender struct {
 	next      int
+	sent      int
 	instances []instance
 }
 
@@ -977,7 +1051,11 @@ func (app *appSender) SendAppGossip(ctx context.Context, _ common.SendConfig, ap
 	sender := app.instances[app.next].nodeID
 	app.next++
 	app.next %= n
-	return app.instances[app.next].vm.AppGossip(ctx, sender, appGossipBytes)
+	app.sent++
+	receiver := app.instances[app.next]
+	return sched.Do(replay.Gossip, receiver.name, strconv.Itoa(app.sent), func() error {
+		return receiver.vm.AppGossip(ctx, sender, appGossipBytes)
+	})
 }
 
 func (*appSender) SendAppRequest(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
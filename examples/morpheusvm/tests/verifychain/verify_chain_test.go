@@ -0,0 +1,266 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package verifychain_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/api/metrics"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/crypto/ed25519"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/actions"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/auth"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/controller"
+	"github.com/ava-labs/hypersdk/examples/morpheusvm/genesis"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/rpc"
+	"github.com/ava-labs/hypersdk/vm"
+
+	lconsts "github.com/ava-labs/hypersdk/examples/morpheusvm/consts"
+	lrpc "github.com/ava-labs/hypersdk/examples/morpheusvm/rpc"
+)
+
+const chainLength = 10
+
+type testNetwork struct {
+	networkID    uint32
+	subnetID     ids.ID
+	chainID      ids.ID
+	genesisBytes []byte
+}
+
+func newTestNetwork(t *testing.T, allocations []*genesis.CustomAllocation) *testNetwork {
+	gen := genesis.Default()
+	gen.MinUnitPrice = fees.Dimensions{1, 1, 1, 1, 1}
+	gen.MinBlockGap = 0
+	gen.CustomAllocation = allocations
+	genesisBytes, err := json.Marshal(gen)
+	require.NoError(t, err)
+	return &testNetwork{
+		networkID:    1,
+		subnetID:     ids.GenerateTestID(),
+		chainID:      ids.GenerateTestID(),
+		genesisBytes: genesisBytes,
+	}
+}
+
+// newVM starts an embedded MorpheusVM on [n] that has only accepted genesis.
+func (n *testNetwork) newVM(t *testing.T) (*vm.VM, chan common.Message) {
+	require := require.New(t)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	snowCtx := &snow.Context{
+		NetworkID:      n.networkID,
+		SubnetID:       n.subnetID,
+		ChainID:        n.chainID,
+		NodeID:         ids.GenerateTestNodeID(),
+		Log:            logging.NoLog{},
+		ChainDataDir:   t.TempDir(),
+		Metrics:        metrics.NewOptionalGatherer(),
+		PublicKey:      bls.PublicFromSecretKey(sk),
+		ValidatorState: &validators.TestState{},
+	}
+	toEngine := make(chan common.Message, 1)
+	v := controller.New()
+	require.NoError(v.Initialize(
+		context.TODO(),
+		snowCtx,
+		memdb.New(),
+		n.genesisBytes,
+		nil,
+		[]byte(`{"testMode":true}`),
+		toEngine,
+		nil,
+		&appSender{},
+	))
+	t.Cleanup(func() {
+		require.NoError(v.Shutdown(context.TODO()))
+	})
+	v.ForceReady()
+	return v, toEngine
+}
+
+// buildChain builds and accepts [chainLength] blocks (each containing a single
+// transfer) and returns their bytes.
+func buildChain(t *testing.T, n *testNetwork, factory chain.AuthFactory) [][]byte {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	v, toEngine := n.newVM(t)
+	handlers, err := v.CreateHandlers(ctx)
+	require.NoError(err)
+	srv := httptest.NewServer(handlers[rpc.JSONRPCEndpoint])
+	t.Cleanup(srv.Close)
+	cli := rpc.NewJSONRPCClient(srv.URL)
+	lsrv := httptest.NewServer(handlers[lrpc.JSONRPCEndpoint])
+	t.Cleanup(lsrv.Close)
+	parser, err := lrpc.NewJSONRPCClient(lsrv.URL, n.networkID, n.chainID).Parser(ctx)
+	require.NoError(err)
+
+	priv, err := ed25519.GeneratePrivateKey()
+	require.NoError(err)
+	recipient := auth.NewED25519Address(priv.PublicKey())
+
+	chainBytes := make([][]byte, 0, chainLength)
+	for i := 0; i < chainLength; i++ {
+		submit, _, _, err := cli.GenerateTransaction(
+			ctx,
+			parser,
+			[]chain.Action{&actions.Transfer{
+				To:    recipient,
+				Value: uint64(i + 1), // ensure txs are unique
+			}},
+			factory,
+		)
+		require.NoError(err)
+		require.NoError(submit(ctx))
+
+		require.NoError(v.Builder().Force(ctx))
+		<-toEngine
+		blk, err := v.BuildBlock(ctx)
+		require.NoError(err)
+		require.NoError(blk.Verify(ctx))
+		require.NoError(v.SetPreference(ctx, blk.ID()))
+		require.NoError(blk.Accept(ctx))
+		chainBytes = append(chainBytes, blk.Bytes())
+	}
+	return chainBytes
+}
+
+func parseChain(t *testing.T, v *vm.VM, chainBytes [][]byte) []*chain.StatelessBlock {
+	blks := make([]*chain.StatelessBlock, 0, len(chainBytes))
+	for _, raw := range chainBytes {
+		blk, err := v.ParseBlock(context.TODO(), raw)
+		require.NoError(t, err)
+		blks = append(blks, blk.(*chain.StatelessBlock))
+	}
+	return blks
+}
+
+func TestVerifyChain(t *testing.T) {
+	ctx := context.TODO()
+
+	priv, err := ed25519.GeneratePrivateKey()
+	require.NoError(t, err)
+	n := newTestNetwork(t, []*genesis.CustomAllocation{
+		{
+			Address: lconsts.FormatAddress(auth.NewED25519Address(priv.PublicKey())),
+			Balance: 10_000_000,
+		},
+	})
+	chainBytes := buildChain(t, n, auth.NewED25519Factory(priv))
+
+	t.Run("valid chain", func(t *testing.T) {
+		require := require.New(t)
+
+		v, _ := n.newVM(t)
+		blks := parseChain(t, v, chainBytes)
+
+		// Blocks are verified in height order regardless of the order provided
+		shuffled := slices.Clone(blks)
+		slices.Reverse(shuffled)
+		require.NoError(chain.VerifyChain(ctx, v, shuffled))
+		for _, blk := range blks {
+			require.True(blk.Processed())
+			stored, err := v.GetStatelessBlock(ctx, blk.ID())
+			require.NoError(err)
+			require.Equal(blk.ID(), stored.ID())
+		}
+
+		// The verified chain can be accepted
+		for _, blk := range blks {
+			require.NoError(blk.Accept(ctx))
+		}
+		lastAccepted, err := v.LastAccepted(ctx)
+		require.NoError(err)
+		require.Equal(blks[chainLength-1].ID(), lastAccepted)
+	})
+
+	t.Run("bad state root", func(t *testing.T) {
+		require := require.New(t)
+
+		v, _ := n.newVM(t)
+		blks := parseChain(t, v, chainBytes)
+
+		// Corrupt the state root of the 5th block
+		bad := *blks[4].StatefulBlock
+		bad.StateRoot = ids.GenerateTestID()
+		badBytes, err := bad.Marshal(v)
+		require.NoError(err)
+		badBlk, err := v.ParseBlock(ctx, badBytes)
+		require.NoError(err)
+		blks[4] = badBlk.(*chain.StatelessBlock)
+
+		err = chain.VerifyChain(ctx, v, blks)
+		require.ErrorIs(err, chain.ErrStateRootMismatch)
+		require.Contains(err.Error(), "height=5")
+
+		// Ancestors of the failing block are still verified
+		for _, blk := range blks[:4] {
+			require.True(blk.Processed())
+		}
+		for _, blk := range blks[4:] {
+			require.False(blk.Processed())
+		}
+	})
+
+	t.Run("missing block", func(t *testing.T) {
+		require := require.New(t)
+
+		v, _ := n.newVM(t)
+		blks := parseChain(t, v, chainBytes)
+		blks = slices.Delete(blks, 4, 5)
+
+		err := chain.VerifyChain(ctx, v, blks)
+		require.ErrorIs(err, chain.ErrInvalidBlockHeight)
+		require.Contains(err.Error(), "height=6")
+	})
+}
+
+var _ common.AppSender = &appSender{}
+
+type appSender struct{}
+
+func (*appSender) SendAppGossip(context.Context, common.SendConfig, []byte) error {
+	return nil
+}
+
+func (*appSender) SendAppRequest(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendAppError(context.Context, ids.NodeID, uint32, int32, string) error {
+	return nil
+}
+
+func (*appSender) SendAppResponse(context.Context, ids.NodeID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppRequest(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppError(context.Context, ids.ID, uint32, int32, string) error {
+	return nil
+}
@@ -78,9 +78,9 @@ func (c *CreateAsset) Size() int {
 }
 
 func (c *CreateAsset) Marshal(p *codec.Packer) {
-	p.PackBytes(c.Symbol)
+	p.PackLimitedBytes(c.Symbol, MaxSymbolSize)
 	p.PackByte(c.Decimals)
-	p.PackBytes(c.Metadata)
+	p.PackLimitedBytes(c.Metadata, MaxMetadataSize)
 }
 
 func UnmarshalCreateAsset(p *codec.Packer) (chain.Action, error) {
@@ -15,7 +15,7 @@ import (
 	"github.com/ava-labs/hypersdk/state"
 )
 
-var _ chain.Action = (*Transfer)(nil)
+var _ chain.ValueSpender = (*Transfer)(nil)
 
 type Transfer struct {
 	// To is the recipient of the [Value].
@@ -45,6 +45,15 @@ func (t *Transfer) StateKeys(actor codec.Address, _ ids.ID) state.Keys {
 	}
 }
 
+// ValueSpent implements [chain.ValueSpender]. Only transfers of the native
+// asset (which is used to pay fees) are counted.
+func (t *Transfer) ValueSpent() uint64 {
+	if t.Asset != ids.Empty {
+		return 0
+	}
+	return t.Value
+}
+
 func (*Transfer) StateKeysMaxChunks() []uint16 {
 	return []uint16{storage.BalanceChunks, storage.BalanceChunks}
 }
@@ -85,7 +94,7 @@ func (t *Transfer) Marshal(p *codec.Packer) {
 	p.PackAddress(t.To)
 	p.PackID(t.Asset)
 	p.PackUint64(t.Value)
-	p.PackBytes(t.Memo)
+	p.PackLimitedBytes(t.Memo, MaxMemoSize)
 }
 
 func UnmarshalTransfer(p *codec.Packer) (chain.Action, error) {
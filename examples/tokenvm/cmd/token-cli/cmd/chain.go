@@ -63,6 +63,19 @@ var chainInfoCmd = &cobra.Command{
 	},
 }
 
+var compareChainCmd = &cobra.Command{
+	Use: "compare [peer-rpc-url]",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ErrInvalidArgs
+		}
+		return nil
+	},
+	RunE: func(_ *cobra.Command, args []string) error {
+		return handler.Root().CompareChain(args[0])
+	},
+}
+
 var watchChainCmd = &cobra.Command{
 	Use: "watch",
 	RunE: func(_ *cobra.Command, args []string) error {
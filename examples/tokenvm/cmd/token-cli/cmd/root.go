@@ -148,6 +148,7 @@ func init() {
 		setChainCmd,
 		chainInfoCmd,
 		watchChainCmd,
+		compareChainCmd,
 	)
 
 	// actions
@@ -37,6 +37,7 @@ func (cli *JSONRPCClient) FaucetAddress(ctx context.Context) (string, error) {
 		"faucetAddress",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.Address, err
 }
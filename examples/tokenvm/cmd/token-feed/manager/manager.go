@@ -125,14 +125,14 @@ func (m *Manager) Run(ctx context.Context) error {
 		}
 		for ctx.Err() == nil {
 			// Listen for blocks
-			blk, results, _, err := scli.ListenBlock(ctx, parser)
+			blk, txs, results, _, err := scli.ListenBlock(ctx, parser)
 			if err != nil {
 				m.log.Warn("unable to listen for blocks", zap.Error(err))
 				break
 			}
 
 			// Look for transactions to recipient
-			for i, tx := range blk.Txs {
+			for i, tx := range txs {
 				for _, act := range tx.Actions {
 					action, ok := act.(*actions.Transfer)
 					if !ok {
@@ -36,6 +36,7 @@ func (cli *JSONRPCClient) FeedInfo(ctx context.Context) (string, uint64, error)
 		"feedInfo",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.Address, resp.Fee, err
 }
@@ -47,6 +48,7 @@ func (cli *JSONRPCClient) Feed(ctx context.Context) ([]*manager.FeedObject, erro
 		"feed",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.Feed, err
 }
@@ -193,7 +193,7 @@ func (b *Backend) collectBlocks() {
 		tpsWindow = window.Window{}
 	)
 	for b.ctx.Err() == nil {
-		blk, results, prices, err := b.scli.ListenBlock(b.ctx, b.parser)
+		blk, txs, results, prices, err := b.scli.ListenBlock(b.ctx, b.parser)
 		if err != nil {
 			b.fatal(err)
 			return
@@ -208,7 +208,7 @@ func (b *Backend) collectBlocks() {
 			}
 			consumed = nconsumed
 
-			tx := blk.Txs[i]
+			tx := txs[i]
 			actor := tx.Auth.Actor()
 			if !result.Success {
 				failTxs++
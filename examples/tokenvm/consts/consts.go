@@ -34,4 +34,8 @@ func init() {
 var (
 	ActionRegistry *codec.TypeParser[chain.Action, bool]
 	AuthRegistry   *codec.TypeParser[chain.Auth, bool]
+
+	// ActionVectors are the conformance vectors of the actions in
+	// [ActionRegistry].
+	ActionVectors *chain.ActionVectorRegistry
 )
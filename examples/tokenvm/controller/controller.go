@@ -163,7 +163,7 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 	defer batch.Reset()
 
 	results := blk.Results()
-	for i, tx := range blk.Txs {
+	for i, tx := range blk.ExecutedTxs() {
 		result := results[i]
 		if c.config.GetStoreTransactions() {
 			err := storage.StoreTransaction(
@@ -30,6 +30,30 @@ func (*StateManager) FeeKey() []byte {
 	return storage.FeeKey()
 }
 
+func (*StateManager) MemoKey(txID ids.ID) []byte {
+	return storage.MemoKey(txID)
+}
+
+func (*StateManager) TaskQueueKey() []byte {
+	return storage.TaskQueueKey()
+}
+
+func (*StateManager) TaskKey(taskID ids.ID) []byte {
+	return storage.TaskKey(taskID)
+}
+
+func (*StateManager) ACLKey(actionTypeID uint8, addr codec.Address) []byte {
+	return storage.ACLKey(actionTypeID, addr)
+}
+
+func (*StateManager) EpochKey(epoch uint64) []byte {
+	return storage.EpochKey(epoch)
+}
+
+func (*StateManager) IdempotencyKey(id ids.ID) []byte {
+	return storage.IdempotencyKey(id)
+}
+
 func (*StateManager) SponsorStateKeys(addr codec.Address) state.Keys {
 	return state.Keys{
 		string(storage.BalanceKey(addr, ids.Empty)): state.Read | state.Write,
@@ -60,3 +84,13 @@ func (*StateManager) Deduct(
 ) error {
 	return storage.SubBalance(ctx, mu, addr, ids.Empty, amount)
 }
+
+func (*StateManager) Refund(
+	ctx context.Context,
+	addr codec.Address,
+	mu state.Mutable,
+	amount uint64,
+) error {
+	// Don't create accounts that were deleted after paying fees
+	return storage.AddBalance(ctx, mu, addr, ids.Empty, amount, false)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
+	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/examples/tokenvm/consts"
 	"github.com/ava-labs/hypersdk/examples/tokenvm/storage"
@@ -35,19 +36,63 @@ type Genesis struct {
 	StateBranchFactor merkledb.BranchFactor `json:"stateBranchFactor"`
 
 	// Chain Parameters
-	MinBlockGap      int64 `json:"minBlockGap"`      // ms
-	MinEmptyBlockGap int64 `json:"minEmptyBlockGap"` // ms
+	MinBlockGap              int64 `json:"minBlockGap"`      // ms
+	MinEmptyBlockGap         int64 `json:"minEmptyBlockGap"` // ms
+	RestrictBuilders         bool  `json:"restrictBuilders"`
+	IncludeResultsRoot       bool  `json:"includeResultsRoot"`
+	DelayedExecution         bool  `json:"delayedExecution"`
+	PermutePendingTxs        bool  `json:"permutePendingTxs"` // requires delayedExecution
+	StateFetchRetries        uint8 `json:"stateFetchRetries"`
+	DisableParallelExecution bool  `json:"disableParallelExecution"` // parallel execution is enabled when omitted
+
+	MaxRepeatCheckDepth int `json:"maxRepeatCheckDepth"` // blocks (0 is unlimited)
+
+	// Epoch Parameters (disabled if EpochLength is 0)
+	EpochLength uint64 `json:"epochLength"` // blocks
+
+	// Signer Diversity Parameters (disabled if MinDistinctSigners is 0)
+	MinDistinctSigners    int `json:"minDistinctSigners"`
+	MinDistinctSignersTxs int `json:"minDistinctSignersTxs"` // blocks with fewer txs are exempt
+
+	// Access Control Parameters
+	RestrictedActions []uint8 `json:"restrictedActions"` // action type IDs
+	ACLAdmin          string  `json:"aclAdmin"`          // bech32 address
+
+	aclAdmin codec.Address
+
+	// Emergency Parameters (the chain is paused from PausedFrom until
+	// PausedUntil, or indefinitely if PausedUntil is 0)
+	PausedFrom  int64 `json:"pausedFrom"`  // ms (disabled if 0)
+	PausedUntil int64 `json:"pausedUntil"` // ms
+
+	// Upgrade Parameters (when each fork activates, see [chain.IsActive])
+	ForkActivations chain.ForkActivations `json:"forkActivations"` // ms
 
 	// Chain Fee Parameters
-	MinUnitPrice               fees.Dimensions `json:"minUnitPrice"`
-	UnitPriceChangeDenominator fees.Dimensions `json:"unitPriceChangeDenominator"`
-	WindowTargetUnits          fees.Dimensions `json:"windowTargetUnits"` // 10s
-	MaxBlockUnits              fees.Dimensions `json:"maxBlockUnits"`     // must be possible to reach before block too large
+	RefundPolicy               chain.RefundPolicy `json:"refundPolicy"`
+	MinUnitPrice               fees.Dimensions    `json:"minUnitPrice"`
+	UnitPriceChangeDenominator fees.Dimensions    `json:"unitPriceChangeDenominator"`
+	WindowTargetUnits          fees.Dimensions    `json:"windowTargetUnits"` // 10s
+	MaxBlockUnits              fees.Dimensions    `json:"maxBlockUnits"`     // must be possible to reach before block too large
+
+	// Pricing Parameters (PricingMode is used from PricingModeActivation,
+	// before which unit prices are computed with [fees.WindowPricing])
+	PricingMode               fees.PricingMode `json:"pricingMode"`
+	PricingModeActivation     int64            `json:"pricingModeActivation"` // ms
+	UtilizationEMADenominator uint64           `json:"utilizationEMADenominator"`
+
+	// Block Pacing Parameters (disabled if TargetBlockRate is 0)
+	TargetBlockRate            int64  `json:"targetBlockRate"` // s
+	BlockCostChangeDenominator uint64 `json:"blockCostChangeDenominator"`
+	MinBlockCost               uint64 `json:"minBlockCost"`
+	MaxBlockCost               uint64 `json:"maxBlockCost"`
 
 	// Tx Parameters
-	ValidityWindow      int64 `json:"validityWindow"` // ms
-	MaxActionsPerTx     uint8 `json:"maxActionsPerTx"`
-	MaxOutputsPerAction uint8 `json:"maxOutputsPerAction"`
+	ValidityWindow         int64  `json:"validityWindow"` // ms
+	MaxActionsPerTx        uint8  `json:"maxActionsPerTx"`
+	MaxOutputsPerAction    uint8  `json:"maxOutputsPerAction"`
+	MaxActionOutputBytes   uint64 `json:"maxActionOutputBytes"`
+	EnforceIdempotencyKeys bool   `json:"enforceIdempotencyKeys"`
 
 	// Tx Fee Parameters
 	BaseComputeUnits          uint64 `json:"baseUnits"`
@@ -68,8 +113,10 @@ func Default() *Genesis {
 		StateBranchFactor: merkledb.BranchFactor16,
 
 		// Chain Parameters
-		MinBlockGap:      100,
-		MinEmptyBlockGap: 2_500,
+		MinBlockGap:         100,
+		MinEmptyBlockGap:    2_500,
+		StateFetchRetries:   3,
+		MaxRepeatCheckDepth: 1_024,
 
 		// Chain Fee Parameters
 		MinUnitPrice:               fees.Dimensions{100, 100, 100, 100, 100},
@@ -77,10 +124,17 @@ func Default() *Genesis {
 		WindowTargetUnits:          fees.Dimensions{20_000_000, 1_000, 1_000, 1_000, 1_000},
 		MaxBlockUnits:              fees.Dimensions{1_800_000, 2_000, 2_000, 2_000, 2_000},
 
+		// Pricing Parameters
+		UtilizationEMADenominator: 8,
+
+		// Block Pacing Parameters
+		BlockCostChangeDenominator: 48,
+
 		// Tx Parameters
-		ValidityWindow:      60 * hconsts.MillisecondsPerSecond, // ms
-		MaxActionsPerTx:     16,
-		MaxOutputsPerAction: 1,
+		ValidityWindow:       60 * hconsts.MillisecondsPerSecond, // ms
+		MaxActionsPerTx:      16,
+		MaxOutputsPerAction:  1,
+		MaxActionOutputBytes: 1_024,
 
 		// Tx Fee Compute Parameters
 		BaseComputeUnits: 1,
@@ -104,6 +158,17 @@ func New(b []byte, _ []byte /* upgradeBytes */) (*Genesis, error) {
 			return nil, fmt.Errorf("failed to unmarshal config %s: %w", string(b), err)
 		}
 	}
+	// Computing the next block cost would panic without a change denominator
+	if g.TargetBlockRate > 0 && g.BlockCostChangeDenominator == 0 {
+		return nil, fmt.Errorf("%w: blockCostChangeDenominator is 0 but targetBlockRate is set", ErrInvalidFeeParameters)
+	}
+	if len(g.ACLAdmin) > 0 {
+		admin, err := codec.ParseAddressBech32(consts.HRP, g.ACLAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, g.ACLAdmin)
+		}
+		g.aclAdmin = admin
+	}
 	return g, nil
 }
 
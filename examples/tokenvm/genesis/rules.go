@@ -4,9 +4,12 @@
 package genesis
 
 import (
+	"slices"
+
 	"github.com/ava-labs/avalanchego/ids"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/examples/tokenvm/storage"
 	"github.com/ava-labs/hypersdk/fees"
 )
@@ -16,13 +19,14 @@ var _ chain.Rules = (*Rules)(nil)
 type Rules struct {
 	g *Genesis
 
+	timestamp int64
 	networkID uint32
 	chainID   ids.ID
 }
 
 // TODO: use upgradeBytes
-func (g *Genesis) Rules(_ int64, networkID uint32, chainID ids.ID) *Rules {
-	return &Rules{g, networkID, chainID}
+func (g *Genesis) Rules(t int64, networkID uint32, chainID ids.ID) *Rules {
+	return &Rules{g, t, networkID, chainID}
 }
 
 func (r *Rules) NetworkID() uint32 {
@@ -45,6 +49,10 @@ func (r *Rules) GetValidityWindow() int64 {
 	return r.g.ValidityWindow
 }
 
+func (r *Rules) GetMaxRepeatCheckDepth() int {
+	return r.g.MaxRepeatCheckDepth
+}
+
 func (r *Rules) GetMaxActionsPerTx() uint8 {
 	return r.g.MaxActionsPerTx
 }
@@ -53,14 +61,97 @@ func (r *Rules) GetMaxOutputsPerAction() uint8 {
 	return r.g.MaxOutputsPerAction
 }
 
+func (r *Rules) GetMaxActionOutputBytes() uint64 {
+	return r.g.MaxActionOutputBytes
+}
+
 func (r *Rules) GetMaxBlockUnits() fees.Dimensions {
 	return r.g.MaxBlockUnits
 }
 
+func (r *Rules) GetTargetBlockRate() int64 {
+	return r.g.TargetBlockRate
+}
+
+func (r *Rules) GetBlockCostChangeDenominator() uint64 {
+	return r.g.BlockCostChangeDenominator
+}
+
+func (r *Rules) GetMinBlockCost() uint64 {
+	return r.g.MinBlockCost
+}
+
+func (r *Rules) GetMaxBlockCost() uint64 {
+	return r.g.MaxBlockCost
+}
+
 func (r *Rules) GetBaseComputeUnits() uint64 {
 	return r.g.BaseComputeUnits
 }
 
+func (r *Rules) GetRestrictBuilders() bool {
+	return r.g.RestrictBuilders
+}
+
+func (r *Rules) GetIncludeResultsRoot() bool {
+	return r.g.IncludeResultsRoot
+}
+
+func (r *Rules) GetDelayedExecution() bool {
+	return r.g.DelayedExecution
+}
+
+func (r *Rules) GetPermutePendingTxs() bool {
+	return r.g.PermutePendingTxs
+}
+
+func (r *Rules) GetStateFetchRetries() uint8 {
+	return r.g.StateFetchRetries
+}
+
+func (r *Rules) GetParallelExecution() bool {
+	return !r.g.DisableParallelExecution
+}
+
+func (r *Rules) GetEpochLength() uint64 {
+	return r.g.EpochLength
+}
+
+func (r *Rules) GetMinDistinctSigners() int {
+	return r.g.MinDistinctSigners
+}
+
+func (r *Rules) GetMinDistinctSignersTxs() int {
+	return r.g.MinDistinctSignersTxs
+}
+
+func (r *Rules) GetRefundPolicy() chain.RefundPolicy {
+	return r.g.RefundPolicy
+}
+
+func (r *Rules) GetEnforceIdempotencyKeys() bool {
+	return r.g.EnforceIdempotencyKeys
+}
+
+func (r *Rules) IsActionRestricted(typeID uint8) bool {
+	return slices.Contains(r.g.RestrictedActions, typeID)
+}
+
+func (r *Rules) GetACLAdmin() codec.Address {
+	return r.g.aclAdmin
+}
+
+func (r *Rules) IsPaused(timestamp int64) bool {
+	if r.g.PausedFrom == 0 || timestamp < r.g.PausedFrom {
+		return false
+	}
+	return r.g.PausedUntil == 0 || timestamp < r.g.PausedUntil
+}
+
+func (r *Rules) GetForkActivations() chain.ForkActivations {
+	return r.g.ForkActivations
+}
+
 func (*Rules) GetSponsorStateKeysMaxChunks() []uint16 {
 	return []uint16{storage.BalanceChunks}
 }
@@ -101,6 +192,17 @@ func (r *Rules) GetWindowTargetUnits() fees.Dimensions {
 	return r.g.WindowTargetUnits
 }
 
+func (r *Rules) GetPricingMode() fees.PricingMode {
+	if r.timestamp < r.g.PricingModeActivation {
+		return fees.WindowPricing
+	}
+	return r.g.PricingMode
+}
+
+func (r *Rules) GetUtilizationEMADenominator() uint64 {
+	return r.g.UtilizationEMADenominator
+}
+
 func (*Rules) FetchCustom(string) (any, bool) {
 	return nil, false
 }
@@ -17,6 +17,7 @@ import (
 func init() {
 	consts.ActionRegistry = codec.NewTypeParser[chain.Action]()
 	consts.AuthRegistry = codec.NewTypeParser[chain.Auth]()
+	consts.ActionVectors = chain.NewActionVectorRegistry()
 
 	errs := &wrappers.Errs{}
 	errs.Add(
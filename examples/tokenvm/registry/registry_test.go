@@ -0,0 +1,29 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/ava-labs/hypersdk/chain/chaintest"
+	"github.com/ava-labs/hypersdk/examples/tokenvm/actions"
+	"github.com/ava-labs/hypersdk/examples/tokenvm/consts"
+)
+
+func TestActionVectors(t *testing.T) {
+	chaintest.RunActionVectors(
+		t,
+		consts.ActionRegistry,
+		consts.ActionVectors,
+		nil,
+		// TODO: add vectors for all actions
+		(&actions.Transfer{}).GetTypeID(),
+		(&actions.CreateAsset{}).GetTypeID(),
+		(&actions.MintAsset{}).GetTypeID(),
+		(&actions.BurnAsset{}).GetTypeID(),
+		(&actions.CreateOrder{}).GetTypeID(),
+		(&actions.FillOrder{}).GetTypeID(),
+		(&actions.CloseOrder{}).GetTypeID(),
+	)
+}
@@ -56,6 +56,7 @@ func (cli *JSONRPCClient) Genesis(ctx context.Context) (*genesis.Genesis, error)
 		"genesis",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	if err != nil {
 		return nil, err
@@ -71,6 +72,7 @@ func (cli *JSONRPCClient) Tx(ctx context.Context, id ids.ID) (bool, bool, int64,
 		"tx",
 		&TxArgs{TxID: id},
 		resp,
+		requester.Idempotent(),
 	)
 	switch {
 	// We use string parsing here because the JSON-RPC library we use may not
@@ -102,6 +104,7 @@ func (cli *JSONRPCClient) Asset(
 			Asset: asset,
 		},
 		resp,
+		requester.Idempotent(),
 	)
 	switch {
 	// We use string parsing here because the JSON-RPC library we use may not
@@ -127,6 +130,7 @@ func (cli *JSONRPCClient) Balance(ctx context.Context, addr string, asset ids.ID
 			Asset:   asset,
 		},
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.Amount, err
 }
@@ -140,6 +144,7 @@ func (cli *JSONRPCClient) Orders(ctx context.Context, pair string) ([]*orderbook
 			Pair: pair,
 		},
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.Orders, err
 }
@@ -153,6 +158,7 @@ func (cli *JSONRPCClient) GetOrder(ctx context.Context, orderID ids.ID) (*orderb
 			OrderID: orderID,
 		},
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.Order, err
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 
+	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/fees"
@@ -38,6 +39,17 @@ type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 // 0x3/ (hypersdk-height)
 // 0x4/ (hypersdk-timestamp)
 // 0x5/ (hypersdk-fee)
+// 0x6/ (hypersdk-memo)
+//   -> [txID] => memo
+// 0x7/ (hypersdk-task-queue)
+// 0x8/ (hypersdk-task)
+//   -> [taskID] => task
+// 0x9/ (hypersdk-acl)
+//   -> [actionTypeID|address] => granted
+// 0xA/ (hypersdk-epoch)
+//   -> [epoch] => validator set
+// 0xB/ (hypersdk-idempotency)
+//   -> [idempotencyID] => txID
 
 const (
 	// metaDB
@@ -50,6 +62,13 @@ const (
 	heightPrefix    = 0x3
 	timestampPrefix = 0x4
 	feePrefix       = 0x5
+	memoPrefix      = 0x6
+	taskQueuePrefix = 0x7
+	taskPrefix      = 0x8
+	aclPrefix       = 0x9
+	epochPrefix     = 0xA
+
+	idempotencyPrefix = 0xB
 )
 
 const (
@@ -64,6 +83,7 @@ var (
 	heightKey    = []byte{heightPrefix}
 	timestampKey = []byte{timestampPrefix}
 	feeKey       = []byte{feePrefix}
+	taskQueueKey = []byte{taskQueuePrefix}
 
 	balanceKeyPool = sync.Pool{
 		New: func() any {
@@ -469,3 +489,62 @@ func TimestampKey() (k []byte) {
 func FeeKey() (k []byte) {
 	return feeKey
 }
+
+// [memoPrefix] + [txID]
+func MemoKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = memoPrefix
+	copy(k[1:], txID[:])
+	return
+}
+
+func TaskQueueKey() (k []byte) {
+	return taskQueueKey
+}
+
+// [taskPrefix] + [taskID]
+func TaskKey(taskID ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = taskPrefix
+	copy(k[1:], taskID[:])
+	return
+}
+
+// [aclPrefix] + [actionTypeID] + [address]
+func ACLKey(actionTypeID uint8, addr codec.Address) (k []byte) {
+	k = make([]byte, 2+codec.AddressLen)
+	k[0] = aclPrefix
+	k[1] = actionTypeID
+	copy(k[2:], addr[:])
+	return
+}
+
+// [epochPrefix] + [epoch]
+func EpochKey(epoch uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = epochPrefix
+	binary.BigEndian.PutUint64(k[1:], epoch)
+	return
+}
+
+// [idempotencyPrefix] + [id]
+func IdempotencyKey(id ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = idempotencyPrefix
+	copy(k[1:], id[:])
+	return
+}
+
+// GetTxMemo returns the memo stored by the transaction [txID] (or nil if it
+// had no memo).
+func GetTxMemo(
+	ctx context.Context,
+	im state.Immutable,
+	txID ids.ID,
+) ([]byte, error) {
+	memo, err := im.GetValue(ctx, chain.MemoKey(MemoKey(txID)))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	return memo, err
+}
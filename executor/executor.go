@@ -20,6 +20,10 @@ import (
 // Executor ensures that conflicting tasks
 // are executed in the order they were queued.
 // Tasks with no conflicts are executed immediately.
+//
+// Tasks that only read a key, or that only accumulate to a key (see
+// [Executor.RunCommutative]), don't conflict with each other on that key
+// (but reading and accumulating do).
 type Executor struct {
 	metrics Metrics
 
@@ -31,6 +35,8 @@ type Executor struct {
 	tasks           int
 	maxDependencies int64
 	nodes           map[string]*task
+	// shared is the access shared by the readers of each node (if any)
+	shared map[string]access
 
 	err uatomic.Error
 }
@@ -47,6 +53,7 @@ func New(items, concurrency int, maxDependencies int64, metrics Metrics) *Execut
 		maxDependencies: maxDependencies,
 		nodes:           make(map[string]*task, items*2), // TODO: tune this
 		executable:      make(chan *task, items),         // ensure we don't block while holding lock
+		shared:          make(map[string]access),
 	}
 	e.workers.Add(concurrency)
 	for i := 0; i < concurrency; i++ {
@@ -128,6 +135,14 @@ func (e *Executor) runTask(t *task) {
 	}
 }
 
+// access is how a task uses a key that it doesn't need exclusive access to.
+type access uint8
+
+const (
+	readAccess access = iota + 1
+	accumulateAccess
+)
+
 // Run executes [f] after all previously enqueued [f] with
 // overlapping [keys] are executed.
 //
@@ -137,6 +152,15 @@ func (e *Executor) runTask(t *task) {
 // to the caller to ensure correctness does not depend on exactly when work begins
 // to be skipped (i.e. not safe to rely on this functionality for block verification).
 func (e *Executor) Run(keys state.Keys, f func() error) {
+	e.RunCommutative(keys, nil, f)
+}
+
+// RunCommutative is like [Run], except that [f] only accumulates to the
+// [commutative] keys (which must be in [keys]), so it may be executed
+// concurrently with other tasks that only accumulate to them. It is up to
+// the caller to ensure that the result doesn't depend on the order
+// accumulations are applied in.
+func (e *Executor) RunCommutative(keys state.Keys, commutative []string, f func() error) {
 	e.outstanding.Add(1)
 
 	// Add task to map
@@ -160,12 +184,24 @@ func (e *Executor) Run(keys state.Keys, f func() error) {
 	t.dependencies.Add(e.maxDependencies)
 
 	// Record dependencies
+	//
+	// Keys are visited in sorted order so that locks on conflicting tasks
+	// are always acquired in the same global order, regardless of the order
+	// in which an action declared its keys.
 	dependencies := set.NewSet[int](len(keys))
-	for k, v := range keys {
+	accumulated := set.Of(commutative...)
+	for _, k := range keys.Sorted() {
+		var a access
+		switch {
+		case accumulated.Contains(k):
+			a = accumulateAccess
+		case keys[k] == state.Read:
+			a = readAccess
+		}
 		lt, ok := e.nodes[k]
 		if ok {
 			lt.l.Lock()
-			if v == state.Read {
+			if shared, ok := e.shared[k]; a != 0 && (!ok || shared == a) {
 				// If we don't need exclusive access to a key, just mark
 				// that we are reading it and that we are a reader of it.
 				//
@@ -173,6 +209,7 @@ func (e *Executor) Run(keys state.Keys, f func() error) {
 				// different keys that are all just readers of another task.
 				t.reading[lt.id] = lt
 				lt.readers[id] = t
+				e.shared[k] = a
 			} else {
 				// If we do need exclusive access to a key, we need to
 				// mark ourselves blocked on all readers ahead of us.
@@ -191,6 +228,7 @@ func (e *Executor) Run(keys state.Keys, f func() error) {
 					dependencies.Add(rt.id)
 				}
 				e.nodes[k] = t
+				e.setShared(k, a)
 			}
 			if !lt.executed {
 				// If the task hasn't executed yet, we need to block on it.
@@ -204,6 +242,7 @@ func (e *Executor) Run(keys state.Keys, f func() error) {
 			continue
 		}
 		e.nodes[k] = t
+		e.setShared(k, a)
 	}
 
 	// Adjust dependency traker and execute if necessary
@@ -222,6 +261,17 @@ func (e *Executor) Run(keys state.Keys, f func() error) {
 	}
 }
 
+// setShared records that the readers of the task that just became the node
+// of [k] share access [a] (the readers of a task that needs exclusive access
+// can share either).
+func (e *Executor) setShared(k string, a access) {
+	if a == 0 {
+		delete(e.shared, k)
+		return
+	}
+	e.shared[k] = a
+}
+
 func (e *Executor) Stop() {
 	e.err.CompareAndSwap(nil, ErrStopped)
 }
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/set"
@@ -919,3 +920,123 @@ func TestLargeRandomReadsAndWrites(t *testing.T) {
 	require.NoError(e.Wait())
 	require.Len(completed, numTxs)
 }
+
+func TestConflictKeysDeclaredOutOfOrder(t *testing.T) {
+	for j := 0; j < numIterations; j++ {
+		var (
+			require   = require.New(t)
+			key1      = ids.GenerateTestID().String()
+			key2      = ids.GenerateTestID().String()
+			l         sync.Mutex
+			completed = make([]int, 0, 100)
+			e         = New(100, 4, maxDependencies, nil)
+			done      = make(chan error)
+		)
+		for i := 0; i < 100; i++ {
+			// Alternate the order in which the shared keys are declared
+			s := make(state.Keys, 2)
+			if i%2 == 0 {
+				s.Add(key1, state.Write)
+				s.Add(key2, state.Write)
+			} else {
+				s.Add(key2, state.Write)
+				s.Add(key1, state.Write)
+			}
+			ti := i
+			e.Run(s, func() error {
+				l.Lock()
+				completed = append(completed, ti)
+				l.Unlock()
+				return nil
+			})
+		}
+		go func() {
+			done <- e.Wait()
+		}()
+		select {
+		case err := <-done:
+			require.NoError(err)
+		case <-time.After(10 * time.Second):
+			require.FailNow("executor deadlocked")
+		}
+		require.Equal(generateNumbers(0)[:100], completed)
+	}
+}
+
+func TestAccumulateConflicts(t *testing.T) {
+	// Each task either writes (w), reads (r), or accumulates to (a) the same
+	// key
+	accesses := []byte("waaaarraaw")
+	for j := 0; j < numIterations; j++ {
+		var (
+			require   = require.New(t)
+			key       = ids.GenerateTestID().String()
+			l         sync.Mutex
+			completed = make([]int, 0, len(accesses))
+			observed  = make([][]int, len(accesses)) // completed when each task started
+			e         = New(len(accesses), len(accesses), maxDependencies, nil)
+			started   = make(chan struct{})
+			done      = make(chan error)
+		)
+		for i, access := range accesses {
+			ti := i
+			f := func() error {
+				// The first accumulator waits for the last one of its group
+				// to start (which deadlocks if they are run one at a time)
+				switch ti {
+				case 1:
+					select {
+					case <-started:
+					case <-time.After(10 * time.Second):
+						return errors.New("accumulators executed serially")
+					}
+				case 4:
+					close(started)
+				}
+				l.Lock()
+				observed[ti] = append([]int{}, completed...)
+				l.Unlock()
+				time.Sleep(time.Millisecond)
+				l.Lock()
+				completed = append(completed, ti)
+				l.Unlock()
+				return nil
+			}
+			switch access {
+			case 'w':
+				e.Run(state.Keys{key: state.Write}, f)
+			case 'r':
+				e.Run(state.Keys{key: state.Read}, f)
+			case 'a':
+				e.RunCommutative(state.Keys{key: state.All}, []string{key}, f)
+			}
+		}
+		go func() {
+			done <- e.Wait()
+		}()
+		select {
+		case err := <-done:
+			require.NoError(err)
+		case <-time.After(20 * time.Second):
+			require.FailNow("executor deadlocked")
+		}
+
+		// Tasks start after every previous conflicting task completed
+		for i, access := range accesses {
+			expected := set.NewSet[int](i)
+			for k := i - 1; k >= 0; k-- {
+				if accesses[k] != access || access == 'w' {
+					// All tasks before the previous conflicting task also
+					// completed
+					for p := k; p >= 0; p-- {
+						expected.Add(p)
+					}
+					break
+				}
+			}
+			for p := range expected {
+				require.Contains(observed[i], p, "task %d started before task %d completed", i, p)
+			}
+		}
+	}
+}
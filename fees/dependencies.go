@@ -8,4 +8,12 @@ type Rules interface {
 	GetUnitPriceChangeDenominator() Dimensions
 	GetWindowTargetUnits() Dimensions
 	GetMaxBlockUnits() Dimensions
+
+	GetPricingMode() PricingMode
+	GetUtilizationEMADenominator() uint64
+
+	GetTargetBlockRate() int64 // seconds
+	GetBlockCostChangeDenominator() uint64
+	GetMinBlockCost() uint64
+	GetMaxBlockCost() uint64
 }
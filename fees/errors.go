@@ -5,4 +5,7 @@ package fees
 
 import "errors"
 
-var ErrWrongDimensionSize = errors.New("wrong dimensions size")
+var (
+	ErrWrongDimensionSize = errors.New("wrong dimensions size")
+	ErrUnknownPricingMode = errors.New("unknown pricing mode")
+)
@@ -6,6 +6,7 @@ package fees
 import (
 	"encoding/binary"
 	"fmt"
+	"math/bits"
 	"strconv"
 	"sync"
 
@@ -26,6 +27,17 @@ const (
 
 	DimensionsLen     = consts.Uint64Len * FeeDimensions
 	dimensionStateLen = consts.Uint64Len + window.WindowSliceSize + consts.Uint64Len
+	blockCostStart    = FeeDimensions * dimensionStateLen
+	managerLen        = blockCostStart + consts.Uint64Len
+
+	// The utilization of each dimension is only tracked under [EMAPricing]
+	// (see [Manager.Utilization]).
+	utilizationStart = managerLen
+	emaManagerLen    = utilizationStart + FeeDimensions*consts.Uint32Len
+
+	// UtilizationScale is the utilization of a dimension that consumes
+	// exactly its target units (utilization is tracked in basis points).
+	UtilizationScale = 10_000
 )
 
 type (
@@ -33,6 +45,26 @@ type (
 	Dimensions [FeeDimensions]uint64
 )
 
+// PricingMode determines how [Manager.ComputeNext] updates unit prices.
+type PricingMode uint8
+
+const (
+	// WindowPricing adjusts the unit price of each dimension by how far the
+	// units consumed over the last [window.WindowSize] seconds are from the
+	// target.
+	WindowPricing PricingMode = 0
+
+	// EMAPricing adjusts the unit price of each dimension by how far an
+	// exponential moving average of the utilization of each block (the units
+	// consumed over the last [window.WindowSize] seconds relative to the
+	// target) is from the target. The price changes by at most
+	// 1/[Rules.GetUnitPriceChangeDenominator] per block.
+	//
+	// This avoids the price oscillations of [WindowPricing] when consumption
+	// is bursty (the window is still maintained, but ignored).
+	EMAPricing PricingMode = 1
+)
+
 // Manager is safe for concurrent use
 type Manager struct {
 	l   sync.RWMutex
@@ -40,8 +72,12 @@ type Manager struct {
 }
 
 func NewManager(raw []byte) *Manager {
-	if len(raw) == 0 {
-		raw = make([]byte, FeeDimensions*dimensionStateLen)
+	if len(raw) < managerLen {
+		// Fee state written before the block cost was tracked is padded
+		// with a block cost of 0.
+		padded := make([]byte, managerLen)
+		copy(padded, raw)
+		raw = padded
 	}
 	return &Manager{raw: raw}
 }
@@ -82,33 +118,108 @@ func (f *Manager) lastConsumed(d Dimension) uint64 {
 	return binary.BigEndian.Uint64(f.raw[start : start+consts.Uint64Len])
 }
 
+// BlockCost is the minimum sum of fees that must be paid by the transactions
+// in a block (see [Rules.GetTargetBlockRate]).
+func (f *Manager) BlockCost() uint64 {
+	f.l.RLock()
+	defer f.l.RUnlock()
+
+	return f.blockCost()
+}
+
+func (f *Manager) blockCost() uint64 {
+	return binary.BigEndian.Uint64(f.raw[blockCostStart:managerLen])
+}
+
+// Utilization is the exponential moving average of the utilization of [d]
+// (in basis points of its target, see [UtilizationScale]) used by
+// [EMAPricing]. It is 0 if the utilization is not tracked (the fee state was
+// last computed under a different [PricingMode]).
+func (f *Manager) Utilization(d Dimension) uint64 {
+	f.l.RLock()
+	defer f.l.RUnlock()
+
+	u, _ := f.utilization(d)
+	return u
+}
+
+func (f *Manager) utilization(d Dimension) (uint64, bool) {
+	if len(f.raw) < emaManagerLen {
+		return 0, false
+	}
+	start := utilizationStart + consts.Uint32Len*int(d)
+	return uint64(binary.BigEndian.Uint32(f.raw[start : start+consts.Uint32Len])), true
+}
+
+// ComputeNext returns the fee state of a block produced at [currTime] that
+// follows a block produced at [lastTime] with the fee state of [f]. The unit
+// prices are computed with [Rules.GetPricingMode].
+//
+// When switching to [EMAPricing], the utilization of each dimension starts at
+// the utilization of the parent (there is no average to smooth yet).
 func (f *Manager) ComputeNext(lastTime int64, currTime int64, r Rules) (*Manager, error) {
 	f.l.RLock()
 	defer f.l.RUnlock()
 
+	mode := r.GetPricingMode()
+	size := managerLen
+	switch mode {
+	case WindowPricing:
+	case EMAPricing:
+		size = emaManagerLen
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownPricingMode, mode)
+	}
 	targetUnits := r.GetWindowTargetUnits()
 	unitPriceChangeDenom := r.GetUnitPriceChangeDenominator()
 	minUnitPrice := r.GetMinUnitPrice()
 	since := int((currTime - lastTime) / consts.MillisecondsPerSecond)
-	bytes := make([]byte, dimensionStateLen*FeeDimensions)
+	bytes := make([]byte, size)
 	for i := Dimension(0); i < FeeDimensions; i++ {
-		nextUnitPrice, nextUnitWindow, err := computeNextPriceWindow(
-			f.Window(i),
-			f.LastConsumed(i),
-			f.UnitPrice(i),
-			targetUnits[i],
-			unitPriceChangeDenom[i],
-			minUnitPrice[i],
-			since,
-		)
+		nextUnitWindow, total, err := computeNextWindow(f.window(i), f.lastConsumed(i), since)
 		if err != nil {
 			return nil, err
 		}
+		var nextUnitPrice uint64
+		switch mode {
+		case WindowPricing:
+			nextUnitPrice = computeNextPrice(
+				total,
+				f.unitPrice(i),
+				targetUnits[i],
+				unitPriceChangeDenom[i],
+				minUnitPrice[i],
+				since,
+			)
+		case EMAPricing:
+			sample := utilizationOf(total, targetUnits[i])
+			nextUtilization := sample
+			if previous, ok := f.utilization(i); ok {
+				nextUtilization = computeNextUtilization(previous, sample, r.GetUtilizationEMADenominator())
+			}
+			nextUnitPrice = computeNextEMAPrice(
+				nextUtilization,
+				f.unitPrice(i),
+				unitPriceChangeDenom[i],
+				minUnitPrice[i],
+			)
+			start := utilizationStart + consts.Uint32Len*int(i)
+			binary.BigEndian.PutUint32(bytes[start:start+consts.Uint32Len], uint32(nextUtilization))
+		}
 		start := dimensionStateLen * i
 		binary.BigEndian.PutUint64(bytes[start:start+consts.Uint64Len], nextUnitPrice)
 		copy(bytes[start+consts.Uint64Len:start+consts.Uint64Len+window.WindowSliceSize], nextUnitWindow[:])
 		// Usage must be set after block is processed (we leave as 0 for now)
 	}
+	nextBlockCost := computeNextBlockCost(
+		f.blockCost(),
+		r.GetTargetBlockRate()*consts.MillisecondsPerSecond,
+		r.GetBlockCostChangeDenominator(),
+		r.GetMinBlockCost(),
+		r.GetMaxBlockCost(),
+		currTime-lastTime,
+	)
+	binary.BigEndian.PutUint64(bytes[blockCostStart:managerLen], nextBlockCost)
 	return &Manager{raw: bytes}, nil
 }
 
@@ -162,6 +273,24 @@ func (f *Manager) Consume(d Dimensions, l Dimensions) (bool, Dimension) {
 	return true, 0
 }
 
+// Release returns [d] units previously consumed with [Consume] (like when
+// units are refunded after execution). If any dimension would underflow,
+// nothing is released.
+func (f *Manager) Release(d Dimensions) (bool, Dimension) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	for i := Dimension(0); i < FeeDimensions; i++ {
+		if _, err := math.Sub(f.lastConsumed(i), d[i]); err != nil {
+			return false, i
+		}
+	}
+	for i := Dimension(0); i < FeeDimensions; i++ {
+		f.setLastConsumed(i, f.lastConsumed(i)-d[i])
+	}
+	return true, 0
+}
+
 func (f *Manager) Bytes() []byte {
 	f.l.RLock()
 	defer f.l.RUnlock()
@@ -210,18 +339,17 @@ func (f *Manager) UnitsConsumed() Dimensions {
 	return d
 }
 
-func computeNextPriceWindow(
+// computeNextWindow rolls [previous] forward by [since] seconds and adds the
+// units consumed by the parent block. It returns the rolled window and the
+// units consumed in it.
+func computeNextWindow(
 	previous window.Window,
 	previousConsumed uint64,
-	previousPrice uint64,
-	target uint64, /* per window */
-	changeDenom uint64,
-	minPrice uint64,
 	since int, /* seconds */
-) (uint64, window.Window, error) {
+) (window.Window, uint64, error) {
 	newRollupWindow, err := window.Roll(previous, since)
 	if err != nil {
-		return 0, window.Window{}, err
+		return window.Window{}, 0, err
 	}
 	if since < window.WindowSize {
 		// add in the units used by the parent block in the correct place
@@ -231,8 +359,19 @@ func computeNextPriceWindow(
 		start := slot * consts.Uint64Len
 		window.Update(&newRollupWindow, start, previousConsumed)
 	}
-	total := window.Sum(newRollupWindow)
+	return newRollupWindow, window.Sum(newRollupWindow), nil
+}
 
+// computeNextPrice computes the next unit price under [WindowPricing] from
+// the [total] units consumed in the window.
+func computeNextPrice(
+	total uint64,
+	previousPrice uint64,
+	target uint64, /* per window */
+	changeDenom uint64,
+	minPrice uint64,
+	since int, /* seconds */
+) uint64 {
 	nextPrice := previousPrice
 	if total > target {
 		// If the parent block used more units than its target, the baseFee should increase.
@@ -277,7 +416,141 @@ func computeNextPriceWindow(
 	if nextPrice < minPrice {
 		nextPrice = minPrice
 	}
-	return nextPrice, newRollupWindow, nil
+	return nextPrice
+}
+
+// utilizationOf returns [total] in basis points of [target] (saturating at
+// [consts.MaxUint32]).
+func utilizationOf(total uint64, target uint64) uint64 {
+	if target == 0 {
+		return uint64(consts.MaxUint32)
+	}
+	return min(mulDiv(total, UtilizationScale, target), uint64(consts.MaxUint32))
+}
+
+// computeNextUtilization moves [previous] towards [sample] by
+// 1/[smoothingDenom] of the difference between them.
+func computeNextUtilization(previous uint64, sample uint64, smoothingDenom uint64) uint64 {
+	if smoothingDenom == 0 {
+		smoothingDenom = 1
+	}
+	if sample > previous {
+		return previous + (sample-previous)/smoothingDenom
+	}
+	return previous - (previous-sample)/smoothingDenom
+}
+
+// computeNextEMAPrice computes the next unit price under [EMAPricing]. Like
+// EIP-1559, the price changes by the distance of [utilization] from the
+// target divided by [changeDenom] (which is also the max change of a single
+// block, reached at twice the target or no utilization).
+func computeNextEMAPrice(
+	utilization uint64, /* basis points of target */
+	previousPrice uint64,
+	changeDenom uint64,
+	minPrice uint64,
+) uint64 {
+	nextPrice := previousPrice
+	if utilization > UtilizationScale {
+		excess := min(utilization-UtilizationScale, UtilizationScale)
+		baseDelta := mulDiv(previousPrice, excess, UtilizationScale) / changeDenom
+		if baseDelta < 1 {
+			baseDelta = 1
+		}
+		nextPrice = saturatingAdd(previousPrice, baseDelta)
+	} else if utilization < UtilizationScale {
+		deficit := UtilizationScale - utilization
+		baseDelta := mulDiv(previousPrice, deficit, UtilizationScale) / changeDenom
+		if baseDelta < 1 {
+			baseDelta = 1
+		}
+		n, under := math.Sub(previousPrice, baseDelta)
+		if under != nil {
+			nextPrice = 0
+		} else {
+			nextPrice = n
+		}
+	}
+	if nextPrice < minPrice {
+		nextPrice = minPrice
+	}
+	return nextPrice
+}
+
+// mulDiv returns a * b / c without overflowing the intermediate product
+// (saturating at [consts.MaxUint64] if the result overflows).
+func mulDiv(a, b, c uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if hi >= c {
+		return consts.MaxUint64
+	}
+	q, _ := bits.Div64(hi, lo, c)
+	return q
+}
+
+// computeNextBlockCost adjusts the block cost by how far [elapsed] is from
+// [target] (both in ms), like a difficulty adjustment. Blocks produced faster
+// than [target] increase the cost of the next block and blocks produced slower
+// decrease it.
+//
+// If [target] is 0, block production is not paced and the cost is always 0.
+func computeNextBlockCost(
+	previousCost uint64,
+	target int64, /* ms */
+	changeDenom uint64,
+	minCost uint64,
+	maxCost uint64,
+	elapsed int64, /* ms */
+) uint64 {
+	if target <= 0 {
+		return 0
+	}
+	if previousCost < minCost {
+		previousCost = minCost
+	}
+
+	nextCost := previousCost
+	if elapsed < target {
+		// If the block was produced faster than the target, the cost should increase.
+		nextCost = saturatingAdd(previousCost, blockCostDelta(previousCost, uint64(target-elapsed), uint64(target), changeDenom))
+	} else if elapsed > target {
+		// Otherwise if the block was produced slower than the target, the cost should decrease
+		// (by more the longer no blocks were produced).
+		baseDelta := blockCostDelta(previousCost, uint64(elapsed-target), uint64(target), changeDenom)
+		n, under := math.Sub(previousCost, baseDelta)
+		if under != nil {
+			nextCost = 0
+		} else {
+			nextCost = n
+		}
+	}
+	if nextCost < minCost {
+		nextCost = minCost
+	}
+	if nextCost > maxCost {
+		nextCost = maxCost
+	}
+	return nextCost
+}
+
+func blockCostDelta(previousCost uint64, delta uint64, target uint64, changeDenom uint64) uint64 {
+	x, err := math.Mul64(previousCost, delta)
+	if err != nil {
+		x = consts.MaxUint64
+	}
+	baseDelta := x / target / changeDenom
+	if baseDelta < 1 {
+		baseDelta = 1
+	}
+	return baseDelta
+}
+
+func saturatingAdd(a, b uint64) uint64 {
+	n, err := math.Add64(a, b)
+	if err != nil {
+		return consts.MaxUint64
+	}
+	return n
 }
 
 func Add(a, b Dimensions) (Dimensions, error) {
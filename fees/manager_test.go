@@ -0,0 +1,156 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package fees
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testRules targets 1,000 units of each dimension per window.
+type testRules struct {
+	mode PricingMode
+}
+
+func (*testRules) GetMinUnitPrice() Dimensions               { return Dimensions{100, 100, 100, 100, 100} }
+func (*testRules) GetUnitPriceChangeDenominator() Dimensions { return Dimensions{8, 8, 8, 8, 8} }
+func (*testRules) GetWindowTargetUnits() Dimensions {
+	return Dimensions{1_000, 1_000, 1_000, 1_000, 1_000}
+}
+func (*testRules) GetMaxBlockUnits() Dimensions          { return Dimensions{} }
+func (r *testRules) GetPricingMode() PricingMode         { return r.mode }
+func (*testRules) GetUtilizationEMADenominator() uint64  { return 4 }
+func (*testRules) GetTargetBlockRate() int64             { return 0 }
+func (*testRules) GetBlockCostChangeDenominator() uint64 { return 0 }
+func (*testRules) GetMinBlockCost() uint64               { return 0 }
+func (*testRules) GetMaxBlockCost() uint64               { return 0 }
+
+// burstyConsumption consumes 1,500 [Bandwidth] units (1.5x the target) in
+// every tenth block.
+func burstyConsumption(blocks int) []uint64 {
+	consumed := make([]uint64, blocks)
+	for i := 0; i < blocks; i += 10 {
+		consumed[i] = 1_500
+	}
+	return consumed
+}
+
+// produceFeeStates computes the fee state of a block every second (the
+// [Bandwidth] unit price starts at 1,000) with the pricing mode returned by
+// [modes] and returns the [Bandwidth] unit price and utilization of each
+// block.
+func produceFeeStates(t *testing.T, modes []PricingMode, consumed []uint64) ([]uint64, []uint64) {
+	require := require.New(t)
+
+	m := NewManager(nil)
+	m.SetUnitPrice(Bandwidth, 1_000)
+	var (
+		tmstmp      int64
+		prices      = make([]uint64, len(modes))
+		utilization = make([]uint64, len(modes))
+	)
+	for i, mode := range modes {
+		next, err := m.ComputeNext(tmstmp, tmstmp+1_000, &testRules{mode: mode})
+		require.NoError(err)
+		if mode == EMAPricing {
+			require.Len(next.Bytes(), emaManagerLen)
+		} else {
+			require.Len(next.Bytes(), managerLen)
+		}
+		prices[i] = next.UnitPrice(Bandwidth)
+		utilization[i] = next.Utilization(Bandwidth)
+		next.SetLastConsumed(Bandwidth, consumed[i])
+		m = next
+		tmstmp += 1_000
+	}
+	return prices, utilization
+}
+
+func repeatMode(mode PricingMode, n int) []PricingMode {
+	modes := make([]PricingMode, n)
+	for i := range modes {
+		modes[i] = mode
+	}
+	return modes
+}
+
+func TestComputeNextGolden(t *testing.T) {
+	tests := []struct {
+		name        string
+		modes       []PricingMode
+		prices      []uint64
+		utilization []uint64
+	}{
+		{
+			name:  "window",
+			modes: repeatMode(WindowPricing, 30),
+			prices: []uint64{
+				875, 929, 987, 1048, 1113, 1182, 1255, 1333, 1416, 1504,
+				1316, 1398, 1485, 1577, 1675, 1779, 1890, 2008, 2133, 2266,
+				1983, 2106, 2237, 2376, 2524, 2681, 2848, 3026, 3215, 3415,
+			},
+			utilization: make([]uint64, 30),
+		},
+		{
+			name:  "ema",
+			modes: repeatMode(EMAPricing, 30),
+			prices: []uint64{
+				875, 807, 773, 761, 763, 776, 798, 827, 863, 904,
+				908, 925, 952, 988, 1031, 1081, 1137, 1199, 1267, 1340,
+				1356, 1389, 1436, 1495, 1564, 1643, 1731, 1827, 1932, 2045,
+			},
+			utilization: []uint64{
+				0, 3750, 6562, 8671, 10253, 11439, 12329, 12996, 13497, 13872,
+				10404, 11553, 12414, 13060, 13545, 13908, 14181, 14385, 14538, 14653,
+				10990, 11992, 12744, 13308, 13731, 14048, 14286, 14464, 14598, 14698,
+			},
+		},
+		{
+			// The utilization starts at that of the parent when switching to
+			// [EMAPricing] and is dropped when switching back
+			name:  "switch modes",
+			modes: append(append(repeatMode(WindowPricing, 10), repeatMode(EMAPricing, 10)...), repeatMode(WindowPricing, 10)...),
+			prices: []uint64{
+				875, 929, 987, 1048, 1113, 1182, 1255, 1333, 1416, 1504,
+				1316, 1214, 1162, 1143, 1146, 1166, 1199, 1243, 1297, 1359,
+				1190, 1264, 1343, 1426, 1515, 1609, 1709, 1815, 1928, 2048,
+			},
+			utilization: []uint64{
+				0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				0, 3750, 6562, 8671, 10253, 11439, 12329, 12996, 13497, 13872,
+				0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prices, utilization := produceFeeStates(t, tt.modes, burstyConsumption(len(tt.modes)))
+			require.Equal(t, tt.prices, prices)
+			require.Equal(t, tt.utilization, utilization)
+		})
+	}
+}
+
+func TestComputeNextEMAMaxChange(t *testing.T) {
+	require := require.New(t)
+
+	// Under [EMAPricing], the price changes by at most 1/8 (the change
+	// denominator) per block no matter how far utilization is from the target
+	require.Equal(uint64(875), computeNextEMAPrice(0, 1_000, 8, 100))
+	require.Equal(uint64(1_000), computeNextEMAPrice(UtilizationScale, 1_000, 8, 100))
+	require.Equal(uint64(1_125), computeNextEMAPrice(2*UtilizationScale, 1_000, 8, 100))
+	require.Equal(uint64(1_125), computeNextEMAPrice(100*UtilizationScale, 1_000, 8, 100))
+
+	// The price never drops below the floor
+	require.Equal(uint64(100), computeNextEMAPrice(0, 101, 8, 100))
+
+	// The utilization saturates instead of overflowing
+	require.Equal(uint64(^uint32(0)), utilizationOf(^uint64(0), 1))
+}
+
+func TestComputeNextUnknownPricingMode(t *testing.T) {
+	_, err := NewManager(nil).ComputeNext(0, 1_000, &testRules{mode: 2})
+	require.ErrorIs(t, err, ErrUnknownPricingMode)
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gossiper
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// bundleDecision applies the decision made for the first transaction of each
+// [chain.Bundle] visited by [chain.Mempool.Top] to the rest of its
+// transactions (so a bundle is always gossiped, kept, or dropped as a unit).
+type bundleDecision struct {
+	bundle  *chain.Bundle
+	cont    bool
+	restore bool
+}
+
+// next returns the decision for [tx] (which is in a bundle), calling [decide]
+// if it is the first transaction of its bundle.
+func (d *bundleDecision) next(tx *chain.Transaction, decide func(*chain.Bundle) (cont bool, restore bool)) (bool, bool) {
+	if bundle := tx.Bundle(); bundle != d.bundle {
+		d.bundle = bundle
+		d.cont, d.restore = decide(bundle)
+	}
+	return d.cont, d.restore
+}
+
+// parseBundles returns the bundles gossiped by [nodeID] in [msg] (or nil if
+// they are invalid).
+func parseBundles(vm VM, nodeID ids.NodeID, msg []byte) []*chain.Bundle {
+	actionRegistry, authRegistry := vm.Registry()
+	bundles, err := chain.UnmarshalBundles(msg, initialCapacity, actionRegistry, authRegistry)
+	if err != nil {
+		vm.GossipLogger().Warn(
+			"received invalid bundles",
+			zap.Stringer("peerID", nodeID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	txs := 0
+	for _, bundle := range bundles {
+		txs += len(bundle.Txs)
+	}
+	vm.RecordTxsReceived(txs)
+	return bundles
+}
+
+// submitBundles submits [bundles] gossiped by [nodeID] to the mempool
+// (verifying the auth of all of their transactions).
+func submitBundles(ctx context.Context, vm VM, nodeID ids.NodeID, bundles []*chain.Bundle) {
+	start := time.Now()
+	for _, bundle := range bundles {
+		err := vm.SubmitBundle(ctx, true, bundle)
+		if err == nil || errors.Is(err, chain.ErrDuplicateTx) {
+			continue
+		}
+		vm.GossipLogger().Debug(
+			"failed to submit gossiped bundle",
+			zap.Stringer("peerID", nodeID),
+			zap.Stringer("bundleID", bundle.ID()),
+			zap.Error(err),
+		)
+	}
+	vm.GossipLogger().Debug(
+		"bundle gossip received",
+		zap.Int("bundles", len(bundles)),
+		zap.Stringer("peerID", nodeID),
+		zap.Duration("t", time.Since(start)),
+	)
+}
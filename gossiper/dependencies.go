@@ -13,6 +13,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/set"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/network"
 )
 
 type VM interface {
@@ -25,13 +26,21 @@ type VM interface {
 	Proposers(ctx context.Context, diff int, depth int) (set.Set[ids.NodeID], error)
 	IsValidator(context.Context, ids.NodeID) (bool, error)
 	Logger() logging.Logger
+	GossipLogger() logging.Logger
 	PreferredBlock(context.Context) (*chain.StatelessBlock, error)
 	Registry() (chain.ActionRegistry, chain.AuthRegistry)
 	NodeID() ids.NodeID
 	Rules(int64) chain.Rules
 	Submit(ctx context.Context, verify bool, txs []*chain.Transaction) []error
+	// SubmitDeferred adds [txs] to the mempool once their auth is verified
+	// (in the background if [DeferredVerification] is enabled).
+	SubmitDeferred(ctx context.Context, txs []*chain.Transaction) []error
+	DeferredVerification() bool
+	SubmitBundle(ctx context.Context, verify bool, bundle *chain.Bundle) error
+	SendBundleGossip(ctx context.Context, nodeIDs set.Set[ids.NodeID], msg []byte) error
 	GetAuthBatchVerifier(authTypeID uint8, cores int, count int) (chain.AuthBatchVerifier, bool)
 	StateManager() chain.StateManager
+	PeerFeatures(ids.NodeID) network.Features
 
 	RecordTxsGossiped(int)
 	RecordSeenTxsReceived(int)
@@ -12,6 +12,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 )
 
@@ -40,14 +41,32 @@ func (g *Manual) Run(appSender common.AppSender) {
 func (g *Manual) Force(ctx context.Context) error {
 	// Gossip highest paying txs
 	var (
-		txs  = []*chain.Transaction{}
-		size = 0
-		now  = time.Now().UnixMilli()
+		txs     = []*chain.Transaction{}
+		bundles = []*chain.Bundle{}
+		bundled = &bundleDecision{}
+		size    = 0
+		now     = time.Now().UnixMilli()
 	)
 	mempoolErr := g.vm.Mempool().Top(
 		ctx,
 		g.vm.GetTargetGossipDuration(),
 		func(_ context.Context, next *chain.Transaction) (cont bool, rest bool, err error) {
+			// Bundles are gossiped as a unit
+			if next.Bundle() != nil {
+				cont, rest = bundled.next(next, func(bundle *chain.Bundle) (bool, bool) {
+					if bundle.Expiry() < now {
+						return true, false
+					}
+					if bundle.Size()+size > consts.NetworkSizeLimit {
+						return false, true
+					}
+					bundles = append(bundles, bundle)
+					size += bundle.Size()
+					return true, true
+				})
+				return cont, rest, nil
+			}
+
 			// Remove txs that are expired
 			if next.Base.Timestamp < now {
 				return true, false, nil
@@ -66,21 +85,36 @@ func (g *Manual) Force(ctx context.Context) error {
 	if mempoolErr != nil {
 		return mempoolErr
 	}
+	if len(bundles) > 0 {
+		b, err := chain.MarshalBundles(bundles)
+		if err != nil {
+			return err
+		}
+		if err := g.vm.SendBundleGossip(ctx, nil, b); err != nil {
+			g.vm.GossipLogger().Warn(
+				"GossipBundles failed",
+				zap.Error(err),
+			)
+			return err
+		}
+		g.vm.GossipLogger().Debug("gossiped bundles", zap.Int("count", len(bundles)))
+	}
 	if len(txs) == 0 {
 		return nil
 	}
-	b, err := chain.MarshalTxs(txs)
+	p, err := chain.MarshalTxsPooled(txs)
 	if err != nil {
 		return err
 	}
-	if err := g.appSender.SendAppGossip(ctx, common.SendConfig{Validators: 10}, b); err != nil {
-		g.vm.Logger().Warn(
+	defer codec.PutWriter(p)
+	if err := g.appSender.SendAppGossip(ctx, common.SendConfig{Validators: 10}, p.Bytes()); err != nil {
+		g.vm.GossipLogger().Warn(
 			"GossipTxs failed",
 			zap.Error(err),
 		)
 		return err
 	}
-	g.vm.Logger().Debug("gossiped txs", zap.Int("count", len(txs)))
+	g.vm.GossipLogger().Debug("gossiped txs", zap.Int("count", len(txs)))
 	return nil
 }
 
@@ -88,7 +122,7 @@ func (g *Manual) HandleAppGossip(ctx context.Context, nodeID ids.NodeID, msg []b
 	actionRegistry, authRegistry := g.vm.Registry()
 	_, txs, err := chain.UnmarshalTxs(msg, initialCapacity, actionRegistry, authRegistry)
 	if err != nil {
-		g.vm.Logger().Warn(
+		g.vm.GossipLogger().Warn(
 			"AppGossip provided invalid txs",
 			zap.Stringer("peerID", nodeID),
 			zap.Error(err),
@@ -98,25 +132,30 @@ func (g *Manual) HandleAppGossip(ctx context.Context, nodeID ids.NodeID, msg []b
 	g.vm.RecordTxsReceived(len(txs))
 
 	start := time.Now()
-	for _, err := range g.vm.Submit(ctx, true, txs) {
+	for _, err := range g.vm.SubmitDeferred(ctx, txs) {
 		if err == nil {
 			continue
 		}
-		g.vm.Logger().Warn(
+		g.vm.GossipLogger().Warn(
 			"AppGossip failed to submit txs",
 			zap.Stringer("peerID", nodeID),
 			zap.Error(err),
 		)
 	}
-	g.vm.Logger().Info(
+	g.vm.GossipLogger().Info(
 		"tx gossip received",
 		zap.Int("txs", len(txs)),
-		zap.Stringer("nodeID", nodeID),
+		zap.Stringer("peerID", nodeID),
 		zap.Duration("t", time.Since(start)),
 	)
 	return nil
 }
 
+func (g *Manual) HandleBundleGossip(ctx context.Context, nodeID ids.NodeID, msg []byte) error {
+	submitBundles(ctx, g.vm, nodeID, parseBundles(g.vm, nodeID, msg))
+	return nil
+}
+
 func (*Manual) BlockVerified(int64) {}
 
 func (g *Manual) Done() {
@@ -20,7 +20,9 @@ import (
 
 	"github.com/ava-labs/hypersdk/cache"
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/network"
 	"github.com/ava-labs/hypersdk/workers"
 )
 
@@ -54,6 +56,10 @@ type ProposerConfig struct {
 	NoGossipBuilderDiff int
 	VerifyTimeout       int64 // ms
 	SeenCacheSize       int
+
+	// GossipRequiredFeatures are the features a peer must advertise to be
+	// sent gossip (used while transitioning to a new wire format)
+	GossipRequiredFeatures network.Features
 }
 
 func DefaultProposerConfig() *ProposerConfig {
@@ -109,63 +115,98 @@ func (g *Proposer) Force(ctx context.Context) error {
 	// that increases the probability they'll be accepted
 	// before they expire.
 	var (
-		txs   = []*chain.Transaction{}
-		size  = 0
-		start = time.Now()
-		now   = start.UnixMilli()
+		txs     = []*chain.Transaction{}
+		bundles = []*chain.Bundle{}
+		bundled = &bundleDecision{}
+		size    = 0
+		start   = time.Now()
+		now     = start.UnixMilli()
 	)
 	mempoolErr := g.vm.Mempool().Top(
 		ctx,
 		g.vm.GetTargetGossipDuration(),
 		func(_ context.Context, next *chain.Transaction) (cont bool, rest bool, err error) {
-			// Remove txs that are expired
-			if next.Base.Timestamp < now {
-				return true, false, nil
+			// Bundles are gossiped as a unit
+			if next.Bundle() != nil {
+				cont, rest = bundled.next(next, func(bundle *chain.Bundle) (bool, bool) {
+					gossip, cont, rest := g.pick(bundle.ID(), bundle.Expiry(), bundle.Size(), size, now)
+					if gossip {
+						bundles = append(bundles, bundle)
+						size += bundle.Size()
+					}
+					return cont, rest
+				})
+				return cont, rest, nil
 			}
-
-			// Don't gossip txs that are about to expire
-			life := next.Base.Timestamp - now
-			if life < g.cfg.GossipMinLife {
-				return true, true, nil
+			gossip, cont, rest := g.pick(next.ID(), next.Base.Timestamp, next.Size(), size, now)
+			if gossip {
+				txs = append(txs, next)
+				size += next.Size()
 			}
-
-			// Gossip up to [GossipMaxSize]
-			txSize := next.Size()
-			if txSize+size > g.cfg.GossipMaxSize {
-				return false, true, nil
-			}
-
-			// Don't remove anything from mempool
-			// that will be dropped (this seems
-			// like we sent it then got sent it back?)
-			txID := next.ID()
-			if _, ok := g.cache.Get(txID); ok {
-				return true, true, nil
-			}
-			g.cache.Put(txID, nil)
-
-			txs = append(txs, next)
-			size += txSize
-			return true, false, nil
+			return cont, rest, nil
 		},
 	)
 	if mempoolErr != nil {
 		return mempoolErr
 	}
+	if len(txs) == 0 && len(bundles) == 0 {
+		g.vm.GossipLogger().Debug("no transactions to gossip")
+		return nil
+	}
+	g.vm.GossipLogger().Debug(
+		"gossiping transactions",
+		zap.Int("txs", len(txs)),
+		zap.Int("bundles", len(bundles)),
+		zap.Duration("t", time.Since(start)),
+	)
+	if len(bundles) > 0 {
+		if err := g.sendBundles(ctx, bundles); err != nil {
+			return err
+		}
+	}
 	if len(txs) == 0 {
-		g.vm.Logger().Debug("no transactions to gossip")
 		return nil
 	}
-	g.vm.Logger().Debug("gossiping transactions", zap.Int("txs", len(txs)), zap.Duration("t", time.Since(start)))
 	g.vm.RecordTxsGossiped(len(txs))
 	return g.sendTxs(ctx, txs)
 }
 
+// pick returns whether to gossip an item (a transaction or bundle) with [id]
+// that expires at [expiry] and has [itemSize] when [size] bytes of items have
+// already been picked at [now] (and whether to continue iterating the mempool
+// and restore the item, as expected by [chain.Mempool.Top]).
+func (g *Proposer) pick(id ids.ID, expiry int64, itemSize int, size int, now int64) (gossip bool, cont bool, restore bool) {
+	// Remove items that are expired
+	if expiry < now {
+		return false, true, false
+	}
+
+	// Don't gossip items that are about to expire
+	life := expiry - now
+	if life < g.cfg.GossipMinLife {
+		return false, true, true
+	}
+
+	// Gossip up to [GossipMaxSize]
+	if itemSize+size > g.cfg.GossipMaxSize {
+		return false, false, true
+	}
+
+	// Don't remove anything from mempool
+	// that will be dropped (this seems
+	// like we sent it then got sent it back?)
+	if _, ok := g.cache.Get(id); ok {
+		return false, true, true
+	}
+	g.cache.Put(id, nil)
+	return true, true, false
+}
+
 func (g *Proposer) HandleAppGossip(ctx context.Context, nodeID ids.NodeID, msg []byte) error {
 	actionRegistry, authRegistry := g.vm.Registry()
 	authCounts, txs, err := chain.UnmarshalTxs(msg, initialCapacity, actionRegistry, authRegistry)
 	if err != nil {
-		g.vm.Logger().Warn(
+		g.vm.GossipLogger().Warn(
 			"received invalid txs",
 			zap.Stringer("peerID", nodeID),
 			zap.Error(err),
@@ -175,26 +216,41 @@ func (g *Proposer) HandleAppGossip(ctx context.Context, nodeID ids.NodeID, msg [
 	g.vm.RecordTxsReceived(len(txs))
 
 	// Add incoming transactions to our caches to prevent useless gossip and perform
-	// batch signature verification.
+	// batch signature verification (unless it is deferred).
 	//
 	// We rely on AppGossipConcurrency to regulate concurrency here, so we don't create
 	// a separate pool of workers for this verification.
 	job, err := workers.NewSerial().NewJob(len(txs))
 	if err != nil {
-		g.vm.Logger().Warn(
+		g.vm.GossipLogger().Warn(
 			"unable to spawn new worker",
 			zap.Stringer("peerID", nodeID),
 			zap.Error(err),
 		)
 		return nil
 	}
-	batchVerifier := chain.NewAuthBatch(g.vm, job, authCounts)
-	var seen int
+	batchVerifier := chain.NewAuthBatch(ctx, g.vm, job, authCounts)
+	var (
+		seen     int
+		deferred = g.vm.DeferredVerification()
+	)
 	for _, tx := range txs {
+		// Add incoming txs to the cache to make
+		// sure we never gossip anything we receive (someone
+		// else will)
+		if g.cache.Put(tx.ID(), nil) {
+			seen++
+		}
+
+		// Signatures are verified in the background by [SubmitDeferred]
+		if deferred {
+			continue
+		}
+
 		// Verify signature async
 		txDigest, err := tx.Digest()
 		if err != nil {
-			g.vm.Logger().Warn(
+			g.vm.GossipLogger().Warn(
 				"unable to compute tx digest",
 				zap.Stringer("peerID", nodeID),
 				zap.Error(err),
@@ -203,20 +259,13 @@ func (g *Proposer) HandleAppGossip(ctx context.Context, nodeID ids.NodeID, msg [
 			return nil
 		}
 		batchVerifier.Add(txDigest, tx.Auth)
-
-		// Add incoming txs to the cache to make
-		// sure we never gossip anything we receive (someone
-		// else will)
-		if g.cache.Put(tx.ID(), nil) {
-			seen++
-		}
 	}
 	batchVerifier.Done(nil)
 	g.vm.RecordSeenTxsReceived(seen)
 
 	// Wait for signature verification to finish
 	if err := job.Wait(); err != nil {
-		g.vm.Logger().Warn(
+		g.vm.GossipLogger().Warn(
 			"received invalid gossip",
 			zap.Stringer("peerID", nodeID),
 			zap.Error(err),
@@ -227,7 +276,7 @@ func (g *Proposer) HandleAppGossip(ctx context.Context, nodeID ids.NodeID, msg [
 	// Mark incoming gossip as held by [nodeID], if it is a validator
 	isValidator, err := g.vm.IsValidator(ctx, nodeID)
 	if err != nil {
-		g.vm.Logger().Warn(
+		g.vm.GossipLogger().Warn(
 			"unable to determine if nodeID is validator",
 			zap.Stringer("peerID", nodeID),
 			zap.Error(err),
@@ -236,22 +285,28 @@ func (g *Proposer) HandleAppGossip(ctx context.Context, nodeID ids.NodeID, msg [
 
 	// Submit incoming gossip to mempool
 	start := time.Now()
-	for _, err := range g.vm.Submit(ctx, false, txs) {
+	var errs []error
+	if deferred {
+		errs = g.vm.SubmitDeferred(ctx, txs)
+	} else {
+		errs = g.vm.Submit(ctx, false, txs)
+	}
+	for _, err := range errs {
 		if err == nil || errors.Is(err, chain.ErrDuplicateTx) {
 			continue
 		}
-		g.vm.Logger().Debug(
+		g.vm.GossipLogger().Debug(
 			"failed to submit gossiped txs",
-			zap.Stringer("nodeID", nodeID),
+			zap.Stringer("peerID", nodeID),
 			zap.Bool("validator", isValidator),
 			zap.Error(err),
 		)
 	}
-	g.vm.Logger().Debug(
+	g.vm.GossipLogger().Debug(
 		"tx gossip received",
 		zap.Int("txs", len(txs)),
 		zap.Int("previously seen", seen),
-		zap.Stringer("nodeID", nodeID),
+		zap.Stringer("peerID", nodeID),
 		zap.Bool("validator", isValidator),
 		zap.Duration("t", time.Since(start)),
 	)
@@ -261,6 +316,21 @@ func (g *Proposer) HandleAppGossip(ctx context.Context, nodeID ids.NodeID, msg [
 	return nil
 }
 
+func (g *Proposer) HandleBundleGossip(ctx context.Context, nodeID ids.NodeID, msg []byte) error {
+	bundles := parseBundles(g.vm, nodeID, msg)
+
+	// Add incoming bundles to our cache to prevent useless gossip
+	seen := 0
+	for _, bundle := range bundles {
+		if g.cache.Put(bundle.ID(), nil) {
+			seen += len(bundle.Txs)
+		}
+	}
+	g.vm.RecordSeenTxsReceived(seen)
+	submitBundles(ctx, g.vm, nodeID, bundles)
+	return nil
+}
+
 func (g *Proposer) notify() {
 	select {
 	case g.q <- struct{}{}:
@@ -276,7 +346,7 @@ func (g *Proposer) handleTimerNotify() {
 
 func (g *Proposer) Queue(context.Context) {
 	if !g.waiting.CompareAndSwap(false, true) {
-		g.vm.Logger().Debug("unable to start waiting")
+		g.vm.GossipLogger().Debug("unable to start waiting")
 		return
 	}
 	now := time.Now().UnixMilli()
@@ -289,7 +359,7 @@ func (g *Proposer) Queue(context.Context) {
 	sleep := force - now
 	sleepDur := time.Duration(sleep * int64(time.Millisecond))
 	g.timer.SetTimeoutIn(sleepDur)
-	g.vm.Logger().Debug("waiting to notify to gossip", zap.Duration("t", sleepDur))
+	g.vm.GossipLogger().Debug("waiting to notify to gossip", zap.Duration("t", sleepDur))
 }
 
 // periodically but less aggressively force-regossip the pending
@@ -315,20 +385,20 @@ func (g *Proposer) Run(appSender common.AppSender) {
 				)
 				if err == nil && proposers.Contains(g.vm.NodeID()) {
 					g.Queue(tctx) // requeue later in case peer validator
-					g.vm.Logger().Debug("not gossiping because soon to propose")
+					g.vm.GossipLogger().Debug("not gossiping because soon to propose")
 					continue
 				} else if err != nil {
-					g.vm.Logger().Warn("unable to determine if will propose soon, gossiping anyways", zap.Error(err))
+					g.vm.GossipLogger().Warn("unable to determine if will propose soon, gossiping anyways", zap.Error(err))
 				}
 			}
 
 			// Gossip to proposers who will produce next
 			if err := g.Force(tctx); err != nil {
-				g.vm.Logger().Warn("gossip txs failed", zap.Error(err))
+				g.vm.GossipLogger().Warn("gossip txs failed", zap.Error(err))
 				continue
 			}
 		case <-g.vm.StopChan():
-			g.vm.Logger().Info("stopping gossip loop")
+			g.vm.GossipLogger().Info("stopping gossip loop")
 			return
 		}
 	}
@@ -350,23 +420,53 @@ func (g *Proposer) sendTxs(ctx context.Context, txs []*chain.Transaction) error
 	ctx, span := g.vm.Tracer().Start(ctx, "Gossiper.sendTxs")
 	defer span.End()
 
-	// Marshal gossip
-	b, err := chain.MarshalTxs(txs)
+	// Marshal gossip (the bytes are copied into the outbound message, so the
+	// writer can be reused once it is sent)
+	p, err := chain.MarshalTxsPooled(txs)
 	if err != nil {
 		return err
 	}
+	defer codec.PutWriter(p)
 
 	// Select next set of proposers and send gossip to them
+	recipients, err := g.recipients(ctx)
+	if err != nil {
+		return err
+	}
+	return g.appSender.SendAppGossip(ctx, common.SendConfig{NodeIDs: recipients}, p.Bytes())
+}
+
+func (g *Proposer) sendBundles(ctx context.Context, bundles []*chain.Bundle) error {
+	ctx, span := g.vm.Tracer().Start(ctx, "Gossiper.sendBundles")
+	defer span.End()
+
+	b, err := chain.MarshalBundles(bundles)
+	if err != nil {
+		return err
+	}
+	recipients, err := g.recipients(ctx)
+	if err != nil {
+		return err
+	}
+	if recipients.Len() == 0 {
+		// An empty set would send to all peers
+		return nil
+	}
+	return g.vm.SendBundleGossip(ctx, recipients, b)
+}
+
+// recipients returns the next set of proposers to send gossip to.
+func (g *Proposer) recipients(ctx context.Context) (set.Set[ids.NodeID], error) {
 	proposers, err := g.vm.Proposers(
 		ctx,
 		g.cfg.GossipProposerDiff,
 		g.cfg.GossipProposerDepth,
 	)
 	if err != nil {
-		return fmt.Errorf("%w: unable to fetch proposers", err)
+		return nil, fmt.Errorf("%w: unable to fetch proposers", err)
 	}
 	if proposers.Len() == 0 {
-		return errors.New("no proposers to gossip to")
+		return nil, errors.New("no proposers to gossip to")
 	}
 	recipients := set.NewSet[ids.NodeID](len(proposers))
 	for proposer := range proposers {
@@ -374,7 +474,12 @@ func (g *Proposer) sendTxs(ctx context.Context, txs []*chain.Transaction) error
 		if proposer == g.vm.NodeID() {
 			continue
 		}
+
+		// Don't gossip to peers that can't parse the message
+		if !g.vm.PeerFeatures(proposer).Has(g.cfg.GossipRequiredFeatures) {
+			continue
+		}
 		recipients.Add(proposer)
 	}
-	return g.appSender.SendAppGossip(ctx, common.SendConfig{NodeIDs: recipients}, b)
+	return recipients, nil
 }
@@ -0,0 +1,116 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package mempool
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+// SetBundles configures [m] to treat the items of a bundle (as returned by
+// [bundle], which returns nil for items that aren't in a bundle) as a unit:
+//
+//   - a bundle is only added if all of its items can be added (it is never
+//     added to the priority lane and never evicts other items)
+//   - the items of a bundle are kept consecutively (and in order), so they
+//     are always streamed (and passed to [Top]) together
+//   - when any item of a bundle is removed (or expires), the rest of the
+//     bundle is removed as well
+//
+// Like the priority lane, bundles are builder policy (not consensus).
+func (m *Mempool[T]) SetBundles(bundle func(T) []T) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bundle = bundle
+}
+
+// bundled returns the items of the bundle [item] is in (or nil if it isn't in
+// one).
+func (m *Mempool[T]) bundled(item T) []T {
+	if m.bundle == nil {
+		return nil
+	}
+	return m.bundle(item)
+}
+
+// complete returns true if all [items] are in [present].
+func (*Mempool[T]) complete(items []T, present set.Set[ids.ID]) bool {
+	for _, item := range items {
+		if !present.Contains(item.ID()) {
+			return false
+		}
+	}
+	return true
+}
+
+// addBundle adds all [items] (the items of a bundle) to the back (or
+// [front]) of the queue, or none of them.
+func (m *Mempool[T]) addBundle(items []T, front bool) {
+	var (
+		sponsors = map[codec.Address]int{}
+		size     = 0
+	)
+	for _, item := range items {
+		// Ensure no duplicate
+		itemID := item.ID()
+		if m.streamedItems != nil && m.streamedItems.Contains(itemID) {
+			return
+		}
+		if m.eh.Has(itemID) {
+			return
+		}
+		sponsors[item.Sponsor()]++
+		size += item.Size()
+	}
+
+	// Ensure no sender is abusing mempool
+	for sponsor, count := range sponsors {
+		if !m.exemptSponsors.Contains(sponsor) && m.owned[sponsor]+count > m.maxSponsorSize {
+			return
+		}
+	}
+
+	// Ensure mempool isn't full
+	if m.queue.Size()+len(items) > m.maxSize || (m.maxBytes > 0 && m.pendingSize+size > m.maxBytes) {
+		return
+	}
+
+	// Add to mempool (keeping the items in order)
+	if !front {
+		for _, item := range items {
+			m.push(m.queue, item, false)
+		}
+		return
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		m.push(m.queue, items[i], true)
+	}
+}
+
+// popNextItems removes and returns the highest valued item in m (and the rest
+// of its bundle, in order).
+func (m *Mempool[T]) popNextItems() []T {
+	next, ok := m.popNext()
+	if !ok {
+		return nil
+	}
+	members := m.bundled(next)
+	if len(members) == 0 {
+		return []T{next}
+	}
+	items := make([]T, 0, len(members))
+	for _, member := range members {
+		if member.ID() == next.ID() {
+			items = append(items, next)
+			continue
+		}
+		if v, ok := m.removeItem(member.ID()); ok {
+			items = append(items, v)
+		}
+	}
+	return items
+}
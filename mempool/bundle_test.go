@@ -0,0 +1,196 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package mempool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/trace"
+)
+
+// newBundleTestMempool returns a mempool that bundles the items passed to
+// the returned function.
+func newBundleTestMempool(maxSize int, maxSponsorSize int) (*Mempool[*TestItem], func(...*TestItem)) {
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	txm := New[*TestItem](tracer, maxSize, 0, maxSponsorSize, nil)
+	bundles := map[ids.ID][]*TestItem{}
+	txm.SetBundles(func(item *TestItem) []*TestItem {
+		return bundles[item.ID()]
+	})
+	return txm, func(items ...*TestItem) {
+		for _, item := range items {
+			bundles[item.ID()] = items
+		}
+	}
+}
+
+func itemIDs(items []*TestItem) []ids.ID {
+	txIDs := make([]ids.ID, len(items))
+	for i, item := range items {
+		txIDs[i] = item.ID()
+	}
+	return txIDs
+}
+
+func TestMempoolBundleAdd(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	txm, bundle := newBundleTestMempool(5, 2)
+
+	var (
+		other = codec.CreateAddress(2, ids.GenerateTestID())
+		a     = GenerateTestItem(testSponsor, 100)
+		b     = GenerateTestItem(other, 100)
+		c     = GenerateTestItem(testSponsor, 100)
+		d     = GenerateTestItem(testSponsor, 100)
+	)
+	bundle(a, b)
+	bundle(c, d)
+
+	// Bundles are only added if all of their items are added
+	txm.Add(ctx, []*TestItem{b})
+	require.Zero(txm.Len(ctx))
+	txm.Add(ctx, []*TestItem{b, a})
+	require.Equal(2, txm.Len(ctx))
+
+	// Bundles are dropped if any item can't be added
+	txm.Add(ctx, []*TestItem{GenerateTestItem(testSponsor, 100)})
+	txm.Add(ctx, []*TestItem{c, d})
+	require.Equal(3, txm.Len(ctx))
+	require.False(txm.Has(ctx, c.ID()))
+	require.False(txm.Has(ctx, d.ID()))
+
+	// Bundles are dropped if the mempool can't fit them
+	e := GenerateTestItem(codec.CreateAddress(4, ids.GenerateTestID()), 100)
+	f := GenerateTestItem(codec.CreateAddress(5, ids.GenerateTestID()), 100)
+	bundle(e, f)
+	txm.Add(ctx, []*TestItem{GenerateTestItem(codec.CreateAddress(3, ids.GenerateTestID()), 100)})
+	txm.Add(ctx, []*TestItem{e, f})
+	require.Equal(4, txm.Len(ctx))
+	require.False(txm.Has(ctx, e.ID()))
+}
+
+func TestMempoolBundleStream(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	txm, bundle := newBundleTestMempool(10, 10)
+
+	var (
+		a = GenerateTestItem(testSponsor, 100)
+		b = GenerateTestItem(testSponsor, 100)
+		c = GenerateTestItem(testSponsor, 100)
+		d = GenerateTestItem(testSponsor, 100)
+	)
+	bundle(b, c)
+	txm.Add(ctx, []*TestItem{a, b, c, d})
+
+	// Bundles are streamed together (even if that exceeds the count)
+	txm.StartStreaming(ctx)
+	streamed := txm.Stream(ctx, 2)
+	require.Equal([]ids.ID{a.ID(), b.ID(), c.ID()}, itemIDs(streamed))
+
+	// Streamed bundles can't be added again
+	txm.Add(ctx, []*TestItem{b, c})
+	require.Equal(1, txm.Len(ctx))
+
+	// Restored bundles are kept in order (and partial bundles are dropped)
+	txm.FinishStreaming(ctx, []*TestItem{c, a, b})
+	txm.StartStreaming(ctx)
+	require.Equal([]ids.ID{b.ID(), c.ID()}, itemIDs(txm.Stream(ctx, 1)))
+	txm.FinishStreaming(ctx, []*TestItem{b})
+	require.False(txm.Has(ctx, b.ID()))
+	require.Equal(2, txm.Len(ctx))
+}
+
+func TestMempoolBundleRemove(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	txm, bundle := newBundleTestMempool(10, 10)
+
+	var (
+		a = GenerateTestItem(testSponsor, 100)
+		b = GenerateTestItem(testSponsor, 200)
+		c = GenerateTestItem(testSponsor, 300)
+		d = GenerateTestItem(testSponsor, 300)
+		e = GenerateTestItem(testSponsor, 300)
+	)
+	bundle(a, b)
+	bundle(c, d)
+	txm.Add(ctx, []*TestItem{a, b, c, d, e})
+
+	// Expiry of any item expires the bundle
+	removed := txm.SetMinTimestamp(ctx, 150)
+	require.ElementsMatch([]ids.ID{a.ID(), b.ID()}, itemIDs(removed))
+
+	// Removing any item removes the bundle
+	txm.Remove(ctx, []*TestItem{d})
+	require.Equal(1, txm.Len(ctx))
+	require.True(txm.Has(ctx, e.ID()))
+	require.Equal(2, txm.Size(ctx))
+}
+
+func TestMempoolBundleTop(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	txm, bundle := newBundleTestMempool(10, 10)
+
+	var (
+		a = GenerateTestItem(testSponsor, 100)
+		b = GenerateTestItem(testSponsor, 100)
+		c = GenerateTestItem(testSponsor, 100)
+		d = GenerateTestItem(testSponsor, 100)
+	)
+	bundle(a, b, c)
+	txm.Add(ctx, []*TestItem{a, b, c, d})
+
+	// Bundles are restored if any item is restored
+	visited := []*TestItem{}
+	require.NoError(txm.Top(ctx, time.Minute, func(_ context.Context, item *TestItem) (bool, bool, error) {
+		visited = append(visited, item)
+		return true, item == b, nil
+	}))
+	require.Equal([]ids.ID{a.ID(), b.ID(), c.ID(), d.ID()}, itemIDs(visited))
+	require.Equal(3, txm.Len(ctx))
+
+	// Bundles are restored if not all items are visited
+	visited = visited[:0]
+	require.NoError(txm.Top(ctx, time.Minute, func(_ context.Context, item *TestItem) (bool, bool, error) {
+		visited = append(visited, item)
+		return item != b, false, nil
+	}))
+	require.Equal([]ids.ID{a.ID(), b.ID()}, itemIDs(visited))
+	require.Equal(3, txm.Len(ctx))
+	next, ok := txm.PeekNext(ctx)
+	require.True(ok)
+	require.Equal(a.ID(), next.ID())
+
+	// Bundles are removed if no item is restored
+	require.NoError(txm.Top(ctx, time.Minute, func(context.Context, *TestItem) (bool, bool, error) {
+		return true, false, nil
+	}))
+	require.Zero(txm.Len(ctx))
+}
+
+func TestMempoolBundleEviction(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	txm, bundle := newBundleTestMempool(2, 10)
+	txm.SetEviction(&EvictionConfig{FeeWeight: 1}, func(item *TestItem) uint64 {
+		return uint64(item.timestamp)
+	})
+
+	// Bundles are never evicted
+	a, b := GenerateTestItem(testSponsor, 1), GenerateTestItem(testSponsor, 1)
+	bundle(a, b)
+	txm.Add(ctx, []*TestItem{a, b})
+	txm.Add(ctx, []*TestItem{GenerateTestItem(testSponsor, 100)})
+	require.True(txm.Has(ctx, a.ID()))
+	require.True(txm.Has(ctx, b.ID()))
+}
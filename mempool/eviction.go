@@ -0,0 +1,139 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package mempool
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ava-labs/hypersdk/list"
+)
+
+// EvictionConfig determines which items are evicted to make room for a new
+// item when a [Mempool] is full (by item count or by size). Each item is
+// scored as:
+//
+//	FeeWeight * (fee / size) * 0.5^(age / AgeHalfLife) - SizeWeight * size
+//
+// where age is the time since the item was first admitted (re-admitting an
+// evicted item doesn't reset its age). A new item only evicts items with a
+// strictly lower score, so an item is never evicted by an item it outscores.
+//
+// Eviction is disabled if both weights are zero (the default): new items are
+// dropped while the mempool is full. Setting only [FeeWeight] evicts the items
+// with the lowest fee per byte.
+type EvictionConfig struct {
+	FeeWeight   float64       `json:"feeWeight"`
+	AgeHalfLife time.Duration `json:"ageHalfLife"` // 0 disables age decay
+	SizeWeight  float64       `json:"sizeWeight"`
+}
+
+// Enabled returns true if items may be evicted.
+func (c *EvictionConfig) Enabled() bool {
+	return c.FeeWeight != 0 || c.SizeWeight != 0
+}
+
+// Score returns the score of an item with [fee] and [size] that was admitted
+// [age] ago.
+func (c *EvictionConfig) Score(fee uint64, size int, age time.Duration) float64 {
+	var score float64
+	if size > 0 {
+		score = c.FeeWeight * float64(fee) / float64(size)
+	}
+	if c.AgeHalfLife > 0 && age > 0 {
+		score *= math.Exp2(-float64(age) / float64(c.AgeHalfLife))
+	}
+	return score - c.SizeWeight*float64(size)
+}
+
+// SetEviction configures [m] to evict the lowest scoring items (as scored by
+// [config] using the [fee] of each item) to make room for new items when it
+// is full. Items are only evicted from the queue the new item is added to
+// (so regular items can't evict priority lane items).
+//
+// Items being streamed and bundles can't be evicted (and bundles never evict
+// other items).
+func (m *Mempool[T]) SetEviction(config *EvictionConfig, fee func(T) uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !config.Enabled() {
+		m.eviction, m.fee = nil, nil
+		return
+	}
+	m.eviction, m.fee = config, fee
+}
+
+// hasRoom returns true if an item of [size] can be added to [queue] (which
+// holds up to [maxSize] items).
+func (m *Mempool[T]) hasRoom(queue *list.List[T], maxSize int, size int) bool {
+	if queue.Size() >= maxSize {
+		return false
+	}
+	return m.maxBytes == 0 || m.pendingSize+size <= m.maxBytes
+}
+
+// score returns the score of [item] at [now].
+func (m *Mempool[T]) score(item T, now time.Time) float64 {
+	var age time.Duration
+	if a, ok := m.admitted.Get(item.ID()); ok {
+		age = now.Sub(a.time)
+	}
+	return m.eviction.Score(m.fee(item), item.Size(), age)
+}
+
+// evict removes the lowest scoring items in [queue] to make room for [item]
+// and returns true if there is now room for it. If there isn't enough room
+// after evicting all items with a lower score than [item], nothing is
+// evicted.
+func (m *Mempool[T]) evict(queue *list.List[T], maxSize int, item T) bool {
+	size := item.Size()
+	if m.eviction == nil || (m.maxBytes > 0 && size > m.maxBytes) {
+		return false
+	}
+
+	type candidate struct {
+		elem  *list.Element[T]
+		score float64
+	}
+	var (
+		now        = m.now()
+		score      = m.score(item, now)
+		candidates = []candidate{}
+	)
+	for elem := queue.First(); elem != nil; elem = elem.Next() {
+		if len(m.bundled(elem.Value())) > 0 {
+			continue // bundles are never evicted
+		}
+		if s := m.score(elem.Value(), now); s < score {
+			candidates = append(candidates, candidate{elem, s})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(a.score, b.score)
+	})
+
+	var (
+		count = queue.Size()
+		bytes = m.pendingSize
+		evict = 0
+	)
+	for count >= maxSize || (m.maxBytes > 0 && bytes+size > m.maxBytes) {
+		if evict == len(candidates) {
+			return false
+		}
+		count--
+		bytes -= candidates[evict].elem.Value().Size()
+		evict++
+	}
+	for _, c := range candidates[:evict] {
+		v := m.removeElem(c.elem)
+		m.eh.Remove(v.ID())
+		m.removeFromOwned(v)
+		m.pendingSize -= v.Size()
+	}
+	return true
+}
@@ -0,0 +1,253 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/trace"
+)
+
+// evictionTestItem is a [TestItem] with a fee and a size.
+type evictionTestItem struct {
+	TestItem
+
+	fee  uint64
+	size int
+}
+
+func (e *evictionTestItem) Size() int { return e.size }
+
+func evictionTestFee(e *evictionTestItem) uint64 { return e.fee }
+
+func newEvictionTestItem(expiry int64, fee uint64, size int) *evictionTestItem {
+	return &evictionTestItem{
+		TestItem: TestItem{id: ids.GenerateTestID(), sponsor: testSponsor, timestamp: expiry},
+		fee:      fee,
+		size:     size,
+	}
+}
+
+func TestEvictionConfigScore(t *testing.T) {
+	require := require.New(t)
+
+	// Eviction is disabled by default
+	require.False((&EvictionConfig{}).Enabled())
+	require.False((&EvictionConfig{AgeHalfLife: time.Second}).Enabled())
+
+	// Items are scored by fee per byte...
+	c := &EvictionConfig{FeeWeight: 2}
+	require.True(c.Enabled())
+	require.InDelta(20.0, c.Score(100, 10, time.Hour), 0)
+
+	// ...which decays with age...
+	c.AgeHalfLife = time.Minute
+	require.InDelta(20.0, c.Score(100, 10, 0), 0)
+	require.InDelta(10.0, c.Score(100, 10, time.Minute), 1e-9)
+	require.InDelta(5.0, c.Score(100, 10, 2*time.Minute), 1e-9)
+
+	// ...and is reduced by size
+	c.SizeWeight = 0.5
+	require.InDelta(15.0, c.Score(100, 10, 0), 0)
+}
+
+func TestMempoolMaxBytes(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	txm := New[*evictionTestItem](tracer, 10, 100, 10, nil)
+
+	// Items are dropped once the size limit is reached (even if there is
+	// room for more items)
+	a := newEvictionTestItem(100, 1, 60)
+	b := newEvictionTestItem(100, 1_000, 50)
+	c := newEvictionTestItem(100, 1, 40)
+	txm.Add(ctx, []*evictionTestItem{a, b, c})
+	require.True(txm.Has(ctx, a.ID()))
+	require.False(txm.Has(ctx, b.ID()))
+	require.True(txm.Has(ctx, c.ID()))
+	require.Equal(100, txm.Size(ctx))
+
+	// Room is made when items leave the mempool
+	txm.Remove(ctx, []*evictionTestItem{a})
+	txm.Add(ctx, []*evictionTestItem{b})
+	require.True(txm.Has(ctx, b.ID()))
+	require.Equal(90, txm.Size(ctx))
+}
+
+func TestMempoolEviction(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	now := time.UnixMilli(0)
+	txm := New[*evictionTestItem](tracer, 3, 100, 10, nil)
+	txm.now = func() time.Time { return now }
+	txm.SetEviction(&EvictionConfig{FeeWeight: 1, AgeHalfLife: time.Second}, evictionTestFee)
+
+	var (
+		low  = newEvictionTestItem(100, 10, 10)  // 1 per byte
+		mid  = newEvictionTestItem(100, 30, 10)  // 3 per byte
+		high = newEvictionTestItem(100, 100, 10) // 10 per byte
+	)
+	txm.Add(ctx, []*evictionTestItem{low, mid, high})
+	require.Equal(3, txm.Len(ctx))
+
+	// Items that don't outscore any item are dropped
+	tie := newEvictionTestItem(100, 10, 10)
+	txm.Add(ctx, []*evictionTestItem{tie})
+	require.False(txm.Has(ctx, tie.ID()))
+
+	// The lowest scoring item is evicted
+	better := newEvictionTestItem(100, 20, 10)
+	txm.Add(ctx, []*evictionTestItem{better})
+	require.True(txm.Has(ctx, better.ID()))
+	require.False(txm.Has(ctx, low.ID()))
+	require.Equal(3, txm.Len(ctx))
+	require.Equal(30, txm.Size(ctx))
+
+	// Multiple items are evicted (lowest score first) to make room for a
+	// large item...
+	large := newEvictionTestItem(100, 450, 90) // 5 per byte
+	txm.Add(ctx, []*evictionTestItem{large})
+	require.True(txm.Has(ctx, large.ID()))
+	require.False(txm.Has(ctx, better.ID()))
+	require.False(txm.Has(ctx, mid.ID()))
+	require.True(txm.Has(ctx, high.ID()))
+	require.Equal(100, txm.Size(ctx))
+
+	// ...unless evicting all lower scoring items doesn't make enough room
+	huge := newEvictionTestItem(100, 760, 95) // 8 per byte
+	txm.Add(ctx, []*evictionTestItem{huge})
+	require.False(txm.Has(ctx, huge.ID()))
+	require.True(txm.Has(ctx, large.ID()))
+	require.True(txm.Has(ctx, high.ID()))
+
+	// Scores decay with age, so fresh items can evict items that used to
+	// outscore them...
+	now = now.Add(2 * time.Second) // [high] now scores 2.5 per byte
+	fresh := []*evictionTestItem{
+		newEvictionTestItem(100, 30, 10),
+		newEvictionTestItem(100, 30, 10),
+		newEvictionTestItem(100, 30, 10),
+	}
+	txm.Add(ctx, fresh)
+	require.False(txm.Has(ctx, large.ID()))
+	require.False(txm.Has(ctx, high.ID()))
+	require.Equal(3, txm.Len(ctx))
+
+	// ...but evicted items keep their age when they are re-admitted
+	txm.Remove(ctx, fresh[:1])
+	txm.Add(ctx, []*evictionTestItem{low}) // scores 0.25 per byte
+	require.True(txm.Has(ctx, low.ID()))
+	txm.Add(ctx, []*evictionTestItem{high})
+	require.True(txm.Has(ctx, high.ID()))
+	require.False(txm.Has(ctx, low.ID()))
+	require.InDelta(2.5, txm.score(high, now), 1e-9)
+}
+
+func TestMempoolEvictionPriorityLane(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	oracle := codec.CreateAddress(1, ids.GenerateTestID())
+	txm := New[*evictionTestItem](tracer, 1, 0, 10, nil)
+	txm.SetPriorityLane(func(item *evictionTestItem) bool {
+		return item.Sponsor() == oracle
+	}, 1)
+	txm.SetEviction(&EvictionConfig{FeeWeight: 1}, evictionTestFee)
+
+	lane := newEvictionTestItem(100, 1, 10)
+	lane.sponsor = oracle
+	txm.Add(ctx, []*evictionTestItem{lane})
+
+	// Regular items only evict regular items
+	regular := newEvictionTestItem(100, 10, 10)
+	txm.Add(ctx, []*evictionTestItem{regular})
+	require.True(txm.Has(ctx, lane.ID()))
+	require.True(txm.Has(ctx, regular.ID()))
+	better := newEvictionTestItem(100, 100, 10)
+	txm.Add(ctx, []*evictionTestItem{better})
+	require.True(txm.Has(ctx, lane.ID()))
+	require.False(txm.Has(ctx, regular.ID()))
+	require.True(txm.Has(ctx, better.ID()))
+}
+
+// TestMempoolEvictionNoStarvation ensures that an item with a sufficient fee
+// is never evicted (regardless of the items added after it or how often
+// evicted items are re-admitted), so it is eventually either built or expires.
+func TestMempoolEvictionNoStarvation(t *testing.T) {
+	const (
+		validity = 60_000 // ms
+		halfLife = 10 * time.Second
+
+		maxSize  = 16
+		maxBytes = 512
+
+		// Competing items pay less than [maxFee] and are at least 1 byte, so
+		// they always score less than [maxFee] per byte. The target item
+		// still scores more than that when it expires (after 6 half-lives).
+		maxFee        = 1_000
+		targetSize    = 10
+		sufficientFee = targetSize * (maxFee + 1) * 64 * 2
+	)
+	config := &EvictionConfig{FeeWeight: 1, AgeHalfLife: halfLife, SizeWeight: 0.01}
+	for seed := int64(0); seed < 50; seed++ {
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+			tracer, _ := trace.New(&trace.Config{Enabled: false})
+			rng := rand.New(rand.NewSource(seed)) //nolint:gosec
+
+			var nowMs int64
+			txm := New[*evictionTestItem](tracer, maxSize, maxBytes, 0, []codec.Address{testSponsor})
+			txm.now = func() time.Time { return time.UnixMilli(nowMs) }
+			txm.SetEviction(config, evictionTestFee)
+
+			var seen []*evictionTestItem
+			competitor := func() *evictionTestItem {
+				if len(seen) > 0 && rng.Intn(3) == 0 {
+					// Re-gossip an item that may have been evicted
+					return seen[rng.Intn(len(seen))]
+				}
+				item := newEvictionTestItem(nowMs+validity, uint64(rng.Intn(maxFee)), 1+rng.Intn(100))
+				seen = append(seen, item)
+				return item
+			}
+			for i := 0; i < 2*maxSize; i++ {
+				txm.Add(ctx, []*evictionTestItem{competitor()})
+			}
+			target := newEvictionTestItem(nowMs+validity, sufficientFee, targetSize)
+			txm.Add(ctx, []*evictionTestItem{target})
+
+			for {
+				require.True(txm.Has(ctx, target.ID()), "target evicted at %d ms", nowMs)
+				require.LessOrEqual(txm.Len(ctx), maxSize)
+				require.LessOrEqual(txm.Size(ctx), maxBytes)
+
+				nowMs += int64(rng.Intn(500))
+				if expired := txm.SetMinTimestamp(ctx, nowMs); !txm.Has(ctx, target.ID()) {
+					require.Contains(expired, target)
+					require.Less(target.Expiry(), nowMs)
+					return
+				}
+				for i := rng.Intn(4); i > 0; i-- {
+					txm.Add(ctx, []*evictionTestItem{competitor()})
+				}
+				if rng.Intn(50) == 0 {
+					if built, _ := txm.PopNext(ctx); built == target {
+						return
+					}
+				}
+			}
+		})
+	}
+}
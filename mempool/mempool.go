@@ -35,11 +35,19 @@ type Mempool[T Item] struct {
 	pendingSize int // bytes
 
 	maxSize        int
+	maxBytes       int // Maximum size of all items (or 0 if unlimited)
 	maxSponsorSize int // Maximum items allowed by a single sponsor
 
 	queue *list.List[T]
 	eh    *eheap.ExpiryHeap[*list.Element[T]]
 
+	// priorityQueue holds items matching [priority]. It is bounded separately
+	// from [queue] (so a flood of other items can't crowd it out) and is always
+	// drained before [queue].
+	priority        func(T) bool
+	maxPrioritySize int
+	priorityQueue   *list.List[T]
+
 	// owned tracks the number of items in the mempool owned by a single
 	// [Sponsor]
 	owned map[codec.Address]int
@@ -53,13 +61,49 @@ type Mempool[T Item] struct {
 
 	// sponsors that are exempt from [maxSponsorSize]
 	exemptSponsors set.Set[codec.Address]
+
+	// reserved tracks the sum of the amounts reserved by all items in the
+	// mempool from each address (if [reservations] is set)
+	reservations func(T) map[codec.Address]uint64
+	reserved     map[codec.Address]uint64
+
+	// admitted records when each item was first added to the mempool (until
+	// it expires or is popped with [PopAdmitted]). Unlike [eh], items are
+	// not removed from [admitted] when they are streamed or removed, so the
+	// time an item waited to be included can be measured after it leaves the
+	// mempool.
+	admitted *eheap.ExpiryHeap[*admission]
+
+	// bundle returns the items of the bundle an item is in (or nil if it
+	// isn't in one). The items of a bundle are added, streamed, and removed
+	// as a unit (see [SetBundles]).
+	bundle func(T) []T
+
+	// eviction scores items (using their [fee]) to make room for new items
+	// when the mempool is full (if nil, new items are dropped while full)
+	eviction *EvictionConfig
+	fee      func(T) uint64
+
+	now func() time.Time
+}
+
+// admission records the time an item was admitted to the mempool.
+type admission struct {
+	id     ids.ID
+	expiry int64
+	time   time.Time
 }
 
+func (a *admission) ID() ids.ID    { return a.id }
+func (a *admission) Expiry() int64 { return a.expiry }
+
 // New creates a new [Mempool]. [maxSize] must be > 0 or else the
-// implementation may panic.
+// implementation may panic. If [maxBytes] is 0, the size of the items in the
+// mempool is unlimited.
 func New[T Item](
 	tracer trace.Tracer,
 	maxSize int, // items
+	maxBytes int,
 	maxSponsorSize int,
 	exemptSponsors []codec.Address,
 ) *Mempool[T] {
@@ -67,13 +111,19 @@ func New[T Item](
 		tracer: tracer,
 
 		maxSize:        maxSize,
+		maxBytes:       maxBytes,
 		maxSponsorSize: maxSponsorSize,
 
-		queue: &list.List[T]{},
-		eh:    eheap.New[*list.Element[T]](min(maxSize, maxPrealloc)),
+		queue:         &list.List[T]{},
+		eh:            eheap.New[*list.Element[T]](min(maxSize, maxPrealloc)),
+		priorityQueue: &list.List[T]{},
 
 		owned:          map[codec.Address]int{},
 		exemptSponsors: set.Set[codec.Address]{},
+		reserved:       map[codec.Address]uint64{},
+		admitted:       eheap.New[*admission](min(maxSize, maxPrealloc)),
+
+		now: time.Now,
 	}
 	for _, sponsor := range exemptSponsors {
 		m.exemptSponsors.Add(sponsor)
@@ -81,7 +131,88 @@ func New[T Item](
 	return m
 }
 
+// SetPriorityLane configures [m] to hold up to [maxSize] items matching
+// [match] in a separate lane that is streamed before all other items.
+//
+// Lane membership only affects the order items are returned from [m] (it is
+// builder policy, not consensus).
+func (m *Mempool[T]) SetPriorityLane(match func(T) bool, maxSize int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.priority = match
+	m.maxPrioritySize = maxSize
+}
+
+// SetReservations configures [m] to track the amounts reserved by each item
+// from each address (as returned by [reservations]). Reservations are released
+// when an item is removed from [m] for any reason.
+//
+// Reservations are policy (used to avoid admitting items that can't all be
+// paid for), so [reservations] may rely on hints that are not verified.
+func (m *Mempool[T]) SetReservations(reservations func(T) map[codec.Address]uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reservations = reservations
+}
+
+// Reserved returns the sum of the amounts reserved from [addr] by all items in
+// m.
+func (m *Mempool[T]) Reserved(addr codec.Address) uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.reserved[addr]
+}
+
+func (m *Mempool[T]) reserve(item T) {
+	if m.reservations == nil {
+		return
+	}
+	for addr, amount := range m.reservations(item) {
+		reserved := m.reserved[addr] + amount
+		if reserved < amount {
+			reserved = ^uint64(0) // saturate on overflow
+		}
+		m.reserved[addr] = reserved
+	}
+}
+
+func (m *Mempool[T]) release(item T) {
+	if m.reservations == nil {
+		return
+	}
+	for addr, amount := range m.reservations(item) {
+		reserved := m.reserved[addr]
+		if reserved <= amount {
+			delete(m.reserved, addr)
+			continue
+		}
+		m.reserved[addr] = reserved - amount
+	}
+}
+
+// PriorityLen returns the number of items in the priority lane of m.
+func (m *Mempool[T]) PriorityLen(ctx context.Context) int {
+	_, span := m.tracer.Start(ctx, "Mempool.PriorityLen")
+	defer span.End()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.priorityQueue.Size()
+}
+
+// removeElem removes [elem] from whichever queue it is in.
+func (m *Mempool[T]) removeElem(elem *list.Element[T]) T {
+	m.priorityQueue.Remove(elem)
+	return m.queue.Remove(elem)
+}
+
 func (m *Mempool[T]) removeFromOwned(item T) {
+	m.release(item)
+
 	sender := item.Sponsor()
 	items, ok := m.owned[sender]
 	if !ok {
@@ -108,8 +239,8 @@ func (m *Mempool[T]) Has(ctx context.Context, itemID ids.ID) bool {
 
 // Add pushes all new items from [items] to m. Does not add a item if
 // the item sponsor is not exempt and their items in the mempool exceed m.maxSponsorSize.
-// If the size of m exceeds m.maxSize, Add pops the lowest value item
-// from m.eh.
+// If m is full (by item count or size), the item is dropped unless it can
+// evict lower scoring items (see [SetEviction]).
 func (m *Mempool[T]) Add(ctx context.Context, items []T) {
 	_, span := m.tracer.Start(ctx, "Mempool.Add")
 	defer span.End()
@@ -121,7 +252,25 @@ func (m *Mempool[T]) Add(ctx context.Context, items []T) {
 }
 
 func (m *Mempool[T]) add(items []T, front bool) {
+	var present set.Set[ids.ID] // only populated if [items] contains a bundle
 	for _, item := range items {
+		// Bundles are added when their first item is reached (if all of their
+		// items are in [items])
+		if members := m.bundled(item); len(members) > 0 {
+			if members[0].ID() != item.ID() {
+				continue
+			}
+			if present == nil {
+				present = set.NewSet[ids.ID](len(items))
+				for _, item := range items {
+					present.Add(item.ID())
+				}
+			}
+			if m.complete(members, present) {
+				m.addBundle(members, front)
+			}
+			continue
+		}
 		sender := item.Sponsor()
 
 		// Ensure no duplicate
@@ -140,21 +289,39 @@ func (m *Mempool[T]) add(items []T, front bool) {
 			continue // do nothing, wait for items to expire
 		}
 
-		// Ensure mempool isn't full
-		if m.queue.Size() == m.maxSize {
+		// Ensure mempool isn't full (or make room by evicting lower scoring
+		// items)
+		queue, maxSize := m.queue, m.maxSize
+		if m.priority != nil && m.priority(item) {
+			queue, maxSize = m.priorityQueue, m.maxPrioritySize
+		}
+		if !m.hasRoom(queue, maxSize, item.Size()) && !m.evict(queue, maxSize, item) {
 			continue // do nothing, wait for items to expire
 		}
 
 		// Add to mempool
-		var elem *list.Element[T]
-		if !front {
-			elem = m.queue.PushBack(item)
-		} else {
-			elem = m.queue.PushFront(item)
-		}
-		m.eh.Add(elem)
-		m.owned[sender]++
-		m.pendingSize += item.Size()
+		m.push(queue, item, front)
+	}
+}
+
+// push adds [item] to the back (or [front]) of [queue].
+func (m *Mempool[T]) push(queue *list.List[T], item T, front bool) {
+	var elem *list.Element[T]
+	if !front {
+		elem = queue.PushBack(item)
+	} else {
+		elem = queue.PushFront(item)
+	}
+	m.eh.Add(elem)
+	m.owned[item.Sponsor()]++
+	m.reserve(item)
+	m.pendingSize += item.Size()
+
+	// Items that are restored (or re-added after a block is rejected)
+	// keep the time they were first admitted
+	itemID := item.ID()
+	if !m.admitted.Has(itemID) {
+		m.admitted.Add(&admission{id: itemID, expiry: item.Expiry(), time: m.now()})
 	}
 }
 
@@ -167,15 +334,37 @@ func (m *Mempool[T]) PeekNext(ctx context.Context) (T, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	first := m.queue.First()
+	first := m.first()
 	if first == nil {
 		return *new(T), false
 	}
 	return first.Value(), true
 }
 
+// Snapshot returns up to [limit] of the highest-valued items in m (in the
+// order they would be streamed) without removing them. Items that are being
+// streamed are not included.
+func (m *Mempool[T]) Snapshot(ctx context.Context, limit int) []T {
+	_, span := m.tracer.Start(ctx, "Mempool.Snapshot")
+	defer span.End()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := make([]T, 0, min(limit, m.eh.Len()))
+	for _, l := range []*list.List[T]{m.priorityQueue, m.queue} {
+		for elem := l.First(); elem != nil && len(items) < limit; elem = elem.Next() {
+			items = append(items, elem.Value())
+		}
+	}
+	return items
+}
+
 // PopNext removes and returns the highest valued item in m.eh.
 // Assumes there is non-zero items in [Mempool]
+//
+// If the item is in a bundle, the rest of the bundle is removed as well (a
+// bundle is never partially in m).
 func (m *Mempool[T]) PopNext(ctx context.Context) (T, bool) { // O(log N)
 	_, span := m.tracer.Start(ctx, "Mempool.PopNext")
 	defer span.End()
@@ -183,15 +372,28 @@ func (m *Mempool[T]) PopNext(ctx context.Context) (T, bool) { // O(log N)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	return m.popNext()
+	items := m.popNextItems()
+	if len(items) == 0 {
+		return *new(T), false
+	}
+	return items[0], true
+}
+
+// first returns the next item in the priority lane, if any, or else the next
+// item in the regular queue.
+func (m *Mempool[T]) first() *list.Element[T] {
+	if first := m.priorityQueue.First(); first != nil {
+		return first
+	}
+	return m.queue.First()
 }
 
 func (m *Mempool[T]) popNext() (T, bool) {
-	first := m.queue.First()
+	first := m.first()
 	if first == nil {
 		return *new(T), false
 	}
-	v := m.queue.Remove(first)
+	v := m.removeElem(first)
 	m.eh.Remove(v.ID())
 	m.removeFromOwned(v)
 	m.pendingSize -= v.Size()
@@ -207,14 +409,42 @@ func (m *Mempool[T]) Remove(ctx context.Context, items []T) {
 	defer m.mu.Unlock()
 
 	for _, item := range items {
-		elem, ok := m.eh.Remove(item.ID())
+		v, ok := m.removeItem(item.ID())
 		if !ok {
 			continue
 		}
-		m.queue.Remove(elem)
-		m.removeFromOwned(item)
-		m.pendingSize -= item.Size()
+
+		// The rest of the bundle can no longer be included as a unit
+		for _, member := range m.bundled(v) {
+			m.removeItem(member.ID())
+		}
+	}
+}
+
+// removeItem removes the item with [itemID] from m (if it is in m).
+func (m *Mempool[T]) removeItem(itemID ids.ID) (T, bool) {
+	elem, ok := m.eh.Remove(itemID)
+	if !ok {
+		return *new(T), false
 	}
+	v := m.removeElem(elem)
+	m.removeFromOwned(v)
+	m.pendingSize -= v.Size()
+	return v, true
+}
+
+// PopAdmitted returns the time the item with [itemID] was first added to m
+// (and stops tracking it). It returns false if the item was never added to m
+// (or expired).
+func (m *Mempool[T]) PopAdmitted(itemID ids.ID) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.admitted.Remove(itemID)
+	if !ok {
+		return time.Time{}, false
+	}
+	return a.time, true
 }
 
 // Len returns the number of items in m.
@@ -236,7 +466,8 @@ func (m *Mempool[T]) Size(context.Context) int {
 	return m.pendingSize
 }
 
-// SetMinTimestamp removes and returns all items with a lower expiry than [t] from m.
+// SetMinTimestamp removes and returns all items with a lower expiry than [t] from m
+// (and the rest of the bundles they are in).
 func (m *Mempool[T]) SetMinTimestamp(ctx context.Context, t int64) []T {
 	_, span := m.tracer.Start(ctx, "Mempool.SetMinTimesamp")
 	defer span.End()
@@ -244,15 +475,24 @@ func (m *Mempool[T]) SetMinTimestamp(ctx context.Context, t int64) []T {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.admitted.SetMin(t)
 	removedElems := m.eh.SetMin(t)
 	removed := make([]T, len(removedElems))
 	for i, remove := range removedElems {
-		m.queue.Remove(remove)
-		v := remove.Value()
+		v := m.removeElem(remove)
 		m.removeFromOwned(v)
 		m.pendingSize -= v.Size()
 		removed[i] = v
 	}
+
+	// A bundle expires when any of its items does
+	for _, v := range removed {
+		for _, member := range m.bundled(v) {
+			if member, ok := m.removeItem(member.ID()); ok {
+				removed = append(removed, member)
+			}
+		}
+	}
 	return removed
 }
 
@@ -274,12 +514,28 @@ func (m *Mempool[T]) Top(
 		err             error
 	)
 	for m.eh.Len() > 0 {
-		next, _ := m.popNext()
-		cont, restore, fErr := f(ctx, next)
+		// The items of a bundle are passed to [f] in order and the bundle is
+		// restored if [f] restores any of them (or stops before reaching
+		// all of them).
+		var (
+			items   = m.popNextItems()
+			cont    = true
+			restore bool
+			fErr    error
+		)
+		for i, next := range items {
+			var r bool
+			cont, r, fErr = f(ctx, next)
+			restore = restore || r
+			if !cont || fErr != nil {
+				restore = restore || i < len(items)-1
+				break
+			}
+		}
 		if restore {
 			// Waiting to restore unused transactions ensures that an account will be
 			// excluded from future price mempool iterations
-			restorableItems = append(restorableItems, next)
+			restorableItems = append(restorableItems, items...)
 		}
 		if !cont || time.Since(start) > targetDuration || fErr != nil {
 			err = fErr
@@ -341,12 +597,14 @@ func (m *Mempool[T]) Stream(ctx context.Context, count int) []T {
 func (m *Mempool[T]) streamItems(count int) []T {
 	txs := make([]T, 0, count)
 	for len(txs) < count {
-		item, ok := m.popNext()
-		if !ok {
+		items := m.popNextItems()
+		if len(items) == 0 {
 			break
 		}
-		m.streamedItems.Add(item.ID())
-		txs = append(txs, item)
+		for _, item := range items {
+			m.streamedItems.Add(item.ID())
+		}
+		txs = append(txs, items...)
 	}
 	return txs
 }
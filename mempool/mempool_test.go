@@ -6,6 +6,7 @@ package mempool
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/stretchr/testify/require"
@@ -55,7 +56,7 @@ func TestMempool(t *testing.T) {
 
 	ctx := context.TODO()
 	tracer, _ := trace.New(&trace.Config{Enabled: false})
-	txm := New[*TestItem](tracer, 3, 16, nil)
+	txm := New[*TestItem](tracer, 3, 0, 16, nil)
 
 	for _, i := range []int64{100, 200, 300, 400} {
 		item := GenerateTestItem(testSponsor, i)
@@ -75,7 +76,7 @@ func TestMempoolAddDuplicates(t *testing.T) {
 	defer ctrl.Finish()
 	ctx := context.TODO()
 	tracer, _ := trace.New(&trace.Config{Enabled: false})
-	txm := New[*TestItem](tracer, 3, 16, nil)
+	txm := New[*TestItem](tracer, 3, 0, 16, nil)
 	// Generate item
 	item := GenerateTestItem(testSponsor, 300)
 	items := []*TestItem{item}
@@ -100,7 +101,7 @@ func TestMempoolAddExceedMaxSponsorSize(t *testing.T) {
 	exemptSponsor := codec.CreateAddress(99, ids.GenerateTestID())
 	sponsor := codec.CreateAddress(4, ids.GenerateTestID())
 	// Non exempt sponsors max of 4
-	txm := New[*TestItem](tracer, 20, 4, []codec.Address{exemptSponsor})
+	txm := New[*TestItem](tracer, 20, 0, 4, []codec.Address{exemptSponsor})
 	// Add 6 transactions for each sponsor
 	for i := int64(0); i <= 5; i++ {
 		itemSponsor := GenerateTestItem(sponsor, i)
@@ -120,7 +121,7 @@ func TestMempoolAddExceedMaxSize(t *testing.T) {
 	ctx := context.TODO()
 	tracer, _ := trace.New(&trace.Config{Enabled: false})
 
-	txm := New[*TestItem](tracer, 3, 20, nil)
+	txm := New[*TestItem](tracer, 3, 0, 20, nil)
 	// Add more tx's than txm.maxSize
 	for i := int64(0); i < 10; i++ {
 		item := GenerateTestItem(testSponsor, i)
@@ -150,7 +151,7 @@ func TestMempoolRemoveTxs(t *testing.T) {
 	ctx := context.TODO()
 	tracer, _ := trace.New(&trace.Config{Enabled: false})
 
-	txm := New[*TestItem](tracer, 3, 20, nil)
+	txm := New[*TestItem](tracer, 3, 0, 20, nil)
 	// Add
 	item := GenerateTestItem(testSponsor, 10)
 	items := []*TestItem{item}
@@ -170,7 +171,7 @@ func TestMempoolSetMinTimestamp(t *testing.T) {
 	ctx := context.TODO()
 	tracer, _ := trace.New(&trace.Config{Enabled: false})
 
-	txm := New[*TestItem](tracer, 20, 20, nil)
+	txm := New[*TestItem](tracer, 20, 0, 20, nil)
 	// Add more tx's than txm.maxSize
 	for i := int64(0); i < 10; i++ {
 		item := GenerateTestItem(testSponsor, i)
@@ -192,3 +193,172 @@ func TestMempoolSetMinTimestamp(t *testing.T) {
 	// Mempool has same length
 	require.Equal(5, txm.Len(ctx), "Mempool has incorrect number of txs.")
 }
+
+func TestMempoolPriorityLane(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+
+	oracle := codec.CreateAddress(1, ids.GenerateTestID())
+	txm := New[*TestItem](tracer, 10, 0, 10, nil)
+	txm.SetPriorityLane(func(item *TestItem) bool {
+		return item.Sponsor() == oracle
+	}, 2)
+
+	// Flood the mempool with spam
+	for i := int64(0); i < 20; i++ {
+		txm.Add(ctx, []*TestItem{GenerateTestItem(codec.CreateAddress(1, ids.GenerateTestID()), i)})
+	}
+	require.Equal(10, txm.Len(ctx))
+	require.Zero(txm.PriorityLen(ctx))
+
+	// Oracle txs are still accepted (up to the lane size)
+	oracleItems := []*TestItem{
+		GenerateTestItem(oracle, 100),
+		GenerateTestItem(oracle, 101),
+		GenerateTestItem(oracle, 102),
+	}
+	txm.Add(ctx, oracleItems)
+	require.True(txm.Has(ctx, oracleItems[0].ID()))
+	require.True(txm.Has(ctx, oracleItems[1].ID()))
+	require.False(txm.Has(ctx, oracleItems[2].ID()))
+	require.Equal(12, txm.Len(ctx))
+	require.Equal(2, txm.PriorityLen(ctx))
+
+	// Oracle txs are streamed before spam
+	txm.StartStreaming(ctx)
+	streamed := txm.Stream(ctx, 3)
+	require.Equal(oracleItems[0].ID(), streamed[0].ID())
+	require.Equal(oracleItems[1].ID(), streamed[1].ID())
+	require.NotEqual(oracle, streamed[2].Sponsor())
+	require.Zero(txm.PriorityLen(ctx))
+
+	// Restored oracle txs return to the lane
+	require.Equal(1, txm.FinishStreaming(ctx, streamed[:1]))
+	require.Equal(1, txm.PriorityLen(ctx))
+	next, ok := txm.PeekNext(ctx)
+	require.True(ok)
+	require.Equal(oracleItems[0].ID(), next.ID())
+
+	// Expired lane items are removed
+	require.Len(txm.SetMinTimestamp(ctx, 101), 10)
+	require.Zero(txm.PriorityLen(ctx))
+}
+
+func TestMempoolReservations(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	txm := New[*TestItem](tracer, 10, 0, 16, nil)
+
+	// Each item reserves its expiry from its sponsor
+	txm.SetReservations(func(item *TestItem) map[codec.Address]uint64 {
+		return map[codec.Address]uint64{item.sponsor: uint64(item.timestamp)}
+	})
+	other := codec.CreateAddress(2, ids.GenerateTestID())
+	var (
+		a = GenerateTestItem(testSponsor, 100)
+		b = GenerateTestItem(testSponsor, 200)
+		c = GenerateTestItem(testSponsor, 300)
+		d = GenerateTestItem(other, 400)
+	)
+	txm.Add(ctx, []*TestItem{a, b, c, d})
+	require.Equal(uint64(600), txm.Reserved(testSponsor))
+	require.Equal(uint64(400), txm.Reserved(other))
+
+	// Duplicates don't reserve again
+	txm.Add(ctx, []*TestItem{a})
+	require.Equal(uint64(600), txm.Reserved(testSponsor))
+
+	// Released on inclusion
+	txm.Remove(ctx, []*TestItem{b})
+	require.Equal(uint64(400), txm.Reserved(testSponsor))
+
+	// Released on expiry
+	txm.SetMinTimestamp(ctx, 150)
+	require.Equal(uint64(300), txm.Reserved(testSponsor))
+
+	// Released while streamed and reserved again when restored
+	txm.StartStreaming(ctx)
+	streamed := txm.Stream(ctx, 2)
+	require.Len(streamed, 2)
+	require.Zero(txm.Reserved(testSponsor))
+	require.Zero(txm.Reserved(other))
+	txm.FinishStreaming(ctx, streamed[:1])
+	require.Equal(uint64(300), txm.Reserved(testSponsor))
+	require.Zero(txm.Reserved(other))
+}
+
+func TestMempoolPopAdmitted(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	txm := New[*TestItem](tracer, 3, 0, 16, nil)
+
+	start := time.Now()
+	item := GenerateTestItem(testSponsor, 100)
+	expired := GenerateTestItem(testSponsor, 10)
+	txm.Add(ctx, []*TestItem{item, expired})
+	admitted := time.Now()
+
+	// Streaming (and restoring) an item doesn't change when it was admitted
+	txm.StartStreaming(ctx)
+	require.Len(txm.Stream(ctx, 2), 2)
+	txm.FinishStreaming(ctx, []*TestItem{item})
+	txm.Add(ctx, []*TestItem{item})
+
+	// Expired items are no longer tracked
+	txm.SetMinTimestamp(ctx, 50)
+	_, ok := txm.PopAdmitted(expired.ID())
+	require.False(ok)
+
+	// Admission is tracked after the item leaves the mempool
+	txm.Remove(ctx, []*TestItem{item})
+	require.Zero(txm.Len(ctx))
+	at, ok := txm.PopAdmitted(item.ID())
+	require.True(ok)
+	require.False(at.Before(start))
+	require.False(at.After(admitted))
+
+	// Admission is only returned once
+	_, ok = txm.PopAdmitted(item.ID())
+	require.False(ok)
+	_, ok = txm.PopAdmitted(ids.GenerateTestID())
+	require.False(ok)
+}
+
+func TestMempoolSnapshot(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+
+	oracle := codec.CreateAddress(1, ids.GenerateTestID())
+	txm := New[*TestItem](tracer, 10, 0, 10, nil)
+	txm.SetPriorityLane(func(item *TestItem) bool {
+		return item.Sponsor() == oracle
+	}, 2)
+	items := []*TestItem{
+		GenerateTestItem(testSponsor, 100),
+		GenerateTestItem(testSponsor, 101),
+		GenerateTestItem(oracle, 102),
+	}
+	txm.Add(ctx, items)
+
+	// Items are returned in the order they are streamed (lane items first)
+	// without being removed
+	snapshot := txm.Snapshot(ctx, 10)
+	require.Equal([]*TestItem{items[2], items[0], items[1]}, snapshot)
+	require.Equal(3, txm.Len(ctx))
+	require.Equal(1, txm.PriorityLen(ctx))
+	require.Equal([]*TestItem{items[2], items[0]}, txm.Snapshot(ctx, 2))
+
+	txm.StartStreaming(ctx)
+	require.Equal(snapshot, txm.Stream(ctx, 10))
+
+	// Streamed items are not included
+	require.Empty(txm.Snapshot(ctx, 10))
+	require.Equal(3, txm.FinishStreaming(ctx, snapshot))
+	require.Len(txm.Snapshot(ctx, 10), 3)
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package merkle implements a binary merkle tree over an ordered list of
+// items (i.e. the results of a block) that supports inclusion proofs.
+//
+// Leaves and interior nodes are hashed with different prefixes to prevent
+// second-preimage attacks. If a level has an odd number of nodes, the last
+// node is promoted to the next level without being hashed.
+package merkle
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/utils"
+)
+
+const (
+	leafPrefix = 0x0
+	nodePrefix = 0x1
+)
+
+// Leaf returns the hash of the leaf containing [item].
+func Leaf(item []byte) ids.ID {
+	b := make([]byte, 1+len(item))
+	b[0] = leafPrefix
+	copy(b[1:], item)
+	return utils.ToID(b)
+}
+
+func node(left ids.ID, right ids.ID) ids.ID {
+	b := make([]byte, 1+ids.IDLen+ids.IDLen)
+	b[0] = nodePrefix
+	copy(b[1:], left[:])
+	copy(b[1+ids.IDLen:], right[:])
+	return utils.ToID(b)
+}
+
+// nextLevel hashes each pair of nodes in [level].
+func nextLevel(level []ids.ID) []ids.ID {
+	next := make([]ids.ID, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, node(level[i], level[i+1]))
+	}
+	return next
+}
+
+// Root returns the root of the tree with [leaves] (computed with [Leaf]). The
+// root of an empty tree is [ids.Empty].
+func Root(leaves []ids.ID) ids.ID {
+	if len(leaves) == 0 {
+		return ids.Empty
+	}
+	level := leaves
+	for len(level) > 1 {
+		level = nextLevel(level)
+	}
+	return level[0]
+}
+
+// Proof returns the sibling hashes needed to recompute the root of the tree
+// with [leaves] from the leaf at [index].
+func Proof(leaves []ids.ID, index int) ([]ids.ID, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, ErrInvalidIndex
+	}
+	var (
+		proof = []ids.ID{}
+		level = leaves
+	)
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		level = nextLevel(level)
+		index /= 2
+	}
+	return proof, nil
+}
+
+// Verify returns true if [proof] shows that [leaf] is at [index] in a tree of
+// [count] leaves with [root].
+func Verify(root ids.ID, leaf ids.ID, index int, count int, proof []ids.ID) bool {
+	if index < 0 || index >= count {
+		return false
+	}
+	computed := leaf
+	for width := count; width > 1; width = (width + 1) / 2 {
+		sibling := index ^ 1
+		if sibling < width {
+			if len(proof) == 0 {
+				return false
+			}
+			if index%2 == 0 {
+				computed = node(computed, proof[0])
+			} else {
+				computed = node(proof[0], computed)
+			}
+			proof = proof[1:]
+		}
+		index /= 2
+	}
+	return len(proof) == 0 && computed == root
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootEmpty(t *testing.T) {
+	require.Equal(t, ids.Empty, Root(nil))
+}
+
+func TestProof(t *testing.T) {
+	for count := 1; count <= 9; count++ {
+		t.Run(fmt.Sprintf("count=%d", count), func(t *testing.T) {
+			require := require.New(t)
+
+			leaves := make([]ids.ID, count)
+			for i := range leaves {
+				leaves[i] = Leaf([]byte{byte(i)})
+			}
+			root := Root(leaves)
+			for i, leaf := range leaves {
+				proof, err := Proof(leaves, i)
+				require.NoError(err)
+				require.True(Verify(root, leaf, i, count, proof))
+
+				// Proof must not verify a different leaf or position
+				require.False(Verify(root, Leaf([]byte{0xff}), i, count, proof))
+				if count > 1 {
+					require.False(Verify(root, leaf, (i+1)%count, count, proof))
+				}
+			}
+
+			_, err := Proof(leaves, count)
+			require.ErrorIs(err, ErrInvalidIndex)
+		})
+	}
+}
+
+func TestRootOrder(t *testing.T) {
+	require := require.New(t)
+
+	a, b := Leaf([]byte("a")), Leaf([]byte("b"))
+	require.NotEqual(Root([]ids.ID{a, b}), Root([]ids.ID{b, a}))
+	require.Equal(a, Root([]ids.ID{a}))
+}
@@ -0,0 +1,242 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/version"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/utils"
+)
+
+var _ Handler = (*BlockFetcher)(nil)
+
+// BlockFetcher requests blocks that are not available locally from peers
+// (with [Fetch]) and serves local blocks to peers that request them.
+//
+// A request is the ID of a block and a response is the bytes of that block
+// (or empty, if the peer does not have it).
+type BlockFetcher struct {
+	log      logging.Logger
+	sender   common.AppSender
+	getBlock func(context.Context, ids.ID) ([]byte, error)
+	peers    func() []ids.NodeID
+	timeout  time.Duration
+	retries  int
+
+	l         sync.Mutex
+	requestID uint32
+	requests  map[uint32]*blockRequest
+	fetches   map[ids.ID]*blockFetch
+}
+
+type blockRequest struct {
+	nodeID   ids.NodeID
+	response chan []byte
+}
+
+// blockFetch is shared by all concurrent callers of [Fetch] for the same
+// block. [blk] and [err] are set before [done] is closed.
+type blockFetch struct {
+	done chan struct{}
+	blk  []byte
+	err  error
+}
+
+// NewBlockFetcher returns a [BlockFetcher] that serves blocks with
+// [getBlock] and fetches blocks from [peers]. Each fetch is sent to at most
+// [retries] peers and each request times out after [timeout].
+func NewBlockFetcher(
+	log logging.Logger,
+	sender common.AppSender,
+	getBlock func(context.Context, ids.ID) ([]byte, error),
+	peers func() []ids.NodeID,
+	timeout time.Duration,
+	retries int,
+) *BlockFetcher {
+	return &BlockFetcher{
+		log:      log,
+		sender:   sender,
+		getBlock: getBlock,
+		peers:    peers,
+		timeout:  timeout,
+		retries:  retries,
+		requests: map[uint32]*blockRequest{},
+		fetches:  map[ids.ID]*blockFetch{},
+	}
+}
+
+// Fetch returns the bytes of [blkID] from a peer. Concurrent calls for the
+// same block share a single fetch.
+func (f *BlockFetcher) Fetch(ctx context.Context, blkID ids.ID) ([]byte, error) {
+	f.l.Lock()
+	fetch, ok := f.fetches[blkID]
+	if !ok {
+		fetch = &blockFetch{done: make(chan struct{})}
+		f.fetches[blkID] = fetch
+	}
+	f.l.Unlock()
+
+	if ok {
+		select {
+		case <-fetch.done:
+			return fetch.blk, fetch.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	fetch.blk, fetch.err = f.fetch(ctx, blkID)
+	f.l.Lock()
+	delete(f.fetches, blkID)
+	f.l.Unlock()
+	close(fetch.done)
+	return fetch.blk, fetch.err
+}
+
+func (f *BlockFetcher) fetch(ctx context.Context, blkID ids.ID) ([]byte, error) {
+	peers := f.peers()
+	if len(peers) == 0 {
+		return nil, ErrNoFetchPeers
+	}
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	for attempt := 0; attempt < f.retries; attempt++ {
+		nodeID := peers[attempt%len(peers)]
+		blk, err := f.request(ctx, nodeID, blkID)
+		if err == nil && utils.ToID(blk) != blkID {
+			err = ErrBlockFetchMismatch
+		}
+		if err == nil {
+			return blk, nil
+		}
+		f.log.Debug(
+			"unable to fetch block",
+			zap.Stringer("peerID", nodeID),
+			zap.Stringer("blkID", blkID),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, fmt.Errorf("%w: %s after %d attempts", ErrBlockFetchFailed, blkID, f.retries)
+}
+
+// request sends a request for [blkID] to [nodeID] and waits for the
+// response.
+func (f *BlockFetcher) request(ctx context.Context, nodeID ids.NodeID, blkID ids.ID) ([]byte, error) {
+	f.l.Lock()
+	requestID := f.requestID
+	f.requestID++
+	req := &blockRequest{nodeID: nodeID, response: make(chan []byte, 1)}
+	f.requests[requestID] = req
+	f.l.Unlock()
+
+	defer func() {
+		f.l.Lock()
+		delete(f.requests, requestID)
+		f.l.Unlock()
+	}()
+
+	if err := f.sender.SendAppRequest(ctx, set.Of(nodeID), requestID, blkID[:]); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(f.timeout)
+	defer timer.Stop()
+	select {
+	case blk := <-req.response:
+		if len(blk) == 0 {
+			return nil, ErrBlockUnavailable
+		}
+		return blk, nil
+	case <-timer.C:
+		return nil, ErrBlockFetchTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// deliver passes [blk] to the caller waiting on [requestID] (a failed request
+// is delivered as nil).
+func (f *BlockFetcher) deliver(nodeID ids.NodeID, requestID uint32, blk []byte) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	req, ok := f.requests[requestID]
+	if !ok || req.nodeID != nodeID {
+		f.log.Debug(
+			"dropping unexpected block response",
+			zap.Stringer("peerID", nodeID),
+			zap.Uint32("requestID", requestID),
+		)
+		return
+	}
+	delete(f.requests, requestID)
+	req.response <- blk
+}
+
+func (*BlockFetcher) Connected(context.Context, ids.NodeID, *version.Application) error {
+	return nil
+}
+
+func (*BlockFetcher) Disconnected(context.Context, ids.NodeID) error {
+	return nil
+}
+
+func (*BlockFetcher) AppGossip(context.Context, ids.NodeID, []byte) error {
+	return nil
+}
+
+func (f *BlockFetcher) AppRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, _ time.Time, msg []byte) error {
+	blkID, err := ids.ToID(msg)
+	if err != nil {
+		f.log.Debug(
+			"dropping invalid block request",
+			zap.Stringer("peerID", nodeID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	// Respond with no bytes if we don't have the block, so the peer can
+	// immediately try another
+	blk, err := f.getBlock(ctx, blkID)
+	if err != nil {
+		blk = []byte{}
+	}
+	return f.sender.SendAppResponse(ctx, nodeID, requestID, blk)
+}
+
+func (f *BlockFetcher) AppRequestFailed(_ context.Context, nodeID ids.NodeID, requestID uint32) error {
+	f.deliver(nodeID, requestID, nil)
+	return nil
+}
+
+func (f *BlockFetcher) AppResponse(_ context.Context, nodeID ids.NodeID, requestID uint32, msg []byte) error {
+	f.deliver(nodeID, requestID, msg)
+	return nil
+}
+
+func (*BlockFetcher) CrossChainAppRequest(context.Context, ids.ID, uint32, time.Time, []byte) error {
+	return nil
+}
+
+func (*BlockFetcher) CrossChainAppRequestFailed(context.Context, ids.ID, uint32) error {
+	return nil
+}
+
+func (*BlockFetcher) CrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
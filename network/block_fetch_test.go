@@ -0,0 +1,213 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/utils"
+)
+
+var errTestBlockMissing = errors.New("missing")
+
+func TestBlockFetcherServe(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	var (
+		peer     = ids.GenerateTestNodeID()
+		blk      = []byte{1, 2, 3}
+		blkID    = utils.ToID(blk)
+		response []byte
+	)
+	sender := &common.SenderTest{
+		T: t,
+		SendAppResponseF: func(_ context.Context, nodeID ids.NodeID, requestID uint32, msg []byte) error {
+			require.Equal(peer, nodeID)
+			require.Equal(uint32(1), requestID)
+			response = msg
+			return nil
+		},
+	}
+	getBlock := func(_ context.Context, id ids.ID) ([]byte, error) {
+		if id != blkID {
+			return nil, errTestBlockMissing
+		}
+		return blk, nil
+	}
+	f := NewBlockFetcher(logging.NoLog{}, sender, getBlock, nil, time.Second, 1)
+
+	require.NoError(f.AppRequest(ctx, peer, 1, time.Time{}, blkID[:]))
+	require.Equal(blk, response)
+
+	// Missing blocks are served as an empty response
+	missing := ids.GenerateTestID()
+	require.NoError(f.AppRequest(ctx, peer, 1, time.Time{}, missing[:]))
+	require.Empty(response)
+
+	// Invalid requests are dropped
+	response = nil
+	require.NoError(f.AppRequest(ctx, peer, 1, time.Time{}, []byte{1}))
+	require.Nil(response)
+}
+
+func TestBlockFetcherFetch(t *testing.T) {
+	blk := []byte{1, 2, 3}
+	tests := []struct {
+		name      string
+		peers     int
+		responses [][]byte // nil fails the request, missing responses time out
+		requests  int
+		err       error
+	}{
+		{
+			name:      "fetched",
+			peers:     1,
+			responses: [][]byte{blk},
+			requests:  1,
+		},
+		{
+			name:      "retry unavailable",
+			peers:     2,
+			responses: [][]byte{{}, blk},
+			requests:  2,
+		},
+		{
+			name:      "retry failed",
+			peers:     2,
+			responses: [][]byte{nil, blk},
+			requests:  2,
+		},
+		{
+			name:      "retry mismatch",
+			peers:     2,
+			responses: [][]byte{{4, 5, 6}, blk},
+			requests:  2,
+		},
+		{
+			name:      "retry timeout",
+			peers:     1,
+			responses: [][]byte{},
+			requests:  3,
+			err:       ErrBlockFetchFailed,
+		},
+		{
+			name:      "retries exhausted",
+			peers:     3,
+			responses: [][]byte{nil, {}, nil, blk},
+			requests:  3,
+			err:       ErrBlockFetchFailed,
+		},
+		{
+			name:  "no peers",
+			peers: 0,
+			err:   ErrNoFetchPeers,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+
+			peers := make([]ids.NodeID, tt.peers)
+			for i := range peers {
+				peers[i] = ids.GenerateTestNodeID()
+			}
+			var (
+				f        *BlockFetcher
+				requests int
+			)
+			sender := &common.SenderTest{
+				T: t,
+				SendAppRequestF: func(ctx context.Context, nodeIDs set.Set[ids.NodeID], requestID uint32, msg []byte) error {
+					require.Equal(utils.ToID(blk), ids.ID(msg))
+					require.Equal(1, nodeIDs.Len())
+					nodeID := nodeIDs.List()[0]
+					requests++
+					if requests > len(tt.responses) {
+						return nil
+					}
+					if response := tt.responses[requests-1]; response != nil {
+						return f.AppResponse(ctx, nodeID, requestID, response)
+					}
+					return f.AppRequestFailed(ctx, nodeID, requestID)
+				},
+			}
+			f = NewBlockFetcher(logging.NoLog{}, sender, nil, func() []ids.NodeID { return peers }, time.Millisecond, 3)
+
+			fetched, err := f.Fetch(ctx, utils.ToID(blk))
+			require.ErrorIs(err, tt.err)
+			require.Equal(tt.requests, requests)
+			if tt.err == nil {
+				require.Equal(blk, fetched)
+			}
+			require.Empty(f.requests)
+			require.Empty(f.fetches)
+		})
+	}
+}
+
+func TestBlockFetcherDeduplicate(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	var (
+		peer     = ids.GenerateTestNodeID()
+		blk      = []byte{1, 2, 3}
+		blkID    = utils.ToID(blk)
+		pending  = make(chan uint32, 1)
+		requests int
+	)
+	sender := &common.SenderTest{
+		T: t,
+		SendAppRequestF: func(_ context.Context, _ set.Set[ids.NodeID], requestID uint32, _ []byte) error {
+			requests++
+			pending <- requestID
+			return nil
+		},
+	}
+	f := NewBlockFetcher(logging.NoLog{}, sender, nil, func() []ids.NodeID { return []ids.NodeID{peer} }, time.Minute, 1)
+
+	type result struct {
+		blk []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		blk, err := f.Fetch(ctx, blkID)
+		done <- result{blk, err}
+	}()
+	requestID := <-pending
+
+	// A concurrent fetch of the same block waits for the pending fetch
+	// instead of sending another request
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := f.Fetch(cancelledCtx, blkID)
+	require.ErrorIs(err, context.Canceled)
+	require.Equal(1, requests)
+
+	// Responses from other peers are dropped
+	require.NoError(f.AppResponse(ctx, ids.GenerateTestNodeID(), requestID, blk))
+	require.NoError(f.AppResponse(ctx, peer, requestID, blk))
+	res := <-done
+	require.NoError(res.err)
+	require.Equal(blk, res.blk)
+
+	// Once the fetch completes, the block can be fetched again
+	go func() {
+		_, _ = f.Fetch(ctx, blkID)
+	}()
+	requestID = <-pending
+	require.NoError(f.AppRequestFailed(ctx, peer, requestID))
+}
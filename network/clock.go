@@ -0,0 +1,192 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+// MinClockSamples is the number of peers that must have sent their time
+// before the local clock is adjusted by a [PeerClock].
+const MinClockSamples = 3
+
+// marshalClockSample encodes the local time sent to peers after the
+// [Handshake].
+//
+// A sample is shorter than any valid [Handshake], so peers that predate
+// clock sampling drop it as an invalid handshake.
+func marshalClockSample(t time.Time) []byte {
+	p := codec.NewWriter(consts.Int64Len, consts.Int64Len)
+	p.PackInt64(t.UnixMilli())
+	return p.Bytes()
+}
+
+func unmarshalClockSample(b []byte) (time.Time, error) {
+	p := codec.NewReader(b, consts.Int64Len)
+	t := p.UnpackInt64(true)
+	if err := p.Err(); err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrInvalidClockSample, err)
+	}
+	return time.UnixMilli(t), nil
+}
+
+// PeerClock estimates the offset of the local clock from the network using
+// the time sent by each connected peer (see [HandshakeHandler]).
+//
+// The measured offset is the median of the offsets of all peers, so a
+// minority of skewed peers can't move it. To prevent a Sybil of skewed peers
+// from dragging the local clock far from real time, the correction applied by
+// [PeerClock.Now] is bounded by [maxCorrection].
+//
+// Samples are recorded against the monotonic clock, so they remain valid if
+// the local clock is adjusted (i.e. by NTP) after they are received.
+type PeerClock struct {
+	log           logging.Logger
+	maxCorrection time.Duration
+	warnThreshold time.Duration
+	measured      prometheus.Gauge
+
+	// start is the time the [PeerClock] was created (including a monotonic
+	// clock reading)
+	start time.Time
+
+	l       sync.RWMutex
+	samples map[ids.NodeID]time.Duration
+	median  time.Duration // relative to [monotonic]
+	ready   bool          // at least [MinClockSamples] samples
+}
+
+// NewPeerClock returns a [PeerClock] that corrects the local clock by at most
+// [maxCorrection] (0 disables correction) and warns if the measured offset
+// exceeds [warnThreshold]. The measured offset (in ms) is recorded in
+// [measured].
+func NewPeerClock(
+	log logging.Logger,
+	maxCorrection time.Duration,
+	warnThreshold time.Duration,
+	measured prometheus.Gauge,
+) *PeerClock {
+	return &PeerClock{
+		log:           log,
+		maxCorrection: maxCorrection,
+		warnThreshold: warnThreshold,
+		measured:      measured,
+		start:         time.Now(),
+		samples:       map[ids.NodeID]time.Duration{},
+	}
+}
+
+// monotonic returns the current time as measured by the monotonic clock since
+// [start] (stripped of its monotonic reading).
+func (c *PeerClock) monotonic() time.Time {
+	return c.start.Round(0).Add(time.Since(c.start))
+}
+
+// Record records the time sent by [nodeID] (received just now), replacing
+// any previous sample from the same peer.
+func (c *PeerClock) Record(nodeID ids.NodeID, t time.Time) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.samples[nodeID] = t.Sub(c.monotonic())
+	c.update()
+}
+
+// Remove forgets the sample of [nodeID] (when it disconnects).
+func (c *PeerClock) Remove(nodeID ids.NodeID) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if _, ok := c.samples[nodeID]; !ok {
+		return
+	}
+	delete(c.samples, nodeID)
+	c.update()
+}
+
+// update recomputes the median of [samples] and warns if the local clock is
+// skewed.
+//
+// Assumes [l] is held.
+func (c *PeerClock) update() {
+	if len(c.samples) < MinClockSamples {
+		c.median, c.ready = 0, false
+		c.measured.Set(0)
+		return
+	}
+	offsets := make([]time.Duration, 0, len(c.samples))
+	for _, offset := range c.samples {
+		offsets = append(offsets, offset)
+	}
+	slices.Sort(offsets)
+	mid := len(offsets) / 2
+	c.median = offsets[mid]
+	if len(offsets)%2 == 0 {
+		c.median = (offsets[mid-1] + offsets[mid]) / 2
+	}
+	c.ready = true
+
+	measured := c.measuredOffset()
+	c.measured.Set(float64(measured.Milliseconds()))
+	abs := measured.Abs()
+	switch {
+	case abs > c.maxCorrection && abs > c.warnThreshold:
+		c.log.Error(
+			"local clock is skewed from peers by more than the max correction",
+			zap.Duration("offset", measured),
+			zap.Duration("maxCorrection", c.maxCorrection),
+			zap.Int("peers", len(offsets)),
+		)
+	case abs > c.warnThreshold:
+		c.log.Warn(
+			"local clock is skewed from peers",
+			zap.Duration("offset", measured),
+			zap.Duration("maxCorrection", c.maxCorrection),
+			zap.Int("peers", len(offsets)),
+		)
+	}
+}
+
+// measuredOffset returns the median offset of peers' clocks from the local
+// clock (or 0 if there are fewer than [MinClockSamples] samples).
+//
+// Assumes [l] is held.
+func (c *PeerClock) measuredOffset() time.Duration {
+	if !c.ready {
+		return 0
+	}
+	// [monotonic] has no monotonic reading, so this compares wall clocks
+	return c.monotonic().Add(c.median).Sub(time.Now())
+}
+
+// MeasuredOffset returns the median offset of peers' clocks from the local
+// clock (or 0 if there are fewer than [MinClockSamples] samples).
+func (c *PeerClock) MeasuredOffset() time.Duration {
+	c.l.RLock()
+	defer c.l.RUnlock()
+
+	return c.measuredOffset()
+}
+
+// Offset returns the correction applied to the local clock (the measured
+// offset bounded by [maxCorrection]).
+func (c *PeerClock) Offset() time.Duration {
+	return min(max(c.MeasuredOffset(), -c.maxCorrection), c.maxCorrection)
+}
+
+// Now returns the local time corrected by [Offset].
+func (c *PeerClock) Now() time.Time {
+	return time.Now().Add(c.Offset())
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// clockTolerance accounts for the time elapsed between recording a sample and
+// reading the offset.
+const clockTolerance = float64(50 * time.Millisecond)
+
+func TestClockSampleMarshal(t *testing.T) {
+	require := require.New(t)
+
+	now := time.UnixMilli(time.Now().UnixMilli())
+	b := marshalClockSample(now)
+	require.Len(b, 8)
+	sample, err := unmarshalClockSample(b)
+	require.NoError(err)
+	require.True(now.Equal(sample))
+
+	_, err = unmarshalClockSample(make([]byte, 8))
+	require.ErrorIs(err, ErrInvalidClockSample)
+}
+
+func TestPeerClock(t *testing.T) {
+	require := require.New(t)
+	measured := prometheus.NewGauge(prometheus.GaugeOpts{Name: "offset"})
+	c := NewPeerClock(logging.NoLog{}, 2*time.Second, time.Second, measured)
+
+	peers := make([]ids.NodeID, 5)
+	for i := range peers {
+		peers[i] = ids.GenerateTestNodeID()
+	}
+
+	// The clock isn't corrected until there are enough samples
+	for _, peer := range peers[:MinClockSamples-1] {
+		c.Record(peer, time.Now().Add(time.Second))
+	}
+	require.Zero(c.MeasuredOffset())
+	require.Zero(c.Offset())
+
+	// The median offset is used...
+	c.Record(peers[MinClockSamples-1], time.Now().Add(-time.Hour))
+	require.InDelta(float64(time.Second), float64(c.MeasuredOffset()), clockTolerance)
+	require.InDelta(float64(time.Second), float64(c.Offset()), clockTolerance)
+	require.InDelta(float64(time.Second.Milliseconds()), testutil.ToFloat64(measured), 50)
+	require.InDelta(float64(time.Second), float64(time.Until(c.Now())), clockTolerance)
+
+	// ...(averaged if there are an even number of samples)...
+	c.Record(peers[3], time.Now().Add(-time.Hour))
+	require.InDelta(float64(-time.Hour/2+time.Second/2), float64(c.MeasuredOffset()), clockTolerance)
+
+	// ...but the correction is bounded, so a majority of skewed peers can't
+	// drag the clock further than the max correction
+	c.Record(peers[4], time.Now().Add(-time.Hour))
+	require.InDelta(float64(-time.Hour), float64(c.MeasuredOffset()), clockTolerance)
+	require.Equal(-2*time.Second, c.Offset())
+
+	// Samples from the same peer are replaced and removed on disconnect
+	c.Record(peers[4], time.Now().Add(time.Hour))
+	c.Remove(peers[3])
+	require.InDelta(float64(time.Second), float64(c.MeasuredOffset()), clockTolerance)
+	c.Remove(peers[4])
+	c.Remove(peers[4])
+	require.InDelta(float64(time.Second), float64(c.MeasuredOffset()), clockTolerance)
+	c.Remove(peers[0])
+	require.Zero(c.Offset())
+	require.Zero(testutil.ToFloat64(measured))
+}
+
+func TestPeerClockDisabled(t *testing.T) {
+	require := require.New(t)
+	c := NewPeerClock(logging.NoLog{}, 0, time.Second, prometheus.NewGauge(prometheus.GaugeOpts{Name: "offset"}))
+
+	// The offset is measured but never applied
+	for i := 0; i < MinClockSamples; i++ {
+		c.Record(ids.GenerateTestNodeID(), time.Now().Add(time.Minute))
+	}
+	require.InDelta(float64(time.Minute), float64(c.MeasuredOffset()), clockTolerance)
+	require.Zero(c.Offset())
+}
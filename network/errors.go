@@ -0,0 +1,18 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import "errors"
+
+var (
+	ErrInvalidFeature     = errors.New("invalid feature")
+	ErrDuplicateFeature   = errors.New("duplicate feature")
+	ErrInvalidHandshake   = errors.New("invalid handshake")
+	ErrInvalidClockSample = errors.New("invalid clock sample")
+	ErrNoFetchPeers       = errors.New("no peers to fetch from")
+	ErrBlockUnavailable   = errors.New("block unavailable")
+	ErrBlockFetchTimeout  = errors.New("block fetch timed out")
+	ErrBlockFetchMismatch = errors.New("fetched block does not match requested id")
+	ErrBlockFetchFailed   = errors.New("block fetch failed")
+)
@@ -0,0 +1,95 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// MaxFeatures is the number of features that can be advertised by a peer.
+const MaxFeatures = 64
+
+// Features is a set of protocol features (one per bit) supported by a peer.
+type Features uint64
+
+// LegacyFeatures is the feature set assumed for peers that have not sent a
+// [Handshake] (like peers running software that predates it).
+const LegacyFeatures Features = 0
+
+// Has returns true if [f] includes all of [o].
+func (f Features) Has(o Features) bool {
+	return f&o == o
+}
+
+// FeatureRegistry assigns each protocol feature a unique bit.
+//
+// Bits must never be reused once a feature has been released (even if it is
+// later removed), otherwise peers on different software will disagree about
+// what a bit means.
+type FeatureRegistry struct {
+	l        sync.RWMutex
+	names    map[uint8]string
+	features map[string]Features
+}
+
+func NewFeatureRegistry() *FeatureRegistry {
+	return &FeatureRegistry{
+		names:    map[uint8]string{},
+		features: map[string]Features{},
+	}
+}
+
+// Register assigns [bit] to the feature [name] and returns its [Features].
+func (r *FeatureRegistry) Register(bit uint8, name string) (Features, error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if bit >= MaxFeatures || len(name) == 0 {
+		return 0, fmt.Errorf("%w: bit=%d name=%q", ErrInvalidFeature, bit, name)
+	}
+	if prev, ok := r.names[bit]; ok {
+		return 0, fmt.Errorf("%w: bit %d already assigned to %q", ErrDuplicateFeature, bit, prev)
+	}
+	if _, ok := r.features[name]; ok {
+		return 0, fmt.Errorf("%w: %q already registered", ErrDuplicateFeature, name)
+	}
+	f := Features(1) << bit
+	r.names[bit] = name
+	r.features[name] = f
+	return f, nil
+}
+
+// Supported returns all registered features.
+func (r *FeatureRegistry) Supported() Features {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	var f Features
+	for _, feature := range r.features {
+		f |= feature
+	}
+	return f
+}
+
+// Names returns the names of the features in [f] (ordered by bit). Unknown
+// features are named by their bit.
+func (r *FeatureRegistry) Names(f Features) []string {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	names := make([]string, 0, bits.OnesCount64(uint64(f)))
+	for bit := uint8(0); bit < MaxFeatures; bit++ {
+		if f&(Features(1)<<bit) == 0 {
+			continue
+		}
+		name, ok := r.names[bit]
+		if !ok {
+			name = fmt.Sprintf("unknown(%d)", bit)
+		}
+		names = append(names, name)
+	}
+	return names
+}
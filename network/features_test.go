@@ -0,0 +1,41 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureRegistry(t *testing.T) {
+	require := require.New(t)
+	r := NewFeatureRegistry()
+	require.Equal(LegacyFeatures, r.Supported())
+
+	a, err := r.Register(0, "a")
+	require.NoError(err)
+	b, err := r.Register(63, "b")
+	require.NoError(err)
+	require.Equal(a|b, r.Supported())
+	require.True(r.Supported().Has(a))
+	require.True(r.Supported().Has(LegacyFeatures))
+	require.False(a.Has(a | b))
+
+	// Bits and names can't be reused
+	_, err = r.Register(0, "c")
+	require.ErrorIs(err, ErrDuplicateFeature)
+	_, err = r.Register(1, "a")
+	require.ErrorIs(err, ErrDuplicateFeature)
+
+	// Bits must fit in [Features]
+	_, err = r.Register(MaxFeatures, "c")
+	require.ErrorIs(err, ErrInvalidFeature)
+	_, err = r.Register(1, "")
+	require.ErrorIs(err, ErrInvalidFeature)
+
+	// Unknown bits advertised by peers are still named
+	require.Equal([]string{"a", "unknown(5)", "b"}, r.Names(a|b|Features(1)<<5))
+	require.Empty(r.Names(LegacyFeatures))
+}
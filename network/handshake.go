@@ -0,0 +1,197 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/version"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+// MaxHandshakeVersionLen is the maximum length of the software version
+// advertised in a [Handshake].
+const MaxHandshakeVersionLen = 256
+
+// Handshake is sent to each peer when it connects to advertise the software
+// version and protocol features supported by this node.
+type Handshake struct {
+	Version  string   `json:"version"`
+	Features Features `json:"features"`
+}
+
+func (h *Handshake) Marshal() ([]byte, error) {
+	p := codec.NewWriter(codec.StringLen(h.Version)+consts.Uint64Len, consts.NetworkSizeLimit)
+	p.PackLimitedString(h.Version, MaxHandshakeVersionLen)
+	p.PackUint64(uint64(h.Features))
+	return p.Bytes(), p.Err()
+}
+
+func UnmarshalHandshake(b []byte) (*Handshake, error) {
+	p := codec.NewReader(b, consts.NetworkSizeLimit)
+	h := &Handshake{
+		Version:  p.UnpackLimitedString(MaxHandshakeVersionLen, false),
+		Features: Features(p.UnpackUint64(false)),
+	}
+	if err := p.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHandshake, err)
+	}
+	if !p.Empty() {
+		return nil, fmt.Errorf("%w: leftover bytes", ErrInvalidHandshake)
+	}
+	return h, nil
+}
+
+var _ Handler = (*HandshakeHandler)(nil)
+
+// HandshakeHandler sends a [Handshake] to each peer when it connects and
+// tracks the [Handshake] received from each peer.
+//
+// Peers running software that predates the handshake can't route it to a
+// handler and drop it, so they are assumed to support [LegacyFeatures].
+//
+// The handshake is followed by a sample of the local time, which is recorded
+// in the [PeerClock] of the peer.
+type HandshakeHandler struct {
+	log    logging.Logger
+	nodeID ids.NodeID
+	sender common.AppSender
+	msg    []byte
+	clock  *PeerClock
+
+	l     sync.RWMutex
+	peers map[ids.NodeID]*Handshake
+}
+
+func NewHandshakeHandler(
+	log logging.Logger,
+	nodeID ids.NodeID,
+	sender common.AppSender,
+	handshake *Handshake,
+	clock *PeerClock,
+) (*HandshakeHandler, error) {
+	msg, err := handshake.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &HandshakeHandler{
+		log:    log,
+		nodeID: nodeID,
+		sender: sender,
+		msg:    msg,
+		clock:  clock,
+		peers:  map[ids.NodeID]*Handshake{},
+	}, nil
+}
+
+// Features returns the features advertised by [nodeID] (or [LegacyFeatures]
+// if it has not sent a [Handshake]).
+func (h *HandshakeHandler) Features(nodeID ids.NodeID) Features {
+	h.l.RLock()
+	defer h.l.RUnlock()
+
+	peer, ok := h.peers[nodeID]
+	if !ok {
+		return LegacyFeatures
+	}
+	return peer.Features
+}
+
+// Peers returns the [Handshake] received from each connected peer.
+func (h *HandshakeHandler) Peers() map[ids.NodeID]*Handshake {
+	h.l.RLock()
+	defer h.l.RUnlock()
+
+	return maps.Clone(h.peers)
+}
+
+func (h *HandshakeHandler) Connected(ctx context.Context, nodeID ids.NodeID, _ *version.Application) error {
+	if nodeID == h.nodeID {
+		return nil
+	}
+	config := common.SendConfig{NodeIDs: set.Of(nodeID)}
+	if err := h.sender.SendAppGossip(ctx, config, h.msg); err != nil {
+		return err
+	}
+	// We send the uncorrected local time so that corrections don't compound
+	// across peers.
+	return h.sender.SendAppGossip(ctx, config, marshalClockSample(time.Now()))
+}
+
+func (h *HandshakeHandler) Disconnected(_ context.Context, nodeID ids.NodeID) error {
+	h.clock.Remove(nodeID)
+
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	delete(h.peers, nodeID)
+	return nil
+}
+
+func (h *HandshakeHandler) AppGossip(_ context.Context, nodeID ids.NodeID, msg []byte) error {
+	if len(msg) == consts.Int64Len {
+		t, err := unmarshalClockSample(msg)
+		if err != nil {
+			h.log.Debug(
+				"dropping invalid clock sample",
+				zap.Stringer("peerID", nodeID),
+				zap.Error(err),
+			)
+			return nil
+		}
+		h.clock.Record(nodeID, t)
+		return nil
+	}
+
+	handshake, err := UnmarshalHandshake(msg)
+	if err != nil {
+		h.log.Debug(
+			"dropping invalid handshake",
+			zap.Stringer("peerID", nodeID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	h.peers[nodeID] = handshake
+	return nil
+}
+
+func (*HandshakeHandler) AppRequest(context.Context, ids.NodeID, uint32, time.Time, []byte) error {
+	return nil
+}
+
+func (*HandshakeHandler) AppRequestFailed(context.Context, ids.NodeID, uint32) error {
+	return nil
+}
+
+func (*HandshakeHandler) AppResponse(context.Context, ids.NodeID, uint32, []byte) error {
+	return nil
+}
+
+func (*HandshakeHandler) CrossChainAppRequest(context.Context, ids.ID, uint32, time.Time, []byte) error {
+	return nil
+}
+
+func (*HandshakeHandler) CrossChainAppRequestFailed(context.Context, ids.ID, uint32) error {
+	return nil
+}
+
+func (*HandshakeHandler) CrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+func TestHandshakeMarshal(t *testing.T) {
+	require := require.New(t)
+
+	h := &Handshake{Version: "v0.0.1", Features: 0b101}
+	b, err := h.Marshal()
+	require.NoError(err)
+	h2, err := UnmarshalHandshake(b)
+	require.NoError(err)
+	require.Equal(h, h2)
+
+	_, err = UnmarshalHandshake(append(b, 0))
+	require.ErrorIs(err, ErrInvalidHandshake)
+	_, err = UnmarshalHandshake(b[:len(b)-1])
+	require.ErrorIs(err, ErrInvalidHandshake)
+}
+
+func TestHandshakeHandler(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	var (
+		self    = ids.GenerateTestNodeID()
+		peer    = ids.GenerateTestNodeID()
+		sent    = set.Set[ids.NodeID]{}
+		samples int
+	)
+	handshake := &Handshake{Version: "v0.0.1", Features: 0b11}
+	expected, err := handshake.Marshal()
+	require.NoError(err)
+	sender := &common.SenderTest{
+		T: t,
+		SendAppGossipF: func(_ context.Context, cfg common.SendConfig, msg []byte) error {
+			if len(msg) == consts.Int64Len {
+				samples++
+			} else {
+				require.Equal(expected, msg)
+			}
+			sent.Union(cfg.NodeIDs)
+			return nil
+		},
+	}
+	clock := NewPeerClock(logging.NoLog{}, time.Second, time.Second, prometheus.NewGauge(prometheus.GaugeOpts{Name: "offset"}))
+	h, err := NewHandshakeHandler(logging.NoLog{}, self, sender, handshake, clock)
+	require.NoError(err)
+
+	// Handshake (and a clock sample) is sent to each connected peer (but not
+	// to self)
+	require.NoError(h.Connected(ctx, self, nil))
+	require.NoError(h.Connected(ctx, peer, nil))
+	require.Equal(set.Of(peer), sent)
+	require.Equal(1, samples)
+
+	// Peers that haven't sent a handshake (or sent an invalid one) support
+	// the legacy features
+	require.Equal(LegacyFeatures, h.Features(peer))
+	require.NoError(h.AppGossip(ctx, peer, []byte{1, 2, 3}))
+	require.Equal(LegacyFeatures, h.Features(peer))
+	require.Empty(h.Peers())
+
+	require.NoError(h.AppGossip(ctx, peer, expected))
+	require.Equal(handshake.Features, h.Features(peer))
+	require.Equal(map[ids.NodeID]*Handshake{peer: handshake}, h.Peers())
+
+	// Clock samples are recorded (but don't replace the handshake)
+	require.NoError(h.AppGossip(ctx, peer, marshalClockSample(time.Now())))
+	require.Equal(handshake.Features, h.Features(peer))
+	require.Contains(clock.samples, peer)
+
+	// Handshake (and clock sample) is forgotten on disconnect
+	require.NoError(h.Disconnected(ctx, peer))
+	require.Equal(LegacyFeatures, h.Features(peer))
+	require.Empty(h.Peers())
+	require.Empty(clock.samples)
+}
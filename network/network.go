@@ -171,7 +171,7 @@ func (n *Manager) AppGossip(ctx context.Context, nodeID ids.NodeID, msg []byte)
 	if !ok {
 		n.log.Debug(
 			"could not route incoming AppGossip",
-			zap.Stringer("nodeID", nodeID),
+			zap.Stringer("peerID", nodeID),
 		)
 		return nil
 	}
@@ -190,7 +190,7 @@ func (n *Manager) AppRequest(
 	if !ok {
 		n.log.Debug(
 			"could not route incoming AppRequest",
-			zap.Stringer("nodeID", nodeID),
+			zap.Stringer("peerID", nodeID),
 			zap.Uint32("requestID", requestID),
 		)
 		return nil
@@ -208,7 +208,7 @@ func (n *Manager) AppRequestFailed(
 	if !ok {
 		n.log.Debug(
 			"could not handle incoming AppRequestFailed",
-			zap.Stringer("nodeID", nodeID),
+			zap.Stringer("peerID", nodeID),
 			zap.Uint32("requestID", requestID),
 		)
 		return nil
@@ -227,7 +227,7 @@ func (n *Manager) AppResponse(
 	if !ok {
 		n.log.Debug(
 			"could not handle incoming AppResponse",
-			zap.Stringer("nodeID", nodeID),
+			zap.Stringer("peerID", nodeID),
 			zap.Uint32("requestID", requestID),
 		)
 		return nil
@@ -247,7 +247,7 @@ func (n *Manager) Connected(
 		if err := handler.Connected(ctx, nodeID, v); err != nil {
 			n.log.Debug(
 				"handler could not hanlde connected message",
-				zap.Stringer("nodeID", nodeID),
+				zap.Stringer("peerID", nodeID),
 				zap.Uint8("handler", k),
 				zap.Error(err),
 			)
@@ -264,7 +264,7 @@ func (n *Manager) Disconnected(ctx context.Context, nodeID ids.NodeID) error {
 		if err := handler.Disconnected(ctx, nodeID); err != nil {
 			n.log.Debug(
 				"handler could not hanlde disconnected message",
-				zap.Stringer("nodeID", nodeID),
+				zap.Stringer("peerID", nodeID),
 				zap.Uint8("handler", k),
 				zap.Error(err),
 			)
@@ -0,0 +1,303 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package offload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+var _ chain.AuthOffloader = (*Client)(nil)
+
+type Config struct {
+	Endpoints      []string
+	Timeout        time.Duration // how long to wait for a batch to be verified before falling back
+	HealthInterval time.Duration // how often to check which auth types each endpoint supports
+	BatchSize      int           // signatures sent in each call (capped by the size advertised by each endpoint)
+}
+
+// endpoint is a sidecar serving offload.proto.
+type endpoint struct {
+	addr string
+	conn *grpc.ClientConn
+
+	lock         sync.RWMutex
+	healthy      bool
+	authTypes    set.Set[uint8]
+	maxBatchSize int
+}
+
+func (e *endpoint) supports(authTypeID uint8, size int) bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	return e.healthy && e.authTypes.Contains(authTypeID) && (e.maxBatchSize == 0 || size <= e.maxBatchSize)
+}
+
+// setHealth records the result of a health check ([resp] is nil if it
+// failed). Returns true if the health of [e] changed.
+func (e *endpoint) setHealth(resp *HealthResponse) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	wasHealthy := e.healthy
+	e.healthy = resp != nil
+	e.authTypes = set.Set[uint8]{}
+	e.maxBatchSize = 0
+	if resp != nil {
+		for _, t := range resp.AuthTypes {
+			if t <= math.MaxUint8 {
+				e.authTypes.Add(uint8(t))
+			}
+		}
+		e.maxBatchSize = int(resp.MaxBatchSize)
+	}
+	return wasHealthy != e.healthy
+}
+
+// Client offloads signature verification to a set of sidecars, sending each
+// batch to the next healthy endpoint that advertised support for its auth
+// type (and size) at its last health check.
+//
+// An endpoint that fails a call is not used again until it passes a health
+// check.
+type Client struct {
+	log       logging.Logger
+	config    Config
+	endpoints []*endpoint
+	next      atomic.Uint64
+	metrics   *metrics
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewClient connects to [config.Endpoints] (without TLS unless overridden by
+// [opts]) and checks their health before returning. Endpoints that are not
+// reachable are checked again every [config.HealthInterval].
+func NewClient(
+	ctx context.Context,
+	log logging.Logger,
+	registerer prometheus.Registerer,
+	config Config,
+	opts ...grpc.DialOption,
+) (*Client, error) {
+	if config.Timeout <= 0 || config.HealthInterval <= 0 {
+		return nil, ErrInvalidConfig
+	}
+	m, err := newMetrics(registerer)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		log:     log,
+		config:  config,
+		metrics: m,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	c.config.BatchSize = max(c.config.BatchSize, 1)
+
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)
+	for _, addr := range config.Endpoints {
+		conn, err := grpc.DialContext(ctx, addr, opts...)
+		if err != nil {
+			_ = c.closeConns()
+			return nil, fmt.Errorf("%w: unable to dial %s", err, addr)
+		}
+		c.endpoints = append(c.endpoints, &endpoint{addr: addr, conn: conn})
+	}
+	c.checkHealth(ctx)
+	go c.run()
+	return c, nil
+}
+
+func (c *Client) run() {
+	defer close(c.done)
+
+	t := time.NewTicker(c.config.HealthInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			c.checkHealth(context.Background())
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// checkHealth updates the auth types supported by each endpoint.
+func (c *Client) checkHealth(ctx context.Context) {
+	healthy := 0
+	for _, e := range c.endpoints {
+		ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+		resp := &HealthResponse{}
+		err := e.conn.Invoke(ctx, healthMethod, &HealthRequest{}, resp)
+		cancel()
+		if err != nil {
+			resp = nil
+		} else {
+			healthy++
+		}
+		if e.setHealth(resp) {
+			c.log.Info("offload endpoint health changed",
+				zap.String("endpoint", e.addr),
+				zap.Bool("healthy", err == nil),
+				zap.Error(err),
+			)
+		}
+	}
+	c.metrics.healthyEndpoints.Set(float64(healthy))
+}
+
+// Supports returns true if any healthy endpoint advertised support for
+// [authTypeID].
+func (c *Client) Supports(authTypeID uint8) bool {
+	for _, e := range c.endpoints {
+		if e.supports(authTypeID, 1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) BatchSize() int {
+	return c.config.BatchSize
+}
+
+// pick returns the next endpoint that supports a batch of [size] signatures
+// of [authTypeID] (or nil if there are none).
+func (c *Client) pick(authTypeID uint8, size int) *endpoint {
+	start := c.next.Add(1)
+	for i := range c.endpoints {
+		e := c.endpoints[(start+uint64(i))%uint64(len(c.endpoints))]
+		if e.supports(authTypeID, size) {
+			return e
+		}
+	}
+	return nil
+}
+
+// Verify returns nil only if an endpoint that advertised support for
+// [authTypeID] answered that all [sigs] are valid.
+func (c *Client) Verify(ctx context.Context, authTypeID uint8, sigs []*chain.OffloadedSignature) error {
+	start := time.Now()
+	e := c.pick(authTypeID, len(sigs))
+	if e == nil {
+		c.metrics.fallbacks.Inc()
+		return fmt.Errorf("%w: %d", ErrNoEndpoint, authTypeID)
+	}
+
+	req := &VerifyRequest{
+		AuthType:   uint32(authTypeID),
+		Signatures: make([]*Signature, len(sigs)),
+	}
+	for i, sig := range sigs {
+		req.Signatures[i] = &Signature{Digest: sig.Digest, PublicKey: sig.PublicKey, Signature: sig.Signature}
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	resp := &VerifyResponse{}
+	err := e.conn.Invoke(ctx, verifyMethod, req, resp)
+	c.metrics.verifyLatency.Observe(time.Since(start).Seconds())
+	if err != nil {
+		c.metrics.fallbacks.Inc()
+		if e.setHealth(nil) {
+			c.log.Info("offload endpoint failed",
+				zap.String("endpoint", e.addr),
+				zap.Error(err),
+			)
+		}
+		return fmt.Errorf("%w: %s", err, e.addr)
+	}
+
+	// [e] may have stopped advertising [authTypeID] while verifying (like if
+	// it restarted with a different configuration)
+	if !e.supports(authTypeID, len(sigs)) {
+		c.metrics.fallbacks.Inc()
+		return fmt.Errorf("%w: %s %d", ErrNotAdvertised, e.addr, authTypeID)
+	}
+	if !resp.Valid {
+		c.metrics.invalid.Inc()
+		return ErrInvalidBatch
+	}
+	c.metrics.verified.Add(float64(len(sigs)))
+	return nil
+}
+
+// Close stops health checks and closes all connections.
+func (c *Client) Close() error {
+	close(c.stop)
+	<-c.done
+	return c.closeConns()
+}
+
+func (c *Client) closeConns() error {
+	var errs []error
+	for _, e := range c.endpoints {
+		errs = append(errs, e.conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+type metrics struct {
+	verifyLatency    prometheus.Histogram
+	verified         prometheus.Counter
+	invalid          prometheus.Counter
+	fallbacks        prometheus.Counter
+	healthyEndpoints prometheus.Gauge
+}
+
+func newMetrics(r prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		verifyLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "verify_latency",
+			Help:    "seconds between sending a batch to an endpoint and receiving its result (including serialization)",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 12), // 500us to ~1s
+		}),
+		verified: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "signatures_verified",
+			Help: "number of signatures an endpoint answered were valid",
+		}),
+		invalid: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "invalid_batches",
+			Help: "number of batches an endpoint answered were invalid (and are verified locally)",
+		}),
+		fallbacks: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "fallbacks",
+			Help: "number of batches not verified by an endpoint (because none was available, or it failed or timed out)",
+		}),
+		healthyEndpoints: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "healthy_endpoints",
+			Help: "number of endpoints that passed the last health check",
+		}),
+	}
+	errs := wrappers.Errs{}
+	errs.Add(
+		r.Register(m.verifyLatency),
+		r.Register(m.verified),
+		r.Register(m.invalid),
+		r.Register(m.fallbacks),
+		r.Register(m.healthyEndpoints),
+	)
+	return m, errs.Err
+}
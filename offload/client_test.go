@@ -0,0 +1,257 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package offload
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// equalVerifier accepts signatures that are equal to their digest.
+func equalVerifier(digest []byte, _ []byte, signature []byte) bool {
+	return bytes.Equal(digest, signature)
+}
+
+// lyingServer advertises [authTypes] but answers that every batch is valid
+// (and counts the batches of each auth type it is sent).
+type lyingServer struct {
+	authTypes []uint32
+	calls     [2]atomic.Int64
+}
+
+func (s *lyingServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return &HealthResponse{AuthTypes: s.authTypes}, nil
+}
+
+func (s *lyingServer) Verify(_ context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+	s.calls[req.AuthType].Add(1)
+	return &VerifyResponse{Valid: true}, nil
+}
+
+// blockingServer supports auth type 0 but never answers a batch before the
+// caller gives up.
+type blockingServer struct{}
+
+func (blockingServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return &HealthResponse{AuthTypes: []uint32{0}}, nil
+}
+
+func (blockingServer) Verify(ctx context.Context, _ *VerifyRequest) (*VerifyResponse, error) {
+	<-ctx.Done()
+	return nil, status.FromContextError(ctx.Err()).Err()
+}
+
+// newTestClient serves each of [servers] over an in-memory connection and
+// returns a client connected to all of them (in order).
+func newTestClient(t *testing.T, config Config, servers ...SignatureVerifierServer) *Client {
+	require := require.New(t)
+
+	listeners := map[string]*bufconn.Listener{}
+	for i, srv := range servers {
+		addr := string(rune('a' + i))
+		listener := bufconn.Listen(1024 * 1024)
+		listeners[addr] = listener
+		config.Endpoints = append(config.Endpoints, addr)
+
+		s := NewGRPCServer(srv)
+		go func() { _ = s.Serve(listener) }()
+		t.Cleanup(s.Stop)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = time.Second
+	}
+	if config.HealthInterval == 0 {
+		config.HealthInterval = time.Hour
+	}
+	c, err := NewClient(
+		context.Background(),
+		logging.NoLog{},
+		prometheus.NewRegistry(),
+		config,
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return listeners[addr].DialContext(ctx)
+		}),
+	)
+	require.NoError(err)
+	t.Cleanup(func() { require.NoError(c.Close()) })
+	return c
+}
+
+func newTestSignatures(count int, invalid int) []*chain.OffloadedSignature {
+	sigs := make([]*chain.OffloadedSignature, count)
+	for i := range sigs {
+		digest := []byte{byte(i)}
+		signature := digest
+		if i == invalid {
+			signature = []byte{byte(i) + 1}
+		}
+		sigs[i] = &chain.OffloadedSignature{Digest: digest, Signature: signature}
+	}
+	return sigs
+}
+
+func TestClientVerify(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	c := newTestClient(t, Config{BatchSize: 4}, NewServer(map[uint8]Verifier{0: equalVerifier}, 4, 2))
+	require.Equal(4, c.BatchSize())
+	require.True(c.Supports(0))
+	require.False(c.Supports(1))
+	require.Equal(1.0, testutil.ToFloat64(c.metrics.healthyEndpoints))
+
+	require.NoError(c.Verify(ctx, 0, newTestSignatures(4, -1)))
+	require.Equal(4.0, testutil.ToFloat64(c.metrics.verified))
+	require.ErrorIs(c.Verify(ctx, 0, newTestSignatures(4, 3)), ErrInvalidBatch)
+	require.Equal(1.0, testutil.ToFloat64(c.metrics.invalid))
+
+	// Batches larger than the endpoint accepts are not sent
+	require.ErrorIs(c.Verify(ctx, 0, newTestSignatures(5, -1)), ErrNoEndpoint)
+	require.ErrorIs(c.Verify(ctx, 1, newTestSignatures(1, -1)), ErrNoEndpoint)
+	require.Equal(2.0, testutil.ToFloat64(c.metrics.fallbacks))
+}
+
+func TestClientUnadvertisedAuthType(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	// Only the second endpoint advertises auth type 1, so the first is never
+	// sent (or trusted with) its batches
+	first := &lyingServer{authTypes: []uint32{0}}
+	second := &lyingServer{authTypes: []uint32{0, 1}}
+	c := newTestClient(t, Config{BatchSize: 1}, first, second)
+	for i := 0; i < 4; i++ {
+		require.NoError(c.Verify(ctx, 0, newTestSignatures(1, -1)))
+	}
+	for i := 0; i < 4; i++ {
+		require.NoError(c.Verify(ctx, 1, newTestSignatures(1, -1)))
+	}
+	require.Equal(int64(2), first.calls[0].Load())
+	require.Equal(int64(2), second.calls[0].Load())
+	require.Zero(first.calls[1].Load())
+	require.Equal(int64(4), second.calls[1].Load())
+
+	// No endpoint is trusted once none advertises auth type 1
+	second.authTypes = []uint32{0}
+	c.checkHealth(ctx)
+	require.False(c.Supports(1))
+	require.ErrorIs(c.Verify(ctx, 1, newTestSignatures(1, -1)), ErrNoEndpoint)
+	require.Equal(int64(4), second.calls[1].Load())
+}
+
+func TestClientTimeout(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	c := newTestClient(t, Config{BatchSize: 1, Timeout: 50 * time.Millisecond}, blockingServer{})
+	require.True(c.Supports(0))
+
+	// The endpoint is not used once it fails...
+	err := c.Verify(ctx, 0, newTestSignatures(1, -1))
+	require.Equal(codes.DeadlineExceeded, status.Code(err))
+	require.False(c.Supports(0))
+	require.ErrorIs(c.Verify(ctx, 0, newTestSignatures(1, -1)), ErrNoEndpoint)
+	require.Equal(2.0, testutil.ToFloat64(c.metrics.fallbacks))
+	latency := &dto.Metric{}
+	require.NoError(c.metrics.verifyLatency.Write(latency))
+	require.Equal(uint64(1), latency.GetHistogram().GetSampleCount()) // only sent batches are observed
+
+	// ...until it passes a health check
+	c.checkHealth(ctx)
+	require.True(c.Supports(0))
+}
+
+func TestServerVerify(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewServer(map[uint8]Verifier{1: equalVerifier, 0: equalVerifier}, 8, 3)
+	health, err := s.Health(ctx, &HealthRequest{})
+	require.NoError(t, err)
+	require.Equal(t, &HealthResponse{AuthTypes: []uint32{0, 1}, MaxBatchSize: 8}, health)
+
+	tests := []struct {
+		name          string
+		req           *VerifyRequest
+		expectedValid bool
+		expectedCode  codes.Code
+	}{
+		{
+			name:          "valid",
+			req:           &VerifyRequest{Signatures: []*Signature{{Digest: []byte{1}, Signature: []byte{1}}, {}, {}, {}, {}}},
+			expectedValid: true,
+		},
+		{
+			name: "invalid",
+			req:  &VerifyRequest{AuthType: 1, Signatures: []*Signature{{}, {}, {}, {}, {Digest: []byte{1}}}},
+		},
+		{
+			name:         "unsupported",
+			req:          &VerifyRequest{AuthType: 2},
+			expectedCode: codes.Unimplemented,
+		},
+		{
+			name:         "too large",
+			req:          &VerifyRequest{Signatures: make([]*Signature, 9)},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			resp, err := s.Verify(ctx, tt.req)
+			require.Equal(tt.expectedCode, status.Code(err))
+			if err == nil {
+				require.Equal(tt.expectedValid, resp.Valid)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	require := require.New(t)
+
+	req := &VerifyRequest{
+		AuthType: 3,
+		Signatures: []*Signature{
+			{Digest: []byte{1}, PublicKey: []byte{2, 3}, Signature: []byte{4}},
+			{Digest: []byte{5}},
+		},
+	}
+	raw, err := proto.Marshal(req)
+	require.NoError(err)
+	parsed := &VerifyRequest{}
+	require.NoError(proto.Unmarshal(raw, parsed))
+	require.True(proto.Equal(req, parsed))
+
+	// Packed and unpacked repeated fields are parsed and unknown fields are
+	// skipped
+	raw, err = proto.Marshal(&HealthResponse{AuthTypes: []uint32{0, 2}, MaxBatchSize: 7})
+	require.NoError(err)
+	raw = append(raw, 0x08, 0x05)       // auth_types = 5 (unpacked)
+	raw = append(raw, 0x1a, 0x01, 0xff) // field 3 (bytes)
+	health := &HealthResponse{}
+	require.NoError(proto.Unmarshal(raw, health))
+	require.Equal([]uint32{0, 2, 5}, health.GetAuthTypes())
+	require.Equal(uint32(7), health.GetMaxBatchSize())
+
+	require.Error(proto.Unmarshal([]byte{0x0a, 0x05}, health))
+}
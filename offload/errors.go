@@ -0,0 +1,15 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package offload
+
+import "errors"
+
+var (
+	ErrInvalidConfig   = errors.New("timeout and health interval must be positive")
+	ErrNoEndpoint      = errors.New("no healthy endpoint supports auth type")
+	ErrNotAdvertised   = errors.New("endpoint stopped advertising auth type")
+	ErrInvalidBatch    = errors.New("batch contains invalid signature")
+	ErrBatchTooLarge   = errors.New("batch too large")
+	ErrUnsupportedType = errors.New("unsupported auth type")
+)
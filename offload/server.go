@@ -0,0 +1,91 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package offload
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ SignatureVerifierServer = (*Server)(nil)
+
+// Verifier returns true if [signature] is a valid signature of [digest] by
+// [publicKey].
+type Verifier func(digest []byte, publicKey []byte, signature []byte) bool
+
+// Server verifies batches of signatures with a [Verifier] for each supported
+// auth type, splitting each batch across [cores] goroutines.
+type Server struct {
+	verifiers    map[uint8]Verifier
+	maxBatchSize int
+	cores        int
+}
+
+func NewServer(verifiers map[uint8]Verifier, maxBatchSize int, cores int) *Server {
+	return &Server{
+		verifiers:    verifiers,
+		maxBatchSize: maxBatchSize,
+		cores:        max(cores, 1),
+	}
+}
+
+// Health advertises the auth types with a [Verifier].
+func (s *Server) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	authTypes := make([]uint32, 0, len(s.verifiers))
+	for t := range s.verifiers {
+		authTypes = append(authTypes, uint32(t))
+	}
+	slices.Sort(authTypes)
+	return &HealthResponse{
+		AuthTypes:    authTypes,
+		MaxBatchSize: uint32(min(s.maxBatchSize, math.MaxUint32)),
+	}, nil
+}
+
+func (s *Server) Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+	if req.AuthType > math.MaxUint8 {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("%s: %d", ErrUnsupportedType, req.AuthType))
+	}
+	verify, ok := s.verifiers[uint8(req.AuthType)]
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, fmt.Sprintf("%s: %d", ErrUnsupportedType, req.AuthType))
+	}
+	if s.maxBatchSize > 0 && len(req.Signatures) > s.maxBatchSize {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("%s: %d > %d", ErrBatchTooLarge, len(req.Signatures), s.maxBatchSize))
+	}
+
+	var (
+		wg      sync.WaitGroup
+		invalid atomic.Bool
+		chunk   = (len(req.Signatures) + s.cores - 1) / s.cores
+	)
+	for start := 0; start < len(req.Signatures); start += chunk {
+		sigs := req.Signatures[start:min(start+chunk, len(req.Signatures))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, sig := range sigs {
+				if invalid.Load() || ctx.Err() != nil {
+					return
+				}
+				if !verify(sig.Digest, sig.PublicKey, sig.Signature) {
+					invalid.Store(true)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return &VerifyResponse{Valid: !invalid.Load()}, nil
+}
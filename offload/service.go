@@ -0,0 +1,78 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package offload
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// The messages of offload.proto are generated with scripts/protobuf.gen.sh.
+// protoc-gen-go-grpc is not used, so the service is registered by hand with
+// the [grpc.ServiceDesc] below.
+
+const (
+	serviceName  = "offload.SignatureVerifier"
+	healthMethod = "/" + serviceName + "/Health"
+	verifyMethod = "/" + serviceName + "/Verify"
+)
+
+// SignatureVerifierServer is the server API of offload.proto.
+type SignatureVerifierServer interface {
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+}
+
+// NewGRPCServer returns a [grpc.Server] serving [srv].
+func NewGRPCServer(srv SignatureVerifierServer, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	s.RegisterService(&serviceDesc, srv)
+	return s
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*SignatureVerifierServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Health",
+			Handler: unaryHandler(healthMethod, func(srv SignatureVerifierServer, ctx context.Context, req *HealthRequest) (any, error) {
+				return srv.Health(ctx, req)
+			}),
+		},
+		{
+			MethodName: "Verify",
+			Handler: unaryHandler(verifyMethod, func(srv SignatureVerifierServer, ctx context.Context, req *VerifyRequest) (any, error) {
+				return srv.Verify(ctx, req)
+			}),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "offload/offload.proto",
+}
+
+func unaryHandler[T any, M interface {
+	*T
+	proto.Message
+}](
+	method string,
+	call func(SignatureVerifierServer, context.Context, M) (any, error),
+) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := M(new(T))
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		s := srv.(SignatureVerifierServer)
+		if interceptor == nil {
+			return call(s, ctx, req)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: method}
+		return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return call(s, ctx, req.(M))
+		})
+	}
+}
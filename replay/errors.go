@@ -0,0 +1,13 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package replay
+
+import "errors"
+
+var (
+	ErrUnknownKind = errors.New("unknown event kind")
+	ErrDiverged    = errors.New("schedule diverged from log")
+	ErrEndOfLog    = errors.New("end of log")
+	ErrNotFailing  = errors.New("log does not fail")
+)
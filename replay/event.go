@@ -0,0 +1,121 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package replay records the order in which a test harness delivers events
+// to the components under test (blocks, accept and reject decisions, gossip,
+// and timers) so that a schedule that exposed a failure can be re-delivered
+// in the identical order (and shrunk to the shortest prefix that still
+// fails).
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Kind is the type of an [Event].
+type Kind uint8
+
+const (
+	Block  Kind = iota // a block is delivered (parsed and verified)
+	Accept             // consensus accepts a block
+	Reject             // consensus rejects a block
+	Gossip             // a gossip message is received
+	Timer              // a timer fires
+)
+
+var kindNames = []string{"block", "accept", "reject", "gossip", "timer"}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("kind(%d)", k)
+}
+
+func (k Kind) MarshalText() ([]byte, error) {
+	if int(k) >= len(kindNames) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKind, k)
+	}
+	return []byte(kindNames[k]), nil
+}
+
+func (k *Kind) UnmarshalText(b []byte) error {
+	i := slices.Index(kindNames, string(b))
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, b)
+	}
+	*k = Kind(i)
+	return nil
+}
+
+// Event is delivered to [Node] by a [Scheduler].
+//
+// [ID] distinguishes events of the same [Kind] pending for the same [Node]
+// and must be stable across runs of the harness (i.e. a block height instead
+// of a block ID that depends on generated keys).
+type Event struct {
+	// Time is the logical timestamp of the event (the number of events
+	// delivered before it, plus 1).
+	Time uint64 `json:"time"`
+	Kind Kind   `json:"kind"`
+	Node string `json:"node"`
+	ID   string `json:"id"`
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%d:%s@%s/%s", e.Time, e.Kind, e.Node, e.ID)
+}
+
+// matches returns true if [e] and [o] are the same event (ignoring when they
+// were delivered).
+func (e Event) matches(o Event) bool {
+	return e.Kind == o.Kind && e.Node == o.Node && e.ID == o.ID
+}
+
+// Log is the sequence of events delivered by a [Scheduler] (in order).
+type Log []Event
+
+// Save writes [l] to [path] (as indented JSON, so logs can be checked in as
+// a seed corpus and reviewed).
+func (l Log) Save(path string) error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+// Load reads a [Log] written by [Log.Save].
+func Load(path string) (Log, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l Log
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse %s", err, path)
+	}
+	return l, nil
+}
+
+// LoadCorpus reads every log in [dir] (files ending in .json), keyed by file
+// name.
+func LoadCorpus(dir string) (map[string]Log, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	corpus := make(map[string]Log, len(paths))
+	for _, path := range paths {
+		l, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		corpus[filepath.Base(path)] = l
+	}
+	return corpus, nil
+}
@@ -0,0 +1,255 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package replay
+
+import (
+	"errors"
+	"flag"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/stretchr/testify/require"
+)
+
+const raceTestTx = "tx"
+
+var (
+	updateCorpus = flag.Bool("update", false, "update the seed corpus in testdata")
+
+	errAcceptedTxInMempool = errors.New("accepted tx in mempool")
+)
+
+// raceTestNode is a toy VM that verifies and accepts a single block
+// (including [raceTestTx]).
+//
+// If [injectRace] is set, gossip of a tx is only ignored while the block
+// including it is processing (and not once it is accepted), so gossip
+// received after the block is accepted re-adds the tx to the mempool (the VM
+// ignores it using replay protection).
+type raceTestNode struct {
+	name       string
+	injectRace bool
+
+	mempool    set.Set[string]
+	processing set.Set[string]
+	accepted   set.Set[string]
+}
+
+func newRaceTestNode(name string, injectRace bool) *raceTestNode {
+	return &raceTestNode{
+		name:       name,
+		injectRace: injectRace,
+		mempool:    set.Set[string]{},
+		processing: set.Set[string]{},
+		accepted:   set.Set[string]{},
+	}
+}
+
+func (n *raceTestNode) verify() {
+	n.processing.Add(raceTestTx)
+	n.mempool.Remove(raceTestTx)
+}
+
+func (n *raceTestNode) accept() {
+	n.processing.Remove(raceTestTx)
+	n.accepted.Add(raceTestTx)
+}
+
+func (n *raceTestNode) gossip() {
+	if n.processing.Contains(raceTestTx) {
+		return
+	}
+	if !n.injectRace && n.accepted.Contains(raceTestTx) {
+		return
+	}
+	n.mempool.Add(raceTestTx)
+}
+
+func (n *raceTestNode) check() error {
+	for tx := range n.mempool {
+		if n.accepted.Contains(tx) {
+			return errAcceptedTxInMempool
+		}
+	}
+	return nil
+}
+
+// runRaceTest delivers the events of 2 nodes that each receive a block
+// (which is accepted once verified), receive gossip of the tx included in the
+// block, and fire a timer that re-gossips their mempool to the other node.
+//
+// Once all events are delivered (or the log being replayed ends), the
+// invariants of each node are checked.
+func runRaceTest(s *Scheduler, injectRace bool) error {
+	nodes := []*raceTestNode{newRaceTestNode("n0", injectRace), newRaceTestNode("n1", injectRace)}
+	for i, n := range nodes {
+		n, peer := n, nodes[1-i]
+		if err := s.Submit(Block, n.name, "1", func() error {
+			n.verify()
+			return s.Submit(Accept, n.name, "1", func() error {
+				n.accept()
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+		if err := s.Submit(Gossip, n.name, raceTestTx, func() error {
+			n.gossip()
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := s.Submit(Timer, n.name, "regossip", func() error {
+			if !n.mempool.Contains(raceTestTx) {
+				return nil
+			}
+			return s.Submit(Gossip, peer.name, "regossip", func() error {
+				peer.gossip()
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+	}
+	if err := s.Run(); err != nil && !errors.Is(err, ErrEndOfLog) {
+		return err
+	}
+	for _, n := range nodes {
+		if err := n.check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func raceTestFails(l Log) bool {
+	return errors.Is(runRaceTest(NewReplayer(l), true), errAcceptedTxInMempool)
+}
+
+func TestReplayInjectedRace(t *testing.T) {
+	require := require.New(t)
+
+	// Record schedules until one exposes the race (and one doesn't)
+	var (
+		failing Log
+		passed  bool
+	)
+	for seed := int64(0); seed < 100 && (failing == nil || !passed); seed++ {
+		s := NewRecorder(seed)
+		err := runRaceTest(s, true)
+		if err == nil {
+			passed = true
+			continue
+		}
+		require.ErrorIs(err, errAcceptedTxInMempool)
+		if failing == nil {
+			failing = s.Log()
+		}
+	}
+	require.NotNil(failing)
+	require.True(passed)
+
+	// The same seed always records the same schedule
+	for seed := int64(0); seed < 10; seed++ {
+		a, b := NewRecorder(seed), NewRecorder(seed)
+		_ = runRaceTest(a, true)
+		_ = runRaceTest(b, true)
+		require.Equal(a.Log(), b.Log())
+	}
+
+	// Replaying the failing schedule reproduces the failure (in the identical
+	// order)...
+	for i := 0; i < 3; i++ {
+		s := NewReplayer(failing)
+		require.ErrorIs(runRaceTest(s, true), errAcceptedTxInMempool)
+		require.Equal(failing, s.Log())
+		require.True(s.Done())
+	}
+
+	// ...which doesn't occur without the race
+	require.NoError(runRaceTest(NewReplayer(failing), false))
+
+	// The minimal failing prefix ends with gossip delivered to a node after
+	// it accepted the block
+	minimal, err := Shrink(failing, raceTestFails)
+	require.NoError(err)
+	require.True(raceTestFails(minimal))
+	require.False(raceTestFails(minimal[:len(minimal)-1]))
+	last := minimal[len(minimal)-1]
+	require.Equal(Gossip, last.Kind)
+	require.True(slices.ContainsFunc(minimal, func(e Event) bool {
+		return e.Kind == Accept && e.Node == last.Node
+	}))
+
+	if *updateCorpus {
+		require.NoError(minimal.Save(filepath.Join("testdata", "gossip_after_accept.json")))
+	}
+}
+
+func TestReplayCorpus(t *testing.T) {
+	corpus, err := LoadCorpus("testdata")
+	require.NoError(t, err)
+	require.NotEmpty(t, corpus)
+	for name, l := range corpus {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.ErrorIs(runRaceTest(NewReplayer(l), true), errAcceptedTxInMempool)
+			require.NoError(runRaceTest(NewReplayer(l), false))
+		})
+	}
+}
+
+func TestReplayDiverged(t *testing.T) {
+	require := require.New(t)
+
+	s := NewRecorder(0)
+	require.NoError(runRaceTest(s, false))
+	l := s.Log()
+	l[1].ID = "unknown"
+	require.ErrorIs(runRaceTest(NewReplayer(l), false), ErrDiverged)
+}
+
+func TestPassthrough(t *testing.T) {
+	require := require.New(t)
+
+	// Events are delivered in the order they are submitted (including
+	// events submitted while delivering another)
+	s := NewScheduler()
+	require.NoError(runRaceTest(s, false))
+	kinds := []Kind{}
+	for i, e := range s.Log() {
+		require.Equal(uint64(i+1), e.Time)
+		kinds = append(kinds, e.Kind)
+	}
+	require.Equal([]Kind{Block, Accept, Gossip, Timer, Block, Accept, Gossip, Timer}, kinds)
+	require.True(s.Done())
+}
+
+func TestShrinkNotFailing(t *testing.T) {
+	s := NewRecorder(0)
+	require.NoError(t, runRaceTest(s, false))
+	_, err := Shrink(s.Log(), func(l Log) bool {
+		return runRaceTest(NewReplayer(l), false) != nil
+	})
+	require.ErrorIs(t, err, ErrNotFailing)
+}
+
+func TestKindText(t *testing.T) {
+	require := require.New(t)
+
+	for k := Block; k <= Timer; k++ {
+		b, err := k.MarshalText()
+		require.NoError(err)
+		var parsed Kind
+		require.NoError(parsed.UnmarshalText(b))
+		require.Equal(k, parsed)
+	}
+	_, err := Kind(Timer + 1).MarshalText()
+	require.ErrorIs(err, ErrUnknownKind)
+	var k Kind
+	require.ErrorIs(k.UnmarshalText([]byte("unknown")), ErrUnknownKind)
+}
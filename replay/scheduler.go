@@ -0,0 +1,165 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package replay
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+)
+
+// Mode determines how a [Scheduler] orders pending events.
+type Mode uint8
+
+const (
+	// Passthrough delivers each event as soon as it is submitted (like a
+	// harness without a scheduler) and records the order.
+	Passthrough Mode = iota
+	// Record delivers pending events in a random order (determined by a seed)
+	// and records the order.
+	Record
+	// Replay delivers pending events in the order of a [Log].
+	Replay
+)
+
+type task struct {
+	event Event
+	fn    func() error
+}
+
+// Scheduler delivers the events submitted by a harness one at a time and
+// assigns each a logical timestamp.
+//
+// Events may be submitted concurrently (including by the function of the
+// event being delivered) but are delivered serially by the caller of [Step]
+// or [Run].
+type Scheduler struct {
+	mode Mode
+	rng  *rand.Rand
+
+	l        sync.Mutex
+	pending  []*task // in submission order
+	log      Log
+	expected Log // events to deliver in [Replay] mode
+}
+
+// NewScheduler returns a [Passthrough] scheduler.
+func NewScheduler() *Scheduler {
+	return &Scheduler{mode: Passthrough}
+}
+
+// NewRecorder returns a [Record] scheduler that orders events using [seed]
+// (the same seed and harness always produce the same [Log]).
+func NewRecorder(seed int64) *Scheduler {
+	return &Scheduler{
+		mode: Record,
+		rng:  rand.New(rand.NewSource(seed)), //nolint:gosec
+	}
+}
+
+// NewReplayer returns a [Replay] scheduler that delivers events in the order
+// of [log].
+func NewReplayer(log Log) *Scheduler {
+	return &Scheduler{mode: Replay, expected: slices.Clone(log)}
+}
+
+func (s *Scheduler) Mode() Mode {
+	return s.mode
+}
+
+// Submit adds an event of [kind] for [node] (identified by [id]) that runs
+// [fn] when delivered. In [Passthrough] mode, the event is delivered
+// immediately (and the result of [fn] is returned).
+func (s *Scheduler) Submit(kind Kind, node string, id string, fn func() error) error {
+	t := &task{event: Event{Kind: kind, Node: node, ID: id}, fn: fn}
+	s.l.Lock()
+	if s.mode == Passthrough {
+		t.event.Time = uint64(len(s.log)) + 1
+		s.log = append(s.log, t.event)
+		s.l.Unlock()
+		return fn()
+	}
+	s.pending = append(s.pending, t)
+	s.l.Unlock()
+	return nil
+}
+
+// Do submits an event and delivers pending events until there are none left
+// (so [fn] has run when it returns). This is used by a harness that must wait
+// for the result of an event (i.e. a block verification) before continuing.
+func (s *Scheduler) Do(kind Kind, node string, id string, fn func() error) error {
+	if err := s.Submit(kind, node, id, fn); err != nil {
+		return err
+	}
+	return s.Run()
+}
+
+// Step delivers a single pending event and returns false if there were no
+// pending events. The error of the delivered event is returned.
+//
+// In [Replay] mode, [ErrDiverged] is returned if the next event in the log is
+// not pending and [ErrEndOfLog] is returned if all events in the log have
+// been delivered (but some are still pending).
+func (s *Scheduler) Step() (bool, error) {
+	s.l.Lock()
+	if len(s.pending) == 0 {
+		s.l.Unlock()
+		return false, nil
+	}
+	var i int
+	switch s.mode {
+	case Record:
+		i = s.rng.Intn(len(s.pending))
+	case Replay:
+		if len(s.log) == len(s.expected) {
+			s.l.Unlock()
+			return false, fmt.Errorf("%w: %d events pending", ErrEndOfLog, len(s.pending))
+		}
+		next := s.expected[len(s.log)]
+		i = slices.IndexFunc(s.pending, func(t *task) bool { return t.event.matches(next) })
+		if i < 0 {
+			s.l.Unlock()
+			return false, fmt.Errorf("%w: %s is not pending", ErrDiverged, next)
+		}
+	}
+	t := s.pending[i]
+	s.pending = slices.Delete(s.pending, i, i+1)
+	t.event.Time = uint64(len(s.log)) + 1
+	s.log = append(s.log, t.event)
+	s.l.Unlock()
+
+	return true, t.fn()
+}
+
+// Run delivers pending events until there are none left (or an event
+// returns an error).
+func (s *Scheduler) Run() error {
+	for {
+		delivered, err := s.Step()
+		if err != nil {
+			return err
+		}
+		if !delivered {
+			return nil
+		}
+	}
+}
+
+// Log returns the events delivered so far.
+func (s *Scheduler) Log() Log {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	return slices.Clone(s.log)
+}
+
+// Done returns true if every event in the log has been delivered (always
+// true if not in [Replay] mode).
+func (s *Scheduler) Done() bool {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	return len(s.log) >= len(s.expected)
+}
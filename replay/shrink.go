@@ -0,0 +1,31 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package replay
+
+import "slices"
+
+// Shrink returns the shortest prefix of [log] for which [fails] returns true.
+//
+// [fails] should replay the prefix it is given (stopping at [ErrEndOfLog])
+// and then check the invariants of the harness. Prefixes are bisected, so
+// failures are assumed to persist once the events that cause them have been
+// delivered (which holds for invariants checked on state that the remaining
+// events don't repair).
+func Shrink(log Log, fails func(Log) bool) (Log, error) {
+	if !fails(log) {
+		return nil, ErrNotFailing
+	}
+
+	// fails(log[:lo]) is false and fails(log[:hi]) is true
+	lo, hi := -1, len(log)
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if fails(log[:mid]) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return slices.Clone(log[:hi]), nil
+}
@@ -6,10 +6,12 @@ package requester
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	rpc "github.com/gorilla/rpc/v2/json2"
@@ -20,6 +22,7 @@ type Option func(*Options)
 type Options struct {
 	headers     http.Header
 	queryParams url.Values
+	idempotent  bool
 }
 
 func NewOptions(ops []Option) *Options {
@@ -57,27 +60,152 @@ func WithQueryParam(key, val string) Option {
 	}
 }
 
+// Idempotent marks a request that can safely be processed more than once
+// (like a read of accepted state). Only requests marked as idempotent are
+// retried after they may have reached the server; all other requests are only
+// retried if they never did (see [IsPreAdmission]).
+func Idempotent() Option {
+	return func(o *Options) {
+		o.idempotent = true
+	}
+}
+
+type endpoint struct {
+	uri            string
+	unhealthyUntil time.Time
+}
+
+// EndpointRequester issues requests to one of a set of equivalent endpoints.
+//
+// Requests are sent to the same (sticky) endpoint until it fails, at which
+// point the requester fails over to the next healthy endpoint.
 type EndpointRequester struct {
-	cli       *http.Client
-	uri, base string
+	cli    *http.Client
+	base   string
+	policy RetryPolicy
+
+	l         sync.Mutex
+	endpoints []*endpoint
+	active    int
 }
 
 func New(uri, base string) *EndpointRequester {
+	return NewMulti([]string{uri}, base, DefaultRetryPolicy)
+}
+
+// NewMulti returns an [EndpointRequester] that fails over between [uris]
+// (in order) and retries requests according to [policy].
+func NewMulti(uris []string, base string, policy RetryPolicy) *EndpointRequester {
 	t := http.DefaultTransport.(*http.Transport).Clone()
 	t.MaxIdleConns = 100_000
 	t.MaxConnsPerHost = 100_000
 	t.MaxIdleConnsPerHost = 100_000
 
+	endpoints := make([]*endpoint, len(uris))
+	for i, uri := range uris {
+		endpoints[i] = &endpoint{uri: uri}
+	}
 	return &EndpointRequester{
 		cli: &http.Client{
 			Timeout:   10 * time.Second,
 			Transport: t,
 		},
-		uri:  uri,
-		base: base,
+		base:      base,
+		policy:    policy,
+		endpoints: endpoints,
+	}
+}
+
+// WithBase returns a requester that issues requests to the methods of [base]
+// on the same endpoints (with the same [RetryPolicy]). Endpoint health is
+// tracked separately.
+func (e *EndpointRequester) WithBase(base string) *EndpointRequester {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	endpoints := make([]*endpoint, len(e.endpoints))
+	for i, ep := range e.endpoints {
+		endpoints[i] = &endpoint{uri: ep.uri}
+	}
+	return &EndpointRequester{
+		cli:       e.cli,
+		base:      base,
+		policy:    e.policy,
+		endpoints: endpoints,
+	}
+}
+
+// URI returns the endpoint requests are currently sent to.
+func (e *EndpointRequester) URI() string {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	return e.endpoints[e.active].uri
+}
+
+// next returns the endpoint to send the next request to (the active endpoint
+// unless it is unhealthy).
+func (e *EndpointRequester) next() (int, string) {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	now := time.Now()
+	for i := 0; i < len(e.endpoints); i++ {
+		idx := (e.active + i) % len(e.endpoints)
+		if now.After(e.endpoints[idx].unhealthyUntil) {
+			e.active = idx
+			return idx, e.endpoints[idx].uri
+		}
 	}
+
+	// If all endpoints are unhealthy, keep using the active one
+	return e.active, e.endpoints[e.active].uri
 }
 
+// markUnhealthy skips the endpoint at [idx] for [RetryPolicy.UnhealthyCooldown]
+// and fails over to the next endpoint.
+func (e *EndpointRequester) markUnhealthy(idx int) {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	e.endpoints[idx].unhealthyUntil = time.Now().Add(e.policy.UnhealthyCooldown)
+	if e.active == idx {
+		e.active = (idx + 1) % len(e.endpoints)
+	}
+}
+
+// CheckHealth issues [method] (which must be idempotent, like "ping") to each
+// endpoint and marks the endpoints that fail as unhealthy. It returns the
+// number of healthy endpoints.
+func (e *EndpointRequester) CheckHealth(ctx context.Context, method string) int {
+	e.l.Lock()
+	endpoints := make([]string, len(e.endpoints))
+	for i, endpoint := range e.endpoints {
+		endpoints[i] = endpoint.uri
+	}
+	e.l.Unlock()
+
+	healthy := 0
+	for i, endpoint := range endpoints {
+		err := e.send(ctx, endpoint, method, nil, new(json.RawMessage))
+		if err != nil {
+			e.markUnhealthy(i)
+			continue
+		}
+		e.l.Lock()
+		e.endpoints[i].unhealthyUntil = time.Time{}
+		e.l.Unlock()
+		healthy++
+	}
+	return healthy
+}
+
+// SendRequest issues [method] to the active endpoint, retrying (and failing
+// over to other endpoints) according to the [RetryPolicy] of the requester.
+// Requests that are not marked as [Idempotent] are never retried once they
+// may have been received by the server.
+//
+// Retries never exceed the deadline of [ctx].
 func (e *EndpointRequester) SendRequest(
 	ctx context.Context,
 	method string,
@@ -85,7 +213,39 @@ func (e *EndpointRequester) SendRequest(
 	reply interface{},
 	options ...Option,
 ) error {
-	uri, err := url.Parse(e.uri)
+	idempotent := NewOptions(options).idempotent
+	var err error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if serr := sleep(ctx, e.policy.Backoff(attempt)); serr != nil {
+				return fmt.Errorf("%w: last error: %w", serr, err)
+			}
+		}
+		idx, uri := e.next()
+		err = e.send(ctx, uri, method, params, reply, options...)
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+		e.markUnhealthy(idx)
+		if !idempotent && !IsPreAdmission(err) {
+			// The request may have been processed by the server
+			return err
+		}
+		if attempt+1 >= e.policy.MaxAttempts {
+			return err
+		}
+	}
+}
+
+func (e *EndpointRequester) send(
+	ctx context.Context,
+	endpoint string,
+	method string,
+	params interface{},
+	reply interface{},
+	options ...Option,
+) error {
+	uri, err := url.Parse(endpoint)
 	if err != nil {
 		return err
 	}
@@ -140,7 +300,7 @@ func SendJSONRequest(
 		// Drop any error during close to report the original error
 		all, _ := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
-		return fmt.Errorf("received status code: %d %s %s", resp.StatusCode, all, uri.String())
+		return &StatusError{StatusCode: resp.StatusCode, Body: all, URI: uri.String()}
 	}
 
 	if err := rpc.DecodeClientResponse(resp.Body, reply); err != nil {
@@ -0,0 +1,214 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package requester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	rpc "github.com/gorilla/rpc/v2/json2"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:       3,
+	InitialBackoff:    time.Millisecond,
+	MaxBackoff:        10 * time.Millisecond,
+	Multiplier:        2,
+	Jitter:            0.2,
+	UnhealthyCooldown: time.Minute,
+}
+
+type testReply struct {
+	Server string `json:"server"`
+}
+
+// testServer responds to every request with its name unless [kill] is set, in
+// which case the connection is dropped after the request is received (as if
+// the server was killed mid-request).
+type testServer struct {
+	*httptest.Server
+
+	name     string
+	requests atomic.Int32
+	kill     atomic.Bool
+	status   atomic.Int32
+}
+
+func newTestServer(t *testing.T, name string) *testServer {
+	s := &testServer{name: name}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.requests.Add(1)
+		if s.kill.Load() {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			require.NoError(t, conn.Close())
+			return
+		}
+		if status := s.status.Load(); status != 0 {
+			w.WriteHeader(int(status))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","result":{"server":%q},"id":0}`, s.name)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestSendRequestFailover(t *testing.T) {
+	tests := []struct {
+		name       string
+		idempotent bool
+		setup      func(primary *testServer)
+		err        error
+		status     int
+	}{
+		{
+			name:       "read killed mid-request",
+			idempotent: true,
+			setup:      func(primary *testServer) { primary.kill.Store(true) },
+		},
+		{
+			name:  "submission killed mid-request",
+			setup: func(primary *testServer) { primary.kill.Store(true) },
+			err:   io.EOF,
+		},
+		{
+			name:  "submission before admission",
+			setup: func(primary *testServer) { primary.Close() },
+		},
+		{
+			name:       "read server error",
+			idempotent: true,
+			setup:      func(primary *testServer) { primary.status.Store(http.StatusServiceUnavailable) },
+		},
+		{
+			name:   "submission server error",
+			setup:  func(primary *testServer) { primary.status.Store(http.StatusServiceUnavailable) },
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "read client error",
+			idempotent: true,
+			setup:      func(primary *testServer) { primary.status.Store(http.StatusBadRequest) },
+			status:     http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			primary := newTestServer(t, "primary")
+			secondary := newTestServer(t, "secondary")
+			tt.setup(primary)
+			r := NewMulti([]string{primary.URL, secondary.URL}, "test", testRetryPolicy)
+
+			var options []Option
+			if tt.idempotent {
+				options = append(options, Idempotent())
+			}
+			reply := new(testReply)
+			err := r.SendRequest(context.Background(), "method", nil, reply, options...)
+			if tt.err != nil || tt.status != 0 {
+				if tt.err != nil {
+					require.ErrorIs(err, tt.err)
+				} else {
+					var statusErr *StatusError
+					require.ErrorAs(err, &statusErr)
+					require.Equal(tt.status, statusErr.StatusCode)
+				}
+				require.Zero(secondary.requests.Load())
+				return
+			}
+			require.NoError(err)
+			require.Equal("secondary", reply.Server)
+			require.Equal(int32(1), secondary.requests.Load())
+
+			// Requests stick to the endpoint that served the last request
+			require.Equal(secondary.URL, r.URI())
+			require.NoError(r.SendRequest(context.Background(), "method", nil, reply, options...))
+			require.Equal("secondary", reply.Server)
+			require.Equal(int32(2), secondary.requests.Load())
+		})
+	}
+}
+
+func TestSendRequestDeadline(t *testing.T) {
+	require := require.New(t)
+	primary := newTestServer(t, "primary")
+	primary.status.Store(http.StatusServiceUnavailable)
+	r := NewMulti([]string{primary.URL}, "test", RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second,
+		Multiplier:     1,
+	})
+
+	// Retries are not attempted if they can't be issued before the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := r.SendRequest(ctx, "method", nil, new(testReply), Idempotent())
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.Less(time.Since(start), time.Second)
+	require.Equal(int32(1), primary.requests.Load())
+}
+
+func TestCheckHealth(t *testing.T) {
+	require := require.New(t)
+	primary := newTestServer(t, "primary")
+	secondary := newTestServer(t, "secondary")
+	r := NewMulti([]string{primary.URL, secondary.URL}, "test", testRetryPolicy)
+	require.Equal(2, r.CheckHealth(context.Background(), "ping"))
+	require.Equal(primary.URL, r.URI())
+
+	// Unhealthy endpoints are skipped
+	primary.status.Store(http.StatusServiceUnavailable)
+	require.Equal(1, r.CheckHealth(context.Background(), "ping"))
+	require.Equal(secondary.URL, r.URI())
+
+	// Endpoints are used again once they recover
+	primary.status.Store(0)
+	secondary.status.Store(http.StatusServiceUnavailable)
+	require.Equal(1, r.CheckHealth(context.Background(), "ping"))
+	reply := new(testReply)
+	require.NoError(r.SendRequest(context.Background(), "method", nil, reply))
+	require.Equal("primary", reply.Server)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	require := require.New(t)
+	p := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	require.Equal(100*time.Millisecond, p.Backoff(1))
+	require.Equal(400*time.Millisecond, p.Backoff(3))
+	require.Equal(time.Second, p.Backoff(10))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := p.Backoff(1)
+		require.GreaterOrEqual(backoff, 50*time.Millisecond)
+		require.LessOrEqual(backoff, 150*time.Millisecond)
+	}
+}
+
+func TestIsRejected(t *testing.T) {
+	require := require.New(t)
+
+	require.True(IsRejected(fmt.Errorf("failed to decode client response: %w", &rpc.Error{Message: "invalid"})))
+	require.True(IsRejected(&StatusError{StatusCode: http.StatusBadRequest}))
+	require.False(IsRejected(&StatusError{StatusCode: http.StatusServiceUnavailable}))
+	require.False(IsRejected(io.EOF))
+	require.False(IsRejected(nil))
+}
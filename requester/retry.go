@@ -0,0 +1,142 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	rpc "github.com/gorilla/rpc/v2/json2"
+)
+
+// RetryPolicy controls how requests are retried when they fail with a
+// retryable error (a network error or a 5xx status code).
+//
+// Only requests marked with [Idempotent] are retried after a failure that
+// happened once the request could have been admitted by the server. All other
+// requests are only retried if they never reached the server (see
+// [IsPreAdmission]).
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is issued
+	// (including the first attempt). A value <= 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the backoff after each retry.
+	Multiplier float64
+	// Jitter is the fraction (in [0, 1]) of each backoff that is randomized.
+	Jitter float64
+	// UnhealthyCooldown is how long an endpoint that failed to serve a
+	// request is skipped (unless all endpoints are unhealthy).
+	UnhealthyCooldown time.Duration
+}
+
+// NoRetries issues every request exactly once.
+var NoRetries = RetryPolicy{MaxAttempts: 1}
+
+// DefaultRetryPolicy is used by clients that are not explicitly configured
+// with a [RetryPolicy].
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:       4,
+	InitialBackoff:    100 * time.Millisecond,
+	MaxBackoff:        2 * time.Second,
+	Multiplier:        2,
+	Jitter:            0.2,
+	UnhealthyCooldown: 5 * time.Second,
+}
+
+// Backoff returns the delay before retry [attempt] (starting at 1).
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt && backoff < float64(p.MaxBackoff); i++ {
+		backoff *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		//nolint:gosec
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+// StatusError is returned when an endpoint responds with a non-2xx status
+// code.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+	URI        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received status code: %d %s %s", e.StatusCode, e.Body, e.URI)
+}
+
+// IsPreAdmission returns true if [err] occurred before the request could
+// have been received by the server (the connection was never established),
+// in which case it is safe to retry any request.
+func IsPreAdmission(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// IsRetryable returns true if a request that failed with [err] may succeed
+// if issued again (possibly to another endpoint).
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || IsPreAdmission(err)
+}
+
+// IsRejected returns true if [err] was returned by the server after processing
+// the request (like a transaction that failed verification). Unlike retryable
+// errors, the same request will fail again but a modified one may not.
+func IsRejected(err error) bool {
+	var rpcErr *rpc.Error
+	if errors.As(err, &rpcErr) {
+		return true
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		statusErr.StatusCode >= http.StatusBadRequest &&
+		statusErr.StatusCode < http.StatusInternalServerError
+}
+
+// sleep waits for [d] or until [ctx] is done (whichever comes first).
+func sleep(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		// Don't wait for a retry that can't be issued before the deadline
+		return context.DeadlineExceeded
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var _ ChainChecksumSource = (*JSONRPCClient)(nil)
+
+// ChainChecksumSource serves the chain checksum of a node (see
+// [JSONRPCServer.ChainChecksum]).
+type ChainChecksumSource interface {
+	ChainChecksum(ctx context.Context) (*ChainChecksumReport, error)
+	GetChainCheckpoint(ctx context.Context, height uint64) (ids.ID, error)
+}
+
+// ChainDivergence is the result of comparing the checkpoints retained by two
+// nodes.
+type ChainDivergence struct {
+	// From and To are the range of checkpoints retained by both nodes.
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+
+	// Diverged is true if the checkpoints at [To] differ. FirstDiffering is
+	// the first checkpoint that differs and LastMatching is the checkpoint
+	// before it (0 if the nodes already differ at [From]).
+	Diverged       bool   `json:"diverged"`
+	FirstDiffering uint64 `json:"firstDiffering"`
+	LastMatching   uint64 `json:"lastMatching"`
+}
+
+// FindChainDivergence finds the first checkpoint at which the accepted chains
+// of [a] and [b] differ.
+//
+// Once two chains diverge, every later checkpoint differs (each block ID
+// commits to its parent), so the range of checkpoints retained by both nodes
+// is binary searched (fetching O(log n) checkpoints from each node).
+func FindChainDivergence(ctx context.Context, a ChainChecksumSource, b ChainChecksumSource) (*ChainDivergence, error) {
+	ra, err := a.ChainChecksum(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rb, err := b.ChainChecksum(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ra.Interval != rb.Interval {
+		return nil, fmt.Errorf("%w: %d != %d", ErrChecksumIntervalMismatch, ra.Interval, rb.Interval)
+	}
+	if ra.LatestCheckpoint == 0 || rb.LatestCheckpoint == 0 {
+		return nil, ErrNoCommonCheckpoints
+	}
+	d := &ChainDivergence{
+		From: max(ra.OldestCheckpoint, rb.OldestCheckpoint),
+		To:   min(ra.LatestCheckpoint, rb.LatestCheckpoint),
+	}
+	if d.From > d.To {
+		return nil, fmt.Errorf("%w: [%d, %d] and [%d, %d]", ErrNoCommonCheckpoints, ra.OldestCheckpoint, ra.LatestCheckpoint, rb.OldestCheckpoint, rb.LatestCheckpoint)
+	}
+	differs := func(height uint64) (bool, error) {
+		ca, err := a.GetChainCheckpoint(ctx, height)
+		if err != nil {
+			return false, fmt.Errorf("%w: height=%d", err, height)
+		}
+		cb, err := b.GetChainCheckpoint(ctx, height)
+		if err != nil {
+			return false, fmt.Errorf("%w: height=%d", err, height)
+		}
+		return ca != cb, nil
+	}
+
+	// Find the first index (of the checkpoints in [From, To]) that differs
+	var (
+		count  = (d.To-d.From)/ra.Interval + 1
+		lo, hi = uint64(0), count
+	)
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		diverged, err := differs(d.From + mid*ra.Interval)
+		if err != nil {
+			return nil, err
+		}
+		if diverged {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	if lo == count {
+		return d, nil
+	}
+	d.Diverged = true
+	d.FirstDiffering = d.From + lo*ra.Interval
+	if lo > 0 {
+		d.LastMatching = d.FirstDiffering - ra.Interval
+	}
+	return d, nil
+}
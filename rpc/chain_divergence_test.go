@@ -0,0 +1,114 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+// divergenceTestSource retains the checkpoints in [oldest, latest] (every
+// [interval]), which differ from [base] from [diverged] (if not 0).
+type divergenceTestSource struct {
+	interval uint64
+	oldest   uint64
+	latest   uint64
+	diverged uint64
+
+	fetched int
+}
+
+func (s *divergenceTestSource) ChainChecksum(context.Context) (*ChainChecksumReport, error) {
+	return &ChainChecksumReport{
+		Interval:         s.interval,
+		OldestCheckpoint: s.oldest,
+		LatestCheckpoint: s.latest,
+	}, nil
+}
+
+func (s *divergenceTestSource) GetChainCheckpoint(_ context.Context, height uint64) (ids.ID, error) {
+	if height < s.oldest || height > s.latest {
+		return ids.Empty, database.ErrNotFound
+	}
+	s.fetched++
+	checksum := ids.ID{byte(height), byte(height >> 8)}
+	if s.diverged > 0 && height >= s.diverged {
+		checksum[31] = 0x1
+	}
+	return checksum, nil
+}
+
+func TestFindChainDivergence(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        *divergenceTestSource
+		b        *divergenceTestSource
+		expected *ChainDivergence
+		err      error
+	}{
+		{
+			name:     "match",
+			a:        &divergenceTestSource{interval: 10, oldest: 10, latest: 1_000},
+			b:        &divergenceTestSource{interval: 10, oldest: 500, latest: 2_000},
+			expected: &ChainDivergence{From: 500, To: 1_000},
+		},
+		{
+			name: "diverged",
+			a:    &divergenceTestSource{interval: 10, oldest: 10, latest: 1_000},
+			b:    &divergenceTestSource{interval: 10, oldest: 10, latest: 1_000, diverged: 730},
+			expected: &ChainDivergence{
+				From:           10,
+				To:             1_000,
+				Diverged:       true,
+				FirstDiffering: 730,
+				LastMatching:   720,
+			},
+		},
+		{
+			name: "diverged before common range",
+			a:    &divergenceTestSource{interval: 10, oldest: 10, latest: 1_000},
+			b:    &divergenceTestSource{interval: 10, oldest: 300, latest: 1_000, diverged: 200},
+			expected: &ChainDivergence{
+				From:           300,
+				To:             1_000,
+				Diverged:       true,
+				FirstDiffering: 300,
+			},
+		},
+		{
+			name: "interval mismatch",
+			a:    &divergenceTestSource{interval: 10, oldest: 10, latest: 1_000},
+			b:    &divergenceTestSource{interval: 20, oldest: 20, latest: 1_000},
+			err:  ErrChecksumIntervalMismatch,
+		},
+		{
+			name: "no checkpoints",
+			a:    &divergenceTestSource{interval: 10, oldest: 10, latest: 1_000},
+			b:    &divergenceTestSource{interval: 10},
+			err:  ErrNoCommonCheckpoints,
+		},
+		{
+			name: "disjoint checkpoints",
+			a:    &divergenceTestSource{interval: 10, oldest: 10, latest: 100},
+			b:    &divergenceTestSource{interval: 10, oldest: 200, latest: 1_000},
+			err:  ErrNoCommonCheckpoints,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			d, err := FindChainDivergence(context.TODO(), tt.a, tt.b)
+			require.ErrorIs(err, tt.err)
+			require.Equal(tt.expected, d)
+
+			// Only O(log n) checkpoints are fetched
+			require.LessOrEqual(tt.a.fetched, 8)
+		})
+	}
+}
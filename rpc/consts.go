@@ -11,4 +11,11 @@ const (
 	WebSocketEndpoint = "/corews"
 
 	DefaultHandshakeTimeout = 10 * time.Second
+
+	// MaxPageSize is the max number of items returned by a list endpoint.
+	MaxPageSize = 1024
+
+	// BlockBacklogSize is the number of recently accepted blocks kept by the
+	// [WebSocketServer] to replay to block subscribers that reconnect.
+	BlockBacklogSize = 32
 )
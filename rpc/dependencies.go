@@ -12,7 +12,9 @@ import (
 	"github.com/ava-labs/avalanchego/utils/logging"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/network"
 )
 
 type VM interface {
@@ -20,17 +22,37 @@ type VM interface {
 	NetworkID() uint32
 	SubnetID() ids.ID
 	Tracer() trace.Tracer
-	Logger() logging.Logger
+	RPCLogger() logging.Logger
 	Registry() (chain.ActionRegistry, chain.AuthRegistry)
 	Submit(
 		ctx context.Context,
 		verifySig bool,
 		txs []*chain.Transaction,
 	) (errs []error)
+	// SubmitDeferred is like Submit (with auth verification) but may add
+	// [txs] to the mempool after returning, once their auth is verified in
+	// the background.
+	SubmitDeferred(ctx context.Context, txs []*chain.Transaction) (errs []error)
+	// SubmitBundle adds all transactions in [bundle] to the mempool (as a
+	// unit) or none of them.
+	SubmitBundle(ctx context.Context, verifyAuth bool, bundle *chain.Bundle) error
 	LastAcceptedBlock() *chain.StatelessBlock
 	UnitPrices(context.Context) (fees.Dimensions, error)
 	CurrentValidators(
 		context.Context,
 	) (map[ids.NodeID]*validators.GetValidatorOutput, map[string]struct{})
 	GetVerifyAuth() bool
+	GetStoreTxsByAddress() bool
+	GetTxsByAddress(addr codec.Address, pageToken string, limit int) ([]*AddressTx, string, error)
+	GetStoreStateUsage() bool
+	GetStateUsage() (*StateUsageReport, error)
+	GetChainChecksum() (*ChainChecksumReport, error)
+	GetChainCheckpoint(height uint64) (ids.ID, error)
+	PeerHandshakes() map[ids.NodeID]*network.Handshake
+	FeatureNames(network.Features) []string
+	AdminAPI() bool
+	LogLevels() map[string]logging.Level
+	SetLogLevels(map[string]logging.Level) error
+	ProfileBlocks(count int) (string, error)
+	BuildPreview(context.Context) (*chain.BlockPreview, error)
 }
@@ -9,4 +9,24 @@ var (
 	ErrClosed         = errors.New("closed")
 	ErrExpired        = errors.New("expired")
 	ErrMessageMissing = errors.New("message missing")
+	ErrIndexDisabled  = errors.New("index disabled")
+	ErrAdminDisabled  = errors.New("admin api disabled")
+	ErrBlockGap       = errors.New("blocks missing from stream")
+	ErrOutOfOrder     = errors.New("block message out of order")
+
+	ErrInvalidPageToken = errors.New("invalid page token")
+
+	ErrChecksumIntervalMismatch = errors.New("chain checksum intervals differ")
+	ErrNoCommonCheckpoints      = errors.New("no common chain checkpoints")
+
+	ErrInvalidOpenRPCArgs = errors.New("args must be a struct")
+	ErrInvalidOpenRPCTag  = errors.New("invalid openrpc tag")
+
+	ErrInvalidNamespace     = errors.New("invalid namespace")
+	ErrDuplicateNamespace   = errors.New("duplicate namespace")
+	ErrUnprotectedNamespace = errors.New("admin namespace requires a token or localhost only")
+	ErrMethodConflict       = errors.New("method already registered")
+	ErrNotLocalhost         = errors.New("namespace only served to localhost")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrRateLimited          = errors.New("rate limited")
 )
@@ -10,8 +10,10 @@ import (
 	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
 
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/requester"
 	"github.com/ava-labs/hypersdk/utils"
@@ -34,18 +36,36 @@ type JSONRPCClient struct {
 }
 
 func NewJSONRPCClient(uri string) *JSONRPCClient {
-	uri = strings.TrimSuffix(uri, "/")
-	uri += JSONRPCEndpoint
-	req := requester.New(uri, Name)
+	return NewJSONRPCClientWithEndpoints([]string{uri}, requester.DefaultRetryPolicy)
+}
+
+// NewJSONRPCClientWithEndpoints returns a client that fails over between
+// [uris] and retries requests according to [policy].
+//
+// Reads are retried on any network error or 5xx status code. Transactions
+// are only resubmitted if the previous submission never reached the server.
+func NewJSONRPCClientWithEndpoints(uris []string, policy requester.RetryPolicy) *JSONRPCClient {
+	endpoints := make([]string, len(uris))
+	for i, uri := range uris {
+		endpoints[i] = strings.TrimSuffix(uri, "/") + JSONRPCEndpoint
+	}
+	req := requester.NewMulti(endpoints, Name, policy)
 	return &JSONRPCClient{requester: req}
 }
 
+// CheckHealth pings each endpoint of [cli] and returns the number that are
+// healthy (unhealthy endpoints are skipped until they recover).
+func (cli *JSONRPCClient) CheckHealth(ctx context.Context) int {
+	return cli.requester.CheckHealth(ctx, "ping")
+}
+
 func (cli *JSONRPCClient) Ping(ctx context.Context) (bool, error) {
 	resp := new(PingReply)
 	err := cli.requester.SendRequest(ctx,
 		"ping",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.Success, err
 }
@@ -61,6 +81,7 @@ func (cli *JSONRPCClient) Network(ctx context.Context) (uint32, ids.ID, ids.ID,
 		"network",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	if err != nil {
 		return 0, ids.Empty, ids.Empty, err
@@ -78,6 +99,7 @@ func (cli *JSONRPCClient) Accepted(ctx context.Context) (ids.ID, uint64, int64,
 		"lastAccepted",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	return resp.BlockID, resp.Height, resp.Timestamp, err
 }
@@ -93,6 +115,7 @@ func (cli *JSONRPCClient) UnitPrices(ctx context.Context, useCache bool) (fees.D
 		"unitPrices",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	if err != nil {
 		return fees.Dimensions{}, err
@@ -115,6 +138,141 @@ func (cli *JSONRPCClient) SubmitTx(ctx context.Context, d []byte) (ids.ID, error
 	return resp.TxID, err
 }
 
+// SubmitBundle submits the transactions in [txs] as a bundle (see
+// [chain.Bundle]) and returns the ID of the bundle.
+func (cli *JSONRPCClient) SubmitBundle(ctx context.Context, txs [][]byte) (ids.ID, error) {
+	resp := new(SubmitBundleReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"submitBundle",
+		&SubmitBundleArgs{Txs: txs},
+		resp,
+	)
+	return resp.BundleID, err
+}
+
+// GetTxsByAddress returns a page of transactions involving [addr] (most
+// recent first) and the token to use to fetch the next page (empty if there
+// are no more).
+func (cli *JSONRPCClient) GetTxsByAddress(
+	ctx context.Context,
+	addr codec.Address,
+	pageToken string,
+	limit int,
+) ([]*AddressTx, string, error) {
+	resp := new(GetTxsByAddressReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"getTxsByAddress",
+		&GetTxsByAddressArgs{
+			Address:   addr,
+			PageToken: pageToken,
+			Limit:     limit,
+		},
+		resp,
+		requester.Idempotent(),
+	)
+	return resp.Txs, resp.NextPageToken, err
+}
+
+// GetStateUsage returns the approximate usage of each registered state prefix
+// (see [StateUsageReport]).
+func (cli *JSONRPCClient) GetStateUsage(ctx context.Context) (*StateUsageReport, error) {
+	resp := new(StateUsageReport)
+	err := cli.requester.SendRequest(
+		ctx,
+		"getStateUsage",
+		nil,
+		resp,
+		requester.Idempotent(),
+	)
+	return resp, err
+}
+
+func (cli *JSONRPCClient) ChainChecksum(ctx context.Context) (*ChainChecksumReport, error) {
+	resp := new(ChainChecksumReport)
+	err := cli.requester.SendRequest(
+		ctx,
+		"chainChecksum",
+		nil,
+		resp,
+		requester.Idempotent(),
+	)
+	return resp, err
+}
+
+func (cli *JSONRPCClient) GetChainCheckpoint(ctx context.Context, height uint64) (ids.ID, error) {
+	resp := new(GetChainCheckpointReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"getChainCheckpoint",
+		&GetChainCheckpointArgs{Height: height},
+		resp,
+		requester.Idempotent(),
+	)
+	return resp.Checksum, err
+}
+
+// PeerFeatures returns the software version and protocol features advertised
+// by each connected peer (ordered by node ID).
+func (cli *JSONRPCClient) PeerFeatures(ctx context.Context) ([]*PeerFeatures, error) {
+	resp := new(PeerFeaturesReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"peerFeatures",
+		nil,
+		resp,
+		requester.Idempotent(),
+	)
+	return resp.Peers, err
+}
+
+func (cli *JSONRPCClient) LogLevels(ctx context.Context) (map[string]logging.Level, error) {
+	resp := new(LogLevelsReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"logLevels",
+		nil,
+		resp,
+		requester.Idempotent(),
+	)
+	return resp.Levels, err
+}
+
+func (cli *JSONRPCClient) SetLogLevels(ctx context.Context, levels map[string]logging.Level) (map[string]logging.Level, error) {
+	resp := new(LogLevelsReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"setLogLevels",
+		&LogLevelsArgs{Levels: levels},
+		resp,
+	)
+	return resp.Levels, err
+}
+
+func (cli *JSONRPCClient) ProfileBlocks(ctx context.Context, count int) (string, error) {
+	resp := new(ProfileBlocksReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"profileBlocks",
+		&ProfileBlocksArgs{Count: count},
+		resp,
+	)
+	return resp.Dir, err
+}
+
+func (cli *JSONRPCClient) BuildPreview(ctx context.Context) (*chain.BlockPreview, error) {
+	resp := new(BuildPreviewReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"buildPreview",
+		nil,
+		resp,
+		requester.Idempotent(),
+	)
+	return resp.Preview, err
+}
+
 type Modifier interface {
 	Base(*chain.Base)
 }
@@ -196,3 +354,32 @@ func Wait(ctx context.Context, check func(ctx context.Context) (bool, error)) er
 	}
 	return ctx.Err()
 }
+
+// NamespaceClient issues requests to the methods of a [Namespace] served by
+// the [Router] of a node (see [JSONRPCClient.Namespace]).
+type NamespaceClient struct {
+	requester *requester.EndpointRequester
+	options   []requester.Option
+}
+
+// Namespace returns a client for the methods of the namespace [name] on the
+// endpoints of [cli]. [options] (like [WithToken]) are sent with each
+// request.
+func (cli *JSONRPCClient) Namespace(name string, options ...requester.Option) *NamespaceClient {
+	return &NamespaceClient{
+		requester: cli.requester.WithBase(name),
+		options:   options,
+	}
+}
+
+// WithToken authenticates requests to a namespace that requires
+// [NamespaceConfig.Token].
+func WithToken(token string) requester.Option {
+	return requester.WithHeader("Authorization", bearerPrefix+token)
+}
+
+// Call issues [method] of the namespace with [args] and decodes the response
+// into [reply].
+func (cli *NamespaceClient) Call(ctx context.Context, method string, args any, reply any) error {
+	return cli.requester.SendRequest(ctx, method, args, reply, cli.options...)
+}
@@ -7,13 +7,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
 
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/network"
 )
 
 type JSONRPCServer struct {
@@ -29,7 +32,7 @@ type PingReply struct {
 }
 
 func (j *JSONRPCServer) Ping(_ *http.Request, _ *struct{}, reply *PingReply) (err error) {
-	j.vm.Logger().Info("ping")
+	j.vm.RPCLogger().Info("ping")
 	reply.Success = true
 	return nil
 }
@@ -72,17 +75,56 @@ func (j *JSONRPCServer) SubmitTx(
 	if !rtx.Empty() {
 		return errors.New("tx has extra bytes")
 	}
-	msg, err := tx.Digest()
+	txID := tx.ID()
+	reply.TxID = txID
+
+	// If auth verification is deferred, a tx with an invalid signature is
+	// only reported as removed to websocket listeners
+	return j.vm.SubmitDeferred(ctx, []*chain.Transaction{tx})[0]
+}
+
+type SubmitBundleArgs struct {
+	Txs [][]byte `json:"txs"`
+}
+
+type SubmitBundleReply struct {
+	BundleID ids.ID   `json:"bundleId"`
+	TxIDs    []ids.ID `json:"txIds"`
+}
+
+// SubmitBundle submits [args.Txs] as a [chain.Bundle]: they are included in
+// the same block (consecutively and in order) or not at all.
+func (j *JSONRPCServer) SubmitBundle(
+	req *http.Request,
+	args *SubmitBundleArgs,
+	reply *SubmitBundleReply,
+) error {
+	ctx, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.SubmitBundle")
+	defer span.End()
+
+	actionRegistry, authRegistry := j.vm.Registry()
+	txs := make([]*chain.Transaction, len(args.Txs))
+	for i, raw := range args.Txs {
+		rtx := codec.NewReader(raw, consts.NetworkSizeLimit)
+		tx, err := chain.UnmarshalTx(rtx, actionRegistry, authRegistry)
+		if err != nil {
+			return fmt.Errorf("%w: unable to unmarshal tx %d on public service", err, i)
+		}
+		if !rtx.Empty() {
+			return fmt.Errorf("tx %d has extra bytes", i)
+		}
+		txs[i] = tx
+	}
+	bundle, err := chain.NewBundle(txs)
 	if err != nil {
-		// Should never occur because populated during unmarshal
 		return err
 	}
-	if err := tx.Auth.Verify(ctx, msg); err != nil {
-		return err
+	reply.BundleID = bundle.ID()
+	reply.TxIDs = make([]ids.ID, len(txs))
+	for i, tx := range txs {
+		reply.TxIDs[i] = tx.ID()
 	}
-	txID := tx.ID()
-	reply.TxID = txID
-	return j.vm.Submit(ctx, false, []*chain.Transaction{tx})[0]
+	return j.vm.SubmitBundle(ctx, true, bundle)
 }
 
 type LastAcceptedReply struct {
@@ -118,3 +160,243 @@ func (j *JSONRPCServer) UnitPrices(
 	reply.UnitPrices = unitPrices
 	return nil
 }
+
+type GetTxsByAddressArgs struct {
+	Address   codec.Address `json:"address"`
+	PageToken string        `json:"pageToken"`
+	Limit     int           `json:"limit"`
+}
+
+type AddressTx struct {
+	TxID      ids.ID `json:"txId"`
+	BlockID   ids.ID `json:"blockId"`
+	Height    uint64 `json:"height"`
+	Index     uint32 `json:"index"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type GetTxsByAddressReply struct {
+	Txs           []*AddressTx `json:"txs"`
+	NextPageToken string       `json:"nextPageToken"`
+}
+
+func (j *JSONRPCServer) GetTxsByAddress(
+	req *http.Request,
+	args *GetTxsByAddressArgs,
+	reply *GetTxsByAddressReply,
+) error {
+	_, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.GetTxsByAddress")
+	defer span.End()
+
+	if !j.vm.GetStoreTxsByAddress() {
+		return ErrIndexDisabled
+	}
+	txs, next, err := j.vm.GetTxsByAddress(args.Address, args.PageToken, PageLimit(args.Limit))
+	if err != nil {
+		return err
+	}
+	reply.Txs = txs
+	reply.NextPageToken = next
+	return nil
+}
+
+type PrefixUsage struct {
+	Name   string           `json:"name"`
+	Prefix []byte           `json:"prefix"`
+	Usage  chain.StateUsage `json:"usage"`
+}
+
+// StateUsageReport is the approximate number of keys (and bytes of keys and
+// values) stored under each state prefix registered by the VM. Counters may
+// drift by a few percent from the actual state (and are recomputed in the
+// background when [Rebuilding]).
+type StateUsageReport struct {
+	Height       uint64           `json:"height"`
+	Rebuilding   bool             `json:"rebuilding"`
+	Prefixes     []*PrefixUsage   `json:"prefixes"`
+	Unregistered chain.StateUsage `json:"unregistered"`
+	Total        chain.StateUsage `json:"total"`
+}
+
+// GetStateUsage returns the approximate usage of each registered state prefix
+// as of the last accepted block.
+func (j *JSONRPCServer) GetStateUsage(req *http.Request, _ *struct{}, reply *StateUsageReport) error {
+	_, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.GetStateUsage")
+	defer span.End()
+
+	if !j.vm.GetStoreStateUsage() {
+		return ErrIndexDisabled
+	}
+	report, err := j.vm.GetStateUsage()
+	if err != nil {
+		return err
+	}
+	*reply = *report
+	return nil
+}
+
+// ChainCheckpoint is the rolling checksum of the accepted chain at a
+// multiple of [ChainChecksumReport.Interval].
+type ChainCheckpoint struct {
+	Height   uint64 `json:"height"`
+	Checksum ids.ID `json:"checksum"`
+}
+
+// ChainChecksumReport is the rolling checksum of the accepted chain as of the
+// last accepted block. The checksum restarts after each checkpoint, so it is
+// only [Complete] once a node has accepted every block since the last
+// checkpoint.
+type ChainChecksumReport struct {
+	Interval uint64 `json:"interval"`
+	Height   uint64 `json:"height"`
+	Checksum ids.ID `json:"checksum"`
+	Complete bool   `json:"complete"`
+
+	// OldestCheckpoint and LatestCheckpoint are the range of checkpoints
+	// retained (both 0 if there are none)
+	OldestCheckpoint uint64 `json:"oldestCheckpoint"`
+	LatestCheckpoint uint64 `json:"latestCheckpoint"`
+
+	// Checkpoints are the most recent checkpoints (in ascending height)
+	Checkpoints []*ChainCheckpoint `json:"checkpoints"`
+}
+
+// ChainChecksum returns the rolling checksum of the accepted chain, which can
+// be compared across nodes to detect divergence (see [FindChainDivergence]).
+// It is node-local and not part of consensus.
+func (j *JSONRPCServer) ChainChecksum(_ *http.Request, _ *struct{}, reply *ChainChecksumReport) error {
+	report, err := j.vm.GetChainChecksum()
+	if err != nil {
+		return err
+	}
+	*reply = *report
+	return nil
+}
+
+type GetChainCheckpointArgs struct {
+	Height uint64 `json:"height"`
+}
+
+type GetChainCheckpointReply struct {
+	Checksum ids.ID `json:"checksum"`
+}
+
+// GetChainCheckpoint returns the checkpoint at [Height] (if it is retained).
+func (j *JSONRPCServer) GetChainCheckpoint(_ *http.Request, args *GetChainCheckpointArgs, reply *GetChainCheckpointReply) error {
+	checksum, err := j.vm.GetChainCheckpoint(args.Height)
+	if err != nil {
+		return err
+	}
+	reply.Checksum = checksum
+	return nil
+}
+
+type PeerFeatures struct {
+	NodeID   ids.NodeID       `json:"nodeId"`
+	Version  string           `json:"version"`
+	Features network.Features `json:"features"`
+	Names    []string         `json:"names"`
+}
+
+type PeerFeaturesReply struct {
+	Peers []*PeerFeatures `json:"peers"`
+}
+
+// PeerFeatures returns the software version and protocol features advertised
+// by each connected peer. Peers that have not sent a handshake (like peers
+// running older software) are omitted and are assumed to support
+// [network.LegacyFeatures].
+func (j *JSONRPCServer) PeerFeatures(_ *http.Request, _ *struct{}, reply *PeerFeaturesReply) error {
+	peers := j.vm.PeerHandshakes()
+	reply.Peers = make([]*PeerFeatures, 0, len(peers))
+	for nodeID, handshake := range peers {
+		reply.Peers = append(reply.Peers, &PeerFeatures{
+			NodeID:   nodeID,
+			Version:  handshake.Version,
+			Features: handshake.Features,
+			Names:    j.vm.FeatureNames(handshake.Features),
+		})
+	}
+	slices.SortFunc(reply.Peers, func(a, b *PeerFeatures) int {
+		return a.NodeID.Compare(b.NodeID)
+	})
+	return nil
+}
+
+type LogLevelsArgs struct {
+	Levels map[string]logging.Level `json:"levels"`
+}
+
+type LogLevelsReply struct {
+	Levels map[string]logging.Level `json:"levels"`
+}
+
+// LogLevels returns the log level of each component.
+func (j *JSONRPCServer) LogLevels(_ *http.Request, _ *struct{}, reply *LogLevelsReply) error {
+	if !j.vm.AdminAPI() {
+		return ErrAdminDisabled
+	}
+	reply.Levels = j.vm.LogLevels()
+	return nil
+}
+
+// SetLogLevels replaces the log level overrides of all components and
+// returns the resulting level of each component. Components omitted from
+// [args.Levels] log at the level of the VM.
+func (j *JSONRPCServer) SetLogLevels(_ *http.Request, args *LogLevelsArgs, reply *LogLevelsReply) error {
+	if !j.vm.AdminAPI() {
+		return ErrAdminDisabled
+	}
+	if err := j.vm.SetLogLevels(args.Levels); err != nil {
+		return err
+	}
+	reply.Levels = j.vm.LogLevels()
+	return nil
+}
+
+type ProfileBlocksArgs struct {
+	Count int `json:"count"`
+}
+
+type ProfileBlocksReply struct {
+	Dir string `json:"dir"`
+}
+
+// ProfileBlocks collects a CPU profile and a summary (the duration of each
+// phase and the action types with the largest execution time) of the
+// verification of the next [args.Count] blocks (0 stops profiling) and
+// returns the directory they are written to.
+func (j *JSONRPCServer) ProfileBlocks(_ *http.Request, args *ProfileBlocksArgs, reply *ProfileBlocksReply) error {
+	if !j.vm.AdminAPI() {
+		return ErrAdminDisabled
+	}
+	dir, err := j.vm.ProfileBlocks(args.Count)
+	if err != nil {
+		return err
+	}
+	reply.Dir = dir
+	return nil
+}
+
+type BuildPreviewReply struct {
+	Preview *chain.BlockPreview `json:"preview"`
+}
+
+// BuildPreview returns the block this node would build on top of its
+// preferred block right now (without modifying its mempool or producing a
+// block). Previews are rate-limited and fail while the node is building a
+// block.
+func (j *JSONRPCServer) BuildPreview(req *http.Request, _ *struct{}, reply *BuildPreviewReply) error {
+	if !j.vm.AdminAPI() {
+		return ErrAdminDisabled
+	}
+	ctx, span := j.vm.Tracer().Start(req.Context(), "JSONRPCServer.BuildPreview")
+	defer span.End()
+
+	preview, err := j.vm.BuildPreview(ctx)
+	if err != nil {
+		return err
+	}
+	reply.Preview = preview
+	return nil
+}
@@ -0,0 +1,381 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+const (
+	// OpenRPCVersion is the version of the OpenRPC specification followed by
+	// the documents returned by [NewOpenRPCDocument].
+	OpenRPCVersion = "1.2.6"
+
+	// DefaultOpenRPCDocumentVersion is the version of the documents served by
+	// [NewJSONRPCHandler] (documents committed alongside a release should be
+	// generated with the version of the release).
+	DefaultOpenRPCDocumentVersion = "0.0.0"
+
+	// DiscoverMethod is the method served by [NewJSONRPCHandler] that returns
+	// the OpenRPC document describing the other methods of the handler.
+	DiscoverMethod = discoverService + ".discover"
+
+	discoverService = "rpc"
+
+	// openRPCTag overrides the schema derived for a field (for example,
+	// `openrpc:"type=string,format=hex"` for a field with a custom JSON
+	// encoding).
+	openRPCTag = "openrpc"
+
+	// openRPCSchemaRef is the prefix of references to the schemas in
+	// [OpenRPCComponents.Schemas].
+	openRPCSchemaRef = "#/components/schemas/"
+)
+
+var (
+	httpRequestType   = reflect.TypeOf((*http.Request)(nil))
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// wellKnownSchemas are the schemas of types with a custom JSON encoding
+	// that are commonly used by services.
+	wellKnownSchemas = map[reflect.Type]OpenRPCSchema{
+		reflect.TypeOf(ids.ID{}):         {Type: "string", Format: "cb58"},
+		reflect.TypeOf(ids.ShortID{}):    {Type: "string", Format: "cb58"},
+		reflect.TypeOf(ids.NodeID{}):     {Type: "string", Format: "node-id"},
+		reflect.TypeOf(logging.Level(0)): {Type: "string", Format: "log-level"},
+	}
+
+	// openRPCErrors are the errors that may be returned by any method. Codes
+	// are assigned by the JSON-RPC codec: all errors returned by handlers
+	// (like [ErrAdminDisabled]) are reported as "server error" with the error
+	// as the message.
+	openRPCErrors = map[string]*OpenRPCError{
+		"parseError":     {Code: -32700, Message: "parse error"},
+		"invalidRequest": {Code: -32600, Message: "invalid request"},
+		"methodNotFound": {Code: -32601, Message: "method not found"},
+		"invalidParams":  {Code: -32602, Message: "invalid params"},
+		"internalError":  {Code: -32603, Message: "internal error"},
+		"serverError":    {Code: -32000, Message: "server error"},
+	}
+)
+
+// OpenRPCDocument describes the methods of a JSON-RPC service (see
+// https://spec.open-rpc.org).
+type OpenRPCDocument struct {
+	OpenRPC    string            `json:"openrpc"`
+	Info       OpenRPCInfo       `json:"info"`
+	Methods    []*OpenRPCMethod  `json:"methods"`
+	Components OpenRPCComponents `json:"components"`
+}
+
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenRPCMethod struct {
+	Name           string                      `json:"name"`
+	ParamStructure string                      `json:"paramStructure"`
+	Params         []*OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor   `json:"result"`
+
+	// Tags are the namespace of the method (in documents served by a
+	// [Router]).
+	Tags []*OpenRPCTag `json:"tags,omitempty"`
+}
+
+type OpenRPCTag struct {
+	Name string `json:"name"`
+}
+
+type OpenRPCContentDescriptor struct {
+	Name   string         `json:"name"`
+	Schema *OpenRPCSchema `json:"schema"`
+}
+
+type OpenRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type OpenRPCComponents struct {
+	Schemas map[string]*OpenRPCSchema `json:"schemas"`
+	Errors  map[string]*OpenRPCError  `json:"errors"`
+}
+
+// OpenRPCSchema is the subset of JSON Schema used to describe the params and
+// results of methods. An empty schema accepts any value.
+type OpenRPCSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *OpenRPCSchema            `json:"items,omitempty"`
+	MinItems             int                       `json:"minItems,omitempty"`
+	MaxItems             int                       `json:"maxItems,omitempty"`
+	Properties           map[string]*OpenRPCSchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenRPCSchema            `json:"additionalProperties,omitempty"`
+}
+
+// NewOpenRPCDocument returns the OpenRPC document describing the methods
+// [service] would serve if registered as [name] (see [NewJSONRPCHandler]).
+//
+// Methods are named "<name>.<method>" and take their params by name (the
+// fields of their args). Schemas are derived from the Go types of the args
+// and replies following the rules of encoding/json and can be overridden for
+// a field with an "openrpc" struct tag (`openrpc:"type=string,format=hex"`).
+// Types with a custom JSON encoding are described by an empty schema unless
+// they implement [encoding.TextMarshaler] (which are described as strings) or
+// are overridden.
+func NewOpenRPCDocument(name, version string, service any) (*OpenRPCDocument, error) {
+	g := &openRPCGenerator{schemas: map[string]*OpenRPCSchema{}}
+	doc := &OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info:    OpenRPCInfo{Title: name, Version: version},
+		Methods: []*OpenRPCMethod{},
+		Components: OpenRPCComponents{
+			Schemas: g.schemas,
+			Errors:  openRPCErrors,
+		},
+	}
+	t := reflect.TypeOf(service)
+	for i := 0; i < t.NumMethod(); i++ {
+		method := t.Method(i)
+		args, reply, ok := rpcMethodTypes(method)
+		if !ok {
+			continue
+		}
+		params, err := g.params(args)
+		if err != nil {
+			return nil, fmt.Errorf("%w: method=%s", err, method.Name)
+		}
+		result, err := g.schema(reply)
+		if err != nil {
+			return nil, fmt.Errorf("%w: method=%s", err, method.Name)
+		}
+		doc.Methods = append(doc.Methods, &OpenRPCMethod{
+			Name:           name + "." + lowerFirst(method.Name),
+			ParamStructure: "by-name",
+			Params:         params,
+			Result:         &OpenRPCContentDescriptor{Name: lowerFirst(reply.Name()), Schema: result},
+		})
+	}
+	return doc, nil
+}
+
+// rpcMethodTypes returns the args and reply types of [method] if it can be
+// served by a JSON-RPC service (which requires the signature
+// "func(*http.Request, *Args, *Reply) error").
+func rpcMethodTypes(method reflect.Method) (reflect.Type, reflect.Type, bool) {
+	t := method.Type
+	if !method.IsExported() || t.NumIn() != 4 || t.NumOut() != 1 {
+		return nil, nil, false
+	}
+	if t.In(1) != httpRequestType || t.Out(0) != errorType {
+		return nil, nil, false
+	}
+	args, reply := t.In(2), t.In(3)
+	if args.Kind() != reflect.Pointer || reply.Kind() != reflect.Pointer {
+		return nil, nil, false
+	}
+	return args.Elem(), reply.Elem(), true
+}
+
+type openRPCGenerator struct {
+	schemas map[string]*OpenRPCSchema
+}
+
+// params returns the params of a method that takes [args].
+func (g *openRPCGenerator) params(args reflect.Type) ([]*OpenRPCContentDescriptor, error) {
+	if args.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: found %s", ErrInvalidOpenRPCArgs, args)
+	}
+	return g.fields(args)
+}
+
+// fields returns the fields of [t] as they are encoded by encoding/json.
+func (g *openRPCGenerator) fields(t reflect.Type) ([]*OpenRPCContentDescriptor, error) {
+	fields := []*OpenRPCContentDescriptor{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && len(name) == 0 {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner, err := g.fields(embedded)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, inner...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		schema, err := g.fieldSchema(field)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, &OpenRPCContentDescriptor{Name: name, Schema: schema})
+	}
+	return fields, nil
+}
+
+// fieldSchema returns the schema of [field] (applying the overrides of its
+// "openrpc" tag, if any).
+func (g *openRPCGenerator) fieldSchema(field reflect.StructField) (*OpenRPCSchema, error) {
+	tag, ok := field.Tag.Lookup(openRPCTag)
+	if !ok {
+		return g.schema(field.Type)
+	}
+	schema := &OpenRPCSchema{}
+	for _, option := range strings.Split(tag, ",") {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: field=%s option=%q", ErrInvalidOpenRPCTag, field.Name, option)
+		}
+		switch key {
+		case "type":
+			schema.Type = value
+		case "format":
+			schema.Format = value
+		default:
+			return nil, fmt.Errorf("%w: field=%s option=%q", ErrInvalidOpenRPCTag, field.Name, option)
+		}
+	}
+	return schema, nil
+}
+
+// schema returns the schema of values of type [t]. Named structs are added
+// to [g.schemas] and referenced.
+func (g *openRPCGenerator) schema(t reflect.Type) (*OpenRPCSchema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if schema, ok := wellKnownSchemas[t]; ok {
+		return &schema, nil
+	}
+	switch {
+	case t.Implements(textMarshalerType), reflect.PointerTo(t).Implements(textMarshalerType):
+		return &OpenRPCSchema{Type: "string"}, nil
+	case t.Implements(jsonMarshalerType), reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &OpenRPCSchema{}, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenRPCSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &OpenRPCSchema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &OpenRPCSchema{Type: "number"}, nil
+	case reflect.String:
+		return &OpenRPCSchema{Type: "string"}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenRPCSchema{Type: "string", Format: "base64"}, nil
+		}
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &OpenRPCSchema{Type: "array", Items: items}, nil
+	case reflect.Array:
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &OpenRPCSchema{Type: "array", Items: items, MinItems: t.Len(), MaxItems: t.Len()}, nil
+	case reflect.Map:
+		values, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &OpenRPCSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		// Interfaces (and types that can't be encoded) accept any value
+		return &OpenRPCSchema{}, nil
+	}
+}
+
+// structSchema returns the schema of struct [t]. If [t] is named, its schema
+// is added to [g.schemas] (if it hasn't been already) and a reference to it
+// is returned.
+func (g *openRPCGenerator) structSchema(t reflect.Type) (*OpenRPCSchema, error) {
+	name := schemaName(t)
+	if len(name) > 0 {
+		if _, ok := g.schemas[name]; ok {
+			return &OpenRPCSchema{Ref: openRPCSchemaRef + name}, nil
+		}
+		// Reserve [name] before generating the fields of [t], in case [t] is
+		// recursive.
+		g.schemas[name] = &OpenRPCSchema{}
+	}
+	fields, err := g.fields(t)
+	if err != nil {
+		return nil, err
+	}
+	schema := &OpenRPCSchema{Type: "object", Properties: map[string]*OpenRPCSchema{}}
+	for _, field := range fields {
+		schema.Properties[field.Name] = field.Schema
+	}
+	if len(name) == 0 {
+		return schema, nil
+	}
+	*g.schemas[name] = *schema
+	return &OpenRPCSchema{Ref: openRPCSchemaRef + name}, nil
+}
+
+// schemaName returns the name of the schema of [t] in [OpenRPCComponents]
+// (like "rpc.PingReply") or an empty string if [t] is not named.
+func schemaName(t reflect.Type) string {
+	if len(t.Name()) == 0 {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '_'
+	}, t.String())
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// openRPCService serves [DiscoverMethod].
+type openRPCService struct {
+	doc *OpenRPCDocument
+}
+
+func (s *openRPCService) Discover(_ *http.Request, _ *struct{}, reply *OpenRPCDocument) error {
+	*reply = *s.doc
+	return nil
+}
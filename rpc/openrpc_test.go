@@ -0,0 +1,149 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	json2 "github.com/gorilla/rpc/v2/json2"
+)
+
+var updateOpenRPC = flag.Bool("update", false, "update the OpenRPC documents in testdata")
+
+// TestOpenRPCDocument ensures the committed OpenRPC document of
+// [JSONRPCServer] matches its handlers. If a handler is added or its args or
+// reply change, regenerate the document with:
+//
+//	go test ./rpc -run TestOpenRPCDocument -update
+func TestOpenRPCDocument(t *testing.T) {
+	require := require.New(t)
+
+	doc, err := NewOpenRPCDocument(Name, DefaultOpenRPCDocumentVersion, &JSONRPCServer{})
+	require.NoError(err)
+	b, err := json.MarshalIndent(doc, "", "  ")
+	require.NoError(err)
+	b = append(b, '\n')
+
+	path := filepath.Join("testdata", Name+".openrpc.json")
+	if *updateOpenRPC {
+		require.NoError(os.WriteFile(path, b, 0o600))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(err)
+	require.Equal(string(expected), string(b), "OpenRPC document is stale (run with -update)")
+}
+
+type openRPCTestEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type OpenRPCTestArgs struct {
+	openRPCTestEmbedded
+
+	ID       ids.ID            `json:"id"`
+	Raw      []byte            `json:"raw"`
+	Custom   []byte            `json:"custom" openrpc:"type=string,format=hex"`
+	Values   map[string]uint64 `json:"values"`
+	Fixed    [2]bool           `json:"fixed"`
+	Untagged int
+	Ignored  int `json:"-"`
+}
+
+type OpenRPCTestReply struct {
+	Nested *OpenRPCTestReply `json:"nested"`
+	Any    any               `json:"any"`
+}
+
+type openRPCTestService struct{}
+
+func (*openRPCTestService) Call(*http.Request, *OpenRPCTestArgs, *OpenRPCTestReply) error {
+	return nil
+}
+
+// NotRPC is skipped because it can't be served as a method
+func (*openRPCTestService) NotRPC(*OpenRPCTestArgs) error { return nil }
+
+type OpenRPCTestInvalidArgs struct {
+	Field int `openrpc:"minimum=1"`
+}
+
+type openRPCTestInvalidService struct{}
+
+func (*openRPCTestInvalidService) Call(*http.Request, *OpenRPCTestInvalidArgs, *OpenRPCTestReply) error {
+	return nil
+}
+
+func TestOpenRPCSchemas(t *testing.T) {
+	require := require.New(t)
+
+	doc, err := NewOpenRPCDocument("test", "1.0.0", &openRPCTestService{})
+	require.NoError(err)
+	require.Equal(OpenRPCVersion, doc.OpenRPC)
+	require.Equal(OpenRPCInfo{Title: "test", Version: "1.0.0"}, doc.Info)
+	require.Len(doc.Methods, 1)
+
+	method := doc.Methods[0]
+	require.Equal("test.call", method.Name)
+	require.Equal([]*OpenRPCContentDescriptor{
+		{Name: "embedded", Schema: &OpenRPCSchema{Type: "string"}},
+		{Name: "id", Schema: &OpenRPCSchema{Type: "string", Format: "cb58"}},
+		{Name: "raw", Schema: &OpenRPCSchema{Type: "string", Format: "base64"}},
+		{Name: "custom", Schema: &OpenRPCSchema{Type: "string", Format: "hex"}},
+		{Name: "values", Schema: &OpenRPCSchema{Type: "object", AdditionalProperties: &OpenRPCSchema{Type: "integer"}}},
+		{Name: "fixed", Schema: &OpenRPCSchema{Type: "array", Items: &OpenRPCSchema{Type: "boolean"}, MinItems: 2, MaxItems: 2}},
+		{Name: "Untagged", Schema: &OpenRPCSchema{Type: "integer"}},
+	}, method.Params)
+
+	// Named structs are referenced (even if recursive)
+	ref := &OpenRPCSchema{Ref: openRPCSchemaRef + "rpc.OpenRPCTestReply"}
+	require.Equal(&OpenRPCContentDescriptor{Name: "openRPCTestReply", Schema: ref}, method.Result)
+	require.Equal(map[string]*OpenRPCSchema{
+		"rpc.OpenRPCTestReply": {
+			Type: "object",
+			Properties: map[string]*OpenRPCSchema{
+				"nested": ref,
+				"any":    {},
+			},
+		},
+	}, doc.Components.Schemas)
+
+	// Unknown tag options are rejected
+	_, err = NewOpenRPCDocument("test", "1.0.0", &openRPCTestInvalidService{})
+	require.ErrorIs(err, ErrInvalidOpenRPCTag)
+}
+
+func TestOpenRPCDiscover(t *testing.T) {
+	require := require.New(t)
+
+	handler, err := NewJSONRPCHandler("test", &openRPCTestService{})
+	require.NoError(err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	body, err := json2.EncodeClientRequest(DiscoverMethod, struct{}{})
+	require.NoError(err)
+	req, err := http.NewRequestWithContext(context.TODO(), http.MethodPost, server.URL, bytes.NewReader(body))
+	require.NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(err)
+	defer resp.Body.Close()
+
+	var doc OpenRPCDocument
+	require.NoError(json2.DecodeClientResponse(resp.Body, &doc))
+	require.Equal(DefaultOpenRPCDocumentVersion, doc.Info.Version)
+	require.Len(doc.Methods, 1)
+	require.Equal("test.call", doc.Methods[0].Name)
+}
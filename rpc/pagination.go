@@ -0,0 +1,72 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/hashing"
+)
+
+const (
+	// pageTokenChecksumLen is the number of bytes of the hash of the query
+	// parameters included in a page token.
+	pageTokenChecksumLen = 8
+
+	// MaxPageTokenPositionLen is the max length of the iterator position that
+	// can be encoded in a page token.
+	MaxPageTokenPositionLen = 256
+)
+
+var pageTokenEncoding = base64.RawURLEncoding.Strict()
+
+// PageLimit returns the number of items a list endpoint should return when
+// [limit] is requested. If [limit] is not positive or exceeds [MaxPageSize],
+// [MaxPageSize] is returned.
+func PageLimit(limit int) int {
+	if limit <= 0 || limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
+
+// EncodePageToken returns an opaque token that resumes iteration at
+// [position] for the query with [params].
+//
+// The token includes a checksum of [params], so it can't be used to resume a
+// query with different parameters. List endpoints return an empty token when
+// there are no more items.
+func EncodePageToken(params []byte, position []byte) string {
+	checksum := hashing.ComputeHash256(params)[:pageTokenChecksumLen]
+	return pageTokenEncoding.EncodeToString(append(checksum, position...))
+}
+
+// DecodePageToken returns the position encoded in [token] (as produced by
+// [EncodePageToken]) for the query with [params]. If [token] is empty, nil is
+// returned (iteration should start at the beginning).
+//
+// If [token] is malformed or was produced for a query with different
+// parameters, [ErrInvalidPageToken] is returned.
+func DecodePageToken(token string, params []byte) ([]byte, error) {
+	if len(token) == 0 {
+		return nil, nil
+	}
+	if len(token) > pageTokenEncoding.EncodedLen(pageTokenChecksumLen+MaxPageTokenPositionLen) {
+		return nil, fmt.Errorf("%w: length=%d", ErrInvalidPageToken, len(token))
+	}
+	raw, err := pageTokenEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPageToken, err)
+	}
+	if len(raw) <= pageTokenChecksumLen {
+		return nil, fmt.Errorf("%w: missing position", ErrInvalidPageToken)
+	}
+	checksum := hashing.ComputeHash256(params)[:pageTokenChecksumLen]
+	if !bytes.Equal(raw[:pageTokenChecksumLen], checksum) {
+		return nil, fmt.Errorf("%w: query mismatch", ErrInvalidPageToken)
+	}
+	return raw[pageTokenChecksumLen:], nil
+}
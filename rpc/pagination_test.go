@@ -0,0 +1,77 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageLimit(t *testing.T) {
+	require := require.New(t)
+	require.Equal(MaxPageSize, PageLimit(0))
+	require.Equal(MaxPageSize, PageLimit(-1))
+	require.Equal(MaxPageSize, PageLimit(MaxPageSize+1))
+	require.Equal(10, PageLimit(10))
+}
+
+func TestPageToken(t *testing.T) {
+	require := require.New(t)
+	params := []byte("params")
+	position := []byte{1, 2, 3}
+
+	// Empty token starts at the beginning
+	decoded, err := DecodePageToken("", params)
+	require.NoError(err)
+	require.Nil(decoded)
+
+	token := EncodePageToken(params, position)
+	decoded, err = DecodePageToken(token, params)
+	require.NoError(err)
+	require.Equal(position, decoded)
+
+	// Token can't be used for a query with different parameters
+	_, err = DecodePageToken(token, []byte("other"))
+	require.ErrorIs(err, ErrInvalidPageToken)
+
+	// Token must include a position
+	_, err = DecodePageToken(EncodePageToken(params, nil), params)
+	require.ErrorIs(err, ErrInvalidPageToken)
+
+	// Token must be canonical base64
+	_, err = DecodePageToken(token+"=", params)
+	require.ErrorIs(err, ErrInvalidPageToken)
+
+	// Token can't exceed the max position length
+	_, err = DecodePageToken(EncodePageToken(params, make([]byte, MaxPageTokenPositionLen+1)), params)
+	require.ErrorIs(err, ErrInvalidPageToken)
+}
+
+func FuzzDecodePageToken(f *testing.F) {
+	params := []byte("params")
+	token := EncodePageToken(params, []byte{1, 2, 3})
+	f.Add(token)
+	f.Add(token[1:])
+	f.Add(token[:len(token)-1])
+	f.Add("!" + token)
+	f.Add(EncodePageToken([]byte("other"), []byte{1, 2, 3}))
+	f.Fuzz(func(t *testing.T, token string) {
+		require := require.New(t)
+
+		position, err := DecodePageToken(token, params)
+		if err != nil {
+			require.ErrorIs(err, ErrInvalidPageToken)
+			return
+		}
+		if len(token) == 0 {
+			require.Nil(position)
+			return
+		}
+
+		// Any accepted token must be exactly the token for its position
+		require.NotEmpty(position)
+		require.Equal(token, EncodePageToken(params, position))
+	})
+}
@@ -0,0 +1,302 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/rpc/v2"
+
+	ajson "github.com/ava-labs/avalanchego/utils/json"
+)
+
+const bearerPrefix = "Bearer "
+
+// Namespace is a named group of JSON-RPC services served by a [Router]. The
+// methods of all services are served as "<Name>.<method>", so no two
+// services may have a method with the same name.
+type Namespace struct {
+	Name     string
+	Services []any
+
+	// Admin namespaces must be protected by a [NamespaceConfig.Token] or
+	// [NamespaceConfig.LocalhostOnly] (registration fails otherwise).
+	Admin bool
+
+	Config NamespaceConfig
+}
+
+// NamespaceConfig controls access to the methods of a [Namespace]. The config
+// a namespace is registered with can be replaced in the VM config.
+type NamespaceConfig struct {
+	// Disabled namespaces are not served (or documented), but their methods
+	// still conflict with those of other namespaces.
+	Disabled bool `json:"disabled"`
+
+	// Token, if not empty, must be provided by each request as
+	// "Authorization: Bearer <Token>".
+	Token string `json:"token"`
+	// LocalhostOnly rejects requests that don't originate from a loopback
+	// address.
+	LocalhostOnly bool `json:"localhostOnly"`
+
+	// RateLimit is the number of requests per second served across all
+	// callers (0 is unlimited) with bursts of up to RateBurst.
+	RateLimit float64 `json:"rateLimit"`
+	RateBurst int     `json:"rateBurst"`
+}
+
+type routedNamespace struct {
+	*Namespace
+
+	limiter *rateLimiter // nil if unlimited
+}
+
+// Router serves the methods of multiple [Namespace]s on a single endpoint.
+// Requests are routed to the namespace of their method, which checks its
+// auth requirements and rate limit before serving it.
+//
+// [DiscoverMethod] returns a single OpenRPC document of the methods of all
+// enabled namespaces, where each method is tagged with its namespace.
+type Router struct {
+	overrides map[string]NamespaceConfig
+
+	l          sync.RWMutex
+	namespaces map[string]*routedNamespace
+	methods    map[string]*routedNamespace // by "<namespace>.<method>"
+	servers    map[string]http.Handler     // by "<namespace>.<method>" (if enabled)
+	doc        *OpenRPCDocument
+
+	// fallback serves [DiscoverMethod] and any methods that aren't routed
+	// (returning the same error as a handler that doesn't serve them)
+	fallback *rpc.Server
+}
+
+// NewRouter returns a [Router] that documents its methods as [title]. The
+// config of each namespace registered is replaced by its entry in
+// [overrides] (if any).
+func NewRouter(title string, overrides map[string]NamespaceConfig) (*Router, error) {
+	r := &Router{
+		overrides:  overrides,
+		namespaces: map[string]*routedNamespace{},
+		methods:    map[string]*routedNamespace{},
+		servers:    map[string]http.Handler{},
+		doc: &OpenRPCDocument{
+			OpenRPC: OpenRPCVersion,
+			Info:    OpenRPCInfo{Title: title, Version: DefaultOpenRPCDocumentVersion},
+			Methods: []*OpenRPCMethod{},
+			Components: OpenRPCComponents{
+				Schemas: map[string]*OpenRPCSchema{},
+				Errors:  openRPCErrors,
+			},
+		},
+		fallback: newJSONRPCServer(),
+	}
+	return r, r.fallback.RegisterService(&openRPCService{r.doc}, discoverService)
+}
+
+// Register serves the methods of [ns]. It fails if [ns] is not uniquely
+// named, is an unprotected admin namespace, or has a method that is
+// already registered.
+func (r *Router) Register(ns *Namespace) error {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if len(ns.Name) == 0 || ns.Name == discoverService || strings.Contains(ns.Name, ".") {
+		return fmt.Errorf("%w: %q", ErrInvalidNamespace, ns.Name)
+	}
+	if _, ok := r.namespaces[ns.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateNamespace, ns.Name)
+	}
+	if config, ok := r.overrides[ns.Name]; ok {
+		overridden := *ns
+		overridden.Config = config
+		ns = &overridden
+	}
+	if ns.Admin && len(ns.Config.Token) == 0 && !ns.Config.LocalhostOnly {
+		return fmt.Errorf("%w: %s", ErrUnprotectedNamespace, ns.Name)
+	}
+
+	// Resolve the methods of all services before modifying the router, so
+	// that a conflict doesn't leave [ns] partially registered
+	var (
+		routed  = &routedNamespace{Namespace: ns}
+		methods = map[string]http.Handler{}
+		docs    = make([]*OpenRPCDocument, 0, len(ns.Services))
+	)
+	for _, service := range ns.Services {
+		doc, err := NewOpenRPCDocument(ns.Name, DefaultOpenRPCDocumentVersion, service)
+		if err != nil {
+			return fmt.Errorf("%w: namespace=%s", err, ns.Name)
+		}
+		server := newJSONRPCServer()
+		if err := server.RegisterService(service, ns.Name); err != nil {
+			return fmt.Errorf("%w: namespace=%s", err, ns.Name)
+		}
+		for _, method := range doc.Methods {
+			if _, ok := methods[method.Name]; ok {
+				return fmt.Errorf("%w: method=%s namespace=%s", ErrMethodConflict, method.Name, ns.Name)
+			}
+			if existing, ok := r.methods[method.Name]; ok {
+				return fmt.Errorf("%w: method=%s namespace=%s registered by=%s", ErrMethodConflict, method.Name, ns.Name, existing.Name)
+			}
+			methods[method.Name] = server
+		}
+		docs = append(docs, doc)
+	}
+	if ns.Config.RateLimit > 0 {
+		routed.limiter = newRateLimiter(ns.Config.RateLimit, ns.Config.RateBurst)
+	}
+	r.namespaces[ns.Name] = routed
+	for method, server := range methods {
+		r.methods[method] = routed
+		if !ns.Config.Disabled {
+			r.servers[method] = server
+		}
+	}
+	if ns.Config.Disabled {
+		return nil
+	}
+
+	// Document the methods of [ns] (segmented by namespace)
+	tags := []*OpenRPCTag{{Name: ns.Name}}
+	for _, doc := range docs {
+		for _, method := range doc.Methods {
+			method.Tags = tags
+			r.doc.Methods = append(r.doc.Methods, method)
+		}
+		for name, schema := range doc.Components.Schemas {
+			r.doc.Components.Schemas[name] = schema
+		}
+	}
+	slices.SortStableFunc(r.doc.Methods, func(a, b *OpenRPCMethod) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return nil
+}
+
+// Namespaces returns the names of the registered namespaces (including those
+// that are disabled).
+func (r *Router) Namespaces() []string {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	names := make([]string, 0, len(r.namespaces))
+	for name := range r.namespaces {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Document returns the OpenRPC document served by [DiscoverMethod].
+func (r *Router) Document() *OpenRPCDocument {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	doc := *r.doc
+	return &doc
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Requests that can't be parsed are rejected by [fallback]
+	var request struct {
+		Method string `json:"method"`
+	}
+	_ = json.Unmarshal(body, &request)
+
+	r.l.RLock()
+	ns := r.methods[request.Method]
+	server, ok := r.servers[request.Method]
+	r.l.RUnlock()
+	if !ok {
+		r.fallback.ServeHTTP(w, req)
+		return
+	}
+	if status, err := ns.authorize(req); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if ns.limiter != nil && !ns.limiter.allow(time.Now()) {
+		http.Error(w, ErrRateLimited.Error(), http.StatusTooManyRequests)
+		return
+	}
+	server.ServeHTTP(w, req)
+}
+
+// authorize returns an error (and the status to respond with) if [req] does
+// not meet the auth requirements of [ns].
+func (ns *routedNamespace) authorize(req *http.Request) (int, error) {
+	if ns.Config.LocalhostOnly {
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return http.StatusForbidden, fmt.Errorf("%w: namespace=%s", ErrNotLocalhost, ns.Name)
+		}
+	}
+	if len(ns.Config.Token) > 0 {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), bearerPrefix)
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(ns.Config.Token)) != 1 {
+			return http.StatusUnauthorized, fmt.Errorf("%w: namespace=%s", ErrInvalidToken, ns.Name)
+		}
+	}
+	return 0, nil
+}
+
+func newJSONRPCServer() *rpc.Server {
+	server := rpc.NewServer()
+	server.RegisterCodec(ajson.NewCodec(), "application/json")
+	server.RegisterCodec(ajson.NewCodec(), "application/json;charset=UTF-8")
+	return server
+}
+
+// rateLimiter is a token bucket that allows [limit] requests per second with
+// bursts of up to [burst].
+type rateLimiter struct {
+	limit float64
+	burst float64
+
+	l      sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limit float64, burst int) *rateLimiter {
+	b := float64(max(burst, 1))
+	return &rateLimiter{limit: limit, burst: b, tokens: b}
+}
+
+func (r *rateLimiter) allow(now time.Time) bool {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if !r.last.IsZero() {
+		r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.limit)
+	}
+	r.last = now
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
@@ -0,0 +1,223 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/requester"
+
+	json2 "github.com/gorilla/rpc/v2/json2"
+)
+
+type RouterTestArgs struct {
+	Value string `json:"value"`
+}
+
+type RouterTestReply struct {
+	Value string `json:"value"`
+}
+
+type routerTestQueries struct{}
+
+func (*routerTestQueries) Echo(_ *http.Request, args *RouterTestArgs, reply *RouterTestReply) error {
+	reply.Value = args.Value
+	return nil
+}
+
+type routerTestAdmin struct{}
+
+func (*routerTestAdmin) Reset(_ *http.Request, _ *struct{}, reply *RouterTestReply) error {
+	reply.Value = "reset"
+	return nil
+}
+
+// routerTestEcho conflicts with [routerTestQueries] if registered in the same
+// namespace.
+type routerTestEcho struct{}
+
+func (*routerTestEcho) Echo(*http.Request, *RouterTestArgs, *RouterTestReply) error {
+	return nil
+}
+
+func newRouterTestServer(t *testing.T, router *Router) *JSONRPCClient {
+	mux := http.NewServeMux()
+	mux.Handle(JSONRPCEndpoint, router)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewJSONRPCClient(server.URL)
+}
+
+func TestRouterNamespaces(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	router, err := NewRouter("test", nil)
+	require.NoError(err)
+	require.NoError(router.Register(&Namespace{Name: "queries", Services: []any{&routerTestQueries{}}}))
+	require.NoError(router.Register(&Namespace{
+		Name:     "admin",
+		Services: []any{&routerTestAdmin{}, &routerTestEcho{}},
+		Admin:    true,
+		Config:   NamespaceConfig{Token: "secret"},
+	}))
+	require.Equal([]string{"admin", "queries"}, router.Namespaces())
+	cli := newRouterTestServer(t, router)
+
+	// Each namespace is served by its own sub-client
+	reply := new(RouterTestReply)
+	require.NoError(cli.Namespace("queries").Call(ctx, "echo", &RouterTestArgs{Value: "hello"}, reply))
+	require.Equal("hello", reply.Value)
+	require.NoError(cli.Namespace("admin", WithToken("secret")).Call(ctx, "reset", nil, reply))
+	require.Equal("reset", reply.Value)
+
+	// The admin namespace requires its token
+	var statusErr *requester.StatusError
+	err = cli.Namespace("admin").Call(ctx, "reset", nil, reply)
+	require.ErrorAs(err, &statusErr)
+	require.Equal(http.StatusUnauthorized, statusErr.StatusCode)
+	err = cli.Namespace("admin", WithToken("wrong")).Call(ctx, "reset", nil, reply)
+	require.ErrorAs(err, &statusErr)
+	require.Equal(http.StatusUnauthorized, statusErr.StatusCode)
+
+	// Methods are not served by other namespaces
+	var rpcErr *json2.Error
+	err = cli.Namespace("queries").Call(ctx, "reset", nil, reply)
+	require.True(errors.As(err, &rpcErr))
+
+	// The document is segmented by namespace
+	doc := router.Document()
+	names := []string{}
+	for _, method := range doc.Methods {
+		require.Len(method.Tags, 1)
+		names = append(names, method.Tags[0].Name+":"+method.Name)
+	}
+	require.Equal([]string{"admin:admin.echo", "admin:admin.reset", "queries:queries.echo"}, names)
+	require.Contains(doc.Components.Schemas, "rpc.RouterTestReply")
+}
+
+func TestRouterRegisterErrors(t *testing.T) {
+	require := require.New(t)
+
+	router, err := NewRouter("test", map[string]NamespaceConfig{"admin": {}})
+	require.NoError(err)
+	require.NoError(router.Register(&Namespace{Name: "queries", Services: []any{&routerTestQueries{}}}))
+
+	tests := []struct {
+		name string
+		ns   *Namespace
+		err  error
+	}{
+		{
+			name: "reserved",
+			ns:   &Namespace{Name: discoverService, Services: []any{&routerTestAdmin{}}},
+			err:  ErrInvalidNamespace,
+		},
+		{
+			name: "duplicate",
+			ns:   &Namespace{Name: "queries", Services: []any{&routerTestAdmin{}}},
+			err:  ErrDuplicateNamespace,
+		},
+		{
+			name: "conflicting services",
+			ns:   &Namespace{Name: "other", Services: []any{&routerTestQueries{}, &routerTestEcho{}}},
+			err:  ErrMethodConflict,
+		},
+		{
+			// The override removes the token the namespace was registered
+			// with
+			name: "unprotected admin",
+			ns: &Namespace{
+				Name:     "admin",
+				Services: []any{&routerTestAdmin{}},
+				Admin:    true,
+				Config:   NamespaceConfig{Token: "secret"},
+			},
+			err: ErrUnprotectedNamespace,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(router.Register(tt.ns), tt.err)
+		})
+	}
+
+	// A failed registration doesn't leave the namespace partially registered
+	require.Equal([]string{"queries"}, router.Namespaces())
+	require.NoError(router.Register(&Namespace{Name: "other", Services: []any{&routerTestQueries{}}}))
+}
+
+func TestRouterConfig(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	router, err := NewRouter("test", map[string]NamespaceConfig{
+		"disabled": {Disabled: true},
+		"limited":  {RateLimit: 0.001, RateBurst: 2},
+	})
+	require.NoError(err)
+	require.NoError(router.Register(&Namespace{Name: "disabled", Services: []any{&routerTestQueries{}}}))
+	require.NoError(router.Register(&Namespace{Name: "limited", Services: []any{&routerTestQueries{}}}))
+	require.NoError(router.Register(&Namespace{
+		Name:     "local",
+		Services: []any{&routerTestAdmin{}},
+		Admin:    true,
+		Config:   NamespaceConfig{LocalhostOnly: true},
+	}))
+	cli := newRouterTestServer(t, router)
+
+	// Disabled namespaces are neither served nor documented
+	var rpcErr *json2.Error
+	err = cli.Namespace("disabled").Call(ctx, "echo", &RouterTestArgs{}, new(RouterTestReply))
+	require.True(errors.As(err, &rpcErr))
+	for _, method := range router.Document().Methods {
+		require.NotEqual("disabled.echo", method.Name)
+	}
+
+	// Requests exceeding the rate limit are rejected
+	limited := cli.Namespace("limited")
+	for i := 0; i < 2; i++ {
+		require.NoError(limited.Call(ctx, "echo", &RouterTestArgs{}, new(RouterTestReply)))
+	}
+	var statusErr *requester.StatusError
+	err = limited.Call(ctx, "echo", &RouterTestArgs{}, new(RouterTestReply))
+	require.ErrorAs(err, &statusErr)
+	require.Equal(http.StatusTooManyRequests, statusErr.StatusCode)
+
+	// Localhost-only namespaces reject remote callers
+	require.NoError(cli.Namespace("local").Call(ctx, "reset", nil, new(RouterTestReply)))
+	body, err := json2.EncodeClientRequest("local.reset", struct{}{})
+	require.NoError(err)
+	req := httptest.NewRequest(http.MethodPost, JSONRPCEndpoint, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:9650"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(http.StatusForbidden, w.Code)
+}
+
+func TestRateLimiter(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now()
+	r := newRateLimiter(2, 2)
+	require.True(r.allow(now))
+	require.True(r.allow(now))
+	require.False(r.allow(now))
+
+	// Tokens are refilled at [limit] per second (up to [burst])
+	require.True(r.allow(now.Add(500 * time.Millisecond)))
+	require.False(r.allow(now.Add(500 * time.Millisecond)))
+	require.True(r.allow(now.Add(10 * time.Second)))
+	require.True(r.allow(now.Add(10 * time.Second)))
+	require.False(r.allow(now.Add(10 * time.Second)))
+}
@@ -3,19 +3,22 @@
 
 package rpc
 
-import (
-	"net/http"
-
-	"github.com/ava-labs/avalanchego/utils/json"
-	"github.com/gorilla/rpc/v2"
-)
+import "net/http"
 
+// NewJSONRPCHandler returns a handler that serves the methods of [service]
+// as "<name>.<method>" and [DiscoverMethod], which returns the OpenRPC
+// document describing them (see [NewOpenRPCDocument]).
 func NewJSONRPCHandler(
 	name string,
 	service interface{},
 ) (http.Handler, error) {
-	server := rpc.NewServer()
-	server.RegisterCodec(json.NewCodec(), "application/json")
-	server.RegisterCodec(json.NewCodec(), "application/json;charset=UTF-8")
+	doc, err := NewOpenRPCDocument(name, DefaultOpenRPCDocumentVersion, service)
+	if err != nil {
+		return nil, err
+	}
+	server := newJSONRPCServer()
+	if err := server.RegisterService(&openRPCService{doc}, discoverService); err != nil {
+		return nil, err
+	}
 	return server, server.RegisterService(service, name)
 }
@@ -5,6 +5,8 @@ package rpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"sync"
@@ -17,19 +19,47 @@ import (
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/pubsub"
+	"github.com/ava-labs/hypersdk/requester"
 	"github.com/ava-labs/hypersdk/utils"
 )
 
 type WebSocketClient struct {
-	cl   sync.Once
-	conn *websocket.Conn
+	uri    string
+	dialer *websocket.Dialer
+	policy requester.RetryPolicy
 
-	mb           *pubsub.MessageBuffer
-	writeStopped chan struct{}
-	readStopped  chan struct{}
+	pending int
+	maxSize int
 
-	pendingBlocks chan []byte
-	pendingTxs    chan []byte
+	cl sync.Once
+
+	// [l] protects the connection (which is replaced on reconnect) and
+	// the subscriptions that must be re-established on it.
+	l                 sync.Mutex
+	conn              *websocket.Conn
+	mb                *pubsub.MessageBuffer
+	writeStopped      chan struct{}
+	registeredBlocks  bool
+	registeredFrom    uint64
+	registeredTxs     map[ids.ID][]byte
+	registeredBundles map[ids.ID][]byte
+
+	// [readStopped] is closed once the client stops receiving messages
+	// (after it is closed or fails to reconnect).
+	readStopped chan struct{}
+
+	pendingBlocks  chan []byte
+	pendingTxs     chan []byte
+	pendingBundles chan []byte
+
+	// [lastSeq] (reset on reconnect) and [receivedHeight] are only accessed
+	// by [run] (and [connect]).
+	lastSeq        uint64
+	receivedHeight uint64
+
+	// [lastHeight] and [gapBlock] are only accessed by [ListenBlock].
+	lastHeight uint64
+	gapBlock   []byte
 
 	startedClose bool
 	closed       bool
@@ -40,6 +70,25 @@ type WebSocketClient struct {
 // NewWebSocketClient creates a new client for the decision rpc server.
 // Dials into the server at [uri] and returns a client.
 func NewWebSocketClient(uri string, handshakeTimeout time.Duration, pending int, maxSize int) (*WebSocketClient, error) {
+	return NewReconnectingWebSocketClient(uri, handshakeTimeout, pending, maxSize, requester.NoRetries)
+}
+
+// NewReconnectingWebSocketClient creates a new client for the decision rpc
+// server that reconnects (according to [policy]) if the connection to the
+// server at [uri] is lost.
+//
+// After reconnecting, block subscriptions and transactions (and bundles)
+// without a final status are re-registered. Blocks accepted while disconnected are replayed
+// by the server if they are still in its backlog (see [BlockBacklogSize]),
+// otherwise [ListenBlock] returns [ErrBlockGap] with the missing heights
+// before the first block received after a gap.
+func NewReconnectingWebSocketClient(
+	uri string,
+	handshakeTimeout time.Duration,
+	pending int,
+	maxSize int,
+	policy requester.RetryPolicy,
+) (*WebSocketClient, error) {
 	uri = strings.ReplaceAll(uri, "http://", "ws://")
 	uri = strings.ReplaceAll(uri, "https://", "wss://")
 	if !strings.HasPrefix(uri, "ws") { // fallback to default usage
@@ -52,106 +101,276 @@ func NewWebSocketClient(uri string, handshakeTimeout time.Duration, pending int,
 		Proxy:            http.ProxyFromEnvironment,
 		HandshakeTimeout: handshakeTimeout,
 	}
-	conn, resp, err := dialer.Dial(uri, nil)
-	if err != nil {
+	wc := &WebSocketClient{
+		uri:               uri,
+		dialer:            dialer,
+		policy:            policy,
+		pending:           pending,
+		maxSize:           maxSize,
+		registeredTxs:     map[ids.ID][]byte{},
+		registeredBundles: map[ids.ID][]byte{},
+		readStopped:       make(chan struct{}),
+		pendingBlocks:     make(chan []byte, pending),
+		pendingTxs:        make(chan []byte, pending),
+		pendingBundles:    make(chan []byte, pending),
+	}
+	if err := wc.connect(); err != nil {
 		return nil, err
 	}
+	go wc.run()
+	return wc, nil
+}
+
+// connect dials the server and starts writing messages to the new connection.
+func (c *WebSocketClient) connect() error {
+	conn, resp, err := c.dialer.Dial(c.uri, nil)
+	if err != nil {
+		return err
+	}
 	resp.Body.Close()
-	wc := &WebSocketClient{
-		conn:          conn,
-		mb:            pubsub.NewMessageBuffer(&logging.NoLog{}, pending, maxSize, pubsub.MaxMessageWait),
-		readStopped:   make(chan struct{}),
-		writeStopped:  make(chan struct{}),
-		pendingBlocks: make(chan []byte, pending),
-		pendingTxs:    make(chan []byte, pending),
-	}
-	go func() {
-		defer close(wc.readStopped)
-		for {
-			_, msgBatch, err := conn.ReadMessage()
-			if err != nil {
-				wc.errl.Do(func() {
-					wc.err = err
-				})
-				return
-			}
-			if len(msgBatch) == 0 {
-				utils.Outf("{{orange}}got empty message{{/}}\n")
-				continue
-			}
-			msgs, err := pubsub.ParseBatchMessage(pubsub.MaxWriteMessageSize, msgBatch)
-			if err != nil {
-				utils.Outf("{{orange}}received invalid message:{{/}} %v\n", err)
-				continue
-			}
-			for _, msg := range msgs {
-				tmsg := msg[1:]
-				switch msg[0] {
-				case BlockMode:
-					wc.pendingBlocks <- tmsg
-				case TxMode:
-					wc.pendingTxs <- tmsg
-				default:
-					utils.Outf("{{orange}}unexpected message mode:{{/}} %x\n", msg[0])
-					continue
-				}
-			}
+
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.startedClose {
+		_ = conn.Close()
+		return ErrClosed
+	}
+	c.conn = conn
+	c.mb = pubsub.NewMessageBuffer(&logging.NoLog{}, c.pending, c.maxSize, pubsub.MaxMessageWait)
+	c.writeStopped = make(chan struct{})
+	c.lastSeq = 0
+	if c.registeredBlocks {
+		// Resume after the last block received (which is delivered at most
+		// once, even if it is in the backlog of the server)
+		from := c.registeredFrom
+		if c.receivedHeight > 0 {
+			from = c.receivedHeight + 1
 		}
+		if err := c.mb.Send(PackBlockSubscription(from)); err != nil {
+			return err
+		}
+	}
+	for _, tx := range c.registeredTxs {
+		if err := c.mb.Send(append([]byte{TxMode}, tx...)); err != nil {
+			return err
+		}
+	}
+	for _, bundle := range c.registeredBundles {
+		if err := c.mb.Send(bundle); err != nil {
+			return err
+		}
+	}
+	go c.write(conn, c.mb, c.writeStopped)
+	return nil
+}
+
+// run reads messages from the server (reconnecting if allowed by the
+// [requester.RetryPolicy] of [c]) until [c] is closed.
+func (c *WebSocketClient) run() {
+	defer func() {
+		if !c.startedClose {
+			utils.Outf("{{orange}}unclean client shutdown:{{/}} %v\n", c.err)
+		}
+		c.closed = true
 	}()
-	go func() {
-		defer close(wc.writeStopped)
-		for {
-			select {
-			case msg, ok := <-wc.mb.Queue:
-				if !ok {
-					return
-				}
-				if err := wc.conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
-					wc.errl.Do(func() {
-						wc.err = err
+	defer close(c.readStopped)
+
+	for {
+		c.l.Lock()
+		conn, mb, writeStopped := c.conn, c.mb, c.writeStopped
+		c.l.Unlock()
+
+		err := c.read(conn)
+
+		// Stop writing to the failed connection
+		_ = mb.Close()
+		<-writeStopped
+		if c.startedClose || errors.Is(err, ErrOutOfOrder) || !c.reconnect() {
+			c.errl.Do(func() {
+				c.err = err
+			})
+			return
+		}
+	}
+}
+
+// reconnect attempts to re-establish the connection to the server.
+func (c *WebSocketClient) reconnect() bool {
+	for attempt := 1; attempt < c.policy.MaxAttempts; attempt++ {
+		time.Sleep(c.policy.Backoff(attempt))
+		if c.startedClose {
+			return false
+		}
+		if err := c.connect(); err != nil {
+			utils.Outf("{{orange}}failed to reconnect:{{/}} %v\n", err)
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (c *WebSocketClient) read(conn *websocket.Conn) error {
+	for {
+		_, msgBatch, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if len(msgBatch) == 0 {
+			utils.Outf("{{orange}}got empty message{{/}}\n")
+			continue
+		}
+		msgs, err := pubsub.ParseBatchMessage(pubsub.MaxWriteMessageSize, msgBatch)
+		if err != nil {
+			utils.Outf("{{orange}}received invalid message:{{/}} %v\n", err)
+			continue
+		}
+		for _, msg := range msgs {
+			tmsg := msg[1:]
+			switch msg[0] {
+			case BlockMode:
+				blkMsg, err := c.trackBlock(tmsg)
+				if err != nil {
+					// Record [err] before closing the connection (which fails
+					// any pending write) so it is the error returned by
+					// [ListenBlock]
+					c.errl.Do(func() {
+						c.err = err
 					})
-					_ = wc.conn.Close()
-					return
+					_ = conn.Close()
+					return err
 				}
-			case <-wc.readStopped:
-				// If we exit here, the connection must've failed ungracefully
-				// otherwise writeStopped will exit first.
-				_ = wc.mb.Close()
-				return
+				c.pendingBlocks <- blkMsg
+			case TxMode:
+				c.trackTxStatus(tmsg)
+				c.pendingTxs <- tmsg
+			case BundleMode:
+				c.trackBundleStatus(tmsg)
+				c.pendingBundles <- tmsg
+			default:
+				utils.Outf("{{orange}}unexpected message mode:{{/}} %x\n", msg[0])
+				continue
 			}
 		}
-	}()
-	go func() {
-		<-wc.writeStopped
-		<-wc.readStopped
-		if !wc.startedClose {
-			utils.Outf("{{orange}}unclean client shutdown:{{/}} %v\n", wc.err)
+	}
+}
+
+func (c *WebSocketClient) write(conn *websocket.Conn, mb *pubsub.MessageBuffer, writeStopped chan struct{}) {
+	defer close(writeStopped)
+	for msg := range mb.Queue {
+		if err := conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+			c.errl.Do(func() {
+				c.err = err
+			})
+			_ = conn.Close()
+
+			// Drain the queue so that [mb] can be closed
+			for range mb.Queue { //nolint:revive
+			}
+			return
 		}
-		wc.closed = true
-	}()
-	return wc, nil
+	}
+}
+
+// trackBlock returns the block message in [msg] after ensuring it has a
+// greater sequence number and height than any block message previously
+// received (on the current connection for sequence numbers).
+func (c *WebSocketClient) trackBlock(msg []byte) ([]byte, error) {
+	seq, height, blkMsg, err := UnpackSequencedBlockMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	if seq <= c.lastSeq || height <= c.receivedHeight {
+		return nil, fmt.Errorf(
+			"%w: seq=%d height=%d received after seq=%d height=%d",
+			ErrOutOfOrder, seq, height, c.lastSeq, c.receivedHeight,
+		)
+	}
+	c.lastSeq = seq
+	c.receivedHeight = height
+	return blkMsg, nil
+}
+
+// trackTxStatus stops re-registering a transaction on reconnect once it has
+// a final status.
+func (c *WebSocketClient) trackTxStatus(msg []byte) {
+	txID, status, _, _, err := UnpackTxStatusMessage(msg)
+	if err != nil || status == TxIncluded || status == TxPending {
+		return
+	}
+	c.l.Lock()
+	delete(c.registeredTxs, txID)
+	c.l.Unlock()
+}
+
+// trackBundleStatus stops re-registering a bundle on reconnect once it has a
+// final status.
+func (c *WebSocketClient) trackBundleStatus(msg []byte) {
+	bundleID, status, _, _, err := UnpackBundleStatusMessage(msg)
+	if err != nil || status == TxIncluded {
+		return
+	}
+	c.l.Lock()
+	delete(c.registeredBundles, bundleID)
+	c.l.Unlock()
 }
 
 func (c *WebSocketClient) RegisterBlocks() error {
+	return c.RegisterBlocksFrom(0)
+}
+
+// RegisterBlocksFrom subscribes to accepted blocks, starting with the blocks
+// at or above height [from] that are still in the backlog of the server (if
+// [from] is non-zero).
+//
+// Each block is delivered at most once and in order of height. If the
+// server delivers a block out of order, the client is closed and
+// [ListenBlock] returns [ErrOutOfOrder].
+func (c *WebSocketClient) RegisterBlocksFrom(from uint64) error {
 	if c.closed {
 		return ErrClosed
 	}
-	return c.mb.Send([]byte{BlockMode})
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.registeredBlocks = true
+	c.registeredFrom = from
+	return c.mb.Send(PackBlockSubscription(from))
 }
 
 // Listen listens for block messages from the streaming server.
+//
+// The returned results correspond to the returned transactions (the
+// transactions executed by the block).
 func (c *WebSocketClient) ListenBlock(
 	ctx context.Context,
 	parser chain.Parser,
-) (*chain.StatefulBlock, []*chain.Result, fees.Dimensions, error) {
-	select {
-	case msg := <-c.pendingBlocks:
-		return UnpackBlockMessage(msg, parser)
-	case <-c.readStopped:
-		return nil, nil, fees.Dimensions{}, c.err
-	case <-ctx.Done():
-		return nil, nil, fees.Dimensions{}, ctx.Err()
+) (*chain.StatefulBlock, []*chain.Transaction, []*chain.Result, fees.Dimensions, error) {
+	msg := c.gapBlock
+	c.gapBlock = nil
+	if msg == nil {
+		select {
+		case msg = <-c.pendingBlocks:
+		case <-c.readStopped:
+			return nil, nil, nil, fees.Dimensions{}, c.err
+		case <-ctx.Done():
+			return nil, nil, nil, fees.Dimensions{}, ctx.Err()
+		}
+	}
+	blk, txs, results, prices, err := UnpackBlockMessage(msg, parser)
+	if err != nil {
+		return nil, nil, nil, fees.Dimensions{}, err
 	}
+	if c.lastHeight > 0 && blk.Hght > c.lastHeight+1 {
+		// Return the gap before the block (which is returned on the next call)
+		from, to := c.lastHeight+1, blk.Hght-1
+		c.lastHeight = blk.Hght - 1
+		c.gapBlock = msg
+		return nil, nil, nil, fees.Dimensions{}, fmt.Errorf("%w: heights [%d, %d]", ErrBlockGap, from, to)
+	}
+	c.lastHeight = blk.Hght
+	return blk, txs, results, prices, nil
 }
 
 // IssueTx sends [tx] to the streaming rpc server.
@@ -159,6 +378,12 @@ func (c *WebSocketClient) RegisterTx(tx *chain.Transaction) error {
 	if c.closed {
 		return ErrClosed
 	}
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.policy.MaxAttempts > 1 {
+		c.registeredTxs[tx.ID()] = tx.Bytes()
+	}
 	return c.mb.Send(append([]byte{TxMode}, tx.Bytes()...))
 }
 
@@ -167,14 +392,64 @@ func (c *WebSocketClient) RegisterTx(tx *chain.Transaction) error {
 // TODO: add the option to subscribe to a single TxID to avoid
 // trampling other listeners (could have an intermediate tracking
 // layer in the client so no changes required in the server).
+//
+// ListenTx skips [TxIncluded] and [TxPending] messages (use [ListenTxStatus]
+// to receive them).
 func (c *WebSocketClient) ListenTx(ctx context.Context) (ids.ID, error, *chain.Result, error) {
+	for {
+		txID, status, dErr, result, err := c.ListenTxStatus(ctx)
+		if err == nil && (status == TxIncluded || status == TxPending) {
+			continue
+		}
+		return txID, dErr, result, err
+	}
+}
+
+// ListenTxStatus listens for responses from the streamingServer, including
+// [TxIncluded] messages sent when [chain.Rules.GetDelayedExecution] is enabled
+// and [TxPending] messages sent while auth verification is deferred.
+func (c *WebSocketClient) ListenTxStatus(ctx context.Context) (ids.ID, byte, error, *chain.Result, error) {
 	select {
 	case msg := <-c.pendingTxs:
-		return UnpackTxMessage(msg)
+		return UnpackTxStatusMessage(msg)
+	case <-c.readStopped:
+		return ids.Empty, 0, nil, nil, c.err
+	case <-ctx.Done():
+		return ids.Empty, 0, nil, nil, ctx.Err()
+	}
+}
+
+// RegisterBundle sends [bundle] to the streaming rpc server (its status is
+// returned by [ListenBundle]).
+func (c *WebSocketClient) RegisterBundle(bundle *chain.Bundle) error {
+	if c.closed {
+		return ErrClosed
+	}
+	msg, err := PackBundleSubmission(bundle)
+	if err != nil {
+		return err
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.policy.MaxAttempts > 1 {
+		c.registeredBundles[bundle.ID()] = msg
+	}
+	return c.mb.Send(msg)
+}
+
+// ListenBundle listens for bundle responses from the streamingServer. Returns
+// the bundleID, its status ([TxExecuted], [TxRemoved], or [TxIncluded]), an
+// error regarding the status (if removed), and the results of its
+// transactions (if executed).
+func (c *WebSocketClient) ListenBundle(ctx context.Context) (ids.ID, byte, error, []*chain.Result, error) {
+	select {
+	case msg := <-c.pendingBundles:
+		return UnpackBundleStatusMessage(msg)
 	case <-c.readStopped:
-		return ids.Empty, nil, nil, c.err
+		return ids.Empty, 0, nil, nil, c.err
 	case <-ctx.Done():
-		return ids.Empty, nil, nil, ctx.Err()
+		return ids.Empty, 0, nil, nil, ctx.Err()
 	}
 }
 
@@ -182,14 +457,17 @@ func (c *WebSocketClient) ListenTx(ctx context.Context) (ids.ID, error, *chain.R
 func (c *WebSocketClient) Close() error {
 	var err error
 	c.cl.Do(func() {
+		c.l.Lock()
 		c.startedClose = true
+		conn, mb, writeStopped := c.conn, c.mb, c.writeStopped
+		c.l.Unlock()
 
 		// Flush all unwritten messages before we close the connection
-		_ = c.mb.Close()
-		<-c.writeStopped
+		_ = mb.Close()
+		<-writeStopped
 
 		// Close connection and stop reading
-		err = c.conn.Close()
+		err = conn.Close()
 	})
 	return err
 }
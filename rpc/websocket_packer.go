@@ -15,60 +15,162 @@ import (
 )
 
 const (
-	BlockMode byte = 0
-	TxMode    byte = 1
+	BlockMode  byte = 0
+	TxMode     byte = 1
+	BundleMode byte = 2
 )
 
+// Status of a transaction in a tx message. If [chain.Rules.GetDelayedExecution]
+// is enabled, a transaction is first included in a block (and only executed by
+// its child), otherwise it is executed by the block that includes it.
+//
+// If the VM defers auth verification, a transaction is pending until its auth
+// is verified (it is then added to the mempool or removed).
+const (
+	TxExecuted byte = 0
+	TxRemoved  byte = 1
+	TxIncluded byte = 2
+	TxPending  byte = 3
+)
+
+// PackBlockSubscription packs a block subscription. If [from] is non-zero,
+// accepted blocks starting at height [from] that are still in the backlog of
+// the server (see [BlockBacklogSize]) are sent before new blocks.
+func PackBlockSubscription(from uint64) []byte {
+	if from == 0 {
+		return []byte{BlockMode}
+	}
+	p := codec.NewWriter(consts.ByteLen+consts.Uint64Len, consts.MaxInt)
+	p.PackByte(BlockMode)
+	p.PackUint64(from)
+	return p.Bytes()
+}
+
+// UnpackBlockSubscription returns the height to replay blocks from of a block
+// subscription (0 if only new blocks should be sent).
+func UnpackBlockSubscription(msg []byte) (uint64, error) {
+	if len(msg) == 0 {
+		return 0, nil
+	}
+	p := codec.NewReader(msg, consts.Uint64Len)
+	from := p.UnpackUint64(true)
+	if !p.Empty() {
+		return 0, chain.ErrInvalidObject
+	}
+	return from, p.Err()
+}
+
+// PackSequencedBlockMessage prefixes the block message [msg] (of the block at
+// [height]) with its sequence number on a connection. Sequence numbers start
+// at 1 and increase by 1 with every block message delivered to a connection.
+func PackSequencedBlockMessage(seq uint64, height uint64, msg []byte) []byte {
+	p := codec.NewWriter(consts.ByteLen+2*consts.Uint64Len+len(msg), consts.MaxInt)
+	p.PackByte(BlockMode)
+	p.PackUint64(seq)
+	p.PackUint64(height)
+	p.PackFixedBytes(msg)
+	return p.Bytes()
+}
+
+// UnpackSequencedBlockMessage returns the sequence number, height, and block
+// message of a message packed with [PackSequencedBlockMessage] (without its
+// mode).
+func UnpackSequencedBlockMessage(msg []byte) (uint64, uint64, []byte, error) {
+	if len(msg) < 2*consts.Uint64Len {
+		return 0, 0, nil, chain.ErrInvalidObject
+	}
+	p := codec.NewReader(msg[:2*consts.Uint64Len], 2*consts.Uint64Len)
+	seq := p.UnpackUint64(true)
+	height := p.UnpackUint64(false)
+	return seq, height, msg[2*consts.Uint64Len:], p.Err()
+}
+
 func PackBlockMessage(b *chain.StatelessBlock) ([]byte, error) {
-	results := b.Results()
-	size := codec.BytesLen(b.Bytes()) + consts.IntLen + codec.CummSize(results) + fees.DimensionsLen
+	return packBlockMessage(b.Bytes(), b.DelayedExecution(), b.PendingTxs(), b.Results(), b.FeeManager().UnitPrices())
+}
+
+func packBlockMessage(
+	blk []byte,
+	delayed bool,
+	pending []*chain.Transaction,
+	results []*chain.Result,
+	prices fees.Dimensions,
+) ([]byte, error) {
+	size := codec.BytesLen(blk) + consts.BoolLen + consts.IntLen + codec.CummSize(pending) +
+		consts.IntLen + codec.CummSize(results) + fees.DimensionsLen
 	p := codec.NewWriter(size, consts.MaxInt)
-	p.PackBytes(b.Bytes())
+	p.PackBytes(blk)
+	p.PackBool(delayed)
+	p.PackInt(len(pending))
+	for _, tx := range pending {
+		if err := tx.Marshal(p); err != nil {
+			return nil, err
+		}
+	}
 	mresults, err := chain.MarshalResults(results)
 	if err != nil {
 		return nil, err
 	}
 	p.PackBytes(mresults)
-	p.PackFixedBytes(b.FeeManager().UnitPrices().Bytes())
+	p.PackFixedBytes(prices.Bytes())
 	return p.Bytes(), p.Err()
 }
 
+// UnpackBlockMessage returns the block in [msg], the transactions executed by
+// the block, their results, and the unit prices of the block.
+//
+// If [chain.Rules.GetDelayedExecution] is enabled, the executed transactions
+// may differ from those included in the block.
 func UnpackBlockMessage(
 	msg []byte,
 	parser chain.Parser,
-) (*chain.StatefulBlock, []*chain.Result, fees.Dimensions, error) {
+) (*chain.StatefulBlock, []*chain.Transaction, []*chain.Result, fees.Dimensions, error) {
 	p := codec.NewReader(msg, consts.MaxInt)
 	var blkMsg []byte
 	p.UnpackBytes(-1, true, &blkMsg)
 	blk, err := chain.UnmarshalBlock(blkMsg, parser)
 	if err != nil {
-		return nil, nil, fees.Dimensions{}, err
+		return nil, nil, nil, fees.Dimensions{}, err
+	}
+	delayed := p.UnpackBool()
+	pendingCount := p.UnpackInt(false)
+	actionRegistry, authRegistry := parser.Registry()
+	executed := make([]*chain.Transaction, 0, len(blk.Txs)) // DoS to set size to pendingCount
+	for i := 0; i < pendingCount; i++ {
+		tx, err := chain.UnmarshalTx(p, actionRegistry, authRegistry)
+		if err != nil {
+			return nil, nil, nil, fees.Dimensions{}, err
+		}
+		executed = append(executed, tx)
+	}
+	if !delayed {
+		executed = append(executed, blk.Txs...)
 	}
 	var resultsMsg []byte
 	p.UnpackBytes(-1, true, &resultsMsg)
 	results, err := chain.UnmarshalResults(resultsMsg)
 	if err != nil {
-		return nil, nil, fees.Dimensions{}, err
+		return nil, nil, nil, fees.Dimensions{}, err
 	}
 	pricesMsg := make([]byte, fees.DimensionsLen)
 	p.UnpackFixedBytes(fees.DimensionsLen, &pricesMsg)
 	prices, err := fees.UnpackDimensions(pricesMsg)
 	if err != nil {
-		return nil, nil, fees.Dimensions{}, err
+		return nil, nil, nil, fees.Dimensions{}, err
 	}
 	if !p.Empty() {
-		return nil, nil, fees.Dimensions{}, chain.ErrInvalidObject
+		return nil, nil, nil, fees.Dimensions{}, chain.ErrInvalidObject
 	}
-	return blk, results, prices, p.Err()
+	return blk, executed, results, prices, p.Err()
 }
 
 // Could be a better place for these methods
 // Packs an accepted block message
 func PackAcceptedTxMessage(txID ids.ID, result *chain.Result) ([]byte, error) {
-	size := ids.IDLen + consts.BoolLen + result.Size()
+	size := ids.IDLen + consts.ByteLen + result.Size()
 	p := codec.NewWriter(size, consts.MaxInt)
 	p.PackID(txID)
-	p.PackBool(false)
+	p.PackByte(TxExecuted)
 	if err := result.Marshal(p); err != nil {
 		return nil, err
 	}
@@ -78,31 +180,152 @@ func PackAcceptedTxMessage(txID ids.ID, result *chain.Result) ([]byte, error) {
 // Packs a removed block message
 func PackRemovedTxMessage(txID ids.ID, err error) ([]byte, error) {
 	errString := err.Error()
-	size := ids.IDLen + consts.BoolLen + codec.StringLen(errString)
+	size := ids.IDLen + consts.ByteLen + codec.StringLen(errString)
 	p := codec.NewWriter(size, consts.MaxInt)
 	p.PackID(txID)
-	p.PackBool(true)
+	p.PackByte(TxRemoved)
 	p.PackString(errString)
 	return p.Bytes(), p.Err()
 }
 
-// Unpacks a tx message from [msg]. Returns the txID, an error regarding the status
-// of the tx, the result of the tx, and an error if there was a
-// problem unpacking the message.
-func UnpackTxMessage(msg []byte) (ids.ID, error, *chain.Result, error) {
+// Packs an included (but not yet executed) tx message
+func PackIncludedTxMessage(txID ids.ID) ([]byte, error) {
+	p := codec.NewWriter(ids.IDLen+consts.ByteLen, consts.MaxInt)
+	p.PackID(txID)
+	p.PackByte(TxIncluded)
+	return p.Bytes(), p.Err()
+}
+
+// Packs a pending (not yet verified) tx message
+func PackPendingTxMessage(txID ids.ID) ([]byte, error) {
+	p := codec.NewWriter(ids.IDLen+consts.ByteLen, consts.MaxInt)
+	p.PackID(txID)
+	p.PackByte(TxPending)
+	return p.Bytes(), p.Err()
+}
+
+// Unpacks a tx message from [msg]. Returns the txID, the status of the tx, an
+// error regarding the status of the tx (if removed), the result of the tx (if
+// executed), and an error if there was a problem unpacking the message.
+func UnpackTxStatusMessage(msg []byte) (ids.ID, byte, error, *chain.Result, error) {
 	p := codec.NewReader(msg, consts.MaxInt)
 	var txID ids.ID
 	p.UnpackID(true, &txID)
-	if p.UnpackBool() {
+	status := p.UnpackByte()
+	switch status {
+	case TxExecuted:
+		result, err := chain.UnmarshalResult(p)
+		if err != nil {
+			return ids.Empty, 0, nil, nil, err
+		}
+		if !p.Empty() {
+			return ids.Empty, 0, nil, nil, chain.ErrInvalidObject
+		}
+		return txID, status, nil, result, p.Err()
+	case TxRemoved:
 		err := p.UnpackString(true)
-		return ids.Empty, errors.New(err), nil, p.Err()
+		return ids.Empty, status, errors.New(err), nil, p.Err()
+	case TxIncluded, TxPending:
+		if !p.Empty() {
+			return ids.Empty, 0, nil, nil, chain.ErrInvalidObject
+		}
+		return txID, status, nil, nil, p.Err()
+	default:
+		return ids.Empty, 0, nil, nil, chain.ErrInvalidObject
+	}
+}
+
+// Unpacks a tx message from [msg]. Returns the txID, an error regarding the status
+// of the tx, the result of the tx, and an error if there was a
+// problem unpacking the message.
+//
+// Included and pending tx messages are returned with a nil result.
+func UnpackTxMessage(msg []byte) (ids.ID, error, *chain.Result, error) {
+	txID, _, dErr, result, err := UnpackTxStatusMessage(msg)
+	return txID, dErr, result, err
+}
+
+// PackBundleSubmission packs a submission of [bundle] (listeners of a bundle
+// are notified with bundle messages).
+func PackBundleSubmission(bundle *chain.Bundle) ([]byte, error) {
+	p := codec.NewWriter(consts.ByteLen+bundle.Size(), consts.MaxInt)
+	p.PackByte(BundleMode)
+	if err := bundle.Marshal(p); err != nil {
+		return nil, err
 	}
-	result, err := chain.UnmarshalResult(p)
+	return p.Bytes(), p.Err()
+}
+
+// PackExecutedBundleMessage packs an executed bundle message with the
+// [results] of all of its transactions (in order).
+//
+// Bundle messages use the same statuses as tx messages (except [TxPending]).
+func PackExecutedBundleMessage(bundleID ids.ID, results []*chain.Result) ([]byte, error) {
+	mresults, err := chain.MarshalResults(results)
 	if err != nil {
-		return ids.Empty, nil, nil, err
+		return nil, err
 	}
-	if !p.Empty() {
-		return ids.Empty, nil, nil, chain.ErrInvalidObject
+	p := codec.NewWriter(ids.IDLen+consts.ByteLen+codec.BytesLen(mresults), consts.MaxInt)
+	p.PackID(bundleID)
+	p.PackByte(TxExecuted)
+	p.PackBytes(mresults)
+	return p.Bytes(), p.Err()
+}
+
+// Packs a removed bundle message
+func PackRemovedBundleMessage(bundleID ids.ID, err error) ([]byte, error) {
+	errString := err.Error()
+	p := codec.NewWriter(ids.IDLen+consts.ByteLen+codec.StringLen(errString), consts.MaxInt)
+	p.PackID(bundleID)
+	p.PackByte(TxRemoved)
+	p.PackString(errString)
+	return p.Bytes(), p.Err()
+}
+
+// Packs an included (but not yet executed) bundle message
+func PackIncludedBundleMessage(bundleID ids.ID) ([]byte, error) {
+	p := codec.NewWriter(ids.IDLen+consts.ByteLen, consts.MaxInt)
+	p.PackID(bundleID)
+	p.PackByte(TxIncluded)
+	return p.Bytes(), p.Err()
+}
+
+// Unpacks a bundle message from [msg]. Returns the bundleID, the status of the
+// bundle, an error regarding the status of the bundle (if removed), the
+// results of its transactions (if executed), and an error if there was a
+// problem unpacking the message.
+func UnpackBundleStatusMessage(msg []byte) (ids.ID, byte, error, []*chain.Result, error) {
+	p := codec.NewReader(msg, consts.MaxInt)
+	var bundleID ids.ID
+	p.UnpackID(true, &bundleID)
+	status := p.UnpackByte()
+	switch status {
+	case TxExecuted:
+		var resultsMsg []byte
+		p.UnpackBytes(-1, true, &resultsMsg)
+		if err := p.Err(); err != nil {
+			return ids.Empty, 0, nil, nil, err
+		}
+		results, err := chain.UnmarshalResults(resultsMsg)
+		if err != nil {
+			return ids.Empty, 0, nil, nil, err
+		}
+		if !p.Empty() {
+			return ids.Empty, 0, nil, nil, chain.ErrInvalidObject
+		}
+		return bundleID, status, nil, results, nil
+	case TxRemoved:
+		err := p.UnpackString(true)
+		if !p.Empty() {
+			return ids.Empty, 0, nil, nil, chain.ErrInvalidObject
+		}
+		return bundleID, status, errors.New(err), nil, p.Err()
+	case TxIncluded:
+		if !p.Empty() {
+			return ids.Empty, 0, nil, nil, chain.ErrInvalidObject
+		}
+		return bundleID, status, nil, nil, p.Err()
+	default:
+		return ids.Empty, 0, nil, nil, chain.ErrInvalidObject
 	}
-	return txID, nil, result, p.Err()
 }
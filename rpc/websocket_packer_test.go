@@ -0,0 +1,57 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+func TestBundleStatusMessage(t *testing.T) {
+	require := require.New(t)
+	bundleID := ids.GenerateTestID()
+
+	// Executed bundles carry the results of all of their txs (in order)
+	results := []*chain.Result{
+		{Success: true, Outputs: [][][]byte{{[]byte("a")}}, Units: fees.Dimensions{1, 2, 3, 4, 5}, Fee: 15},
+		{Success: true, Outputs: [][][]byte{{}}, Fee: 1},
+	}
+	msg, err := PackExecutedBundleMessage(bundleID, results)
+	require.NoError(err)
+	id, status, dErr, unpacked, err := UnpackBundleStatusMessage(msg)
+	require.NoError(err)
+	require.Equal(bundleID, id)
+	require.Equal(TxExecuted, status)
+	require.NoError(dErr)
+	require.Len(unpacked, 2)
+	require.Equal(results[0].Units, unpacked[0].Units)
+	require.Equal(results[1].Fee, unpacked[1].Fee)
+
+	msg, err = PackRemovedBundleMessage(bundleID, ErrExpired)
+	require.NoError(err)
+	id, status, dErr, unpacked, err = UnpackBundleStatusMessage(msg)
+	require.NoError(err)
+	require.Equal(bundleID, id)
+	require.Equal(TxRemoved, status)
+	require.Equal(ErrExpired.Error(), dErr.Error())
+	require.Nil(unpacked)
+
+	msg, err = PackIncludedBundleMessage(bundleID)
+	require.NoError(err)
+	id, status, _, _, err = UnpackBundleStatusMessage(msg)
+	require.NoError(err)
+	require.Equal(bundleID, id)
+	require.Equal(TxIncluded, status)
+
+	// Pending is not a bundle status
+	msg, err = PackPendingTxMessage(bundleID)
+	require.NoError(err)
+	_, _, _, _, err = UnpackBundleStatusMessage(msg)
+	require.ErrorIs(err, chain.ErrInvalidObject)
+}
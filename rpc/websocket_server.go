@@ -18,23 +18,62 @@ import (
 	"github.com/ava-labs/hypersdk/pubsub"
 )
 
+// blockMessage is a packed block message of an accepted block.
+type blockMessage struct {
+	height uint64
+	msg    []byte
+}
+
+// blockSubscriber tracks the block messages delivered to a connection, so
+// that each accepted block is delivered at most once (even if it is both
+// replayed from the backlog and accepted after the connection subscribes).
+type blockSubscriber struct {
+	next uint64 // height of the next block to deliver (0 if none delivered)
+	seq  uint64 // sequence number of the last message delivered
+}
+
+// bundleListener tracks the results of the transactions of a bundle, so its
+// listeners are only notified once all of them are executed.
+type bundleListener struct {
+	conns    *pubsub.Connections
+	txIDs    []ids.ID
+	results  map[ids.ID]*chain.Result
+	included bool // notified of inclusion (delayed execution only)
+}
+
 type WebSocketServer struct {
 	logger logging.Logger
 	s      *pubsub.Server
 
-	blockListeners *pubsub.Connections
+	// [blockL] protects [blockListeners] and [blockBacklog] so that blocks
+	// replayed to a new subscriber are always delivered before (and never
+	// again after) newly accepted blocks.
+	blockL         sync.Mutex
+	blockListeners map[*pubsub.Connection]*blockSubscriber
+	blockBacklog   []*blockMessage // oldest first
 
 	txL         sync.Mutex
 	txListeners map[ids.ID]*pubsub.Connections
 	expiringTxs *emap.EMap[*chain.Transaction] // ensures all tx listeners are eventually responded to
+
+	// [bundleTxs] maps the transactions of a bundle with listeners to the
+	// bundle (a transaction submitted in several bundles is only tracked for
+	// the last one, the others expire). Protected by [txL].
+	bundleListeners map[ids.ID]*bundleListener
+	bundleTxs       map[ids.ID]ids.ID
+	expiringBundles *emap.EMap[*chain.Bundle]
 }
 
 func NewWebSocketServer(vm VM, maxPendingMessages int) (*WebSocketServer, *pubsub.Server) {
 	w := &WebSocketServer{
-		logger:         vm.Logger(),
-		blockListeners: pubsub.NewConnections(),
+		logger:         vm.RPCLogger(),
+		blockListeners: map[*pubsub.Connection]*blockSubscriber{},
 		txListeners:    map[ids.ID]*pubsub.Connections{},
 		expiringTxs:    emap.NewEMap[*chain.Transaction](),
+
+		bundleListeners: map[ids.ID]*bundleListener{},
+		bundleTxs:       map[ids.ID]ids.ID{},
+		expiringBundles: emap.NewEMap[*chain.Bundle](),
 	}
 	cfg := pubsub.NewDefaultServerConfig()
 	cfg.MaxPendingMessages = maxPendingMessages
@@ -42,6 +81,51 @@ func NewWebSocketServer(vm VM, maxPendingMessages int) (*WebSocketServer, *pubsu
 	return w, w.s
 }
 
+// AddBlockListener subscribes [c] to accepted blocks. If [from] is non-zero,
+// the accepted blocks in the backlog starting at height [from] are sent to
+// [c] first.
+//
+// Blocks at or below the height of a block already delivered to [c] are
+// never sent again.
+func (w *WebSocketServer) AddBlockListener(c *pubsub.Connection, from uint64) {
+	w.blockL.Lock()
+	defer w.blockL.Unlock()
+
+	sub, ok := w.blockListeners[c]
+	if !ok {
+		sub = &blockSubscriber{}
+		w.blockListeners[c] = sub
+	}
+	if from == 0 {
+		return
+	}
+	for _, m := range w.blockBacklog {
+		if m.height >= from {
+			w.sendBlock(c, sub, m)
+		}
+	}
+}
+
+// sendBlock sends [m] to [c] with the next sequence number of [sub], unless a
+// block at the same (or a greater) height was already delivered to [c].
+//
+// If [m] can't be sent (because too many messages are pending for [c]), the
+// sequence number is not incremented and [c] will observe a gap in heights.
+func (w *WebSocketServer) sendBlock(c *pubsub.Connection, sub *blockSubscriber, m *blockMessage) {
+	if m.height < sub.next {
+		w.logger.Debug("suppressed duplicate block",
+			zap.Uint64("height", m.height),
+			zap.Uint64("next", sub.next),
+		)
+		return
+	}
+	if !c.Send(PackSequencedBlockMessage(sub.seq+1, m.height, m.msg)) {
+		return
+	}
+	sub.seq++
+	sub.next = m.height + 1
+}
+
 // Note: no need to have a tx listener removal, this will happen when all
 // submitted transactions are cleared.
 func (w *WebSocketServer) AddTxListener(tx *chain.Transaction, c *pubsub.Connection) {
@@ -57,14 +141,79 @@ func (w *WebSocketServer) AddTxListener(tx *chain.Transaction, c *pubsub.Connect
 	w.expiringTxs.Add([]*chain.Transaction{tx})
 }
 
-// If never possible for a tx to enter mempool, call this
+// AddBundleListener subscribes [c] to the status of [bundle]. Like tx
+// listeners, bundle listeners are cleared once the bundle is executed,
+// removed, or expired.
+func (w *WebSocketServer) AddBundleListener(bundle *chain.Bundle, c *pubsub.Connection) {
+	w.txL.Lock()
+	defer w.txL.Unlock()
+
+	bundleID := bundle.ID()
+	listener, ok := w.bundleListeners[bundleID]
+	if !ok {
+		listener = &bundleListener{
+			conns:   pubsub.NewConnections(),
+			txIDs:   make([]ids.ID, len(bundle.Txs)),
+			results: make(map[ids.ID]*chain.Result, len(bundle.Txs)),
+		}
+		for i, tx := range bundle.Txs {
+			listener.txIDs[i] = tx.ID()
+			w.bundleTxs[listener.txIDs[i]] = bundleID
+		}
+		w.bundleListeners[bundleID] = listener
+	}
+	listener.conns.Add(c)
+	w.expiringBundles.Add([]*chain.Bundle{bundle})
+}
+
+// If never possible for a tx to enter mempool, call this (the bundle the tx
+// is in, if any, is removed as well)
 func (w *WebSocketServer) RemoveTx(txID ids.ID, err error) error {
 	w.txL.Lock()
 	defer w.txL.Unlock()
 
+	if bundleID, ok := w.bundleTxs[txID]; ok {
+		if err := w.removeBundle(bundleID, err); err != nil {
+			return err
+		}
+	}
 	return w.removeTx(txID, err)
 }
 
+// RemoveBundle notifies the listeners of [bundleID] that it will never be
+// included.
+func (w *WebSocketServer) RemoveBundle(bundleID ids.ID, err error) error {
+	w.txL.Lock()
+	defer w.txL.Unlock()
+
+	return w.removeBundle(bundleID, err)
+}
+
+func (w *WebSocketServer) removeBundle(bundleID ids.ID, err error) error {
+	listener, ok := w.bundleListeners[bundleID]
+	if !ok {
+		return nil
+	}
+	bytes, err := PackRemovedBundleMessage(bundleID, err)
+	if err != nil {
+		return err
+	}
+	w.s.Publish(append([]byte{BundleMode}, bytes...), listener.conns)
+	w.deleteBundle(bundleID, listener)
+	return nil
+}
+
+// deleteBundle stops tracking the listeners of [bundleID] ([expiringBundles]
+// will be cleared eventually).
+func (w *WebSocketServer) deleteBundle(bundleID ids.ID, listener *bundleListener) {
+	delete(w.bundleListeners, bundleID)
+	for _, txID := range listener.txIDs {
+		if w.bundleTxs[txID] == bundleID {
+			delete(w.bundleTxs, txID)
+		}
+	}
+}
+
 func (w *WebSocketServer) removeTx(txID ids.ID, err error) error {
 	listeners, ok := w.txListeners[txID]
 	if !ok {
@@ -80,6 +229,24 @@ func (w *WebSocketServer) removeTx(txID ids.ID, err error) error {
 	return nil
 }
 
+// PendingTx notifies the listeners of [txID] that it is waiting for its auth
+// to be verified (the listeners are kept until it is removed or executed).
+func (w *WebSocketServer) PendingTx(txID ids.ID) error {
+	w.txL.Lock()
+	defer w.txL.Unlock()
+
+	listeners, ok := w.txListeners[txID]
+	if !ok {
+		return nil
+	}
+	bytes, err := PackPendingTxMessage(txID)
+	if err != nil {
+		return err
+	}
+	w.s.Publish(append([]byte{TxMode}, bytes...), listeners)
+	return nil
+}
+
 func (w *WebSocketServer) SetMinTx(t int64) error {
 	w.txL.Lock()
 	defer w.txL.Unlock()
@@ -90,28 +257,29 @@ func (w *WebSocketServer) SetMinTx(t int64) error {
 			return err
 		}
 	}
-	if exp := len(expired); exp > 0 {
+	expiredBundles := w.expiringBundles.SetMin(t)
+	for _, id := range expiredBundles {
+		if err := w.removeBundle(id, ErrExpired); err != nil {
+			return err
+		}
+	}
+	if exp := len(expired) + len(expiredBundles); exp > 0 {
 		w.logger.Debug("expired listeners", zap.Int("count", exp))
 	}
 	return nil
 }
 
 func (w *WebSocketServer) AcceptBlock(b *chain.StatelessBlock) error {
-	if w.blockListeners.Len() > 0 {
-		bytes, err := PackBlockMessage(b)
-		if err != nil {
-			return err
-		}
-		inactiveConnection := w.s.Publish(append([]byte{BlockMode}, bytes...), w.blockListeners)
-		for _, conn := range inactiveConnection {
-			w.blockListeners.Remove(conn)
-		}
+	bytes, err := PackBlockMessage(b)
+	if err != nil {
+		return err
 	}
+	w.acceptBlockMessage(&blockMessage{height: b.Hght, msg: bytes})
 
 	w.txL.Lock()
 	defer w.txL.Unlock()
 	results := b.Results()
-	for i, tx := range b.Txs {
+	for i, tx := range b.ExecutedTxs() {
 		txID := tx.ID()
 		listeners, ok := w.txListeners[txID]
 		if !ok {
@@ -126,15 +294,102 @@ func (w *WebSocketServer) AcceptBlock(b *chain.StatelessBlock) error {
 		delete(w.txListeners, txID)
 		// [expiringTxs] will be cleared eventually (does not support removal)
 	}
+	if err := w.acceptBundleTxs(b.ExecutedTxs(), results); err != nil {
+		return err
+	}
+	if !b.DelayedExecution() {
+		return nil
+	}
+
+	// Notify listeners of txs that will be executed by the next block (we keep
+	// the listeners registered until then)
+	for _, tx := range b.Txs {
+		txID := tx.ID()
+		listeners, ok := w.txListeners[txID]
+		if !ok {
+			continue
+		}
+		bytes, err := PackIncludedTxMessage(txID)
+		if err != nil {
+			return err
+		}
+		w.s.Publish(append([]byte{TxMode}, bytes...), listeners)
+	}
+	for _, tx := range b.Txs {
+		bundleID, ok := w.bundleTxs[tx.ID()]
+		if !ok {
+			continue
+		}
+		listener := w.bundleListeners[bundleID]
+		if listener.included {
+			continue
+		}
+		listener.included = true
+		bytes, err := PackIncludedBundleMessage(bundleID)
+		if err != nil {
+			return err
+		}
+		w.s.Publish(append([]byte{BundleMode}, bytes...), listener.conns)
+	}
 	return nil
 }
 
+// acceptBundleTxs records the [results] of the executed [txs] that are in a
+// bundle with listeners and notifies the listeners of each bundle once all of
+// its transactions are executed.
+func (w *WebSocketServer) acceptBundleTxs(txs []*chain.Transaction, results []*chain.Result) error {
+	for i, tx := range txs {
+		txID := tx.ID()
+		bundleID, ok := w.bundleTxs[txID]
+		if !ok {
+			continue
+		}
+		listener := w.bundleListeners[bundleID]
+		listener.results[txID] = results[i]
+		if len(listener.results) < len(listener.txIDs) {
+			continue
+		}
+		bundleResults := make([]*chain.Result, len(listener.txIDs))
+		for j, memberID := range listener.txIDs {
+			bundleResults[j] = listener.results[memberID]
+		}
+		bytes, err := PackExecutedBundleMessage(bundleID, bundleResults)
+		if err != nil {
+			return err
+		}
+		w.s.Publish(append([]byte{BundleMode}, bytes...), listener.conns)
+		w.deleteBundle(bundleID, listener)
+	}
+	return nil
+}
+
+// acceptBlockMessage adds [m] to the backlog and sends it to all block
+// listeners.
+func (w *WebSocketServer) acceptBlockMessage(m *blockMessage) {
+	w.blockL.Lock()
+	defer w.blockL.Unlock()
+
+	w.blockBacklog = append(w.blockBacklog, m)
+	if len(w.blockBacklog) > BlockBacklogSize {
+		w.blockBacklog[0] = nil
+		w.blockBacklog = w.blockBacklog[1:]
+	}
+	conns := w.s.Connections()
+	for c, sub := range w.blockListeners {
+		if !conns.Has(c) {
+			delete(w.blockListeners, c)
+			continue
+		}
+		w.sendBlock(c, sub, m)
+	}
+}
+
 func (w *WebSocketServer) MessageCallback(vm VM) pubsub.Callback {
 	// Assumes controller is initialized before this is called
 	var (
 		actionRegistry, authRegistry = vm.Registry()
 		tracer                       = vm.Tracer()
-		log                          = vm.Logger()
+		log                          = vm.RPCLogger()
 	)
 
 	return func(msgBytes []byte, c *pubsub.Connection) {
@@ -153,8 +408,16 @@ func (w *WebSocketServer) MessageCallback(vm VM) pubsub.Callback {
 		// implementations
 		switch msgBytes[0] {
 		case BlockMode:
-			w.blockListeners.Add(c)
-			log.Debug("added block listener")
+			from, err := UnpackBlockSubscription(msgBytes[1:])
+			if err != nil {
+				log.Error("failed to unmarshal block subscription",
+					zap.Int("len", len(msgBytes)),
+					zap.Error(err),
+				)
+				return
+			}
+			w.AddBlockListener(c, from)
+			log.Debug("added block listener", zap.Uint64("from", from))
 		case TxMode:
 			msgBytes = msgBytes[1:]
 			// Unmarshal TX
@@ -168,32 +431,48 @@ func (w *WebSocketServer) MessageCallback(vm VM) pubsub.Callback {
 				return
 			}
 
-			// Verify tx
-			if vm.GetVerifyAuth() {
-				msg, err := tx.Digest()
-				if err != nil {
-					// Should never occur because populated during unmarshal
-					return
-				}
-				if err := tx.Auth.Verify(ctx, msg); err != nil {
-					log.Error("failed to verify sig",
-						zap.Error(err),
-					)
-					return
-				}
-			}
 			w.AddTxListener(tx, c)
 
-			// Submit will remove from [txWaiters] if it is not added
+			// Auth is verified by [SubmitDeferred], which notifies the
+			// listener if the tx is pending or removed
 			txID := tx.ID()
-			if err := vm.Submit(ctx, false, []*chain.Transaction{tx})[0]; err != nil {
+			if err := vm.SubmitDeferred(ctx, []*chain.Transaction{tx})[0]; err != nil {
 				log.Error("failed to submit tx",
 					zap.Stringer("txID", txID),
 					zap.Error(err),
 				)
 				return
 			}
-			log.Debug("submitted tx", zap.Stringer("id", txID))
+			log.Debug("submitted tx", zap.Stringer("txID", txID))
+		case BundleMode:
+			msgBytes = msgBytes[1:]
+			p := codec.NewReader(msgBytes, consts.NetworkSizeLimit)
+			bundle, err := chain.UnmarshalBundle(p, actionRegistry, authRegistry)
+			if err == nil && !p.Empty() {
+				err = chain.ErrInvalidObject
+			}
+			if err != nil {
+				log.Error("failed to unmarshal bundle",
+					zap.Int("len", len(msgBytes)),
+					zap.Error(err),
+				)
+				return
+			}
+
+			w.AddBundleListener(bundle, c)
+
+			// Like txs, a bundle that fails auth verification is reported as
+			// removed (other failures may still end up with the bundle in a
+			// block if it was already submitted)
+			bundleID := bundle.ID()
+			if err := vm.SubmitBundle(ctx, true, bundle); err != nil {
+				log.Error("failed to submit bundle",
+					zap.Stringer("bundleID", bundleID),
+					zap.Error(err),
+				)
+				return
+			}
+			log.Debug("submitted bundle", zap.Stringer("bundleID", bundleID))
 		default:
 			log.Error("unexpected message type",
 				zap.Int("len", len(msgBytes)),
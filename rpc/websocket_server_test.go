@@ -0,0 +1,166 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/pubsub"
+	"github.com/ava-labs/hypersdk/requester"
+)
+
+// webSocketTestVM implements the parts of [VM] (and [chain.Parser]) used by
+// the [WebSocketServer] to stream blocks.
+type webSocketTestVM struct {
+	VM
+}
+
+func (*webSocketTestVM) RPCLogger() logging.Logger { return logging.NoLog{} }
+func (*webSocketTestVM) Tracer() trace.Tracer      { return trace.Noop }
+func (*webSocketTestVM) Rules(int64) chain.Rules   { return &webSocketTestRules{} }
+func (*webSocketTestVM) Registry() (chain.ActionRegistry, chain.AuthRegistry) {
+	return codec.NewTypeParser[chain.Action, bool](), codec.NewTypeParser[chain.Auth, bool]()
+}
+
+// webSocketTestRules activates no forks and disables epochs and results roots.
+type webSocketTestRules struct {
+	chain.Rules
+}
+
+func (*webSocketTestRules) GetForkActivations() chain.ForkActivations { return nil }
+func (*webSocketTestRules) GetEpochLength() uint64                    { return 0 }
+func (*webSocketTestRules) GetIncludeResultsRoot() bool               { return false }
+
+// newWebSocketTest starts a [WebSocketServer] and returns a client connected
+// to it (that reconnects if disconnected) and only buffers [pending] block
+// messages before it stops reading from the connection.
+func newWebSocketTest(t *testing.T, pending int) (*WebSocketServer, *WebSocketClient) {
+	w, s := NewWebSocketServer(&webSocketTestVM{}, pubsub.MaxPendingMessages)
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	c, err := NewReconnectingWebSocketClient(
+		srv.URL,
+		DefaultHandshakeTimeout,
+		pending,
+		pubsub.MaxWriteMessageSize,
+		requester.RetryPolicy{
+			MaxAttempts:    10,
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+			Multiplier:     1,
+		},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return w, c
+}
+
+// acceptTestBlocks accepts empty blocks at heights [from, to].
+func acceptTestBlocks(t *testing.T, w *WebSocketServer, from uint64, to uint64) {
+	for height := from; height <= to; height++ {
+		blk, err := (&chain.StatefulBlock{Hght: height, Txs: []*chain.Transaction{}}).Marshal(&webSocketTestVM{})
+		require.NoError(t, err)
+		msg, err := packBlockMessage(blk, false, nil, nil, fees.Dimensions{})
+		require.NoError(t, err)
+		w.acceptBlockMessage(&blockMessage{height: height, msg: msg})
+	}
+}
+
+// requireBlocks requires that the next blocks received by [c] are at heights
+// [from, to] (in order).
+func requireBlocks(t *testing.T, c *WebSocketClient, from uint64, to uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for height := from; height <= to; height++ {
+		blk, _, _, _, err := c.ListenBlock(ctx, &webSocketTestVM{})
+		require.NoError(t, err)
+		require.Equal(t, height, blk.Hght)
+	}
+}
+
+func TestWebSocketBlockBacklog(t *testing.T) {
+	w, c := newWebSocketTest(t, 128)
+
+	// Blocks are replayed from the backlog (the oldest is dropped when full)
+	acceptTestBlocks(t, w, 1, BlockBacklogSize+1)
+	require.NoError(t, c.RegisterBlocksFrom(2))
+	requireBlocks(t, c, 2, BlockBacklogSize+1)
+
+	// Subscribing again on the same connection doesn't deliver any block
+	// twice
+	require.NoError(t, c.RegisterBlocksFrom(2))
+	acceptTestBlocks(t, w, BlockBacklogSize+2, BlockBacklogSize+2)
+	requireBlocks(t, c, BlockBacklogSize+2, BlockBacklogSize+2)
+}
+
+func TestWebSocketBlockHandoffSlowConsumer(t *testing.T) {
+	// The client stops reading after 2 block messages, so most messages are
+	// buffered by the server
+	w, c := newWebSocketTest(t, 2)
+
+	// Blocks accepted while the subscription is processed are delivered
+	// exactly once, whether they are replayed or sent live
+	acceptTestBlocks(t, w, 1, 10)
+	require.NoError(t, c.RegisterBlocksFrom(1))
+	acceptTestBlocks(t, w, 11, 30)
+	requireBlocks(t, c, 1, 30)
+}
+
+func TestWebSocketBlockHandoffReconnect(t *testing.T) {
+	w, c := newWebSocketTest(t, 128)
+
+	require.NoError(t, c.RegisterBlocksFrom(1))
+	acceptTestBlocks(t, w, 1, 5)
+	requireBlocks(t, c, 1, 5)
+
+	// Blocks accepted while the client reconnects (and resubscribes from the
+	// last block it received) are delivered exactly once
+	c.l.Lock()
+	require.NoError(t, c.conn.Close())
+	c.l.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for height := uint64(6); height <= 20; height++ {
+			acceptTestBlocks(t, w, height, height)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	requireBlocks(t, c, 6, 20)
+	<-done
+}
+
+func TestWebSocketBlockOutOfOrder(t *testing.T) {
+	require := require.New(t)
+	w, c := newWebSocketTest(t, 128)
+
+	require.NoError(c.RegisterBlocksFrom(1))
+	acceptTestBlocks(t, w, 1, 2)
+	requireBlocks(t, c, 1, 2)
+
+	// Reusing a sequence number closes the client (instead of reconnecting)
+	w.blockL.Lock()
+	for _, sub := range w.blockListeners {
+		sub.seq = 0
+	}
+	w.blockL.Unlock()
+	acceptTestBlocks(t, w, 3, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _, _, _, err := c.ListenBlock(ctx, &webSocketTestVM{})
+	require.ErrorIs(err, ErrOutOfOrder)
+}
@@ -0,0 +1,28 @@
+#!/usr/bin/env bash
+# Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+# See the file LICENSE for licensing terms.
+
+set -e
+
+if ! [[ "$0" =~ scripts/protobuf.gen.sh ]]; then
+  echo "must be run from repository root"
+  exit 255
+fi
+
+# https://github.com/protocolbuffers/protobuf/releases
+if ! command -v protoc &> /dev/null; then
+  echo "protoc must be installed"
+  exit 255
+fi
+
+# https://pkg.go.dev/google.golang.org/protobuf/cmd/protoc-gen-go
+#
+# Keep the version in sync with google.golang.org/protobuf in go.mod.
+go install -v google.golang.org/protobuf/cmd/protoc-gen-go@v1.33.0
+
+for proto in offload/offload.proto; do
+  echo "Generating ${proto%.proto}.pb.go..."
+  protoc --go_out=. --go_opt=paths=source_relative "${proto}"
+done
+
+echo "SUCCESS"
@@ -3,8 +3,15 @@
 
 package state
 
-import "github.com/ava-labs/hypersdk/keys"
+import (
+	"slices"
 
+	"github.com/ava-labs/hypersdk/keys"
+)
+
+// [Read] allows a key to be read, [Allocate] allows a key that doesn't exist
+// to be created, and [Write] allows an existing key to be modified or removed
+// (both [Allocate] and [Write] include [Read]).
 const (
 	Read     Permissions = 1
 	Allocate             = 1<<1 | Read
@@ -24,7 +31,9 @@ type Permissions byte
 
 // Add verifies that a key is well-formatted and adds it to the conflict set.
 //
-// If the key already exists, the permissions are unioned.
+// If the key already exists, the permissions are unioned (so the strongest
+// permission wins: adding [Read] to a [Write] key leaves it [Write], and
+// adding [Write] to a [Read] key makes it [Write]).
 func (k Keys) Add(key string, permission Permissions) bool {
 	// If a key is not properly formatted, it cannot be added.
 	if !keys.Valid(key) {
@@ -37,6 +46,20 @@ func (k Keys) Add(key string, permission Permissions) bool {
 	return true
 }
 
+// Sorted returns the keys in [k] in lexicographic order.
+//
+// Map iteration order is randomized, so any code that acquires locks (or
+// records dependencies) per key should iterate over [Sorted] to ensure
+// all tasks observe the same global ordering.
+func (k Keys) Sorted() []string {
+	sorted := make([]string, 0, len(k))
+	for key := range k {
+		sorted = append(sorted, key)
+	}
+	slices.Sort(sorted)
+	return sorted
+}
+
 // Has returns true if [p] has all the permissions that are contained in require
 func (p Permissions) Has(require Permissions) bool {
 	return require&^p == 0
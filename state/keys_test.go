@@ -84,3 +84,13 @@ func TestMalformedKey(t *testing.T) {
 	keys := make(Keys)
 	require.False(keys.Add("", Read))
 }
+
+func TestSortedKeys(t *testing.T) {
+	require := require.New(t)
+	keys := make(Keys)
+	require.True(keys.Add("test3", Read))
+	require.True(keys.Add("test1", Write))
+	require.True(keys.Add("test2", Allocate))
+	require.Equal([]string{"test1", "test2", "test3"}, keys.Sorted())
+	require.Empty(Keys{}.Sorted())
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tstate
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+
+	"github.com/ava-labs/avalanchego/utils/maybe"
+
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/keys"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// SetAccumulators restricts [accumulators] (which must be in scope) to
+// [Accumulate], so the view can neither observe nor overwrite their values.
+// If [deferred] is set, accumulations are only applied to the parent view
+// when the view is committed (in any order), so views that only accumulate to
+// the same keys can be executed concurrently.
+//
+// The result of an accumulation never depends on whether it is deferred,
+// unless it fails: a deferred accumulation that can't be applied is recorded
+// in [TState.DeferFailed] instead of being returned.
+//
+// This must be called before the view is used.
+func (ts *TStateView) SetAccumulators(accumulators []string, deferred bool) {
+	if len(accumulators) == 0 {
+		return
+	}
+	ts.accumulators = make(map[string]struct{}, len(accumulators))
+	for _, k := range accumulators {
+		ts.accumulators[k] = struct{}{}
+	}
+	ts.deferred = deferred
+	if deferred {
+		ts.deltas = make(map[string]uint64, len(accumulators))
+	}
+}
+
+func (ts *TStateView) isAccumulator(key string) bool {
+	_, ok := ts.accumulators[key]
+	return ok
+}
+
+// Accumulate adds [delta] to the accumulator at [key] (a big-endian uint64
+// that is created if it doesn't exist). It requires [state.Allocate] and
+// [state.Write] (whether or not [key] exists) and, unless the accumulation
+// is deferred, fails if [key] doesn't hold a valid accumulator or the result
+// would overflow.
+//
+// Accumulating 0 is a no-op.
+func (ts *TStateView) Accumulate(ctx context.Context, key []byte, delta uint64) error {
+	if !ts.checkScope(ctx, key, state.Allocate|state.Write) {
+		return ErrInvalidKeyOrPermission
+	}
+	if !keys.VerifyValue(key, make([]byte, consts.Uint64Len)) {
+		return ErrInvalidKeyValue
+	}
+	if delta == 0 {
+		return nil
+	}
+	k := string(key)
+	if !ts.isAccumulator(k) || !ts.deferred {
+		v, exists := ts.getValue(ctx, k)
+		current, ok := accumulatorValue(v, exists)
+		if !ok {
+			return ErrInvalidAccumulator
+		}
+		if delta > math.MaxUint64-current {
+			return ErrAccumulatorOverflow
+		}
+		ts.updating = true
+		err := ts.insert(ctx, key, binary.BigEndian.AppendUint64(nil, current+delta))
+		ts.updating = false
+		return err
+	}
+
+	// An overflow of the deltas in the view would also overflow the
+	// accumulator, but it may have failed earlier if not deferred (so the
+	// views must be re-executed)
+	past := ts.deltas[k]
+	if delta > math.MaxUint64-past {
+		ts.ts.deferFailed.Store(true)
+		delta = math.MaxUint64 - past
+	}
+	ts.ops = append(ts.ops, &op{t: accumulateOp, k: k, pastDelta: past})
+	ts.deltas[k] = past + delta
+	return nil
+}
+
+// commitDelta adds [delta] to the accumulator at [key] in the parent view.
+// [ts.ts.l] must be held.
+func (ts *TStateView) commitDelta(key string, delta uint64) {
+	var (
+		v      []byte
+		exists bool
+	)
+	if changed, ok := ts.ts.changedKeys[key]; ok {
+		v, exists = changed.Value(), changed.HasValue()
+	} else {
+		v, exists = ts.scopeStorage[key]
+	}
+	current, ok := accumulatorValue(v, exists)
+	if !ok || delta > math.MaxUint64-current {
+		ts.ts.deferFailed.Store(true)
+		return
+	}
+	ts.ts.changedKeys[key] = maybe.Some(binary.BigEndian.AppendUint64(nil, current+delta))
+}
+
+func accumulatorValue(v []byte, exists bool) (uint64, bool) {
+	if !exists {
+		return 0, true
+	}
+	if len(v) != consts.Uint64Len {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(v), true
+}
@@ -10,4 +10,7 @@ var (
 	ErrInvalidKeyOrPermission = errors.New("invalid key or key permission")
 	ErrInvalidKeyValue        = errors.New("invalid key or value")
 	ErrAllocationDisabled     = errors.New("allocation disabled")
+	ErrAccumulatorKey         = errors.New("key may only be accumulated")
+	ErrInvalidAccumulator     = errors.New("invalid accumulator value")
+	ErrAccumulatorOverflow    = errors.New("accumulator overflow")
 )
@@ -5,7 +5,9 @@ package tstate
 
 import (
 	"context"
+	"maps"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils/maybe"
@@ -22,6 +24,10 @@ type TState struct {
 	l           sync.RWMutex
 	ops         int
 	changedKeys map[string]maybe.Maybe[[]byte]
+
+	// deferFailed is set if a deferred accumulation could not be applied
+	// (see [TStateView.DeferAccumulators])
+	deferFailed atomic.Bool
 }
 
 // New returns a new instance of TState. Initializes the storage and changedKeys
@@ -60,6 +66,22 @@ func (ts *TState) OpIndex() int {
 	return ts.ops
 }
 
+// DeferFailed returns true if an accumulation deferred by a view could not
+// be applied (because it would overflow or the key doesn't hold a valid
+// accumulator). The changes of [TState] are then incomplete, so all views
+// must be re-executed without deferring accumulations.
+func (ts *TState) DeferFailed() bool {
+	return ts.deferFailed.Load()
+}
+
+// ChangedKeys returns a copy of all changes in [TState].
+func (ts *TState) ChangedKeys() map[string]maybe.Maybe[[]byte] {
+	ts.l.RLock()
+	defer ts.l.RUnlock()
+
+	return maps.Clone(ts.changedKeys)
+}
+
 // ExportMerkleDBView creates a slice of [database.BatchOp] of all
 // changes in [TState] that can be used to commit to [merkledb].
 func (ts *TState) ExportMerkleDBView(
@@ -829,11 +829,12 @@ func TestUpdatingKeyPermission(t *testing.T) {
 
 func TestInsertAllocate(t *testing.T) {
 	tests := []struct {
-		name       string
-		key        string
-		permission state.Permissions
-		keyExists  bool
-		shouldFail bool
+		name             string
+		key              string
+		permission       state.Permissions
+		keyExists        bool
+		implicitAllocate bool
+		shouldFail       bool
 	}{
 		// Test if key already exists
 		{
@@ -850,13 +851,21 @@ func TestInsertAllocate(t *testing.T) {
 			keyExists:  true,
 			shouldFail: true,
 		},
+		{
+			name:             "key has RA (implicit allocate)",
+			key:              "test",
+			permission:       state.Read | state.Allocate,
+			keyExists:        true,
+			implicitAllocate: true,
+			shouldFail:       true,
+		},
 		// Test if key doesn't exist
 		{
 			name:       "key has RA",
 			key:        "test",
 			permission: state.Read | state.Allocate,
 			keyExists:  false,
-			shouldFail: true,
+			shouldFail: false,
 		},
 		{
 			name:       "key has RW",
@@ -865,6 +874,22 @@ func TestInsertAllocate(t *testing.T) {
 			keyExists:  false,
 			shouldFail: true,
 		},
+		{
+			name:             "key has RW (implicit allocate)",
+			key:              "test",
+			permission:       state.Read | state.Write,
+			keyExists:        false,
+			implicitAllocate: true,
+			shouldFail:       false,
+		},
+		{
+			name:             "key has R (implicit allocate)",
+			key:              "test",
+			permission:       state.Read,
+			keyExists:        false,
+			implicitAllocate: true,
+			shouldFail:       true,
+		},
 		{
 			name:       "key has RAW",
 			key:        "test",
@@ -901,6 +926,9 @@ func TestInsertAllocate(t *testing.T) {
 			} else {
 				tsv = ts.NewView(keys, map[string][]byte{})
 			}
+			if tt.implicitAllocate {
+				tsv.AllowImplicitAllocate()
+			}
 
 			// Try to update key
 			if tt.shouldFail {
@@ -911,3 +939,82 @@ func TestInsertAllocate(t *testing.T) {
 		})
 	}
 }
+
+// newTestMerkleDBView exports the changes of [txs] transfers (each modifying
+// 2 keys) as a view on an empty [merkledb.MerkleDB].
+func newTestMerkleDBView(t testing.TB, txs int) (merkledb.MerkleDB, merkledb.View) {
+	require := require.New(t)
+
+	ctx := context.TODO()
+	tracer, err := trace.New(&trace.Config{Enabled: false})
+	require.NoError(err)
+	db, err := merkledb.New(ctx, memdb.New(), merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor16,
+		RootGenConcurrency:          1,
+		HistoryLength:               100,
+		ValueNodeCacheSize:          units.MiB,
+		IntermediateNodeCacheSize:   units.MiB,
+		IntermediateWriteBufferSize: units.KiB,
+		IntermediateWriteBatchSize:  units.KiB,
+		Tracer:                      tracer,
+	})
+	require.NoError(err)
+
+	ts := New(txs * 2)
+	for i := 0; i < txs; i++ {
+		from := keys.EncodeChunks(binary.BigEndian.AppendUint64([]byte("from"), uint64(i)), 1)
+		to := keys.EncodeChunks(binary.BigEndian.AppendUint64([]byte("to"), uint64(i)), 1)
+		tsv := ts.NewView(state.Keys{
+			string(from): state.All,
+			string(to):   state.All,
+		}, map[string][]byte{})
+		require.NoError(tsv.Insert(ctx, from, binary.BigEndian.AppendUint64(nil, uint64(i))))
+		require.NoError(tsv.Insert(ctx, to, binary.BigEndian.AppendUint64(nil, uint64(i))))
+		tsv.Commit()
+	}
+	view, err := ts.ExportMerkleDBView(ctx, tracer, db)
+	require.NoError(err)
+	return db, view
+}
+
+func TestCommitMatchesViewRoot(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.TODO()
+	db, view := newTestMerkleDBView(t, 500)
+	expectedRoot, err := view.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	// Ensure the committed state root matches the root of the view
+	require.NoError(view.CommitToDB(ctx))
+	root, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+}
+
+// BenchmarkCommit500Txs compares committing a 500 tx block when the root
+// was not generated beforehand ("rehash", the cost of hashing the changes
+// during Accept) with committing after the root was generated during verify
+// ("precomputed", what Accept does).
+func BenchmarkCommit500Txs(b *testing.B) {
+	for _, precompute := range []bool{false, true} {
+		name := "rehash"
+		if precompute {
+			name = "precomputed"
+		}
+		b.Run(name, func(b *testing.B) {
+			ctx := context.TODO()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				_, view := newTestMerkleDBView(b, 500)
+				if precompute {
+					_, err := view.GetMerkleRoot(ctx)
+					require.NoError(b, err)
+				}
+				b.StartTimer()
+
+				require.NoError(b, view.CommitToDB(ctx))
+			}
+		})
+	}
+}
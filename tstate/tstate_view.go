@@ -19,9 +19,10 @@ const defaultOps = 4
 type opType uint8
 
 const (
-	createOp opType = 0
-	insertOp opType = 1
-	removeOp opType = 2
+	createOp     opType = 0
+	insertOp     opType = 1
+	removeOp     opType = 2
+	accumulateOp opType = 3
 )
 
 type op struct {
@@ -30,6 +31,7 @@ type op struct {
 	pastV         []byte
 	pastAllocates *uint16
 	pastWrites    *uint16
+	pastDelta     uint64
 }
 
 type TStateView struct {
@@ -50,6 +52,20 @@ type TStateView struct {
 	// Store which keys are modified and how large their values were.
 	allocates map[string]uint16
 	writes    map[string]uint16
+
+	// implicitAllocate allows keys with [state.Write] (but not
+	// [state.Allocate]) to be created
+	implicitAllocate bool
+
+	// updating is set while [Insert] or [Remove] modify the view (and is
+	// left set if either panics)
+	updating bool
+
+	// accumulators can only be modified with [Accumulate]. If [deferred] is
+	// set, their [deltas] are added when the view is committed.
+	accumulators map[string]struct{}
+	deferred     bool
+	deltas       map[string]uint64
 }
 
 func (ts *TState) NewView(scope state.Keys, storage map[string][]byte) *TStateView {
@@ -98,6 +114,12 @@ func (ts *TStateView) Rollback(_ context.Context, restorePoint int) {
 				delete(ts.writes, op.k)
 				delete(ts.pendingChangedKeys, op.k)
 			}
+		case accumulateOp:
+			if op.pastDelta == 0 {
+				delete(ts.deltas, op.k)
+			} else {
+				ts.deltas[op.k] = op.pastDelta
+			}
 		case removeOp:
 			if op.pastAllocates != nil {
 				// If we removed a newly created key, we need to restore it
@@ -143,6 +165,14 @@ func (ts *TStateView) KeyOperations() (map[string]uint16, map[string]uint16) {
 	return ts.allocates, ts.writes
 }
 
+// AllowImplicitAllocate allows keys in scope with [state.Write] to be created
+// even if they don't have [state.Allocate]. This must be called before the
+// view is used and is only meant for executing transactions under rules that
+// predate [state.Allocate] enforcement.
+func (ts *TStateView) AllowImplicitAllocate() {
+	ts.implicitAllocate = true
+}
+
 // checkScope returns whether [k] is in scope and has appropriate permissions.
 func (ts *TStateView) checkScope(_ context.Context, k []byte, perm state.Permissions) bool {
 	return ts.scope[string(k)].Has(perm)
@@ -157,6 +187,9 @@ func (ts *TStateView) GetValue(ctx context.Context, key []byte) ([]byte, error)
 		return nil, ErrInvalidKeyOrPermission
 	}
 	k := string(key)
+	if ts.isAccumulator(k) {
+		return nil, ErrAccumulatorKey
+	}
 	v, exists := ts.getValue(ctx, k)
 	if !exists {
 		return nil, database.ErrNotFound
@@ -194,9 +227,24 @@ func (ts *TStateView) isUnchanged(ctx context.Context, key string, nval []byte,
 
 // Insert allocates and writes (or just writes) a new key to [tstate]. If this
 // action returns the value of [key] to the parent view, it reverts any pending changes.
+//
+// Creating a key requires [state.Allocate] (unless [AllowImplicitAllocate]
+// was called, in which case [state.Write] is also sufficient) and modifying
+// an existing key requires [state.Write].
 func (ts *TStateView) Insert(ctx context.Context, key []byte, value []byte) error {
-	// Inserting requires a Write Permissions, so we pass state.Write
-	if !ts.checkScope(ctx, key, state.Write) {
+	if ts.isAccumulator(string(key)) {
+		return ErrAccumulatorKey
+	}
+	ts.updating = true
+	err := ts.insert(ctx, key, value)
+	ts.updating = false
+	return err
+}
+
+func (ts *TStateView) insert(ctx context.Context, key []byte, value []byte) error {
+	// Both [state.Allocate] and [state.Write] include [state.Read], so we
+	// only need to check that the key is in scope before looking it up
+	if !ts.checkScope(ctx, key, state.Read) {
 		return ErrInvalidKeyOrPermission
 	}
 	if !keys.VerifyValue(key, value) {
@@ -204,8 +252,6 @@ func (ts *TStateView) Insert(ctx context.Context, key []byte, value []byte) erro
 	}
 	valueChunks, _ := keys.NumChunks(value) // not possible to fail
 	k := string(key)
-	// Invariant: [getValue] is safe to call here because with [state.Write], it
-	// will provide Read and Write access to the state
 	past, exists := ts.getValue(ctx, k)
 	op := &op{
 		k:             k,
@@ -214,6 +260,10 @@ func (ts *TStateView) Insert(ctx context.Context, key []byte, value []byte) erro
 		pastWrites:    chunks(ts.writes, k),
 	}
 	if exists {
+		// Modifying an existing entry requires Write
+		if !ts.checkScope(ctx, key, state.Write) {
+			return ErrInvalidKeyOrPermission
+		}
 		if bytes.Equal(past, value) {
 			// No change, so this isn't an op.
 			return nil
@@ -222,10 +272,8 @@ func (ts *TStateView) Insert(ctx context.Context, key []byte, value []byte) erro
 		ts.writes[k] = valueChunks // set to latest value
 	} else {
 		// New entry requires Allocate
-		// TODO: we assume any allocate is a write too, but we should
-		// make this invariant more clear. Do we require Write,
-		// Allocate|Write, and never Allocate alone?
-		if !ts.checkScope(ctx, key, state.Allocate) {
+		if !ts.checkScope(ctx, key, state.Allocate) &&
+			!(ts.implicitAllocate && ts.checkScope(ctx, key, state.Write)) {
 			return ErrInvalidKeyOrPermission
 		}
 		op.t = createOp
@@ -246,6 +294,16 @@ func (ts *TStateView) Insert(ctx context.Context, key []byte, value []byte) erro
 // Remove deletes a key from [tstate]. If this action returns the
 // value of [key] to the parent view, it reverts any pending changes.
 func (ts *TStateView) Remove(ctx context.Context, key []byte) error {
+	if ts.isAccumulator(string(key)) {
+		return ErrAccumulatorKey
+	}
+	ts.updating = true
+	err := ts.remove(ctx, key)
+	ts.updating = false
+	return err
+}
+
+func (ts *TStateView) remove(ctx context.Context, key []byte) error {
 	// Removing requires writing & deleting that key, so we pass state.Write
 	if !ts.checkScope(ctx, key, state.Write) {
 		return ErrInvalidKeyOrPermission
@@ -283,12 +341,20 @@ func (ts *TStateView) Remove(ctx context.Context, key []byte) error {
 	return nil
 }
 
+// Interrupted returns true if a panic interrupted [Insert] or [Remove]. The
+// pending changes of the view may then be inconsistent with its operations,
+// so it can't be rolled back (or committed).
+func (ts *TStateView) Interrupted() bool {
+	return ts.updating
+}
+
 // PendingChanges returns the number of changed keys (not ops).
 func (ts *TStateView) PendingChanges() int {
 	return len(ts.pendingChangedKeys)
 }
 
-// Commit adds all pending changes to the parent view.
+// Commit adds all pending changes (and any deferred accumulations) to the
+// parent view.
 func (ts *TStateView) Commit() {
 	ts.ts.l.Lock()
 	defer ts.ts.l.Unlock()
@@ -296,6 +362,9 @@ func (ts *TStateView) Commit() {
 	for k, v := range ts.pendingChangedKeys {
 		ts.ts.changedKeys[k] = v
 	}
+	for k, delta := range ts.deltas {
+		ts.commitDelta(k, delta)
+	}
 	ts.ts.ops += len(ts.ops)
 }
 
@@ -0,0 +1,185 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// acceptWriter appends the writes for an accepted block to the accept batch
+// (and the deletes for a pruned height).
+type acceptWriter struct {
+	name   string
+	accept func(batch database.Batch, blk *chain.StatelessBlock) error
+	prune  func(batch database.Batch, height uint64) error
+}
+
+// acceptWriters returns the side-index writers enabled by [vm.config], in the
+// order they append to the accept batch.
+func (vm *VM) acceptWriters() []acceptWriter {
+	writers := []acceptWriter{}
+	if vm.config.GetStoreTxsByAddress() {
+		writers = append(writers, acceptWriter{
+			name:   "txs by address",
+			accept: vm.indexTxsByAddress,
+			prune:  vm.pruneTxsByAddress,
+		})
+	}
+	if vm.config.GetStoreTxReceipts() {
+		// Receipts are not pruned with the block that executed them
+		writers = append(writers, acceptWriter{
+			name:   "tx receipts",
+			accept: vm.indexTxReceipts,
+		})
+	}
+	if vm.chainChecksums != nil {
+		// Checkpoints are pruned by retention (not with the block)
+		writers = append(writers, acceptWriter{
+			name:   "chain checksum",
+			accept: vm.chainChecksums.accept,
+		})
+	}
+	if vm.stateUsage != nil {
+		// Counters are overwritten (not pruned)
+		writers = append(writers, acceptWriter{
+			name:   "state usage",
+			accept: vm.stateUsage.accept,
+		})
+	}
+	return writers
+}
+
+// newAcceptBatch returns a batch with every write made to [vmDB] when [blk]
+// is accepted: the [lastAccepted] pointer, [blk] and its height indexes, the
+// rows of each [acceptWriter], and the deletes for the block that falls out
+// of the accepted block window (if any, with its height returned).
+//
+// The batch is written once after the changes of [blk] are committed to the
+// accepted state (see [chain.StatelessBlock.Accept]). If the node stops
+// between the two writes, the accepted state is the source of truth: [blk]
+// (persisted by [VM.CommitState]) is recorded as accepted without
+// re-execution on restart and the batch is rebuilt (see
+// [VM.recoverCommittedBlock]).
+func (vm *VM) newAcceptBatch(blk *chain.StatelessBlock) (database.Batch, uint64, error) {
+	var (
+		batch           = vm.vmDB.NewBatch()
+		height          = blk.Height()
+		blkID           = blk.ID()
+		bigEndianHeight = binary.BigEndian.AppendUint64(nil, height)
+		writers         = vm.acceptWriters()
+	)
+	if err := batch.Put(lastAccepted, bigEndianHeight); err != nil {
+		return nil, 0, err
+	}
+	if err := batch.Delete(committedBlock); err != nil {
+		return nil, 0, err
+	}
+	if err := batch.Put(PrefixBlockKey(height), blk.Bytes()); err != nil {
+		return nil, 0, err
+	}
+	if err := batch.Put(PrefixBlockIDHeightKey(blkID), bigEndianHeight); err != nil {
+		return nil, 0, err
+	}
+	if err := batch.Put(PrefixBlockHeightIDKey(height), heightIndexValue(blkID, blk.Tmstmp)); err != nil {
+		return nil, 0, err
+	}
+	for _, w := range writers {
+		if err := w.accept(batch, blk); err != nil {
+			return nil, 0, fmt.Errorf("%w: unable to index %s", err, w.name)
+		}
+	}
+
+	expiryHeight := height - uint64(vm.config.GetAcceptedBlockWindow())
+	if expiryHeight == 0 || expiryHeight >= height { // ensure we don't free genesis
+		return batch, 0, nil
+	}
+	if err := batch.Delete(PrefixBlockKey(expiryHeight)); err != nil {
+		return nil, 0, err
+	}
+	expiredID, err := vm.GetBlockHeightID(expiryHeight)
+	if err == nil {
+		if err := batch.Delete(PrefixBlockIDHeightKey(expiredID)); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		vm.Logger().Warn("unable to delete blkID", zap.Uint64("height", expiryHeight), zap.Error(err))
+	}
+	if err := batch.Delete(PrefixBlockHeightIDKey(expiryHeight)); err != nil {
+		return nil, 0, err
+	}
+	for _, w := range writers {
+		if w.prune == nil {
+			continue
+		}
+		if err := w.prune(batch, expiryHeight); err != nil {
+			return nil, 0, err
+		}
+	}
+	return batch, expiryHeight, nil
+}
+
+// recoverCommittedBlock records the block whose changes were committed to the
+// accepted state before the node stopped (but whose accept batch was never
+// written) as the last accepted block.
+//
+// The accepted state is the source of truth: the block persisted by
+// [VM.CommitState] must be the child of the last accepted block at the height
+// and timestamp of the accepted state. It is not re-executed, so (like a
+// block accepted during state sync) it has no results or
+// [chain.StatelessBlock.StateUsageDiff] and its side indexes are rebuilt from
+// the txs it includes.
+func (vm *VM) recoverCommittedBlock(ctx context.Context) error {
+	syncing, err := vm.GetDiskIsSyncing()
+	if err != nil {
+		return err
+	}
+	if syncing {
+		// The accepted state is not the result of executing accepted blocks
+		return nil
+	}
+	heightRaw, err := vm.stateDB.GetValue(ctx, chain.HeightKey(vm.StateManager().HeightKey()))
+	if errors.Is(err, database.ErrNotFound) {
+		// State sync has not completed
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	stateHeight := binary.BigEndian.Uint64(heightRaw)
+	if stateHeight <= vm.lastAccepted.Hght {
+		return nil
+	}
+	if stateHeight != vm.lastAccepted.Hght+1 {
+		return fmt.Errorf("%w: state height=%d last accepted height=%d", ErrStateAhead, stateHeight, vm.lastAccepted.Hght)
+	}
+	timestampRaw, err := vm.stateDB.GetValue(ctx, chain.TimestampKey(vm.StateManager().TimestampKey()))
+	if err != nil {
+		return err
+	}
+	blkBytes, err := vm.vmDB.Get(committedBlock)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load committed block", err)
+	}
+	blk, err := chain.ParseBlock(ctx, blkBytes, choices.Accepted, vm)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse committed block", err)
+	}
+	if blk.Hght != stateHeight || blk.Prnt != vm.lastAccepted.ID() || blk.Tmstmp != int64(binary.BigEndian.Uint64(timestampRaw)) {
+		return fmt.Errorf("%w: blkID=%s height=%d", ErrInvalidCommittedBlock, blk.ID(), blk.Hght)
+	}
+	vm.Logger().Info("recovered block committed to state before restart",
+		zap.Uint64("height", blk.Hght),
+		zap.Stringer("blkID", blk.ID()),
+	)
+	return vm.UpdateLastAccepted(blk)
+}
@@ -0,0 +1,452 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/cache"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/config"
+	"github.com/ava-labs/hypersdk/network"
+	"github.com/ava-labs/hypersdk/trace"
+	"github.com/ava-labs/hypersdk/workers"
+
+	avatrace "github.com/ava-labs/avalanchego/trace"
+)
+
+var errCrash = errors.New("crash")
+
+// acceptBatchTestConfig stores all side indexes and keeps [window] accepted
+// blocks.
+type acceptBatchTestConfig struct {
+	*config.Config
+
+	window int
+}
+
+func (*acceptBatchTestConfig) GetStoreTxsByAddress() bool       { return true }
+func (*acceptBatchTestConfig) GetStoreTxReceipts() bool         { return true }
+func (c *acceptBatchTestConfig) GetAcceptedBlockWindow() int    { return c.window }
+func (*acceptBatchTestConfig) GetBlockCompactionFrequency() int { return 1 }
+
+// noForkTestController only provides the [chain.Rules] needed to marshal
+// blocks (which activate no forks and disable epochs and results roots).
+type noForkTestController struct {
+	Controller
+}
+
+func (noForkTestController) Rules(int64) chain.Rules { return noForkTestRules{} }
+
+type noForkTestRules struct {
+	chain.Rules
+}
+
+func (noForkTestRules) GetForkActivations() chain.ForkActivations { return nil }
+func (noForkTestRules) GetEpochLength() uint64                    { return 0 }
+func (noForkTestRules) GetIncludeResultsRoot() bool               { return false }
+
+// crashTestDB counts the writes made to a [database.Database] and fails the
+// [failAt]-th one (if non-zero) like a node stopping before it is made.
+//
+// Writes to a batch are only counted once the batch is written.
+type crashTestDB struct {
+	database.Database
+
+	ops    int // puts and deletes (including those added to a batch)
+	writes int // direct puts, deletes, and batch writes
+	failAt int
+}
+
+func (db *crashTestDB) op() error {
+	db.ops++
+	if db.ops == db.failAt {
+		return errCrash
+	}
+	return nil
+}
+
+func (db *crashTestDB) Put(k, v []byte) error {
+	if err := db.op(); err != nil {
+		return err
+	}
+	db.writes++
+	return db.Database.Put(k, v)
+}
+
+func (db *crashTestDB) Delete(k []byte) error {
+	if err := db.op(); err != nil {
+		return err
+	}
+	db.writes++
+	return db.Database.Delete(k)
+}
+
+func (db *crashTestDB) NewBatch() database.Batch {
+	return &crashTestBatch{Batch: db.Database.NewBatch(), db: db}
+}
+
+type crashTestBatch struct {
+	database.Batch
+
+	db *crashTestDB
+}
+
+func (b *crashTestBatch) Put(k, v []byte) error {
+	if err := b.db.op(); err != nil {
+		return err
+	}
+	return b.Batch.Put(k, v)
+}
+
+func (b *crashTestBatch) Delete(k []byte) error {
+	if err := b.db.op(); err != nil {
+		return err
+	}
+	return b.Batch.Delete(k)
+}
+
+func (b *crashTestBatch) Write() error {
+	if err := b.db.op(); err != nil {
+		return err
+	}
+	b.db.writes++
+	return b.Batch.Write()
+}
+
+func newAcceptBatchTestVM(require *require.Assertions, db database.Database, window int) *VM {
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	bByID, _ := cache.NewFIFO[ids.ID, *chain.StatelessBlock](3)
+	bByHeight, _ := cache.NewFIFO[uint64, ids.ID](3)
+	_, m, err := newMetrics()
+	require.NoError(err)
+	return &VM{
+		snowCtx: &snow.Context{Log: logging.NoLog{}},
+		config:  &acceptBatchTestConfig{Config: &config.Config{}, window: window},
+		c:       noForkTestController{},
+
+		vmDB: db,
+
+		tracer:                 tracer,
+		metrics:                m,
+		clock:                  network.NewPeerClock(logging.NoLog{}, 0, 0, prometheus.NewGauge(prometheus.GaugeOpts{})),
+		acceptedBlocksByID:     bByID,
+		acceptedBlocksByHeight: bByHeight,
+		verifiedBlocks:         make(map[ids.ID]*chain.StatelessBlock),
+		maintenance: newMaintenanceCoordinator(
+			logging.NoLog{},
+			memdb.New(),
+			func() bool { return false },
+			m.compactionsRun,
+			m.compactionsDeferred,
+			m.compactionsForced,
+		),
+	}
+}
+
+// newAcceptBatchTestBlocks returns a chain of [count] blocks (after genesis)
+// that each include 2 txs involving [actor].
+func newAcceptBatchTestBlocks(
+	ctx context.Context,
+	require *require.Assertions,
+	ctrl *gomock.Controller,
+	vm chain.VM,
+	count int,
+) []*chain.StatelessBlock {
+	actor := codec.CreateAddress(0, ids.GenerateTestID())
+	auth := chain.NewMockAuth(ctrl)
+	auth.EXPECT().Actor().Return(actor).AnyTimes()
+	auth.EXPECT().Sponsor().Return(actor).AnyTimes()
+
+	genesis, err := chain.ParseStatefulBlock(ctx, chain.NewGenesisBlock(ids.Empty), nil, choices.Accepted, vm)
+	require.NoError(err)
+	blks := []*chain.StatelessBlock{genesis}
+	for i := 1; i <= count; i++ {
+		blk, err := chain.ParseStatefulBlock(ctx, &chain.StatefulBlock{
+			Prnt:   blks[i-1].ID(),
+			Tmstmp: genesis.Tmstmp + int64(i*1_000),
+			Hght:   uint64(i),
+			Txs:    []*chain.Transaction{},
+		}, nil, choices.Accepted, vm)
+		require.NoError(err)
+
+		// Txs are only read by side indexes (not from the bytes of [blk])
+		blk.Txs = []*chain.Transaction{{Auth: auth}, {Auth: auth}}
+		blks = append(blks, blk)
+	}
+	return blks
+}
+
+// dumpDB returns all key/values in [db].
+func dumpDB(require *require.Assertions, db database.Database) map[string][]byte {
+	it := db.NewIterator()
+	defer it.Release()
+
+	kv := map[string][]byte{}
+	for it.Next() {
+		kv[string(it.Key())] = it.Value()
+	}
+	require.NoError(it.Error())
+	return kv
+}
+
+func TestAcceptBatchCrashRecovery(t *testing.T) {
+	const (
+		window = 4
+		count  = 8
+	)
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+
+	// Accept all blocks without crashing
+	expected := newAcceptBatchTestVM(require, memdb.New(), window)
+	blks := newAcceptBatchTestBlocks(ctx, require, ctrl, expected, count)
+	for _, blk := range blks {
+		require.NoError(expected.UpdateLastAccepted(blk))
+	}
+	expectedKV := dumpDB(require, expected.vmDB)
+
+	// Count the writes made to accept the last block (which prunes an
+	// expired block)
+	db := &crashTestDB{Database: memdb.New()}
+	vm := newAcceptBatchTestVM(require, db, window)
+	for _, blk := range blks[:count] {
+		require.NoError(vm.UpdateLastAccepted(blk))
+	}
+	opsBefore, writesBefore := db.ops, db.writes
+	require.NoError(vm.UpdateLastAccepted(blks[count]))
+	ops := db.ops - opsBefore
+	require.Equal(1, db.writes-writesBefore)
+
+	// Crash at each write made while accepting the last block (including the
+	// batch write, which leaves the accepted state ahead of the last accepted
+	// block)
+	for i := 1; i <= ops; i++ {
+		db := &crashTestDB{Database: memdb.New()}
+		vm := newAcceptBatchTestVM(require, db, window)
+		for _, blk := range blks[:count] {
+			require.NoError(vm.UpdateLastAccepted(blk))
+		}
+		before := dumpDB(require, db)
+		db.failAt = db.ops + i
+		require.ErrorIs(vm.UpdateLastAccepted(blks[count]), errCrash)
+
+		// Nothing is written...
+		require.Equal(before, dumpDB(require, db))
+		height, err := vm.GetLastAcceptedHeight()
+		require.NoError(err)
+		require.Equal(uint64(count-1), height)
+		require.Equal(blks[count-1].ID(), vm.lastAccepted.ID())
+
+		// ...so the batch is rebuilt when the block is accepted again on
+		// restart
+		vm = newAcceptBatchTestVM(require, db, window)
+		require.NoError(vm.UpdateLastAccepted(blks[count]))
+		require.Equal(expectedKV, dumpDB(require, db))
+	}
+}
+
+func BenchmarkAcceptBatch(b *testing.B) {
+	const count = 256
+	require := require.New(b)
+	ctx := context.TODO()
+	blks := newAcceptBatchTestBlocks(ctx, require, gomock.NewController(b), newAcceptBatchTestVM(require, memdb.New(), count/4), count)
+
+	var writes int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		db := &crashTestDB{Database: memdb.New()}
+		vm := newAcceptBatchTestVM(require, db, count/4)
+		b.StartTimer()
+		for _, blk := range blks {
+			require.NoError(vm.UpdateLastAccepted(blk))
+		}
+		writes += db.writes
+	}
+	b.ReportMetric(float64(writes)/float64(b.N*len(blks)), "writes/block")
+}
+
+// commitTestStateManager stores the height and timestamp of the accepted
+// state (all other keys are unused).
+type commitTestStateManager struct {
+	chain.StateManager
+}
+
+func (commitTestStateManager) HeightKey() []byte    { return []byte{0x0} }
+func (commitTestStateManager) TimestampKey() []byte { return []byte{0x1} }
+
+// newCommitTestVM returns a VM that stores blocks and the accepted state in
+// [db] (like [newAcceptBatchTestVM] with a state).
+func newCommitTestVM(ctx context.Context, require *require.Assertions, ctrl *gomock.Controller, db database.Database, window int) *VM {
+	vm := newAcceptBatchTestVM(require, prefixdb.New([]byte{0x0}, db), window)
+	stateDB, err := merkledb.New(ctx, prefixdb.New([]byte{0x1}, db), merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor16,
+		RootGenConcurrency:          1,
+		HistoryLength:               100,
+		ValueNodeCacheSize:          units.MiB,
+		IntermediateNodeCacheSize:   units.MiB,
+		IntermediateWriteBufferSize: units.KiB,
+		IntermediateWriteBatchSize:  units.KiB,
+		Tracer:                      avatrace.Noop,
+	})
+	require.NoError(err)
+	c := NewMockController(ctrl)
+	c.EXPECT().StateManager().Return(commitTestStateManager{}).AnyTimes()
+	c.EXPECT().Rules(gomock.Any()).Return(noForkTestRules{}).AnyTimes()
+	vm.c = c
+	vm.stateDB = stateDB
+	vm.authVerifiers = workers.NewSerial() // a recovered block is parsed like a new block
+	return vm
+}
+
+// acceptCommitTestBlock commits the changes of [blk] to the accepted state
+// and then writes its accept batch (like [chain.StatelessBlock.Accept]).
+func acceptCommitTestBlock(ctx context.Context, vm *VM, blk *chain.StatelessBlock) error {
+	sm := vm.StateManager()
+	view, err := vm.stateDB.NewView(ctx, merkledb.ViewChanges{MapOps: map[string]maybe.Maybe[[]byte]{
+		string(chain.HeightKey(sm.HeightKey())):       maybe.Some(binary.BigEndian.AppendUint64(nil, blk.Hght)),
+		string(chain.TimestampKey(sm.TimestampKey())): maybe.Some(binary.BigEndian.AppendUint64(nil, uint64(blk.Tmstmp))),
+	}})
+	if err != nil {
+		return err
+	}
+	if err := vm.CommitState(ctx, blk, view); err != nil {
+		return err
+	}
+	return vm.UpdateLastAccepted(blk)
+}
+
+// restartCommitTestVM loads the last accepted block from [db] (like
+// [VM.Initialize]) after recovering any committed block.
+func restartCommitTestVM(ctx context.Context, require *require.Assertions, ctrl *gomock.Controller, db database.Database, window int) *VM {
+	vm := newCommitTestVM(ctx, require, ctrl, db, window)
+	height, err := vm.GetLastAcceptedHeight()
+	require.NoError(err)
+	vm.lastAccepted, err = vm.GetDiskBlock(ctx, height)
+	require.NoError(err)
+	require.NoError(vm.recoverCommittedBlock(ctx))
+	return vm
+}
+
+func TestCommitCrashRecovery(t *testing.T) {
+	const (
+		window = 4
+		count  = 8
+	)
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+
+	// Txs are not included in the bytes of the blocks, so a block recovered
+	// from disk would index none of them
+	blks := newAcceptBatchTestBlocks(ctx, require, ctrl, newAcceptBatchTestVM(require, memdb.New(), window), count)
+	for _, blk := range blks {
+		blk.Txs = nil
+	}
+
+	// Accept all blocks without crashing
+	db := &crashTestDB{Database: memdb.New()}
+	expected := newCommitTestVM(ctx, require, ctrl, db, window)
+	for _, blk := range blks[:count] {
+		require.NoError(acceptCommitTestBlock(ctx, expected, blk))
+	}
+	opsBefore := db.ops
+	require.NoError(acceptCommitTestBlock(ctx, expected, blks[count]))
+	ops := db.ops - opsBefore
+	expectedKV := dumpDB(require, expected.vmDB)
+	expectedRoot, err := expected.stateDB.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	// Crash at each write made while accepting the last block: before the
+	// block is persisted, before its changes are committed, while they are
+	// committed, and before (or while) the accept batch is written
+	var recovered int
+	for i := 1; i <= ops; i++ {
+		db := &crashTestDB{Database: memdb.New()}
+		vm := newCommitTestVM(ctx, require, ctrl, db, window)
+		for _, blk := range blks[:count] {
+			require.NoError(acceptCommitTestBlock(ctx, vm, blk))
+		}
+		db.failAt = db.ops + i
+		require.ErrorIs(acceptCommitTestBlock(ctx, vm, blks[count]), errCrash)
+
+		// On restart, the last block is either recorded as accepted (if its
+		// changes were committed) or re-delivered by consensus
+		db.failAt = 0
+		vm = restartCommitTestVM(ctx, require, ctrl, db, window)
+		if vm.lastAccepted.Hght == count {
+			recovered++
+		} else {
+			require.Equal(blks[count-1].ID(), vm.lastAccepted.ID())
+			require.NoError(acceptCommitTestBlock(ctx, vm, blks[count]))
+		}
+		require.Equal(blks[count].ID(), vm.lastAccepted.ID())
+		require.Equal(expectedKV, dumpDB(require, vm.vmDB))
+		root, err := vm.stateDB.GetMerkleRoot(ctx)
+		require.NoError(err)
+		require.Equal(expectedRoot, root)
+	}
+	require.Positive(recovered)
+	require.Less(recovered, ops)
+}
+
+func TestRecoverCommittedBlock(t *testing.T) {
+	const window = 4
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+
+	blks := newAcceptBatchTestBlocks(ctx, require, ctrl, newAcceptBatchTestVM(require, memdb.New(), window), 3)
+	newVM := func() (database.Database, *VM) {
+		db := memdb.New()
+		vm := newCommitTestVM(ctx, require, ctrl, db, window)
+		require.NoError(acceptCommitTestBlock(ctx, vm, blks[0]))
+		return db, vm
+	}
+
+	// The accepted state is more than one block ahead of the last accepted
+	// block
+	db, vm := newVM()
+	require.NoError(acceptCommitTestBlock(ctx, vm, blks[1]))
+	require.NoError(vm.vmDB.Put(lastAccepted, binary.BigEndian.AppendUint64(nil, 0)))
+	require.NoError(acceptCommitTestBlock(ctx, vm, blks[2]))
+	require.NoError(vm.vmDB.Put(lastAccepted, binary.BigEndian.AppendUint64(nil, 0)))
+	vm = newCommitTestVM(ctx, require, ctrl, db, window)
+	vm.lastAccepted = blks[0]
+	require.ErrorIs(vm.recoverCommittedBlock(ctx), ErrStateAhead)
+
+	// The persisted block doesn't match the accepted state
+	db, vm = newVM()
+	require.NoError(acceptCommitTestBlock(ctx, vm, blks[1]))
+	require.NoError(vm.vmDB.Put(lastAccepted, binary.BigEndian.AppendUint64(nil, 0)))
+	require.NoError(vm.vmDB.Put(committedBlock, blks[2].Bytes()))
+	vm = newCommitTestVM(ctx, require, ctrl, db, window)
+	vm.lastAccepted = blks[0]
+	require.ErrorIs(vm.recoverCommittedBlock(ctx), ErrInvalidCommittedBlock)
+
+	// Nothing is recovered while state sync is in progress
+	require.NoError(vm.PutDiskIsSyncing(true))
+	require.NoError(vm.recoverCommittedBlock(ctx))
+	require.Equal(blks[0].ID(), vm.lastAccepted.ID())
+}
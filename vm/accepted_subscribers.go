@@ -0,0 +1,113 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// acceptedSubscriptionSize is the number of accepted blocks buffered for each
+// subscriber before blocks are dropped.
+const acceptedSubscriptionSize = 128
+
+// acceptedSubscribers delivers accepted blocks to the subscribers registered
+// with [VM.SubscribeAcceptedBlocks].
+//
+// Blocks are never delivered while holding up the acceptor: if the buffer of
+// a subscriber is full, the block is dropped for that subscriber (and
+// [dropped] is incremented).
+type acceptedSubscribers struct {
+	dropped prometheus.Counter
+
+	l      sync.Mutex
+	subs   map[chan *chain.StatelessBlock]struct{}
+	closed bool
+}
+
+func newAcceptedSubscribers(dropped prometheus.Counter) *acceptedSubscribers {
+	return &acceptedSubscribers{
+		dropped: dropped,
+		subs:    map[chan *chain.StatelessBlock]struct{}{},
+	}
+}
+
+// subscribe registers a new subscriber, which is removed (and its channel
+// closed) when [ctx] is done or the returned function is called.
+func (s *acceptedSubscribers) subscribe(ctx context.Context) (<-chan *chain.StatelessBlock, func()) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	ch := make(chan *chain.StatelessBlock, acceptedSubscriptionSize)
+	if s.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	s.subs[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.l.Lock()
+			defer s.l.Unlock()
+
+			if _, ok := s.subs[ch]; !ok {
+				// Already closed by [close]
+				return
+			}
+			delete(s.subs, ch)
+			close(ch)
+		})
+	}
+	stop := context.AfterFunc(ctx, unsubscribe)
+	return ch, func() {
+		stop()
+		unsubscribe()
+	}
+}
+
+// publish delivers [b] to all subscribers with room in their buffer.
+func (s *acceptedSubscribers) publish(b *chain.StatelessBlock) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	for ch := range s.subs {
+		select {
+		case ch <- b:
+		default:
+			s.dropped.Inc()
+		}
+	}
+}
+
+// close closes the channels of all subscribers. Subscribers registered after
+// close is called receive a closed channel.
+func (s *acceptedSubscribers) close() {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	for ch := range s.subs {
+		close(ch)
+	}
+	clear(s.subs)
+	s.closed = true
+}
+
+// SubscribeAcceptedBlocks returns a channel that receives each block
+// processed after it is accepted (in order) and a function that stops the
+// subscription and closes the channel. The subscription is also stopped when
+// [ctx] is done or the VM shuts down.
+//
+// Up to [acceptedSubscriptionSize] blocks are buffered for each subscriber.
+// The VM never waits for a slow subscriber: once its buffer is full, blocks
+// are dropped (and the "vm_accepted_subscriber_dropped" metric is
+// incremented) until it catches up. Subscribers that can't miss a block
+// should check that heights are contiguous.
+func (vm *VM) SubscribeAcceptedBlocks(ctx context.Context) (<-chan *chain.StatelessBlock, func()) {
+	return vm.acceptedSubscribers.subscribe(ctx)
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+func newAcceptedTestBlock(height uint64) *chain.StatelessBlock {
+	return &chain.StatelessBlock{StatefulBlock: &chain.StatefulBlock{Hght: height}}
+}
+
+func TestAcceptedSubscribers(t *testing.T) {
+	require := require.New(t)
+	dropped := prometheus.NewCounter(prometheus.CounterOpts{Name: "dropped"})
+	s := newAcceptedSubscribers(dropped)
+
+	// Accepted blocks are received in order
+	blocks, cancel := s.subscribe(context.TODO())
+	for height := uint64(1); height <= 3; height++ {
+		s.publish(newAcceptedTestBlock(height))
+	}
+	for height := uint64(1); height <= 3; height++ {
+		blk := <-blocks
+		require.Equal(height, blk.Hght)
+	}
+
+	// Blocks are dropped once the buffer of a slow subscriber is full
+	for height := uint64(4); height <= 4+acceptedSubscriptionSize; height++ {
+		s.publish(newAcceptedTestBlock(height))
+	}
+	require.Equal(float64(1), testutil.ToFloat64(dropped))
+	require.Len(blocks, acceptedSubscriptionSize)
+	require.Equal(uint64(4), (<-blocks).Hght)
+
+	// Canceling closes the channel (after the buffered blocks)
+	cancel()
+	cancel()
+	remaining := 0
+	for range blocks {
+		remaining++
+	}
+	require.Equal(acceptedSubscriptionSize-1, remaining)
+	s.publish(newAcceptedTestBlock(4 + acceptedSubscriptionSize + 1))
+	require.Equal(float64(1), testutil.ToFloat64(dropped))
+}
+
+func TestAcceptedSubscribersClose(t *testing.T) {
+	require := require.New(t)
+	s := newAcceptedSubscribers(prometheus.NewCounter(prometheus.CounterOpts{Name: "dropped"}))
+
+	// Subscriptions end when their context is done
+	ctx, cancelCtx := context.WithCancel(context.TODO())
+	blocks, cancel := s.subscribe(ctx)
+	cancelCtx()
+	_, ok := <-blocks
+	require.False(ok)
+	cancel()
+
+	// ...or the subscribers are closed
+	blocks, cancel = s.subscribe(context.TODO())
+	s.close()
+	_, ok = <-blocks
+	require.False(ok)
+	cancel()
+
+	// Subscribing after close returns a closed channel
+	blocks, cancel = s.subscribe(context.TODO())
+	_, ok = <-blocks
+	require.False(ok)
+	cancel()
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/network"
+)
+
+const (
+	// blockFetchFeatureBit is reserved by the VM (controllers should assign
+	// their features starting from bit 0).
+	blockFetchFeatureBit  = network.MaxFeatures - 1
+	blockFetchFeatureName = "block-fetch"
+)
+
+type fetchDepthKey struct{}
+
+// fetchDepth returns the number of ancestors fetched from peers to verify the
+// block being verified in [ctx].
+func fetchDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(fetchDepthKey{}).(int)
+	return depth
+}
+
+// getBlockBytes returns the bytes of [blkID] to serve to peers.
+func (vm *VM) getBlockBytes(ctx context.Context, blkID ids.ID) ([]byte, error) {
+	blk, err := vm.GetStatelessBlock(ctx, blkID)
+	if err != nil {
+		return nil, err
+	}
+	return blk.Bytes(), nil
+}
+
+// blockFetchPeers returns the peers that serve blocks to [blockFetcher].
+func (vm *VM) blockFetchPeers() []ids.NodeID {
+	peers := []ids.NodeID{}
+	for nodeID, handshake := range vm.handshake.Peers() {
+		if handshake.Features.Has(vm.blockFetchFeature) {
+			peers = append(peers, nodeID)
+		}
+	}
+	return peers
+}
+
+// fetchParent returns [parent] (of a block being verified) when it could not
+// be found locally, either from the blocks we have parsed (but not verified)
+// or by fetching it from peers.
+//
+// Fetched blocks may themselves be missing their parent, so the number of
+// ancestors that can be fetched to verify a single block is limited by
+// [Config.GetParentFetchDepth].
+func (vm *VM) fetchParent(ctx context.Context, parent ids.ID) (*chain.StatelessBlock, int, error) {
+	depth := fetchDepth(ctx)
+	if blk, ok := vm.parsedBlocks.Get(parent); ok {
+		return blk, depth, nil
+	}
+	if depth >= vm.config.GetParentFetchDepth() {
+		return nil, 0, fmt.Errorf("exceeded parent fetch depth (%d)", depth)
+	}
+
+	ctx, span := vm.tracer.Start(ctx, "VM.fetchParent")
+	defer span.End()
+
+	vm.metrics.parentFetchAttempts.Inc()
+	source, err := vm.blockFetcher.Fetch(ctx, parent)
+	if err != nil {
+		return nil, 0, err
+	}
+	blk, err := vm.ParseBlock(ctx, source)
+	if err != nil {
+		return nil, 0, err
+	}
+	vm.metrics.parentFetchSuccesses.Inc()
+	vm.Logger().Info(
+		"fetched missing parent",
+		zap.Stringer("blkID", parent),
+		zap.Int("depth", depth+1),
+	)
+	return blk.(*chain.StatelessBlock), depth + 1, nil
+}
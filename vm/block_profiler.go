@@ -0,0 +1,166 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+const (
+	blockProfileExt = ".pprof"
+	blockSummaryExt = ".json"
+
+	blockProfileDirMode  = 0o755
+	blockProfileFileMode = 0o600
+)
+
+var _ chain.BlockProfiler = (*blockProfiler)(nil)
+
+// blockProfiler collects a CPU profile ("<blkID>.pprof") and a
+// [chain.BlockProfileSummary] ("<blkID>.json") of the verification of the
+// next [remaining] blocks in [dir] (see [VM.ProfileBlocks]).
+//
+// Once the files in [dir] exceed [maxBytes], the oldest are removed (the
+// files of the last profiled block are always kept).
+type blockProfiler struct {
+	log      logging.Logger
+	dir      string
+	maxBytes int64
+
+	remaining atomic.Int64
+
+	// Only one CPU profile can be collected at a time, so blocks verified
+	// while another block is profiled are skipped
+	l       sync.Mutex
+	profile *chain.BlockProfile
+	file    *os.File
+}
+
+func newBlockProfiler(log logging.Logger, dir string, maxBytes int64) *blockProfiler {
+	return &blockProfiler{
+		log:      log,
+		dir:      dir,
+		maxBytes: maxBytes,
+	}
+}
+
+func (p *blockProfiler) Start(b *chain.StatelessBlock) *chain.BlockProfile {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	if p.profile != nil || p.remaining.Load() <= 0 {
+		return nil
+	}
+	if err := os.MkdirAll(p.dir, blockProfileDirMode); err != nil {
+		p.log.Warn("unable to create block profile directory", zap.String("dir", p.dir), zap.Error(err))
+		return nil
+	}
+	path := filepath.Join(p.dir, b.ID().String()+blockProfileExt)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, blockProfileFileMode)
+	if err != nil {
+		p.log.Warn("unable to create block profile", zap.String("path", path), zap.Error(err))
+		return nil
+	}
+
+	// Fails if another CPU profile is being collected (i.e. by the
+	// continuous profiler)
+	if err := pprof.StartCPUProfile(f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		p.log.Warn("unable to start block profile", zap.Stringer("blkID", b.ID()), zap.Error(err))
+		return nil
+	}
+	p.remaining.Add(-1)
+	p.profile = chain.NewBlockProfile(b)
+	p.file = f
+	return p.profile
+}
+
+func (p *blockProfiler) Finish(profile *chain.BlockProfile, err error) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	pprof.StopCPUProfile()
+	if err := p.file.Close(); err != nil {
+		p.log.Warn("unable to write block profile", zap.Stringer("blkID", profile.ID()), zap.Error(err))
+	}
+	p.profile, p.file = nil, nil
+
+	summary := profile.Summary(err)
+	b, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		p.log.Warn("unable to marshal block profile summary", zap.Stringer("blkID", profile.ID()), zap.Error(err))
+		return
+	}
+	path := filepath.Join(p.dir, profile.ID().String()+blockSummaryExt)
+	if err := os.WriteFile(path, b, blockProfileFileMode); err != nil {
+		p.log.Warn("unable to write block profile summary", zap.String("path", path), zap.Error(err))
+	}
+	p.log.Info("profiled block verification",
+		zap.Stringer("blkID", profile.ID()),
+		zap.Uint64("height", summary.Height),
+		zap.Duration("duration", summary.Duration),
+		zap.String("dir", p.dir),
+	)
+	if err := p.prune(profile.ID().String()); err != nil {
+		p.log.Warn("unable to prune block profiles", zap.String("dir", p.dir), zap.Error(err))
+	}
+}
+
+// prune removes the oldest profiles (other than those of [keep]) until the
+// files in [dir] don't exceed [maxBytes].
+func (p *blockProfiler) prune(keep string) error {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return err
+	}
+	var (
+		files = make([]os.FileInfo, 0, len(entries))
+		total int64
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (filepath.Ext(name) != blockProfileExt && filepath.Ext(name) != blockSummaryExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		if strings.TrimSuffix(name, filepath.Ext(name)) == keep {
+			continue
+		}
+		files = append(files, info)
+	}
+	slices.SortFunc(files, func(a, b os.FileInfo) int {
+		if c := a.ModTime().Compare(b.ModTime()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name(), b.Name())
+	})
+	for _, f := range files {
+		if total <= p.maxBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(p.dir, f.Name())); err != nil {
+			return err
+		}
+		total -= f.Size()
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+func newProfileTestBlock(require *require.Assertions, height uint64) *chain.StatelessBlock {
+	blk, err := chain.ParseStatefulBlock(context.TODO(), &chain.StatefulBlock{
+		Hght:      height,
+		StateRoot: ids.GenerateTestID(),
+		Txs:       []*chain.Transaction{},
+	}, nil, choices.Processing, &syncTestVM{})
+	require.NoError(err)
+	return blk
+}
+
+func TestBlockProfiler(t *testing.T) {
+	require := require.New(t)
+
+	// Profiling is disabled without a directory
+	vm := &VM{}
+	require.Nil(vm.BlockProfiler())
+	_, err := vm.ProfileBlocks(1)
+	require.ErrorIs(err, ErrBlockProfilingDisabled)
+
+	// No blocks are profiled until requested
+	dir := t.TempDir()
+	vm.blockProfiler = newBlockProfiler(logging.NoLog{}, dir, 1<<30)
+	require.Nil(vm.BlockProfiler())
+	_, err = vm.ProfileBlocks(-1)
+	require.ErrorIs(err, ErrInvalidProfileCount)
+	profileDir, err := vm.ProfileBlocks(2)
+	require.NoError(err)
+	require.Equal(dir, profileDir)
+
+	// Only one block is profiled at a time
+	blks := []*chain.StatelessBlock{newProfileTestBlock(require, 1), newProfileTestBlock(require, 2), newProfileTestBlock(require, 3)}
+	profiler := vm.BlockProfiler()
+	require.NotNil(profiler)
+	profile := profiler.Start(blks[0])
+	require.NotNil(profile)
+	require.Nil(profiler.Start(blks[1]))
+	profiler.Finish(profile, nil)
+
+	// A CPU profile and a summary are written for the block
+	_, err = os.Stat(filepath.Join(dir, blks[0].ID().String()+blockProfileExt))
+	require.NoError(err)
+	b, err := os.ReadFile(filepath.Join(dir, blks[0].ID().String()+blockSummaryExt))
+	require.NoError(err)
+	var summary chain.BlockProfileSummary
+	require.NoError(json.Unmarshal(b, &summary))
+	require.Equal(blks[0].ID(), summary.BlockID)
+	require.Equal(uint64(1), summary.Height)
+
+	// Profiling stops once [count] blocks are profiled
+	profile = profiler.Start(blks[1])
+	require.NotNil(profile)
+	profiler.Finish(profile, nil)
+	require.Nil(vm.BlockProfiler())
+	require.Nil(profiler.Start(blks[2]))
+	entries, err := os.ReadDir(dir)
+	require.NoError(err)
+	require.Len(entries, 4)
+}
+
+func TestBlockProfilerRetention(t *testing.T) {
+	require := require.New(t)
+
+	// The files of the last profiled block are kept even if they exceed the
+	// limit
+	dir := t.TempDir()
+	p := newBlockProfiler(logging.NoLog{}, dir, 1)
+	p.remaining.Store(2)
+	blks := []*chain.StatelessBlock{newProfileTestBlock(require, 1), newProfileTestBlock(require, 2)}
+	for _, blk := range blks {
+		profile := p.Start(blk)
+		require.NotNil(profile)
+		p.Finish(profile, nil)
+	}
+	entries, err := os.ReadDir(dir)
+	require.NoError(err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.ElementsMatch([]string{
+		blks[1].ID().String() + blockProfileExt,
+		blks[1].ID().String() + blockSummaryExt,
+	}, names)
+}
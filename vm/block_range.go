@@ -0,0 +1,150 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// MaxBlockHeightRange is the maximum number of heights that can be requested
+// from [VM.GetBlocksByHeightRange] at once.
+const MaxBlockHeightRange = 256
+
+// GetBlocksByHeightRange returns the accepted blocks with heights in
+// [from, to] (inclusive) in ascending order of height. This allows explorers
+// to paginate the chain.
+//
+// Only genesis and the last [AcceptedBlockWindow] blocks are stored, so
+// heights that have been pruned are skipped (callers can detect gaps using the
+// heights of the returned blocks). An error is returned if the range is
+// larger than [MaxBlockHeightRange] or ends after the last accepted block.
+func (vm *VM) GetBlocksByHeightRange(ctx context.Context, from uint64, to uint64) ([]*chain.StatelessBlock, error) {
+	ctx, span := vm.tracer.Start(ctx, "VM.GetBlocksByHeightRange")
+	defer span.End()
+
+	if from > to {
+		return nil, fmt.Errorf("%w: from=%d to=%d", ErrInvalidHeightRange, from, to)
+	}
+	if to-from >= MaxBlockHeightRange {
+		return nil, fmt.Errorf("%w: from=%d to=%d (max=%d)", ErrHeightRangeTooLarge, from, to, MaxBlockHeightRange)
+	}
+	if lastAccepted := vm.lastAccepted.Height(); to > lastAccepted {
+		return nil, fmt.Errorf("%w: to=%d lastAccepted=%d", ErrInvalidHeightRange, to, lastAccepted)
+	}
+
+	blks := make([]*chain.StatelessBlock, 0, to-from+1)
+	for i := uint64(0); i <= to-from; i++ {
+		blkID, err := vm.GetBlockIDAtHeight(ctx, from+i)
+		if errors.Is(err, database.ErrNotFound) {
+			continue // pruned
+		}
+		if err != nil {
+			return nil, err
+		}
+		blk, err := vm.GetStatelessBlock(ctx, blkID)
+		if errors.Is(err, database.ErrNotFound) {
+			continue // pruned after the ID was looked up
+		}
+		if err != nil {
+			return nil, err
+		}
+		blks = append(blks, blk)
+	}
+	return blks, nil
+}
+
+// StateRootAt returns the [StateRoot] of the accepted block at [height] (read
+// from the per-height block store), so that light clients can checkpoint the
+// chain without tracking every block.
+//
+// An error is returned if [height] is after the last accepted block or has
+// been pruned (only genesis and the last [AcceptedBlockWindow] blocks are
+// stored).
+func (vm *VM) StateRootAt(ctx context.Context, height uint64) (ids.ID, error) {
+	ctx, span := vm.tracer.Start(ctx, "VM.StateRootAt")
+	defer span.End()
+
+	if lastAccepted := vm.lastAccepted.Height(); height > lastAccepted {
+		return ids.Empty, fmt.Errorf("%w: height=%d lastAccepted=%d", ErrInvalidHeightRange, height, lastAccepted)
+	}
+	blk, err := vm.GetDiskBlock(ctx, height)
+	if errors.Is(err, database.ErrNotFound) {
+		return ids.Empty, fmt.Errorf("%w: height=%d", ErrHeightPruned, height)
+	}
+	if err != nil {
+		return ids.Empty, err
+	}
+	return blk.StateRoot, nil
+}
+
+// GetBlockByTime returns the last accepted block with a timestamp at or
+// before [t] (in milliseconds), searching the timestamps in the height index.
+//
+// Only genesis and the last [AcceptedBlockWindow] blocks are stored, so
+// genesis is returned if [t] is before every other stored block.
+func (vm *VM) GetBlockByTime(ctx context.Context, t int64) (*chain.StatelessBlock, error) {
+	ctx, span := vm.tracer.Start(ctx, "VM.GetBlockByTime")
+	defer span.End()
+
+	if t < vm.genesisBlk.Tmstmp {
+		return nil, fmt.Errorf("%w: t=%d genesis=%d", ErrNoBlockBeforeTime, t, vm.genesisBlk.Tmstmp)
+	}
+	if t >= vm.lastAccepted.Tmstmp {
+		return vm.lastAccepted, nil
+	}
+
+	// Find the oldest stored height (after genesis)
+	low, err := vm.oldestStoredHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the last height in [low, lastAccepted) with a timestamp at or
+	// before [t] (timestamps increase with height)
+	var (
+		high  = vm.lastAccepted.Height()
+		found = vm.genesisBlk.Height()
+	)
+	for low < high {
+		mid := low + (high-low)/2
+		ts, err := vm.GetBlockHeightTimestamp(mid)
+		if err != nil {
+			return nil, err
+		}
+		if ts <= t {
+			found = mid
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	blkID, err := vm.GetBlockIDAtHeight(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+	return vm.GetStatelessBlock(ctx, blkID)
+}
+
+// oldestStoredHeight returns the lowest height (other than genesis) in the
+// height index.
+func (vm *VM) oldestStoredHeight() (uint64, error) {
+	it := vm.vmDB.NewIteratorWithStartAndPrefix(PrefixBlockHeightIDKey(1), []byte{blockHeightIDPrefix})
+	defer it.Release()
+
+	if !it.Next() {
+		if err := it.Error(); err != nil {
+			return 0, err
+		}
+		return vm.lastAccepted.Height(), nil
+	}
+	return binary.BigEndian.Uint64(it.Key()[1:]), nil
+}
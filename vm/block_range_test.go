@@ -0,0 +1,284 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/cache"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/config"
+	"github.com/ava-labs/hypersdk/network"
+	"github.com/ava-labs/hypersdk/trace"
+)
+
+// newBlockRangeTestVM returns a [VM] that accepted a chain of [chainLength]
+// blocks (after genesis) whose heights [1, pruned] are no longer stored.
+func newBlockRangeTestVM(t *testing.T, chainLength int, pruned uint64) (*VM, []*chain.StatelessBlock) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	tracer, _ := trace.New(&trace.Config{Enabled: false})
+	bByID, _ := cache.NewFIFO[ids.ID, *chain.StatelessBlock](3)
+	bByHeight, _ := cache.NewFIFO[uint64, ids.ID](3)
+	_, m, err := newMetrics()
+	require.NoError(err)
+	vm := &VM{
+		snowCtx: &snow.Context{Log: logging.NoLog{}},
+		config:  &config.Config{},
+		c:       noForkTestController{},
+
+		vmDB: memdb.New(),
+
+		tracer:                 tracer,
+		metrics:                m,
+		clock:                  network.NewPeerClock(logging.NoLog{}, 0, 0, prometheus.NewGauge(prometheus.GaugeOpts{})),
+		acceptedBlocksByID:     bByID,
+		acceptedBlocksByHeight: bByHeight,
+		verifiedBlocks:         make(map[ids.ID]*chain.StatelessBlock),
+	}
+
+	// Accept a chain of [chainLength] blocks (only the most recent are
+	// cached, so older blocks are read from disk)
+	genesis, err := chain.ParseStatefulBlock(ctx, chain.NewGenesisBlock(ids.GenerateTestID()), nil, choices.Accepted, vm)
+	require.NoError(err)
+	vm.genesisBlk = genesis
+	blks := []*chain.StatelessBlock{genesis}
+	for i := 1; i <= chainLength; i++ {
+		blk, err := chain.ParseStatefulBlock(ctx, &chain.StatefulBlock{
+			Prnt:      blks[i-1].ID(),
+			Tmstmp:    genesis.Tmstmp + int64(i*1_000),
+			Hght:      uint64(i),
+			Txs:       []*chain.Transaction{},
+			StateRoot: ids.GenerateTestID(),
+		}, nil, choices.Accepted, vm)
+		require.NoError(err)
+		blks = append(blks, blk)
+	}
+	for _, blk := range blks {
+		require.NoError(vm.UpdateLastAccepted(blk))
+	}
+
+	// Prune the oldest blocks (other than genesis)
+	for height := uint64(1); height <= pruned; height++ {
+		require.NoError(vm.vmDB.Delete(PrefixBlockKey(height)))
+		require.NoError(vm.vmDB.Delete(PrefixBlockIDHeightKey(blks[height].ID())))
+		require.NoError(vm.vmDB.Delete(PrefixBlockHeightIDKey(height)))
+	}
+	return vm, blks
+}
+
+func TestGetBlocksByHeightRange(t *testing.T) {
+	const (
+		chainLength = 50
+		pruned      = 10 // heights [1, pruned] are no longer stored
+	)
+	ctx := context.TODO()
+	vm, blks := newBlockRangeTestVM(t, chainLength, pruned)
+
+	tests := []struct {
+		name     string
+		from     uint64
+		to       uint64
+		expected []uint64 // heights
+		err      error
+	}{
+		{
+			name:     "stored range",
+			from:     20,
+			to:       30,
+			expected: []uint64{20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30},
+		},
+		{
+			name:     "single block",
+			from:     chainLength,
+			to:       chainLength,
+			expected: []uint64{chainLength},
+		},
+		{
+			name:     "pruned blocks are skipped",
+			from:     0,
+			to:       pruned + 2,
+			expected: []uint64{0, pruned + 1, pruned + 2},
+		},
+		{
+			name: "entirely pruned",
+			from: 1,
+			to:   pruned,
+		},
+		{
+			name: "reversed range",
+			from: 30,
+			to:   20,
+			err:  ErrInvalidHeightRange,
+		},
+		{
+			name: "after last accepted",
+			from: chainLength - 1,
+			to:   chainLength + 1,
+			err:  ErrInvalidHeightRange,
+		},
+		{
+			name: "too large",
+			from: 0,
+			to:   MaxBlockHeightRange,
+			err:  ErrHeightRangeTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			result, err := vm.GetBlocksByHeightRange(ctx, tt.from, tt.to)
+			require.ErrorIs(err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			require.Len(result, len(tt.expected))
+			for i, height := range tt.expected {
+				require.Equal(height, result[i].Height())
+				require.Equal(blks[height].ID(), result[i].ID())
+			}
+		})
+	}
+
+	// The entire stored chain can be paginated
+	require := require.New(t)
+	var heights []uint64
+	for from := uint64(0); from <= chainLength; from += 16 {
+		result, err := vm.GetBlocksByHeightRange(ctx, from, min(from+15, chainLength))
+		require.NoError(err)
+		for _, blk := range result {
+			heights = append(heights, blk.Height())
+		}
+	}
+	require.Len(heights, chainLength-pruned+1)
+	require.Equal(uint64(0), heights[0])
+	require.Equal(uint64(pruned+1), heights[1])
+	require.Equal(uint64(chainLength), heights[len(heights)-1])
+}
+
+func TestStateRootAt(t *testing.T) {
+	const (
+		chainLength = 20
+		pruned      = 5 // heights [1, pruned] are no longer stored
+	)
+	ctx := context.TODO()
+	vm, blks := newBlockRangeTestVM(t, chainLength, pruned)
+
+	tests := []struct {
+		name   string
+		height uint64
+		err    error
+	}{
+		{
+			name:   "genesis",
+			height: 0,
+		},
+		{
+			name:   "mid-chain",
+			height: chainLength / 2,
+		},
+		{
+			name:   "last accepted",
+			height: chainLength,
+		},
+		{
+			name:   "pruned",
+			height: pruned,
+			err:    ErrHeightPruned,
+		},
+		{
+			name:   "after last accepted",
+			height: chainLength + 1,
+			err:    ErrInvalidHeightRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			root, err := vm.StateRootAt(ctx, tt.height)
+			require.ErrorIs(err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			require.Equal(blks[tt.height].StateRoot, root)
+		})
+	}
+}
+
+func TestGetBlockByTime(t *testing.T) {
+	const (
+		chainLength = 20
+		pruned      = 5 // heights [1, pruned] are no longer stored
+	)
+	ctx := context.TODO()
+	vm, blks := newBlockRangeTestVM(t, chainLength, pruned)
+	genesis := blks[0].Tmstmp
+
+	tests := []struct {
+		name     string
+		t        int64
+		expected uint64 // height
+		err      error
+	}{
+		{
+			name:     "exact timestamp",
+			t:        blks[10].Tmstmp,
+			expected: 10,
+		},
+		{
+			name:     "between blocks",
+			t:        blks[10].Tmstmp + 500,
+			expected: 10,
+		},
+		{
+			name:     "oldest stored",
+			t:        blks[pruned+1].Tmstmp,
+			expected: pruned + 1,
+		},
+		{
+			name:     "pruned",
+			t:        blks[pruned].Tmstmp,
+			expected: 0,
+		},
+		{
+			name:     "genesis",
+			t:        genesis,
+			expected: 0,
+		},
+		{
+			name:     "after last accepted",
+			t:        blks[chainLength].Tmstmp + 1,
+			expected: chainLength,
+		},
+		{
+			name: "before genesis",
+			t:    genesis - 1,
+			err:  ErrNoBlockBeforeTime,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			blk, err := vm.GetBlockByTime(ctx, tt.t)
+			require.ErrorIs(err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			require.Equal(blks[tt.expected].ID(), blk.ID())
+		})
+	}
+}
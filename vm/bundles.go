@@ -0,0 +1,75 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/network"
+)
+
+const (
+	// bundleGossipFeatureBit is reserved by the VM (see [blockFetchFeatureBit]).
+	bundleGossipFeatureBit  = network.MaxFeatures - 2
+	bundleGossipFeatureName = "bundle-gossip"
+)
+
+// txBundle returns the transactions of the [chain.Bundle] [tx] was submitted
+// in (or nil if it wasn't submitted in one).
+func txBundle(tx *chain.Transaction) []*chain.Transaction {
+	if bundle := tx.Bundle(); bundle != nil {
+		return bundle.Txs
+	}
+	return nil
+}
+
+// SubmitBundle adds all transactions in [bundle] to the mempool (as a unit) if
+// they all pass the checks performed by [Submit], or none of them.
+func (vm *VM) SubmitBundle(ctx context.Context, verifyAuth bool, bundle *chain.Bundle) error {
+	ctx, span := vm.tracer.Start(ctx, "VM.SubmitBundle")
+	defer span.End()
+	vm.metrics.txsSubmitted.Add(float64(len(bundle.Txs)))
+
+	_, errs := vm.checkTxs(ctx, verifyAuth, bundle.Txs)
+	if len(errs) != len(bundle.Txs) {
+		return errs[0]
+	}
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("%w: tx %s", err, bundle.Txs[i].ID())
+		}
+	}
+	vm.addTxs(ctx, bundle.Txs)
+
+	// The mempool drops the bundle if it can't add all of its transactions
+	if !vm.mempool.Has(ctx, bundle.Txs[0].ID()) {
+		return ErrNotAdded
+	}
+	return nil
+}
+
+// SendBundleGossip sends [msg] (marshaled [chain.Bundle]s) to the peers in
+// [nodeIDs] that advertise [bundleGossipFeature] (or to all connected peers
+// that advertise it if [nodeIDs] is empty).
+func (vm *VM) SendBundleGossip(ctx context.Context, nodeIDs set.Set[ids.NodeID], msg []byte) error {
+	recipients := set.NewSet[ids.NodeID](nodeIDs.Len())
+	for nodeID, handshake := range vm.handshake.Peers() {
+		if nodeIDs.Len() > 0 && !nodeIDs.Contains(nodeID) {
+			continue
+		}
+		if handshake.Features.Has(vm.bundleGossipFeature) {
+			recipients.Add(nodeID)
+		}
+	}
+	if recipients.Len() == 0 {
+		return nil
+	}
+	return vm.bundleGossipSender.SendAppGossip(ctx, common.SendConfig{NodeIDs: recipients}, msg)
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/rpc"
+)
+
+// MaxReportedCheckpoints is the number of (most recent) checkpoints included
+// in a [rpc.ChainChecksumReport].
+const MaxReportedCheckpoints = 16
+
+const chainChecksumLen = consts.Uint64Len + consts.BoolLen + ids.IDLen
+
+var chainChecksumKey = []byte("chain_checksum")
+
+func PrefixChainCheckpointKey(height uint64) []byte {
+	k := make([]byte, 1+consts.Uint64Len)
+	k[0] = chainCheckpointPrefix
+	binary.BigEndian.PutUint64(k[1:], height)
+	return k
+}
+
+// ChainChecksum returns hash([prev] || [blkID] || [stateRoot]).
+func ChainChecksum(prev ids.ID, blkID ids.ID, stateRoot ids.ID) ids.ID {
+	b := make([]byte, 0, 3*ids.IDLen)
+	b = append(b, prev[:]...)
+	b = append(b, blkID[:]...)
+	b = append(b, stateRoot[:]...)
+	return hashing.ComputeHash256Array(b)
+}
+
+// chainChecksums maintains a rolling [ChainChecksum] of the accepted chain,
+// which allows operators to cheaply compare the accepted chains of two nodes
+// (see [rpc.FindChainDivergence]). It is node-local and not part of
+// consensus.
+//
+// The checksum restarts (from [ids.Empty]) at the first block after each
+// multiple of [interval], and the checksum of each multiple of [interval] is
+// persisted as a checkpoint (the last [retention] are kept). This allows nodes
+// that state synced to different heights to produce the same checkpoints from
+// the first interval both accepted in full. Since each block ID commits to its
+// parent, two chains that diverge also differ at every later checkpoint.
+//
+// The checksum and checkpoints are written in the accept batch (so they are
+// always written with the last accepted block).
+type chainChecksums struct {
+	interval  uint64
+	retention uint64
+	db        database.Database
+
+	l        sync.RWMutex
+	height   uint64 // height of the last accepted block applied
+	checksum ids.ID
+	complete bool // false until the first block of an interval is applied
+}
+
+// newChainChecksums loads the checksum persisted in [db] (if any).
+func newChainChecksums(interval uint64, retention uint64, db database.Database) (*chainChecksums, error) {
+	c := &chainChecksums{
+		interval:  interval,
+		retention: retention,
+		db:        db,
+	}
+	v, err := db.Get(chainChecksumKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(v) != chainChecksumLen {
+		return nil, fmt.Errorf("%w: chain checksum length=%d", ErrCorruptIndex, len(v))
+	}
+	c.height = binary.BigEndian.Uint64(v)
+	c.complete = v[consts.Uint64Len] == 0x1
+	c.checksum = ids.ID(v[consts.Uint64Len+consts.BoolLen:])
+	return c, nil
+}
+
+func (c *chainChecksums) bytes() []byte {
+	v := make([]byte, 0, chainChecksumLen)
+	v = binary.BigEndian.AppendUint64(v, c.height)
+	if c.complete {
+		v = append(v, 0x1)
+	} else {
+		v = append(v, 0x0)
+	}
+	return append(v, c.checksum[:]...)
+}
+
+// accept rolls [blk] into the checksum and adds it (and the checkpoint of
+// [blk], if any) to [batch].
+//
+// The checksum is incomplete if the parent of [blk] was not applied (i.e. the
+// node state synced or checksums were just enabled) until the next interval
+// starts.
+func (c *chainChecksums) accept(batch database.Batch, blk *chain.StatelessBlock) error {
+	height := blk.Height()
+	if height == 0 {
+		return nil
+	}
+
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	prev := c.checksum
+	complete := c.complete && c.height+1 == height
+	if (height-1)%c.interval == 0 {
+		prev = ids.Empty
+		complete = true
+	}
+	c.height = height
+	c.checksum = ChainChecksum(prev, blk.ID(), blk.StateRoot)
+	c.complete = complete
+	if err := batch.Put(chainChecksumKey, c.bytes()); err != nil {
+		return err
+	}
+	if !complete || height%c.interval != 0 {
+		return nil
+	}
+	if err := batch.Put(PrefixChainCheckpointKey(height), c.checksum[:]); err != nil {
+		return err
+	}
+	if expired := c.retention * c.interval; height > expired {
+		return batch.Delete(PrefixChainCheckpointKey(height - expired))
+	}
+	return nil
+}
+
+// checkpoint returns the checkpoint at [height] (or [database.ErrNotFound] if
+// it was not recorded or is no longer retained).
+func (c *chainChecksums) checkpoint(height uint64) (ids.ID, error) {
+	v, err := c.db.Get(PrefixChainCheckpointKey(height))
+	if err != nil {
+		return ids.Empty, err
+	}
+	if len(v) != ids.IDLen {
+		return ids.Empty, fmt.Errorf("%w: checkpoint length=%d", ErrCorruptIndex, len(v))
+	}
+	return ids.ID(v), nil
+}
+
+// report returns the current checksum, the range of retained checkpoints,
+// and the last [MaxReportedCheckpoints] checkpoints.
+func (c *chainChecksums) report() (*rpc.ChainChecksumReport, error) {
+	c.l.RLock()
+	r := &rpc.ChainChecksumReport{
+		Interval: c.interval,
+		Height:   c.height,
+		Checksum: c.checksum,
+		Complete: c.complete,
+	}
+	c.l.RUnlock()
+
+	it := c.db.NewIteratorWithPrefix([]byte{chainCheckpointPrefix})
+	defer it.Release()
+	checkpoints := []*rpc.ChainCheckpoint{}
+	for it.Next() {
+		k, v := it.Key(), it.Value()
+		if len(k) != 1+consts.Uint64Len || len(v) != ids.IDLen {
+			return nil, fmt.Errorf("%w: checkpoint key length=%d value length=%d", ErrCorruptIndex, len(k), len(v))
+		}
+		checkpoints = append(checkpoints, &rpc.ChainCheckpoint{
+			Height:   binary.BigEndian.Uint64(k[1:]),
+			Checksum: ids.ID(v),
+		})
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if len(checkpoints) > 0 {
+		r.OldestCheckpoint = checkpoints[0].Height
+		r.LatestCheckpoint = checkpoints[len(checkpoints)-1].Height
+	}
+	r.Checkpoints = checkpoints[max(len(checkpoints)-MaxReportedCheckpoints, 0):]
+	return r, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+func newChainChecksumTestBlock(height uint64) *chain.StatelessBlock {
+	blk := newAcceptedTestBlock(height)
+	blk.StateRoot = ids.ID{byte(height), byte(height >> 8)}
+	return blk
+}
+
+// acceptChainChecksums applies blocks [from, to] to [c] (writing each batch).
+func acceptChainChecksums(require *require.Assertions, c *chainChecksums, from uint64, to uint64) {
+	for height := from; height <= to; height++ {
+		batch := c.db.NewBatch()
+		require.NoError(c.accept(batch, newChainChecksumTestBlock(height)))
+		require.NoError(batch.Write())
+	}
+}
+
+func TestChainChecksums(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	c, err := newChainChecksums(4, 2, db)
+	require.NoError(err)
+	acceptChainChecksums(require, c, 0, 10)
+
+	// The checksum restarts after each multiple of the interval
+	expected := ids.Empty
+	for height := uint64(9); height <= 10; height++ {
+		blk := newChainChecksumTestBlock(height)
+		expected = ChainChecksum(expected, blk.ID(), blk.StateRoot)
+	}
+	r, err := c.report()
+	require.NoError(err)
+	require.Equal(uint64(10), r.Height)
+	require.Equal(expected, r.Checksum)
+	require.True(r.Complete)
+
+	// Only the last [retention] checkpoints are kept
+	require.Equal(uint64(4), r.OldestCheckpoint)
+	require.Equal(uint64(8), r.LatestCheckpoint)
+	require.Len(r.Checkpoints, 2)
+	_, err = c.checkpoint(0)
+	require.ErrorIs(err, database.ErrNotFound)
+	checkpoint, err := c.checkpoint(8)
+	require.NoError(err)
+	require.Equal(r.Checkpoints[1].Checksum, checkpoint)
+
+	// The checksum is reloaded from disk
+	reloaded, err := newChainChecksums(4, 2, db)
+	require.NoError(err)
+	reloadedReport, err := reloaded.report()
+	require.NoError(err)
+	require.Equal(r, reloadedReport)
+}
+
+func TestChainChecksumsGap(t *testing.T) {
+	require := require.New(t)
+
+	// Nodes that start at different heights produce the same checkpoints from
+	// the first interval both applied in full
+	full, err := newChainChecksums(4, 8, memdb.New())
+	require.NoError(err)
+	acceptChainChecksums(require, full, 1, 12)
+	synced, err := newChainChecksums(4, 8, memdb.New())
+	require.NoError(err)
+	acceptChainChecksums(require, synced, 6, 12)
+
+	r, err := synced.report()
+	require.NoError(err)
+	require.Equal(uint64(12), r.OldestCheckpoint)
+	fullCheckpoint, err := full.checkpoint(12)
+	require.NoError(err)
+	syncedCheckpoint, err := synced.checkpoint(12)
+	require.NoError(err)
+	require.Equal(fullCheckpoint, syncedCheckpoint)
+
+	// A gap leaves the checksum incomplete until the next interval starts
+	acceptChainChecksums(require, synced, 14, 14)
+	r, err = synced.report()
+	require.NoError(err)
+	require.False(r.Complete)
+	acceptChainChecksums(require, synced, 15, 17)
+	r, err = synced.report()
+	require.NoError(err)
+	require.True(r.Complete)
+	require.Equal(uint64(12), r.LatestCheckpoint)
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/list"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+// deferredVerificationBatch is the max number of pending transactions
+// verified by a single job.
+const deferredVerificationBatch = 256
+
+// deferredVerifier holds submitted transactions (oldest first) until their
+// auth is verified by [workers]. Transactions that pass verification are
+// passed to [submit] (which adds them to the mempool) and those that fail (or
+// are evicted when more than [maxSize] are pending) are passed to [drop].
+//
+// [workers] are separate from those used to verify blocks, so that
+// verification of submitted transactions is best-effort.
+type deferredVerifier struct {
+	log     logging.Logger
+	workers workers.Workers
+	maxSize int
+
+	verify func(context.Context, *chain.Transaction) error
+	submit func(context.Context, []*chain.Transaction) []error
+	drop   func(ids.ID, error)
+
+	size    prometheus.Gauge
+	evicted prometheus.Counter
+	failed  prometheus.Counter
+
+	l       sync.Mutex
+	pending list.List[*chain.Transaction]
+	elems   map[ids.ID]*list.Element[*chain.Transaction]
+
+	ready chan struct{}
+	done  chan struct{}
+}
+
+func newDeferredVerifier(
+	log logging.Logger,
+	workers workers.Workers,
+	maxSize int,
+	verify func(context.Context, *chain.Transaction) error,
+	submit func(context.Context, []*chain.Transaction) []error,
+	drop func(ids.ID, error),
+	size prometheus.Gauge,
+	evicted, failed prometheus.Counter,
+) *deferredVerifier {
+	return &deferredVerifier{
+		log:     log,
+		workers: workers,
+		maxSize: maxSize,
+		verify:  verify,
+		submit:  submit,
+		drop:    drop,
+		size:    size,
+		evicted: evicted,
+		failed:  failed,
+		elems:   map[ids.ID]*list.Element[*chain.Transaction]{},
+		ready:   make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// Add queues [tx] for verification. It returns false if [tx] is already
+// pending.
+//
+// If more than [maxSize] transactions are pending, the oldest is dropped with
+// [ErrEvictedBeforeVerification].
+func (d *deferredVerifier) Add(tx *chain.Transaction) bool {
+	d.l.Lock()
+	txID := tx.ID()
+	if _, ok := d.elems[txID]; ok {
+		d.l.Unlock()
+		return false
+	}
+	d.elems[txID] = d.pending.PushBack(tx)
+	var evicted *chain.Transaction
+	if d.pending.Size() > d.maxSize {
+		evicted = d.pending.Remove(d.pending.First())
+		delete(d.elems, evicted.ID())
+	}
+	d.size.Set(float64(d.pending.Size()))
+	d.l.Unlock()
+
+	if evicted != nil {
+		d.evicted.Inc()
+		d.drop(evicted.ID(), ErrEvictedBeforeVerification)
+	}
+	select {
+	case d.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Has returns true if [txID] is waiting to be verified.
+func (d *deferredVerifier) Has(txID ids.ID) bool {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	_, ok := d.elems[txID]
+	return ok
+}
+
+func (d *deferredVerifier) Len() int {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	return d.pending.Size()
+}
+
+// pop removes up to [limit] of the oldest pending transactions.
+func (d *deferredVerifier) pop(limit int) []*chain.Transaction {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	txs := make([]*chain.Transaction, 0, min(limit, d.pending.Size()))
+	for len(txs) < limit && d.pending.Size() > 0 {
+		tx := d.pending.Remove(d.pending.First())
+		delete(d.elems, tx.ID())
+		txs = append(txs, tx)
+	}
+	d.size.Set(float64(d.pending.Size()))
+	return txs
+}
+
+// Verify verifies the auth of up to [deferredVerificationBatch] pending
+// transactions and returns the number of transactions verified.
+func (d *deferredVerifier) Verify(ctx context.Context) (int, error) {
+	txs := d.pop(deferredVerificationBatch)
+	if len(txs) == 0 {
+		return 0, nil
+	}
+	job, err := d.workers.NewJob(len(txs))
+	if err != nil {
+		return 0, err
+	}
+	errs := make([]error, len(txs))
+	for i, tx := range txs {
+		i, tx := i, tx
+		job.Go(func() error {
+			// A failure is recorded instead of returned so that it doesn't
+			// stop the verification of other transactions
+			errs[i] = d.verify(ctx, tx)
+			return nil
+		})
+	}
+	job.Done(nil)
+	if err := job.Wait(); err != nil {
+		return 0, err
+	}
+
+	verified := make([]*chain.Transaction, 0, len(txs))
+	for i, tx := range txs {
+		if errs[i] != nil {
+			d.failed.Inc()
+			d.drop(tx.ID(), errs[i])
+			continue
+		}
+		verified = append(verified, tx)
+	}
+	if len(verified) > 0 {
+		d.submit(ctx, verified)
+	}
+	return len(txs), nil
+}
+
+// Run verifies pending transactions as they are added until [stop] is
+// closed.
+func (d *deferredVerifier) Run(stop <-chan struct{}) {
+	defer close(d.done)
+
+	ctx := context.Background()
+	for {
+		select {
+		case <-d.ready:
+		case <-stop:
+			return
+		}
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			n, err := d.Verify(ctx)
+			if err != nil {
+				d.log.Error("unable to verify pending txs", zap.Error(err))
+				return
+			}
+			if n == 0 {
+				break
+			}
+		}
+	}
+}
+
+// Done returns a channel that is closed once [Run] returns.
+func (d *deferredVerifier) Done() <-chan struct{} {
+	return d.done
+}
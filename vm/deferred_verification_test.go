@@ -0,0 +1,223 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/workers"
+)
+
+var errTestInvalidAuth = errors.New("invalid auth")
+
+// deferredTestRecorder records the txs submitted and dropped by a
+// [deferredVerifier] (txs in [invalid] fail verification).
+type deferredTestRecorder struct {
+	invalid   map[ids.ID]bool
+	submitted []ids.ID
+	dropped   map[ids.ID]error
+}
+
+func newTestDeferredVerifier(maxSize int) (*deferredVerifier, *deferredTestRecorder) {
+	r := &deferredTestRecorder{invalid: map[ids.ID]bool{}, dropped: map[ids.ID]error{}}
+	d := newDeferredVerifier(
+		logging.NoLog{},
+		workers.NewSerial(),
+		maxSize,
+		func(_ context.Context, tx *chain.Transaction) error {
+			if r.invalid[tx.ID()] {
+				return errTestInvalidAuth
+			}
+			return nil
+		},
+		func(_ context.Context, txs []*chain.Transaction) []error {
+			for _, tx := range txs {
+				r.submitted = append(r.submitted, tx.ID())
+			}
+			return make([]error, len(txs))
+		},
+		func(txID ids.ID, err error) { r.dropped[txID] = err },
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "size"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "evicted"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "failed"}),
+	)
+	return d, r
+}
+
+func TestDeferredVerifierEviction(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	d, r := newTestDeferredVerifier(2)
+
+	txs := []*chain.Transaction{
+		newLatencyTestTx(t, ctrl),
+		newLatencyTestTx(t, ctrl),
+		newLatencyTestTx(t, ctrl),
+	}
+	require.True(d.Add(txs[0]))
+	require.True(d.Add(txs[1]))
+	require.False(d.Add(txs[1]))
+	require.Equal(2, d.Len())
+	require.Empty(r.dropped)
+
+	// The oldest tx is evicted when full
+	require.True(d.Add(txs[2]))
+	require.Equal(2, d.Len())
+	require.False(d.Has(txs[0].ID()))
+	require.True(d.Has(txs[2].ID()))
+	require.Equal(map[ids.ID]error{txs[0].ID(): ErrEvictedBeforeVerification}, r.dropped)
+	require.Equal(float64(1), testutil.ToFloat64(d.evicted))
+	require.Equal(float64(2), testutil.ToFloat64(d.size))
+}
+
+func TestDeferredVerifierVerify(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	d, r := newTestDeferredVerifier(deferredVerificationBatch + 1)
+
+	var (
+		valid   []ids.ID
+		invalid = newLatencyTestTx(t, ctrl)
+	)
+	r.invalid[invalid.ID()] = true
+	require.True(d.Add(invalid))
+	for i := 0; i < deferredVerificationBatch; i++ {
+		tx := newLatencyTestTx(t, ctrl)
+		require.True(d.Add(tx))
+		valid = append(valid, tx.ID())
+	}
+
+	// Txs are verified oldest first (in batches) and only valid txs are
+	// submitted
+	n, err := d.Verify(ctx)
+	require.NoError(err)
+	require.Equal(deferredVerificationBatch, n)
+	require.Equal(valid[:deferredVerificationBatch-1], r.submitted)
+	require.Equal(map[ids.ID]error{invalid.ID(): errTestInvalidAuth}, r.dropped)
+	require.Equal(float64(1), testutil.ToFloat64(d.failed))
+	require.Equal(1, d.Len())
+
+	n, err = d.Verify(ctx)
+	require.NoError(err)
+	require.Equal(1, n)
+	require.Equal(valid, r.submitted)
+	require.Zero(d.Len())
+
+	// Nothing left to verify
+	n, err = d.Verify(ctx)
+	require.NoError(err)
+	require.Zero(n)
+}
+
+// submitLatencyP99 returns the p99 latency of calling [submit] with each of
+// [txs] at [tps] (from a single handler).
+func submitLatencyP99(txs []*chain.Transaction, tps int, submit func(*chain.Transaction)) time.Duration {
+	var (
+		interval  = time.Second / time.Duration(tps)
+		start     = time.Now()
+		latencies = make([]time.Duration, 0, len(txs))
+	)
+	for i, tx := range txs {
+		time.Sleep(time.Until(start.Add(time.Duration(i) * interval)))
+		submitted := time.Now()
+		submit(tx)
+		latencies = append(latencies, time.Since(submitted))
+	}
+	slices.Sort(latencies)
+	return latencies[len(latencies)*99/100]
+}
+
+func TestDeferredVerifierSubmitLatency(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping load test")
+	}
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	const (
+		tps = 5_000
+		n   = tps // 1s of load
+	)
+	txs := make([]*chain.Transaction, n)
+	for i := range txs {
+		txs[i] = newLatencyTestTx(t, ctrl)
+	}
+
+	// Verification costs as much as an ed25519 signature
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(err)
+	msg := []byte("msg")
+	sig := ed25519.Sign(priv, msg)
+	verify := func(context.Context, *chain.Transaction) error {
+		if !ed25519.Verify(pub, msg, sig) {
+			return errTestInvalidAuth
+		}
+		return nil
+	}
+
+	// Verifying inline delays the response to the submitter
+	ctx := context.TODO()
+	inline := submitLatencyP99(txs, tps, func(tx *chain.Transaction) {
+		require.NoError(verify(ctx, tx))
+	})
+
+	// Deferring verification does not
+	var (
+		l         sync.Mutex
+		submitted int
+		dropped   int
+	)
+	d := newDeferredVerifier(
+		logging.NoLog{},
+		workers.NewParallel(2, 100),
+		n,
+		verify,
+		func(_ context.Context, txs []*chain.Transaction) []error {
+			l.Lock()
+			defer l.Unlock()
+			submitted += len(txs)
+			return make([]error, len(txs))
+		},
+		func(ids.ID, error) {
+			l.Lock()
+			defer l.Unlock()
+			dropped++
+		},
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "size"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "evicted"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "failed"}),
+	)
+	stop := make(chan struct{})
+	go d.Run(stop)
+	deferred := submitLatencyP99(txs, tps, func(tx *chain.Transaction) {
+		require.True(d.Add(tx))
+	})
+	t.Logf("p99 submit latency at %d TPS: inline=%s deferred=%s", tps, inline, deferred)
+	require.Less(deferred, inline)
+
+	// All txs are eventually submitted
+	require.Eventually(func() bool {
+		l.Lock()
+		defer l.Unlock()
+		return submitted+dropped == n
+	}, 10*time.Second, 10*time.Millisecond)
+	close(stop)
+	<-d.Done()
+	d.workers.Stop()
+	require.Zero(dropped)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/profiler"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
@@ -17,6 +18,9 @@ import (
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/gossiper"
+	"github.com/ava-labs/hypersdk/mempool"
+	"github.com/ava-labs/hypersdk/network"
+	"github.com/ava-labs/hypersdk/rpc"
 	"github.com/ava-labs/hypersdk/state"
 	"github.com/ava-labs/hypersdk/trace"
 
@@ -46,6 +50,20 @@ type Config interface {
 	GetStateSyncParallelism() int
 	GetStateSyncMinBlocks() uint64
 	GetStateSyncServerDelay() time.Duration
+	// GetStateSyncServerConcurrency is the maximum number of state sync
+	// requests served at once (0 is unbounded).
+	GetStateSyncServerConcurrency() int
+	// GetStateSyncServerPeerQuota is the maximum number of state sync requests
+	// from a single peer served at once (0 is unbounded).
+	GetStateSyncServerPeerQuota() int
+	// GetStateSyncServerMaxDeferral is the longest state sync requests are
+	// deferred (answered with [StateSyncBusyCode]) while a block is built or
+	// verified (0 never defers).
+	GetStateSyncServerMaxDeferral() time.Duration
+	// GetStateSyncAcceptGracePeriod is how long accepting a block waits for a
+	// completed sync to update the last accepted block before failing (0 waits
+	// indefinitely).
+	GetStateSyncAcceptGracePeriod() time.Duration
 	GetParsedBlockCacheSize() int
 	GetAcceptedBlockWindow() int
 	GetAcceptedBlockWindowCache() int
@@ -54,6 +72,121 @@ type Config interface {
 	GetProcessingBuildSkip() int
 	GetTargetGossipDuration() time.Duration
 	GetBlockCompactionFrequency() int
+	GetStoreTxsByAddress() bool // maintain an index of accepted txs by involved address
+	GetStoreTxReceipts() bool   // maintain a receipt (units and fee paid) for each accepted tx
+
+	// GetMempoolReservations enables rejecting transactions whose max fee (and
+	// hinted value spent, see [chain.ValueSpender]) can't be paid in addition
+	// to that reserved by all transactions from the same payer already in the
+	// mempool.
+	GetMempoolReservations() bool
+
+	GetMempoolMaxBytes() int // how many bytes of transactions to keep in the mempool (0 is unlimited)
+
+	// GetMempoolEviction determines which transactions are evicted to make
+	// room for new transactions when the mempool is full (by weighing their
+	// max fee per byte, age, and size). By default, new transactions are
+	// dropped while the mempool is full.
+	GetMempoolEviction() *mempool.EvictionConfig
+
+	// GetLogLevels overrides the log level of individual components (like
+	// "chain.verify" or "mempool"). Levels can be changed without a restart
+	// with [VM.SetLogLevels] or [GetLogLevelsFile].
+	GetLogLevels() map[string]logging.Level
+	// GetLogLevelsFile is the path of a JSON file whose "logLevels" (in the
+	// format of [GetLogLevels]) replace the overrides whenever it is modified.
+	// This can be the chain config file itself.
+	GetLogLevelsFile() string
+
+	// GetAdminAPI enables RPC methods that modify the node (like setting
+	// log levels).
+	GetAdminAPI() bool
+
+	// GetShadowRootVerification enables computing the state root of each
+	// verified block with a secondary [chain.RootComputer] and logging any
+	// divergence (the primary root is always used for consensus).
+	//
+	// This is meant to be enabled on a single canary node when upgrading
+	// merkledb. It roughly doubles the work spent computing roots (performed
+	// asynchronously after verify) and holds a copy of each block's changes
+	// until the shadow root is computed. Compare the "chain_shadow_root_calculated"
+	// and "chain_root_calculated" metrics to measure the overhead.
+	//
+	// The [Controller] must implement [ShadowRootProvider] to enable it.
+	GetShadowRootVerification() bool
+
+	// GetCatchUpIncrementalRoots enables committing the changes of each block
+	// verified while bootstrapping (which is always accepted before its child
+	// is verified) to the accepted state during verification, so that its
+	// root is computed incrementally instead of on a new view. This is
+	// ignored if shadow root verification is enabled.
+	GetCatchUpIncrementalRoots() bool
+
+	// GetRecordKeyAccesses enables recording how many txs declared read and
+	// write access to each state key when a block is executed (see
+	// [chain.KeyAccesses]). This is a debug option for finding contended keys
+	// and adds overhead to execution.
+	GetRecordKeyAccesses() bool
+
+	// GetBlockProfileDir is the directory that CPU profiles and summaries of
+	// block verification are written to when requested with
+	// [VM.ProfileBlocks] (empty disables profiling). Once the files in the
+	// directory exceed GetBlockProfileMaxBytes, the oldest are removed.
+	GetBlockProfileDir() string
+	GetBlockProfileMaxBytes() int64
+
+	// GetRPCNamespaces replaces the config (enable flag, auth requirements,
+	// and rate limit) of the JSON-RPC namespaces with the same names (see
+	// [RPCNamespaceProvider]). Naming a namespace that isn't registered fails
+	// [VM.Initialize].
+	GetRPCNamespaces() map[string]rpc.NamespaceConfig
+
+	GetPriorityLaneSize() int            // how many priority lane txs to keep in the mempool
+	GetPriorityLaneUnitsPercent() uint64 // percent of each block dimension reserved for priority lane txs
+
+	// GetParentFetchDepth is the number of missing ancestors that can be
+	// fetched from peers to verify a single block (0 disables fetching).
+	GetParentFetchDepth() int
+	GetParentFetchRetries() int           // how many peers to request a missing block from
+	GetParentFetchTimeout() time.Duration // how long to wait for each peer to respond
+
+	// GetDeferredVerificationSize is the number of transactions submitted
+	// over RPC (or received over gossip) that can wait for their auth to be
+	// verified in the background (0 verifies auth before responding to the
+	// submitter or handling the gossip). When full, the oldest transaction is
+	// dropped.
+	GetDeferredVerificationSize() int
+	GetDeferredVerificationCores() int // how many cores to use for deferred auth verification
+
+	// GetMaxClockCorrection bounds the correction applied to the local clock
+	// when building and verifying blocks, which is the median offset of the
+	// clocks of peers (0 disables correction). This limits how far a Sybil of
+	// skewed peers can drag the local clock from real time.
+	GetMaxClockCorrection() time.Duration
+	GetClockSkewThreshold() time.Duration // log if the local clock is skewed from peers by more than this
+
+	// GetAuthOffloadEndpoints are the gRPC addresses of sidecars (serving
+	// offload/offload.proto) that verify the signatures of blocks built by
+	// other nodes (empty disables offloading). A batch is verified locally if
+	// no sidecar supporting its auth type is healthy or it isn't answered
+	// within the timeout.
+	GetAuthOffloadEndpoints() []string
+	GetAuthOffloadTimeout() time.Duration        // how long to wait for a sidecar to verify a batch
+	GetAuthOffloadHealthInterval() time.Duration // how often to check the auth types supported by each sidecar
+	GetAuthOffloadBatchSize() int                // signatures sent to a sidecar in each call
+
+	// GetStoreStateUsage maintains approximate usage counters (keys and bytes)
+	// for each state prefix registered by the [Controller] (see
+	// [StatePrefixProvider]). GetRebuildStateUsage recomputes them from the
+	// full state on startup (in the background).
+	GetStoreStateUsage() bool
+	GetRebuildStateUsage() bool
+
+	// GetChainChecksumInterval is how often (in blocks) the rolling checksum
+	// of the accepted chain is checkpointed (0 disables the checksum).
+	// GetChainChecksumRetention is the number of checkpoints kept.
+	GetChainChecksumInterval() uint64
+	GetChainChecksumRetention() uint64
 }
 
 type Genesis interface {
@@ -67,6 +200,62 @@ type AuthEngine interface {
 	Cache(auth chain.Auth)
 }
 
+// AddressIndexer may optionally be implemented by a [Controller] to report
+// addresses involved in a transaction beyond its actor and sponsor
+// (i.e. the recipient of a transfer) for the txs-by-address index.
+type AddressIndexer interface {
+	ResultAddresses(tx *chain.Transaction, result *chain.Result) []codec.Address
+}
+
+// ShadowRootProvider may optionally be implemented by a [Controller] to
+// provide the secondary [chain.RootComputer] used when shadow root
+// verification is enabled (i.e. one backed by the previous merkledb version).
+// Shadow root verification can't be enabled if it is not implemented.
+type ShadowRootProvider interface {
+	ShadowRootComputer() chain.RootComputer
+}
+
+// PriorityLaneProvider may optionally be implemented by a [Controller] to
+// register a [chain.PriorityLane] for protocol-critical transactions. Matching
+// transactions are held in a separate mempool lane (bounded by
+// [Config.GetPriorityLaneSize]) and are built ahead of all others.
+type PriorityLaneProvider interface {
+	PriorityLane() *chain.PriorityLane
+}
+
+// FeatureProvider may optionally be implemented by a [Controller] to register
+// protocol features (like new wire formats) that are advertised to peers in
+// the [network.Handshake].
+//
+// The last bit ([network.MaxFeatures]-1) is reserved by the VM.
+type FeatureProvider interface {
+	RegisterFeatures(*network.FeatureRegistry) error
+}
+
+// ForkProvider may optionally be implemented by a [Controller] to register
+// the [chain.Fork]s it gates behavior on (in the order they must activate).
+// Scheduling a fork that is not registered is rejected during
+// [VM.Initialize].
+type ForkProvider interface {
+	RegisterForks(*chain.ForkRegistry) error
+}
+
+// StatePrefixProvider may optionally be implemented by a [Controller] to name
+// the state prefixes reported by the getStateUsage RPC (when
+// [Config.GetStoreStateUsage] is enabled). Keys that don't match a registered
+// prefix are reported together.
+type StatePrefixProvider interface {
+	RegisterStatePrefixes(*chain.StatePrefixRegistry) error
+}
+
+// RPCNamespaceProvider may optionally be implemented by a [Controller] to
+// serve JSON-RPC namespaces (like separate public and admin services) on
+// [rpc.JSONRPCEndpoint] alongside the [rpc.Name] namespace. The config of
+// each namespace can be replaced with [Config.GetRPCNamespaces].
+type RPCNamespaceProvider interface {
+	RegisterRPCNamespaces(*rpc.Router) error
+}
+
 type Controller interface {
 	Initialize(
 		inner *VM, // hypersdk VM
@@ -15,4 +15,24 @@ var (
 	ErrStateSyncing        = errors.New("state still syncing")
 	ErrUnexpectedStateRoot = errors.New("unexpected state root")
 	ErrTooManyProcessing   = errors.New("too many processing")
+	ErrCorruptIndex        = errors.New("corrupt index")
+
+	ErrInsufficientProjectedBalance = errors.New("insufficient projected balance")
+	ErrUnknownLogComponent          = errors.New("unknown log component")
+	ErrEvictedBeforeVerification    = errors.New("evicted before verification")
+	ErrPreviewRateLimited           = errors.New("build preview rate limited")
+	ErrBuildInProgress              = errors.New("block build in progress")
+	ErrInvalidHeightRange           = errors.New("invalid height range")
+	ErrHeightRangeTooLarge          = errors.New("height range too large")
+	ErrHeightPruned                 = errors.New("height pruned")
+	ErrSchemaTooNew                 = errors.New("database schema is newer than supported")
+	ErrNoBlockBeforeTime            = errors.New("no stored block at or before time")
+	ErrBlockProfilingDisabled       = errors.New("block profiling disabled")
+	ErrInvalidProfileCount          = errors.New("invalid profile count")
+	ErrUnknownRPCNamespace          = errors.New("unknown rpc namespace")
+	ErrStateSyncServerBusy          = errors.New("state sync server busy")
+	ErrStateAhead                   = errors.New("accepted state ahead of last accepted block")
+	ErrInvalidCommittedBlock        = errors.New("invalid committed block")
+	ErrStaleSnapshot                = errors.New("stale state snapshot")
+	ErrNoShadowRootComputer         = errors.New("shadow root verification requires a ShadowRootProvider")
 )
@@ -0,0 +1,43 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// executionContextCacheSize is the number of [chain.ExecutionContext]s to keep
+// in memory. Siblings are built and verified with the same parent and a small
+// set of timestamps, so this does not need to be large.
+const executionContextCacheSize = 128
+
+// executionContextKey uniquely identifies a [chain.ExecutionContext].
+//
+// We key by the exact timestamp (rather than by second) so that a context is
+// never reused across a change in [chain.Rules] (which may activate at any
+// millisecond).
+type executionContextKey struct {
+	parent    ids.ID
+	timestamp int64
+}
+
+func (vm *VM) GetExecutionContext(
+	parent ids.ID,
+	parentFees []byte,
+	parentTimestamp int64,
+	timestamp int64,
+) (*chain.ExecutionContext, error) {
+	key := executionContextKey{parent, timestamp}
+	if ectx, ok := vm.executionContexts.Get(key); ok {
+		return ectx, nil
+	}
+	ectx, err := chain.GenerateExecutionContext(parentFees, parentTimestamp, timestamp, vm.Rules(timestamp))
+	if err != nil {
+		return nil, err
+	}
+	vm.executionContexts.Put(key, ectx)
+	return ectx, nil
+}
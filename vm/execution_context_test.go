@@ -0,0 +1,95 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"testing"
+
+	avacache "github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/fees"
+	"github.com/ava-labs/hypersdk/window"
+)
+
+func TestExecutionContextCache(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	// Rules change exactly when the fee window rolls over
+	boundary := int64(window.WindowSize * consts.MillisecondsPerSecond)
+	newRules := func(minUnitPrice uint64) *chain.MockRules {
+		r := chain.NewMockRules(ctrl)
+		r.EXPECT().GetWindowTargetUnits().Return(fees.Dimensions{100, 100, 100, 100, 100}).AnyTimes()
+		r.EXPECT().GetUnitPriceChangeDenominator().Return(fees.Dimensions{2, 2, 2, 2, 2}).AnyTimes()
+		r.EXPECT().GetMinUnitPrice().Return(fees.Dimensions{minUnitPrice, minUnitPrice, minUnitPrice, minUnitPrice, minUnitPrice}).AnyTimes()
+		r.EXPECT().GetPricingMode().Return(fees.WindowPricing).AnyTimes()
+		r.EXPECT().GetTargetBlockRate().Return(int64(0)).AnyTimes()
+		r.EXPECT().GetBlockCostChangeDenominator().Return(uint64(48)).AnyTimes()
+		r.EXPECT().GetMinBlockCost().Return(uint64(0)).AnyTimes()
+		r.EXPECT().GetMaxBlockCost().Return(uint64(0)).AnyTimes()
+		return r
+	}
+	before := newRules(1)
+	after := newRules(50)
+	rules := func(t int64) chain.Rules {
+		if t < boundary {
+			return before
+		}
+		return after
+	}
+	c := NewMockController(ctrl)
+	c.EXPECT().Rules(gomock.Any()).DoAndReturn(rules).AnyTimes()
+	vm := VM{
+		c:                 c,
+		executionContexts: &avacache.LRU[executionContextKey, *chain.ExecutionContext]{Size: executionContextCacheSize},
+	}
+
+	// Parent consumed more than the target in each dimension
+	parentFeeManager := fees.NewManager(nil)
+	for i := fees.Dimension(0); i < fees.FeeDimensions; i++ {
+		parentFeeManager.SetUnitPrice(i, 10)
+		parentFeeManager.SetLastConsumed(i, 1_000)
+	}
+	parentFees := parentFeeManager.Bytes()
+	parent := ids.GenerateTestID()
+
+	for _, timestamp := range []int64{boundary - 1, boundary, boundary + 1} {
+		cached, err := vm.GetExecutionContext(parent, parentFees, 0, timestamp)
+		require.NoError(err)
+		again, err := vm.GetExecutionContext(parent, parentFees, 0, timestamp)
+		require.NoError(err)
+		require.Same(cached, again)
+
+		fresh, err := chain.GenerateExecutionContext(parentFees, 0, timestamp, rules(timestamp))
+		require.NoError(err)
+		require.Equal(fresh.Timestamp, cached.Timestamp)
+		require.Equal(fresh.Rules, cached.Rules)
+		require.Equal(fresh.FeeManager().Bytes(), cached.FeeManager().Bytes())
+	}
+
+	// Contexts on either side of the boundary use different rules
+	beforeCtx, err := vm.GetExecutionContext(parent, parentFees, 0, boundary-1)
+	require.NoError(err)
+	require.Equal(before, beforeCtx.Rules)
+	afterCtx, err := vm.GetExecutionContext(parent, parentFees, 0, boundary)
+	require.NoError(err)
+	require.Equal(after, afterCtx.Rules)
+	require.Equal(uint64(50), afterCtx.FeeManager().UnitPrice(fees.Bandwidth))
+
+	// Siblings with another parent don't share a context
+	other, err := vm.GetExecutionContext(ids.GenerateTestID(), fees.NewManager(nil).Bytes(), 0, boundary)
+	require.NoError(err)
+	require.NotSame(afterCtx, other)
+
+	// Consuming units doesn't modify the cached context
+	feeManager := afterCtx.FeeManager()
+	ok, _ := feeManager.Consume(fees.Dimensions{1, 1, 1, 1, 1}, fees.Dimensions{10, 10, 10, 10, 10})
+	require.True(ok)
+	require.Equal(fees.Dimensions{}, afterCtx.FeeManager().UnitsConsumed())
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"encoding/hex"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// hotKeysLogged is the number of most accessed keys logged each time a block
+// is executed (if key accesses are recorded).
+const hotKeysLogged = 8
+
+var _ chain.KeyAccessRecorder = (*keyAccessRecorder)(nil)
+
+// keyAccessRecorder logs the most accessed keys of each executed block and
+// records how many txs wrote the hottest one.
+type keyAccessRecorder struct {
+	log           logging.Logger
+	hottestWrites prometheus.Gauge
+}
+
+func (r *keyAccessRecorder) RecordKeyAccesses(accesses *chain.KeyAccesses) {
+	hottest := accesses.Hottest(hotKeysLogged)
+	if len(hottest) == 0 {
+		r.hottestWrites.Set(0)
+		return
+	}
+	r.hottestWrites.Set(float64(hottest[0].Writes))
+	for _, access := range hottest {
+		r.log.Debug("key accessed",
+			zap.String("key", hex.EncodeToString([]byte(access.Key))),
+			zap.Int("reads", access.Reads),
+			zap.Int("writes", access.Writes),
+			zap.Int("txs", accesses.Txs()),
+		)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+)
+
+// Components whose log level can be set with [Config.GetLogLevels].
+//
+// Components without a configured level log at the level of [VMLogComponent]
+// (which is set by the Controller with [snow.Context.Log.SetLevel]).
+const (
+	VMLogComponent      = "vm"
+	VerifyLogComponent  = "chain.verify"
+	MempoolLogComponent = "mempool"
+	GossipLogComponent  = "gossip"
+	SyncLogComponent    = "sync"
+	RPCLogComponent     = "rpc"
+)
+
+// logLevelsReloadInterval is how often [Config.GetLogLevelsFile] is checked
+// for changes.
+const logLevelsReloadInterval = 5 * time.Second
+
+var logComponents = []string{
+	VMLogComponent,
+	VerifyLogComponent,
+	MempoolLogComponent,
+	GossipLogComponent,
+	SyncLogComponent,
+	RPCLogComponent,
+}
+
+// loggers creates a logger for each component that writes to the outputs of
+// a single base logger.
+//
+// Each component logger has its own core (and level), so changing the level
+// of a component never changes the level of the base logger (or of any other
+// component). [VMLogComponent] is the base logger itself.
+type loggers struct {
+	base       logging.Logger
+	components map[string]logging.Logger
+
+	// [l] serializes level changes and protects [modTime] (the modification
+	// time of the last file read by [Reload])
+	l       sync.Mutex
+	modTime time.Time
+}
+
+func newLoggers(base logging.Logger, chainID ids.ID) *loggers {
+	l := &loggers{
+		base:       base,
+		components: make(map[string]logging.Logger, len(logComponents)),
+	}
+	defaultLevel := enabledLevel(base)
+	for _, name := range logComponents {
+		if name == VMLogComponent {
+			l.components[name] = base
+			continue
+		}
+		prefix := logging.Plain.WrapPrefix(fmt.Sprintf("%s %s", chainID, name))
+		core := logging.NewWrappedCore(defaultLevel, baseWriter{base}, logging.Plain.FileEncoder())
+		l.components[name] = logging.NewLogger(prefix, core)
+	}
+	return l
+}
+
+// baseWriter writes pre-formatted messages to all outputs of a logger. It is
+// never closed by a component logger (the base logger is stopped by its
+// owner).
+type baseWriter struct {
+	logging.Logger
+}
+
+func (baseWriter) Close() error { return nil }
+
+// enabledLevel returns the most verbose level enabled on [log].
+func enabledLevel(log logging.Logger) logging.Level {
+	for lvl := logging.Verbo; lvl < logging.Off; lvl++ {
+		if log.Enabled(lvl) {
+			return lvl
+		}
+	}
+	return logging.Off
+}
+
+// Get returns the logger for [component] (which must be one of
+// [logComponents]).
+func (l *loggers) Get(component string) logging.Logger {
+	return l.components[component]
+}
+
+// SetLevels replaces the levels of all components other than
+// [VMLogComponent]. Components not included in [levels] log at the current
+// level of [VMLogComponent].
+func (l *loggers) SetLevels(levels map[string]logging.Level) error {
+	for name := range levels {
+		if _, ok := l.components[name]; !ok || name == VMLogComponent {
+			return fmt.Errorf("%w: %s", ErrUnknownLogComponent, name)
+		}
+	}
+
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	defaultLevel := enabledLevel(l.base)
+	for name, log := range l.components {
+		if name == VMLogComponent {
+			continue
+		}
+		lvl, ok := levels[name]
+		if !ok {
+			lvl = defaultLevel
+		}
+		log.SetLevel(lvl)
+	}
+	return nil
+}
+
+// Levels returns the level of each component.
+func (l *loggers) Levels() map[string]logging.Level {
+	levels := make(map[string]logging.Level, len(l.components))
+	for name, log := range l.components {
+		levels[name] = enabledLevel(log)
+	}
+	return levels
+}
+
+// logLevelsFile is the subset of the VM config read by [Reload].
+type logLevelsFile struct {
+	LogLevels map[string]logging.Level `json:"logLevels"`
+}
+
+// Reload applies the "logLevels" of the JSON file at [path] if it was
+// modified since the last call. It returns true if the levels were changed.
+func (l *loggers) Reload(path string) (bool, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	// An invalid file is only reported once (until it is modified again)
+	l.l.Lock()
+	modified := !fi.ModTime().Equal(l.modTime)
+	l.modTime = fi.ModTime()
+	l.l.Unlock()
+	if !modified {
+		return false, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	var f logLevelsFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return false, err
+	}
+	if err := l.SetLevels(f.LogLevels); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Watch calls [Reload] every [logLevelsReloadInterval] until [stop] is
+// closed.
+func (l *loggers) Watch(path string, stop <-chan struct{}) {
+	t := time.NewTicker(logLevelsReloadInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			changed, err := l.Reload(path)
+			if err != nil {
+				l.base.Warn("unable to reload log levels", zap.String("path", path), zap.Error(err))
+				continue
+			}
+			if changed {
+				l.base.Info("reloaded log levels", zap.String("path", path), zap.Any("levels", l.Levels()))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+// bufferWriter records the lines written by a logger.
+type bufferWriter struct {
+	bytes.Buffer
+}
+
+func (*bufferWriter) Close() error { return nil }
+
+// lines returns (and clears) the lines written since the last call.
+func (b *bufferWriter) lines() []string {
+	s := strings.TrimSpace(b.String())
+	b.Reset()
+	if len(s) == 0 {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func newTestLoggers(lvl logging.Level) (*loggers, *bufferWriter) {
+	w := &bufferWriter{}
+	base := logging.NewLogger("", logging.NewWrappedCore(lvl, w, logging.Plain.FileEncoder()))
+	return newLoggers(base, ids.Empty), w
+}
+
+func requireMessages(t *testing.T, w *bufferWriter, msgs ...string) {
+	lines := w.lines()
+	require.Len(t, lines, len(msgs), lines)
+	for i, msg := range msgs {
+		require.Contains(t, lines[i], msg)
+		// The caller is the call site (not a wrapper)
+		require.Contains(t, lines[i], "loggers_test.go")
+	}
+}
+
+func TestLoggersLevelIsolation(t *testing.T) {
+	require := require.New(t)
+	l, w := newTestLoggers(logging.Info)
+	vmLog := l.Get(VMLogComponent)
+	verifyLog := l.Get(VerifyLogComponent)
+	mempoolLog := l.Get(MempoolLogComponent)
+
+	// Components inherit the level of the base logger
+	verifyLog.Info("verify info")
+	mempoolLog.Debug("mempool debug")
+	requireMessages(t, w, "verify info")
+
+	// Components are configured independently (without changing the level
+	// of the base logger)
+	require.NoError(l.SetLevels(map[string]logging.Level{
+		VerifyLogComponent:  logging.Warn,
+		MempoolLogComponent: logging.Debug,
+	}))
+	require.False(vmLog.Enabled(logging.Debug))
+	verifyLog.Info("verify info")
+	verifyLog.Warn("verify warn")
+	mempoolLog.Debug("mempool debug")
+	vmLog.Debug("vm debug")
+	vmLog.Info("vm info")
+	l.Get(GossipLogComponent).Debug("gossip debug")
+	requireMessages(t, w, "verify warn", "mempool debug", "vm info")
+	require.False(verifyLog.Enabled(logging.Info))
+	require.True(mempoolLog.Enabled(logging.Debug))
+	require.Equal(map[string]logging.Level{
+		VMLogComponent:      logging.Info,
+		VerifyLogComponent:  logging.Warn,
+		MempoolLogComponent: logging.Debug,
+		GossipLogComponent:  logging.Info,
+		SyncLogComponent:    logging.Info,
+		RPCLogComponent:     logging.Info,
+	}, l.Levels())
+
+	// Components without an override follow the level of the VM
+	vmLog.SetLevel(logging.Warn)
+	require.NoError(l.SetLevels(map[string]logging.Level{MempoolLogComponent: logging.Debug}))
+	l.Get(RPCLogComponent).Info("rpc info")
+	verifyLog.Info("verify info")
+	mempoolLog.Debug("mempool debug")
+	requireMessages(t, w, "mempool debug")
+	require.Equal(logging.Warn, l.Levels()[RPCLogComponent])
+
+	// Overrides can be removed without a restart
+	require.NoError(l.SetLevels(nil))
+	mempoolLog.Debug("mempool debug")
+	require.Empty(w.lines())
+
+	// Unknown components are rejected
+	require.ErrorIs(l.SetLevels(map[string]logging.Level{"unknown": logging.Debug}), ErrUnknownLogComponent)
+	require.ErrorIs(l.SetLevels(map[string]logging.Level{VMLogComponent: logging.Debug}), ErrUnknownLogComponent)
+}
+
+func TestLoggersReload(t *testing.T) {
+	require := require.New(t)
+	l, w := newTestLoggers(logging.Info)
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(config string, modTime time.Time) {
+		require.NoError(os.WriteFile(path, []byte(config), 0o600))
+		require.NoError(os.Chtimes(path, modTime, modTime))
+	}
+	now := time.Now()
+
+	// Other fields of the config are ignored
+	write(`{"logLevel":"info","logLevels":{"chain.verify":"warn","mempool":"debug"}}`, now)
+	changed, err := l.Reload(path)
+	require.NoError(err)
+	require.True(changed)
+	l.Get(VerifyLogComponent).Info("verify info")
+	l.Get(MempoolLogComponent).Debug("mempool debug")
+	requireMessages(t, w, "mempool debug")
+
+	// The file is only applied when it is modified
+	changed, err = l.Reload(path)
+	require.NoError(err)
+	require.False(changed)
+
+	write(`{"logLevels":{"mempool":"warn"}}`, now.Add(time.Second))
+	changed, err = l.Reload(path)
+	require.NoError(err)
+	require.True(changed)
+	l.Get(VerifyLogComponent).Info("verify info")
+	l.Get(MempoolLogComponent).Debug("mempool debug")
+	requireMessages(t, w, "verify info")
+
+	// Invalid files leave the levels unchanged
+	write(`{"logLevels":{"unknown":"debug"}}`, now.Add(2*time.Second))
+	_, err = l.Reload(path)
+	require.ErrorIs(err, ErrUnknownLogComponent)
+	require.Equal(logging.Warn, l.Levels()[MempoolLogComponent])
+}
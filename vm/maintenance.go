@@ -0,0 +1,160 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// maintenanceInterval is how often we check if deferred compactions can
+	// be run.
+	maintenanceInterval = 100 * time.Millisecond
+
+	// maintenanceIdleGap is how long the VM must not have built, verified, or
+	// accepted a block before it is considered idle.
+	maintenanceIdleGap = 250 * time.Millisecond
+
+	// maxCompactionDeferral is the longest a compaction is deferred before it
+	// is run even if the VM is busy.
+	maxCompactionDeferral = 30 * time.Second
+)
+
+// Compacter is implemented by databases that support manual compaction of a
+// key range (like [database.Database]).
+type Compacter interface {
+	Compact(start []byte, limit []byte) error
+}
+
+// maintenanceCoordinator defers manual compactions of [db] until the VM is
+// idle (it has not built, verified, or accepted a block for
+// [maintenanceIdleGap] and [idle] returns true).
+type maintenanceCoordinator struct {
+	log  logging.Logger
+	db   Compacter
+	idle func() bool
+
+	run      prometheus.Counter
+	deferred prometheus.Counter
+	forced   prometheus.Counter
+
+	l          sync.Mutex
+	active     int
+	lastActive time.Time
+
+	// pending is the limit of the range to compact by its start (ranges with
+	// the same start are merged)
+	pending      map[string][]byte
+	pendingSince time.Time
+}
+
+func newMaintenanceCoordinator(
+	log logging.Logger,
+	db Compacter,
+	idle func() bool,
+	run, deferred, forced prometheus.Counter,
+) *maintenanceCoordinator {
+	return &maintenanceCoordinator{
+		log:      log,
+		db:       db,
+		idle:     idle,
+		run:      run,
+		deferred: deferred,
+		forced:   forced,
+		pending:  map[string][]byte{},
+	}
+}
+
+// Begin marks the VM as busy until the returned function is called.
+func (c *maintenanceCoordinator) Begin() func() {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.active++
+	return func() {
+		c.l.Lock()
+		defer c.l.Unlock()
+
+		c.active--
+		c.lastActive = time.Now()
+	}
+}
+
+// ShouldDefer returns true if scheduled compactions are currently deferred.
+func (c *maintenanceCoordinator) ShouldDefer() bool {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.shouldDefer()
+}
+
+func (c *maintenanceCoordinator) shouldDefer() bool {
+	return c.active > 0 || time.Since(c.lastActive) < maintenanceIdleGap || !c.idle()
+}
+
+// Schedule queues a compaction of [start, limit) to be run once the VM is
+// idle.
+func (c *maintenanceCoordinator) Schedule(start []byte, limit []byte) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if len(c.pending) == 0 {
+		c.pendingSince = time.Now()
+	}
+	if prev, ok := c.pending[string(start)]; ok && bytes.Compare(prev, limit) >= 0 {
+		return
+	}
+	c.pending[string(start)] = limit
+}
+
+// Compact runs all scheduled compactions if the VM is idle (or they have been
+// deferred for longer than [maxCompactionDeferral]).
+func (c *maintenanceCoordinator) Compact() {
+	c.l.Lock()
+	if len(c.pending) == 0 {
+		c.l.Unlock()
+		return
+	}
+	if c.shouldDefer() {
+		if time.Since(c.pendingSince) < maxCompactionDeferral {
+			c.deferred.Inc()
+			c.l.Unlock()
+			return
+		}
+		c.forced.Inc()
+	}
+	pending := c.pending
+	c.pending = map[string][]byte{}
+	c.l.Unlock()
+
+	for start, limit := range pending {
+		t := time.Now()
+		if err := c.db.Compact([]byte(start), limit); err != nil {
+			c.log.Error("unable to compact", zap.Binary("start", []byte(start)), zap.Binary("limit", limit), zap.Error(err))
+			continue
+		}
+		c.run.Inc()
+		c.log.Info("compacted range", zap.Binary("start", []byte(start)), zap.Binary("limit", limit), zap.Duration("t", time.Since(t)))
+	}
+}
+
+// Run calls [Compact] every [maintenanceInterval] until [stop] is closed.
+func (c *maintenanceCoordinator) Run(stop <-chan struct{}) {
+	t := time.NewTicker(maintenanceInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			c.Compact()
+		case <-stop:
+			return
+		}
+	}
+}
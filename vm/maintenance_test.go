@@ -0,0 +1,201 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// compactionRecorder records the ranges it is asked to compact.
+type compactionRecorder struct {
+	ranges [][2][]byte
+}
+
+func (c *compactionRecorder) Compact(start []byte, limit []byte) error {
+	c.ranges = append(c.ranges, [2][]byte{start, limit})
+	return nil
+}
+
+func newTestMaintenanceCoordinator(db Compacter, idle func() bool) *maintenanceCoordinator {
+	return newMaintenanceCoordinator(
+		logging.NoLog{},
+		db,
+		idle,
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "run"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "deferred"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "forced"}),
+	)
+}
+
+func TestMaintenanceCoordinator(t *testing.T) {
+	require := require.New(t)
+	db := &compactionRecorder{}
+	idle := true
+	c := newTestMaintenanceCoordinator(db, func() bool { return idle })
+
+	// Nothing to compact
+	c.Compact()
+	require.Empty(db.ranges)
+
+	// Ranges with the same start are merged
+	c.Schedule([]byte{0}, []byte{0, 2})
+	c.Schedule([]byte{0}, []byte{0, 1})
+	c.Schedule([]byte{1}, []byte{1, 1})
+	require.Len(c.pending, 2)
+	require.Equal([]byte{0, 2}, c.pending[string([]byte{0})])
+
+	// Deferred while a block is processed
+	done := c.Begin()
+	require.True(c.ShouldDefer())
+	c.Compact()
+	require.Empty(db.ranges)
+	require.Equal(float64(1), testutil.ToFloat64(c.deferred))
+
+	// Deferred right after a block is processed
+	done()
+	require.True(c.ShouldDefer())
+	c.Compact()
+	require.Empty(db.ranges)
+	require.Equal(float64(2), testutil.ToFloat64(c.deferred))
+
+	// Deferred while the VM has pending work
+	c.lastActive = time.Now().Add(-2 * maintenanceIdleGap)
+	idle = false
+	require.True(c.ShouldDefer())
+	c.Compact()
+	require.Empty(db.ranges)
+	require.Equal(float64(3), testutil.ToFloat64(c.deferred))
+
+	// Run once idle
+	idle = true
+	require.False(c.ShouldDefer())
+	c.Compact()
+	require.Len(db.ranges, 2)
+	require.Empty(c.pending)
+	require.Equal(float64(2), testutil.ToFloat64(c.run))
+	require.Zero(testutil.ToFloat64(c.forced))
+}
+
+func TestMaintenanceCoordinatorForced(t *testing.T) {
+	require := require.New(t)
+	db := &compactionRecorder{}
+	c := newTestMaintenanceCoordinator(db, func() bool { return false })
+
+	// Compaction is run if deferred for too long
+	c.Schedule([]byte{0}, []byte{0, 1})
+	c.Compact()
+	require.Empty(db.ranges)
+	c.pendingSince = time.Now().Add(-2 * maxCompactionDeferral)
+	c.Compact()
+	require.Len(db.ranges, 1)
+	require.Equal(float64(1), testutil.ToFloat64(c.forced))
+	require.Equal(float64(1), testutil.ToFloat64(c.run))
+}
+
+// stallingCompacter simulates a database that stalls writes while it
+// compacts.
+type stallingCompacter struct {
+	l     sync.Mutex
+	stall time.Duration
+}
+
+func (c *stallingCompacter) Compact([]byte, []byte) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	time.Sleep(c.stall)
+	return nil
+}
+
+// write simulates a write made while accepting a block.
+func (c *stallingCompacter) write() {
+	c.l.Lock()
+	defer c.l.Unlock()
+}
+
+// acceptLatencyP99 returns the p99 latency of accepting [blocks] blocks every
+// [interval] (each scheduling a compaction, like [VM.UpdateLastAccepted])
+// while [compact] is called every [maintenanceInterval].
+func acceptLatencyP99(db *stallingCompacter, c *maintenanceCoordinator, compact func(), blocks int, interval time.Duration) time.Duration {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		t := time.NewTicker(maintenanceInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				compact()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	latencies := make([]time.Duration, 0, blocks)
+	for i := 0; i < blocks; i++ {
+		start := time.Now()
+		done := c.Begin()
+		db.write()
+		c.Schedule([]byte{0}, []byte{0, byte(i)})
+		done()
+		latencies = append(latencies, time.Since(start))
+		time.Sleep(interval)
+	}
+	close(stop)
+	<-stopped
+	slices.Sort(latencies)
+	return latencies[len(latencies)*99/100]
+}
+
+func TestMaintenanceCoordinatorAcceptLatency(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping load test")
+	}
+	require := require.New(t)
+	const (
+		stall  = 50 * time.Millisecond
+		blocks = 200
+		// Blocks are accepted more frequently than [maintenanceIdleGap]
+		interval = 5 * time.Millisecond
+	)
+
+	// Compacting as soon as a compaction is scheduled stalls accepts
+	db := &stallingCompacter{stall: stall}
+	c := newTestMaintenanceCoordinator(db, func() bool { return true })
+	immediate := acceptLatencyP99(db, c, func() {
+		c.l.Lock()
+		pending := c.pending
+		c.pending = map[string][]byte{}
+		c.l.Unlock()
+		for start, limit := range pending {
+			_ = db.Compact([]byte(start), limit)
+		}
+	}, blocks, interval)
+
+	// Deferring compactions until the VM is idle does not
+	db = &stallingCompacter{stall: stall}
+	c = newTestMaintenanceCoordinator(db, func() bool { return true })
+	deferred := acceptLatencyP99(db, c, c.Compact, blocks, interval)
+	require.Positive(testutil.ToFloat64(c.deferred))
+	require.Zero(testutil.ToFloat64(c.run))
+	require.Len(c.pending, 1)
+	t.Logf("p99 accept latency: immediate=%s deferred=%s", immediate, deferred)
+	require.Greater(immediate, stall/2)
+	require.Less(deferred, stall/2)
+
+	// Deferred compactions run once the VM is idle
+	time.Sleep(maintenanceIdleGap)
+	c.Compact()
+	require.Equal(float64(1), testutil.ToFloat64(c.run))
+}
@@ -4,10 +4,14 @@
 package vm
 
 import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/metric"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/executor"
 )
 
@@ -43,12 +47,30 @@ type Metrics struct {
 	executorBuildExecutable  prometheus.Counter
 	executorVerifyBlocked    prometheus.Counter
 	executorVerifyExecutable prometheus.Counter
+	shadowRootMismatch       prometheus.Counter
+	actionPanics             prometheus.Counter
+	compactionsRun           prometheus.Counter
+	compactionsDeferred      prometheus.Counter
+	compactionsForced        prometheus.Counter
+	stateSyncServeDeferred   prometheus.Counter
+	stateSyncServeRejected   prometheus.Counter
+	parentFetchAttempts      prometheus.Counter
+	parentFetchSuccesses     prometheus.Counter
+	deferredEvicted          prometheus.Counter
+	deferredFailed           prometheus.Counter
+	acceptedDropped          prometheus.Counter
 	mempoolSize              prometheus.Gauge
 	bandwidthPrice           prometheus.Gauge
 	computePrice             prometheus.Gauge
 	storageReadPrice         prometheus.Gauge
 	storageAllocatePrice     prometheus.Gauge
 	storageWritePrice        prometheus.Gauge
+	priorityLaneSize         prometheus.Gauge
+	deferredSize             prometheus.Gauge
+	clockOffset              prometheus.Gauge
+	hottestKeyWrites         prometheus.Gauge
+	txInclusionLatency       prometheus.Histogram
+	stateSyncServeLatency    prometheus.Histogram
 	rootCalculated           metric.Averager
 	waitRoot                 metric.Averager
 	waitSignatures           metric.Averager
@@ -57,6 +79,8 @@ type Metrics struct {
 	blockVerify              metric.Averager
 	blockAccept              metric.Averager
 	blockProcess             metric.Averager
+	shadowRootCalculated     metric.Averager
+	priorityLaneUtilization  metric.Averager
 
 	executorBuildRecorder  executor.Metrics
 	executorVerifyRecorder executor.Metrics
@@ -137,6 +161,24 @@ func newMetrics() (*prometheus.Registry, *Metrics, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	shadowRootCalculated, err := metric.NewAverager(
+		"chain",
+		"shadow_root_calculated",
+		"time spent calculating the shadow state root in verify",
+		r,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	priorityLaneUtilization, err := metric.NewAverager(
+		"chain",
+		"priority_lane_utilization",
+		"fraction of units reserved for the priority lane used by built blocks",
+		r,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	m := &Metrics{
 		txsSubmitted: prometheus.NewCounter(prometheus.CounterOpts{
@@ -259,14 +301,108 @@ func newMetrics() (*prometheus.Registry, *Metrics, error) {
 			Name:      "storage_modify_price",
 			Help:      "unit price of storage modifications",
 		}),
-		rootCalculated: rootCalculated,
-		waitRoot:       waitRoot,
-		waitSignatures: waitSignatures,
-		blockBuild:     blockBuild,
-		blockParse:     blockParse,
-		blockVerify:    blockVerify,
-		blockAccept:    blockAccept,
-		blockProcess:   blockProcess,
+		shadowRootMismatch: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "chain",
+			Name:      "shadow_root_mismatch",
+			Help:      "number of times the shadow state root did not match the state root",
+		}),
+		actionPanics: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "chain",
+			Name:      "action_panics",
+			Help:      "number of actions that panicked during execution (and failed their transaction)",
+		}),
+		priorityLaneSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "chain",
+			Name:      "priority_lane_size",
+			Help:      "number of transactions in the mempool priority lane",
+		}),
+		txInclusionLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "vm",
+			Name:      "tx_inclusion_latency",
+			Help:      "seconds between a tx being added to the mempool and being accepted in a block",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12), // 50ms to ~100s
+		}),
+		compactionsRun: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "compactions_run",
+			Help:      "number of manual database compactions run",
+		}),
+		compactionsDeferred: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "compactions_deferred",
+			Help:      "number of times a manual database compaction was deferred because the node was busy",
+		}),
+		compactionsForced: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "compactions_forced",
+			Help:      "number of manual database compactions run while the node was busy (after being deferred too long)",
+		}),
+		stateSyncServeDeferred: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "state_sync_serve_deferred",
+			Help:      "number of state sync requests not served because a block was being built or verified",
+		}),
+		stateSyncServeRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "state_sync_serve_rejected",
+			Help:      "number of state sync requests not served because the server or peer was at capacity",
+		}),
+		stateSyncServeLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "vm",
+			Name:      "state_sync_serve_latency",
+			Help:      "seconds spent serving a state sync request",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14), // 1ms to ~8s
+		}),
+		parentFetchAttempts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "parent_fetch_attempts",
+			Help:      "number of times a missing parent was fetched from peers during verification",
+		}),
+		parentFetchSuccesses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "parent_fetch_successes",
+			Help:      "number of missing parents successfully fetched from peers and parsed",
+		}),
+		deferredSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "vm",
+			Name:      "deferred_verification_size",
+			Help:      "number of submitted transactions waiting for auth verification",
+		}),
+		clockOffset: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "vm",
+			Name:      "clock_offset",
+			Help:      "median offset (in ms) of the clocks of peers from the local clock",
+		}),
+		hottestKeyWrites: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "chain",
+			Name:      "hottest_key_writes",
+			Help:      "number of txs that declared write access to the most written key of the last executed block (if key accesses are recorded)",
+		}),
+		deferredEvicted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "deferred_verification_evicted",
+			Help:      "number of submitted transactions evicted before their auth was verified",
+		}),
+		deferredFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "deferred_verification_failed",
+			Help:      "number of submitted transactions that failed deferred auth verification",
+		}),
+		acceptedDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "vm",
+			Name:      "accepted_subscriber_dropped",
+			Help:      "number of accepted blocks dropped because a subscriber was not keeping up",
+		}),
+		rootCalculated:          rootCalculated,
+		waitRoot:                waitRoot,
+		waitSignatures:          waitSignatures,
+		blockBuild:              blockBuild,
+		blockParse:              blockParse,
+		blockVerify:             blockVerify,
+		blockAccept:             blockAccept,
+		blockProcess:            blockProcess,
+		shadowRootCalculated:    shadowRootCalculated,
+		priorityLaneUtilization: priorityLaneUtilization,
 	}
 	m.executorBuildRecorder = &executorMetrics{blocked: m.executorBuildBlocked, executable: m.executorBuildExecutable}
 	m.executorVerifyRecorder = &executorMetrics{blocked: m.executorVerifyBlocked, executable: m.executorVerifyExecutable}
@@ -297,6 +433,50 @@ func newMetrics() (*prometheus.Registry, *Metrics, error) {
 		r.Register(m.storageReadPrice),
 		r.Register(m.storageAllocatePrice),
 		r.Register(m.storageWritePrice),
+		r.Register(m.shadowRootMismatch),
+		r.Register(m.actionPanics),
+		r.Register(m.priorityLaneSize),
+		r.Register(m.txInclusionLatency),
+		r.Register(m.compactionsRun),
+		r.Register(m.compactionsDeferred),
+		r.Register(m.compactionsForced),
+		r.Register(m.stateSyncServeDeferred),
+		r.Register(m.stateSyncServeRejected),
+		r.Register(m.stateSyncServeLatency),
+		r.Register(m.parentFetchAttempts),
+		r.Register(m.parentFetchSuccesses),
+		r.Register(m.deferredSize),
+		r.Register(m.deferredEvicted),
+		r.Register(m.deferredFailed),
+		r.Register(m.acceptedDropped),
+		r.Register(m.clockOffset),
+		r.Register(m.hottestKeyWrites),
 	)
 	return r, m, errs.Err
 }
+
+// admissionTracker records when txs were added to the mempool (see
+// [mempool.Mempool.PopAdmitted]).
+type admissionTracker interface {
+	PopAdmitted(txID ids.ID) (time.Time, bool)
+}
+
+// observeInclusionLatency records the time between when each of [txs] was
+// added to [admissions] and [accepted].
+//
+// Txs that never went through our mempool (like those included by another
+// builder before they were gossiped to us) are skipped.
+func observeInclusionLatency(
+	h prometheus.Observer,
+	admissions admissionTracker,
+	txs []*chain.Transaction,
+	accepted time.Time,
+) {
+	for _, tx := range txs {
+		admitted, ok := admissions.PopAdmitted(tx.ID())
+		if !ok {
+			continue
+		}
+		h.Observe(accepted.Sub(admitted).Seconds())
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// latencyTestMempool records the time each tx was admitted.
+type latencyTestMempool map[ids.ID]time.Time
+
+func (m latencyTestMempool) PopAdmitted(txID ids.ID) (time.Time, bool) {
+	t, ok := m[txID]
+	delete(m, txID)
+	return t, ok
+}
+
+// newLatencyTestTx returns a signed transaction (with a unique ID).
+func newLatencyTestTx(t *testing.T, ctrl *gomock.Controller) *chain.Transaction {
+	action := chain.NewMockAction(ctrl)
+	action.EXPECT().GetTypeID().Return(uint8(0)).AnyTimes()
+	action.EXPECT().Size().Return(0).AnyTimes()
+	action.EXPECT().Marshal(gomock.Any()).AnyTimes()
+	auth := chain.NewMockAuth(ctrl)
+	auth.EXPECT().GetTypeID().Return(uint8(0)).AnyTimes()
+	auth.EXPECT().Size().Return(0).AnyTimes()
+	auth.EXPECT().Marshal(gomock.Any()).AnyTimes()
+	auth.EXPECT().Actor().Return(codec.EmptyAddress).AnyTimes()
+	auth.EXPECT().Sponsor().Return(codec.EmptyAddress).AnyTimes()
+	factory := chain.NewMockAuthFactory(ctrl)
+	factory.EXPECT().Sign(gomock.Any()).Return(auth, nil).AnyTimes()
+
+	actionRegistry := codec.NewTypeParser[chain.Action, bool]()
+	require.NoError(t, actionRegistry.Register(0, func(*codec.Packer) (chain.Action, error) { return action, nil }, false))
+	authRegistry := codec.NewTypeParser[chain.Auth, bool]()
+	require.NoError(t, authRegistry.Register(0, func(*codec.Packer) (chain.Auth, error) { return auth, nil }, false))
+	tx, err := chain.NewTx(
+		&chain.Base{Timestamp: 1_000, ChainID: ids.GenerateTestID(), MaxFee: 1},
+		[]chain.Action{action},
+	).Sign(factory, actionRegistry, authRegistry)
+	require.NoError(t, err)
+	return tx
+}
+
+func TestObserveInclusionLatency(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	_, m, err := newMetrics()
+	require.NoError(err)
+	var (
+		accepted = time.Now()
+		admitted = newLatencyTestTx(t, ctrl)
+		injected = newLatencyTestTx(t, ctrl) // never added to the mempool
+		mempool  = latencyTestMempool{admitted.ID(): accepted.Add(-3 * time.Second)}
+	)
+	observeInclusionLatency(m.txInclusionLatency, mempool, []*chain.Transaction{admitted, injected}, accepted)
+
+	// Only the tx that went through the mempool is observed
+	histogram := readHistogram(t, m.txInclusionLatency)
+	require.Equal(uint64(1), histogram.GetSampleCount())
+	require.InDelta(3, histogram.GetSampleSum(), 0.001)
+	require.Empty(mempool)
+
+	// Each tx is only observed once
+	observeInclusionLatency(m.txInclusionLatency, mempool, []*chain.Transaction{admitted}, accepted)
+	require.Equal(uint64(1), readHistogram(t, m.txInclusionLatency).GetSampleCount())
+}
+
+func readHistogram(t *testing.T, h prometheus.Histogram) *dto.Histogram {
+	var metric dto.Metric
+	require.NoError(t, h.Write(&metric))
+	return metric.GetHistogram()
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/version"
+	"go.uber.org/zap"
+)
+
+// BundleGossipHandler receives [chain.Bundle]s gossiped by peers that
+// advertise [bundleGossipFeature].
+type BundleGossipHandler struct {
+	vm *VM
+}
+
+func NewBundleGossipHandler(vm *VM) *BundleGossipHandler {
+	return &BundleGossipHandler{vm}
+}
+
+func (*BundleGossipHandler) Connected(context.Context, ids.NodeID, *version.Application) error {
+	return nil
+}
+
+func (*BundleGossipHandler) Disconnected(context.Context, ids.NodeID) error {
+	return nil
+}
+
+func (b *BundleGossipHandler) AppGossip(ctx context.Context, nodeID ids.NodeID, msg []byte) error {
+	if !b.vm.isReady() {
+		b.vm.snowCtx.Log.Warn("handle bundle gossip failed", zap.Error(ErrNotReady))
+		return nil
+	}
+
+	return b.vm.gossiper.HandleBundleGossip(ctx, nodeID, msg)
+}
+
+func (*BundleGossipHandler) AppRequest(
+	context.Context,
+	ids.NodeID,
+	uint32,
+	time.Time,
+	[]byte,
+) error {
+	return nil
+}
+
+func (*BundleGossipHandler) AppRequestFailed(
+	context.Context,
+	ids.NodeID,
+	uint32,
+) error {
+	return nil
+}
+
+func (*BundleGossipHandler) AppResponse(
+	context.Context,
+	ids.NodeID,
+	uint32,
+	[]byte,
+) error {
+	return nil
+}
+
+func (*BundleGossipHandler) CrossChainAppRequest(
+	context.Context,
+	ids.ID,
+	uint32,
+	time.Time,
+	[]byte,
+) error {
+	return nil
+}
+
+func (*BundleGossipHandler) CrossChainAppRequestFailed(context.Context, ids.ID, uint32) error {
+	return nil
+}
+
+func (*BundleGossipHandler) CrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
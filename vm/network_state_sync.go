@@ -9,6 +9,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/version"
+	"go.uber.org/zap"
 )
 
 type StateSyncHandler struct {
@@ -45,7 +46,25 @@ func (s *StateSyncHandler) AppRequest(
 	if delay := s.vm.config.GetStateSyncServerDelay(); delay > 0 {
 		time.Sleep(delay)
 	}
-	return s.vm.stateSyncNetworkServer.AppRequest(ctx, nodeID, requestID, deadline, request)
+
+	// Generating proofs competes with building and verifying blocks, so we
+	// ask the peer to retry later if we can't serve it right now
+	release, err := s.vm.stateSyncLimiter.Acquire(nodeID)
+	if err != nil {
+		s.vm.SyncLogger().Debug(
+			"not serving state sync request",
+			zap.Stringer("nodeID", nodeID),
+			zap.Uint32("requestID", requestID),
+			zap.Error(err),
+		)
+		return s.vm.stateSyncSender.SendAppError(ctx, nodeID, requestID, StateSyncBusyCode, err.Error())
+	}
+	defer release()
+
+	start := time.Now()
+	err = s.vm.stateSyncNetworkServer.AppRequest(ctx, nodeID, requestID, deadline, request)
+	s.vm.metrics.stateSyncServeLatency.Observe(time.Since(start).Seconds())
+	return err
 }
 
 func (s *StateSyncHandler) AppRequestFailed(
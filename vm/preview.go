@@ -0,0 +1,102 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+const (
+	// minBuildPreviewInterval is the minimum time between the start of two
+	// calls to [VM.BuildPreview].
+	minBuildPreviewInterval = time.Second
+
+	// maxBuildPreviewTxs is the maximum number of mempool transactions
+	// considered by [VM.BuildPreview].
+	maxBuildPreviewTxs = 16_384
+)
+
+// buildPreviewer ensures that [VM.BuildPreview] never delays [VM.BuildBlock]:
+// previews are rate-limited, are not started while a block is being built,
+// and are canceled when a build starts.
+type buildPreviewer struct {
+	l        sync.Mutex
+	building int
+	cancel   context.CancelFunc // cancels the running preview (nil if none)
+	last     time.Time
+}
+
+// beginBuild cancels the running preview (if any) and prevents new previews
+// from starting until the returned function is called.
+func (p *buildPreviewer) beginBuild() func() {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	p.building++
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return func() {
+		p.l.Lock()
+		defer p.l.Unlock()
+
+		p.building--
+	}
+}
+
+// begin returns the context to run a preview with and a function to call
+// when it is done (or an error if a preview can't be started now).
+func (p *buildPreviewer) begin(ctx context.Context) (context.Context, func(), error) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	switch {
+	case p.building > 0:
+		return nil, nil, ErrBuildInProgress
+	case p.cancel != nil, time.Since(p.last) < minBuildPreviewInterval:
+		return nil, nil, ErrPreviewRateLimited
+	}
+	p.last = time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	return ctx, func() {
+		p.l.Lock()
+		defer p.l.Unlock()
+
+		cancel()
+		p.cancel = nil
+	}, nil
+}
+
+// BuildPreview performs a dry run of [VM.BuildBlock] with a snapshot of the
+// mempool and returns the block that would be built on top of the preferred
+// block right now. The mempool is not modified and no block is produced.
+//
+// BuildPreview fails with [ErrPreviewRateLimited] if called more than once
+// every [minBuildPreviewInterval] and with [ErrBuildInProgress] if a block is
+// being built (a preview is canceled if a block starts being built while it
+// runs).
+func (vm *VM) BuildPreview(ctx context.Context) (*chain.BlockPreview, error) {
+	ctx, span := vm.tracer.Start(ctx, "VM.BuildPreview")
+	defer span.End()
+
+	if !vm.isReady() {
+		return nil, ErrNotReady
+	}
+	ctx, done, err := vm.previewer.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	preferredBlk, err := vm.GetStatelessBlock(ctx, vm.preferred)
+	if err != nil {
+		return nil, err
+	}
+	return chain.PreviewBlock(ctx, vm, preferredBlk, vm.mempool.Snapshot(ctx, maxBuildPreviewTxs))
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPreviewer(t *testing.T) {
+	require := require.New(t)
+	p := &buildPreviewer{}
+
+	// Only one preview can run at a time
+	ctx, done, err := p.begin(context.TODO())
+	require.NoError(err)
+	_, _, err = p.begin(context.TODO())
+	require.ErrorIs(err, ErrPreviewRateLimited)
+
+	// Starting a build cancels the running preview...
+	finishBuild := p.beginBuild()
+	require.ErrorIs(ctx.Err(), context.Canceled)
+	done()
+
+	// ...and no previews can start until it finishes
+	p.last = time.Time{}
+	_, _, err = p.begin(context.TODO())
+	require.ErrorIs(err, ErrBuildInProgress)
+	finishBuild()
+
+	// Previews are rate limited
+	_, done, err = p.begin(context.TODO())
+	require.NoError(err)
+	done()
+	_, _, err = p.begin(context.TODO())
+	require.ErrorIs(err, ErrPreviewRateLimited)
+	p.last = time.Now().Add(-minBuildPreviewInterval)
+	_, done, err = p.begin(context.TODO())
+	require.NoError(err)
+	done()
+}
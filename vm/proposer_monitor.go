@@ -21,6 +21,9 @@ import (
 const (
 	refreshTime            = 30 * time.Second
 	proposerMonitorLRUSize = 60
+
+	// nodeAddressTypeID prefixes builder addresses derived from an [ids.NodeID]
+	nodeAddressTypeID uint8 = 0xFF
 )
 
 type ProposerMonitor struct {
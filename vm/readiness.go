@@ -0,0 +1,37 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+// Phases reported by [VM.Readiness]
+const (
+	// ReadinessSyncing is reported until state sync finishes (or the engine
+	// skips it). There is no state to serve.
+	ReadinessSyncing = "syncing"
+	// ReadinessBootstrapping is reported once state is ready but the engine
+	// is still bootstrapping blocks (so the state may be far behind the
+	// network).
+	ReadinessBootstrapping = "bootstrapping"
+	// ReadinessReady is reported during normal operation.
+	ReadinessReady = "ready"
+)
+
+// Readiness reports whether the VM is ready to serve traffic (for liveness
+// and readiness probes), the phase it is in, and the height of the last
+// accepted block whose state is available (0 while syncing).
+//
+// Unlike [VM.HealthCheck], which only requires state to be ready, the VM is
+// not considered ready until it has finished bootstrapping.
+func (vm *VM) Readiness() (bool, string, uint64) {
+	if !vm.StateReady() {
+		return false, ReadinessSyncing, 0
+	}
+	var height uint64
+	if blk := vm.LastAcceptedBlock(); blk != nil {
+		height = blk.Height()
+	}
+	if !vm.IsBootstrapped() {
+		return false, ReadinessBootstrapping, height
+	}
+	return true, ReadinessReady, height
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	require := require.New(t)
+
+	// There is no state until the engine provides a syncable block (or skips
+	// state sync)
+	manager := newSyncTestManager()
+	blkVM := &syncTestVM{unblock: make(chan struct{})}
+	close(blkVM.unblock)
+	s, blks := newSyncTestClient(require, nil, 0, blkVM, 1)
+	vm := s.vm
+	ready, phase, height := vm.Readiness()
+	require.False(ready)
+	require.Equal(ReadinessSyncing, phase)
+	require.Zero(height)
+
+	// The last accepted block is not reported while state is synced
+	vm.lastAccepted = blks[1]
+	s.init = true
+	s.startedSync = true
+	s.syncManager = manager
+	s.target = blks[0]
+	go s.wait()
+	require.Equal(Syncing, vm.SyncState())
+	ready, phase, height = vm.Readiness()
+	require.False(ready)
+	require.Equal(ReadinessSyncing, phase)
+	require.Zero(height)
+
+	// Once state is ready, the VM bootstraps...
+	manager.release()
+	<-s.done
+	ready, phase, height = vm.Readiness()
+	require.False(ready)
+	require.Equal(ReadinessBootstrapping, phase)
+	require.Equal(blks[1].Height(), height)
+
+	// ...before it is ready
+	vm.bootstrapped.Set(true)
+	ready, phase, height = vm.Readiness()
+	require.True(ready)
+	require.Equal(ReadinessReady, phase)
+	require.Equal(blks[1].Height(), height)
+
+	// Bootstrapping can restart
+	vm.bootstrapped.Set(false)
+	ready, phase, _ = vm.Readiness()
+	require.False(ready)
+	require.Equal(ReadinessBootstrapping, phase)
+}
+
+func TestReadinessSkippedSync(t *testing.T) {
+	require := require.New(t)
+
+	blkVM := &syncTestVM{unblock: make(chan struct{})}
+	s, blks := newSyncTestClient(require, nil, 0, blkVM, 1)
+	vm := s.vm
+	vm.lastAccepted = blks[1]
+
+	s.ForceDone()
+	ready, phase, height := vm.Readiness()
+	require.False(ready)
+	require.Equal(ReadinessBootstrapping, phase)
+	require.Equal(blks[1].Height(), height)
+}
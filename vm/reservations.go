@@ -0,0 +1,71 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/math"
+	"github.com/ava-labs/hypersdk/state"
+)
+
+// txReservations returns the amounts reserved by [tx] in the mempool: its max
+// fee from its sponsor and the value its actions hint they spend (see
+// [chain.ValueSpender]) from its actor.
+func txReservations(tx *chain.Transaction) map[codec.Address]uint64 {
+	reservations := map[codec.Address]uint64{tx.Sponsor(): tx.MaxFee()}
+	spent := math.NewUint64Operator(0)
+	for _, action := range tx.Actions {
+		if spender, ok := action.(chain.ValueSpender); ok {
+			spent.Add(spender.ValueSpent())
+		}
+	}
+	actor := tx.Auth.Actor()
+	spent.Add(reservations[actor])
+	v, err := spent.Value()
+	if err != nil {
+		v = ^uint64(0) // can never be paid
+	}
+	reservations[actor] = v
+	return reservations
+}
+
+// checkReservations returns [ErrInsufficientProjectedBalance] if any payer of
+// [tx] can't afford the amount it reserves in addition to the amounts reserved
+// by all transactions in the mempool and [pending] (transactions admitted in
+// the same call to [Submit] that are not yet in the mempool).
+//
+// If [tx] is admitted, its reservations are added to [pending].
+func (vm *VM) checkReservations(
+	ctx context.Context,
+	im state.Immutable,
+	tx *chain.Transaction,
+	pending map[codec.Address]uint64,
+) error {
+	var (
+		sm           = vm.c.StateManager()
+		reservations = txReservations(tx)
+		projected    = make(map[codec.Address]uint64, len(reservations))
+	)
+	for addr, amount := range reservations {
+		total := math.NewUint64Operator(amount)
+		total.Add(vm.mempool.Reserved(addr))
+		total.Add(pending[addr])
+		v, err := total.Value()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInsufficientProjectedBalance, err)
+		}
+		if err := sm.CanDeduct(ctx, addr, im, v); err != nil {
+			return fmt.Errorf("%w: %w", ErrInsufficientProjectedBalance, err)
+		}
+		projected[addr] = pending[addr] + amount // can't overflow because [v] didn't
+	}
+	for addr, v := range projected {
+		pending[addr] = v
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+)
+
+// valueSpenderAction is a [chain.MockAction] that hints it spends [value].
+type valueSpenderAction struct {
+	*chain.MockAction
+
+	value uint64
+}
+
+func (a *valueSpenderAction) ValueSpent() uint64 { return a.value }
+
+func TestTxReservations(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	var (
+		actor   = codec.CreateAddress(0, ids.GenerateTestID())
+		sponsor = codec.CreateAddress(0, ids.GenerateTestID())
+	)
+	newTx := func(actor codec.Address, sponsor codec.Address, maxFee uint64, values ...uint64) *chain.Transaction {
+		auth := chain.NewMockAuth(ctrl)
+		auth.EXPECT().Actor().Return(actor).AnyTimes()
+		auth.EXPECT().Sponsor().Return(sponsor).AnyTimes()
+		actions := []chain.Action{chain.NewMockAction(ctrl)} // no hint
+		for _, v := range values {
+			actions = append(actions, &valueSpenderAction{chain.NewMockAction(ctrl), v})
+		}
+		return &chain.Transaction{Base: &chain.Base{MaxFee: maxFee}, Actions: actions, Auth: auth}
+	}
+
+	// Fee and value spent are reserved from the same payer
+	require.Equal(
+		map[codec.Address]uint64{actor: 16},
+		txReservations(newTx(actor, actor, 10, 2, 4)),
+	)
+
+	// Fee is reserved from the sponsor and value spent from the actor
+	require.Equal(
+		map[codec.Address]uint64{actor: 6, sponsor: 10},
+		txReservations(newTx(actor, sponsor, 10, 2, 4)),
+	)
+
+	// Overflowing reservations can never be paid
+	require.Equal(
+		map[codec.Address]uint64{actor: math.MaxUint64},
+		txReservations(newTx(actor, actor, 10, math.MaxUint64)),
+	)
+}
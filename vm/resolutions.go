@@ -12,21 +12,27 @@ import (
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 	"go.uber.org/zap"
 
 	"github.com/ava-labs/hypersdk/builder"
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/executor"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/gossiper"
+	"github.com/ava-labs/hypersdk/network"
+	"github.com/ava-labs/hypersdk/rpc"
 	"github.com/ava-labs/hypersdk/workers"
 )
 
 var (
 	_ chain.VM              = (*VM)(nil)
+	_ chain.AuthOffloadVM   = (*VM)(nil)
 	_ gossiper.VM           = (*VM)(nil)
 	_ builder.VM            = (*VM)(nil)
 	_ block.ChainVM         = (*VM)(nil)
@@ -65,6 +71,45 @@ func (vm *VM) Logger() logging.Logger {
 	return vm.snowCtx.Log
 }
 
+func (vm *VM) VerifyLogger() logging.Logger {
+	return vm.loggers.Get(VerifyLogComponent)
+}
+
+func (vm *VM) MempoolLogger() logging.Logger {
+	return vm.loggers.Get(MempoolLogComponent)
+}
+
+func (vm *VM) GossipLogger() logging.Logger {
+	return vm.loggers.Get(GossipLogComponent)
+}
+
+func (vm *VM) SyncLogger() logging.Logger {
+	return vm.loggers.Get(SyncLogComponent)
+}
+
+func (vm *VM) RPCLogger() logging.Logger {
+	return vm.loggers.Get(RPCLogComponent)
+}
+
+// SetLogLevels replaces the log level overrides of all components (see
+// [Config.GetLogLevels]).
+func (vm *VM) SetLogLevels(levels map[string]logging.Level) error {
+	if err := vm.loggers.SetLevels(levels); err != nil {
+		return err
+	}
+	vm.snowCtx.Log.Info("updated log levels", zap.Any("levels", levels))
+	return nil
+}
+
+// LogLevels returns the log level of each component.
+func (vm *VM) LogLevels() map[string]logging.Level {
+	return vm.loggers.Levels()
+}
+
+func (vm *VM) AdminAPI() bool {
+	return vm.config.GetAdminAPI()
+}
+
 func (vm *VM) Rules(t int64) chain.Rules {
 	return vm.c.Rules(t)
 }
@@ -77,10 +122,17 @@ func (vm *VM) IsBootstrapped() bool {
 	return vm.bootstrapped.Get()
 }
 
+// Now returns the local time corrected by the offset measured from peers (see
+// [network.PeerClock]).
+func (vm *VM) Now() time.Time {
+	return vm.clock.Now()
+}
+
 func (vm *VM) State() (merkledb.MerkleDB, error) {
 	// As soon as synced (before ready), we can safely request data from the db.
 	if !vm.StateReady() {
-		return nil, ErrStateMissing
+		// State will become available once sync completes
+		return nil, chain.NewTransientError(ErrStateMissing)
 	}
 	return vm.stateDB, nil
 }
@@ -96,6 +148,19 @@ func (vm *VM) IsRepeat(ctx context.Context, txs []*chain.Transaction, marker set
 	return vm.seen.Contains(txs, marker, stop)
 }
 
+func (vm *VM) BeginVerify() func() {
+	endSync := vm.stateSyncLimiter.Begin()
+	endMaintenance := vm.maintenance.Begin()
+	return func() {
+		endMaintenance()
+		endSync()
+	}
+}
+
+func (vm *VM) BeginAccept() func() {
+	return vm.maintenance.Begin()
+}
+
 func (vm *VM) Verified(ctx context.Context, b *chain.StatelessBlock) {
 	ctx, span := vm.tracer.Start(ctx, "VM.Verified")
 	defer span.End()
@@ -150,7 +215,7 @@ func (vm *VM) Rejected(ctx context.Context, b *chain.StatelessBlock) {
 
 	// Ensure children of block are cleared, they may never be
 	// verified
-	vm.snowCtx.Log.Info("rejected block", zap.Stringer("id", b.ID()))
+	vm.snowCtx.Log.Info("rejected block", zap.Stringer("blkID", b.ID()))
 }
 
 func (vm *VM) processAcceptedBlock(b *chain.StatelessBlock) {
@@ -190,6 +255,9 @@ func (vm *VM) processAcceptedBlock(b *chain.StatelessBlock) {
 		vm.Fatal("unable to set min tx in websocket server", zap.Error(err))
 	}
 
+	// Notify subscribers
+	vm.acceptedSubscribers.publish(b)
+
 	// Update price metrics
 	feeManager := b.FeeManager()
 	vm.metrics.bandwidthPrice.Set(float64(feeManager.UnitPrice(fees.Bandwidth)))
@@ -223,8 +291,10 @@ func (vm *VM) processAcceptedBlocks() {
 func (vm *VM) Accepted(ctx context.Context, b *chain.StatelessBlock) {
 	ctx, span := vm.tracer.Start(ctx, "VM.Accepted")
 	defer span.End()
+	defer vm.maintenance.Begin()()
 
 	vm.metrics.txsAccepted.Add(float64(len(b.Txs)))
+	observeInclusionLatency(vm.metrics.txInclusionLatency, vm.mempool, b.Txs, time.Now())
 
 	// Update accepted blocks on-disk and caches
 	if err := vm.UpdateLastAccepted(b); err != nil {
@@ -296,6 +366,67 @@ func (vm *VM) IsValidator(ctx context.Context, nid ids.NodeID) (bool, error) {
 	return vm.proposerMonitor.IsValidator(ctx, nid)
 }
 
+// NodeAddress returns the [codec.Address] a node with [nodeID] uses as the
+// builder of blocks it produces.
+func NodeAddress(nodeID ids.NodeID) codec.Address {
+	var id ids.ID
+	copy(id[:], nodeID.Bytes())
+	return codec.CreateAddress(nodeAddressTypeID, id)
+}
+
+func (vm *VM) BuilderAddress() codec.Address {
+	return NodeAddress(vm.snowCtx.NodeID)
+}
+
+// SignBuilder returns the BLS signature of this node over a warp message
+// (from this chain) with [digest] as its payload.
+func (vm *VM) SignBuilder(digest ids.ID) ([]byte, error) {
+	msg, err := warp.NewUnsignedMessage(vm.snowCtx.NetworkID, vm.snowCtx.ChainID, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	return vm.snowCtx.WarpSigner.Sign(msg)
+}
+
+// VerifyBuilder returns true if [addr] belongs to a node in the validator set
+// at [pChainHeight] and [signature] is its signature of [digest] (see
+// [VM.SignBuilder]).
+func (vm *VM) VerifyBuilder(
+	ctx context.Context,
+	addr codec.Address,
+	pChainHeight uint64,
+	digest ids.ID,
+	signature []byte,
+) (bool, error) {
+	if addr[0] != nodeAddressTypeID {
+		return false, nil
+	}
+	nodeID, err := ids.ToNodeID(addr[1 : 1+ids.NodeIDLen])
+	if err != nil {
+		return false, err
+	}
+	if NodeAddress(nodeID) != addr {
+		return false, nil
+	}
+	vdrs, err := vm.snowCtx.ValidatorState.GetValidatorSet(ctx, pChainHeight, vm.snowCtx.SubnetID)
+	if err != nil {
+		return false, err
+	}
+	vdr, ok := vdrs[nodeID]
+	if !ok || vdr.PublicKey == nil {
+		return false, nil
+	}
+	sig, err := bls.SignatureFromBytes(signature)
+	if err != nil {
+		return false, err
+	}
+	msg, err := warp.NewUnsignedMessage(vm.snowCtx.NetworkID, vm.snowCtx.ChainID, digest[:])
+	if err != nil {
+		return false, err
+	}
+	return bls.Verify(vdr.PublicKey, sig, msg.Bytes()), nil
+}
+
 func (vm *VM) Proposers(ctx context.Context, diff int, depth int) (set.Set[ids.NodeID], error) {
 	return vm.proposerMonitor.Proposers(ctx, diff, depth)
 }
@@ -310,6 +441,20 @@ func (vm *VM) NodeID() ids.NodeID {
 	return vm.snowCtx.NodeID
 }
 
+// PeerFeatures returns the protocol features advertised by [nodeID] (or
+// [network.LegacyFeatures] if it has not sent a handshake).
+func (vm *VM) PeerFeatures(nodeID ids.NodeID) network.Features {
+	return vm.handshake.Features(nodeID)
+}
+
+func (vm *VM) PeerHandshakes() map[ids.NodeID]*network.Handshake {
+	return vm.handshake.Peers()
+}
+
+func (vm *VM) FeatureNames(f network.Features) []string {
+	return vm.features.Names(f)
+}
+
 func (vm *VM) PreferredBlock(ctx context.Context) (*chain.StatelessBlock, error) {
 	return vm.GetStatelessBlock(ctx, vm.preferred)
 }
@@ -346,6 +491,15 @@ func (vm *VM) StateReady() bool {
 	return vm.stateSyncClient.StateReady()
 }
 
+// SyncState returns the [SyncPhase] of the VM. Blocks accepted before the
+// phase is [Synced] update the sync target instead of being executed.
+func (vm *VM) SyncState() SyncPhase {
+	if vm.stateSyncClient == nil {
+		return SyncPending
+	}
+	return vm.stateSyncClient.Phase()
+}
+
 func (vm *VM) UpdateSyncTarget(b *chain.StatelessBlock) (bool, error) {
 	return vm.stateSyncClient.UpdateSyncTarget(b)
 }
@@ -370,6 +524,79 @@ func (vm *VM) RecordWaitRoot(t time.Duration) {
 	vm.metrics.waitRoot.Observe(float64(t))
 }
 
+func (vm *VM) RecordShadowRootCalculated(t time.Duration) {
+	vm.metrics.shadowRootCalculated.Observe(float64(t))
+}
+
+func (vm *VM) RecordShadowRootMismatch() {
+	vm.metrics.shadowRootMismatch.Inc()
+}
+
+func (vm *VM) ShadowRootComputer() chain.RootComputer {
+	return vm.shadowRootComputer
+}
+
+// BlockProfiler returns nil unless blocks remain to be profiled (so no
+// profiling hooks are installed otherwise).
+func (vm *VM) BlockProfiler() chain.BlockProfiler {
+	if vm.blockProfiler == nil || vm.blockProfiler.remaining.Load() <= 0 {
+		return nil
+	}
+	return vm.blockProfiler
+}
+
+// ProfileBlocks profiles the verification of the next [count] blocks (0
+// stops profiling) and returns the directory the profiles are written to.
+//
+// Profiling only measures verification and doesn't change its result.
+func (vm *VM) ProfileBlocks(count int) (string, error) {
+	if vm.blockProfiler == nil {
+		return "", ErrBlockProfilingDisabled
+	}
+	if count < 0 {
+		return "", ErrInvalidProfileCount
+	}
+	vm.blockProfiler.remaining.Store(int64(count))
+	vm.blockProfiler.log.Info("profiling block verification", zap.Int("count", count))
+	return vm.blockProfiler.dir, nil
+}
+
+func (vm *VM) StatePrefixes() *chain.StatePrefixRegistry {
+	if vm.stateUsage == nil {
+		return nil
+	}
+	return vm.stateUsage.registry
+}
+
+func (vm *VM) GetStoreStateUsage() bool {
+	return vm.stateUsage != nil
+}
+
+// GetStateUsage returns the approximate usage of each registered state prefix
+// (see [stateUsage]).
+func (vm *VM) GetStateUsage() (*rpc.StateUsageReport, error) {
+	if vm.stateUsage == nil {
+		return nil, rpc.ErrIndexDisabled
+	}
+	return vm.stateUsage.report(), nil
+}
+
+// GetChainChecksum returns the rolling checksum of the accepted chain (see
+// [chainChecksums]).
+func (vm *VM) GetChainChecksum() (*rpc.ChainChecksumReport, error) {
+	if vm.chainChecksums == nil {
+		return nil, rpc.ErrIndexDisabled
+	}
+	return vm.chainChecksums.report()
+}
+
+func (vm *VM) GetChainCheckpoint(height uint64) (ids.ID, error) {
+	if vm.chainChecksums == nil {
+		return ids.Empty, rpc.ErrIndexDisabled
+	}
+	return vm.chainChecksums.checkpoint(height)
+}
+
 func (vm *VM) RecordWaitSignatures(t time.Duration) {
 	vm.metrics.waitSignatures.Observe(float64(t))
 }
@@ -382,10 +609,18 @@ func (vm *VM) RecordStateOperations(c int) {
 	vm.metrics.stateOperations.Add(float64(c))
 }
 
+func (vm *VM) CatchUpIncrementalRoots() bool {
+	return vm.config.GetCatchUpIncrementalRoots() && !vm.bootstrapped.Get()
+}
+
 func (vm *VM) GetVerifyAuth() bool {
 	return vm.config.GetVerifyAuth()
 }
 
+func (vm *VM) GetStoreTxsByAddress() bool {
+	return vm.config.GetStoreTxsByAddress()
+}
+
 func (vm *VM) RecordTxsGossiped(c int) {
 	vm.metrics.txsGossiped.Add(float64(c))
 }
@@ -402,6 +637,14 @@ func (vm *VM) RecordBuildCapped() {
 	vm.metrics.buildCapped.Inc()
 }
 
+func (vm *VM) PriorityLane() *chain.PriorityLane {
+	return vm.priorityLane
+}
+
+func (vm *VM) GetPriorityLaneUnitsPercent() uint64 {
+	return min(vm.config.GetPriorityLaneUnitsPercent(), 100)
+}
+
 func (vm *VM) GetTargetBuildDuration() time.Duration {
 	return vm.config.GetTargetBuildDuration()
 }
@@ -422,6 +665,14 @@ func (vm *VM) GetAuthBatchVerifier(authTypeID uint8, cores int, count int) (chai
 	return bv.GetBatchVerifier(cores, count), ok
 }
 
+// GetAuthOffloader implements [chain.AuthOffloadVM].
+func (vm *VM) GetAuthOffloader() chain.AuthOffloader {
+	if vm.authOffloader == nil {
+		return nil
+	}
+	return vm.authOffloader
+}
+
 func (vm *VM) cacheAuth(auth chain.Auth) {
 	bv, ok := vm.authEngine[auth.GetTypeID()]
 	if !ok {
@@ -442,6 +693,14 @@ func (vm *VM) RecordClearedMempool() {
 	vm.metrics.clearedMempool.Inc()
 }
 
+func (vm *VM) RecordPriorityLaneUtilization(u float64) {
+	vm.metrics.priorityLaneUtilization.Observe(u)
+}
+
+func (vm *VM) RecordActionPanic() {
+	vm.metrics.actionPanics.Inc()
+}
+
 func (vm *VM) UnitPrices(context.Context) (fees.Dimensions, error) {
 	v, err := vm.stateDB.Get(chain.FeeKey(vm.StateManager().FeeKey()))
 	if err != nil {
@@ -465,3 +724,10 @@ func (vm *VM) GetExecutorBuildRecorder() executor.Metrics {
 func (vm *VM) GetExecutorVerifyRecorder() executor.Metrics {
 	return vm.metrics.executorVerifyRecorder
 }
+
+func (vm *VM) GetKeyAccessRecorder() chain.KeyAccessRecorder {
+	if !vm.config.GetRecordKeyAccesses() {
+		return nil
+	}
+	return &keyAccessRecorder{log: vm.Logger(), hottestWrites: vm.metrics.hottestKeyWrites}
+}
@@ -0,0 +1,178 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+// migrationStepSize is the maximum number of rows a [migration] modifies in
+// a single batch.
+const migrationStepSize = 1_024
+
+var (
+	schemaVersionKey   = []byte("schema_version")
+	migrationCursorKey = []byte("migration_cursor")
+)
+
+// migration upgrades [vmDB] from the schema version equal to its index in
+// [migrations] to the next.
+//
+// A migration is applied in steps, each of which appends the changes for a
+// bounded number of rows (starting at [cursor], which is nil for the first
+// step) to [batch] and returns the cursor to resume from (nil once the
+// migration is complete). The cursor is written in the same batch as the
+// changes of the step, so a migration that is interrupted resumes from the
+// last step written. Steps must skip rows that are already migrated.
+type migration struct {
+	name string
+	step func(db database.Database, batch database.Batch, cursor []byte) ([]byte, error)
+}
+
+// migrations are applied in order to upgrade [vmDB] to [SchemaVersion]. They
+// must never be removed or reordered.
+var migrations = []migration{
+	{
+		name: "add timestamps to height index",
+		step: migrateHeightTimestamps(migrationStepSize),
+	},
+}
+
+// SchemaVersion is the version of the layout of [vmDB] written by this
+// binary.
+var SchemaVersion = uint64(len(migrations))
+
+// migrateSchema applies the migrations [ms] required to upgrade [db] to the
+// latest schema version (persisting progress as it goes).
+//
+// A database created before schema versions were introduced has version 0
+// and a new database is created with the latest version. An error is returned
+// if [db] was written by a newer binary (with a schema this binary can't
+// read).
+func migrateSchema(log logging.Logger, db database.Database, ms []migration) error {
+	latest := uint64(len(ms))
+	version, err := database.GetUInt64(db, schemaVersionKey)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		has, err := db.Has(lastAccepted)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return database.PutUInt64(db, schemaVersionKey, latest)
+		}
+		version = 0
+	case err != nil:
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("%w: version=%d supported=%d", ErrSchemaTooNew, version, latest)
+	}
+
+	for ; version < latest; version++ {
+		m := ms[version]
+		cursor, err := db.Get(migrationCursorKey)
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			cursor = nil
+		case err != nil:
+			return err
+		default:
+			log.Info("resuming schema migration", zap.String("name", m.name), zap.Binary("cursor", cursor))
+		}
+		steps := 0
+		for {
+			batch := db.NewBatch()
+			next, err := m.step(db, batch, cursor)
+			if err != nil {
+				return fmt.Errorf("%w: unable to apply migration %q", err, m.name)
+			}
+			if next == nil {
+				if err := batch.Delete(migrationCursorKey); err != nil {
+					return err
+				}
+				if err := database.PutUInt64(batch, schemaVersionKey, version+1); err != nil {
+					return err
+				}
+			} else if err := batch.Put(migrationCursorKey, next); err != nil {
+				return err
+			}
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			steps++
+			if next == nil {
+				break
+			}
+			cursor = next
+		}
+		log.Info("applied schema migration",
+			zap.String("name", m.name),
+			zap.Uint64("version", version+1),
+			zap.Int("steps", steps),
+		)
+	}
+	return nil
+}
+
+// migrateHeightTimestamps returns a migration step that appends the
+// timestamp of each block to its row in the height index (which previously
+// only held its ID), so that [VM.GetBlockByTime] can search heights without
+// reading blocks. Each step migrates at most [stepSize] rows.
+//
+// The cursor is the key of the next row to migrate.
+func migrateHeightTimestamps(stepSize int) func(database.Database, database.Batch, []byte) ([]byte, error) {
+	return func(db database.Database, batch database.Batch, cursor []byte) ([]byte, error) {
+		prefix := []byte{blockHeightIDPrefix}
+		if cursor == nil {
+			cursor = prefix
+		}
+		it := db.NewIteratorWithStartAndPrefix(cursor, prefix)
+		defer it.Release()
+
+		for rows := 0; it.Next(); rows++ {
+			if rows == stepSize {
+				return slices.Clone(it.Key()), nil
+			}
+			if len(it.Value()) != ids.IDLen {
+				continue // already migrated
+			}
+			height := binary.BigEndian.Uint64(it.Key()[1:])
+			raw, err := db.Get(PrefixBlockKey(height))
+			if errors.Is(err, database.ErrNotFound) {
+				continue // block is missing, so the row is left as-is
+			}
+			if err != nil {
+				return nil, err
+			}
+			timestamp, err := chain.UnmarshalBlockTimestamp(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to parse block at height %d", err, height)
+			}
+			if err := batch.Put(slices.Clone(it.Key()), heightIndexValue(ids.ID(it.Value()), timestamp)); err != nil {
+				return nil, err
+			}
+		}
+		return nil, it.Error()
+	}
+}
+
+// heightIndexValue returns the row of a block in the height index:
+// ID|Timestamp.
+func heightIndexValue(blkID ids.ID, timestamp int64) []byte {
+	v := make([]byte, 0, ids.IDLen+consts.Uint64Len)
+	v = append(v, blkID[:]...)
+	return binary.BigEndian.AppendUint64(v, uint64(timestamp))
+}
@@ -0,0 +1,166 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+var errTestCrash = errors.New("crash")
+
+// newLegacySchemaTestDB returns the [vmDB] of a VM that accepted a chain of
+// [chainLength] blocks (with heights [1, pruned] no longer stored) before
+// schema versions were introduced.
+func newLegacySchemaTestDB(t *testing.T, chainLength int, pruned uint64) (database.Database, []*chain.StatelessBlock) {
+	require := require.New(t)
+
+	vm, blks := newBlockRangeTestVM(t, chainLength, pruned)
+	for _, blk := range blks {
+		if blk.Height() > 0 && blk.Height() <= pruned {
+			continue
+		}
+		blkID := blk.ID()
+		require.NoError(vm.vmDB.Put(PrefixBlockHeightIDKey(blk.Height()), blkID[:]))
+	}
+	return vm.vmDB, blks
+}
+
+func requireHeightTimestamps(require *require.Assertions, db database.Database, blks []*chain.StatelessBlock, pruned uint64) {
+	vm := &VM{vmDB: db}
+	for _, blk := range blks {
+		if blk.Height() > 0 && blk.Height() <= pruned {
+			continue
+		}
+		blkID, err := vm.GetBlockHeightID(blk.Height())
+		require.NoError(err)
+		require.Equal(blk.ID(), blkID)
+		timestamp, err := vm.GetBlockHeightTimestamp(blk.Height())
+		require.NoError(err)
+		require.Equal(blk.Tmstmp, timestamp)
+	}
+}
+
+func TestMigrateSchemaNewDatabase(t *testing.T) {
+	require := require.New(t)
+	db := memdb.New()
+
+	// A new database is created with the latest schema (without migrating)
+	ms := []migration{{
+		name: "crash",
+		step: func(database.Database, database.Batch, []byte) ([]byte, error) { return nil, errTestCrash },
+	}}
+	require.NoError(migrateSchema(logging.NoLog{}, db, ms))
+	version, err := database.GetUInt64(db, schemaVersionKey)
+	require.NoError(err)
+	require.Equal(uint64(1), version)
+	require.NoError(migrateSchema(logging.NoLog{}, db, ms))
+}
+
+func TestMigrateHeightTimestamps(t *testing.T) {
+	const (
+		chainLength = 50
+		pruned      = 10
+	)
+	require := require.New(t)
+	db, blks := newLegacySchemaTestDB(t, chainLength, pruned)
+	vm := &VM{vmDB: db}
+	_, err := vm.GetBlockHeightTimestamp(chainLength)
+	require.ErrorIs(err, ErrCorruptIndex)
+
+	require.NoError(migrateSchema(logging.NoLog{}, db, migrations))
+	version, err := database.GetUInt64(db, schemaVersionKey)
+	require.NoError(err)
+	require.Equal(SchemaVersion, version)
+	requireHeightTimestamps(require, db, blks, pruned)
+
+	// Migrations are not applied again
+	require.NoError(migrateSchema(logging.NoLog{}, db, []migration{{
+		name: "crash",
+		step: func(database.Database, database.Batch, []byte) ([]byte, error) { return nil, errTestCrash },
+	}}))
+}
+
+func TestMigrateSchemaResume(t *testing.T) {
+	const (
+		chainLength = 50
+		pruned      = 10
+		stepSize    = 4
+	)
+	require := require.New(t)
+	db, blks := newLegacySchemaTestDB(t, chainLength, pruned)
+
+	// Crash (without writing the step) after a few steps have been written
+	var (
+		step    = migrateHeightTimestamps(stepSize)
+		steps   int
+		cursors [][]byte
+	)
+	crashing := []migration{{
+		name: "crash",
+		step: func(db database.Database, batch database.Batch, cursor []byte) ([]byte, error) {
+			if steps == 3 {
+				return nil, errTestCrash
+			}
+			steps++
+			next, err := step(db, batch, cursor)
+			cursors = append(cursors, next)
+			return next, err
+		},
+	}}
+	require.ErrorIs(migrateSchema(logging.NoLog{}, db, crashing), errTestCrash)
+	_, err := database.GetUInt64(db, schemaVersionKey)
+	require.ErrorIs(err, database.ErrNotFound)
+	cursor, err := db.Get(migrationCursorKey)
+	require.NoError(err)
+	require.Equal(cursors[2], cursor)
+
+	// Only the rows of the written steps were migrated
+	vm := &VM{vmDB: db}
+	_, err = vm.GetBlockHeightTimestamp(chainLength)
+	require.ErrorIs(err, ErrCorruptIndex)
+
+	// The migration resumes from the last step written
+	var resumed []byte
+	ms := []migration{{
+		name: "resume",
+		step: func(db database.Database, batch database.Batch, c []byte) ([]byte, error) {
+			if resumed == nil {
+				resumed = c
+			}
+			return step(db, batch, c)
+		},
+	}}
+	require.NoError(migrateSchema(logging.NoLog{}, db, ms))
+	require.Equal(cursor, resumed)
+	has, err := db.Has(migrationCursorKey)
+	require.NoError(err)
+	require.False(has)
+	version, err := database.GetUInt64(db, schemaVersionKey)
+	require.NoError(err)
+	require.Equal(uint64(1), version)
+	requireHeightTimestamps(require, db, blks, pruned)
+}
+
+func TestMigrateSchemaTooNew(t *testing.T) {
+	require := require.New(t)
+	db, _ := newLegacySchemaTestDB(t, 10, 0)
+	require.NoError(database.PutUInt64(db, schemaVersionKey, SchemaVersion+1))
+
+	// A database written by a newer binary is not modified
+	require.ErrorIs(migrateSchema(logging.NoLog{}, db, migrations), ErrSchemaTooNew)
+	vm := &VM{vmDB: db}
+	_, err := vm.GetBlockHeightTimestamp(10)
+	require.ErrorIs(err, ErrCorruptIndex)
+	version, err := database.GetUInt64(db, schemaVersionKey)
+	require.NoError(err)
+	require.Equal(SchemaVersion+1, version)
+}
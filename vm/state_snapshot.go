@@ -0,0 +1,125 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+
+	"github.com/ava-labs/hypersdk/chain"
+)
+
+// StateSnapshot is a read-only view of the accepted state at [Root].
+//
+// All reads from a StateSnapshot are consistent with each other, so RPC
+// handlers that perform more than one read should serve them all from a
+// single StateSnapshot (see [VM.ReadSnapshot]). [GetValues] can be used
+// anywhere a ReadState function is expected.
+//
+// A StateSnapshot doesn't delay the commit of accepted blocks. Once a block
+// is committed, reads are served from the history of recent roots kept by
+// [stateDB] (the last [Config.GetStateHistoryLength] roots) instead. Only
+// once [Root] is no longer in the history do reads fail with
+// [ErrStaleSnapshot].
+type StateSnapshot struct {
+	db   merkledb.MerkleDB
+	view merkledb.View
+	root ids.ID
+}
+
+// StateSnapshot returns a [StateSnapshot] of the accepted state.
+func (vm *VM) StateSnapshot(ctx context.Context) (*StateSnapshot, error) {
+	if !vm.isReady() {
+		return nil, ErrNotReady
+	}
+	for {
+		view, err := vm.stateDB.NewView(ctx, merkledb.ViewChanges{})
+		if err != nil {
+			return nil, err
+		}
+		root, err := view.GetMerkleRoot(ctx)
+		if errors.Is(err, merkledb.ErrInvalid) {
+			// A block was committed before the root of [view] was computed,
+			// so we don't know which root to read from once it is stale
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &StateSnapshot{
+			db:   vm.stateDB,
+			view: view,
+			root: root,
+		}, nil
+	}
+}
+
+// ReadSnapshot calls [f] with a new [StateSnapshot].
+func (vm *VM) ReadSnapshot(ctx context.Context, f func(*StateSnapshot) error) error {
+	snapshot, err := vm.StateSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	return f(snapshot)
+}
+
+// CommitState persists [blk] and then commits [view] to the accepted state
+// (after which every [StateSnapshot] taken before reads from the history).
+//
+// If the node stops before the accept batch of [blk] is written, [blk] is
+// recorded as accepted on restart (see [VM.recoverCommittedBlock]).
+func (vm *VM) CommitState(ctx context.Context, blk *chain.StatelessBlock, view merkledb.View) error {
+	if err := vm.vmDB.Put(committedBlock, blk.Bytes()); err != nil {
+		return fmt.Errorf("%w: unable to persist committed block", err)
+	}
+	return view.CommitToDB(ctx)
+}
+
+// Root is the state root all reads are served from.
+func (s *StateSnapshot) Root() ids.ID {
+	return s.root
+}
+
+func (s *StateSnapshot) GetValue(ctx context.Context, key []byte) ([]byte, error) {
+	value, err := s.view.GetValue(ctx, key)
+	if errors.Is(err, merkledb.ErrInvalid) {
+		return s.getHistoricalValue(ctx, key)
+	}
+	return value, err
+}
+
+func (s *StateSnapshot) GetValues(ctx context.Context, keys [][]byte) ([][]byte, []error) {
+	values, errs := s.view.GetValues(ctx, keys)
+	for i, err := range errs {
+		if errors.Is(err, merkledb.ErrInvalid) {
+			values[i], errs[i] = s.getHistoricalValue(ctx, keys[i])
+		}
+	}
+	return values, errs
+}
+
+// getHistoricalValue reads [key] at [s.root] from the history of [s.db]
+// (after a block was committed).
+func (s *StateSnapshot) getHistoricalValue(ctx context.Context, key []byte) ([]byte, error) {
+	if s.root == ids.Empty {
+		return nil, database.ErrNotFound
+	}
+	proof, err := s.db.GetRangeProofAtRoot(ctx, s.root, maybe.Some(key), maybe.Some(key), 1)
+	if errors.Is(err, merkledb.ErrInsufficientHistory) {
+		return nil, fmt.Errorf("%w: %w", ErrStaleSnapshot, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(proof.KeyValues) == 0 {
+		return nil, database.ErrNotFound
+	}
+	return proof.KeyValues[0].Value, nil
+}
@@ -0,0 +1,216 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	snapshotTestTotal         = 1_000_000
+	snapshotTestHistoryLength = 256
+	snapshotTestBlockInterval = 100 * time.Microsecond
+)
+
+var (
+	snapshotTestKeyA = []byte("a")
+	snapshotTestKeyB = []byte("b")
+)
+
+// commitSnapshotTestBlock commits a block that sets key A to [a] and key B to
+// [snapshotTestTotal]-[a] (so that A+B is always [snapshotTestTotal]).
+func commitSnapshotTestBlock(ctx context.Context, vm *VM, a uint64) error {
+	view, err := vm.stateDB.NewView(ctx, merkledb.ViewChanges{MapOps: map[string]maybe.Maybe[[]byte]{
+		string(snapshotTestKeyA): maybe.Some(binary.BigEndian.AppendUint64(nil, a)),
+		string(snapshotTestKeyB): maybe.Some(binary.BigEndian.AppendUint64(nil, snapshotTestTotal-a)),
+	}})
+	if err != nil {
+		return err
+	}
+	return view.CommitToDB(ctx)
+}
+
+func TestStateSnapshotIsolation(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+	db, err := merkledb.New(ctx, memdb.New(), merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor16,
+		RootGenConcurrency:          1,
+		HistoryLength:               snapshotTestHistoryLength,
+		ValueNodeCacheSize:          units.MiB,
+		IntermediateNodeCacheSize:   units.MiB,
+		IntermediateWriteBufferSize: units.KiB,
+		IntermediateWriteBatchSize:  units.KiB,
+		Tracer:                      trace.Noop,
+	})
+	require.NoError(err)
+	vm := &VM{
+		snowCtx: &snow.Context{Log: logging.NoLog{}},
+		stateDB: db,
+		ready:   make(chan struct{}),
+	}
+
+	// Snapshots can't be taken until the VM is ready
+	_, err = vm.StateSnapshot(ctx)
+	require.ErrorIs(err, ErrNotReady)
+	close(vm.ready)
+	require.NoError(commitSnapshotTestBlock(ctx, vm, snapshotTestTotal))
+
+	var (
+		accepts = 2_000
+		readers = 8
+		done    = make(chan struct{})
+		wg      sync.WaitGroup
+		errs    = make(chan error, readers+2)
+
+		// committed is the number of blocks committed by the acceptor
+		committed atomic.Int64
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+
+		for i := 0; i < accepts; i++ {
+			if err := commitSnapshotTestBlock(ctx, vm, uint64(i)); err != nil {
+				errs <- err
+				return
+			}
+			committed.Add(1)
+			time.Sleep(snapshotTestBlockInterval)
+		}
+	}()
+
+	// checkSum reads A and B from [snapshot] (calling [between] after reading
+	// A) and checks that they were read from the same accepted state
+	checkSum := func(snapshot *StateSnapshot, between func()) error {
+		a, err := snapshot.GetValue(ctx, snapshotTestKeyA)
+		if err != nil {
+			return err
+		}
+		between()
+		b, err := snapshot.GetValue(ctx, snapshotTestKeyB)
+		if err != nil {
+			return err
+		}
+		if sum := binary.BigEndian.Uint64(a) + binary.BigEndian.Uint64(b); sum != snapshotTestTotal {
+			return fmt.Errorf("unexpected sum: %d", sum)
+		}
+		return nil
+	}
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				// Reads of correlated keys in separate calls must observe the
+				// same accepted state
+				if err := vm.ReadSnapshot(ctx, func(snapshot *StateSnapshot) error {
+					return checkSum(snapshot, runtime.Gosched)
+				}); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	// Reads of a request that is slower than the block interval (that spans
+	// multiple commits) are still served from the root it started at
+	var slowReads int
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			start := committed.Load()
+			straddled := false
+			if err := vm.ReadSnapshot(ctx, func(snapshot *StateSnapshot) error {
+				return checkSum(snapshot, func() {
+					for committed.Load() < start+2 {
+						select {
+						case <-done:
+							return
+						case <-time.After(snapshotTestBlockInterval):
+						}
+					}
+					straddled = true
+				})
+			}); err != nil {
+				errs <- err
+				return
+			}
+			if straddled {
+				slowReads++
+			}
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(err)
+	}
+	require.Positive(slowReads)
+
+	// Snapshots are served at the latest root
+	snapshot, err := vm.StateSnapshot(ctx)
+	require.NoError(err)
+	root, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(root, snapshot.Root())
+	values, readErrs := snapshot.GetValues(ctx, [][]byte{snapshotTestKeyA, snapshotTestKeyB})
+	require.Equal([]error{nil, nil}, readErrs)
+	require.Equal(uint64(accepts-1), binary.BigEndian.Uint64(values[0]))
+
+	// Holding a snapshot doesn't delay commits, and it can still be read
+	// once a block is committed
+	require.NoError(commitSnapshotTestBlock(ctx, vm, 0))
+	values, readErrs = snapshot.GetValues(ctx, [][]byte{snapshotTestKeyA, snapshotTestKeyB, []byte("missing")})
+	require.Equal([]error{nil, nil, database.ErrNotFound}, readErrs)
+	require.Equal(uint64(accepts-1), binary.BigEndian.Uint64(values[0]))
+	require.Equal(uint64(snapshotTestTotal-accepts+1), binary.BigEndian.Uint64(values[1]))
+	values, readErrs = vm.ReadState(ctx, [][]byte{snapshotTestKeyA, snapshotTestKeyB})
+	require.Equal([]error{nil, nil}, readErrs)
+	require.Equal(uint64(0), binary.BigEndian.Uint64(values[0]))
+	require.Equal(uint64(snapshotTestTotal), binary.BigEndian.Uint64(values[1]))
+
+	// ...until its root is no longer in the history
+	for i := 1; i <= snapshotTestHistoryLength; i++ {
+		require.NoError(commitSnapshotTestBlock(ctx, vm, uint64(i)))
+	}
+	_, err = snapshot.GetValue(ctx, snapshotTestKeyA)
+	require.ErrorIs(err, ErrStaleSnapshot)
+	_, readErrs = snapshot.GetValues(ctx, [][]byte{snapshotTestKeyA})
+	require.ErrorIs(readErrs[0], ErrStaleSnapshot)
+}
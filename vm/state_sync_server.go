@@ -0,0 +1,105 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StateSyncBusyCode is the [common.AppError] code sent in response to a state
+// sync request that the server won't serve right now. The sync client treats
+// it like any other failed request and retries it (with backoff), possibly on
+// a different peer.
+const StateSyncBusyCode int32 = 1
+
+// stateSyncLimiter bounds the range and change proofs served to syncing peers
+// so that serving them doesn't degrade the latency of building and verifying
+// blocks:
+//
+//   - at most [concurrency] requests are served at once (0 is unbounded)
+//   - at most [peerQuota] requests from the same peer are served at once (0 is
+//     unbounded)
+//   - requests are deferred while a block is built or verified, unless no
+//     request has been served for [maxDeferral] (0 never defers)
+type stateSyncLimiter struct {
+	concurrency int
+	peerQuota   int
+	maxDeferral time.Duration
+
+	deferred prometheus.Counter
+	rejected prometheus.Counter
+
+	l          sync.Mutex
+	active     int
+	inflight   int
+	peers      map[ids.NodeID]int
+	lastServed time.Time
+}
+
+func newStateSyncLimiter(
+	concurrency int,
+	peerQuota int,
+	maxDeferral time.Duration,
+	deferred, rejected prometheus.Counter,
+) *stateSyncLimiter {
+	return &stateSyncLimiter{
+		concurrency: concurrency,
+		peerQuota:   peerQuota,
+		maxDeferral: maxDeferral,
+		deferred:    deferred,
+		rejected:    rejected,
+		peers:       map[ids.NodeID]int{},
+		lastServed:  time.Now(),
+	}
+}
+
+// Begin marks a block as being built or verified until the returned function
+// is called.
+func (l *stateSyncLimiter) Begin() func() {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	l.active++
+	return func() {
+		l.l.Lock()
+		defer l.l.Unlock()
+
+		l.active--
+	}
+}
+
+// Acquire reserves capacity to serve a request from [nodeID], returning a
+// function to release it once the request is served (or
+// [ErrStateSyncServerBusy] if the request should not be served right now).
+func (l *stateSyncLimiter) Acquire(nodeID ids.NodeID) (func(), error) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	if (l.concurrency > 0 && l.inflight >= l.concurrency) || (l.peerQuota > 0 && l.peers[nodeID] >= l.peerQuota) {
+		l.rejected.Inc()
+		return nil, ErrStateSyncServerBusy
+	}
+	now := time.Now()
+	if l.active > 0 && l.maxDeferral > 0 && now.Sub(l.lastServed) < l.maxDeferral {
+		l.deferred.Inc()
+		return nil, ErrStateSyncServerBusy
+	}
+	l.inflight++
+	l.peers[nodeID]++
+	l.lastServed = now
+	return func() {
+		l.l.Lock()
+		defer l.l.Unlock()
+
+		l.inflight--
+		l.peers[nodeID]--
+		if l.peers[nodeID] == 0 {
+			delete(l.peers, nodeID)
+		}
+	}, nil
+}
@@ -0,0 +1,183 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStateSyncLimiter(concurrency int, peerQuota int, maxDeferral time.Duration) *stateSyncLimiter {
+	return newStateSyncLimiter(
+		concurrency,
+		peerQuota,
+		maxDeferral,
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "deferred"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "rejected"}),
+	)
+}
+
+func TestStateSyncLimiterQuotas(t *testing.T) {
+	require := require.New(t)
+	l := newTestStateSyncLimiter(3, 2, time.Minute)
+	peerA, peerB, peerC := ids.GenerateTestNodeID(), ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+
+	// A single peer can't use all capacity
+	releaseA, err := l.Acquire(peerA)
+	require.NoError(err)
+	_, err = l.Acquire(peerA)
+	require.NoError(err)
+	_, err = l.Acquire(peerA)
+	require.ErrorIs(err, ErrStateSyncServerBusy)
+
+	// Other peers are limited by the total capacity
+	_, err = l.Acquire(peerB)
+	require.NoError(err)
+	_, err = l.Acquire(peerC)
+	require.ErrorIs(err, ErrStateSyncServerBusy)
+	require.Equal(float64(2), testutil.ToFloat64(l.rejected))
+
+	// Released capacity can be used by any peer
+	releaseA()
+	_, err = l.Acquire(peerC)
+	require.NoError(err)
+	require.Equal(3, l.inflight)
+	require.Equal(map[ids.NodeID]int{peerA: 1, peerB: 1, peerC: 1}, l.peers)
+	require.Zero(testutil.ToFloat64(l.deferred))
+}
+
+func TestStateSyncLimiterDeferral(t *testing.T) {
+	require := require.New(t)
+	l := newTestStateSyncLimiter(0, 0, time.Minute)
+	peer := ids.GenerateTestNodeID()
+
+	// Deferred while a block is built or verified
+	done := l.Begin()
+	_, err := l.Acquire(peer)
+	require.ErrorIs(err, ErrStateSyncServerBusy)
+	require.Equal(float64(1), testutil.ToFloat64(l.deferred))
+	done()
+	release, err := l.Acquire(peer)
+	require.NoError(err)
+	release()
+	require.Empty(l.peers)
+
+	// Served anyway if deferred for too long
+	done = l.Begin()
+	defer done()
+	l.lastServed = time.Now().Add(-time.Minute)
+	_, err = l.Acquire(peer)
+	require.NoError(err)
+	_, err = l.Acquire(peer)
+	require.ErrorIs(err, ErrStateSyncServerBusy)
+	require.Equal(float64(2), testutil.ToFloat64(l.deferred))
+
+	// Never deferred without a max deferral
+	l = newTestStateSyncLimiter(0, 0, 0)
+	defer l.Begin()()
+	_, err = l.Acquire(peer)
+	require.NoError(err)
+	require.Zero(testutil.ToFloat64(l.deferred))
+	require.Zero(testutil.ToFloat64(l.rejected))
+}
+
+// TestStateSyncLimiterLoad syncs 3 peers (each sending more requests than its
+// quota) from a server that is concurrently building and verifying blocks.
+func TestStateSyncLimiterLoad(t *testing.T) {
+	// [concurrency] is below the combined [peerQuota] of all syncers (so the
+	// total limit is reached) but above that of any [syncers]-1 of them (so
+	// no syncer can be starved by the others).
+	const (
+		concurrency = 5
+		peerQuota   = 2
+		syncers     = 3
+		requests    = 4 // in flight from each syncer
+		blocks      = 50
+	)
+	require := require.New(t)
+	l := newTestStateSyncLimiter(concurrency, peerQuota, time.Minute)
+
+	var (
+		stop   = make(chan struct{})
+		wg     sync.WaitGroup
+		phase  atomic.Int64 // odd while a block is built or verified
+		busy   atomic.Int64 // requests admitted while a block was processed
+		served [syncers]atomic.Int64
+
+		l2       sync.Mutex
+		inflight = map[int]int{}
+		maxPeer  int
+		total    int
+		maxTotal int
+	)
+	for i := 0; i < syncers; i++ {
+		nodeID := ids.GenerateTestNodeID()
+		for j := 0; j < requests; j++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					before := phase.Load()
+					release, err := l.Acquire(nodeID)
+					if err != nil {
+						// Retry with backoff (like the sync client)
+						time.Sleep(100 * time.Microsecond)
+						continue
+					}
+					if before%2 == 1 && phase.Load() == before {
+						busy.Add(1)
+					}
+					l2.Lock()
+					inflight[i]++
+					total++
+					maxPeer = max(maxPeer, inflight[i])
+					maxTotal = max(maxTotal, total)
+					l2.Unlock()
+
+					time.Sleep(100 * time.Microsecond) // generate proof
+
+					l2.Lock()
+					inflight[i]--
+					total--
+					l2.Unlock()
+					release()
+					served[i].Add(1)
+				}
+			}(i)
+		}
+	}
+
+	// Build and verify blocks while peers sync
+	for i := 0; i < blocks; i++ {
+		done := l.Begin()
+		phase.Add(1)
+		time.Sleep(time.Millisecond)
+		phase.Add(1)
+		done()
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+
+	require.Zero(busy.Load())
+	require.LessOrEqual(maxTotal, concurrency)
+	require.LessOrEqual(maxPeer, peerQuota)
+	for i := range served {
+		require.Positive(served[i].Load(), "syncer=%d", i)
+	}
+	require.Positive(testutil.ToFloat64(l.deferred))
+	require.Positive(testutil.ToFloat64(l.rejected))
+}
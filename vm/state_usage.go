@@ -0,0 +1,269 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/rpc"
+)
+
+// stateUsageCheckStop is how many keys are counted during a rebuild between
+// checks for shutdown.
+const stateUsageCheckStop = 4_096
+
+var (
+	stateUsageKey = []byte("state_usage")
+
+	errStateUsageRebuildStopped = errors.New("state usage rebuild stopped")
+)
+
+// stateUsage maintains approximate [chain.StateUsage] counters for each
+// prefix in [registry] (and for all unregistered keys).
+//
+// Counters are updated with the [chain.StatelessBlock.StateUsageDiff] of each
+// accepted block and persisted in the accept batch (so they are always
+// written with the last accepted block). They drift when state is changed
+// outside of execution:
+//   - blocks accepted during state sync (or committed before a restart but
+//     not yet accepted) have no diff
+//   - state sync replaces the entire state
+//
+// To correct drift, counters are rebuilt by iterating over the full state in
+// the background (after state sync, if no counters were persisted, or if
+// [Config.GetRebuildStateUsage] is enabled). Blocks accepted during a rebuild
+// may be counted twice (if iteration observes their changes), so counters are
+// only expected to be accurate within a few percent.
+type stateUsage struct {
+	log      logging.Logger
+	registry *chain.StatePrefixRegistry
+
+	l          sync.RWMutex
+	usage      []chain.StateUsage // indexed like [chain.StatePrefixRegistry.Index]
+	height     uint64             // height of the last accepted block applied
+	loaded     bool               // false until counters are loaded or rebuilt
+	rebuilding bool
+	pending    []chain.StateUsage // diffs accepted while rebuilding
+}
+
+// newStateUsage loads the counters persisted in [db]. If there are none (or
+// they don't include a registered prefix), counters must be rebuilt.
+func newStateUsage(log logging.Logger, registry *chain.StatePrefixRegistry, db database.KeyValueReader) (*stateUsage, error) {
+	u := &stateUsage{
+		log:      log,
+		registry: registry,
+		usage:    make([]chain.StateUsage, len(registry.Prefixes())+1),
+	}
+	v, err := db.Get(stateUsageKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return u, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, u.parse(v)
+}
+
+// bytes encodes the height of the last block applied, the counters of each
+// registered prefix (with the prefix), and the counters of unregistered keys.
+func (u *stateUsage) bytes() []byte {
+	prefixes := u.registry.Prefixes()
+	p := codec.NewWriter(0, consts.MaxInt)
+	p.PackUint64(u.height)
+	p.PackInt(len(prefixes))
+	for i, prefix := range prefixes {
+		p.PackBytes(prefix.Prefix)
+		p.PackInt64(u.usage[i].Keys)
+		p.PackInt64(u.usage[i].Bytes)
+	}
+	p.PackInt64(u.usage[len(prefixes)].Keys)
+	p.PackInt64(u.usage[len(prefixes)].Bytes)
+	return p.Bytes()
+}
+
+// parse populates the counters from [v] (if every registered prefix has been
+// persisted). Counters persisted for prefixes that are no longer registered
+// are added to those of unregistered keys.
+func (u *stateUsage) parse(v []byte) error {
+	prefixes := u.registry.Prefixes()
+	p := codec.NewReader(v, consts.MaxInt)
+	height := p.UnpackUint64(false)
+	stored := p.UnpackInt(false)
+	usage := make([]chain.StateUsage, len(prefixes)+1)
+	unregistered := &usage[len(prefixes)]
+	found := 0
+	for i := 0; i < stored && p.Err() == nil; i++ {
+		var prefix []byte
+		p.UnpackBytes(-1, true, &prefix)
+		keys, bytes := p.UnpackInt64(false), p.UnpackInt64(false)
+		idx := slices.IndexFunc(prefixes, func(sp chain.StatePrefix) bool { return string(sp.Prefix) == string(prefix) })
+		if idx < 0 {
+			unregistered.Add(chain.StateUsage{Keys: keys, Bytes: bytes})
+			continue
+		}
+		usage[idx] = chain.StateUsage{Keys: keys, Bytes: bytes}
+		found++
+	}
+	unregistered.Add(chain.StateUsage{Keys: p.UnpackInt64(false), Bytes: p.UnpackInt64(false)})
+	if !p.Empty() {
+		return fmt.Errorf("%w: state usage has %d extra bytes", ErrCorruptIndex, len(v)-p.Offset())
+	}
+	if err := p.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrCorruptIndex, err)
+	}
+	if found != len(prefixes) {
+		u.log.Info("state usage prefixes changed", zap.Int("stored", stored), zap.Int("registered", len(prefixes)))
+		return nil
+	}
+	u.usage = usage
+	u.height = height
+	u.loaded = true
+	return nil
+}
+
+// accept applies the [chain.StatelessBlock.StateUsageDiff] of [blk] and adds
+// the updated counters to [batch] (if they have been loaded or rebuilt).
+func (u *stateUsage) accept(batch database.Batch, blk *chain.StatelessBlock) error {
+	u.l.Lock()
+	defer u.l.Unlock()
+
+	u.height = blk.Height()
+	if diff := blk.StateUsageDiff(); diff != nil {
+		target := u.usage
+		if u.rebuilding {
+			target = u.pending
+		}
+		for i, d := range diff {
+			target[i].Add(d)
+		}
+	}
+	if !u.loaded {
+		return nil
+	}
+	return batch.Put(stateUsageKey, u.bytes())
+}
+
+// needsRebuild returns true if no counters were loaded (and no rebuild has
+// completed).
+func (u *stateUsage) needsRebuild() bool {
+	u.l.RLock()
+	defer u.l.RUnlock()
+
+	return !u.loaded
+}
+
+// rebuild recomputes the counters by iterating over all keys in [db]. Diffs
+// of blocks accepted during iteration are applied once it completes.
+//
+// The counters are persisted with the next accepted block.
+func (u *stateUsage) rebuild(db database.Iteratee, stop <-chan struct{}) error {
+	u.l.Lock()
+	if u.rebuilding {
+		u.l.Unlock()
+		return nil
+	}
+	u.rebuilding = true
+	u.pending = make([]chain.StateUsage, len(u.usage))
+	u.l.Unlock()
+
+	usage, err := u.iterate(db, stop)
+
+	u.l.Lock()
+	defer u.l.Unlock()
+	if err == nil {
+		for i, d := range u.pending {
+			usage[i].Add(d)
+		}
+		u.usage = usage
+		u.loaded = true
+	} else {
+		// Diffs accepted during iteration are still applied to the previous
+		// counters
+		for i, d := range u.pending {
+			u.usage[i].Add(d)
+		}
+	}
+	u.pending = nil
+	u.rebuilding = false
+	return err
+}
+
+func (u *stateUsage) iterate(db database.Iteratee, stop <-chan struct{}) ([]chain.StateUsage, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	usage := make([]chain.StateUsage, len(u.usage))
+	for i := 1; it.Next(); i++ {
+		if i%stateUsageCheckStop == 0 {
+			select {
+			case <-stop:
+				return nil, errStateUsageRebuildStopped
+			default:
+			}
+		}
+		k := it.Key()
+		usage[u.registry.Index(k)].Add(chain.KeyUsage(k, it.Value()))
+	}
+	return usage, it.Error()
+}
+
+// report returns the current counters of each registered prefix (and their
+// totals).
+func (u *stateUsage) report() *rpc.StateUsageReport {
+	u.l.RLock()
+	defer u.l.RUnlock()
+
+	prefixes := u.registry.Prefixes()
+	report := &rpc.StateUsageReport{
+		Height:       u.height,
+		Rebuilding:   u.rebuilding,
+		Prefixes:     make([]*rpc.PrefixUsage, 0, len(prefixes)),
+		Unregistered: u.usage[len(prefixes)],
+	}
+	for i, prefix := range prefixes {
+		report.Prefixes = append(report.Prefixes, &rpc.PrefixUsage{
+			Name:   prefix.Name,
+			Prefix: prefix.Prefix,
+			Usage:  u.usage[i],
+		})
+	}
+	for _, usage := range u.usage {
+		report.Total.Add(usage)
+	}
+	return report
+}
+
+// maybeRebuildStateUsage rebuilds the state usage counters in the background
+// if they may have drifted (or were never computed). This must be called
+// once state sync has completed.
+func (vm *VM) maybeRebuildStateUsage() {
+	if vm.stateUsage == nil {
+		return
+	}
+	synced := vm.stateSyncClient.Started()
+	if !synced && !vm.stateUsage.needsRebuild() && !vm.config.GetRebuildStateUsage() {
+		return
+	}
+	go func() {
+		log := vm.snowCtx.Log
+		log.Info("rebuilding state usage", zap.Bool("synced", synced))
+		start := time.Now()
+		if err := vm.stateUsage.rebuild(vm.stateDB, vm.stop); err != nil {
+			log.Warn("unable to rebuild state usage", zap.Error(err))
+			return
+		}
+		log.Info("rebuilt state usage", zap.Duration("t", time.Since(start)))
+	}()
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/rpc"
+)
+
+func newStateUsageTestRegistry(require *require.Assertions, prefixes ...string) *chain.StatePrefixRegistry {
+	r := chain.NewStatePrefixRegistry()
+	for _, p := range prefixes {
+		require.NoError(r.Register(p, []byte(p)))
+	}
+	return r
+}
+
+func TestStateUsagePersistence(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	state := memdb.New()
+	require.NoError(state.Put([]byte("a1"), []byte("value")))
+	require.NoError(state.Put([]byte("a2"), []byte("v")))
+	require.NoError(state.Put([]byte("b1"), []byte("value")))
+	require.NoError(state.Put([]byte("c1"), []byte{}))
+
+	// Counters must be rebuilt if none were persisted
+	vmDB := memdb.New()
+	u, err := newStateUsage(logging.NoLog{}, newStateUsageTestRegistry(require, "a", "b"), vmDB)
+	require.NoError(err)
+	require.True(u.needsRebuild())
+	require.NoError(u.rebuild(state, make(chan struct{})))
+	require.False(u.needsRebuild())
+
+	expected := &rpc.StateUsageReport{
+		Height: 1,
+		Prefixes: []*rpc.PrefixUsage{
+			{Name: "a", Prefix: []byte("a"), Usage: chain.StateUsage{Keys: 2, Bytes: 2 + 5 + 2 + 1}},
+			{Name: "b", Prefix: []byte("b"), Usage: chain.StateUsage{Keys: 1, Bytes: 2 + 5}},
+		},
+		Unregistered: chain.StateUsage{Keys: 1, Bytes: 2},
+		Total:        chain.StateUsage{Keys: 4, Bytes: 19},
+	}
+
+	// Counters are persisted with each accepted block
+	vm := newAcceptBatchTestVM(require, memdb.New(), 0)
+	blks := newAcceptBatchTestBlocks(ctx, require, gomock.NewController(t), vm, 1)
+	batch := vmDB.NewBatch()
+	require.NoError(u.accept(batch, blks[1]))
+	require.NoError(batch.Write())
+	require.Equal(expected, u.report())
+
+	u, err = newStateUsage(logging.NoLog{}, newStateUsageTestRegistry(require, "a", "b"), vmDB)
+	require.NoError(err)
+	require.False(u.needsRebuild())
+	require.Equal(expected, u.report())
+
+	// Counters must be rebuilt if a prefix is registered
+	u, err = newStateUsage(logging.NoLog{}, newStateUsageTestRegistry(require, "a", "b", "c"), vmDB)
+	require.NoError(err)
+	require.True(u.needsRebuild())
+
+	// Counters of prefixes that are no longer registered are unregistered
+	u, err = newStateUsage(logging.NoLog{}, newStateUsageTestRegistry(require, "b"), vmDB)
+	require.NoError(err)
+	require.False(u.needsRebuild())
+	report := u.report()
+	require.Equal(chain.StateUsage{Keys: 3, Bytes: 12}, report.Unregistered)
+	require.Equal(expected.Total, report.Total)
+}
+
+func TestStateUsageRebuildStopped(t *testing.T) {
+	require := require.New(t)
+
+	state := memdb.New()
+	for i := 0; i < stateUsageCheckStop; i++ {
+		require.NoError(state.Put([]byte{'a', byte(i >> 8), byte(i)}, []byte{}))
+	}
+	u, err := newStateUsage(logging.NoLog{}, newStateUsageTestRegistry(require, "a"), memdb.New())
+	require.NoError(err)
+
+	stop := make(chan struct{})
+	close(stop)
+	require.ErrorIs(u.rebuild(state, stop), errStateUsageRebuildStopped)
+	require.True(u.needsRebuild())
+	require.False(u.report().Rebuilding)
+	require.Equal(chain.StateUsage{}, u.report().Total)
+}
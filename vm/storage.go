@@ -32,12 +32,24 @@ func init() {
 const (
 	blockPrefix         = 0x0 // TODO: move to flat files (https://github.com/ava-labs/hypersdk/issues/553)
 	blockIDHeightPrefix = 0x1 // ID -> Height
-	blockHeightIDPrefix = 0x2 // Height -> ID (don't always need full block from disk)
+	blockHeightIDPrefix = 0x2 // Height -> ID|Timestamp (don't always need full block from disk)
+
+	txsByAddressPrefix       = 0x3 // Address|^Height|^Index -> TxID|BlockID|Timestamp
+	txsByAddressHeightPrefix = 0x4 // Height -> rows written to [txsByAddressPrefix]
+
+	txReceiptPrefix = 0x5 // TxID -> Height|Index|Success|Units|Fee
+
+	chainCheckpointPrefix = 0x6 // Height -> Checksum
 )
 
 var (
 	isSyncing    = []byte("is_syncing")
 	lastAccepted = []byte("last_accepted")
+
+	// committedBlock is the last block whose changes were committed to the
+	// accepted state (see [VM.CommitState]). It is deleted by the accept batch
+	// of the block.
+	committedBlock = []byte("committed_block")
 )
 
 func PrefixBlockKey(height uint64) []byte {
@@ -102,58 +114,33 @@ func (vm *VM) shouldComapct(expiryHeight uint64) bool {
 //
 // We store blocks by height because it doesn't cause nearly as much
 // compaction as storing blocks randomly on-disk (when using [block.ID]).
+//
+// All writes (including side indexes) are made with a single batch (see
+// [newAcceptBatch]).
 func (vm *VM) UpdateLastAccepted(blk *chain.StatelessBlock) error {
-	batch := vm.vmDB.NewBatch()
-	bigEndianHeight := binary.BigEndian.AppendUint64(nil, blk.Height())
-	if err := batch.Put(lastAccepted, bigEndianHeight); err != nil {
-		return err
-	}
-	if err := batch.Put(PrefixBlockKey(blk.Height()), blk.Bytes()); err != nil {
-		return err
-	}
-	if err := batch.Put(PrefixBlockIDHeightKey(blk.ID()), bigEndianHeight); err != nil {
+	batch, expiryHeight, err := vm.newAcceptBatch(blk)
+	if err != nil {
 		return err
 	}
-	blkID := blk.ID()
-	if err := batch.Put(PrefixBlockHeightIDKey(blk.Height()), blkID[:]); err != nil {
-		return err
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("%w: unable to update last accepted", err)
 	}
-	expiryHeight := blk.Height() - uint64(vm.config.GetAcceptedBlockWindow())
-	var expired bool
-	if expiryHeight > 0 && expiryHeight < blk.Height() { // ensure we don't free genesis
-		if err := batch.Delete(PrefixBlockKey(expiryHeight)); err != nil {
-			return err
-		}
-		blkID, err := vm.vmDB.Get(PrefixBlockHeightIDKey(expiryHeight))
-		if err == nil {
-			if err := batch.Delete(PrefixBlockIDHeightKey(ids.ID(blkID))); err != nil {
-				return err
-			}
-		} else {
-			vm.Logger().Warn("unable to delete blkID", zap.Uint64("height", expiryHeight), zap.Error(err))
-		}
-		if err := batch.Delete(PrefixBlockHeightIDKey(expiryHeight)); err != nil {
-			return err
-		}
-		expired = true
+	expired := expiryHeight > 0
+	if expired {
 		vm.metrics.deletedBlocks.Inc()
 		vm.Logger().Info("deleted block", zap.Uint64("height", expiryHeight))
 	}
-	if err := batch.Write(); err != nil {
-		return fmt.Errorf("%w: unable to update last accepted", err)
-	}
 	vm.lastAccepted = blk
 	vm.acceptedBlocksByID.Put(blk.ID(), blk)
 	vm.acceptedBlocksByHeight.Put(blk.Height(), blk.ID())
 	if expired && vm.shouldComapct(expiryHeight) {
-		go func() {
-			start := time.Now()
-			if err := vm.CompactDiskBlocks(expiryHeight); err != nil {
-				vm.Logger().Error("unable to compact blocks", zap.Error(err))
-				return
-			}
-			vm.Logger().Info("compacted disk blocks", zap.Uint64("end", expiryHeight), zap.Duration("t", time.Since(start)))
-		}()
+		// Compaction is deferred until the VM is idle to avoid contending
+		// with block acceptance
+		vm.maintenance.Schedule([]byte{blockPrefix}, PrefixBlockKey(expiryHeight))
+		vm.maintenance.Schedule([]byte{blockHeightIDPrefix}, PrefixBlockHeightIDKey(expiryHeight))
+		if vm.config.GetStoreTxsByAddress() {
+			vm.maintenance.Schedule([]byte{txsByAddressHeightPrefix}, PrefixTxsByAddressHeightKey(expiryHeight))
+		}
 	}
 	return nil
 }
@@ -175,7 +162,20 @@ func (vm *VM) GetBlockHeightID(height uint64) (ids.ID, error) {
 	if err != nil {
 		return ids.Empty, err
 	}
-	return ids.ID(b), nil
+	return ids.ID(b[:ids.IDLen]), nil
+}
+
+// GetBlockHeightTimestamp returns the timestamp of the block at [height] from
+// the height index.
+func (vm *VM) GetBlockHeightTimestamp(height uint64) (int64, error) {
+	b, err := vm.vmDB.Get(PrefixBlockHeightIDKey(height))
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != ids.IDLen+consts.Uint64Len {
+		return 0, fmt.Errorf("%w: missing timestamp at height %d", ErrCorruptIndex, height)
+	}
+	return int64(binary.BigEndian.Uint64(b[ids.IDLen:])), nil
 }
 
 func (vm *VM) GetBlockIDHeight(blkID ids.ID) (uint64, error) {
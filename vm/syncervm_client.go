@@ -6,9 +6,12 @@ package vm
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -19,15 +22,63 @@ import (
 	avasync "github.com/ava-labs/avalanchego/x/sync"
 )
 
+// SyncPhase is the progress of state sync (see [VM.SyncState]).
+type SyncPhase uint8
+
+const (
+	// SyncPending is the phase before the engine provides a syncable block
+	// (or skips state sync).
+	SyncPending SyncPhase = iota
+	// Syncing is the phase where state is fetched from peers. Accepted
+	// blocks update the sync target instead of being executed.
+	Syncing
+	// SyncFinishing is the phase after the sync target has been reached and
+	// before the last accepted block is updated to it. Accepted blocks wait
+	// for [Synced] and are then executed.
+	SyncFinishing
+	// Synced is the phase where the state is ready (whether state sync
+	// completed or was skipped). Accepted blocks are executed.
+	Synced
+)
+
+func (p SyncPhase) String() string {
+	switch p {
+	case SyncPending:
+		return "pending"
+	case Syncing:
+		return "syncing"
+	case SyncFinishing:
+		return "finishing"
+	case Synced:
+		return "synced"
+	default:
+		return "unknown"
+	}
+}
+
+// syncManager is the subset of [avasync.Manager] used by [stateSyncerClient].
+type syncManager interface {
+	Start(context.Context) error
+	Wait(context.Context) error
+	UpdateSyncTarget(ids.ID) error
+	Close()
+}
+
 type stateSyncerClient struct {
 	vm          *VM
 	gatherer    avametrics.MultiGatherer
-	syncManager *avasync.Manager
+	syncManager syncManager
 
 	// tracks the sync target so we can update last accepted
 	// block when sync completes.
+	//
+	// [l] ensures a target updated while the sync is finishing is either
+	// synced to (and accepted by [finishSync]) or rejected by [syncManager]
+	// (and executed by the caller), but never both or neither.
+	l             sync.Mutex
 	target        *chain.StatelessBlock
 	targetUpdated bool
+	finishing     bool
 
 	// State Sync results
 	init         bool
@@ -72,19 +123,19 @@ func (s *stateSyncerClient) AcceptedSyncableBlock(
 	sb *chain.SyncableBlock,
 ) (block.StateSyncMode, error) {
 	s.init = true
-	s.vm.snowCtx.Log.Info("accepted syncable block",
+	s.vm.SyncLogger().Info("accepted syncable block",
 		zap.Uint64("height", sb.Height()),
-		zap.Stringer("blockID", sb.ID()),
+		zap.Stringer("blkID", sb.ID()),
 	)
 
 	// If we did not finish syncing, we must state sync.
 	syncing, err := s.vm.GetDiskIsSyncing()
 	if err != nil {
-		s.vm.snowCtx.Log.Warn("could not determine if syncing", zap.Error(err))
+		s.vm.SyncLogger().Warn("could not determine if syncing", zap.Error(err))
 		return block.StateSyncSkipped, err
 	}
 	if !syncing && (s.vm.lastAccepted.Hght+s.vm.config.GetStateSyncMinBlocks() > sb.Height()) {
-		s.vm.snowCtx.Log.Info(
+		s.vm.SyncLogger().Info(
 			"bypassing state sync",
 			zap.Uint64("lastAccepted", s.vm.lastAccepted.Hght),
 			zap.Uint64("syncableHeight", sb.Height()),
@@ -111,7 +162,7 @@ func (s *stateSyncerClient) AcceptedSyncableBlock(
 	// MerkleDB will handle clearing any keys on-disk that are no
 	// longer necessary.
 	s.target = sb.StatelessBlock
-	s.vm.snowCtx.Log.Info(
+	s.vm.SyncLogger().Info(
 		"starting state sync",
 		zap.Uint64("height", s.target.Hght),
 		zap.Stringer("summary", sb),
@@ -131,24 +182,25 @@ func (s *stateSyncerClient) AcceptedSyncableBlock(
 	syncClient, err := avasync.NewClient(&avasync.ClientConfig{
 		BranchFactor:     s.vm.genesis.GetStateBranchFactor(),
 		NetworkClient:    s.vm.stateSyncNetworkClient,
-		Log:              s.vm.snowCtx.Log,
+		Log:              s.vm.SyncLogger(),
 		Metrics:          metrics,
 		StateSyncNodeIDs: nil, // pull from all
 	})
 	if err != nil {
 		return block.StateSyncSkipped, err
 	}
-	s.syncManager, err = avasync.NewManager(avasync.ManagerConfig{
+	manager, err := avasync.NewManager(avasync.ManagerConfig{
 		BranchFactor:          s.vm.genesis.GetStateBranchFactor(),
 		DB:                    s.vm.stateDB,
 		Client:                syncClient,
 		SimultaneousWorkLimit: s.vm.config.GetStateSyncParallelism(),
-		Log:                   s.vm.snowCtx.Log,
+		Log:                   s.vm.SyncLogger(),
 		TargetRoot:            sb.StateRoot,
 	})
 	if err != nil {
 		return block.StateSyncSkipped, err
 	}
+	s.syncManager = manager // only set on success ([StateReady] checks for nil)
 
 	// Persist that the node has started syncing.
 	//
@@ -170,43 +222,54 @@ func (s *stateSyncerClient) AcceptedSyncableBlock(
 
 	// Kickoff state syncing from [s.target]
 	if err := s.syncManager.Start(context.Background()); err != nil {
-		s.vm.snowCtx.Log.Warn("not starting state syncing", zap.Error(err))
+		s.vm.SyncLogger().Warn("not starting state syncing", zap.Error(err))
 		return block.StateSyncSkipped, err
 	}
-	go func() {
-		// wait for the work to complete on this goroutine
-		//
-		// [syncManager] guarantees this will always return so it isn't possible to
-		// deadlock.
-		s.stateSyncErr = s.syncManager.Wait(context.Background())
-		s.vm.snowCtx.Log.Info("state sync done", zap.Error(s.stateSyncErr))
-		if s.stateSyncErr == nil {
-			// if the sync was successful, update the last accepted pointers.
-			s.stateSyncErr = s.finishSync()
-		}
-		// notify the engine the VM is ready to participate
-		// in voting and it can verify blocks.
-		//
-		// This function will send a message to the VM when it has processed at least
-		// [ValidityWindow] blocks.
-		s.doneOnce.Do(func() {
-			close(s.done)
-		})
-	}()
+	go s.wait()
 	// TODO: engine will mark VM as ready when we return
 	// [block.StateSyncDynamic]. This should change in v1.9.11.
 	return block.StateSyncDynamic, nil
 }
 
+// wait blocks until [syncManager] completes, updates the last accepted
+// pointers (if successful), and then marks the state as ready.
+func (s *stateSyncerClient) wait() {
+	// wait for the work to complete on this goroutine
+	//
+	// [syncManager] guarantees this will always return so it isn't possible to
+	// deadlock.
+	s.stateSyncErr = s.syncManager.Wait(context.Background())
+	s.vm.SyncLogger().Info("state sync done", zap.Error(s.stateSyncErr))
+	if s.stateSyncErr == nil {
+		// if the sync was successful, update the last accepted pointers.
+		s.stateSyncErr = s.finishSync()
+	}
+	// notify the engine the VM is ready to participate
+	// in voting and it can verify blocks.
+	//
+	// This function will send a message to the VM when it has processed at least
+	// [ValidityWindow] blocks.
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+}
+
 // finishSync is responsible for updating disk and memory pointers
 func (s *stateSyncerClient) finishSync() error {
-	if s.targetUpdated {
+	// [syncManager] no longer accepts target updates, so [target] is the
+	// block the state was synced to.
+	s.l.Lock()
+	s.finishing = true
+	target, targetUpdated := s.target, s.targetUpdated
+	s.l.Unlock()
+
+	if targetUpdated {
 		// Will look like block on start accepted then last block before beginning
 		// bootstrapping is accepted.
 		//
 		// NOTE: There may be a number of verified but unaccepted blocks above this
 		// block.
-		s.target.MarkAccepted(context.Background())
+		target.MarkAccepted(context.Background())
 	}
 	return s.vm.PutDiskIsSyncing(false)
 }
@@ -255,12 +318,66 @@ func (s *stateSyncerClient) StateReady() bool {
 	return s.syncManager == nil
 }
 
+// Phase returns the [SyncPhase] of the client. The phase is [Synced] if and
+// only if [StateReady] is true.
+func (s *stateSyncerClient) Phase() SyncPhase {
+	if s.StateReady() {
+		return Synced
+	}
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	switch {
+	case !s.init:
+		return SyncPending
+	case s.finishing:
+		return SyncFinishing
+	default:
+		return Syncing
+	}
+}
+
 // UpdateSyncTarget returns a boolean indicating if the root was
 // updated and an error if one occurred while updating the root.
+//
+// If false is returned (without an error), the sync has completed and the
+// caller must execute [b].
 func (s *stateSyncerClient) UpdateSyncTarget(b *chain.StatelessBlock) (bool, error) {
+	updated, err := s.updateSyncTarget(b)
+	if err != nil || updated {
+		return updated, err
+	}
+
+	// Wait for goroutine to exit for consistent return values with IsSyncing
+	grace := s.vm.config.GetStateSyncAcceptGracePeriod()
+	if grace <= 0 {
+		<-s.done
+		return false, nil
+	}
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-s.done:
+		return false, nil
+	case <-timer.C:
+		return false, fmt.Errorf("%w: sync did not finish within %s of accepting %s", ErrStateSyncing, grace, b.ID())
+	}
+}
+
+func (s *stateSyncerClient) updateSyncTarget(b *chain.StatelessBlock) (bool, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.syncManager == nil {
+		// State sync was skipped (or failed to start), so the state is only
+		// missing if the engine hasn't told us yet
+		if !s.StateReady() {
+			return false, fmt.Errorf("%w: cannot accept %s", ErrStateMissing, b.ID())
+		}
+		return false, nil
+	}
 	err := s.syncManager.UpdateSyncTarget(b.StateRoot)
 	if errors.Is(err, avasync.ErrAlreadyClosed) {
-		<-s.done          // Wait for goroutine to exit for consistent return values with IsSyncing
 		return false, nil // Sync finished before update
 	}
 	if err != nil {
@@ -0,0 +1,268 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/config"
+
+	avasync "github.com/ava-labs/avalanchego/x/sync"
+)
+
+// syncTestManager is a [syncManager] that completes when [finish] is called
+// and returns from [Wait] when [release] is called.
+type syncTestManager struct {
+	l      sync.Mutex
+	root   ids.ID
+	closed bool
+
+	released chan struct{}
+}
+
+func newSyncTestManager() *syncTestManager {
+	return &syncTestManager{released: make(chan struct{})}
+}
+
+func (*syncTestManager) Start(context.Context) error { return nil }
+
+func (m *syncTestManager) Wait(context.Context) error {
+	<-m.released
+	return nil
+}
+
+func (m *syncTestManager) UpdateSyncTarget(root ids.ID) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.closed {
+		return avasync.ErrAlreadyClosed
+	}
+	m.root = root
+	return nil
+}
+
+func (*syncTestManager) Close() {}
+
+// finish stops accepting target updates (like the sync reaching its target).
+func (m *syncTestManager) finish() {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.closed = true
+}
+
+func (m *syncTestManager) release() {
+	m.finish()
+	close(m.released)
+}
+
+// syncTestVM records the blocks marked accepted by the [stateSyncerClient]
+// and blocks in [Accepted] until [unblock] is closed.
+type syncTestVM struct {
+	chain.VM
+
+	l        sync.Mutex
+	accepted []uint64
+	unblock  chan struct{}
+}
+
+func (*syncTestVM) Tracer() trace.Tracer                     { return trace.Noop }
+func (*syncTestVM) Now() time.Time                           { return time.Now() }
+func (*syncTestVM) LastAcceptedBlock() *chain.StatelessBlock { return nil }
+func (*syncTestVM) Rules(int64) chain.Rules                  { return noForkTestRules{} }
+
+func (vm *syncTestVM) Accepted(_ context.Context, b *chain.StatelessBlock) {
+	<-vm.unblock
+
+	vm.l.Lock()
+	defer vm.l.Unlock()
+	vm.accepted = append(vm.accepted, b.Height())
+}
+
+func (vm *syncTestVM) Accepts() []uint64 {
+	vm.l.Lock()
+	defer vm.l.Unlock()
+
+	return vm.accepted
+}
+
+type syncTestConfig struct {
+	*config.Config
+
+	grace time.Duration
+}
+
+func (c *syncTestConfig) GetStateSyncAcceptGracePeriod() time.Duration { return c.grace }
+
+// newSyncTestClient returns a client syncing with [manager] (if not nil) and
+// [count] blocks (after a genesis target) parsed with [blkVM].
+func newSyncTestClient(
+	require *require.Assertions,
+	manager *syncTestManager,
+	grace time.Duration,
+	blkVM *syncTestVM,
+	count int,
+) (*stateSyncerClient, []*chain.StatelessBlock) {
+	vm := &VM{
+		config:  &syncTestConfig{Config: &config.Config{}, grace: grace},
+		loggers: newLoggers(logging.NoLog{}, ids.Empty),
+		vmDB:    memdb.New(),
+	}
+	blks := make([]*chain.StatelessBlock, 0, count+1)
+	for i := 0; i <= count; i++ {
+		blk, err := chain.ParseStatefulBlock(context.TODO(), &chain.StatefulBlock{
+			Hght:      uint64(i),
+			StateRoot: ids.GenerateTestID(),
+			Txs:       []*chain.Transaction{},
+		}, nil, choices.Processing, blkVM)
+		require.NoError(err)
+		blks = append(blks, blk)
+	}
+
+	s := vm.NewStateSyncClient(nil)
+	vm.stateSyncClient = s
+	if manager != nil {
+		s.init = true
+		s.startedSync = true
+		s.syncManager = manager
+		s.target = blks[0]
+		go s.wait()
+	}
+	return s, blks
+}
+
+func TestStateSyncAcceptDuringSync(t *testing.T) {
+	require := require.New(t)
+
+	manager := newSyncTestManager()
+	blkVM := &syncTestVM{unblock: make(chan struct{})}
+	close(blkVM.unblock)
+	s, blks := newSyncTestClient(require, manager, 0, blkVM, 2)
+	require.Equal(Syncing, s.vm.SyncState())
+
+	// Each accepted block becomes the sync target
+	for _, blk := range blks[1:] {
+		updated, err := s.UpdateSyncTarget(blk)
+		require.NoError(err)
+		require.True(updated)
+		require.Equal(blk.StateRoot, manager.root)
+	}
+	require.Empty(blkVM.Accepts())
+
+	// Only the last target is marked accepted once the sync finishes
+	manager.release()
+	<-s.done
+	require.Equal(Synced, s.vm.SyncState())
+	require.Equal([]uint64{2}, blkVM.Accepts())
+	syncing, err := s.vm.GetDiskIsSyncing()
+	require.NoError(err)
+	require.False(syncing)
+}
+
+func TestStateSyncAcceptAtBoundary(t *testing.T) {
+	require := require.New(t)
+
+	manager := newSyncTestManager()
+	blkVM := &syncTestVM{unblock: make(chan struct{})}
+	s, blks := newSyncTestClient(require, manager, 0, blkVM, 2)
+	updated, err := s.UpdateSyncTarget(blks[1])
+	require.NoError(err)
+	require.True(updated)
+
+	// The sync reaches its target while [blks[2]] is being accepted, so it
+	// can't become the target and must wait for the sync to finish...
+	manager.finish()
+	type result struct {
+		updated bool
+		err     error
+	}
+	results := make(chan result, 1)
+	go func() {
+		updated, err := s.UpdateSyncTarget(blks[2])
+		results <- result{updated, err}
+	}()
+	select {
+	case <-results:
+		require.FailNow("accepted before sync finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.Equal(Syncing, s.vm.SyncState())
+
+	// ...which marks the previous target accepted...
+	close(manager.released)
+	require.Eventually(func() bool { return s.vm.SyncState() == SyncFinishing }, time.Second, time.Millisecond)
+	close(blkVM.unblock)
+
+	// ...before the caller executes it
+	r := <-results
+	require.NoError(r.err)
+	require.False(r.updated)
+	require.Equal(Synced, s.vm.SyncState())
+	require.Equal([]uint64{1}, blkVM.Accepts())
+	require.Equal(blks[1].StateRoot, manager.root)
+}
+
+func TestStateSyncAcceptAfterSync(t *testing.T) {
+	require := require.New(t)
+
+	manager := newSyncTestManager()
+	blkVM := &syncTestVM{unblock: make(chan struct{})}
+	close(blkVM.unblock)
+	s, blks := newSyncTestClient(require, manager, 0, blkVM, 1)
+	manager.release()
+	<-s.done
+
+	updated, err := s.UpdateSyncTarget(blks[1])
+	require.NoError(err)
+	require.False(updated)
+	require.Empty(blkVM.Accepts())
+}
+
+func TestStateSyncAcceptGracePeriod(t *testing.T) {
+	require := require.New(t)
+
+	// The sync reaches its target but never finishes
+	manager := newSyncTestManager()
+	manager.finish()
+	blkVM := &syncTestVM{unblock: make(chan struct{})}
+	s, blks := newSyncTestClient(require, manager, 10*time.Millisecond, blkVM, 1)
+
+	_, err := s.UpdateSyncTarget(blks[1])
+	require.ErrorIs(err, ErrStateSyncing)
+
+	// Stop the sync goroutine
+	close(blkVM.unblock)
+	close(manager.released)
+	<-s.done
+}
+
+func TestStateSyncAcceptSkipped(t *testing.T) {
+	require := require.New(t)
+
+	blkVM := &syncTestVM{unblock: make(chan struct{})}
+	s, blks := newSyncTestClient(require, nil, 0, blkVM, 1)
+	require.Equal(SyncPending, s.vm.SyncState())
+
+	// There is no state until the engine skips the sync
+	_, err := s.UpdateSyncTarget(blks[1])
+	require.ErrorIs(err, ErrStateMissing)
+
+	s.ForceDone()
+	require.Equal(Synced, s.vm.SyncState())
+	updated, err := s.UpdateSyncTarget(blks[1])
+	require.NoError(err)
+	require.False(updated)
+}
@@ -17,7 +17,7 @@ import (
 // If no summary is available, [database.ErrNotFound] must be returned.
 func (vm *VM) GetLastStateSummary(context.Context) (block.StateSummary, error) {
 	summary := chain.NewSyncableBlock(vm.LastAcceptedBlock())
-	vm.Logger().Info("Serving syncable block at latest height", zap.Stringer("summary", summary))
+	vm.SyncLogger().Info("Serving syncable block at latest height", zap.Stringer("summary", summary))
 	return summary, nil
 }
 
@@ -34,7 +34,7 @@ func (vm *VM) GetStateSummary(ctx context.Context, height uint64) (block.StateSu
 		return nil, err
 	}
 	summary := chain.NewSyncableBlock(block)
-	vm.Logger().Info("Serving syncable block at requested height",
+	vm.SyncLogger().Info("Serving syncable block at requested height",
 		zap.Uint64("height", height),
 		zap.Stringer("summary", summary),
 	)
@@ -47,6 +47,6 @@ func (vm *VM) ParseStateSummary(ctx context.Context, bytes []byte) (block.StateS
 		return nil, err
 	}
 	summary := chain.NewSyncableBlock(sb)
-	vm.Logger().Info("parsed state summary", zap.Stringer("summary", summary))
+	vm.SyncLogger().Info("parsed state summary", zap.Stringer("summary", summary))
 	return summary, nil
 }
@@ -0,0 +1,109 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+const txReceiptLen = consts.Uint64Len + consts.Uint32Len + consts.BoolLen + fees.DimensionsLen + consts.Uint64Len
+
+// Receipt describes the execution of an accepted transaction.
+type Receipt struct {
+	// Height is the height of the block that executed the transaction (which
+	// is the child of the block that included it if
+	// [chain.Rules.GetDelayedExecution] is enabled).
+	Height  uint64          `json:"height"`
+	Index   uint32          `json:"index"`
+	Success bool            `json:"success"`
+	Units   fees.Dimensions `json:"units"`
+	Fee     uint64          `json:"fee"`
+}
+
+func PrefixTxReceiptKey(txID ids.ID) []byte {
+	k := make([]byte, 1+ids.IDLen)
+	k[0] = txReceiptPrefix
+	copy(k[1:], txID[:])
+	return k
+}
+
+func (r *Receipt) bytes() []byte {
+	v := make([]byte, 0, txReceiptLen)
+	v = binary.BigEndian.AppendUint64(v, r.Height)
+	v = binary.BigEndian.AppendUint32(v, r.Index)
+	if r.Success {
+		v = append(v, 0x1)
+	} else {
+		v = append(v, 0x0)
+	}
+	v = append(v, r.Units.Bytes()...)
+	return binary.BigEndian.AppendUint64(v, r.Fee)
+}
+
+func parseReceipt(v []byte) (*Receipt, error) {
+	if len(v) != txReceiptLen {
+		return nil, fmt.Errorf("%w: receipt length=%d", ErrCorruptIndex, len(v))
+	}
+	units, err := fees.UnpackDimensions(v[consts.Uint64Len+consts.Uint32Len+consts.BoolLen : txReceiptLen-consts.Uint64Len])
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{
+		Height:  binary.BigEndian.Uint64(v),
+		Index:   binary.BigEndian.Uint32(v[consts.Uint64Len:]),
+		Success: v[consts.Uint64Len+consts.Uint32Len] == 0x1,
+		Units:   units,
+		Fee:     binary.BigEndian.Uint64(v[txReceiptLen-consts.Uint64Len:]),
+	}, nil
+}
+
+func (*VM) putTxReceipt(batch database.Batch, txID ids.ID, receipt *Receipt) error {
+	return batch.Put(PrefixTxReceiptKey(txID), receipt.bytes())
+}
+
+// indexTxReceipts adds a receipt to [batch] for each transaction executed by
+// [blk].
+//
+// If [blk] was not executed by this node (i.e. it was accepted during state
+// sync), its results are not known and no receipts are written.
+func (vm *VM) indexTxReceipts(batch database.Batch, blk *chain.StatelessBlock) error {
+	results := blk.Results()
+	if results == nil {
+		return nil
+	}
+	for i, tx := range blk.ExecutedTxs() {
+		result := results[i]
+		if err := vm.putTxReceipt(batch, tx.ID(), &Receipt{
+			Height:  blk.Hght,
+			Index:   uint32(i),
+			Success: result.Success,
+			Units:   result.Units,
+			Fee:     result.Fee,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTxReceipt returns the [Receipt] of [txID] (or [database.ErrNotFound] if
+// it was not executed by an accepted block while receipts were stored).
+//
+// Receipts are not pruned when the block that executed them is.
+func (vm *VM) GetTxReceipt(_ context.Context, txID ids.ID) (*Receipt, error) {
+	v, err := vm.vmDB.Get(PrefixTxReceiptKey(txID))
+	if err != nil {
+		return nil, err
+	}
+	return parseReceipt(v)
+}
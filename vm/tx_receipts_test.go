@@ -0,0 +1,74 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/fees"
+)
+
+func TestTxReceipts(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	vm := VM{vmDB: memdb.New()}
+	receipts := map[ids.ID]*Receipt{
+		ids.GenerateTestID(): {
+			Height:  1,
+			Index:   0,
+			Success: true,
+			Units:   fees.Dimensions{1, 2, 3, 4, 5},
+			Fee:     100,
+		},
+		ids.GenerateTestID(): {
+			Height:  1,
+			Index:   1,
+			Success: false,
+			Units:   fees.Dimensions{6, 7, 8, 9, 10},
+			Fee:     200,
+		},
+		ids.GenerateTestID(): {
+			Height: 2,
+			Index:  0,
+			Units:  fees.Dimensions{},
+		},
+	}
+	batch := vm.vmDB.NewBatch()
+	for txID, receipt := range receipts {
+		require.NoError(vm.putTxReceipt(batch, txID, receipt))
+	}
+	require.NoError(batch.Write())
+
+	// Receipts of included txs are returned
+	for txID, receipt := range receipts {
+		r, err := vm.GetTxReceipt(ctx, txID)
+		require.NoError(err)
+		require.Equal(receipt, r)
+	}
+
+	// Unknown txs are not found
+	_, err := vm.GetTxReceipt(ctx, ids.GenerateTestID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Corrupt receipts are rejected
+	txID := ids.GenerateTestID()
+	require.NoError(vm.vmDB.Put(PrefixTxReceiptKey(txID), []byte{0x1}))
+	_, err = vm.GetTxReceipt(ctx, txID)
+	require.ErrorIs(err, ErrCorruptIndex)
+
+	// Blocks that were not executed by this node don't write receipts
+	batch = vm.vmDB.NewBatch()
+	require.NoError(vm.indexTxReceipts(batch, &chain.StatelessBlock{
+		StatefulBlock: &chain.StatefulBlock{Hght: 3},
+	}))
+	require.Zero(batch.Size())
+}
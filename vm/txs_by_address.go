@@ -0,0 +1,169 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/rpc"
+)
+
+const (
+	txsByAddressCursorLen = consts.Uint64Len + consts.Uint32Len
+	txsByAddressValueLen  = ids.IDLen + ids.IDLen + consts.Int64Len
+	txsByAddressEntryLen  = codec.AddressLen + consts.Uint32Len
+)
+
+// PrefixTxsByAddressKey returns the key of the index row for the transaction at
+// [index] in the block at [height] that involves [addr].
+//
+// Height and index are stored inverted so that iterating over the rows of an
+// address yields the most recent transactions first.
+func PrefixTxsByAddressKey(addr codec.Address, height uint64, index uint32) []byte {
+	k := make([]byte, 1+codec.AddressLen+txsByAddressCursorLen)
+	k[0] = txsByAddressPrefix
+	copy(k[1:], addr[:])
+	binary.BigEndian.PutUint64(k[1+codec.AddressLen:], ^height)
+	binary.BigEndian.PutUint32(k[1+codec.AddressLen+consts.Uint64Len:], ^index)
+	return k
+}
+
+// PrefixTxsByAddressHeightKey returns the key that records which index rows
+// were written for the block at [height] (so they can be pruned with it).
+func PrefixTxsByAddressHeightKey(height uint64) []byte {
+	k := make([]byte, 1+consts.Uint64Len)
+	k[0] = txsByAddressHeightPrefix
+	binary.BigEndian.PutUint64(k[1:], height)
+	return k
+}
+
+// txAddresses returns all addresses involved in [tx]. [result] may be nil if
+// the block was not executed by this node (i.e. it was accepted during state
+// sync).
+func (vm *VM) txAddresses(tx *chain.Transaction, result *chain.Result) set.Set[codec.Address] {
+	addrs := set.Of(tx.Auth.Actor(), tx.Auth.Sponsor())
+	if indexer, ok := vm.c.(AddressIndexer); ok && result != nil {
+		addrs.Add(indexer.ResultAddresses(tx, result)...)
+	}
+	return addrs
+}
+
+// indexTxsByAddress adds an index row to [batch] for each address involved in
+// each transaction executed by [blk].
+//
+// If [blk] was not executed by this node, we index the transactions it
+// includes instead.
+func (vm *VM) indexTxsByAddress(batch database.Batch, blk *chain.StatelessBlock) error {
+	var (
+		results = blk.Results()
+		txs     = blk.ExecutedTxs()
+		blkID   = blk.ID()
+		entries = []byte{}
+	)
+	if results == nil {
+		txs = blk.Txs
+	}
+	for i, tx := range txs {
+		var result *chain.Result
+		if len(results) > i {
+			result = results[i]
+		}
+		v := make([]byte, txsByAddressValueLen)
+		txID := tx.ID()
+		copy(v, txID[:])
+		copy(v[ids.IDLen:], blkID[:])
+		binary.BigEndian.PutUint64(v[ids.IDLen+ids.IDLen:], uint64(blk.Tmstmp))
+		for addr := range vm.txAddresses(tx, result) {
+			if err := batch.Put(PrefixTxsByAddressKey(addr, blk.Hght, uint32(i)), v); err != nil {
+				return err
+			}
+			entries = append(entries, addr[:]...)
+			entries = binary.BigEndian.AppendUint32(entries, uint32(i))
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return batch.Put(PrefixTxsByAddressHeightKey(blk.Hght), entries)
+}
+
+// pruneTxsByAddress removes all index rows written for the block at [height].
+func (vm *VM) pruneTxsByAddress(batch database.Batch, height uint64) error {
+	heightKey := PrefixTxsByAddressHeightKey(height)
+	entries, err := vm.vmDB.Get(heightKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for len(entries) >= txsByAddressEntryLen {
+		addr := codec.Address(entries[:codec.AddressLen])
+		index := binary.BigEndian.Uint32(entries[codec.AddressLen:])
+		if err := batch.Delete(PrefixTxsByAddressKey(addr, height, index)); err != nil {
+			return err
+		}
+		entries = entries[txsByAddressEntryLen:]
+	}
+	return batch.Delete(heightKey)
+}
+
+// GetTxsByAddress returns up to [limit] transactions involving [addr] in
+// reverse chronological order, starting at [pageToken] (or the most recent
+// transaction if empty). The returned token can be used to fetch the next page
+// and is empty if there are no more transactions.
+//
+// [pageToken] is only valid for [addr] (see [rpc.EncodePageToken]).
+func (vm *VM) GetTxsByAddress(
+	addr codec.Address,
+	pageToken string,
+	limit int,
+) ([]*rpc.AddressTx, string, error) {
+	prefix := make([]byte, 1+codec.AddressLen)
+	prefix[0] = txsByAddressPrefix
+	copy(prefix[1:], addr[:])
+	start := prefix
+	cursor, err := rpc.DecodePageToken(pageToken, prefix)
+	if err != nil {
+		return nil, "", err
+	}
+	if cursor != nil {
+		if len(cursor) != txsByAddressCursorLen {
+			return nil, "", fmt.Errorf("%w: cursor length=%d", rpc.ErrInvalidPageToken, len(cursor))
+		}
+		start = append(append([]byte{}, prefix...), cursor...)
+	}
+
+	iter := vm.vmDB.NewIteratorWithStartAndPrefix(start, prefix)
+	defer iter.Release()
+
+	txs := []*rpc.AddressTx{}
+	for iter.Next() {
+		k := iter.Key()
+		if len(txs) == limit {
+			return txs, rpc.EncodePageToken(prefix, k[len(prefix):]), nil
+		}
+		v := iter.Value()
+		if len(k) != len(prefix)+txsByAddressCursorLen || len(v) != txsByAddressValueLen {
+			return nil, "", fmt.Errorf("%w: key=%x", ErrCorruptIndex, k)
+		}
+		txs = append(txs, &rpc.AddressTx{
+			TxID:      ids.ID(v[:ids.IDLen]),
+			BlockID:   ids.ID(v[ids.IDLen : ids.IDLen+ids.IDLen]),
+			Height:    ^binary.BigEndian.Uint64(k[len(prefix):]),
+			Index:     ^binary.BigEndian.Uint32(k[len(prefix)+consts.Uint64Len:]),
+			Timestamp: int64(binary.BigEndian.Uint64(v[ids.IDLen+ids.IDLen:])),
+		})
+	}
+	return txs, "", iter.Error()
+}
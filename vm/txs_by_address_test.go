@@ -0,0 +1,104 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/rpc"
+)
+
+func TestTxsByAddress(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vm := VM{
+		vmDB: memdb.New(),
+		c:    NewMockController(ctrl),
+	}
+	var (
+		actor   = codec.CreateAddress(0, ids.GenerateTestID())
+		sponsor = codec.CreateAddress(0, ids.GenerateTestID())
+		other   = codec.CreateAddress(0, ids.GenerateTestID())
+	)
+	newTx := func(actor codec.Address, sponsor codec.Address) *chain.Transaction {
+		auth := chain.NewMockAuth(ctrl)
+		auth.EXPECT().Actor().Return(actor).AnyTimes()
+		auth.EXPECT().Sponsor().Return(sponsor).AnyTimes()
+		return &chain.Transaction{Auth: auth}
+	}
+
+	// Index 3 blocks with 2 txs each involving [actor]
+	for height := uint64(1); height <= 3; height++ {
+		blk := &chain.StatelessBlock{
+			StatefulBlock: &chain.StatefulBlock{
+				Hght:   height,
+				Tmstmp: int64(height),
+				Txs: []*chain.Transaction{
+					newTx(actor, sponsor),
+					newTx(actor, actor),
+				},
+			},
+		}
+		batch := vm.vmDB.NewBatch()
+		require.NoError(vm.indexTxsByAddress(batch, blk))
+		require.NoError(batch.Write())
+	}
+
+	// Paginate in reverse chronological order
+	txs, next, err := vm.GetTxsByAddress(actor, "", 4)
+	require.NoError(err)
+	require.Len(txs, 4)
+	require.NotEmpty(next)
+	require.Equal(uint64(3), txs[0].Height)
+	require.Equal(uint32(1), txs[0].Index)
+	require.Equal(uint64(3), txs[1].Height)
+	require.Equal(uint32(0), txs[1].Index)
+	require.Equal(uint64(2), txs[2].Height)
+	require.Equal(int64(2), txs[2].Timestamp)
+
+	txs, next, err = vm.GetTxsByAddress(actor, next, 4)
+	require.NoError(err)
+	require.Len(txs, 2)
+	require.Empty(next)
+	require.Equal(uint64(1), txs[0].Height)
+	require.Equal(uint32(1), txs[0].Index)
+	require.Equal(uint64(1), txs[1].Height)
+	require.Equal(uint32(0), txs[1].Index)
+
+	// Sponsor is only involved in the first tx of each block
+	txs, _, err = vm.GetTxsByAddress(sponsor, "", 10)
+	require.NoError(err)
+	require.Len(txs, 3)
+
+	txs, _, err = vm.GetTxsByAddress(other, "", 10)
+	require.NoError(err)
+	require.Empty(txs)
+
+	_, _, err = vm.GetTxsByAddress(actor, "zz", 10)
+	require.ErrorIs(err, rpc.ErrInvalidPageToken)
+
+	// Page token can't be used for another address
+	_, next, err = vm.GetTxsByAddress(actor, "", 1)
+	require.NoError(err)
+	_, _, err = vm.GetTxsByAddress(sponsor, next, 10)
+	require.ErrorIs(err, rpc.ErrInvalidPageToken)
+
+	// Prune the oldest block
+	batch := vm.vmDB.NewBatch()
+	require.NoError(vm.pruneTxsByAddress(batch, 1))
+	require.NoError(batch.Write())
+	txs, _, err = vm.GetTxsByAddress(actor, "", 10)
+	require.NoError(err)
+	require.Len(txs, 4)
+	require.Equal(uint64(2), txs[3].Height)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/set"
+	"go.uber.org/zap"
 
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/state"
@@ -29,10 +30,22 @@ func (vm *VM) GetVerifyContext(ctx context.Context, blockHeight uint64, parent i
 	// or may not be verified yet).
 	if blockHeight-1 > vm.lastAccepted.Hght {
 		blk, err := vm.GetStatelessBlock(ctx, parent)
-		if err != nil {
+		if err == nil {
+			return &PendingVerifyContext{blk: blk}, nil
+		}
+
+		// If the parent is not available locally, attempt to fetch it from
+		// peers (falling back to the original error if we can't).
+		fetched, depth, ferr := vm.fetchParent(ctx, parent)
+		if ferr != nil {
+			vm.Logger().Debug(
+				"unable to fetch missing parent",
+				zap.Stringer("blkID", parent),
+				zap.Error(ferr),
+			)
 			return nil, err
 		}
-		return &PendingVerifyContext{blk}, nil
+		return &PendingVerifyContext{blk: fetched, fetchDepth: depth}, nil
 	}
 
 	// If the last accepted block is not yet processed, we can't use the accepted state for the
@@ -43,7 +56,7 @@ func (vm *VM) GetVerifyContext(ctx context.Context, blockHeight uint64, parent i
 	// Invariant: When [View] is called on [vm.lastAccepted], the block will be verified and the accepted
 	// state will be updated.
 	if !vm.lastAccepted.Processed() && parent == vm.lastAccepted.ID() {
-		return &PendingVerifyContext{vm.lastAccepted}, nil
+		return &PendingVerifyContext{blk: vm.lastAccepted}, nil
 	}
 
 	// If the parent block is accepted and processed, we should
@@ -53,9 +66,16 @@ func (vm *VM) GetVerifyContext(ctx context.Context, blockHeight uint64, parent i
 
 type PendingVerifyContext struct {
 	blk *chain.StatelessBlock
+
+	// fetchDepth is the number of ancestors fetched from peers to get [blk]
+	// (carried to the verification of [blk] to bound further fetches)
+	fetchDepth int
 }
 
 func (p *PendingVerifyContext) View(ctx context.Context, verify bool) (state.View, error) {
+	if p.fetchDepth > 0 {
+		ctx = context.WithValue(ctx, fetchDepthKey{}, p.fetchDepth)
+	}
 	return p.blk.View(ctx, verify)
 }
 
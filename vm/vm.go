@@ -6,8 +6,10 @@ package vm
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/profiler"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/version"
@@ -28,11 +31,13 @@ import (
 	"github.com/ava-labs/hypersdk/builder"
 	"github.com/ava-labs/hypersdk/cache"
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/emap"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/gossiper"
 	"github.com/ava-labs/hypersdk/mempool"
 	"github.com/ava-labs/hypersdk/network"
+	"github.com/ava-labs/hypersdk/offload"
 	"github.com/ava-labs/hypersdk/rpc"
 	"github.com/ava-labs/hypersdk/state"
 	"github.com/ava-labs/hypersdk/trace"
@@ -46,11 +51,16 @@ import (
 	avasync "github.com/ava-labs/avalanchego/x/sync"
 )
 
+// seenRebuildLogInterval is the number of blocks between progress logs when
+// rebuilding [seen] from the ancestry of the state sync target.
+const seenRebuildLogInterval = 1024
+
 type VM struct {
 	c Controller
 	v *version.Semantic
 
 	snowCtx         *snow.Context
+	loggers         *loggers
 	pkBytes         []byte
 	proposerMonitor *ProposerMonitor
 	baseDB          database.Database
@@ -75,10 +85,16 @@ type VM struct {
 	startSeenTime          int64
 	seenValidityWindowOnce sync.Once
 	seenValidityWindow     chan struct{}
+	// closed once [seen] has been rebuilt from the ancestry of the
+	// state sync target (if any)
+	seenRebuilt chan struct{}
 
 	// We cannot use a map here because we may parse blocks up in the ancestry
 	parsedBlocks *avacache.LRU[ids.ID, *chain.StatelessBlock]
 
+	// Contexts used to build and verify children (shared across siblings)
+	executionContexts *avacache.LRU[executionContextKey, *chain.ExecutionContext]
+
 	// Each element is a block that passed verification but
 	// hasn't yet been accepted/rejected
 	verifiedL      sync.RWMutex
@@ -96,10 +112,21 @@ type VM struct {
 	// Transactions that streaming users are currently subscribed to
 	webSocketServer *rpc.WebSocketServer
 
+	// acceptedSubscribers receive each block after it is processed
+	acceptedSubscribers *acceptedSubscribers
+
 	// authVerifiers are used to verify signatures in parallel
 	// with limited parallelism
 	authVerifiers workers.Workers
 
+	// deferredVerifier holds transactions submitted over RPC (or received
+	// over gossip) until their auth is verified (nil if disabled)
+	deferredVerifier *deferredVerifier
+
+	// authOffloader verifies the signatures of blocks built by other nodes on
+	// sidecars (nil if disabled)
+	authOffloader *offload.Client
+
 	bootstrapped avautils.Atomic[bool]
 	genesisBlk   *chain.StatelessBlock
 	preferred    ids.ID
@@ -110,6 +137,8 @@ type VM struct {
 	stateSyncClient        *stateSyncerClient
 	stateSyncNetworkClient avasync.NetworkClient
 	stateSyncNetworkServer *avasync.NetworkServer
+	stateSyncSender        common.AppSender
+	stateSyncLimiter       *stateSyncLimiter
 
 	// Network manager routes p2p messages to pre-registered handlers
 	networkManager *network.Manager
@@ -117,6 +146,53 @@ type VM struct {
 	metrics  *Metrics
 	profiler profiler.ContinuousProfiler
 
+	// shadowRootComputer is used to check the root of each verified block
+	// against a secondary implementation (nil if disabled)
+	shadowRootComputer chain.RootComputer
+
+	// blockProfiler profiles the verification of blocks requested with
+	// [ProfileBlocks] (nil if [Config.GetBlockProfileDir] is empty)
+	blockProfiler *blockProfiler
+
+	// priorityLane is registered by the controller to identify transactions
+	// that are built ahead of all others
+	priorityLane *chain.PriorityLane
+
+	// maintenance defers compactions of [vmDB] until the VM is idle
+	maintenance *maintenanceCoordinator
+
+	// previewer prevents [BuildPreview] from delaying [BuildBlock]
+	previewer buildPreviewer
+
+	// features are the protocol features advertised to peers in [handshake]
+	features  *network.FeatureRegistry
+	handshake *network.HandshakeHandler
+
+	// clock corrects the local time by the offset measured from the time
+	// peers send after their handshake
+	clock *network.PeerClock
+
+	// forks are the upgrades that may be scheduled by [Rules.GetForkActivations]
+	forks *chain.ForkRegistry
+
+	// stateUsage tracks the approximate size of each state prefix registered
+	// by the controller (or nil if [Config.GetStoreStateUsage] is disabled)
+	stateUsage *stateUsage
+
+	// chainChecksums maintains a rolling checksum of the accepted chain (or
+	// nil if [Config.GetChainChecksumInterval] is 0)
+	chainChecksums *chainChecksums
+
+	// blockFetcher fetches missing parents of blocks being verified from
+	// peers that advertise [blockFetchFeature]
+	blockFetcher      *network.BlockFetcher
+	blockFetchFeature network.Features
+
+	// bundleGossipSender gossips [chain.Bundle]s to peers that advertise
+	// [bundleGossipFeature]
+	bundleGossipSender  common.AppSender
+	bundleGossipFeature network.Features
+
 	ready chan struct{}
 	stop  chan struct{}
 }
@@ -137,6 +213,9 @@ func (vm *VM) Initialize(
 	_ []*common.Fx,
 	appSender common.AppSender,
 ) error {
+	// Each component logs to the outputs of the chain logger with its own
+	// level
+	vm.loggers = newLoggers(snowCtx.Log, snowCtx.ChainID)
 	vm.snowCtx = snowCtx
 	vm.pkBytes = bls.PublicKeyToCompressedBytes(vm.snowCtx.PublicKey)
 	// This will be overwritten when we accept the first block (in state sync) or
@@ -145,6 +224,7 @@ func (vm *VM) Initialize(
 	// Init seen for tracking transactions that have been accepted on-chain
 	vm.seen = emap.NewEMap[*chain.Transaction]()
 	vm.seenValidityWindow = make(chan struct{})
+	vm.seenRebuilt = make(chan struct{})
 	vm.ready = make(chan struct{})
 	vm.stop = make(chan struct{})
 	gatherer := avametrics.NewMultiGatherer()
@@ -159,6 +239,7 @@ func (vm *VM) Initialize(
 		return err
 	}
 	vm.metrics = metrics
+	vm.acceptedSubscribers = newAcceptedSubscribers(metrics.acceptedDropped)
 	vm.proposerMonitor = NewProposerMonitor(vm)
 	vm.networkManager = network.NewManager(vm.snowCtx.Log, vm.snowCtx.NodeID, appSender)
 
@@ -177,6 +258,31 @@ func (vm *VM) Initialize(
 	if err != nil {
 		return fmt.Errorf("implementation initialization failed: %w", err)
 	}
+	// Components without an override log at the level set by the Controller
+	// (with [snowCtx.Log.SetLevel])
+	if err := vm.loggers.SetLevels(vm.config.GetLogLevels()); err != nil {
+		return err
+	}
+	if path := vm.config.GetLogLevelsFile(); len(path) > 0 {
+		if _, err := vm.loggers.Reload(path); err != nil {
+			return fmt.Errorf("unable to load log levels: %w", err)
+		}
+		go vm.loggers.Watch(path, vm.stop)
+	}
+
+	// Upgrade the layout of [vmDB] before it is read
+	if err := migrateSchema(vm.Logger(), vm.vmDB, migrations); err != nil {
+		return fmt.Errorf("unable to migrate database schema: %w", err)
+	}
+
+	// Measure the offset of the local clock from peers (sampled in the
+	// handshake)
+	vm.clock = network.NewPeerClock(
+		vm.Logger(),
+		vm.config.GetMaxClockCorrection(),
+		vm.config.GetClockSkewThreshold(),
+		metrics.clockOffset,
+	)
 
 	// Setup tracer
 	vm.tracer, err = trace.New(vm.config.GetTraceConfig())
@@ -192,6 +298,19 @@ func (vm *VM) Initialize(
 		go vm.profiler.Dispatch() //nolint:errcheck
 	}
 
+	// Setup shadow root verification
+	if vm.config.GetShadowRootVerification() {
+		provider, ok := vm.c.(ShadowRootProvider)
+		if !ok {
+			return ErrNoShadowRootComputer
+		}
+		vm.shadowRootComputer = provider.ShadowRootComputer()
+		vm.snowCtx.Log.Warn("shadow root verification enabled")
+	}
+	if dir := vm.config.GetBlockProfileDir(); len(dir) > 0 {
+		vm.blockProfiler = newBlockProfiler(vm.loggers.Get(VerifyLogComponent), dir, vm.config.GetBlockProfileMaxBytes())
+	}
+
 	// Instantiate DBs
 	merkleRegistry := prometheus.NewRegistry()
 	vm.stateDB, err = merkledb.New(ctx, vm.rawStateDB, merkledb.Config{
@@ -225,6 +344,7 @@ func (vm *VM) Initialize(
 	vm.toEngine = toEngine
 
 	vm.parsedBlocks = &avacache.LRU[ids.ID, *chain.StatelessBlock]{Size: vm.config.GetParsedBlockCacheSize()}
+	vm.executionContexts = &avacache.LRU[executionContextKey, *chain.ExecutionContext]{Size: executionContextCacheSize}
 	vm.verifiedBlocks = make(map[ids.ID]*chain.StatelessBlock)
 	vm.acceptedBlocksByID, err = cache.NewFIFO[ids.ID, *chain.StatelessBlock](vm.config.GetAcceptedBlockWindowCache())
 	if err != nil {
@@ -240,9 +360,119 @@ func (vm *VM) Initialize(
 	vm.mempool = mempool.New[*chain.Transaction](
 		vm.tracer,
 		vm.config.GetMempoolSize(),
+		vm.config.GetMempoolMaxBytes(),
 		vm.config.GetMempoolSponsorSize(),
 		vm.config.GetMempoolExemptSponsors(),
 	)
+	if vm.config.GetMempoolReservations() {
+		vm.mempool.SetReservations(txReservations)
+	}
+	vm.mempool.SetBundles(txBundle)
+	vm.mempool.SetEviction(vm.config.GetMempoolEviction(), (*chain.Transaction).MaxFee)
+	if provider, ok := vm.c.(PriorityLaneProvider); ok && provider.PriorityLane() != nil {
+		vm.priorityLane = provider.PriorityLane()
+		vm.mempool.SetPriorityLane(vm.priorityLane.Matches, vm.config.GetPriorityLaneSize())
+	}
+	vm.features = network.NewFeatureRegistry()
+	vm.blockFetchFeature, err = vm.features.Register(blockFetchFeatureBit, blockFetchFeatureName)
+	if err != nil {
+		return err
+	}
+	vm.bundleGossipFeature, err = vm.features.Register(bundleGossipFeatureBit, bundleGossipFeatureName)
+	if err != nil {
+		return err
+	}
+	if provider, ok := vm.c.(FeatureProvider); ok {
+		if err := provider.RegisterFeatures(vm.features); err != nil {
+			return fmt.Errorf("unable to register features: %w", err)
+		}
+	}
+	vm.forks = chain.NewForkRegistry()
+	if err := vm.forks.Register(chain.AllocatePermissionsFork); err != nil {
+		return err
+	}
+	if err := vm.forks.Register(chain.ContainPanicsFork); err != nil {
+		return err
+	}
+	if err := vm.forks.Register(chain.RestrictBuildersFork); err != nil {
+		return err
+	}
+	if err := vm.forks.Register(chain.TxMemoFork); err != nil {
+		return err
+	}
+	if err := vm.forks.Register(chain.HeaderRootsFork); err != nil {
+		return err
+	}
+	if provider, ok := vm.c.(ForkProvider); ok {
+		if err := provider.RegisterForks(vm.forks); err != nil {
+			return fmt.Errorf("unable to register forks: %w", err)
+		}
+	}
+	if vm.config.GetStoreStateUsage() {
+		prefixes := chain.NewStatePrefixRegistry()
+		if provider, ok := vm.c.(StatePrefixProvider); ok {
+			if err := provider.RegisterStatePrefixes(prefixes); err != nil {
+				return fmt.Errorf("unable to register state prefixes: %w", err)
+			}
+		}
+		vm.stateUsage, err = newStateUsage(snowCtx.Log, prefixes, vm.vmDB)
+		if err != nil {
+			return err
+		}
+	}
+
+	if interval := vm.config.GetChainChecksumInterval(); interval > 0 {
+		vm.chainChecksums, err = newChainChecksums(interval, vm.config.GetChainChecksumRetention(), vm.vmDB)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Defer database maintenance while blocks are built, verified, or accepted
+	vm.maintenance = newMaintenanceCoordinator(
+		snowCtx.Log,
+		vm.vmDB,
+		vm.isIdle,
+		vm.metrics.compactionsRun,
+		vm.metrics.compactionsDeferred,
+		vm.metrics.compactionsForced,
+	)
+	go vm.maintenance.Run(vm.stop)
+
+	// Verify the auth of transactions submitted over RPC (or received over
+	// gossip) in the background
+	if size := vm.config.GetDeferredVerificationSize(); size > 0 && vm.config.GetVerifyAuth() {
+		vm.deferredVerifier = newDeferredVerifier(
+			snowCtx.Log,
+			workers.NewParallel(vm.config.GetDeferredVerificationCores(), 100),
+			size,
+			verifyTxAuth,
+			func(ctx context.Context, txs []*chain.Transaction) []error { return vm.Submit(ctx, false, txs) },
+			vm.removeTxListeners,
+			vm.metrics.deferredSize,
+			vm.metrics.deferredEvicted,
+			vm.metrics.deferredFailed,
+		)
+		go vm.deferredVerifier.Run(vm.stop)
+	}
+
+	// Offload signature verification to sidecars
+	if endpoints := vm.config.GetAuthOffloadEndpoints(); len(endpoints) > 0 && vm.config.GetVerifyAuth() {
+		offloadRegistry := prometheus.NewRegistry()
+		vm.authOffloader, err = offload.NewClient(ctx, snowCtx.Log, offloadRegistry, offload.Config{
+			Endpoints:      endpoints,
+			Timeout:        vm.config.GetAuthOffloadTimeout(),
+			HealthInterval: vm.config.GetAuthOffloadHealthInterval(),
+			BatchSize:      vm.config.GetAuthOffloadBatchSize(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := gatherer.Register("offload", offloadRegistry); err != nil {
+			return err
+		}
+		snowCtx.Log.Info("offloading signature verification", zap.Strings("endpoints", endpoints))
+	}
 
 	// Try to load last accepted
 	has, err := vm.HasLastAccepted()
@@ -267,14 +497,20 @@ func (vm *VM) Initialize(
 			snowCtx.Log.Error("could not get last accepted block", zap.Error(err))
 			return err
 		}
-		vm.preferred, vm.lastAccepted = blk.ID(), blk
+		vm.lastAccepted = blk
+		// It is not guaranteed that the last accepted state on-disk matches the post-execution
+		// result of the last accepted block (the node may have stopped after committing the
+		// changes of its child).
+		if err := vm.recoverCommittedBlock(ctx); err != nil {
+			snowCtx.Log.Error("could not recover committed block", zap.Error(err))
+			return err
+		}
+		vm.preferred = vm.lastAccepted.ID()
 		if err := vm.loadAcceptedBlocks(ctx); err != nil {
 			snowCtx.Log.Error("could not load accepted blocks from disk", zap.Error(err))
 			return err
 		}
-		// It is not guaranteed that the last accepted state on-disk matches the post-execution
-		// result of the last accepted block.
-		snowCtx.Log.Info("initialized vm from last accepted", zap.Stringer("block", blk.ID()))
+		snowCtx.Log.Info("initialized vm from last accepted", zap.Stringer("blkID", vm.preferred))
 	} else {
 		// Set balances and compute genesis root
 		sps := state.NewSimpleMutable(vm.stateDB)
@@ -343,11 +579,18 @@ func (vm *VM) Initialize(
 		gBlkID := genesisBlk.ID()
 		vm.preferred, vm.lastAccepted = gBlkID, genesisBlk
 		snowCtx.Log.Info("initialized vm from genesis",
-			zap.Stringer("block", gBlkID),
+			zap.Stringer("blkID", gBlkID),
 			zap.Stringer("pre-execution root", genesisBlk.StateRoot),
 			zap.Stringer("post-execution root", genesisRoot),
 		)
 	}
+
+	// Ensure forks are scheduled in the order they were registered (and not
+	// before genesis)
+	if err := vm.forks.Verify(vm.Rules(vm.genesisBlk.Tmstmp).GetForkActivations(), vm.genesisBlk.Tmstmp); err != nil {
+		return fmt.Errorf("invalid fork activations: %w", err)
+	}
+
 	go vm.processAcceptedBlocks()
 
 	// Setup state syncing
@@ -357,7 +600,7 @@ func (vm *VM) Initialize(
 		stateSyncSender,
 		vm.snowCtx.NodeID,
 		int64(vm.config.GetStateSyncParallelism()),
-		vm.Logger(),
+		vm.SyncLogger(),
 		"",
 		syncRegistry,
 		nil, // TODO: populate minimum version
@@ -369,13 +612,57 @@ func (vm *VM) Initialize(
 		return err
 	}
 	vm.stateSyncClient = vm.NewStateSyncClient(gatherer)
-	vm.stateSyncNetworkServer = avasync.NewNetworkServer(stateSyncSender, vm.stateDB, vm.Logger())
+	vm.stateSyncNetworkServer = avasync.NewNetworkServer(stateSyncSender, vm.stateDB, vm.SyncLogger())
+	vm.stateSyncSender = stateSyncSender
+	vm.stateSyncLimiter = newStateSyncLimiter(
+		vm.config.GetStateSyncServerConcurrency(),
+		vm.config.GetStateSyncServerPeerQuota(),
+		vm.config.GetStateSyncServerMaxDeferral(),
+		vm.metrics.stateSyncServeDeferred,
+		vm.metrics.stateSyncServeRejected,
+	)
 	vm.networkManager.SetHandler(stateSyncHandler, NewStateSyncHandler(vm))
 
 	// Setup gossip networking
 	gossipHandler, gossipSender := vm.networkManager.Register()
 	vm.networkManager.SetHandler(gossipHandler, NewTxGossipHandler(vm))
 
+	// Setup handshake networking
+	//
+	// This handler must be registered after all others that predate it so
+	// that the ids of existing handlers are unchanged for peers that don't
+	// support it. Handlers registered after it must only be used with peers
+	// that advertise support for them in their handshake.
+	handshakeHandler, handshakeSender := vm.networkManager.Register()
+	vm.handshake, err = network.NewHandshakeHandler(
+		vm.Logger(),
+		vm.snowCtx.NodeID,
+		handshakeSender,
+		&network.Handshake{Version: vm.v.String(), Features: vm.features.Supported()},
+		vm.clock,
+	)
+	if err != nil {
+		return err
+	}
+	vm.networkManager.SetHandler(handshakeHandler, vm.handshake)
+
+	// Setup block fetching
+	blockFetchHandler, blockFetchSender := vm.networkManager.Register()
+	vm.blockFetcher = network.NewBlockFetcher(
+		vm.Logger(),
+		blockFetchSender,
+		vm.getBlockBytes,
+		vm.blockFetchPeers,
+		vm.config.GetParentFetchTimeout(),
+		vm.config.GetParentFetchRetries(),
+	)
+	vm.networkManager.SetHandler(blockFetchHandler, vm.blockFetcher)
+
+	// Setup bundle gossip
+	bundleGossipHandler, bundleGossipSender := vm.networkManager.Register()
+	vm.bundleGossipSender = bundleGossipSender
+	vm.networkManager.SetHandler(bundleGossipHandler, NewBundleGossipHandler(vm))
+
 	// Startup block builder and gossiper
 	go vm.builder.Run()
 	go vm.gossiper.Run(gossipSender)
@@ -384,7 +671,7 @@ func (vm *VM) Initialize(
 	go vm.markReady()
 
 	// Setup handlers
-	jsonRPCHandler, err := rpc.NewJSONRPCHandler(rpc.Name, rpc.NewJSONRPCServer(vm))
+	jsonRPCHandler, err := vm.newRPCRouter()
 	if err != nil {
 		return fmt.Errorf("unable to create handler: %w", err)
 	}
@@ -401,6 +688,34 @@ func (vm *VM) Initialize(
 	return nil
 }
 
+// newRPCRouter returns the [rpc.Router] serving the [rpc.Name] namespace and
+// any namespaces registered by the controller (see [RPCNamespaceProvider]).
+func (vm *VM) newRPCRouter() (*rpc.Router, error) {
+	overrides := vm.config.GetRPCNamespaces()
+	router, err := rpc.NewRouter(rpc.Name, overrides)
+	if err != nil {
+		return nil, err
+	}
+	if err := router.Register(&rpc.Namespace{
+		Name:     rpc.Name,
+		Services: []any{rpc.NewJSONRPCServer(vm)},
+	}); err != nil {
+		return nil, err
+	}
+	if provider, ok := vm.c.(RPCNamespaceProvider); ok {
+		if err := provider.RegisterRPCNamespaces(router); err != nil {
+			return nil, fmt.Errorf("unable to register rpc namespaces: %w", err)
+		}
+	}
+	registered := router.Namespaces()
+	for name := range overrides {
+		if !slices.Contains(registered, name) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRPCNamespace, name)
+		}
+	}
+	return router, nil
+}
+
 func (vm *VM) checkActivity(ctx context.Context) {
 	vm.gossiper.Queue(ctx)
 	vm.builder.Queue(ctx)
@@ -419,6 +734,16 @@ func (vm *VM) markReady() {
 	// because we haven't yet observed a full [ValidityWindow].
 	vm.snowCtx.Log.Info("state sync client ready")
 
+	// If we synced to a target, [vm.seen] only contains the transactions
+	// included in blocks accepted during sync. We must rebuild it from the
+	// ancestry of the target before building blocks, otherwise we may include
+	// transactions that were accepted right before the target.
+	if target := vm.stateSyncClient.target; target != nil {
+		vm.rebuildSeenTransactions(context.TODO(), target)
+	}
+	close(vm.seenRebuilt)
+	vm.maybeRebuildStateUsage()
+
 	// Wait for a full [ValidityWindow] before
 	// we are willing to vote on blocks.
 	select {
@@ -450,17 +775,27 @@ func (vm *VM) isReady() bool {
 	}
 }
 
+// isIdle returns true if there are no blocks waiting to be accepted or
+// transactions waiting to be included in a block.
+func (vm *VM) isIdle() bool {
+	vm.verifiedL.RLock()
+	verified := len(vm.verifiedBlocks)
+	vm.verifiedL.RUnlock()
+	return verified == 0 && len(vm.acceptedQueue) == 0 && vm.mempool.Len(context.TODO()) == 0
+}
+
 // TODO: remove?
 func (vm *VM) BaseDB() database.Database {
 	return vm.baseDB
 }
 
+// ReadState reads [keys] from a single [StateSnapshot] of the accepted state.
 func (vm *VM) ReadState(ctx context.Context, keys [][]byte) ([][]byte, []error) {
-	if !vm.isReady() {
-		return utils.Repeat[[]byte](nil, len(keys)), utils.Repeat(ErrNotReady, len(keys))
+	snapshot, err := vm.StateSnapshot(ctx)
+	if err != nil {
+		return utils.Repeat[[]byte](nil, len(keys)), utils.Repeat(err, len(keys))
 	}
-	// Atomic read to ensure consistency
-	return vm.stateDB.GetValues(ctx, keys)
+	return snapshot.GetValues(ctx, keys)
 }
 
 func (vm *VM) SetState(_ context.Context, state snow.State) error {
@@ -551,11 +886,21 @@ func (vm *VM) Shutdown(ctx context.Context) error {
 	// Process remaining accepted blocks before shutdown
 	close(vm.acceptedQueue)
 	<-vm.acceptorDone
+	vm.acceptedSubscribers.close()
 
 	// Shutdown other async VM mechanisms
 	vm.builder.Done()
 	vm.gossiper.Done()
 	vm.authVerifiers.Stop()
+	if vm.deferredVerifier != nil {
+		<-vm.deferredVerifier.Done()
+		vm.deferredVerifier.workers.Stop()
+	}
+	if vm.authOffloader != nil {
+		if err := vm.authOffloader.Close(); err != nil {
+			return err
+		}
+	}
 	if vm.profiler != nil {
 		vm.profiler.Shutdown()
 	}
@@ -671,7 +1016,7 @@ func (vm *VM) ParseBlock(ctx context.Context, source []byte) (snowman.Block, err
 	// If we have seen this block before, return it with the most
 	// up-to-date info
 	if oldBlk, err := vm.GetStatelessBlock(ctx, id); err == nil {
-		vm.snowCtx.Log.Debug("returning previously parsed block", zap.Stringer("id", oldBlk.ID()))
+		vm.snowCtx.Log.Debug("returning previously parsed block", zap.Stringer("blkID", oldBlk.ID()))
 		return oldBlk, nil
 	}
 
@@ -693,7 +1038,7 @@ func (vm *VM) ParseBlock(ctx context.Context, source []byte) (snowman.Block, err
 	vm.parsedBlocks.Put(id, newBlk)
 	vm.snowCtx.Log.Info(
 		"parsed block",
-		zap.Stringer("id", newBlk.ID()),
+		zap.Stringer("blkID", newBlk.ID()),
 		zap.Uint64("height", newBlk.Hght),
 	)
 	return newBlk, nil
@@ -723,6 +1068,9 @@ func (vm *VM) BuildBlock(ctx context.Context) (snowman.Block, error) {
 	// Note: builder should regulate whether or not it actually decides to build based on state
 	// of the mempool.
 	defer vm.checkActivity(ctx)
+	defer vm.maintenance.Begin()()
+	defer vm.stateSyncLimiter.Begin()()
+	defer vm.previewer.beginBuild()()
 
 	vm.verifiedL.RLock()
 	processingBlocks := len(vm.verifiedBlocks)
@@ -758,43 +1106,59 @@ func (vm *VM) Submit(
 	defer span.End()
 	vm.metrics.txsSubmitted.Add(float64(len(txs)))
 
+	validTxs, errs := vm.checkTxs(ctx, verifyAuth, txs)
+	vm.addTxs(ctx, validTxs)
+	return errs
+}
+
+// checkTxs performs the checks required to add [txs] to the mempool and
+// returns the transactions that passed them (and an error for each
+// transaction, or a single error if none could be checked).
+func (vm *VM) checkTxs(
+	ctx context.Context,
+	verifyAuth bool,
+	txs []*chain.Transaction,
+) (validTxs []*chain.Transaction, errs []error) {
 	// We should not allow any transactions to be submitted if the VM is not
 	// ready yet. We should never reach this point because of other checks but it
 	// is good to be defensive.
 	if !vm.isReady() {
-		return []error{ErrNotReady}
+		return nil, []error{ErrNotReady}
 	}
 
 	// Create temporary execution context
 	blk, err := vm.GetStatelessBlock(ctx, vm.preferred)
 	if err != nil {
-		return []error{err}
+		return nil, []error{err}
 	}
 	view, err := blk.View(ctx, false)
 	if err != nil {
 		// This will error if a block does not yet have processed state.
-		return []error{err}
+		return nil, []error{err}
 	}
 	feeRaw, err := view.GetValue(ctx, chain.FeeKey(vm.StateManager().FeeKey()))
 	if err != nil {
-		return []error{err}
+		return nil, []error{err}
 	}
 	feeManager := fees.NewManager(feeRaw)
 	now := time.Now().UnixMilli()
 	r := vm.c.Rules(now)
 	nextFeeManager, err := feeManager.ComputeNext(blk.Tmstmp, now, r)
 	if err != nil {
-		return []error{err}
+		return nil, []error{err}
 	}
 
 	// Find repeats
 	oldestAllowed := now - r.GetValidityWindow()
 	repeats, err := blk.IsRepeat(ctx, oldestAllowed, txs, set.NewBits(), true)
 	if err != nil {
-		return []error{err}
+		return nil, []error{err}
 	}
 
-	validTxs := []*chain.Transaction{}
+	var reserved map[codec.Address]uint64
+	if vm.config.GetMempoolReservations() {
+		reserved = map[codec.Address]uint64{}
+	}
 	for i, tx := range txs {
 		// Check if transaction is a repeat before doing any extra work
 		if repeats.Contains(i) {
@@ -812,7 +1176,7 @@ func (vm *VM) Submit(
 		}
 
 		// Ensure state keys are valid
-		_, err := tx.StateKeys(vm.c.StateManager())
+		_, err := tx.StateKeys(vm.c.StateManager(), r)
 		if err != nil {
 			errs = append(errs, ErrNotAdded)
 			continue
@@ -830,9 +1194,7 @@ func (vm *VM) Submit(
 				// Failed signature verification is the only safe place to remove
 				// a transaction in listeners. Every other case may still end up with
 				// the transaction in a block.
-				if err := vm.webSocketServer.RemoveTx(txID, err); err != nil {
-					vm.snowCtx.Log.Warn("unable to remove tx from webSocketServer", zap.Error(err))
-				}
+				vm.removeTxListeners(txID, err)
 				errs = append(errs, err)
 				continue
 			}
@@ -851,19 +1213,119 @@ func (vm *VM) Submit(
 			errs = append(errs, err)
 			continue
 		}
+
+		// Ensure payers can afford all of their pending transactions
+		if reserved != nil {
+			if err := vm.checkReservations(ctx, view, tx, reserved); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
 		errs = append(errs, nil)
 		validTxs = append(validTxs, tx)
 	}
-	vm.mempool.Add(ctx, validTxs)
+	if log := vm.MempoolLogger(); log.Enabled(logging.Debug) {
+		for i, err := range errs {
+			if err != nil {
+				log.Debug("rejected tx", zap.Stringer("txID", txs[i].ID()), zap.Error(err))
+			}
+		}
+	}
+	return validTxs, errs
+}
+
+// addTxs adds [txs] (which passed [checkTxs]) to the mempool.
+func (vm *VM) addTxs(ctx context.Context, txs []*chain.Transaction) {
+	vm.mempool.Add(ctx, txs)
 	vm.checkActivity(ctx)
 	vm.metrics.mempoolSize.Set(float64(vm.mempool.Len(ctx)))
+	vm.metrics.priorityLaneSize.Set(float64(vm.mempool.PriorityLen(ctx)))
+}
+
+// SubmitDeferred adds [txs] to the mempool once their auth is verified. If
+// deferred verification is enabled, only checks that don't require auth
+// verification (or state) are performed before returning and the remaining
+// checks are performed by [Submit] once verification passes in the
+// background.
+//
+// Listeners of a deferred transaction are notified that it is pending and
+// are notified with the reason if it fails verification (or is evicted before
+// it is verified). Transactions in the [chain.PriorityLane] are never
+// deferred.
+func (vm *VM) SubmitDeferred(ctx context.Context, txs []*chain.Transaction) (errs []error) {
+	if vm.deferredVerifier == nil {
+		return vm.Submit(ctx, true, txs)
+	}
+	ctx, span := vm.tracer.Start(ctx, "VM.SubmitDeferred")
+	defer span.End()
+
+	if !vm.isReady() {
+		return []error{ErrNotReady}
+	}
+	now := time.Now().UnixMilli()
+	r := vm.c.Rules(now)
+	for _, tx := range txs {
+		if vm.priorityLane != nil && vm.priorityLane.Matches(tx) {
+			errs = append(errs, vm.Submit(ctx, true, []*chain.Transaction{tx})[0])
+			continue
+		}
+		errs = append(errs, vm.deferTx(ctx, r, now, tx))
+	}
 	return errs
 }
 
+// DeferredVerification returns true if [SubmitDeferred] verifies the auth of
+// transactions in the background.
+func (vm *VM) DeferredVerification() bool {
+	return vm.deferredVerifier != nil
+}
+
+// deferTx performs the checks of [Submit] that don't require auth
+// verification (or state) and then adds [tx] to [deferredVerifier].
+func (vm *VM) deferTx(ctx context.Context, r chain.Rules, now int64, tx *chain.Transaction) error {
+	txID := tx.ID()
+	if vm.mempool.Has(ctx, txID) || vm.deferredVerifier.Has(txID) {
+		return ErrNotAdded
+	}
+	if err := tx.Base.Execute(r.ChainID(), r, now); err != nil {
+		return err
+	}
+	if _, err := tx.StateKeys(vm.c.StateManager(), r); err != nil {
+		return ErrNotAdded
+	}
+
+	// Listeners are notified before [tx] is added so that they can't be
+	// notified that it is pending after it was removed
+	if err := vm.webSocketServer.PendingTx(txID); err != nil {
+		vm.snowCtx.Log.Warn("unable to notify webSocketServer of pending tx", zap.Error(err))
+	}
+	if !vm.deferredVerifier.Add(tx) {
+		return ErrNotAdded
+	}
+	return nil
+}
+
+// verifyTxAuth verifies the auth of [tx] against its digest.
+func verifyTxAuth(ctx context.Context, tx *chain.Transaction) error {
+	msg, err := tx.Digest()
+	if err != nil {
+		return err
+	}
+	return tx.Auth.Verify(ctx, msg)
+}
+
+// removeTxListeners notifies the listeners of [txID] that it will never be
+// added to the mempool because of [err].
+func (vm *VM) removeTxListeners(txID ids.ID, err error) {
+	if err := vm.webSocketServer.RemoveTx(txID, err); err != nil {
+		vm.snowCtx.Log.Warn("unable to remove tx from webSocketServer", zap.Error(err))
+	}
+}
+
 // "SetPreference" implements "block.ChainVM"
 // replaces "core.SnowmanVM.SetPreference"
 func (vm *VM) SetPreference(_ context.Context, id ids.ID) error {
-	vm.snowCtx.Log.Debug("set preference", zap.Stringer("id", id))
+	vm.snowCtx.Log.Debug("set preference", zap.Stringer("blkID", id))
 	vm.preferred = id
 	return nil
 }
@@ -1053,7 +1515,7 @@ func (vm *VM) backfillSeenTransactions() {
 		if err != nil {
 			vm.snowCtx.Log.Info("could not load block, exiting backfill",
 				zap.Uint64("height", blk.Height()-1),
-				zap.Stringer("blockID", blk.Prnt),
+				zap.Stringer("blkID", blk.Prnt),
 				zap.Error(err),
 			)
 			return
@@ -1067,6 +1529,80 @@ func (vm *VM) backfillSeenTransactions() {
 	)
 }
 
+// rebuildSeenTransactions populates [vm.seen] with the transactions in
+// the ancestry of [target] (going back a full [ValidityWindow]) using the
+// blocks we have locally.
+//
+// If we can't find the full [ValidityWindow] of ancestry, the node will wait
+// to observe a full [ValidityWindow] of accepted blocks before becoming ready.
+func (vm *VM) rebuildSeenTransactions(ctx context.Context, target *chain.StatelessBlock) {
+	var (
+		start  = time.Now()
+		r      = vm.Rules(target.Tmstmp)
+		blk    = target
+		oldest = target.Hght
+		blocks = 0
+		txs    = 0
+	)
+	vm.snowCtx.Log.Info(
+		"rebuilding seen txs from sync target ancestry",
+		zap.Stringer("blkID", target.ID()),
+		zap.Uint64("height", target.Hght),
+	)
+	for {
+		if target.Tmstmp-blk.Tmstmp > r.GetValidityWindow() {
+			break
+		}
+
+		// It is ok to add transactions from newest to oldest
+		vm.seen.Add(blk.Txs)
+		oldest = blk.Hght
+		blocks++
+		txs += len(blk.Txs)
+		if blocks%seenRebuildLogInterval == 0 {
+			vm.snowCtx.Log.Info(
+				"rebuilding seen txs",
+				zap.Uint64("height", blk.Hght),
+				zap.Int("blocks", blocks),
+				zap.Int("txs", txs),
+			)
+		}
+
+		// Genesis contains no transactions, so we have seen everything
+		// that could be replayed.
+		if blk.Hght <= 1 {
+			break
+		}
+		parent, err := vm.GetStatelessBlock(ctx, blk.Prnt)
+		if err != nil {
+			vm.snowCtx.Log.Warn(
+				"could not load sync target ancestry, waiting for validity window",
+				zap.Uint64("height", blk.Hght-1),
+				zap.Stringer("blkID", blk.Prnt),
+				zap.Int("blocks", blocks),
+				zap.Int("txs", txs),
+				zap.Error(err),
+			)
+			return
+		}
+		blk = parent
+	}
+
+	// We have every transaction that could still be valid at [target], so we
+	// don't need to wait for a full [ValidityWindow] of accepted blocks.
+	vm.seenValidityWindowOnce.Do(func() {
+		close(vm.seenValidityWindow)
+	})
+	vm.snowCtx.Log.Info(
+		"rebuilt seen txs from sync target ancestry",
+		zap.Uint64("start", oldest),
+		zap.Uint64("finish", target.Hght),
+		zap.Int("blocks", blocks),
+		zap.Int("txs", txs),
+		zap.Duration("t", time.Since(start)),
+	)
+}
+
 func (vm *VM) loadAcceptedBlocks(ctx context.Context) error {
 	start := uint64(0)
 	lookback := uint64(vm.config.GetAcceptedBlockWindowCache()) - 1 // include latest
@@ -1096,5 +1632,5 @@ func (vm *VM) loadAcceptedBlocks(ctx context.Context) error {
 // issue. It is better to ensure we exit to surface the error.
 func (vm *VM) Fatal(msg string, fields ...zap.Field) {
 	vm.snowCtx.Log.Fatal(msg, fields...)
-	panic("fatal error")
+	panic(&chain.FrameworkPanic{Err: errors.New(msg)})
 }
@@ -6,20 +6,30 @@ package vm
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/avalanchego/api/metrics"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/ava-labs/hypersdk/builder"
 	"github.com/ava-labs/hypersdk/cache"
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/config"
 	"github.com/ava-labs/hypersdk/emap"
+	"github.com/ava-labs/hypersdk/gossiper"
 	"github.com/ava-labs/hypersdk/mempool"
+	"github.com/ava-labs/hypersdk/network"
 	"github.com/ava-labs/hypersdk/trace"
 )
 
@@ -53,7 +63,7 @@ func TestBlockCache(t *testing.T) {
 
 		verifiedBlocks: make(map[ids.ID]*chain.StatelessBlock),
 		seen:           emap.NewEMap[*chain.Transaction](),
-		mempool:        mempool.New[*chain.Transaction](tracer, 100, 32, nil),
+		mempool:        mempool.New[*chain.Transaction](tracer, 100, 0, 32, nil),
 		acceptedQueue:  make(chan *chain.StatelessBlock, 1024), // don't block on queue
 		c:              controller,
 	}
@@ -63,6 +73,14 @@ func TestBlockCache(t *testing.T) {
 	reg, m, err := newMetrics()
 	require.NoError(err)
 	vm.metrics = m
+	vm.maintenance = newMaintenanceCoordinator(
+		logging.NoLog{},
+		vm.vmDB,
+		func() bool { return true },
+		m.compactionsRun,
+		m.compactionsDeferred,
+		m.compactionsForced,
+	)
 	require.NoError(gatherer.Register("hypersdk", reg))
 	require.NoError(vm.snowCtx.Metrics.Register(gatherer))
 
@@ -80,3 +98,178 @@ func TestBlockCache(t *testing.T) {
 	require.NoError(err)
 	require.Equal(blk, blk2)
 }
+
+func TestSeenRebuiltBeforeReady(t *testing.T) {
+	tests := []struct {
+		name          string
+		missingParent bool
+		ready         bool
+	}{
+		{
+			name:  "full ancestry",
+			ready: true,
+		},
+		{
+			name:          "missing ancestry",
+			missingParent: true,
+			ready:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.TODO()
+			tracer, _ := trace.New(&trace.Config{Enabled: false})
+			bByID, _ := cache.NewFIFO[ids.ID, *chain.StatelessBlock](3)
+			controller := NewMockController(ctrl)
+			toEngine := make(chan common.Message, 1)
+			vm := &VM{
+				snowCtx: &snow.Context{Log: logging.NoLog{}},
+				c:       controller,
+
+				vmDB: memdb.New(),
+
+				tracer:             tracer,
+				clock:              network.NewPeerClock(logging.NoLog{}, 0, 0, prometheus.NewGauge(prometheus.GaugeOpts{})),
+				acceptedBlocksByID: bByID,
+				verifiedBlocks:     make(map[ids.ID]*chain.StatelessBlock),
+
+				seen:               emap.NewEMap[*chain.Transaction](),
+				seenValidityWindow: make(chan struct{}),
+				seenRebuilt:        make(chan struct{}),
+				ready:              make(chan struct{}),
+				stop:               make(chan struct{}),
+				toEngine:           toEngine,
+			}
+			vm.builder = builder.NewManual(vm)
+			vm.gossiper = gossiper.NewManual(vm)
+			defer close(vm.stop)
+
+			rules := chain.NewMockRules(ctrl)
+			rules.EXPECT().GetValidityWindow().Return(int64(60)).AnyTimes()
+			rules.EXPECT().GetForkActivations().Return(nil).AnyTimes()
+			rules.EXPECT().GetEpochLength().Return(uint64(0)).AnyTimes()
+			rules.EXPECT().GetIncludeResultsRoot().Return(false).AnyTimes()
+			controller.EXPECT().Rules(gomock.Any()).Return(rules).AnyTimes()
+
+			// Create ancestry of the sync target
+			genesis, err := chain.ParseStatefulBlock(ctx, chain.NewGenesisBlock(ids.Empty), nil, choices.Accepted, vm)
+			require.NoError(err)
+			vm.genesisBlk = genesis
+			parent := genesis
+			blks := make([]*chain.StatelessBlock, 0, 3)
+			for i := 1; i <= 3; i++ {
+				blk, err := chain.ParseStatefulBlock(ctx, &chain.StatefulBlock{
+					Prnt:   parent.ID(),
+					Tmstmp: genesis.Tmstmp + int64(i*10),
+					Hght:   uint64(i),
+					Txs:    []*chain.Transaction{},
+				}, nil, choices.Accepted, vm)
+				require.NoError(err)
+				blks = append(blks, blk)
+				parent = blk
+			}
+			for i, blk := range blks {
+				if tt.missingParent && i == 0 {
+					continue
+				}
+				vm.acceptedBlocksByID.Put(blk.ID(), blk)
+			}
+			target := blks[2]
+			vm.lastAccepted = target
+
+			// Include a tx right before the sync target
+			tx := &chain.Transaction{Base: &chain.Base{Timestamp: target.Tmstmp + 100}}
+			blks[1].Txs = []*chain.Transaction{tx}
+
+			done := make(chan struct{})
+			close(done)
+			vm.stateSyncClient = &stateSyncerClient{
+				vm:          vm,
+				startedSync: true,
+				target:      target,
+				done:        done,
+			}
+			go vm.markReady()
+
+			select {
+			case <-vm.seenRebuilt:
+			case <-time.After(10 * time.Second):
+				require.FailNow("seen was not rebuilt")
+			}
+			require.True(vm.seen.Any([]*chain.Transaction{tx}))
+			if !tt.ready {
+				// We must wait for a full [ValidityWindow] of accepted blocks
+				// before building.
+				require.False(vm.isReady())
+				select {
+				case <-vm.seenValidityWindow:
+					require.FailNow("validity window should not be ready")
+				default:
+				}
+				return
+			}
+			select {
+			case <-vm.ready:
+			case <-time.After(10 * time.Second):
+				require.FailNow("node did not become ready")
+			}
+			require.Equal(common.StateSyncDone, <-toEngine)
+		})
+	}
+}
+
+func TestVerifyBuilder(t *testing.T) {
+	require := require.New(t)
+	ctx := context.TODO()
+
+	var (
+		networkID = uint32(1337)
+		chainID   = ids.GenerateTestID()
+		nodeID    = ids.GenerateTestNodeID()
+	)
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	vm := &VM{snowCtx: &snow.Context{
+		NetworkID:  networkID,
+		ChainID:    chainID,
+		NodeID:     nodeID,
+		WarpSigner: warp.NewSigner(sk, networkID, chainID),
+		ValidatorState: &validators.TestState{
+			GetValidatorSetF: func(_ context.Context, height uint64, _ ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+				// [nodeID] joins the validator set at P-Chain height 10
+				if height < 10 {
+					return map[ids.NodeID]*validators.GetValidatorOutput{}, nil
+				}
+				return map[ids.NodeID]*validators.GetValidatorOutput{
+					nodeID: {NodeID: nodeID, PublicKey: bls.PublicFromSecretKey(sk), Weight: 1},
+				}, nil
+			},
+		},
+	}}
+
+	digest := ids.GenerateTestID()
+	signature, err := vm.SignBuilder(digest)
+	require.NoError(err)
+	ok, err := vm.VerifyBuilder(ctx, vm.BuilderAddress(), 10, digest, signature)
+	require.NoError(err)
+	require.True(ok)
+
+	// The builder must be a validator at the P-Chain height of the block
+	ok, err = vm.VerifyBuilder(ctx, vm.BuilderAddress(), 9, digest, signature)
+	require.NoError(err)
+	require.False(ok)
+
+	// The signature only covers [digest]
+	ok, err = vm.VerifyBuilder(ctx, vm.BuilderAddress(), 10, ids.GenerateTestID(), signature)
+	require.NoError(err)
+	require.False(ok)
+
+	// Another node can't claim the block
+	ok, err = vm.VerifyBuilder(ctx, NodeAddress(ids.GenerateTestNodeID()), 10, digest, signature)
+	require.NoError(err)
+	require.False(ok)
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/fees"
 	"github.com/ava-labs/hypersdk/state"
 	"github.com/ava-labs/hypersdk/vm"
@@ -34,17 +36,60 @@ type Genesis struct {
 	SponsorStateKeysMaxChunks []uint16
 
 	// Chain Parameters
-	MinBlockGap      int64 `json:"minBlockGap"`      // ms
-	MinEmptyBlockGap int64 `json:"minEmptyBlockGap"` // ms
+	MinBlockGap              int64 `json:"minBlockGap"`      // ms
+	MinEmptyBlockGap         int64 `json:"minEmptyBlockGap"` // ms
+	RestrictBuilders         bool  `json:"restrictBuilders"`
+	IncludeResultsRoot       bool  `json:"includeResultsRoot"`
+	DelayedExecution         bool  `json:"delayedExecution"`
+	PermutePendingTxs        bool  `json:"permutePendingTxs"` // requires delayedExecution
+	StateFetchRetries        uint8 `json:"stateFetchRetries"`
+	DisableParallelExecution bool  `json:"disableParallelExecution"` // parallel execution is enabled when omitted
+
+	MaxRepeatCheckDepth int `json:"maxRepeatCheckDepth"` // blocks (0 is unlimited)
+
+	// Epoch Parameters (disabled if EpochLength is 0)
+	EpochLength uint64 `json:"epochLength"` // blocks
+
+	// Signer Diversity Parameters (disabled if MinDistinctSigners is 0)
+	MinDistinctSigners    int `json:"minDistinctSigners"`
+	MinDistinctSignersTxs int `json:"minDistinctSignersTxs"` // blocks with fewer txs are exempt
+
+	// Access Control Parameters
+	RestrictedActions []uint8 `json:"restrictedActions"` // action type IDs
+	ACLAdmin          string  `json:"aclAdmin"`          // bech32 address
+
+	aclAdmin codec.Address
+
+	// Emergency Parameters (the chain is paused from PausedFrom until
+	// PausedUntil, or indefinitely if PausedUntil is 0)
+	PausedFrom  int64 `json:"pausedFrom"`  // ms (disabled if 0)
+	PausedUntil int64 `json:"pausedUntil"` // ms
+
+	// Upgrade Parameters (when each fork activates, see [chain.IsActive])
+	ForkActivations chain.ForkActivations `json:"forkActivations"` // ms
 
 	// Chain Fee Parameters
-	MinUnitPrice               fees.Dimensions `json:"minUnitPrice"`
-	UnitPriceChangeDenominator fees.Dimensions `json:"unitPriceChangeDenominator"`
-	WindowTargetUnits          fees.Dimensions `json:"windowTargetUnits"` // 10s
-	MaxBlockUnits              fees.Dimensions `json:"maxBlockUnits"`     // must be possible to reach before block too large
+	RefundPolicy               chain.RefundPolicy `json:"refundPolicy"`
+	MinUnitPrice               fees.Dimensions    `json:"minUnitPrice"`
+	UnitPriceChangeDenominator fees.Dimensions    `json:"unitPriceChangeDenominator"`
+	WindowTargetUnits          fees.Dimensions    `json:"windowTargetUnits"` // 10s
+	MaxBlockUnits              fees.Dimensions    `json:"maxBlockUnits"`     // must be possible to reach before block too large
+
+	// Pricing Parameters (PricingMode is used from PricingModeActivation,
+	// before which unit prices are computed with [fees.WindowPricing])
+	PricingMode               fees.PricingMode `json:"pricingMode"`
+	PricingModeActivation     int64            `json:"pricingModeActivation"` // ms
+	UtilizationEMADenominator uint64           `json:"utilizationEMADenominator"`
+
+	// Block Pacing Parameters (disabled if TargetBlockRate is 0)
+	TargetBlockRate            int64  `json:"targetBlockRate"` // s
+	BlockCostChangeDenominator uint64 `json:"blockCostChangeDenominator"`
+	MinBlockCost               uint64 `json:"minBlockCost"`
+	MaxBlockCost               uint64 `json:"maxBlockCost"`
 
 	// Tx Parameters
-	ValidityWindow int64 `json:"validityWindow"` // ms
+	ValidityWindow         int64 `json:"validityWindow"` // ms
+	EnforceIdempotencyKeys bool  `json:"enforceIdempotencyKeys"`
 
 	// Tx Fee Parameters
 	BaseComputeUnits          uint64 `json:"baseUnits"`
@@ -71,8 +116,10 @@ func Default() *Genesis {
 		StateBranchFactor: merkledb.BranchFactor16,
 
 		// Chain Parameters
-		MinBlockGap:      100,
-		MinEmptyBlockGap: 2_500,
+		MinBlockGap:         100,
+		MinEmptyBlockGap:    2_500,
+		StateFetchRetries:   3,
+		MaxRepeatCheckDepth: 1_024,
 
 		// Chain Fee Parameters
 		MinUnitPrice:               fees.Dimensions{100, 100, 100, 100, 100},
@@ -80,6 +127,12 @@ func Default() *Genesis {
 		WindowTargetUnits:          fees.Dimensions{20_000_000, 1_000, 1_000, 1_000, 1_000},
 		MaxBlockUnits:              fees.Dimensions{1_800_000, 2_000, 2_000, 2_000, 2_000},
 
+		// Pricing Parameters
+		UtilizationEMADenominator: 8,
+
+		// Block Pacing Parameters
+		BlockCostChangeDenominator: 48,
+
 		// Tx Fee Compute Parameters
 		BaseComputeUnits: 1,
 
@@ -110,6 +163,17 @@ func New(b []byte, _ []byte /* upgradeBytes */) (*Genesis, error) {
 			return nil, fmt.Errorf("failed to unmarshal config %s: %w", string(b), err)
 		}
 	}
+	// Computing the next block cost would panic without a change denominator
+	if g.TargetBlockRate > 0 && g.BlockCostChangeDenominator == 0 {
+		return nil, fmt.Errorf("%w: blockCostChangeDenominator is 0 but targetBlockRate is set", ErrInvalidFeeParameters)
+	}
+	if len(g.ACLAdmin) > 0 {
+		admin, err := codec.ParseAddressBech32(consts.HRP, g.ACLAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, g.ACLAdmin)
+		}
+		g.aclAdmin = admin
+	}
 	return g, nil
 }
 
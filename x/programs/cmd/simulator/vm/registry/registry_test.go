@@ -0,0 +1,24 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/ava-labs/hypersdk/chain/chaintest"
+	"github.com/ava-labs/hypersdk/x/programs/cmd/simulator/vm/actions"
+	"github.com/ava-labs/hypersdk/x/programs/cmd/simulator/vm/consts"
+)
+
+func TestActionVectors(t *testing.T) {
+	chaintest.RunActionVectors(
+		t,
+		consts.ActionRegistry,
+		consts.ActionVectors,
+		nil,
+		// TODO: add vectors for all actions
+		(&actions.ProgramCreate{}).GetTypeID(),
+		(&actions.ProgramExecute{}).GetTypeID(),
+	)
+}
@@ -46,6 +46,7 @@ func (cli *JSONRPCClient) Genesis(ctx context.Context) (*genesis.Genesis, error)
 		"genesis",
 		nil,
 		resp,
+		requester.Idempotent(),
 	)
 	if err != nil {
 		return nil, err
@@ -61,6 +62,7 @@ func (cli *JSONRPCClient) Tx(ctx context.Context, id ids.ID) (bool, bool, int64,
 		"tx",
 		&TxArgs{TxID: id},
 		resp,
+		requester.Idempotent(),
 	)
 	switch {
 	// We use string parsing here because the JSON-RPC library we use may not
@@ -6,6 +6,8 @@ package storage
 import (
 	"context"
 
+	"github.com/ava-labs/avalanchego/ids"
+
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/state"
 )
@@ -43,3 +45,27 @@ func (*StateManager) TimestampKey() []byte {
 func (*StateManager) FeeKey() []byte {
 	return FeeKey()
 }
+
+func (*StateManager) MemoKey(txID ids.ID) []byte {
+	return MemoKey(txID)
+}
+
+func (*StateManager) TaskQueueKey() []byte {
+	return TaskQueueKey()
+}
+
+func (*StateManager) TaskKey(taskID ids.ID) []byte {
+	return TaskKey(taskID)
+}
+
+func (*StateManager) ACLKey(actionTypeID uint8, addr codec.Address) []byte {
+	return ACLKey(actionTypeID, addr)
+}
+
+func (*StateManager) EpochKey(epoch uint64) []byte {
+	return EpochKey(epoch)
+}
+
+func (*StateManager) IdempotencyKey(id ids.ID) []byte {
+	return IdempotencyKey(id)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/crypto/ed25519"
 	"github.com/ava-labs/hypersdk/fees"
@@ -28,6 +29,13 @@ const (
 	heightPrefix    = 0x2
 	timestampPrefix = 0x3
 	feePrefix       = 0x4
+	memoPrefix      = 0x5
+	taskQueuePrefix = 0x6
+	taskPrefix      = 0x7
+	aclPrefix       = 0x8
+	epochPrefix     = 0x9
+
+	idempotencyPrefix = 0xA
 )
 
 var (
@@ -36,6 +44,7 @@ var (
 	heightKey    = []byte{heightPrefix}
 	timestampKey = []byte{timestampPrefix}
 	feeKey       = []byte{feePrefix}
+	taskQueueKey = []byte{taskQueuePrefix}
 )
 
 const ProgramChunks uint16 = 1
@@ -179,3 +188,48 @@ func TimestampKey() (k []byte) {
 func FeeKey() (k []byte) {
 	return feeKey
 }
+
+// [memoPrefix] + [txID]
+func MemoKey(txID ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = memoPrefix
+	copy(k[1:], txID[:])
+	return
+}
+
+func TaskQueueKey() (k []byte) {
+	return taskQueueKey
+}
+
+// [taskPrefix] + [taskID]
+func TaskKey(taskID ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = taskPrefix
+	copy(k[1:], taskID[:])
+	return
+}
+
+// [aclPrefix] + [actionTypeID] + [address]
+func ACLKey(actionTypeID uint8, addr codec.Address) (k []byte) {
+	k = make([]byte, 2+codec.AddressLen)
+	k[0] = aclPrefix
+	k[1] = actionTypeID
+	copy(k[2:], addr[:])
+	return
+}
+
+// [epochPrefix] + [epoch]
+func EpochKey(epoch uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = epochPrefix
+	binary.BigEndian.PutUint64(k[1:], epoch)
+	return
+}
+
+// [idempotencyPrefix] + [id]
+func IdempotencyKey(id ids.ID) (k []byte) {
+	k = make([]byte, 1+ids.IDLen)
+	k[0] = idempotencyPrefix
+	copy(k[1:], id[:])
+	return
+}